@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/analysis"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/jobs"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
 )
 
 // GetAnalysisEnabled returns whether analysis feature is enabled
@@ -140,53 +142,40 @@ func (a *App) UndismissAnnotation(annotationID int64) error {
 
 // AnalyzeCollection performs AI analysis on a collection
 func (a *App) AnalyzeCollection(collID int64) (*analysis.CollectionResult, error) {
-	s := a.settings.Get()
-	if !s.AnalysisEnabled {
-		return nil, fmt.Errorf("analysis feature is not enabled")
+	if _, err := a.collectionAnalysisConfig(); err != nil {
+		return nil, err
 	}
-
-	// Validate provider configuration
-	provider := s.AnalysisProvider
-	if provider == "" {
-		return nil, fmt.Errorf("no AI provider configured - go to Settings > AI Analysis to set one")
-	}
-
-	// Get collection details
 	coll, err := a.db.GetCollection(collID)
 	if err != nil {
 		return nil, fmt.Errorf("get collection: %w", err)
 	}
 
-	// Get works in collection
-	works, err := a.db.GetCollectionWorks(collID, false)
-	if err != nil {
-		return nil, fmt.Errorf("get collection works: %w", err)
+	var result analysis.CollectionResult
+	label := "Analyze " + coll.CollectionName
+	if _, err := a.jobs.Run(a.ctx, jobCollectionAnalysis, label, collectionAnalysisParams{CollID: collID}, &result); err != nil {
+		return nil, err
 	}
+	return &result, nil
+}
 
-	// Build work summaries with previews, skipping suppressed works
-	summaries := make([]analysis.WorkSummary, 0, len(works))
-	for i, w := range works {
-		if w.IsSuppressed {
-			continue
-		}
-		filePath := a.fileOps.GetFullPath(&w.Work)
-		preview := ""
+type collectionAnalysisParams struct {
+	CollID int64 `json:"collId"`
+}
 
-		extracted, err := analysis.ExtractFromDocx(filePath)
-		if err == nil && extracted.FullText != "" {
-			preview = analysis.GetPreview(extracted.FullText, 100)
-		}
+// collectionAnalysisConfig returns the configured AI provider
+func (a *App) collectionAnalysisConfig() (analysis.ProviderConfig, error) {
+	s := a.settings.Get()
+	if !s.AnalysisEnabled {
+		return analysis.ProviderConfig{}, fmt.Errorf("analysis feature is not enabled")
+	}
 
-		summaries = append(summaries, analysis.WorkSummary{
-			WorkID:   w.WorkID,
-			Title:    w.Title,
-			Type:     w.Type,
-			Preview:  preview,
-			Position: i + 1,
-		})
+	// Validate provider configuration
+	provider := s.AnalysisProvider
+	if provider == "" {
+		return analysis.ProviderConfig{}, fmt.Errorf("no AI provider configured - go to Settings > AI Analysis to set one")
 	}
 
-	// Create analyzer with defaults
+	// Use the provider's default model
 	model := s.AnalysisModel
 	if model == "" {
 		switch analysis.Provider(provider) {
@@ -206,12 +195,12 @@ func (a *App) AnalyzeCollection(collID int64) (*analysis.CollectionResult, error
 	switch analysis.Provider(provider) {
 	case analysis.ProviderOpenAI:
 		if s.OpenAIAPIKey == "" {
-			return nil, fmt.Errorf("OpenAI API key not configured - go to Settings > AI Analysis")
+			return analysis.ProviderConfig{}, fmt.Errorf("OpenAI API key not configured - go to Settings > AI Analysis")
 		}
 		cfg.APIKey = s.OpenAIAPIKey
 	case analysis.ProviderAnthropic:
 		if s.AnthropicAPIKey == "" {
-			return nil, fmt.Errorf("anthropic API key not configured - go to Settings > AI Analysis")
+			return analysis.ProviderConfig{}, fmt.Errorf("anthropic API key not configured - go to Settings > AI Analysis")
 		}
 		cfg.APIKey = s.AnthropicAPIKey
 	case analysis.ProviderOllama:
@@ -221,7 +210,61 @@ func (a *App) AnalyzeCollection(collID int64) (*analysis.CollectionResult, error
 			cfg.Endpoint = s.OllamaEndpoint
 		}
 	default:
-		return nil, fmt.Errorf("unknown provider: %s - go to Settings > AI Analysis to configure", provider)
+		return analysis.ProviderConfig{}, fmt.Errorf("unknown provider: %s - go to Settings > AI Analysis to configure", provider)
+	}
+
+	return cfg, nil
+}
+
+func (a *App) runCollectionAnalysis(ctx context.Context, job *models.Job, r *jobs.Reporter) (any, error) {
+	var params collectionAnalysisParams
+	if err := jobs.DecodeParams(job, &params); err != nil {
+		return nil, err
+	}
+	collID := params.CollID
+
+	cfg, err := a.collectionAnalysisConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get collection details
+	coll, err := a.db.GetCollection(collID)
+	if err != nil {
+		return nil, fmt.Errorf("get collection: %w", err)
+	}
+
+	// Get works in collection
+	works, err := a.db.GetCollectionWorks(collID, false)
+	if err != nil {
+		return nil, fmt.Errorf("get collection works: %w", err)
+	}
+
+	// Build work summaries with previews, skipping suppressed works
+	summaries := make([]analysis.WorkSummary, 0, len(works))
+	for i, w := range works {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if w.IsSuppressed {
+			continue
+		}
+		r.Progress(i+1, len(works), w.Title)
+		filePath := a.fileOps.GetFullPath(&w.Work)
+		preview := ""
+
+		extracted, err := analysis.ExtractFromDocx(filePath)
+		if err == nil && extracted.FullText != "" {
+			preview = analysis.GetPreview(extracted.FullText, 100)
+		}
+
+		summaries = append(summaries, analysis.WorkSummary{
+			WorkID:   w.WorkID,
+			Title:    w.Title,
+			Type:     w.Type,
+			Preview:  preview,
+			Position: i + 1,
+		})
 	}
 
 	storage := analysis.NewStorage(a.db.Conn())
@@ -230,7 +273,8 @@ func (a *App) AnalyzeCollection(collID int64) (*analysis.CollectionResult, error
 		return nil, fmt.Errorf("create analyzer: %w", err)
 	}
 
-	return analyzer.AnalyzeCollection(context.Background(), collID, coll.CollectionName, summaries)
+	r.Logf("Asking %s (%s) about %d works", cfg.Provider, cfg.Model, len(summaries))
+	return analyzer.AnalyzeCollection(ctx, collID, coll.CollectionName, summaries)
 }
 
 // GetCollectionAnalysis retrieves the latest analysis for a collection
@@ -5,13 +5,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/backup"
-	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/db"
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/fileops"
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/fts"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/jobs"
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/migrate"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/revisions"
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/server"
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/settings"
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/state"
@@ -25,11 +27,20 @@ type App struct {
 	fileOps       *fileops.FileOps
 	state         *state.Manager
 	backup        *backup.Manager
+	revisions     *revisions.Store
 	settings      *settings.Manager
 	fileServer    *server.FileServer
 	importSession *ImportSession
 	watcher       *watcher.Watcher
-	buildCancel   context.CancelFunc
+	jobs          *jobs.Queue
+
+	// buildObjectsMu is held for reading by each book build and for writing
+	// while build records and their archived inputs are pruned
+	buildObjectsMu sync.RWMutex
+	// collBuilds holds one slot per collection so its builds, which share
+	// a part cache, run one at a time
+	collBuilds   map[int64]chan struct{}
+	collBuildsMu sync.Mutex
 }
 
 func NewApp() *App {
@@ -47,10 +58,14 @@ func (a *App) Startup(ctx context.Context) {
 		PDFPreviewPath:       s.PDFPreviewPath,
 		SubmissionExportPath: s.SubmissionExportPath,
 		TemplateFolderPath:   s.TemplateFolderPath,
+		LibreOfficePath:      s.LibreOfficePath,
+		ConverterPriority:    s.PDFConverterPriority,
+		MaxConversions:       s.PDFMaxConversions,
 	})
 	a.state = state.NewManager()
 
 	a.fileServer = server.New(s.PDFPreviewPath)
+	a.fileServer.SetCalendarHandler(a.writeCalendarICS)
 	_, _ = a.fileServer.Start()
 
 	homeDir, _ := os.UserHomeDir()
@@ -63,6 +78,7 @@ func (a *App) Startup(ctx context.Context) {
 	_ = os.MkdirAll(templatesDir, 0755)
 
 	a.backup = backup.NewManager(dbPath)
+	a.backup.SetOptions(backup.OptionsFromSettings(s))
 	_, _ = a.backup.AutoBackup()
 
 	database, err := db.New(dbPath)
@@ -94,6 +110,13 @@ func (a *App) Startup(ctx context.Context) {
 		panic(err)
 	}
 
+	if err := a.db.PruneAuditLog(auditRetention); err != nil {
+		fmt.Printf(">>> Audit log prune error: %v\n", err)
+	}
+
+	a.revisions = revisions.NewStore(a.db, revisions.DefaultDir())
+	a.startJobs()
+
 	fmt.Println(">>> Starting file watcher setup")
 	fmt.Printf(">>> BaseFolderPath: %s\n", s.BaseFolderPath)
 	runtime.EventsEmit(ctx, "startup:status", map[string]string{"message": "Starting file watcher..."})
@@ -104,6 +127,7 @@ func (a *App) Startup(ctx context.Context) {
 	})
 	a.watcher.SetPDFHandler(a.handlePDFRegeneration)
 	a.watcher.SetFTSHandler(a.handleFTSExtraction)
+	a.watcher.SetRevisionHandler(a.handleRevisionCapture)
 	if err := a.watcher.Start(); err != nil {
 		fmt.Printf(">>> Watcher start error: %v\n", err)
 		runtime.LogWarning(ctx, "Failed to start file watcher: "+err.Error())
@@ -112,6 +136,8 @@ func (a *App) Startup(ctx context.Context) {
 	}
 
 	a.processStaleFiles()
+	go a.autoSnapshotManuscripts()
+	go a.syncRevisions()
 }
 
 func (a *App) SaveWindowGeometry(x, y, width, height int) {
@@ -129,6 +155,9 @@ func (a *App) Shutdown(_ context.Context) {
 	if a.watcher != nil {
 		a.watcher.Stop()
 	}
+	if a.jobs != nil {
+		a.jobs.Stop()
+	}
 	// Checkpoint and close FTS database
 	if ftsDB != nil && ftsDB.Exists() {
 		if conn := ftsDB.Conn(); conn != nil {
@@ -155,27 +184,10 @@ func (a *App) handlePDFRegeneration(workID int64, filePath string) {
 			runtime.EventsEmit(a.ctx, "watcher:error", fmt.Sprintf("PDF generation failed: %v", err))
 		} else {
 			runtime.EventsEmit(a.ctx, "preview:updated", workID)
-
-			// Invalidate part caches for all collections containing this work
-			a.invalidatePartCachesForWork(workID)
 		}
 	}
 }
 
-// invalidatePartCachesForWork clears the part cache for the part containing the given work
-// in all collections that include the work.
-func (a *App) invalidatePartCachesForWork(workID int64) {
-	partInfos, err := a.db.GetWorkPartInfo(workID)
-	if err != nil {
-		return
-	}
-
-	for _, info := range partInfos {
-		cacheDir := bookbuild.GetCacheDir(info.CollID)
-		_ = bookbuild.ClearPartCache(cacheDir, info.PartID)
-	}
-}
-
 func (a *App) handleFTSExtraction(workID int64, _ string) {
 	ftsDB := a.getFTSDB()
 	if !ftsDB.Exists() {
@@ -194,29 +206,24 @@ func (a *App) processStaleFiles() {
 		return
 	}
 
+	workIDs := make([]int64, 0, len(staleness.StaleWorkIDs))
 	for _, workID := range staleness.StaleWorkIDs {
 		work, err := a.db.GetWork(int64(workID))
 		if err != nil || work == nil || work.Path == nil {
 			continue
 		}
-		fullPath := a.fileOps.GetFilename(*work.Path)
-		go a.handlePDFRegeneration(int64(workID), fullPath)
-		go a.handleFTSExtraction(int64(workID), fullPath)
+		workIDs = append(workIDs, int64(workID))
+		go a.handleFTSExtraction(int64(workID), a.fileOps.GetFilename(*work.Path))
+	}
+	if len(workIDs) > 0 {
+		_, _ = a.RegeneratePDFs(workIDs)
 	}
 }
 
-// createBuildContext creates a cancellable context for build operations.
-// Call CancelBuild() to cancel any in-progress build.
-func (a *App) createBuildContext() context.Context {
-	ctx, cancel := context.WithCancel(a.ctx)
-	a.buildCancel = cancel
-	return ctx
-}
-
-// CancelBuild cancels any in-progress book build operation.
+// CancelBuild cancels the queued and running book builds, rebuilds and
+// EPUB exports.
 func (a *App) CancelBuild() {
-	if a.buildCancel != nil {
-		a.buildCancel()
-		a.buildCancel = nil
+	for _, kind := range []string{jobBookBuild, jobBookRebuild, jobBookEPUB} {
+		a.cancelJobsOfKind(kind)
 	}
 }
@@ -13,6 +13,11 @@ func (a *App) ListBackups() ([]backup.BackupInfo, error) {
 	return a.backup.ListBackups()
 }
 
+// VerifyBackup re-reads a backup and checks its checksums and database integrity
+func (a *App) VerifyBackup(backupPath string) (*backup.BackupInfo, error) {
+	return a.backup.VerifyBackup(backupPath)
+}
+
 func (a *App) RestoreBackup(backupPath string) error {
 	return a.backup.RestoreBackup(backupPath)
 }
@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/backup"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/jobs"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ListBookBuilds returns the recorded galley builds of a collection, newest
+// first
+func (a *App) ListBookBuilds(collID int64) ([]models.BookBuildInfo, error) {
+	return a.db.ListBookBuilds(collID)
+}
+
+// GetBookBuild returns one build record with its manifest and inputs
+func (a *App) GetBookBuild(buildID int64) (*models.BookBuild, error) {
+	return a.getBookBuild(buildID)
+}
+
+// DiffBookBuilds compares the inputs and settings of two builds
+func (a *App) DiffBookBuilds(fromID, toID int64) (*bookbuild.BuildDiff, error) {
+	from, err := a.getBookBuild(fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := a.getBookBuild(toID)
+	if err != nil {
+		return nil, err
+	}
+	return bookbuild.DiffBuilds(from, to)
+}
+
+// RebuildBookBuild reproduces a recorded build from its archived inputs.
+// A warning is returned if the new galley does not print the same pages.
+func (a *App) RebuildBookBuild(buildID int64) (*BookExportResult, error) {
+	startTime := time.Now()
+
+	record, err := a.getBookBuild(buildID)
+	if err != nil {
+		return nil, err
+	}
+
+	base := strings.TrimSuffix(filepath.Base(record.OutputPath), filepath.Ext(record.OutputPath))
+	outputPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:            fmt.Sprintf("Rebuild Build %d as PDF", buildID),
+		DefaultDirectory: filepath.Dir(record.OutputPath),
+		DefaultFilename:  fmt.Sprintf("%s-build-%d.pdf", base, buildID),
+		Filters: []runtime.FileFilter{
+			{DisplayName: "PDF Files", Pattern: "*.pdf"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open save dialog: %w", err)
+	}
+	if outputPath == "" {
+		return nil, nil
+	}
+
+	a.OpenStatusBar()
+	defer a.CloseStatusBar()
+	a.EmitStatus("progress", fmt.Sprintf("Rebuilding build %d...", buildID))
+
+	var result BookExportResult
+	_, err = a.jobs.Run(a.ctx, jobBookRebuild, fmt.Sprintf("Rebuild build %d", buildID), bookRebuildParams{
+		BuildID:    buildID,
+		OutputPath: outputPath,
+	}, &result)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			a.EmitStatus("cancelled", "Rebuild cancelled")
+			return nil, fmt.Errorf("rebuild cancelled")
+		}
+		a.EmitStatus("error", fmt.Sprintf("Rebuild failed: %v", err))
+		return nil, err
+	}
+
+	a.EmitStatus("success", "Galley rebuilt")
+	result.Duration = time.Since(startTime).Round(time.Millisecond).String()
+	return &result, nil
+}
+
+// bookRebuildParams are the params of a book-rebuild job
+type bookRebuildParams struct {
+	BuildID    int64  `json:"buildId"`
+	OutputPath string `json:"outputPath"`
+}
+
+// runBookRebuild reproduces a recorded build and records the new one
+func (a *App) runBookRebuild(ctx context.Context, job *models.Job, r *jobs.Reporter) (any, error) {
+	var params bookRebuildParams
+	if err := jobs.DecodeParams(job, &params); err != nil {
+		return nil, err
+	}
+	record, err := a.getBookBuild(params.BuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := a.lockCollectionBuild(ctx, record.CollID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	a.buildObjectsMu.RLock()
+	result, err := bookbuild.Rebuild(record, bookbuild.PipelineOptions{
+		Ctx:        ctx,
+		OutputPath: params.OutputPath,
+		OnProgress: func(stage string, current, total int, message string) {
+			a.emitExportProgress(stage, current, total, message)
+			a.EmitStatus("progress", message)
+			r.Progress(current, total, message)
+		},
+		ConvertToPDF: func(ctx context.Context, src, dst string) error {
+			_, err := a.fileOps.ConvertToPDF(ctx, src, dst)
+			return err
+		},
+	})
+	if err == nil {
+		if err := a.db.AddBookBuild(result.Record); err != nil {
+			r.Logf("Failed to record rebuild: %v", err)
+		}
+	}
+	a.buildObjectsMu.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("rebuild failed: %w", err)
+	}
+	a.pruneBookBuilds(record.CollID)
+
+	if result.Record.PagesSHA256 != record.PagesSHA256 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Rebuilt pages differ from build %d", params.BuildID))
+	}
+	for _, w := range result.Warnings {
+		r.Logf("Warning: %s", w)
+	}
+	r.Logf("Wrote %s", params.OutputPath)
+
+	return &BookExportResult{
+		Success:    result.Success,
+		OutputPath: result.OutputPath,
+		WorkCount:  result.WorkCount,
+		Warnings:   result.Warnings,
+	}, nil
+}
+
+// pruneBookBuilds keeps the newest builds of a collection and removes the
+// archived inputs no remaining build refers to. It is skipped while another
+// build is running, since that build's inputs may not be recorded yet; the
+// next build to finish catches up.
+func (a *App) pruneBookBuilds(collID int64) {
+	if !a.buildObjectsMu.TryLock() {
+		return
+	}
+	defer a.buildObjectsMu.Unlock()
+
+	if _, err := a.db.PruneBookBuilds(collID, bookbuild.MaxBuildsPerCollection); err != nil {
+		runtime.LogWarningf(a.ctx, "Failed to prune builds: %v", err)
+		return
+	}
+	live, err := a.db.BookBuildObjects()
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "Failed to list build objects: %v", err)
+		return
+	}
+	if _, err := backup.NewObjectStore(bookbuild.GetBuildObjectsDir()).Prune(live); err != nil {
+		runtime.LogWarningf(a.ctx, "Failed to prune build objects: %v", err)
+	}
+}
+
+func (a *App) getBookBuild(buildID int64) (*models.BookBuild, error) {
+	b, err := a.db.GetBookBuild(buildID)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, fmt.Errorf("build %d not found", buildID)
+	}
+	return b, nil
+}
@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/epub"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/jobs"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ExportBookEPUB exports a collection as an EPUB 3 file. It uses the same
+// manifest and front/back matter as the galley, reflowing each work's
+// document instead of its PDF.
+func (a *App) ExportBookEPUB(collID int64, htmlContent FrontBackMatterHTML) (*BookExportResult, error) {
+	startTime := time.Now()
+
+	a.OpenStatusBar()
+	defer a.CloseStatusBar()
+	a.EmitStatus("progress", "Making EPUB...")
+
+	book, err := a.db.GetBookByCollection(collID)
+	if err != nil || book == nil {
+		a.EmitStatus("error", "No book configuration found for collection")
+		return nil, fmt.Errorf("no book configuration found for collection")
+	}
+
+	coll, err := a.db.GetCollection(collID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	bookTitle := book.Title
+	if bookTitle == "" {
+		bookTitle = coll.CollectionName
+	}
+
+	defaultDir := ""
+	if book.ExportPath != nil && *book.ExportPath != "" {
+		defaultDir = *book.ExportPath
+	} else {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		defaultDir = filepath.Join(homeDir, "Desktop")
+	}
+
+	outputPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:            "Export Book as EPUB",
+		DefaultDirectory: defaultDir,
+		DefaultFilename:  sanitizeFilename(bookTitle) + ".epub",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "EPUB Files", Pattern: "*.epub"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open save dialog: %w", err)
+	}
+	if outputPath == "" {
+		return nil, nil
+	}
+
+	var result BookExportResult
+	_, err = a.jobs.Run(a.ctx, jobBookEPUB, "EPUB: "+bookTitle, bookEPUBParams{
+		CollID:     collID,
+		HTML:       htmlContent,
+		OutputPath: outputPath,
+	}, &result)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			a.EmitStatus("cancelled", "Build cancelled")
+			return nil, fmt.Errorf("build cancelled")
+		}
+		a.EmitStatus("error", fmt.Sprintf("EPUB export failed: %v", err))
+		return nil, err
+	}
+
+	a.EmitStatus("success", "EPUB created")
+	result.Duration = time.Since(startTime).Round(time.Millisecond).String()
+	return &result, nil
+}
+
+// bookEPUBParams are the params of a book-epub job
+type bookEPUBParams struct {
+	CollID     int64               `json:"collId"`
+	HTML       FrontBackMatterHTML `json:"html"`
+	OutputPath string              `json:"outputPath"`
+}
+
+// runBookEPUB writes a collection's EPUB. It shares the collection's build
+// cache with the galley, so it holds the same lock.
+func (a *App) runBookEPUB(ctx context.Context, job *models.Job, r *jobs.Reporter) (any, error) {
+	var params bookEPUBParams
+	if err := jobs.DecodeParams(job, &params); err != nil {
+		return nil, err
+	}
+	collID := params.CollID
+
+	unlock, err := a.lockCollectionBuild(ctx, collID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	book, err := a.db.GetBookByCollection(collID)
+	if err != nil || book == nil {
+		return nil, fmt.Errorf("no book configuration found for collection")
+	}
+	coll, err := a.db.GetCollection(collID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	manifest, err := a.buildManifestWithParts(collID, book, coll, bookbuild.GetCacheDir(collID), params.OutputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build manifest: %w", err)
+	}
+
+	front, back := params.HTML.epubSections()
+	result, err := epub.Build(epub.Options{
+		Ctx:         ctx,
+		Manifest:    manifest,
+		Metadata:    epub.MetadataFromBook(book),
+		FrontMatter: front,
+		BackMatter:  back,
+		CoverPath:   derefPath(book.FrontCoverPath),
+		OutputPath:  params.OutputPath,
+		OnProgress: func(stage string, current, total int, message string) {
+			a.emitExportProgress(stage, current, total, message)
+			a.EmitStatus("progress", message)
+			r.Progress(current, total, message)
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("epub export failed: %w", err)
+	}
+	for _, w := range result.Warnings {
+		r.Logf("Warning: %s", w)
+	}
+	r.Logf("Wrote %s", result.OutputPath)
+
+	return &BookExportResult{
+		Success:    true,
+		OutputPath: result.OutputPath,
+		WorkCount:  result.WorkCount,
+		Warnings:   result.Warnings,
+	}, nil
+}
+
+// epubSections splits the front and back matter pages in reading order,
+// skipping the empty ones
+func (h FrontBackMatterHTML) epubSections() (front, back []epub.Section) {
+	for _, s := range []epub.Section{
+		{Type: "titlepage", HTML: h.TitlePage},
+		{Type: "copyright", HTML: h.Copyright},
+		{Type: "dedication", HTML: h.Dedication},
+	} {
+		if s.HTML != "" {
+			front = append(front, s)
+		}
+	}
+	for _, s := range []epub.Section{
+		{Type: "afterword", HTML: h.Afterword},
+		{Type: "ack", HTML: h.Acknowledgements},
+		{Type: "about", HTML: h.AboutAuthor},
+	} {
+		if s.HTML != "" {
+			back = append(back, s)
+		}
+	}
+	return front, back
+}
@@ -1,6 +1,8 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -8,9 +10,10 @@ import (
 	"strings"
 	"time"
 
-	pdfcpuapi "github.com/pdfcpu/pdfcpu/pkg/api"
-
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/fileops"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/fts"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/jobs"
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -291,14 +294,6 @@ func (a *App) buildManifestWithParts(collID int64, book *models.Book, coll *mode
 		return nil, fmt.Errorf("failed to get collection works: %w", err)
 	}
 
-	if len(works) == 0 {
-		return nil, fmt.Errorf("collection has no works")
-	}
-
-	pdfPreviewPath := a.fileOps.Config.PDFPreviewPath
-
-	typography := bookbuild.DefaultTypography()
-
 	templatePath := ""
 	if book.TemplatePath != nil && *book.TemplatePath != "" {
 		templatePath = *book.TemplatePath
@@ -306,106 +301,41 @@ func (a *App) buildManifestWithParts(collID int64, book *models.Book, coll *mode
 		templatePath = a.fileOps.GetBookTemplatePath()
 	}
 
-	manifest := &bookbuild.Manifest{
-		Title:               book.Title,
-		Author:              book.Author,
-		OutputPath:          outputPath,
-		TemplatePath:        templatePath,
-		Typography:          typography,
-		WorksStartRecto:     book.WorksStartRecto == nil || *book.WorksStartRecto,
-		VersoHeader:         book.VersoHeader,
-		RectoHeader:         book.RectoHeader,
-		PageNumberPosition:  book.PageNumberPosition,
-		SuppressPageNumbers: book.SuppressPageNumbers,
+	var firstLines map[int64]string
+	if bookbuild.IncludesIndex(book) {
+		firstLines = a.workFirstLines(works)
 	}
 
-	if manifest.Title == "" {
-		manifest.Title = coll.CollectionName
-	}
+	return bookbuild.NewCollectionManifest(bookbuild.CollectionManifestOptions{
+		Works:          works,
+		Book:           book,
+		CollectionName: coll.CollectionName,
+		PDFPreviewPath: a.fileOps.Config.PDFPreviewPath,
+		BaseFolderPath: a.fileOps.Config.BaseFolderPath,
+		TemplatePath:   templatePath,
+		BuildDir:       buildDir,
+		OutputPath:     outputPath,
+		FirstLines:     firstLines,
+	})
+}
 
-	// Add individual front matter PDFs in order: titlepage, copyright, dedication
-	if fileExists(filepath.Join(buildDir, "titlepage.pdf")) {
-		manifest.FrontMatter = append(manifest.FrontMatter, bookbuild.FrontMatterItem{Type: "titlepage", PDF: filepath.Join(buildDir, "titlepage.pdf")})
-	}
-	if fileExists(filepath.Join(buildDir, "copyright.pdf")) {
-		manifest.FrontMatter = append(manifest.FrontMatter, bookbuild.FrontMatterItem{Type: "copyright", PDF: filepath.Join(buildDir, "copyright.pdf")})
-	}
-	if fileExists(filepath.Join(buildDir, "dedication.pdf")) {
-		manifest.FrontMatter = append(manifest.FrontMatter, bookbuild.FrontMatterItem{Type: "dedication", PDF: filepath.Join(buildDir, "dedication.pdf")})
+// workFirstLines looks up the works' opening lines in the search index.
+// Works that are not indexed have none.
+func (a *App) workFirstLines(works []models.CollectionWork) map[int64]string {
+	ftsDB := a.getFTSDB()
+	if !ftsDB.Exists() {
+		return nil
 	}
-
-	manifest.FrontMatter = append(manifest.FrontMatter, bookbuild.FrontMatterItem{Type: "toc", Placeholder: true})
-
-	var currentPart *bookbuild.Part
-	var prologueWorks []bookbuild.Work
-	hasParts := false
-
+	titles := make(map[int64]string, len(works))
 	for _, w := range works {
-		if w.IsSuppressed {
-			continue
-		}
-		if w.Type == workTypeSection {
-			hasParts = true
-			if currentPart != nil {
-				manifest.Parts = append(manifest.Parts, *currentPart)
-			}
-			partDividerPDF := filepath.Join(pdfPreviewPath, fmt.Sprintf("%d.pdf", w.WorkID))
-			currentPart = &bookbuild.Part{
-				ID:    w.WorkID,
-				Title: w.Title,
-				PDF:   partDividerPDF,
-				Works: []bookbuild.Work{},
-			}
-		} else if currentPart != nil {
-			pdfPath := filepath.Join(pdfPreviewPath, fmt.Sprintf("%d.pdf", w.WorkID))
-			currentPart.Works = append(currentPart.Works, bookbuild.Work{
-				ID:    w.WorkID,
-				Title: w.Title,
-				PDF:   pdfPath,
-			})
-		} else {
-			pdfPath := filepath.Join(pdfPreviewPath, fmt.Sprintf("%d.pdf", w.WorkID))
-			prologueWorks = append(prologueWorks, bookbuild.Work{
-				ID:    w.WorkID,
-				Title: w.Title,
-				PDF:   pdfPath,
-			})
-		}
-	}
-
-	if currentPart != nil {
-		manifest.Parts = append(manifest.Parts, *currentPart)
+		titles[w.WorkID] = w.Title
 	}
-
-	// Always create a part for prologue works - use NoDivider for section-less books
-	if len(prologueWorks) > 0 {
-		prologuePart := bookbuild.Part{
-			ID:        0,
-			Works:     prologueWorks,
-			NoDivider: !hasParts, // Skip divider page for section-less books
-		}
-		if hasParts {
-			prologuePart.Title = "Prologue"
-		}
-		manifest.Parts = append([]bookbuild.Part{prologuePart}, manifest.Parts...)
-	}
-
-	if len(manifest.Parts) == 0 {
-		return nil, fmt.Errorf("collection has no works")
-	}
-
-	// Add individual back matter PDFs in order: afterword, ack, about
-	if fileExists(filepath.Join(buildDir, "afterword.pdf")) {
-		manifest.BackMatter = append(manifest.BackMatter, bookbuild.BackMatterItem{Type: "afterword", PDF: filepath.Join(buildDir, "afterword.pdf")})
-	}
-	if fileExists(filepath.Join(buildDir, "ack.pdf")) {
-		manifest.BackMatter = append(manifest.BackMatter, bookbuild.BackMatterItem{Type: "ack", PDF: filepath.Join(buildDir, "ack.pdf")})
-	}
-	if fileExists(filepath.Join(buildDir, "about.pdf")) {
-		manifest.BackMatter = append(manifest.BackMatter, bookbuild.BackMatterItem{Type: "about", PDF: filepath.Join(buildDir, "about.pdf")})
+	lines, err := fts.NewSearcher(ftsDB, a.db.Conn()).FirstLines(titles)
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "Failed to read first lines: %v", err)
+		return nil
 	}
-
-	return manifest, nil
+	return lines
 }
 
 // ExportBookPDFWithParts exports a collection using the part-based pipeline
@@ -477,60 +407,140 @@ func (a *App) ExportBookPDFWithParts(collID int64, rebuildAll bool, htmlContent
 		_ = a.db.UpdateBook(book)
 	}
 
+	label := "Galley: " + bookTitle
+	if isBlind {
+		label = "Blind copy: " + bookTitle
+	}
+	var result BookExportResult
+	_, err = a.jobs.Run(a.ctx, jobBookBuild, label, bookBuildParams{
+		CollID:     collID,
+		RebuildAll: rebuildAll,
+		HTML:       htmlContent,
+		IsBlind:    isBlind,
+		OutputPath: outputPath,
+	}, &result)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			a.EmitStatus("cancelled", "Build cancelled")
+			return nil, fmt.Errorf("build cancelled")
+		}
+		a.EmitStatus("error", err.Error())
+		return nil, err
+	}
+
+	a.EmitStatus("success", "Galley created")
+	_ = exec.Command("open", outputPath).Start()
+
+	result.Duration = time.Since(startTime).Round(time.Millisecond).String()
+	return &result, nil
+}
+
+// bookBuildParams are the params of a book-build job. The output path is
+// chosen before the job is queued so a retried job writes the same file.
+type bookBuildParams struct {
+	CollID     int64               `json:"collId"`
+	RebuildAll bool                `json:"rebuildAll"`
+	HTML       FrontBackMatterHTML `json:"html"`
+	IsBlind    bool                `json:"isBlind"`
+	OutputPath string              `json:"outputPath"`
+}
+
+// runBookBuild builds a galley or blind copy with the part-based pipeline
+// and records the build
+func (a *App) runBookBuild(ctx context.Context, job *models.Job, r *jobs.Reporter) (any, error) {
+	startTime := time.Now()
+
+	var params bookBuildParams
+	if err := jobs.DecodeParams(job, &params); err != nil {
+		return nil, err
+	}
+	collID := params.CollID
+
+	unlock, err := a.lockCollectionBuild(ctx, collID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	book, err := a.db.GetBookByCollection(collID)
+	if err != nil || book == nil {
+		return nil, fmt.Errorf("no book configuration found for collection")
+	}
+	coll, err := a.db.GetCollection(collID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
 	cacheDir := bookbuild.GetCacheDir(collID)
 
 	a.emitExportProgress("Preparing", 1, 5, "Generating front/back matter...")
+	r.Progress(1, 5, "Generating front/back matter...")
 
-	if err := a.generateFrontBackMatterPDFs(cacheDir, htmlContent); err != nil {
-		a.EmitStatus("error", fmt.Sprintf("Front/back matter generation failed: %v", err))
+	if err := a.generateFrontBackMatterPDFs(cacheDir, params.HTML); err != nil {
 		return nil, fmt.Errorf("front/back matter generation failed: %w", err)
 	}
 
-	manifest, err := a.buildManifestWithParts(collID, book, coll, cacheDir, outputPath)
+	manifest, err := a.buildManifestWithParts(collID, book, coll, cacheDir, params.OutputPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build manifest: %w", err)
 	}
 
 	// Fail if manifest has no parts - don't silently fall back
 	if len(manifest.Parts) == 0 {
-		a.EmitStatus("error", "Manifest has no parts - check that works are not all suppressed")
 		return nil, fmt.Errorf("manifest has no parts - check that works are not all suppressed")
 	}
 
-	buildCtx := a.createBuildContext()
-	defer func() { a.buildCancel = nil }()
+	// A blind copy is scrubbed before it is hashed and recorded, and is
+	// not kept if that fails
+	var finish func(string) error
+	if params.IsBlind {
+		finish = func(path string) error {
+			report, err := fileops.AnonymizePDF(path)
+			if err != nil {
+				return fmt.Errorf("failed to anonymize PDF: %w", err)
+			}
+			if len(report.Removed) > 0 {
+				r.Logf("Anonymized: removed %s", strings.Join(report.Removed, ", "))
+			}
+			return nil
+		}
+	}
 
+	a.buildObjectsMu.RLock()
 	pipelineResult, err := bookbuild.BuildWithParts(bookbuild.PipelineOptions{
-		Ctx:          buildCtx,
+		Ctx:          ctx,
 		Manifest:     manifest,
 		CollectionID: collID,
 		CacheDir:     cacheDir,
-		OutputPath:   outputPath,
-		RebuildAll:   rebuildAll,
+		OutputPath:   params.OutputPath,
+		RebuildAll:   params.RebuildAll,
 		OnProgress: func(stage string, current, total int, message string) {
 			a.emitExportProgress(stage, current, total, message)
 			a.EmitStatus("progress", message)
+			r.Progress(current, total, message)
 		},
+		ConvertToPDF: func(ctx context.Context, src, dst string) error {
+			_, err := a.fileOps.ConvertToPDF(ctx, src, dst)
+			return err
+		},
+		Book:   book,
+		Finish: finish,
 	})
-
-	if err != nil {
-		if buildCtx.Err() != nil {
-			a.EmitStatus("cancelled", "Build cancelled")
-			return nil, fmt.Errorf("build cancelled")
+	if err == nil {
+		if err := a.db.AddBookBuild(pipelineResult.Record); err != nil {
+			r.Logf("Failed to record build: %v", err)
 		}
-		a.EmitStatus("error", fmt.Sprintf("Build failed: %v", err))
+	}
+	a.buildObjectsMu.RUnlock()
+	if err != nil {
 		return nil, fmt.Errorf("build failed: %w", err)
 	}
+	a.pruneBookBuilds(collID)
 
-	a.EmitStatus("success", "Galley created")
-
-	if isBlind {
-		if err := stripPDFMetadata(outputPath); err != nil {
-			runtime.LogWarningf(a.ctx, "Failed to strip PDF metadata: %v", err)
-		}
+	for _, w := range pipelineResult.Warnings {
+		r.Logf("Warning: %s", w)
 	}
-
-	_ = exec.Command("open", outputPath).Start()
+	r.Logf("Wrote %s", params.OutputPath)
 
 	return &BookExportResult{
 		Success:    pipelineResult.Success,
@@ -541,8 +551,38 @@ func (a *App) ExportBookPDFWithParts(collID int64, rebuildAll bool, htmlContent
 	}, nil
 }
 
+// lockCollectionBuild waits until no other build is using a collection's
+// part cache and returns the function that releases it. Builds of one
+// collection share bookbuild.GetCacheDir, so two at once would overwrite
+// each other's parts.
+func (a *App) lockCollectionBuild(ctx context.Context, collID int64) (func(), error) {
+	a.collBuildsMu.Lock()
+	if a.collBuilds == nil {
+		a.collBuilds = map[int64]chan struct{}{}
+	}
+	slot, ok := a.collBuilds[collID]
+	if !ok {
+		slot = make(chan struct{}, 1)
+		a.collBuilds[collID] = slot
+	}
+	a.collBuildsMu.Unlock()
+
+	select {
+	case slot <- struct{}{}:
+		return func() { <-slot }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // ClearPartCache clears cached PDFs for specific parts or all parts
 func (a *App) ClearPartCache(collID int64, partIDs []int64) error {
+	unlock, err := a.lockCollectionBuild(a.ctx, collID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	cacheDir := bookbuild.GetCacheDir(collID)
 
 	if len(partIDs) == 0 {
@@ -590,26 +630,6 @@ func sanitizeFilename(name string) string {
 	return result
 }
 
-// stripPDFMetadata removes identifying metadata from a PDF using pdfcpu
-func stripPDFMetadata(pdfPath string) error {
-	tmpPath := pdfPath + ".stripped"
-	props := map[string]string{
-		"Author":  "",
-		"Title":   "",
-		"Creator": "",
-		"Subject": "",
-	}
-	if err := pdfcpuapi.AddPropertiesFile(pdfPath, tmpPath, props, nil); err != nil {
-		os.Remove(tmpPath)
-		return fmt.Errorf("strip metadata: %w", err)
-	}
-	if err := os.Rename(tmpPath, pdfPath); err != nil {
-		os.Remove(tmpPath)
-		return fmt.Errorf("rename stripped pdf: %w", err)
-	}
-	return nil
-}
-
 // generateFrontBackMatterPDFs creates individual PDFs from HTML for each front/back matter page.
 // Empty HTML strings are skipped (no PDF generated for that page).
 func (a *App) generateFrontBackMatterPDFs(buildDir string, html FrontBackMatterHTML) error {
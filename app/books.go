@@ -172,39 +172,6 @@ type GalleyInfo struct {
 	ModTime   int64   `json:"modTime"` // Unix timestamp
 }
 
-// KDP cover dimension constants
-const (
-	kdpBleedMM              = 3.17   // 0.125 inches - universal for all sizes
-	kdpPremiumColorSpinePP  = 0.0596 // mm per page for premium color paper
-	kdpWhitePaperSpinePP    = 0.0572 // mm per page for white paper (B&W)
-	kdpCreamPaperSpinePP    = 0.0635 // mm per page for cream paper (B&W)
-	kdpStandardColorSpinePP = 0.0572 // mm per page for standard color paper
-)
-
-// TrimSizeDimensions holds the dimensions for a specific trim size
-type TrimSizeDimensions struct {
-	WidthMM       float64 // trim width in mm
-	HeightMM      float64 // trim height in mm
-	CoverHeightMM float64 // cover height (trim + 2*bleed)
-}
-
-// kdpTrimSizes maps trim size codes to their dimensions
-var kdpTrimSizes = map[string]TrimSizeDimensions{
-	"5x8":     {WidthMM: 127.0, HeightMM: 203.2, CoverHeightMM: 209.55}, // 5" x 8"
-	"5.5x8.5": {WidthMM: 139.7, HeightMM: 215.9, CoverHeightMM: 222.25}, // 5.5" x 8.5"
-	"6x9":     {WidthMM: 152.4, HeightMM: 228.6, CoverHeightMM: 234.95}, // 6" x 9"
-	"7x10":    {WidthMM: 177.8, HeightMM: 254.0, CoverHeightMM: 260.35}, // 7" x 10"
-	"8.5x11":  {WidthMM: 215.9, HeightMM: 279.4, CoverHeightMM: 285.75}, // 8.5" x 11"
-}
-
-// getTrimSizeDimensions returns dimensions for a trim size, defaulting to 6x9
-func getTrimSizeDimensions(trimSize string) TrimSizeDimensions {
-	if dims, ok := kdpTrimSizes[trimSize]; ok {
-		return dims
-	}
-	return kdpTrimSizes["6x9"]
-}
-
 // GetGalleyInfo returns information about the galley PDF including page count and calculated cover dimensions
 func (a *App) GetGalleyInfo(collID int64) (*GalleyInfo, error) {
 	book, err := a.db.GetBookByCollection(collID)
@@ -239,25 +206,8 @@ func (a *App) GetGalleyInfo(collID int64) (*GalleyInfo, error) {
 		return &GalleyInfo{Exists: true, Path: pdfPath, ModTime: info.ModTime().Unix()}, nil
 	}
 
-	// Calculate spine width based on paper type
-	spinePP := kdpPremiumColorSpinePP // default
-	switch book.PaperType {
-	case "premium-color":
-		spinePP = kdpPremiumColorSpinePP
-	case "standard-color":
-		spinePP = kdpStandardColorSpinePP
-	case "bw-white":
-		spinePP = kdpWhitePaperSpinePP
-	case "bw-cream":
-		spinePP = kdpCreamPaperSpinePP
-	}
-	spineMM := float64(pageCount) * spinePP
-
-	// Get trim size dimensions
-	trimDims := getTrimSizeDimensions(book.TrimSize)
-
-	// Calculate total cover width: back + spine + front + bleed on both sides
-	widthMM := trimDims.WidthMM + spineMM + trimDims.WidthMM + (kdpBleedMM * 2)
+	spineMM := bookbuild.SpineWidthMM(pageCount, book.PaperType)
+	widthMM, heightMM := bookbuild.CoverSizeMM(book.TrimSize, book.PaperType, pageCount)
 
 	return &GalleyInfo{
 		Exists:    true,
@@ -265,7 +215,7 @@ func (a *App) GetGalleyInfo(collID int64) (*GalleyInfo, error) {
 		PageCount: pageCount,
 		SpineMM:   spineMM,
 		WidthMM:   widthMM,
-		HeightMM:  trimDims.CoverHeightMM,
+		HeightMM:  heightMM,
 		ModTime:   info.ModTime().Unix(),
 	}, nil
 }
@@ -419,6 +369,65 @@ func (a *App) OpenCoverPDF(collID int64) (*CoverExportResult, error) {
 	}, nil
 }
 
+// GetCoverGeometry returns the wraparound cover layout for the book's trim
+// size and paper at the galley's current page count
+func (a *App) GetCoverGeometry(collID int64) (*bookbuild.CoverGeometry, error) {
+	book, err := a.db.GetBookByCollection(collID)
+	if err != nil || book == nil {
+		return nil, fmt.Errorf("no book configuration found for collection")
+	}
+	galleyInfo, err := a.GetGalleyInfo(collID)
+	if err != nil || !galleyInfo.Exists || galleyInfo.PageCount == 0 {
+		return nil, fmt.Errorf("galley PDF must be generated first to determine cover dimensions")
+	}
+	g := bookbuild.CoverGeometryForBook(book, galleyInfo.PageCount)
+	return &g, nil
+}
+
+// ExportCoverGuide saves a PDF or SVG guide showing the cover's bleed,
+// trim, folds, safe zones and barcode box for designers
+func (a *App) ExportCoverGuide(collID int64) (*CoverExportResult, error) {
+	geometry, err := a.GetCoverGeometry(collID)
+	if err != nil {
+		return &CoverExportResult{Success: false, Error: err.Error()}, nil
+	}
+	book, err := a.db.GetBookByCollection(collID)
+	if err != nil || book == nil {
+		return &CoverExportResult{Success: false, Error: "No book configuration found for collection"}, nil
+	}
+
+	defaultDir := derefPath(book.ExportPath)
+	if defaultDir == "" {
+		homeDir, _ := os.UserHomeDir()
+		defaultDir = filepath.Join(homeDir, "Desktop")
+	}
+	title := book.Title
+	if title == "" {
+		title = "book"
+	}
+
+	outputPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:            "Export Cover Guide",
+		DefaultDirectory: defaultDir,
+		DefaultFilename:  sanitizeFilename(title) + "-cover-guide.pdf",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "PDF Files", Pattern: "*.pdf"},
+			{DisplayName: "SVG Files", Pattern: "*.svg"},
+		},
+	})
+	if err != nil {
+		return &CoverExportResult{Success: false, Error: fmt.Sprintf("Failed to open save dialog: %v", err)}, nil
+	}
+	if outputPath == "" {
+		return nil, nil
+	}
+
+	if err := bookbuild.WriteCoverGuide(outputPath, *geometry); err != nil {
+		return &CoverExportResult{Success: false, Error: fmt.Sprintf("Failed to write cover guide: %v", err)}, nil
+	}
+	return &CoverExportResult{Success: true, OutputPath: outputPath}, nil
+}
+
 // GetCoverPDFPath returns the path to the cover PDF if it exists
 func (a *App) GetCoverPDFPath(collID int64) (string, error) {
 	book, err := a.db.GetBookByCollection(collID)
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/calendar"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/validation"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// calendarFeedHistory keeps recently passed deadlines visible in subscribed calendars
+const calendarFeedHistory = 90 * 24 * time.Hour
+
+func (a *App) GetSubmissionWindows(orgID int64) ([]models.SubmissionWindow, error) {
+	return a.db.ListSubmissionWindows(orgID)
+}
+
+func (a *App) CreateSubmissionWindow(w *models.SubmissionWindow) (*validation.ValidationResult, error) {
+	return a.db.CreateSubmissionWindow(w)
+}
+
+func (a *App) UpdateSubmissionWindow(w *models.SubmissionWindow) (*validation.ValidationResult, error) {
+	return a.db.UpdateSubmissionWindow(w)
+}
+
+func (a *App) DeleteSubmissionWindow(id int64) error {
+	return a.db.DeleteSubmissionWindow(id)
+}
+
+// GetCalendarEvents returns reading-period openings/closings and contest
+// deadlines between fromDate and toDate (YYYY-MM-DD). Empty dates default
+// to today and one year from today.
+func (a *App) GetCalendarEvents(fromDate, toDate string) ([]models.CalendarEvent, error) {
+	today := time.Now()
+	from, to := today, today.AddDate(1, 0, 0)
+
+	var err error
+	if fromDate != "" {
+		if from, err = time.Parse("2006-01-02", fromDate); err != nil {
+			return nil, fmt.Errorf("invalid from date: %w", err)
+		}
+	}
+	if toDate != "" {
+		if to, err = time.Parse("2006-01-02", toDate); err != nil {
+			return nil, fmt.Errorf("invalid to date: %w", err)
+		}
+	}
+
+	windows, err := a.db.ListSubmissionWindows(0)
+	if err != nil {
+		return nil, err
+	}
+	return calendar.Expand(windows, from, to, today), nil
+}
+
+// GetCalendarURL returns the local subscription URL for the iCalendar feed
+func (a *App) GetCalendarURL() string {
+	return fmt.Sprintf("http://127.0.0.1:%d/calendar.ics", a.GetFileServerPort())
+}
+
+// ExportCalendarICS saves the iCalendar feed to a file chosen by the user
+func (a *App) ExportCalendarICS() (string, error) {
+	defaultDir := a.settings.Get().ExportFolderPath
+	if defaultDir == "" {
+		homeDir, _ := os.UserHomeDir()
+		defaultDir = filepath.Join(homeDir, "Desktop")
+	}
+
+	outputPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:            "Export Submission Calendar",
+		DefaultDirectory: defaultDir,
+		DefaultFilename:  "works-calendar.ics",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "iCalendar Files", Pattern: "*.ics"},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open save dialog: %w", err)
+	}
+	if outputPath == "" {
+		return "", nil
+	}
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return "", fmt.Errorf("create calendar file: %w", err)
+	}
+	defer f.Close()
+
+	if err := a.writeCalendarICS(f); err != nil {
+		return "", err
+	}
+	return outputPath, nil
+}
+
+func (a *App) writeCalendarICS(w io.Writer) error {
+	if a.db == nil {
+		return fmt.Errorf("database not ready")
+	}
+	windows, err := a.db.ListSubmissionWindows(0)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	events := calendar.Expand(windows, now.Add(-calendarFeedHistory), now.AddDate(1, 0, 0), now)
+	return calendar.WriteICS(w, events, now)
+}
@@ -28,6 +28,19 @@ func (a *App) UpdateCollection(coll *models.Collection) (*validation.ValidationR
 	return a.db.UpdateCollection(coll)
 }
 
+// PreviewSmartQuery shows the first limit works, submissions or
+// organizations a smart query selects and how many there are, or where
+// the query is wrong
+func (a *App) PreviewSmartQuery(entity, query string, limit int) (*models.SmartQueryPreview, error) {
+	return a.db.PreviewSmartQuery(entity, query, limit, a.state.GetShowDeleted())
+}
+
+// SetCollectionSmartQuery saves a collection's smart query. An empty query
+// turns it back into a manual collection.
+func (a *App) SetCollectionSmartQuery(collID int64, query string) error {
+	return a.db.SetSmartQuery(collID, query)
+}
+
 func (a *App) AddWorkToCollection(collID, workID int64) error {
 	isSmart, err := a.db.IsSmartCollection(collID)
 	if err != nil {
@@ -58,6 +71,18 @@ func (a *App) GetCollectionWorks(collID int64) ([]models.CollectionWork, error)
 	return a.db.GetCollectionWorks(collID, a.state.GetShowDeleted())
 }
 
+// GetCollectionSubmissions returns what a saved query over submissions
+// selects now
+func (a *App) GetCollectionSubmissions(collID int64) ([]models.SubmissionView, error) {
+	return a.db.GetCollectionSubmissions(collID, a.state.GetShowDeleted())
+}
+
+// GetCollectionOrganizations returns what a saved query over
+// organizations selects now
+func (a *App) GetCollectionOrganizations(collID int64) ([]models.Organization, error) {
+	return a.db.GetCollectionOrganizations(collID, a.state.GetShowDeleted())
+}
+
 func (a *App) ReorderCollectionWorks(collID int64, workIDs []int64) error {
 	isSmart, err := a.db.IsSmartCollection(collID)
 	if err != nil {
@@ -258,20 +283,11 @@ func (a *App) ToggleCollectionSuppressed(collID int64) (bool, error) {
 		return false, fmt.Errorf("count suppressed works: %w", err)
 	}
 
-	newSuppressedValue := 1
-	if suppressedCount > 0 {
-		newSuppressedValue = 0
-	}
-
-	_, err = a.db.Conn().Exec(`
-		UPDATE CollectionDetails SET is_suppressed = ?
-		WHERE collID = ?
-	`, newSuppressedValue, collID)
-	if err != nil {
-		return false, fmt.Errorf("update suppressed: %w", err)
+	suppress := suppressedCount == 0
+	if err := a.db.SetCollectionSuppressed(collID, suppress); err != nil {
+		return false, err
 	}
-
-	return newSuppressedValue == 1, nil
+	return suppress, nil
 }
 
 // GetCollectionHasSuppressedWorks checks if any works in the collection are suppressed.
@@ -53,6 +53,7 @@ type DashboardStats struct {
 	YearProgress  YearProgressStats  `json:"yearProgress"`
 	RecentItems   []RecentItem       `json:"recentItems"`
 	PendingAlerts []PendingAlert     `json:"pendingAlerts"`
+	SavedQueries  []SavedQueryCount  `json:"savedQueries"`
 }
 
 type WorksStats struct {
@@ -117,6 +118,16 @@ type RecentItem struct {
 	CreatedAt  string `json:"createdAt"`
 }
 
+// SavedQueryCount is how many rows a saved query over submissions or
+// organizations selects now
+type SavedQueryCount struct {
+	CollID int64  `json:"collID"`
+	Name   string `json:"name"`
+	Entity string `json:"entity"`
+	Query  string `json:"query"`
+	Count  int    `json:"count"`
+}
+
 type PendingAlert struct {
 	SubmissionID int64  `json:"submissionID"`
 	WorkTitle    string `json:"workTitle"`
@@ -152,6 +163,9 @@ func (a *App) GetDashboardStats(timeframe string) (DashboardStats, error) {
 	// Pending alerts (submissions waiting 60+ days)
 	stats.PendingAlerts = a.getPendingAlerts(60)
 
+	// Saved queries over submissions and organizations
+	stats.SavedQueries = a.getSavedQueries()
+
 	return stats, nil
 }
 
@@ -523,6 +537,7 @@ func (a *App) getCollectionsStats(startDate, endDate string) CollectionsStats {
 		LEFT JOIN CollectionDetails cd ON c.collID = cd.collID
 		LEFT JOIN Works w ON cd.workID = w.workID
 		WHERE (c.attributes IS NULL OR c.attributes NOT LIKE '%deleted%')
+		  AND c.entity = 'works'
 		  AND (w.workID IS NULL OR w.attributes IS NULL OR w.attributes NOT LIKE '%deleted%')` + dateFilter + `
 		GROUP BY c.collID
 		ORDER BY cnt DESC
@@ -570,6 +585,7 @@ func (a *App) getCollectionsStats(startDate, endDate string) CollectionsStats {
 		LEFT JOIN Works w ON cd.workID = w.workID
 		WHERE (c.attributes IS NULL OR c.attributes NOT LIKE '%deleted%')
 		  AND (c.type IS NULL OR c.type != 'Book')
+		  AND c.entity = 'works'
 		  AND (w.workID IS NULL OR w.attributes IS NULL OR w.attributes NOT LIKE '%deleted%')` + dateFilter + `
 		GROUP BY c.collID
 		ORDER BY cnt DESC
@@ -701,3 +717,26 @@ func (a *App) getPendingAlerts(daysThreshold int) []PendingAlert {
 
 	return alerts
 }
+
+func (a *App) getSavedQueries() []SavedQueryCount {
+	saved := []SavedQueryCount{}
+
+	colls, err := a.db.ListCollections(false)
+	if err != nil {
+		return saved
+	}
+	for _, c := range colls {
+		if c.Entity == "works" || c.SmartQuery == nil {
+			continue
+		}
+		saved = append(saved, SavedQueryCount{
+			CollID: c.CollID,
+			Name:   c.CollectionName,
+			Entity: c.Entity,
+			Query:  *c.SmartQuery,
+			Count:  c.NItems,
+		})
+	}
+
+	return saved
+}
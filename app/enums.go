@@ -94,12 +94,7 @@ func (a *App) RenameFieldValue(table, column, oldValue, newValue string) (int64,
 		return 0, nil
 	}
 
-	query := fmt.Sprintf(`UPDATE %s SET %s = ? WHERE %s = ?`, table, column, column)
-	result, err := a.db.Conn().Exec(query, newValue, oldValue)
-	if err != nil {
-		return 0, fmt.Errorf("update failed: %w", err)
-	}
-	return result.RowsAffected()
+	return a.db.RenameFieldValue(table, column, oldValue, newValue)
 }
 
 // WorksFilterOptions contains distinct values from the Works table for filtering
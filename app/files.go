@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/fileops"
@@ -133,7 +134,41 @@ func (a *App) ExportToSubmissions(workID int64) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return a.fileOps.CopyToSubmissions(work)
+	dest, err := a.fileOps.CopyToSubmissions(work)
+	if err != nil {
+		return "", err
+	}
+
+	// Keep the submitted text so it can be compared with later drafts. The
+	// work's own file is captured; the copy may be changed before it is sent.
+	if src, err := a.fileOps.FindWorkFile(work); err == nil {
+		if _, err := a.revisions.Capture(work.WorkID, src, work.DocType, "copied for submission"); err != nil {
+			fmt.Printf(">>> Revision capture error: %v\n", err)
+		}
+	}
+	return dest, nil
+}
+
+// ExportBlindToSubmissions copies a Word document to the submissions
+// folder as ExportToSubmissions does, then anonymizes the copy for
+// journals that read blind. The report lists what was taken out.
+func (a *App) ExportBlindToSubmissions(workID int64) (*fileops.AnonymizeReport, error) {
+	work, err := a.db.GetWork(workID)
+	if err != nil {
+		return nil, err
+	}
+	src, err := a.fileOps.FindWorkFile(work)
+	if err != nil {
+		return nil, fmt.Errorf("source file not found: %w", err)
+	}
+	if !strings.EqualFold(filepath.Ext(src), ".docx") {
+		return nil, fmt.Errorf("only Word documents can be anonymized")
+	}
+	dest, err := a.ExportToSubmissions(workID)
+	if err != nil {
+		return nil, err
+	}
+	return fileops.AnonymizeDocx(dest, []string{a.settings.Get().AuthorName})
 }
 
 func (a *App) PrintWork(workID int64) error {
@@ -242,6 +277,11 @@ func (a *App) CheckLibreOffice() bool {
 	return a.fileOps.CheckLibreOffice()
 }
 
+// GetPDFConverters lists the preview converters in the order they are tried
+func (a *App) GetPDFConverters() []fileops.ConverterStatus {
+	return a.fileOps.Converters()
+}
+
 func (a *App) GetPreviewURL(workID int64) (string, error) {
 	work, err := a.db.GetWork(workID)
 	if err != nil {
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/fts"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/jobs"
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -71,40 +73,51 @@ func (a *App) FTSGetStatus() (*fts.Status, error) {
 }
 
 func (a *App) FTSBuildIndex() (*fts.BuildReport, error) {
-	db := a.getFTSDB()
-	builder := fts.NewIndexBuilder(db, a.db.Conn(), a.settings.Get().BaseFolderPath)
+	return a.runFTSJob(true)
+}
 
-	builder.SetProgressCallback(func(p fts.BuildProgress) {
-		runtime.EventsEmit(a.ctx, "fts:progress", p)
-	})
+func (a *App) FTSUpdateIndex() (*fts.BuildReport, error) {
+	return a.runFTSJob(false)
+}
 
-	runtime.EventsEmit(a.ctx, "fts:started", nil)
+type ftsIndexParams struct {
+	Full bool `json:"full"`
+}
 
-	report, err := builder.BuildFull()
-	if err != nil {
-		runtime.EventsEmit(a.ctx, "fts:error", err.Error())
+// runFTSJob queues a full or incremental index build and waits for it
+func (a *App) runFTSJob(full bool) (*fts.BuildReport, error) {
+	label := "Update search index"
+	if full {
+		label = "Rebuild search index"
+	}
+	var report fts.BuildReport
+	if _, err := a.jobs.Run(a.ctx, jobFTSIndex, label, ftsIndexParams{Full: full}, &report); err != nil {
 		return nil, err
 	}
+	return &report, nil
+}
 
-	if err := db.SetMeta("last_updated", time.Now().Format(time.RFC3339)); err != nil {
+func (a *App) runFTSIndex(ctx context.Context, job *models.Job, r *jobs.Reporter) (any, error) {
+	var params ftsIndexParams
+	if err := jobs.DecodeParams(job, &params); err != nil {
 		return nil, err
 	}
 
-	runtime.EventsEmit(a.ctx, "fts:complete", report)
-	return report, nil
-}
-
-func (a *App) FTSUpdateIndex() (*fts.BuildReport, error) {
 	db := a.getFTSDB()
 	builder := fts.NewIndexBuilder(db, a.db.Conn(), a.settings.Get().BaseFolderPath)
-
+	builder.SetContext(ctx)
 	builder.SetProgressCallback(func(p fts.BuildProgress) {
 		runtime.EventsEmit(a.ctx, "fts:progress", p)
+		r.Progress(p.Current, p.Total, p.CurrentFile)
 	})
 
 	runtime.EventsEmit(a.ctx, "fts:started", nil)
 
-	report, err := builder.UpdateIncremental()
+	build := builder.UpdateIncremental
+	if params.Full {
+		build = builder.BuildFull
+	}
+	report, err := build()
 	if err != nil {
 		runtime.EventsEmit(a.ctx, "fts:error", err.Error())
 		return nil, err
@@ -113,6 +126,10 @@ func (a *App) FTSUpdateIndex() (*fts.BuildReport, error) {
 	if err := db.SetMeta("last_updated", time.Now().Format(time.RFC3339)); err != nil {
 		return nil, err
 	}
+	for _, e := range report.Errors {
+		r.Logf("%s", e)
+	}
+	r.Logf("Indexed %d documents, %d words", report.DocumentCount, report.WordCount)
 
 	runtime.EventsEmit(a.ctx, "fts:complete", report)
 	return report, nil
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// auditRetention is how long history is kept for undo and version restore
+const auditRetention = 365 * 24 * time.Hour
+
+// GetEntityHistory returns every recorded change to one entity, newest first.
+// entityType is one of work, organization, submission, collection, note,
+// book or window.
+func (a *App) GetEntityHistory(entityType string, entityID int64) ([]models.AuditEntry, error) {
+	return a.db.GetEntityHistory(entityType, entityID)
+}
+
+// GetRecentOperations returns the most recent undoable operations
+func (a *App) GetRecentOperations(limit int) ([]models.AuditOperation, error) {
+	return a.db.ListAuditOperations(limit)
+}
+
+// Undo reverts the last n operations
+func (a *App) Undo(n int) ([]models.AuditOperation, error) {
+	ops, err := a.db.Undo(n)
+	a.notifyHistoryChanged("Undid", ops)
+	return ops, err
+}
+
+// Redo re-applies the last n undone operations
+func (a *App) Redo(n int) ([]models.AuditOperation, error) {
+	ops, err := a.db.Redo(n)
+	a.notifyHistoryChanged("Redid", ops)
+	return ops, err
+}
+
+// RestoreEntityVersion returns an entity to the state recorded by an audit entry
+func (a *App) RestoreEntityVersion(auditID int64) error {
+	if err := a.db.RestoreEntityVersion(auditID); err != nil {
+		return err
+	}
+	runtime.EventsEmit(a.ctx, "history:changed", nil)
+	return nil
+}
+
+// notifyHistoryChanged tells the frontend to reload whatever it is showing
+func (a *App) notifyHistoryChanged(verb string, ops []models.AuditOperation) {
+	if len(ops) == 0 {
+		return
+	}
+	msg := fmt.Sprintf("%s %s", verb, ops[0].Label)
+	if len(ops) > 1 {
+		msg = fmt.Sprintf("%s %d operations", verb, len(ops))
+	}
+	a.EmitStatus("success", msg)
+	runtime.EventsEmit(a.ctx, "history:changed", nil)
+}
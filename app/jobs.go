@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/jobs"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Kinds of background job
+const (
+	jobBookBuild          = "book-build"
+	jobBookRebuild        = "book-rebuild"
+	jobBookEPUB           = "book-epub"
+	jobFTSIndex           = "fts-index"
+	jobPDFRegenerate      = "pdf-regenerate"
+	jobCollectionAnalysis = "collection-analysis"
+)
+
+const jobWorkers = 2
+
+// startJobs creates the job queue, resuming jobs the last session left
+// running. Every change to a job is sent to the frontend as job:update.
+func (a *App) startJobs() {
+	a.jobs = jobs.New(a.db, jobWorkers)
+	a.jobs.Register(jobBookBuild, a.runBookBuild)
+	a.jobs.Register(jobBookRebuild, a.runBookRebuild)
+	a.jobs.Register(jobBookEPUB, a.runBookEPUB)
+	a.jobs.Register(jobFTSIndex, a.runFTSIndex)
+	a.jobs.Register(jobPDFRegenerate, a.runPDFRegenerate)
+	a.jobs.Register(jobCollectionAnalysis, a.runCollectionAnalysis)
+	a.jobs.OnUpdate(func(job models.Job) {
+		runtime.EventsEmit(a.ctx, "job:update", job)
+	})
+	if err := a.jobs.Start(a.ctx); err != nil {
+		fmt.Printf(">>> Job queue start error: %v\n", err)
+	}
+}
+
+// ListJobs returns jobs newest first, optionally only those with a status.
+// Params, results and logs are left out; use GetJob for those.
+func (a *App) ListJobs(status string, limit int) ([]models.Job, error) {
+	return a.db.ListJobs(status, limit)
+}
+
+// GetJob returns a job with its params, result and log
+func (a *App) GetJob(jobID int64) (*models.Job, error) {
+	job, err := a.db.GetJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("job %d not found", jobID)
+	}
+	return job, nil
+}
+
+// CancelJob stops a running job or drops a queued one
+func (a *App) CancelJob(jobID int64) error {
+	return a.jobs.Cancel(jobID)
+}
+
+// RetryJob queues a failed or cancelled job again
+func (a *App) RetryJob(jobID int64) (*models.Job, error) {
+	return a.jobs.Retry(jobID)
+}
+
+// RegeneratePDFs queues a job that regenerates the preview PDFs of works
+// whose documents are newer than their previews
+func (a *App) RegeneratePDFs(workIDs []int64) (*models.Job, error) {
+	if len(workIDs) == 0 {
+		return nil, fmt.Errorf("no works to regenerate")
+	}
+	label := fmt.Sprintf("Regenerate %d PDF previews", len(workIDs))
+	if len(workIDs) == 1 {
+		label = "Regenerate 1 PDF preview"
+	}
+	return a.jobs.Submit(jobPDFRegenerate, label, pdfRegenerateParams{WorkIDs: workIDs})
+}
+
+type pdfRegenerateParams struct {
+	WorkIDs []int64 `json:"workIds"`
+}
+
+// PDFRegenerateResult counts the outcome of a pdf-regenerate job
+type PDFRegenerateResult struct {
+	Regenerated int `json:"regenerated"`
+	UpToDate    int `json:"upToDate"`
+	Failed      int `json:"failed"`
+}
+
+func (a *App) runPDFRegenerate(ctx context.Context, job *models.Job, r *jobs.Reporter) (any, error) {
+	var params pdfRegenerateParams
+	if err := jobs.DecodeParams(job, &params); err != nil {
+		return nil, err
+	}
+
+	result := PDFRegenerateResult{}
+	for i, workID := range params.WorkIDs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		work, err := a.db.GetWork(workID)
+		if err != nil || work == nil || work.Path == nil {
+			r.Logf("Work %d: not found", workID)
+			result.Failed++
+			continue
+		}
+		r.Progress(i+1, len(params.WorkIDs), work.Title)
+
+		fullPath := a.fileOps.GetFilename(*work.Path)
+		if !a.fileOps.NeedsRegeneration(fullPath, workID) {
+			result.UpToDate++
+			continue
+		}
+		if _, err := a.fileOps.GeneratePDF(fullPath, workID); err != nil {
+			r.Logf("%s: %v", work.Title, err)
+			result.Failed++
+			continue
+		}
+		result.Regenerated++
+		runtime.EventsEmit(a.ctx, "preview:updated", workID)
+	}
+	r.Logf("Regenerated %d, up to date %d, failed %d", result.Regenerated, result.UpToDate, result.Failed)
+	return result, nil
+}
+
+// cancelJobsOfKind cancels the running and queued jobs of one kind
+func (a *App) cancelJobsOfKind(kind string) {
+	for _, status := range []string{models.JobRunning, models.JobQueued} {
+		list, err := a.db.ListJobs(status, 0)
+		if err != nil {
+			continue
+		}
+		for _, job := range list {
+			if job.Kind == kind {
+				_ = a.jobs.Cancel(job.JobID)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/backup"
+)
+
+// manuscriptSources lists the document of every work that has a path
+func (a *App) manuscriptSources() ([]backup.ManuscriptSource, error) {
+	works, err := a.db.ListWorks(false)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]backup.ManuscriptSource, 0, len(works))
+	for _, w := range works {
+		if w.Path == nil || *w.Path == "" {
+			continue
+		}
+		sources = append(sources, backup.ManuscriptSource{
+			WorkID: w.WorkID,
+			Title:  w.Title,
+			Path:   a.fileOps.GetFilename(*w.Path),
+		})
+	}
+	return sources, nil
+}
+
+// autoSnapshotManuscripts takes the daily manuscript snapshot in the background
+func (a *App) autoSnapshotManuscripts() {
+	sources, err := a.manuscriptSources()
+	if err != nil {
+		return
+	}
+	if _, err := a.backup.AutoSnapshotManuscripts(a.settings.Get().BaseFolderPath, sources); err != nil {
+		fmt.Printf(">>> Manuscript snapshot error: %v\n", err)
+	}
+}
+
+// SnapshotManuscripts archives every work's current document
+func (a *App) SnapshotManuscripts(label string) (*backup.ManuscriptSnapshotInfo, error) {
+	sources, err := a.manuscriptSources()
+	if err != nil {
+		return nil, err
+	}
+	return a.backup.SnapshotManuscripts(a.settings.Get().BaseFolderPath, sources, label)
+}
+
+func (a *App) GetManuscriptSnapshots() ([]backup.ManuscriptSnapshotInfo, error) {
+	return a.backup.ListManuscriptSnapshots()
+}
+
+func (a *App) GetManuscriptSnapshot(id string) (*backup.ManuscriptSnapshot, error) {
+	return a.backup.GetManuscriptSnapshot(id)
+}
+
+func (a *App) DeleteManuscriptSnapshot(id string) error {
+	return a.backup.DeleteManuscriptSnapshot(id)
+}
+
+// GetManuscriptHistory returns the distinct snapshotted versions of a work's document
+func (a *App) GetManuscriptHistory(workID int64) ([]backup.ManuscriptVersion, error) {
+	return a.backup.ManuscriptHistory(workID)
+}
+
+// RestoreWorkFile replaces a work's document with the version snapshotted at
+// or before date (YYYY-MM-DD, inclusive, or RFC 3339). The current file is
+// snapshotted first so the restore can itself be reversed.
+func (a *App) RestoreWorkFile(workID int64, date string) (*backup.ManuscriptVersion, error) {
+	at, err := parseRestoreDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	work, err := a.db.GetWork(workID)
+	if err != nil {
+		return nil, err
+	}
+	if work == nil {
+		return nil, fmt.Errorf("work not found")
+	}
+	if work.Path == nil || *work.Path == "" {
+		return nil, fmt.Errorf("work has no path")
+	}
+	fullPath := a.fileOps.GetFilename(*work.Path)
+
+	// Pick the version first so the snapshot taken below can't be chosen
+	version, err := a.backup.ManuscriptVersionAt(workID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	current := []backup.ManuscriptSource{{WorkID: work.WorkID, Title: work.Title, Path: fullPath}}
+	if _, err := a.backup.PreserveManuscripts(a.settings.Get().BaseFolderPath, current, "before restore"); err != nil {
+		return nil, fmt.Errorf("snapshot current file: %w", err)
+	}
+
+	if err := a.backup.WriteManuscriptVersion(version, fullPath); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+func parseRestoreDate(date string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, date); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date must be YYYY-MM-DD or RFC 3339: %s", date)
+	}
+	return day.Add(24*time.Hour - time.Second), nil
+}
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/fileops"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+// PacketRequest describes a submission packet: several works merged into
+// one document for one organization
+type PacketRequest struct {
+	OrgID          int64   `json:"orgID"`
+	WorkIDs        []int64 `json:"workIDs"`
+	IsBlind        bool    `json:"isBlind"`
+	IncludeHeader  bool    `json:"includeHeader"`
+	LogSubmissions bool    `json:"logSubmissions"`
+	SubmissionDate string  `json:"submissionDate,omitempty"` // YYYY-MM-DD; today when empty
+	SubmissionType string  `json:"submissionType,omitempty"`
+	Cost           float64 `json:"cost,omitempty"` // logged once, on the first work
+}
+
+// PacketResult reports what BuildSubmissionPacket made
+type PacketResult struct {
+	Packet        *models.SubmissionPacket `json:"packet,omitempty"`
+	DocPath       string                   `json:"docPath"`
+	PDFPath       string                   `json:"pdfPath,omitempty"`
+	SubmissionIDs []int64                  `json:"submissionIDs"`
+	Warnings      []string                 `json:"warnings"`
+	Removed       []string                 `json:"removed"` // what anonymizing a blind packet took out
+}
+
+// BuildSubmissionPacket merges the works into one document using the first
+// work's template, renders it to PDF, names both by the organization's
+// file-naming rule, numbered when an earlier packet has the same name,
+// and, when asked, logs a submission of each work linked to the packet.
+// Blind packets leave out the contact header and the author's name and
+// are anonymized before they are rendered.
+func (a *App) BuildSubmissionPacket(req PacketRequest) (*PacketResult, error) {
+	org, err := a.db.GetOrganization(req.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, fmt.Errorf("organization %d not found", req.OrgID)
+	}
+	if len(req.WorkIDs) == 0 {
+		return nil, fmt.Errorf("a packet needs at least one work")
+	}
+
+	var works []*models.Work
+	var parts []bookbuild.PacketWork
+	var titles []string
+	for _, id := range req.WorkIDs {
+		work, err := a.db.GetWork(id)
+		if err != nil {
+			return nil, err
+		}
+		if work == nil {
+			return nil, fmt.Errorf("work %d not found", id)
+		}
+		path, err := a.fileOps.FindWorkFile(work)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", work.Title, err)
+		}
+		if !strings.HasSuffix(strings.ToLower(path), ".docx") {
+			return nil, fmt.Errorf("%s is not a Word document", work.Title)
+		}
+		works = append(works, work)
+		parts = append(parts, bookbuild.PacketWork{Title: work.Title, Path: path})
+		titles = append(titles, work.Title)
+	}
+
+	templatePath, _ := a.GetWorkTemplatePath(works[0].WorkID)
+	if templatePath == "" {
+		if p := a.fileOps.GetTemplatePath(works[0].Type); fileExists(p) {
+			templatePath = p
+		} else {
+			templatePath = parts[0].Path
+		}
+	}
+
+	date := time.Now()
+	if req.SubmissionDate != "" {
+		if date, err = time.Parse("2006-01-02", req.SubmissionDate); err != nil {
+			return nil, fmt.Errorf("invalid submission date %q", req.SubmissionDate)
+		}
+	}
+
+	s := a.settings.Get()
+	if req.IsBlind && strings.TrimSpace(s.AuthorName) == "" {
+		return nil, fmt.Errorf("set the author name in Settings so it can be removed from a blind packet")
+	}
+	name := bookbuild.PacketName{Journal: org.Name, Titles: titles, Date: date}
+	var header []string
+	if !req.IsBlind {
+		name.Author = s.AuthorName
+		if req.IncludeHeader {
+			header = contactLines(s.ContactHeader)
+		}
+	}
+	rule := ""
+	if org.FileNaming != nil {
+		rule = *org.FileNaming
+	}
+	if err := os.MkdirAll(s.SubmissionExportPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	base := bookbuild.UniquePacketPath(s.SubmissionExportPath, bookbuild.PacketFileName(rule, name))
+
+	result := &PacketResult{DocPath: base + ".docx", SubmissionIDs: []int64{}}
+	result.Warnings, err = bookbuild.CreatePacketDocx(bookbuild.PacketDocxOptions{
+		TemplatePath: templatePath,
+		OutputPath:   result.DocPath,
+		Header:       header,
+		Works:        parts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("build packet: %w", err)
+	}
+	if result.Warnings == nil {
+		result.Warnings = []string{}
+	}
+	result.Removed = []string{}
+	if req.IsBlind {
+		report, err := fileops.AnonymizeDocx(result.DocPath, []string{s.AuthorName})
+		if err != nil {
+			os.Remove(result.DocPath)
+			return nil, fmt.Errorf("anonymize packet: %w", err)
+		}
+		result.Removed = append(result.Removed, report.Removed...)
+	}
+
+	if _, err := a.fileOps.ConvertToPDF(a.ctx, result.DocPath, base+".pdf"); err != nil {
+		result.Warnings = append(result.Warnings, "PDF not made: "+err.Error())
+	} else {
+		result.PDFPath = base + ".pdf"
+		if req.IsBlind {
+			// A converter may stamp the PDF with the name of the user running it
+			if report, err := fileops.AnonymizePDF(result.PDFPath); err != nil {
+				os.Remove(result.PDFPath)
+				result.PDFPath = ""
+				result.Warnings = append(result.Warnings, "PDF not kept: "+err.Error())
+			} else {
+				result.Removed = append(result.Removed, report.Removed...)
+			}
+		}
+	}
+
+	// Keep the submitted text of each work so it can be compared with later drafts
+	for i, work := range works {
+		if _, err := a.revisions.Capture(work.WorkID, parts[i].Path, work.DocType, "sent in a packet to "+org.Name); err != nil {
+			fmt.Printf(">>> Revision capture error: %v\n", err)
+		}
+	}
+
+	if !req.LogSubmissions {
+		return result, nil
+	}
+
+	packet := &models.SubmissionPacket{OrgID: org.OrgID, DocPath: result.DocPath, IsBlind: req.IsBlind}
+	if result.PDFPath != "" {
+		packet.PDFPath = &result.PDFPath
+	}
+	day := date.Format("2006-01-02")
+	subs := make([]*models.Submission, len(works))
+	for i, work := range works {
+		subs[i] = &models.Submission{WorkID: work.WorkID, SubmissionDate: &day}
+		if work.Draft != nil {
+			subs[i].Draft = *work.Draft
+		}
+		if req.SubmissionType != "" {
+			subs[i].SubmissionType = &req.SubmissionType
+		}
+	}
+	if req.Cost > 0 {
+		subs[0].Cost = &req.Cost
+	}
+
+	valid, err := a.db.CreateSubmissionPacket(packet, subs)
+	if err != nil {
+		return nil, err
+	}
+	if !valid.IsValid() {
+		return nil, fmt.Errorf("validation failed: %v", valid.Errors)
+	}
+	result.Packet = packet
+	for _, sub := range subs {
+		result.SubmissionIDs = append(result.SubmissionIDs, sub.SubmissionID)
+	}
+	return result, nil
+}
+
+// GetSubmissionPackets returns an organization's packets, newest first
+func (a *App) GetSubmissionPackets(orgID int64) ([]models.SubmissionPacket, error) {
+	return a.db.ListSubmissionPackets(orgID)
+}
+
+// GetPacketSubmissions returns the submissions logged for a packet
+func (a *App) GetPacketSubmissions(packetID int64) ([]models.SubmissionView, error) {
+	return a.db.ListPacketSubmissions(packetID, a.state.GetShowDeleted())
+}
+
+// contactLines splits the contact header setting into its non-blank lines
+func contactLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/recommend"
+)
+
+// RecommendSubmissions proposes (work, organization) pairs, best first,
+// each with the reasons behind its score
+func (a *App) RecommendSubmissions(opts recommend.Options) ([]recommend.Suggestion, error) {
+	in, err := a.db.RecommendInput(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return recommend.Recommend(in, opts), nil
+}
+
+// RecommendPackets proposes a packet of works for each organization that
+// can be sent in the same round
+func (a *App) RecommendPackets(opts recommend.Options) ([]recommend.Packet, error) {
+	in, err := a.db.RecommendInput(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return recommend.Packets(in, opts), nil
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/fileops"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/recommend"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
@@ -54,6 +55,8 @@ var reportDefinitions = []struct {
 	{"Organizations", "IconBuilding"},
 	{"Submissions", "IconSend"},
 	{"Data Quality", "IconAlertTriangle"},
+	{"Saved Queries", "IconFilter"},
+	{"Suggestions", "IconBulb"},
 }
 
 // GetReportNames returns the list of all report names in display order.
@@ -131,6 +134,10 @@ func (a *App) generateReport(name, icon string) {
 		category = a.reportOrganizationsIntegrity()
 	case "Data Quality":
 		category = a.reportDataQuality()
+	case "Saved Queries":
+		category = a.reportSavedQueries()
+	case "Suggestions":
+		category = a.reportSuggestions()
 	default:
 		category = ReportCategory{
 			Name:   name,
@@ -883,6 +890,83 @@ func (a *App) reportOrganizationsIntegrity() ReportCategory {
 	return ReportCategory{Name: "Organizations", Icon: "IconBuilding", Issues: issues, Checks: checks}
 }
 
+// reportSavedQueries lists what each saved query over submissions or
+// organizations selects now, one check per query
+func (a *App) reportSavedQueries() ReportCategory {
+	issues := make([]ReportIssue, 0)
+	checks := make([]string, 0)
+
+	colls, err := a.db.ListCollections(false)
+	if err != nil {
+		return ReportCategory{Name: "Saved Queries", Icon: "IconFilter", Issues: issues, Error: err.Error()}
+	}
+	for _, c := range colls {
+		if c.SmartQuery == nil {
+			continue
+		}
+		switch c.Entity {
+		case "submissions":
+			subs, err := a.db.GetCollectionSubmissions(c.CollID, false)
+			if err != nil {
+				continue
+			}
+			checks = append(checks, fmt.Sprintf("%s: %s", c.CollectionName, *c.SmartQuery))
+			for _, s := range subs {
+				issues = append(issues, ReportIssue{
+					ID:          s.SubmissionID,
+					Description: c.CollectionName,
+					EntityType:  "submission",
+					EntityID:    s.SubmissionID,
+					EntityName:  fmt.Sprintf("%s → %s", s.TitleOfWork, s.JournalName),
+				})
+			}
+		case "organizations":
+			orgs, err := a.db.GetCollectionOrganizations(c.CollID, false)
+			if err != nil {
+				continue
+			}
+			checks = append(checks, fmt.Sprintf("%s: %s", c.CollectionName, *c.SmartQuery))
+			for _, o := range orgs {
+				issues = append(issues, ReportIssue{
+					ID:          o.OrgID,
+					Description: c.CollectionName,
+					EntityType:  "organization",
+					EntityID:    o.OrgID,
+					EntityName:  o.Name,
+				})
+			}
+		}
+	}
+
+	return ReportCategory{Name: "Saved Queries", Icon: "IconFilter", Issues: issues, Checks: checks}
+}
+
+// reportSuggestions lists the packets the recommendation engine would
+// send this round, one line per work
+func (a *App) reportSuggestions() ReportCategory {
+	issues := make([]ReportIssue, 0)
+
+	packets, err := a.RecommendPackets(recommend.Options{})
+	if err != nil {
+		return ReportCategory{Name: "Suggestions", Icon: "IconBulb", Issues: issues, Error: err.Error()}
+	}
+	checks := make([]string, 0, len(packets))
+	for _, p := range packets {
+		checks = append(checks, fmt.Sprintf("%s: %d work(s), score %d", p.OrgName, len(p.Works), p.Score))
+		for _, s := range p.Works {
+			issues = append(issues, ReportIssue{
+				ID:          s.WorkID,
+				Description: fmt.Sprintf("Send to %s (score %d)", s.OrgName, s.Score),
+				EntityType:  "work",
+				EntityID:    s.WorkID,
+				EntityName:  s.Title,
+			})
+		}
+	}
+
+	return ReportCategory{Name: "Suggestions", Icon: "IconBulb", Issues: issues, Checks: checks}
+}
+
 func (a *App) reportDataQuality() ReportCategory {
 	issues := make([]ReportIssue, 0)
 	checks := []string{
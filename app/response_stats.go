@@ -0,0 +1,24 @@
+package app
+
+import "github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+
+// GetOrgResponseStats returns response-time and outcome aggregates for one organization
+func (a *App) GetOrgResponseStats(orgID int64) (*models.OrgResponseStats, error) {
+	return a.db.GetOrgResponseStats(orgID)
+}
+
+// GetAllOrgResponseStats returns aggregates for every organization with submissions
+func (a *App) GetAllOrgResponseStats() ([]models.OrgResponseStats, error) {
+	return a.db.ListOrgResponseStats()
+}
+
+// GetOverdueSubmissions returns pending submissions that have waited longer
+// than the organization usually takes to respond
+func (a *App) GetOverdueSubmissions() ([]models.OverdueSubmission, error) {
+	return a.db.ListOverdueSubmissions()
+}
+
+// RefreshResponseStats rebuilds the cached aggregates for all organizations
+func (a *App) RefreshResponseStats() error {
+	return a.db.RefreshAllOrgResponseStats()
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/revisions"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/textdiff"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// handleRevisionCapture records a watched document change as a new revision
+func (a *App) handleRevisionCapture(workID int64, filePath string) {
+	work, err := a.db.GetWork(workID)
+	if err != nil || work == nil {
+		return
+	}
+
+	r, err := a.revisions.Capture(workID, filePath, work.DocType, "")
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "watcher:error", fmt.Sprintf("Revision capture failed: %v", err))
+		return
+	}
+	if r != nil {
+		runtime.EventsEmit(a.ctx, "revisions:changed", workID)
+	}
+}
+
+// syncRevisions records documents edited while the app was closed and drops
+// stored files no revision needs
+func (a *App) syncRevisions() {
+	works, err := a.db.ListWorks(false)
+	if err != nil {
+		return
+	}
+
+	sources := make([]revisions.Source, 0, len(works))
+	for _, w := range works {
+		if w.Path == nil || *w.Path == "" {
+			continue
+		}
+		sources = append(sources, revisions.Source{
+			WorkID:  w.WorkID,
+			Path:    a.fileOps.GetFilename(*w.Path),
+			DocType: w.DocType,
+		})
+	}
+
+	if _, err := a.revisions.Sync(sources); err != nil {
+		fmt.Printf(">>> Revision sync error: %v\n", err)
+	}
+	_, _ = a.revisions.Prune()
+}
+
+// GetWorkRevisions returns a work's document revisions, newest first
+func (a *App) GetWorkRevisions(workID int64) ([]models.WorkRevision, error) {
+	return a.revisions.List(workID)
+}
+
+// DiffWorkRevisions compares the text of two revisions of the same work
+func (a *App) DiffWorkRevisions(fromRevisionID, toRevisionID int64) (*textdiff.Result, error) {
+	return a.revisions.Diff(fromRevisionID, toRevisionID, textdiff.Options{Context: -1})
+}
+
+// CompareWorkVersions diffs two versions of a work's document, each the
+// current file, a stored revision or the submission copy, paragraph by
+// paragraph and word by word. context is the number of unchanged paragraphs
+// kept around each hunk; negative returns the whole document.
+func (a *App) CompareWorkVersions(workID int64, from, to revisions.Version, context int) (*textdiff.Result, error) {
+	loc, err := a.revisionLocations(workID)
+	if err != nil {
+		return nil, err
+	}
+	return a.revisions.Compare(workID, from, to, loc, textdiff.Options{Context: context})
+}
+
+// RenderWorkDiff renders CompareWorkVersions as "html" or "markdown"
+func (a *App) RenderWorkDiff(workID int64, from, to revisions.Version, context int, format string) (string, error) {
+	result, err := a.CompareWorkVersions(workID, from, to, context)
+	if err != nil {
+		return "", err
+	}
+	switch format {
+	case "html":
+		return textdiff.RenderHTML(result), nil
+	case "markdown", "md":
+		return textdiff.RenderMarkdown(result), nil
+	}
+	return "", fmt.Errorf("unknown diff format: %s", format)
+}
+
+func (a *App) revisionLocations(workID int64) (revisions.Locations, error) {
+	work, err := a.db.GetWork(workID)
+	if err != nil {
+		return revisions.Locations{}, err
+	}
+	if work == nil {
+		return revisions.Locations{}, fmt.Errorf("work not found")
+	}
+	if work.Path == nil || *work.Path == "" {
+		return revisions.Locations{}, fmt.Errorf("work has no path")
+	}
+	return revisions.Locations{
+		Current: a.fileOps.GetFilename(*work.Path),
+		Copy:    a.fileOps.SubmissionCopyPath(work),
+		DocType: work.DocType,
+	}, nil
+}
+
+// RestoreWorkRevision replaces a work's document with a stored revision. The
+// current file is captured first so the restore can itself be reversed.
+func (a *App) RestoreWorkRevision(revisionID int64) (*models.WorkRevision, error) {
+	rev, err := a.db.GetWorkRevision(revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	work, err := a.db.GetWork(rev.WorkID)
+	if err != nil {
+		return nil, err
+	}
+	if work == nil {
+		return nil, fmt.Errorf("work not found")
+	}
+	if work.Path == nil || *work.Path == "" {
+		return nil, fmt.Errorf("work has no path")
+	}
+	fullPath := a.fileOps.GetFilename(*work.Path)
+
+	if _, err := a.revisions.Capture(work.WorkID, fullPath, work.DocType, "before restore"); err != nil {
+		return nil, fmt.Errorf("capture current file: %w", err)
+	}
+	if _, err := a.revisions.Restore(revisionID, fullPath); err != nil {
+		return nil, err
+	}
+
+	restored, err := a.revisions.Capture(work.WorkID, fullPath, work.DocType, fmt.Sprintf("restored revision %d", revisionID))
+	if err != nil {
+		return nil, err
+	}
+	if restored == nil {
+		// The document already matched the revision
+		restored = rev
+	}
+
+	a.EmitStatus("success", fmt.Sprintf("Restored %s to revision %d", work.Title, revisionID))
+	runtime.EventsEmit(a.ctx, "revisions:changed", work.WorkID)
+	return restored, nil
+}
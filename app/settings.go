@@ -4,6 +4,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/backup"
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/settings"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -47,6 +48,7 @@ func (a *App) UpdateSettings(s settings.Settings) error {
 		return err
 	}
 	a.reloadFileOpsConfig()
+	a.backup.SetOptions(backup.OptionsFromSettings(s))
 	return nil
 }
 
@@ -71,6 +73,10 @@ func (a *App) reloadFileOpsConfig() {
 	a.fileOps.Config.PDFPreviewPath = s.PDFPreviewPath
 	a.fileOps.Config.SubmissionExportPath = s.SubmissionExportPath
 	a.fileOps.Config.TemplateFolderPath = s.TemplateFolderPath
+	a.fileOps.Config.LibreOfficePath = s.LibreOfficePath
+	a.fileOps.Config.ConverterPriority = s.PDFConverterPriority
+	a.fileOps.Config.MaxConversions = s.PDFMaxConversions
+	a.fileOps.ReloadConverters()
 }
 
 func (a *App) DetectLibreOffice() string {
@@ -307,12 +307,7 @@ func (a *App) GetWorkMarked(workID int64) (bool, error) {
 
 // SetWorkSkipAudits sets or clears the skip_audits flag for a work
 func (a *App) SetWorkSkipAudits(workID int64, skip bool) error {
-	skipVal := 0
-	if skip {
-		skipVal = 1
-	}
-	_, err := a.db.Conn().Exec("UPDATE Works SET skip_audits = ? WHERE workID = ?", skipVal, workID)
-	return err
+	return a.db.SetWorkSkipAudits(workID, skip)
 }
 
 // GetWorkSkipAudits checks if a work has skip_audits enabled
@@ -4,6 +4,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/db"
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/validation"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func (a *App) GetSubmissions() ([]models.Submission, error) {
@@ -35,11 +36,45 @@ func (a *App) GetSubmissionViewsByCollection(collID int64) ([]models.SubmissionV
 }
 
 func (a *App) CreateSubmission(sub *models.Submission) (*validation.ValidationResult, error) {
-	return a.db.CreateSubmission(sub)
+	result, err := a.db.CreateSubmission(sub)
+	if err == nil && result.IsValid() && sub.IsAccepted() {
+		a.notifyAcceptance(sub.SubmissionID)
+	}
+	return result, err
 }
 
 func (a *App) UpdateSubmission(sub *models.Submission) (*validation.ValidationResult, error) {
-	return a.db.UpdateSubmission(sub)
+	prev, _ := a.db.GetSubmission(sub.SubmissionID)
+	result, err := a.db.UpdateSubmission(sub)
+	if err == nil && result.IsValid() && sub.IsAccepted() && (prev == nil || !prev.IsAccepted()) {
+		a.notifyAcceptance(sub.SubmissionID)
+	}
+	return result, err
+}
+
+// GetWithdrawalChecklist returns the pending submissions that must be
+// withdrawn because submissionID was accepted
+func (a *App) GetWithdrawalChecklist(submissionID int64) (*models.WithdrawalChecklist, error) {
+	return a.db.GetWithdrawalChecklist(submissionID)
+}
+
+// WithdrawConflictingSubmissions marks the selected checklist entries (all
+// of them if none are given) as withdrawn on date and notes why
+func (a *App) WithdrawConflictingSubmissions(acceptedID int64, submissionIDs []int64, date string) (*models.WithdrawalResult, error) {
+	return a.db.WithdrawConflictingSubmissions(acceptedID, submissionIDs, date)
+}
+
+// notifyAcceptance tells the frontend when a new acceptance leaves
+// simultaneous submissions that need to be withdrawn
+func (a *App) notifyAcceptance(submissionID int64) {
+	if a.ctx == nil {
+		return
+	}
+	checklist, err := a.db.GetWithdrawalChecklist(submissionID)
+	if err != nil || len(checklist.Items) == 0 {
+		return
+	}
+	runtime.EventsEmit(a.ctx, "submission:accepted", checklist)
 }
 
 func (a *App) DeleteSubmission(id int64) error {
@@ -5,7 +5,7 @@ import (
 	"os"
 	"strings"
 
-	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/kdp"
 )
 
 // ValidationResult holds the outcome of a validation check
@@ -15,11 +15,25 @@ type ValidationResult struct {
 	Warnings []string `json:"warnings"`
 }
 
-// PublicationReadiness holds validation status for all three areas
+// addFindings folds KDP preflight findings into the result
+func (r *ValidationResult) addFindings(findings []kdp.Finding) {
+	for _, f := range findings {
+		if f.Severity == kdp.SeverityError {
+			r.Errors = append(r.Errors, f.Message)
+			r.Passed = false
+		} else {
+			r.Warnings = append(r.Warnings, f.Message)
+		}
+	}
+}
+
+// PublicationReadiness holds validation status for all three areas, and
+// the KDP preflight of the galley and cover when a galley has been built
 type PublicationReadiness struct {
-	Content ValidationResult `json:"content"`
-	Matter  ValidationResult `json:"matter"`
-	Cover   ValidationResult `json:"cover"`
+	Content   ValidationResult `json:"content"`
+	Matter    ValidationResult `json:"matter"`
+	Cover     ValidationResult `json:"cover"`
+	Preflight *kdp.Report      `json:"preflight,omitempty"`
 }
 
 // joinTitles joins work titles for display in error messages
@@ -35,6 +49,13 @@ func joinTitles(titles []string) string {
 
 // ValidateContent checks if the collection's works are publication-ready
 func (a *App) ValidateContent(collID int64) (*ValidationResult, error) {
+	result, _, err := a.validateContent(collID)
+	return result, err
+}
+
+// validateContent also returns the galley preflight, nil when there is no
+// galley to check
+func (a *App) validateContent(collID int64) (*ValidationResult, *kdp.Report, error) {
 	result := &ValidationResult{
 		Passed:   true,
 		Errors:   []string{},
@@ -44,7 +65,7 @@ func (a *App) ValidateContent(collID int64) (*ValidationResult, error) {
 	// Check works exist
 	works, err := a.db.GetCollectionWorks(collID, false)
 	if err != nil {
-		return nil, fmt.Errorf("get works: %w", err)
+		return nil, nil, fmt.Errorf("get works: %w", err)
 	}
 	if len(works) == 0 {
 		result.Errors = append(result.Errors, "Collection has no works")
@@ -54,7 +75,7 @@ func (a *App) ValidateContent(collID int64) (*ValidationResult, error) {
 	// Check book/template exists
 	book, err := a.db.GetBookByCollection(collID)
 	if err != nil {
-		return nil, fmt.Errorf("get book: %w", err)
+		return nil, nil, fmt.Errorf("get book: %w", err)
 	}
 	if book == nil || book.TemplatePath == nil || *book.TemplatePath == "" {
 		result.Errors = append(result.Errors, "No template selected")
@@ -64,7 +85,7 @@ func (a *App) ValidateContent(collID int64) (*ValidationResult, error) {
 	// Style audit
 	audit, err := a.AuditCollectionStyles(collID)
 	if err != nil {
-		return nil, fmt.Errorf("style audit: %w", err)
+		return nil, nil, fmt.Errorf("style audit: %w", err)
 	}
 	if audit.DirtyWorks > 0 {
 		var dirtyTitles []string
@@ -80,7 +101,7 @@ func (a *App) ValidateContent(collID int64) (*ValidationResult, error) {
 	// Heading analysis
 	headings, err := a.AnalyzeCollectionHeadings(collID)
 	if err != nil {
-		return nil, fmt.Errorf("heading analysis: %w", err)
+		return nil, nil, fmt.Errorf("heading analysis: %w", err)
 	}
 	if headings.Failed > 0 {
 		var failedTitles []string
@@ -94,26 +115,22 @@ func (a *App) ValidateContent(collID int64) (*ValidationResult, error) {
 	}
 
 	// Check galley PDF
+	var report *kdp.Report
 	galleyInfo, err := a.GetGalleyInfo(collID)
 	if err != nil {
 		result.Warnings = append(result.Warnings, fmt.Sprintf("Could not check galley: %v", err))
 	} else if !galleyInfo.Exists {
 		result.Warnings = append(result.Warnings, "Galley PDF not yet generated")
-	} else {
-		// Check font embedding in the galley PDF
-		fontIssues, err := bookbuild.CheckFontsEmbedded(galleyInfo.Path)
+	} else if book != nil {
+		report, err = kdp.CheckGalley(galleyInfo.Path, kdp.SpecFromBook(book))
 		if err != nil {
-			result.Warnings = append(result.Warnings, fmt.Sprintf("Could not check font embedding: %v", err))
-		} else if len(fontIssues) > 0 {
-			for _, issue := range fontIssues {
-				result.Errors = append(result.Errors,
-					fmt.Sprintf("Non-embedded font: %s (%s) - will fail KDP print", issue.FontName, issue.FontType))
-			}
-			result.Passed = false
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Could not preflight galley: %v", err))
+		} else {
+			result.addFindings(report.Galley)
 		}
 	}
 
-	return result, nil
+	return result, report, nil
 }
 
 // ValidateMatter checks if the book's front/back matter is publication-ready
@@ -168,6 +185,13 @@ func (a *App) ValidateMatter(collID int64) (*ValidationResult, error) {
 
 // ValidateCover checks if the book's cover is publication-ready
 func (a *App) ValidateCover(collID int64) (*ValidationResult, error) {
+	result, _, err := a.validateCover(collID)
+	return result, err
+}
+
+// validateCover also returns the cover preflight findings, nil when there
+// is no cover PDF and galley to check
+func (a *App) validateCover(collID int64) (*ValidationResult, []kdp.Finding, error) {
 	result := &ValidationResult{
 		Passed:   true,
 		Errors:   []string{},
@@ -176,12 +200,12 @@ func (a *App) ValidateCover(collID int64) (*ValidationResult, error) {
 
 	book, err := a.db.GetBookByCollection(collID)
 	if err != nil {
-		return nil, fmt.Errorf("get book: %w", err)
+		return nil, nil, fmt.Errorf("get book: %w", err)
 	}
 	if book == nil {
 		result.Errors = append(result.Errors, "No book configuration found")
 		result.Passed = false
-		return result, nil
+		return result, nil, nil
 	}
 
 	// Front cover image
@@ -216,6 +240,7 @@ func (a *App) ValidateCover(collID int64) (*ValidationResult, error) {
 	}
 
 	// Cover PDF warning
+	var findings []kdp.Finding
 	if book.CoverPath == nil || *book.CoverPath == "" {
 		result.Warnings = append(result.Warnings, "Cover PDF not yet generated")
 	} else {
@@ -231,15 +256,19 @@ func (a *App) ValidateCover(collID int64) (*ValidationResult, error) {
 					result.Passed = false
 				}
 			}
+			if galleyErr == nil && galleyInfo.PageCount > 0 {
+				findings = kdp.CheckCover(*book.CoverPath, kdp.SpecFromBook(book), galleyInfo.PageCount)
+				result.addFindings(findings)
+			}
 		}
 	}
 
-	return result, nil
+	return result, findings, nil
 }
 
 // GetPublicationReadiness returns validation status for all three areas
 func (a *App) GetPublicationReadiness(collID int64) (*PublicationReadiness, error) {
-	content, err := a.ValidateContent(collID)
+	content, report, err := a.validateContent(collID)
 	if err != nil {
 		return nil, fmt.Errorf("validate content: %w", err)
 	}
@@ -249,14 +278,18 @@ func (a *App) GetPublicationReadiness(collID int64) (*PublicationReadiness, erro
 		return nil, fmt.Errorf("validate matter: %w", err)
 	}
 
-	cover, err := a.ValidateCover(collID)
+	cover, coverFindings, err := a.validateCover(collID)
 	if err != nil {
 		return nil, fmt.Errorf("validate cover: %w", err)
 	}
+	if report != nil && coverFindings != nil {
+		report.Cover = coverFindings
+	}
 
 	return &PublicationReadiness{
-		Content: *content,
-		Matter:  *matter,
-		Cover:   *cover,
+		Content:   *content,
+		Matter:    *matter,
+		Cover:     *cover,
+		Preflight: report,
 	}, nil
 }
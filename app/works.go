@@ -177,17 +177,7 @@ func (a *App) BatchUpdateWorkField(workIDs []int64, field string, value string)
 	if len(workIDs) == 0 {
 		return 0, nil
 	}
-
-	updated := 0
-	for _, id := range workIDs {
-		query := fmt.Sprintf("UPDATE Works SET %s = ? WHERE workID = ?", dbField)
-		_, err := a.db.Conn().Exec(query, value, id)
-		if err != nil {
-			return updated, fmt.Errorf("update work %d: %w", id, err)
-		}
-		updated++
-	}
-	return updated, nil
+	return a.db.UpdateWorksField(workIDs, dbField, value)
 }
 
 // DuplicateWork creates a copy of a work with a unique title.
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/server"
+)
+
+// apiServe runs the REST API in the foreground until interrupted
+func apiServe(e *env, args []string) error {
+	fs := flag.NewFlagSet("api serve", flag.ContinueOnError)
+	addr := fs.String("addr", "127.0.0.1:7474", "listen address")
+	token := fs.String("token", os.Getenv("WORKS_API_TOKEN"), "bearer token required on every request (generated when empty)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	api := server.NewAPI(database, *addr, *token)
+	port, err := api.Start()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Serving Works API on port %d (Ctrl-C to stop)\n", port)
+	if *token == "" {
+		fmt.Fprintf(os.Stderr, "Token: %s\n", api.Token())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	<-ctx.Done()
+
+	return api.Stop()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func backupList(e *env, _ []string) error {
+	backups, err := e.backupManager().ListBackups()
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(backups))
+	for _, b := range backups {
+		rows = append(rows, []string{b.Name, b.Format, strconv.FormatInt(b.Size, 10), b.CreatedAt})
+	}
+	return e.emit(backups, []string{"NAME", "FORMAT", "SIZE", "CREATED"}, rows)
+}
+
+func backupCreate(e *env, args []string) error {
+	info, err := e.backupManager().CreateBackup(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+
+	if e.jsonOut {
+		return printJSON(info)
+	}
+	fmt.Printf("Created %s\n", info.Path)
+	return nil
+}
+
+func backupVerify(e *env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("backup path is required")
+	}
+
+	info, err := e.backupManager().VerifyBackup(args[0])
+	if err != nil {
+		return err
+	}
+
+	if e.jsonOut {
+		return printJSON(info)
+	}
+	fmt.Printf("OK %s (%s)\n", info.Name, strings.Join(info.Contents, ", "))
+	return nil
+}
+
+func backupRestore(e *env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("backup path is required")
+	}
+
+	if err := e.backupManager().RestoreBackup(args[0]); err != nil {
+		return err
+	}
+
+	if e.jsonOut {
+		return printJSON(map[string]string{"restored": args[0]})
+	}
+	fmt.Printf("Restored %s; quit and restart the desktop app if it is running\n", args[0])
+	return nil
+}
+
+func dbMigrate(e *env, _ []string) error {
+	if _, err := e.openDB(); err != nil {
+		return err
+	}
+	if e.jsonOut {
+		return printJSON(map[string]string{"status": "ok"})
+	}
+	fmt.Println("Database is up to date")
+	return nil
+}
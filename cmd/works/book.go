@@ -0,0 +1,528 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/epub"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/fts"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/kdp"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+// bookBuild runs the same part-based pipeline as the desktop galley export.
+// Front and back matter are taken from the collection's build cache, i.e.
+// whatever the desktop app rendered on its last export.
+func bookBuild(e *env, args []string) error {
+	collID, err := parseID(args, "collID")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("book build", flag.ContinueOnError)
+	out := fs.String("out", "", "output PDF path (defaults to the book's export folder)")
+	rebuild := fs.Bool("rebuild", false, "rebuild all parts")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cacheDir := bookbuild.GetCacheDir(collID)
+	book, manifest, err := loadBookManifest(e, collID, *out, ".pdf", cacheDir)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := bookbuild.BuildWithParts(bookbuild.PipelineOptions{
+		Ctx:          ctx,
+		Manifest:     manifest,
+		CollectionID: collID,
+		CacheDir:     cacheDir,
+		OutputPath:   manifest.OutputPath,
+		RebuildAll:   *rebuild,
+		OnProgress:   e.printProgress,
+		ConvertToPDF: func(ctx context.Context, src, dst string) error {
+			_, err := e.fileOps.ConvertToPDF(ctx, src, dst)
+			return err
+		},
+		Book: book,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("build cancelled")
+		}
+		return fmt.Errorf("build failed: %w", err)
+	}
+	if err := e.db.AddBookBuild(result.Record); err != nil {
+		return err
+	}
+
+	if e.jsonOut {
+		return printJSON(result)
+	}
+	fmt.Printf("Built %s (%d pages, %d works), recorded as build %d\n",
+		result.OutputPath, result.TotalPages, result.WorkCount, result.Record.BuildID)
+	for _, w := range result.Warnings {
+		fmt.Printf("  warning: %s\n", w)
+	}
+	return nil
+}
+
+// bookEPUB exports the collection as an EPUB. The front and back matter
+// pages are rendered by the desktop app and are not available here, so the
+// book starts with the cover and contents.
+func bookEPUB(e *env, args []string) error {
+	collID, err := parseID(args, "collID")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("book epub", flag.ContinueOnError)
+	out := fs.String("out", "", "output EPUB path (defaults to the book's export folder)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	book, manifest, err := loadBookManifest(e, collID, *out, ".epub", bookbuild.GetCacheDir(collID))
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := epub.Build(epub.Options{
+		Ctx:        ctx,
+		Manifest:   manifest,
+		Metadata:   epub.MetadataFromBook(book),
+		CoverPath:  deref(book.FrontCoverPath),
+		OutputPath: manifest.OutputPath,
+		OnProgress: e.printProgress,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("export cancelled")
+		}
+		return fmt.Errorf("epub export failed: %w", err)
+	}
+
+	if e.jsonOut {
+		return printJSON(result)
+	}
+	fmt.Printf("Exported %s (%d works)\n", result.OutputPath, result.WorkCount)
+	for _, w := range result.Warnings {
+		fmt.Printf("  warning: %s\n", w)
+	}
+	return nil
+}
+
+// loadBookManifest reads the collection's book settings and works into a
+// manifest. Without an explicit output path the file goes to the book's
+// export folder, named after the book with the given extension.
+func loadBookManifest(e *env, collID int64, out, ext, cacheDir string) (*models.Book, *bookbuild.Manifest, error) {
+	database, err := e.openDB()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	book, err := database.GetBookByCollection(collID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if book == nil {
+		return nil, nil, fmt.Errorf("no book configuration found for collection %d", collID)
+	}
+
+	coll, err := database.GetCollection(collID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get collection: %w", err)
+	}
+	if coll == nil {
+		return nil, nil, fmt.Errorf("collection %d not found", collID)
+	}
+
+	outputPath := out
+	if outputPath == "" {
+		outputPath = defaultBookPath(book, coll.CollectionName, ext)
+	}
+
+	templatePath := deref(book.TemplatePath)
+	if templatePath == "" {
+		templatePath = e.fileOps.GetBookTemplatePath()
+	}
+
+	works, err := database.GetCollectionWorks(collID, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get collection works: %w", err)
+	}
+
+	var firstLines map[int64]string
+	if bookbuild.IncludesIndex(book) {
+		if firstLines, err = workFirstLines(e, works); err != nil {
+			return nil, nil, fmt.Errorf("read first lines: %w", err)
+		}
+	}
+
+	manifest, err := bookbuild.NewCollectionManifest(bookbuild.CollectionManifestOptions{
+		Works:          works,
+		Book:           book,
+		CollectionName: coll.CollectionName,
+		PDFPreviewPath: e.settings.PDFPreviewPath,
+		BaseFolderPath: e.settings.BaseFolderPath,
+		TemplatePath:   templatePath,
+		BuildDir:       cacheDir,
+		OutputPath:     outputPath,
+		FirstLines:     firstLines,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("build manifest: %w", err)
+	}
+	return book, manifest, nil
+}
+
+// workFirstLines looks up the works' opening lines in the search index
+func workFirstLines(e *env, works []models.CollectionWork) (map[int64]string, error) {
+	ftsDB := fts.NewDatabase()
+	if !ftsDB.Exists() {
+		return nil, nil
+	}
+	if err := ftsDB.Open(); err != nil {
+		return nil, err
+	}
+	defer ftsDB.Close()
+
+	titles := make(map[int64]string, len(works))
+	for _, w := range works {
+		titles[w.WorkID] = w.Title
+	}
+	return fts.NewSearcher(ftsDB, e.db.Conn()).FirstLines(titles)
+}
+
+// defaultBookPath is where book outputs go without an explicit path: the
+// book's export folder, or the working directory
+func defaultBookPath(book *models.Book, collectionName, ext string) string {
+	title := book.Title
+	if title == "" {
+		title = collectionName
+	}
+	dir := deref(book.ExportPath)
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	return filepath.Join(dir, sanitizeFilename(title)+ext)
+}
+
+// bookPreflight checks a built galley, and the book's cover PDF when it
+// has one, against KDP's requirements. It exits non-zero on errors.
+func bookPreflight(e *env, args []string) error {
+	collID, err := parseID(args, "collID")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("book preflight", flag.ContinueOnError)
+	galley := fs.String("galley", "", "galley PDF (defaults to the book's export folder)")
+	cover := fs.String("cover", "", "cover PDF (defaults to the book's cover)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+	book, err := database.GetBookByCollection(collID)
+	if err != nil {
+		return err
+	}
+	if book == nil {
+		return fmt.Errorf("no book configuration found for collection %d", collID)
+	}
+	coll, err := database.GetCollection(collID)
+	if err != nil {
+		return fmt.Errorf("get collection: %w", err)
+	}
+	if coll == nil {
+		return fmt.Errorf("collection %d not found", collID)
+	}
+
+	galleyPath := *galley
+	if galleyPath == "" {
+		galleyPath = defaultBookPath(book, coll.CollectionName, ".pdf")
+	}
+	coverPath := *cover
+	if coverPath == "" {
+		coverPath = deref(book.CoverPath)
+	}
+
+	report, err := kdp.Preflight(galleyPath, coverPath, kdp.SpecFromBook(book))
+	if err != nil {
+		return err
+	}
+
+	if e.jsonOut {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("%s: %d pages, spine %.2fmm", galleyPath, report.PageCount, report.SpineMM)
+		if report.Bleed {
+			fmt.Print(", with bleed")
+		}
+		fmt.Println()
+		for _, section := range []struct {
+			name     string
+			findings []kdp.Finding
+		}{{"galley", report.Galley}, {"cover", report.Cover}} {
+			for _, f := range section.findings {
+				fmt.Printf("  %s %s: %s\n", f.Severity, section.name, f.Message)
+			}
+		}
+	}
+	if !report.Passed() {
+		return fmt.Errorf("preflight failed")
+	}
+	return nil
+}
+
+func (e *env) printProgress(stage string, current, total int, message string) {
+	if !e.jsonOut {
+		fmt.Fprintf(os.Stderr, "[%d/%d] %s: %s\n", current, total, stage, message)
+	}
+}
+
+func sanitizeFilename(name string) string {
+	name = strings.ReplaceAll(name, " | ", " ")
+	replacer := strings.NewReplacer(
+		"/", "-",
+		"\\", "-",
+		":", "-",
+		"*", "",
+		"?", "",
+		"\"", "",
+		"<", "",
+		">", "",
+		"|", "",
+	)
+	result := strings.Trim(replacer.Replace(name), " .")
+	if result == "" {
+		result = "untitled"
+	}
+	return result
+}
+
+func bookCoverGuide(e *env, args []string) error {
+	collID, err := parseID(args, "collID")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("book cover-guide", flag.ContinueOnError)
+	out := fs.String("out", "", "guide file to write (.pdf or .svg)")
+	pages := fs.Int("pages", 0, "page count (defaults to the galley's)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+	book, err := database.GetBookByCollection(collID)
+	if err != nil {
+		return err
+	}
+	if book == nil {
+		return fmt.Errorf("no book configuration found for collection %d", collID)
+	}
+
+	pageCount := *pages
+	if pageCount <= 0 {
+		coll, err := database.GetCollection(collID)
+		if err != nil {
+			return fmt.Errorf("get collection: %w", err)
+		}
+		if coll == nil {
+			return fmt.Errorf("collection %d not found", collID)
+		}
+		galleyPath := defaultBookPath(book, coll.CollectionName, ".pdf")
+		pageCount, err = bookbuild.GetPageCount(galleyPath)
+		if err != nil {
+			return fmt.Errorf("no galley at %s, build the book or pass -pages: %w", galleyPath, err)
+		}
+	}
+
+	g := bookbuild.CoverGeometryForBook(book, pageCount)
+	if *out != "" {
+		if err := bookbuild.WriteCoverGuide(*out, g); err != nil {
+			return err
+		}
+	}
+
+	if e.jsonOut {
+		return printJSON(g)
+	}
+	fmt.Printf("%s trim, %s paper, %d pages\n", g.TrimSize, g.PaperType, g.PageCount)
+	fmt.Printf("full wrap %.2f x %.2f mm, spine %.2f mm, bleed %.2f mm\n", g.WidthMM, g.HeightMM, g.SpineMM, g.BleedMM)
+	if !g.SpineTextAllowed {
+		fmt.Printf("spine text not allowed under %d pages\n", bookbuild.MinSpineTextPages)
+	}
+	if *out != "" {
+		fmt.Printf("guide written to %s\n", *out)
+	}
+	return nil
+}
+
+func bookBuilds(e *env, args []string) error {
+	collID, err := parseID(args, "collID")
+	if err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+	builds, err := database.ListBookBuilds(collID)
+	if err != nil {
+		return err
+	}
+
+	if e.jsonOut {
+		return printJSON(builds)
+	}
+	if len(builds) == 0 {
+		fmt.Println("No builds recorded")
+		return nil
+	}
+	for _, b := range builds {
+		from := ""
+		if b.RebuiltFrom != nil {
+			from = fmt.Sprintf("  (rebuild of %d)", *b.RebuiltFrom)
+		}
+		fmt.Printf("%5d  %s  %4d pages  %3d inputs  %-8s  %s%s\n",
+			b.BuildID, b.CreatedAt, b.TotalPages, b.InputCount, b.Duration, b.OutputPath, from)
+	}
+	return nil
+}
+
+func bookBuildDiff(e *env, args []string) error {
+	fromID, err := parseID(args, "fromBuildID")
+	if err != nil {
+		return err
+	}
+	toID, err := parseID(args[1:], "toBuildID")
+	if err != nil {
+		return err
+	}
+
+	from, err := getBookBuild(e, fromID)
+	if err != nil {
+		return err
+	}
+	to, err := getBookBuild(e, toID)
+	if err != nil {
+		return err
+	}
+	diff, err := bookbuild.DiffBuilds(from, to)
+	if err != nil {
+		return err
+	}
+
+	if e.jsonOut {
+		return printJSON(diff)
+	}
+	if diff.Empty() {
+		fmt.Println("Builds read identical inputs")
+	}
+	for _, c := range diff.Inputs {
+		fmt.Printf("%-8s %s\n", c.Change, c.Label)
+	}
+	if diff.OrderChanged {
+		fmt.Println("reordered")
+	}
+	for _, c := range diff.Settings {
+		fmt.Printf("setting  %s: %s -> %s\n", c.Field, c.Old, c.New)
+	}
+	if diff.SameOutput {
+		fmt.Println("Both builds printed the same pages")
+	}
+	return nil
+}
+
+// bookRebuild reproduces a recorded build from its archived inputs and
+// checks that it prints the same pages
+func bookRebuild(e *env, args []string) error {
+	buildID, err := parseID(args, "buildID")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("book rebuild", flag.ContinueOnError)
+	out := fs.String("out", "", "output PDF path (defaults to the original build's path)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	record, err := getBookBuild(e, buildID)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := bookbuild.Rebuild(record, bookbuild.PipelineOptions{
+		Ctx:        ctx,
+		OutputPath: *out,
+		OnProgress: e.printProgress,
+		ConvertToPDF: func(ctx context.Context, src, dst string) error {
+			_, err := e.fileOps.ConvertToPDF(ctx, src, dst)
+			return err
+		},
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("rebuild cancelled")
+		}
+		return fmt.Errorf("rebuild failed: %w", err)
+	}
+	if err := e.db.AddBookBuild(result.Record); err != nil {
+		return err
+	}
+
+	same := result.Record.PagesSHA256 == record.PagesSHA256
+	if e.jsonOut {
+		return printJSON(map[string]any{"result": result, "buildID": result.Record.BuildID, "samePages": same})
+	}
+	fmt.Printf("Rebuilt build %d as %s (%d pages), recorded as build %d\n",
+		buildID, result.OutputPath, result.TotalPages, result.Record.BuildID)
+	if !same {
+		return fmt.Errorf("rebuild does not match build %d page for page", buildID)
+	}
+	fmt.Println("Pages match the original build")
+	return nil
+}
+
+func getBookBuild(e *env, buildID int64) (*models.BookBuild, error) {
+	database, err := e.openDB()
+	if err != nil {
+		return nil, err
+	}
+	b, err := database.GetBookBuild(buildID)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, fmt.Errorf("build %d not found", buildID)
+	}
+	return b, nil
+}
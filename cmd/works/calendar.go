@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/calendar"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func calendarList(e *env, args []string) error {
+	fs := flag.NewFlagSet("calendar list", flag.ContinueOnError)
+	from := fs.String("from", time.Now().Format("2006-01-02"), "first date (YYYY-MM-DD)")
+	to := fs.String("to", time.Now().AddDate(0, 3, 0).Format("2006-01-02"), "last date (YYYY-MM-DD)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	events, err := loadCalendar(e, *from, *to)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(events))
+	for _, ev := range events {
+		rows = append(rows, []string{ev.Date, strconv.Itoa(ev.DaysAway), truncate(calendar.Summary(ev), 50), ev.Kind})
+	}
+	return e.emit(events, []string{"DATE", "DAYS", "EVENT", "KIND"}, rows)
+}
+
+func calendarICS(e *env, args []string) error {
+	fs := flag.NewFlagSet("calendar ics", flag.ContinueOnError)
+	from := fs.String("from", time.Now().AddDate(0, -3, 0).Format("2006-01-02"), "first date (YYYY-MM-DD)")
+	to := fs.String("to", time.Now().AddDate(1, 0, 0).Format("2006-01-02"), "last date (YYYY-MM-DD)")
+	out := fs.String("out", "", "output file (defaults to stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	events, err := loadCalendar(e, *from, *to)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return calendar.WriteICS(w, events, time.Now())
+}
+
+func loadCalendar(e *env, from, to string) ([]models.CalendarEvent, error) {
+	fromDate, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -from date: %s", from)
+	}
+	toDate, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -to date: %s", to)
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return nil, err
+	}
+	windows, err := database.ListSubmissionWindows(0)
+	if err != nil {
+		return nil, err
+	}
+	return calendar.Expand(windows, fromDate, toDate, time.Now()), nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func collectionList(e *env, _ []string) error {
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	colls, err := database.ListCollections(false)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(colls))
+	for _, c := range colls {
+		kind := deref(c.Type)
+		if c.SmartQuery != nil && *c.SmartQuery != "" {
+			kind = "Smart"
+		}
+		rows = append(rows, []string{
+			strconv.FormatInt(c.CollID, 10), truncate(c.CollectionName, 40), kind, c.Entity,
+			strconv.Itoa(c.NItems), strconv.FormatBool(c.IsBook),
+		})
+	}
+	return e.emit(colls, []string{"ID", "NAME", "TYPE", "OF", "ITEMS", "BOOK"}, rows)
+}
+
+func collectionShow(e *env, args []string) error {
+	id, err := parseID(args, "collID")
+	if err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	coll, err := database.GetCollection(id)
+	if err != nil {
+		return fmt.Errorf("get collection: %w", err)
+	}
+	if coll == nil {
+		return fmt.Errorf("collection %d not found", id)
+	}
+	switch coll.Entity {
+	case "submissions":
+		subs, err := database.GetCollectionSubmissions(id, false)
+		if err != nil {
+			return err
+		}
+		return e.emitSubmissions(subs)
+	case "organizations":
+		orgs, err := database.GetCollectionOrganizations(id, false)
+		if err != nil {
+			return err
+		}
+		return e.emitOrganizations(orgs)
+	}
+
+	works, err := database.GetCollectionWorks(id, false)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(works))
+	for _, w := range works {
+		rows = append(rows, []string{
+			strconv.FormatInt(w.Position, 10), strconv.FormatInt(w.WorkID, 10), truncate(w.Title, 50),
+			w.Type, w.Status, strconv.FormatBool(w.IsSuppressed),
+		})
+	}
+	return e.emit(works, []string{"POS", "ID", "TITLE", "TYPE", "STATUS", "SUPPRESSED"}, rows)
+}
+
+func collectionExport(e *env, args []string) error {
+	id, err := parseID(args, "collID")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("collection export", flag.ContinueOnError)
+	to := fs.String("to", e.settings.CollectionExportPath, "destination folder")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *to == "" {
+		return fmt.Errorf("destination folder is required (-to)")
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	coll, err := database.GetCollection(id)
+	if err != nil {
+		return fmt.Errorf("get collection: %w", err)
+	}
+	if coll == nil {
+		return fmt.Errorf("collection %d not found", id)
+	}
+
+	exportFolder := filepath.Join(*to, coll.CollectionName)
+	if err := os.MkdirAll(exportFolder, 0755); err != nil {
+		return fmt.Errorf("create folder %s: %w", exportFolder, err)
+	}
+
+	works, err := database.GetCollectionWorks(id, false)
+	if err != nil {
+		return fmt.Errorf("get collection works: %w", err)
+	}
+
+	copied := 0
+	for _, work := range works {
+		if work.Path == nil || *work.Path == "" {
+			continue
+		}
+
+		srcPath := *work.Path
+		if !filepath.IsAbs(srcPath) {
+			srcPath = filepath.Join(e.settings.BaseFolderPath, srcPath)
+		}
+		if _, err := os.Stat(srcPath); os.IsNotExist(err) {
+			continue
+		}
+
+		dstPath := filepath.Join(exportFolder, filepath.Base(srcPath))
+		if err := copyFile(srcPath, dstPath); err != nil {
+			return fmt.Errorf("copy %s: %w", filepath.Base(srcPath), err)
+		}
+		copied++
+	}
+
+	if e.jsonOut {
+		return printJSON(map[string]any{"folder": exportFolder, "copied": copied})
+	}
+	fmt.Printf("Copied %d files to %s\n", copied, exportFolder)
+	return nil
+}
+
+func collectionPreview(e *env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("query is required")
+	}
+
+	fs := flag.NewFlagSet("collection preview", flag.ContinueOnError)
+	of := fs.String("of", "works", "works, submissions or organizations")
+	limit := fs.Int("limit", 20, "rows to list")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	preview, err := database.PreviewSmartQuery(*of, args[0], *limit, false)
+	if err != nil {
+		return err
+	}
+	if preview.Error != "" {
+		if e.jsonOut {
+			return printJSON(preview)
+		}
+		return fmt.Errorf("%s\n  %s\n  %s^", preview.Error, args[0], strings.Repeat(" ", preview.ErrorPos))
+	}
+
+	if e.jsonOut {
+		return printJSON(preview)
+	}
+	switch preview.Entity {
+	case "submissions":
+		err = e.emitSubmissions(preview.Submissions)
+	case "organizations":
+		err = e.emitOrganizations(preview.Organizations)
+	default:
+		rows := make([][]string, 0, len(preview.Works))
+		for _, w := range preview.Works {
+			rows = append(rows, []string{
+				strconv.FormatInt(w.WorkID, 10), truncate(w.Title, 50), w.Type, w.Status, w.Quality,
+			})
+		}
+		err = e.emit(preview, []string{"ID", "TITLE", "TYPE", "STATUS", "QUALITY"}, rows)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d %s match %s\n", preview.Count, preview.Entity, preview.Query)
+	return nil
+}
+
+func collectionSave(e *env, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("name and query are required")
+	}
+
+	fs := flag.NewFlagSet("collection save", flag.ContinueOnError)
+	of := fs.String("of", "works", "works, submissions or organizations")
+	if err := fs.Parse(args[2:]); err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	coll := &models.Collection{CollectionName: args[0], SmartQuery: &args[1], Entity: *of}
+	result, err := database.CreateCollection(coll)
+	if err != nil {
+		return err
+	}
+	if err := checkValidation(result); err != nil {
+		return err
+	}
+	fmt.Printf("Saved %s as collection %d of %s\n", coll.CollectionName, coll.CollID, coll.Entity)
+	return nil
+}
+
+func collectionQuery(e *env, args []string) error {
+	id, err := parseID(args, "collID")
+	if err != nil {
+		return err
+	}
+	if len(args) < 2 {
+		return fmt.Errorf(`query is required; use "" to make the collection manual`)
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+	if err := database.SetSmartQuery(id, args[1]); err != nil {
+		return err
+	}
+	if args[1] == "" {
+		fmt.Printf("Collection %d is now manual\n", id)
+	} else {
+		fmt.Printf("Collection %d now selects %s\n", id, args[1])
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+
+	_, err = io.Copy(dstFile, srcFile)
+	return err
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/backup"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/db"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/fileops"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/settings"
+)
+
+type env struct {
+	dbPath   string
+	jsonOut  bool
+	db       *db.DB
+	settings settings.Settings
+	fileOps  *fileops.FileOps
+}
+
+func newEnv(dbPath string, jsonOut bool) *env {
+	s := settings.NewManager().Get()
+	return &env{
+		dbPath:   dbPath,
+		jsonOut:  jsonOut,
+		settings: s,
+		fileOps: fileops.New(fileops.Config{
+			BaseFolderPath:       s.BaseFolderPath,
+			PDFPreviewPath:       s.PDFPreviewPath,
+			SubmissionExportPath: s.SubmissionExportPath,
+			TemplateFolderPath:   s.TemplateFolderPath,
+			LibreOfficePath:      s.LibreOfficePath,
+			ConverterPriority:    s.PDFConverterPriority,
+			MaxConversions:       s.PDFMaxConversions,
+		}),
+	}
+}
+
+// openDB opens the database on first use so that commands such as
+// backup restore can run without holding a connection.
+func (e *env) openDB() (*db.DB, error) {
+	if e.db != nil {
+		return e.db, nil
+	}
+
+	if _, err := os.Stat(e.dbPath); err != nil {
+		return nil, fmt.Errorf("database not found at %s", e.dbPath)
+	}
+
+	database, err := db.New(e.dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	initialized, err := database.IsInitialized()
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+	if !initialized {
+		database.Close()
+		return nil, fmt.Errorf("database at %s has not been initialized; run the desktop app once first", e.dbPath)
+	}
+
+	if err := database.RunMigrations(); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("run migrations: %w", err)
+	}
+
+	e.db = database
+	return e.db, nil
+}
+
+// backupManager returns a backup manager configured from settings
+func (e *env) backupManager() *backup.Manager {
+	m := backup.NewManager(e.dbPath)
+	m.SetOptions(backup.OptionsFromSettings(e.settings))
+	return m
+}
+
+func (e *env) close() {
+	if e.db != nil {
+		_, _ = e.db.Conn().Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+		e.db.Close()
+		e.db = nil
+	}
+}
+
+// emit writes v as JSON when -json is set, otherwise as a table
+func (e *env) emit(v any, headers []string, rows [][]string) error {
+	if e.jsonOut {
+		return printJSON(v)
+	}
+	printTable(headers, rows)
+	return nil
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func printTable(headers []string, rows [][]string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
+// printFields writes label/value pairs for single-record output
+func printFields(fields [][2]string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, f := range fields {
+		fmt.Fprintf(w, "%s:\t%s\n", f[0], f[1])
+	}
+	w.Flush()
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func derefInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d", *n)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
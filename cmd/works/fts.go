@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/fts"
+)
+
+func ftsStatus(e *env, _ []string) error {
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	ftsDB := fts.NewDatabase()
+	status := &fts.Status{Available: ftsDB.Exists()}
+	if status.Available {
+		if err := ftsDB.Open(); err != nil {
+			return err
+		}
+		defer ftsDB.Close()
+
+		conn := ftsDB.Conn()
+		_ = conn.QueryRow("SELECT COUNT(*) FROM content").Scan(&status.DocumentCount)
+		_ = conn.QueryRow("SELECT COALESCE(SUM(word_count), 0) FROM content").Scan(&status.TotalWords)
+		if size, err := ftsDB.Size(); err == nil {
+			status.IndexSize = size
+		}
+		if lastUpdate, err := ftsDB.GetMeta("last_updated"); err == nil && lastUpdate != "" {
+			if t, err := time.Parse(time.RFC3339, lastUpdate); err == nil {
+				status.LastUpdated = t
+			}
+		}
+
+		builder := fts.NewIndexBuilder(ftsDB, database.Conn(), e.settings.BaseFolderPath)
+		if staleness, err := builder.CheckStaleness(); err == nil {
+			status.StaleCount = staleness.StaleWorks
+			status.MissingCount = staleness.MissingWorks
+		}
+	}
+
+	if e.jsonOut {
+		return printJSON(status)
+	}
+
+	printFields([][2]string{
+		{"Available", strconv.FormatBool(status.Available)},
+		{"Documents", strconv.Itoa(status.DocumentCount)},
+		{"Words", strconv.Itoa(status.TotalWords)},
+		{"Stale", strconv.Itoa(status.StaleCount)},
+		{"Missing", strconv.Itoa(status.MissingCount)},
+		{"Size", strconv.FormatInt(status.IndexSize, 10)},
+		{"Last Updated", status.LastUpdated.Format(time.RFC3339)},
+	})
+	return nil
+}
+
+func ftsRebuild(e *env, args []string) error {
+	fs := flag.NewFlagSet("fts rebuild", flag.ContinueOnError)
+	incremental := fs.Bool("incremental", false, "only re-index stale and missing works")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	ftsDB := fts.NewDatabase()
+	defer ftsDB.Close()
+
+	builder := fts.NewIndexBuilder(ftsDB, database.Conn(), e.settings.BaseFolderPath)
+	if !e.jsonOut {
+		builder.SetProgressCallback(func(p fts.BuildProgress) {
+			fmt.Fprintf(os.Stderr, "\r%s %d/%d", p.Phase, p.Current, p.Total)
+		})
+	}
+
+	var report *fts.BuildReport
+	if *incremental {
+		report, err = builder.UpdateIncremental()
+	} else {
+		report, err = builder.BuildFull()
+	}
+	if !e.jsonOut {
+		fmt.Fprintln(os.Stderr)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := ftsDB.SetMeta("last_updated", time.Now().Format(time.RFC3339)); err != nil {
+		return err
+	}
+
+	if e.jsonOut {
+		return printJSON(report)
+	}
+	fmt.Printf("Indexed %d documents (%d words) in %.1fs\n", report.DocumentCount, report.WordCount, report.Duration)
+	for _, fw := range report.FailedWorks {
+		fmt.Printf("  failed: %d %s: %s\n", fw.WorkID, fw.Title, fw.Error)
+	}
+	return nil
+}
+
+func ftsSearch(e *env, args []string) error {
+	fs := flag.NewFlagSet("fts search", flag.ContinueOnError)
+	limit := fs.Int("limit", 25, "maximum results")
+
+	var terms []string
+	for len(args) > 0 {
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		args = fs.Args()
+		if len(args) > 0 {
+			terms = append(terms, args[0])
+			args = args[1:]
+		}
+	}
+	if len(terms) == 0 {
+		return fmt.Errorf("search text is required")
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	ftsDB := fts.NewDatabase()
+	if !ftsDB.Exists() {
+		return fmt.Errorf("full-text index not built; run 'works fts rebuild'")
+	}
+	defer ftsDB.Close()
+
+	searcher := fts.NewSearcher(ftsDB, database.Conn())
+	resp, err := searcher.Search(fts.Query{Text: strings.Join(terms, " "), Limit: *limit})
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		rows = append(rows, []string{
+			strconv.Itoa(r.WorkID), truncate(r.Title, 40), r.Type, r.Year, truncate(r.Snippet, 60),
+		})
+	}
+	return e.emit(resp, []string{"ID", "TITLE", "TYPE", "YEAR", "SNIPPET"}, rows)
+}
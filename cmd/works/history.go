@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func historyList(e *env, args []string) error {
+	fs := flag.NewFlagSet("history list", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "maximum operations to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+	ops, err := database.ListAuditOperations(*limit)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(ops))
+	for _, op := range ops {
+		state := ""
+		if op.Undone {
+			state = "undone"
+		}
+		rows = append(rows, []string{strconv.FormatInt(op.OpID, 10), op.CreatedAt, op.Label,
+			strconv.Itoa(len(op.Entries)), state})
+	}
+	return e.emit(ops, []string{"OP", "WHEN", "LABEL", "ROWS", "STATE"}, rows)
+}
+
+func historyUndo(e *env, args []string) error {
+	return historyReplay(e, args, "Undid", func(n int) ([]models.AuditOperation, error) {
+		database, err := e.openDB()
+		if err != nil {
+			return nil, err
+		}
+		return database.Undo(n)
+	})
+}
+
+func historyRedo(e *env, args []string) error {
+	return historyReplay(e, args, "Redid", func(n int) ([]models.AuditOperation, error) {
+		database, err := e.openDB()
+		if err != nil {
+			return nil, err
+		}
+		return database.Redo(n)
+	})
+}
+
+func historyReplay(e *env, args []string, verb string, replay func(n int) ([]models.AuditOperation, error)) error {
+	n := 1
+	if len(args) > 0 {
+		v, err := strconv.Atoi(args[0])
+		if err != nil || v < 1 {
+			return fmt.Errorf("invalid count: %s", args[0])
+		}
+		n = v
+	}
+
+	ops, err := replay(n)
+	if e.jsonOut {
+		if err != nil {
+			return err
+		}
+		return printJSON(ops)
+	}
+	for _, op := range ops {
+		fmt.Printf("%s: %s\n", verb, op.Label)
+	}
+	if len(ops) == 0 && err == nil {
+		fmt.Println("Nothing to do")
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+func jobsList(e *env, args []string) error {
+	fs := flag.NewFlagSet("jobs list", flag.ContinueOnError)
+	status := fs.String("status", "", "only jobs with this status (queued, running, succeeded, failed, cancelled)")
+	limit := fs.Int("limit", 20, "maximum jobs to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+	list, err := database.ListJobs(*status, *limit)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(list))
+	for _, j := range list {
+		progress := ""
+		if j.ProgressTotal > 0 {
+			progress = fmt.Sprintf("%d/%d", j.ProgressCurrent, j.ProgressTotal)
+		}
+		rows = append(rows, []string{strconv.FormatInt(j.JobID, 10), j.CreatedAt, j.Kind, j.Label,
+			j.Status, progress, j.Error})
+	}
+	return e.emit(list, []string{"JOB", "CREATED", "KIND", "LABEL", "STATUS", "PROGRESS", "ERROR"}, rows)
+}
+
+func jobsShow(e *env, args []string) error {
+	jobID, err := parseID(args, "jobID")
+	if err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+	job, err := database.GetJob(jobID)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return fmt.Errorf("job %d not found", jobID)
+	}
+
+	if e.jsonOut {
+		return printJSON(job)
+	}
+	fmt.Printf("Job %d: %s (%s)\n", job.JobID, job.Label, job.Kind)
+	fmt.Printf("Status:   %s (attempt %d)\n", job.Status, job.Attempts)
+	if job.RetryOf != nil {
+		fmt.Printf("Retry of: %d\n", *job.RetryOf)
+	}
+	fmt.Printf("Created:  %s\n", job.CreatedAt)
+	if job.StartedAt != "" {
+		fmt.Printf("Started:  %s\n", job.StartedAt)
+	}
+	if job.FinishedAt != "" {
+		fmt.Printf("Finished: %s\n", job.FinishedAt)
+	}
+	if job.ProgressTotal > 0 {
+		fmt.Printf("Progress: %d/%d %s\n", job.ProgressCurrent, job.ProgressTotal, job.Message)
+	}
+	if job.Error != "" {
+		fmt.Printf("Error:    %s\n", job.Error)
+	}
+	if job.Log != "" {
+		fmt.Printf("\n%s", job.Log)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type command struct {
+	usage string
+	run   func(e *env, args []string) error
+}
+
+var commands = map[string]map[string]command{
+	"works": {
+		"list":   {"works list [-type T] [-status S] [-year Y] [-deleted]", worksList},
+		"show":   {"works show <workID>", worksShow},
+		"update": {"works update <workID> [-title T] [-type T] [-year Y] [-status S] [-quality Q] [-words N]", worksUpdate},
+	},
+	"subs": {
+		"list":      {"subs list [-work ID] [-org ID] [-pending]", subsList},
+		"conflicts": {"subs conflicts <acceptedSubmissionID> [-withdraw] [-date YYYY-MM-DD]", subsConflicts},
+		"log":       {"subs log -work ID -org ID [-date YYYY-MM-DD] [-type T] [-draft D] [-cost N] [-collection]", subsLog},
+		"suggest":   {"subs suggest [-work ID] [-org ID] [-quality Q] [-status S,S] [-max-words N] [-limit N]", subsSuggest},
+		"packets":   {"subs packets [-size N] [-quality Q] [-status S,S] [-max-words N] [-limit N]", subsPackets},
+	},
+	"orgs": {
+		"find": {"orgs find <name>", orgsFind},
+		"show": {"orgs show <orgID>", orgsShow},
+	},
+	"calendar": {
+		"list": {"calendar list [-from YYYY-MM-DD] [-to YYYY-MM-DD]", calendarList},
+		"ics":  {"calendar ics [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-out file.ics]", calendarICS},
+	},
+	"collection": {
+		"list":    {"collection list", collectionList},
+		"show":    {"collection show <collID>", collectionShow},
+		"export":  {"collection export <collID> -to <folder>", collectionExport},
+		"preview": {"collection preview <query> [-of works|submissions|organizations] [-limit N]", collectionPreview},
+		"save":    {"collection save <name> <query> [-of works|submissions|organizations]", collectionSave},
+		"query":   {"collection query <collID> <query>", collectionQuery},
+	},
+	"book": {
+		"build":       {"book build <collID> [-out file.pdf] [-rebuild]", bookBuild},
+		"epub":        {"book epub <collID> [-out file.epub]", bookEPUB},
+		"builds":      {"book builds <collID>", bookBuilds},
+		"build-diff":  {"book build-diff <fromBuildID> <toBuildID>", bookBuildDiff},
+		"rebuild":     {"book rebuild <buildID> [-out file.pdf]", bookRebuild},
+		"cover-guide": {"book cover-guide <collID> [-out guide.pdf|svg] [-pages n]", bookCoverGuide},
+		"preflight":   {"book preflight <collID> [-galley file.pdf] [-cover file.pdf]", bookPreflight},
+	},
+	"jobs": {
+		"list": {"jobs list [-status S] [-limit N]", jobsList},
+		"show": {"jobs show <jobID>", jobsShow},
+	},
+	"fts": {
+		"status":  {"fts status", ftsStatus},
+		"rebuild": {"fts rebuild [-incremental]", ftsRebuild},
+		"search":  {"fts search <text> [-limit N]", ftsSearch},
+	},
+	"api": {
+		"serve": {"api serve [-addr host:port] [-token T]", apiServe},
+	},
+	"snapshot": {
+		"create":  {"snapshot create [label]", snapshotCreate},
+		"list":    {"snapshot list", snapshotList},
+		"history": {"snapshot history <workID>", snapshotHistory},
+		"restore": {"snapshot restore <workID> <YYYY-MM-DD> [-out file]", snapshotRestore},
+	},
+	"revisions": {
+		"list":    {"revisions list <workID>", revisionsList},
+		"diff":    {"revisions diff <workID> <from> <to> [-context N] [-format text|markdown|html]", revisionsDiff},
+		"restore": {"revisions restore <revisionID> [-out file]", revisionsRestore},
+	},
+	"history": {
+		"list": {"history list [-limit N]", historyList},
+		"undo": {"history undo [N]", historyUndo},
+		"redo": {"history redo [N]", historyRedo},
+	},
+	"backup": {
+		"list":    {"backup list", backupList},
+		"create":  {"backup create [label]", backupCreate},
+		"verify":  {"backup verify <path>", backupVerify},
+		"restore": {"backup restore <path>", backupRestore},
+	},
+	"db": {
+		"migrate": {"db migrate", dbMigrate},
+	},
+}
+
+func main() {
+	homeDir, _ := os.UserHomeDir()
+
+	global := flag.NewFlagSet("works", flag.ContinueOnError)
+	global.Usage = printUsage
+	dbPath := global.String("db", filepath.Join(homeDir, ".works", "works.db"), "path to works database")
+	jsonOut := global.Bool("json", false, "emit JSON instead of tables")
+	if err := global.Parse(os.Args[1:]); err != nil {
+		os.Exit(1)
+	}
+
+	args := global.Args()
+	if len(args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	group, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", args[0])
+		printUsage()
+		os.Exit(1)
+	}
+	cmd, ok := group[args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s %s\n", args[0], args[1])
+		printUsage()
+		os.Exit(1)
+	}
+
+	e := newEnv(*dbPath, *jsonOut)
+	defer e.close()
+
+	if err := cmd.run(e, args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		e.close()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println("works - headless access to the Works database")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  works [-db path] [-json] <command> <subcommand> [args]")
+	fmt.Println()
+	fmt.Println("Commands:")
+
+	groups := make([]string, 0, len(commands))
+	for name := range commands {
+		groups = append(groups, name)
+	}
+	sort.Strings(groups)
+
+	for _, name := range groups {
+		subs := make([]string, 0, len(commands[name]))
+		for sub := range commands[name] {
+			subs = append(subs, sub)
+		}
+		sort.Strings(subs)
+		for _, sub := range subs {
+			fmt.Printf("  works %s\n", commands[name][sub].usage)
+		}
+	}
+}
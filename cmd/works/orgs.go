@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func orgsFind(e *env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("name is required")
+	}
+	needle := strings.ToLower(strings.Join(args, " "))
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	orgs, err := database.ListOrganizations(false)
+	if err != nil {
+		return err
+	}
+
+	matches := make([]models.Organization, 0)
+	for _, o := range orgs {
+		if strings.Contains(strings.ToLower(o.Name), needle) ||
+			strings.Contains(strings.ToLower(deref(o.OtherName)), needle) {
+			matches = append(matches, o)
+		}
+	}
+
+	return e.emitOrganizations(matches)
+}
+
+func (e *env) emitOrganizations(orgs []models.Organization) error {
+	rows := make([][]string, 0, len(orgs))
+	for _, o := range orgs {
+		rows = append(rows, []string{
+			strconv.FormatInt(o.OrgID, 10), truncate(o.Name, 40), o.Status, o.Type,
+			truncate(deref(o.Accepts), 30), derefInt(o.Ranking),
+		})
+	}
+	return e.emit(orgs, []string{"ID", "NAME", "STATUS", "TYPE", "ACCEPTS", "RANKING"}, rows)
+}
+
+func orgsShow(e *env, args []string) error {
+	id, err := parseID(args, "orgID")
+	if err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	org, err := database.GetOrganization(id)
+	if err != nil {
+		return err
+	}
+	if org == nil {
+		return fmt.Errorf("organization %d not found", id)
+	}
+
+	if e.jsonOut {
+		return printJSON(org)
+	}
+
+	printFields([][2]string{
+		{"ID", strconv.FormatInt(org.OrgID, 10)},
+		{"Name", org.Name},
+		{"Status", org.Status},
+		{"Type", org.Type},
+		{"URL", deref(org.URL)},
+		{"Timing", deref(org.Timing)},
+		{"Accepts", deref(org.Accepts)},
+		{"Interest", deref(org.MyInterest)},
+		{"Ranking", derefInt(org.Ranking)},
+		{"Contest Ends", deref(org.ContestEnds)},
+	})
+	return nil
+}
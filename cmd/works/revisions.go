@@ -0,0 +1,185 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/revisions"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/textdiff"
+)
+
+func (e *env) revisionStore() (*revisions.Store, error) {
+	database, err := e.openDB()
+	if err != nil {
+		return nil, err
+	}
+	return revisions.NewStore(database, revisions.DefaultDir()), nil
+}
+
+func revisionsList(e *env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("workID is required")
+	}
+	workID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid workID: %s", args[0])
+	}
+
+	store, err := e.revisionStore()
+	if err != nil {
+		return err
+	}
+	revs, err := store.List(workID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(revs))
+	for _, r := range revs {
+		rows = append(rows, []string{strconv.FormatInt(r.RevisionID, 10), r.CreatedAt,
+			strconv.Itoa(r.NWords), fmt.Sprintf("%+d", r.WordDelta), strconv.FormatInt(r.Size, 10), deref(r.Note)})
+	}
+	return e.emit(revs, []string{"REVISION", "CREATED", "WORDS", "DELTA", "SIZE", "NOTE"}, rows)
+}
+
+func revisionsDiff(e *env, args []string) error {
+	fs := flag.NewFlagSet("revisions diff", flag.ContinueOnError)
+	context := fs.Int("context", 2, "unchanged paragraphs to show around each change; -1 shows everything")
+	format := fs.String("format", "text", "output format: text, markdown or html")
+	if len(args) < 3 {
+		return fmt.Errorf("workID and two versions are required")
+	}
+	if err := fs.Parse(args[3:]); err != nil {
+		return err
+	}
+
+	workID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid workID: %s", args[0])
+	}
+	from, err := revisions.ParseVersion(args[1])
+	if err != nil {
+		return err
+	}
+	to, err := revisions.ParseVersion(args[2])
+	if err != nil {
+		return err
+	}
+
+	store, err := e.revisionStore()
+	if err != nil {
+		return err
+	}
+	work, err := e.db.GetWork(workID)
+	if err != nil {
+		return err
+	}
+	if work == nil || work.Path == nil || *work.Path == "" {
+		return fmt.Errorf("work %d has no file", workID)
+	}
+	loc := revisions.Locations{
+		Current: e.fileOps.GetFilename(*work.Path),
+		Copy:    e.fileOps.SubmissionCopyPath(work),
+		DocType: work.DocType,
+	}
+
+	result, err := store.Compare(workID, from, to, loc, textdiff.Options{Context: *context})
+	if err != nil {
+		return err
+	}
+
+	if e.jsonOut {
+		return printJSON(result)
+	}
+	switch *format {
+	case "markdown", "md":
+		fmt.Print(textdiff.RenderMarkdown(result))
+		return nil
+	case "html":
+		fmt.Print(textdiff.RenderHTML(result))
+		return nil
+	case "text":
+	default:
+		return fmt.Errorf("unknown format: %s", *format)
+	}
+
+	fmt.Printf("--- %s\n+++ %s\n", result.From, result.To)
+	for _, h := range result.Hunks {
+		fmt.Printf("@@ -%d,%d +%d,%d @@\n", h.FromStart, h.FromCount, h.ToStart, h.ToCount)
+		for _, p := range h.Paragraphs {
+			switch p.Op {
+			case textdiff.OpEqual:
+				fmt.Println("  " + p.To)
+			case textdiff.OpDelete:
+				fmt.Println("- " + p.From)
+			case textdiff.OpInsert:
+				fmt.Println("+ " + p.To)
+			case textdiff.OpChange:
+				fmt.Println("- " + p.From)
+				fmt.Println("+ " + p.To)
+			}
+		}
+	}
+	st := result.Stats
+	fmt.Printf("\n%d paragraphs changed, %d added, %d removed; %+d words (%d added, %d removed)\n",
+		st.ParagraphsChanged, st.ParagraphsAdded, st.ParagraphsRemoved,
+		st.WordsAdded-st.WordsRemoved, st.WordsAdded, st.WordsRemoved)
+	return nil
+}
+
+func revisionsRestore(e *env, args []string) error {
+	fs := flag.NewFlagSet("revisions restore", flag.ContinueOnError)
+	out := fs.String("out", "", "write the file here instead of over the work's document")
+	if len(args) == 0 {
+		return fmt.Errorf("revisionID is required")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	revisionID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid revisionID: %s", args[0])
+	}
+
+	store, err := e.revisionStore()
+	if err != nil {
+		return err
+	}
+
+	dest := *out
+	var work *models.Work
+	if dest == "" {
+		rev, err := e.db.GetWorkRevision(revisionID)
+		if err != nil {
+			return err
+		}
+		work, err = e.db.GetWork(rev.WorkID)
+		if err != nil {
+			return err
+		}
+		if work == nil || work.Path == nil || *work.Path == "" {
+			return fmt.Errorf("work %d has no file", rev.WorkID)
+		}
+		dest = e.fileOps.GetFilename(*work.Path)
+
+		if _, err := store.Capture(work.WorkID, dest, work.DocType, "before restore"); err != nil {
+			return fmt.Errorf("capture current file: %w", err)
+		}
+	}
+
+	rev, err := store.Restore(revisionID, dest)
+	if err != nil {
+		return err
+	}
+	if work != nil {
+		_, _ = store.Capture(work.WorkID, dest, work.DocType, fmt.Sprintf("restored revision %d", revisionID))
+	}
+
+	if e.jsonOut {
+		return printJSON(rev)
+	}
+	fmt.Printf("Restored revision %d (%d words) to %s\n", rev.RevisionID, rev.NWords, dest)
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/backup"
+)
+
+func (e *env) manuscriptSources() ([]backup.ManuscriptSource, error) {
+	database, err := e.openDB()
+	if err != nil {
+		return nil, err
+	}
+	works, err := database.ListWorks(false)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]backup.ManuscriptSource, 0, len(works))
+	for _, w := range works {
+		if w.Path == nil || *w.Path == "" {
+			continue
+		}
+		sources = append(sources, backup.ManuscriptSource{
+			WorkID: w.WorkID,
+			Title:  w.Title,
+			Path:   e.fileOps.GetFilename(*w.Path),
+		})
+	}
+	return sources, nil
+}
+
+func snapshotCreate(e *env, args []string) error {
+	sources, err := e.manuscriptSources()
+	if err != nil {
+		return err
+	}
+
+	info, err := e.backupManager().SnapshotManuscripts(e.settings.BaseFolderPath, sources, strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+
+	if e.jsonOut {
+		return printJSON(info)
+	}
+	fmt.Printf("Snapshot %s: %d files (%d new bytes), %d missing\n", info.ID, info.FileCount, info.NewBytes, info.MissingCount)
+	return nil
+}
+
+func snapshotList(e *env, _ []string) error {
+	infos, err := e.backupManager().ListManuscriptSnapshots()
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(infos))
+	for _, s := range infos {
+		rows = append(rows, []string{s.ID, s.Label, strconv.Itoa(s.FileCount), strconv.Itoa(s.MissingCount),
+			strconv.FormatInt(s.TotalBytes, 10), strconv.FormatInt(s.NewBytes, 10)})
+	}
+	return e.emit(infos, []string{"ID", "LABEL", "FILES", "MISSING", "BYTES", "NEW"}, rows)
+}
+
+func snapshotHistory(e *env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("workID is required")
+	}
+	workID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid workID: %s", args[0])
+	}
+
+	versions, err := e.backupManager().ManuscriptHistory(workID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(versions))
+	for _, v := range versions {
+		rows = append(rows, []string{v.SnapshotID, v.ModTime, strconv.FormatInt(v.Size, 10), v.SHA256[:12], v.Path})
+	}
+	return e.emit(versions, []string{"SNAPSHOT", "MODIFIED", "SIZE", "SHA256", "PATH"}, rows)
+}
+
+func snapshotRestore(e *env, args []string) error {
+	fs := flag.NewFlagSet("snapshot restore", flag.ContinueOnError)
+	out := fs.String("out", "", "write the file here instead of over the work's document")
+	if len(args) < 2 {
+		return fmt.Errorf("workID and date are required")
+	}
+	if err := fs.Parse(args[2:]); err != nil {
+		return err
+	}
+
+	workID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid workID: %s", args[0])
+	}
+	day, err := time.ParseInLocation("2006-01-02", args[1], time.Local)
+	if err != nil {
+		return fmt.Errorf("date must be YYYY-MM-DD: %s", args[1])
+	}
+	at := day.Add(24*time.Hour - time.Second)
+
+	dest := *out
+	m := e.backupManager()
+	// Pick the version first so the snapshot taken below can't be chosen
+	version, err := m.ManuscriptVersionAt(workID, at)
+	if err != nil {
+		return err
+	}
+	if dest == "" {
+		database, err := e.openDB()
+		if err != nil {
+			return err
+		}
+		work, err := database.GetWork(workID)
+		if err != nil {
+			return err
+		}
+		if work == nil || work.Path == nil || *work.Path == "" {
+			return fmt.Errorf("work %d has no file", workID)
+		}
+		dest = e.fileOps.GetFilename(*work.Path)
+
+		current := []backup.ManuscriptSource{{WorkID: work.WorkID, Title: work.Title, Path: dest}}
+		if _, err := m.PreserveManuscripts(e.settings.BaseFolderPath, current, "before restore"); err != nil {
+			return fmt.Errorf("snapshot current file: %w", err)
+		}
+	}
+
+	if err := m.WriteManuscriptVersion(version, dest); err != nil {
+		return err
+	}
+
+	if e.jsonOut {
+		return printJSON(version)
+	}
+	fmt.Printf("Restored version from snapshot %s to %s\n", version.SnapshotID, dest)
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func subsList(e *env, args []string) error {
+	fs := flag.NewFlagSet("subs list", flag.ContinueOnError)
+	workID := fs.Int64("work", 0, "only submissions of this work")
+	orgID := fs.Int64("org", 0, "only submissions to this organization")
+	pending := fs.Bool("pending", false, "only submissions awaiting a response")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	var views []models.SubmissionView
+	switch {
+	case *workID > 0:
+		views, err = database.ListSubmissionViewsByWork(*workID, false)
+	case *orgID > 0:
+		views, err = database.ListSubmissionViewsByOrg(*orgID, false)
+	default:
+		views, err = database.ListAllSubmissionViews(false)
+	}
+	if err != nil {
+		return err
+	}
+
+	filtered := make([]models.SubmissionView, 0, len(views))
+	for _, v := range views {
+		if *pending && v.DecisionPending != "yes" {
+			continue
+		}
+		filtered = append(filtered, v)
+	}
+
+	return e.emitSubmissions(filtered)
+}
+
+func (e *env) emitSubmissions(views []models.SubmissionView) error {
+	rows := make([][]string, 0, len(views))
+	for _, v := range views {
+		rows = append(rows, []string{
+			strconv.FormatInt(v.SubmissionID, 10), truncate(v.TitleOfWork, 40), truncate(v.JournalName, 30),
+			deref(v.SubmissionDate), deref(v.ResponseType), deref(v.ResponseDate),
+		})
+	}
+	return e.emit(views, []string{"ID", "WORK", "JOURNAL", "SUBMITTED", "RESPONSE", "RESPONDED"}, rows)
+}
+
+func subsLog(e *env, args []string) error {
+	fs := flag.NewFlagSet("subs log", flag.ContinueOnError)
+	workID := fs.Int64("work", 0, "workID (or collID with -collection)")
+	orgID := fs.Int64("org", 0, "orgID")
+	date := fs.String("date", time.Now().Format("2006-01-02"), "submission date")
+	subType := fs.String("type", "", "submission type (e.g. Online, Email)")
+	draft := fs.String("draft", "", "draft submitted")
+	cost := fs.Float64("cost", 0, "submission fee")
+	isCollection := fs.Bool("collection", false, "submit a collection instead of a single work")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	sub := &models.Submission{
+		WorkID:         *workID,
+		OrgID:          *orgID,
+		IsCollection:   *isCollection,
+		Draft:          *draft,
+		SubmissionDate: date,
+	}
+	if *subType != "" {
+		sub.SubmissionType = subType
+	}
+	if *cost > 0 {
+		sub.Cost = cost
+	}
+
+	result, err := database.CreateSubmission(sub)
+	if err != nil {
+		return err
+	}
+	if err := checkValidation(result); err != nil {
+		return err
+	}
+
+	if e.jsonOut {
+		return printJSON(sub)
+	}
+	fmt.Printf("Logged submission %d\n", sub.SubmissionID)
+	return nil
+}
+
+func subsConflicts(e *env, args []string) error {
+	acceptedID, err := parseID(args, "submissionID")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("subs conflicts", flag.ContinueOnError)
+	withdraw := fs.Bool("withdraw", false, "mark every conflicting submission withdrawn")
+	date := fs.String("date", time.Now().Format("2006-01-02"), "withdrawal date")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	if *withdraw {
+		result, err := database.WithdrawConflictingSubmissions(acceptedID, nil, *date)
+		if err != nil {
+			return err
+		}
+		if e.jsonOut {
+			return printJSON(result)
+		}
+		fmt.Printf("Withdrew %d submission(s)\n", len(result.Withdrawn))
+		for _, msg := range result.Errors {
+			fmt.Printf("  failed: %s\n", msg)
+		}
+		return nil
+	}
+
+	checklist, err := database.GetWithdrawalChecklist(acceptedID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(checklist.Items))
+	for _, item := range checklist.Items {
+		rows = append(rows, []string{
+			strconv.FormatInt(item.SubmissionID, 10), truncate(item.OrgName, 30), truncate(item.Title, 40),
+			item.SubmissionDate, item.Reason,
+		})
+	}
+	return e.emit(checklist, []string{"ID", "JOURNAL", "TITLE", "SUBMITTED", "REASON"}, rows)
+}
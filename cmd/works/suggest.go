@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/recommend"
+)
+
+func subsSuggest(e *env, args []string) error {
+	fs := flag.NewFlagSet("subs suggest", flag.ContinueOnError)
+	opts := recommendFlags(fs)
+	fs.Int64Var(&opts.WorkID, "work", 0, "only suggestions for this work")
+	fs.Int64Var(&opts.OrgID, "org", 0, "only suggestions for this organization")
+	fs.IntVar(&opts.Limit, "limit", 20, "most suggestions to show (0 for all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	in, err := e.recommendInput()
+	if err != nil {
+		return err
+	}
+	list := recommend.Recommend(in, *opts)
+
+	rows := make([][]string, 0, len(list))
+	for _, s := range list {
+		rows = append(rows, []string{
+			strconv.Itoa(s.Score), truncate(s.Title, 30), truncate(s.OrgName, 30), formatReasons(s.Reasons),
+		})
+	}
+	return e.emit(list, []string{"SCORE", "WORK", "JOURNAL", "WHY"}, rows)
+}
+
+func subsPackets(e *env, args []string) error {
+	fs := flag.NewFlagSet("subs packets", flag.ContinueOnError)
+	opts := recommendFlags(fs)
+	fs.IntVar(&opts.PacketSize, "size", recommend.DefaultPacketSize, "works per packet")
+	fs.IntVar(&opts.Limit, "limit", 10, "most packets to show (0 for all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	in, err := e.recommendInput()
+	if err != nil {
+		return err
+	}
+	packets := recommend.Packets(in, *opts)
+	if e.jsonOut {
+		return printJSON(packets)
+	}
+
+	for i, p := range packets {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (#%d)  score %d\n", p.OrgName, p.OrgID, p.Score)
+		for _, note := range p.Notes {
+			fmt.Printf("  note: %s\n", note)
+		}
+		for _, s := range p.Works {
+			fmt.Printf("  %3d  %s (#%d): %s\n", s.Score, s.Title, s.WorkID, formatReasons(s.Reasons))
+		}
+	}
+	return nil
+}
+
+func recommendFlags(fs *flag.FlagSet) *recommend.Options {
+	opts := &recommend.Options{}
+	fs.StringVar(&opts.MinQuality, "quality", recommend.DefaultMinQuality, "weakest quality worth sending")
+	fs.IntVar(&opts.MaxWords, "max-words", 0, "skip works longer than this")
+	fs.Func("status", "comma-separated work statuses ready to send", func(s string) error {
+		opts.Statuses = strings.Split(s, ",")
+		return nil
+	})
+	return opts
+}
+
+func (e *env) recommendInput() (recommend.Input, error) {
+	database, err := e.openDB()
+	if err != nil {
+		return recommend.Input{}, err
+	}
+	return database.RecommendInput(time.Now())
+}
+
+func formatReasons(reasons []recommend.Reason) string {
+	parts := make([]string, 0, len(reasons))
+	for _, r := range reasons {
+		parts = append(parts, fmt.Sprintf("%s %+d", r.Text, r.Points))
+	}
+	return strings.Join(parts, "; ")
+}
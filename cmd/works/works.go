@@ -0,0 +1,178 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/validation"
+)
+
+func worksList(e *env, args []string) error {
+	fs := flag.NewFlagSet("works list", flag.ContinueOnError)
+	workType := fs.String("type", "", "filter by type")
+	status := fs.String("status", "", "filter by status")
+	year := fs.String("year", "", "filter by year")
+	showDeleted := fs.Bool("deleted", false, "include deleted works")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	works, err := database.ListWorks(*showDeleted)
+	if err != nil {
+		return err
+	}
+
+	filtered := make([]models.WorkView, 0, len(works))
+	for _, w := range works {
+		if *workType != "" && !strings.EqualFold(w.Type, *workType) {
+			continue
+		}
+		if *status != "" && !strings.EqualFold(w.Status, *status) {
+			continue
+		}
+		if *year != "" && deref(w.Year) != *year {
+			continue
+		}
+		filtered = append(filtered, w)
+	}
+
+	rows := make([][]string, 0, len(filtered))
+	for _, w := range filtered {
+		rows = append(rows, []string{
+			strconv.FormatInt(w.WorkID, 10), truncate(w.Title, 50), w.Type, deref(w.Year),
+			w.Status, w.Quality, derefInt(w.NWords), strconv.Itoa(w.NSubmissions),
+		})
+	}
+	return e.emit(filtered, []string{"ID", "TITLE", "TYPE", "YEAR", "STATUS", "QUALITY", "WORDS", "SUBS"}, rows)
+}
+
+func worksShow(e *env, args []string) error {
+	id, err := parseID(args, "workID")
+	if err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	work, err := database.GetWork(id)
+	if err != nil {
+		return err
+	}
+	if work == nil {
+		return fmt.Errorf("work %d not found", id)
+	}
+
+	if e.jsonOut {
+		return printJSON(work)
+	}
+
+	printFields([][2]string{
+		{"ID", strconv.FormatInt(work.WorkID, 10)},
+		{"Title", work.Title},
+		{"Type", work.Type},
+		{"Year", deref(work.Year)},
+		{"Status", work.Status},
+		{"Quality", work.Quality},
+		{"Words", derefInt(work.NWords)},
+		{"Path", deref(work.Path)},
+		{"Draft", deref(work.Draft)},
+		{"Attributes", work.Attributes},
+		{"Modified", work.ModifiedAt},
+	})
+	return nil
+}
+
+func worksUpdate(e *env, args []string) error {
+	id, err := parseID(args, "workID")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("works update", flag.ContinueOnError)
+	title := fs.String("title", "", "new title")
+	workType := fs.String("type", "", "new type")
+	year := fs.String("year", "", "new year")
+	status := fs.String("status", "", "new status")
+	quality := fs.String("quality", "", "new quality")
+	words := fs.Int("words", -1, "new word count")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	database, err := e.openDB()
+	if err != nil {
+		return err
+	}
+
+	work, err := database.GetWork(id)
+	if err != nil {
+		return err
+	}
+	if work == nil {
+		return fmt.Errorf("work %d not found", id)
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "title":
+			work.Title = *title
+		case "type":
+			work.Type = *workType
+		case "year":
+			work.Year = year
+		case "status":
+			work.Status = *status
+		case "quality":
+			work.Quality = *quality
+		case "words":
+			work.NWords = words
+		}
+	})
+
+	result, err := database.UpdateWork(work)
+	if err != nil {
+		return err
+	}
+	if err := checkValidation(result); err != nil {
+		return err
+	}
+
+	if e.jsonOut {
+		return printJSON(work)
+	}
+	fmt.Printf("Updated work %d (%s)\n", work.WorkID, work.Title)
+	return nil
+}
+
+func parseID(args []string, name string) (int64, error) {
+	if len(args) == 0 {
+		return 0, fmt.Errorf("%s is required", name)
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", name, args[0])
+	}
+	return id, nil
+}
+
+func checkValidation(result *validation.ValidationResult) error {
+	if result == nil || result.IsValid() {
+		return nil
+	}
+	msgs := make([]string, 0, len(result.Errors))
+	for _, fe := range result.Errors {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", fe.Field, fe.Message))
+	}
+	return fmt.Errorf("validation failed: %s", strings.Join(msgs, "; "))
+}
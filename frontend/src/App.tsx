@@ -3,7 +3,13 @@ import { Routes, Route, useLocation, useNavigate } from 'react-router-dom';
 import { AppShell } from '@mantine/core';
 import { WindowGetPosition, WindowGetSize, EventsOn, EventsOff } from '@wailsjs/runtime/runtime';
 import { Navigation } from '@/components/Navigation';
-import { SearchModal, BackupRestoreModal, ImportReviewModal, ImportConfirmModal } from '@/modals';
+import {
+  SearchModal,
+  BackupRestoreModal,
+  ImportReviewModal,
+  ImportConfirmModal,
+  WithdrawalChecklistModal,
+} from '@/modals';
 import { StatusBar } from '@/components';
 import { SetupWizard } from '@/components/SetupWizard';
 import { SplashScreen } from '@trueblocks/ui';
@@ -29,7 +35,7 @@ import {
 } from '@app';
 import { notifications } from '@mantine/notifications';
 import { Log, LogErr } from '@/utils';
-import type { app } from '@models';
+import type { app, models } from '@models';
 
 type ImportResult = app.ImportResult;
 type FileEdit = app.FileEdit;
@@ -50,6 +56,8 @@ function App() {
   const [showSplash, setShowSplash] = useState(true);
   const [searchOpen, setSearchOpen] = useState(false);
   const [backupOpen, setBackupOpen] = useState(false);
+  const [withdrawalChecklist, setWithdrawalChecklist] =
+    useState<models.WithdrawalChecklist | null>(null);
   const [showWizard, setShowWizard] = useState(false);
   const [wizardChecked, setWizardChecked] = useState(false);
   const [importResult, setImportResult] = useState<ImportResult | null>(null);
@@ -161,6 +169,14 @@ function App() {
     return () => EventsOff('watcher:error');
   }, []);
 
+  // When an acceptance leaves simultaneous submissions pending, offer to withdraw them
+  useEffect(() => {
+    EventsOn('submission:accepted', (checklist: models.WithdrawalChecklist) => {
+      setWithdrawalChecklist(checklist);
+    });
+    return () => EventsOff('submission:accepted');
+  }, []);
+
   const handleAddType = useCallback(async (newType: string) => {
     try {
       const result = await AddTypeAndContinue(newType);
@@ -370,6 +386,10 @@ function App() {
       <SetupWizard opened={showWizard} onComplete={() => setShowWizard(false)} />
       <SearchModal opened={searchOpen} onClose={() => setSearchOpen(false)} />
       <BackupRestoreModal opened={backupOpen} onClose={() => setBackupOpen(false)} />
+      <WithdrawalChecklistModal
+        checklist={withdrawalChecklist}
+        onClose={() => setWithdrawalChecklist(null)}
+      />
       <ImportConfirmModal
         opened={importConfirmOpen}
         onClose={() => setImportConfirmOpen(false)}
@@ -5,6 +5,7 @@ import {
   IconArrowsExchange,
   IconCopy,
   IconDownload,
+  IconEyeOff,
   IconFileText,
   IconRefresh,
 } from '@tabler/icons-react';
@@ -12,6 +13,7 @@ import {
   BackupWork,
   CheckWorkPath,
   CleanDocxStyles,
+  ExportBlindToSubmissions,
   ExportToSubmissions,
   GetSupportingInfo,
   GetWorkBookAuditStatus,
@@ -144,6 +146,29 @@ export function FileActionsToolbar({
     }
   };
 
+  const handleExportBlind = async () => {
+    try {
+      const report = await ExportBlindToSubmissions(workID);
+      Log('Exported blind copy to:', report.path, 'removed:', report.removed.join('; '));
+      notifications.show({
+        title: 'Blind copy exported',
+        message:
+          report.removed.length > 0
+            ? `Removed ${report.removed.join('; ')}`
+            : 'Nothing identifying was found',
+        color: 'green',
+        autoClose: 8000,
+      });
+    } catch (err) {
+      LogErr('Failed to export blind copy:', err);
+      notifications.show({
+        message: String(err) || 'Export failed',
+        color: 'red',
+        autoClose: 5000,
+      });
+    }
+  };
+
   const handleBackup = useCallback(async () => {
     try {
       await BackupWork(workID);
@@ -411,6 +436,17 @@ export function FileActionsToolbar({
           </ActionIcon>
         </Tooltip>
 
+        <Tooltip label="Export anonymized copy for blind reading">
+          <ActionIcon
+            variant="light"
+            color="grape"
+            onClick={handleExportBlind}
+            disabled={!fileExists || !currentPath.toLowerCase().endsWith('.docx')}
+          >
+            <IconEyeOff size={18} />
+          </ActionIcon>
+        </Tooltip>
+
         <Tooltip
           label={
             !fileExists
@@ -239,7 +239,8 @@ export function BackupRestoreModal({ opened, onClose }: BackupRestoreModalProps)
         )}
 
         <Text size="xs" c="dimmed">
-          Backups are created automatically daily and kept for 30 days (max 10).
+          Backups are verified after writing and created automatically once a day when the
+          database has changed. Retention and contents are set in Settings → Backups.
         </Text>
       </Stack>
 
@@ -0,0 +1,229 @@
+import { useState, useEffect, useMemo } from 'react';
+import {
+  Modal,
+  MultiSelect,
+  Select,
+  Switch,
+  Button,
+  Group,
+  Stack,
+  NumberInput,
+  Text,
+  Alert,
+} from '@mantine/core';
+import { DateInput } from '@mantine/dates';
+import { notifications } from '@mantine/notifications';
+import { IconAlertTriangle, IconEyeOff } from '@tabler/icons-react';
+import { BuildSubmissionPacket, GetWorks, GetDistinctValues } from '@app';
+import { app, models } from '@models';
+import { Log, LogErr } from '@/utils';
+import dayjs from 'dayjs';
+
+interface PacketBuilderModalProps {
+  opened: boolean;
+  onClose: () => void;
+  onBuilt?: (result: app.PacketResult) => void;
+  orgID: number;
+  orgName: string;
+}
+
+// PacketBuilderModal merges several works into one file for a journal
+// and logs a submission of each
+export function PacketBuilderModal({
+  opened,
+  onClose,
+  onBuilt,
+  orgID,
+  orgName,
+}: PacketBuilderModalProps) {
+  const [workIDs, setWorkIDs] = useState<string[]>([]);
+  const [isBlind, setIsBlind] = useState(false);
+  const [includeHeader, setIncludeHeader] = useState(true);
+  const [logSubmissions, setLogSubmissions] = useState(true);
+  const [submissionDate, setSubmissionDate] = useState<string | null>(
+    dayjs().format('YYYY-MM-DD')
+  );
+  const [submissionType, setSubmissionType] = useState<string | null>(null);
+  const [cost, setCost] = useState<number | string>('');
+  const [warnings, setWarnings] = useState<string[]>([]);
+  const [removed, setRemoved] = useState<string[]>([]);
+
+  const [works, setWorks] = useState<models.WorkView[]>([]);
+  const [typeOptions, setTypeOptions] = useState<string[]>([]);
+  const [loading, setLoading] = useState(false);
+
+  useEffect(() => {
+    if (!opened) return;
+    GetWorks()
+      .then((w) => setWorks(w || []))
+      .catch((err) => LogErr('Failed to load works:', err));
+    GetDistinctValues('Submissions', 'submission_type')
+      .then((v) => setTypeOptions(v || []))
+      .catch((err) => LogErr('Failed to load submission types:', err));
+  }, [opened]);
+
+  const workOptions = useMemo(
+    () =>
+      works
+        .filter((w) => w.docType === 'docx')
+        .map((w) => ({ value: String(w.workID), label: w.title || `Work #${w.workID}` })),
+    [works]
+  );
+
+  const handleBuild = async () => {
+    if (workIDs.length === 0) return;
+
+    setLoading(true);
+    try {
+      const result = await BuildSubmissionPacket(
+        new app.PacketRequest({
+          orgID,
+          workIDs: workIDs.map(Number),
+          isBlind,
+          includeHeader,
+          logSubmissions,
+          submissionDate: submissionDate || undefined,
+          submissionType: submissionType || undefined,
+          cost: cost !== '' ? Number(cost) : undefined,
+        })
+      );
+      Log('Packet written to:', result.pdfPath || result.docPath);
+      notifications.show({
+        title: 'Packet built',
+        message: logSubmissions
+          ? `${result.submissionIDs.length} submissions logged to ${orgName}`
+          : 'Saved to the Submissions folder',
+        color: 'green',
+        autoClose: 3000,
+      });
+      onBuilt?.(result);
+      if (result.warnings.length > 0 || result.removed.length > 0) {
+        setWarnings(result.warnings);
+        setRemoved(result.removed);
+      } else {
+        handleClose();
+      }
+    } catch (err) {
+      LogErr('Failed to build packet:', err);
+      notifications.show({
+        title: 'Error',
+        message: String(err) || 'Failed to build packet',
+        color: 'red',
+        autoClose: 5000,
+      });
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleClose = () => {
+    setWorkIDs([]);
+    setIsBlind(false);
+    setIncludeHeader(true);
+    setLogSubmissions(true);
+    setSubmissionDate(dayjs().format('YYYY-MM-DD'));
+    setSubmissionType(null);
+    setCost('');
+    setWarnings([]);
+    setRemoved([]);
+    onClose();
+  };
+
+  const built = warnings.length > 0 || removed.length > 0;
+
+  return (
+    <Modal opened={opened} onClose={handleClose} title={`Packet for ${orgName}`} size="md">
+      <Stack gap="md">
+        <MultiSelect
+          label="Works"
+          description="In the order they should appear"
+          placeholder="Select works..."
+          data={workOptions}
+          value={workIDs}
+          onChange={setWorkIDs}
+          searchable
+          nothingFoundMessage="No Word documents found"
+        />
+
+        <Switch
+          label="Blind copy"
+          description="Leave out the contact header and strip the author from the files"
+          checked={isBlind}
+          onChange={(e) => setIsBlind(e.currentTarget.checked)}
+        />
+        <Switch
+          label="Contact header"
+          description="Set the header text in Settings"
+          checked={includeHeader && !isBlind}
+          disabled={isBlind}
+          onChange={(e) => setIncludeHeader(e.currentTarget.checked)}
+        />
+        <Switch
+          label="Log submissions"
+          checked={logSubmissions}
+          onChange={(e) => setLogSubmissions(e.currentTarget.checked)}
+        />
+
+        {logSubmissions && (
+          <>
+            <DateInput
+              label="Submission Date"
+              value={submissionDate}
+              onChange={setSubmissionDate}
+              valueFormat="MM/DD/YYYY"
+            />
+            <Select
+              label="Submission Type"
+              placeholder="Select type..."
+              data={typeOptions}
+              value={submissionType}
+              onChange={setSubmissionType}
+              searchable
+              clearable
+            />
+            <NumberInput
+              label="Cost"
+              placeholder="0.00"
+              value={cost}
+              onChange={setCost}
+              min={0}
+              decimalScale={2}
+              prefix="$"
+            />
+          </>
+        )}
+
+        {removed.length > 0 && (
+          <Alert color="blue" icon={<IconEyeOff size={16} />} title="Anonymized">
+            {removed.map((r) => (
+              <Text key={r} size="sm">
+                {r}
+              </Text>
+            ))}
+          </Alert>
+        )}
+
+        {warnings.length > 0 && (
+          <Alert color="yellow" icon={<IconAlertTriangle size={16} />} title="Built with warnings">
+            {warnings.map((w) => (
+              <Text key={w} size="sm">
+                {w}
+              </Text>
+            ))}
+          </Alert>
+        )}
+
+        <Group justify="flex-end" mt="md">
+          <Button variant="subtle" onClick={handleClose} disabled={loading}>
+            {built ? 'Close' : 'Cancel'}
+          </Button>
+          {!built && (
+            <Button onClick={handleBuild} loading={loading} disabled={workIDs.length === 0}>
+              Build Packet
+            </Button>
+          )}
+        </Group>
+      </Stack>
+    </Modal>
+  );
+}
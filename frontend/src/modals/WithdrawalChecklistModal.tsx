@@ -0,0 +1,105 @@
+import { useState, useEffect } from 'react';
+import { Modal, Stack, Group, Text, Button, Table, Checkbox, Anchor } from '@mantine/core';
+import { notifications } from '@mantine/notifications';
+import { WithdrawConflictingSubmissions } from '@app';
+import { models } from '@models';
+import { BrowserOpenURL } from '@wailsjs/runtime/runtime';
+import { LogErr } from '@/utils';
+
+interface WithdrawalChecklistModalProps {
+  checklist: models.WithdrawalChecklist | null;
+  onClose: () => void;
+}
+
+export function WithdrawalChecklistModal({ checklist, onClose }: WithdrawalChecklistModalProps) {
+  const [selected, setSelected] = useState<number[]>([]);
+  const [withdrawing, setWithdrawing] = useState(false);
+
+  useEffect(() => {
+    setSelected(checklist?.items.map((item) => item.submissionID) || []);
+  }, [checklist]);
+
+  if (!checklist) return null;
+
+  const toggle = (id: number) => {
+    setSelected((prev) => (prev.includes(id) ? prev.filter((x) => x !== id) : [...prev, id]));
+  };
+
+  const handleWithdraw = async () => {
+    setWithdrawing(true);
+    try {
+      const result = await WithdrawConflictingSubmissions(checklist.acceptedID, selected, '');
+      if (result.errors.length > 0) {
+        notifications.show({
+          title: 'Some withdrawals failed',
+          message: result.errors.join('\n'),
+          color: 'red',
+        });
+      } else {
+        notifications.show({
+          message: `Marked ${result.withdrawn.length} submission(s) withdrawn`,
+          color: 'green',
+        });
+      }
+      onClose();
+    } catch (err) {
+      LogErr('Failed to withdraw submissions:', err);
+    } finally {
+      setWithdrawing(false);
+    }
+  };
+
+  return (
+    <Modal opened onClose={onClose} title="Withdraw simultaneous submissions" size="lg">
+      <Stack>
+        <Text size="sm">
+          <b>{checklist.title}</b> was accepted by <b>{checklist.orgName}</b>. Notify these
+          publications, then mark them withdrawn.
+        </Text>
+        <Table>
+          <Table.Thead>
+            <Table.Tr>
+              <Table.Th />
+              <Table.Th>Organization</Table.Th>
+              <Table.Th>Title</Table.Th>
+              <Table.Th>Submitted</Table.Th>
+              <Table.Th>Reason</Table.Th>
+            </Table.Tr>
+          </Table.Thead>
+          <Table.Tbody>
+            {checklist.items.map((item) => (
+              <Table.Tr key={item.submissionID}>
+                <Table.Td>
+                  <Checkbox
+                    checked={selected.includes(item.submissionID)}
+                    onChange={() => toggle(item.submissionID)}
+                  />
+                </Table.Td>
+                <Table.Td>
+                  {item.webAddress ? (
+                    <Anchor size="sm" onClick={() => BrowserOpenURL(item.webAddress)}>
+                      {item.orgName}
+                    </Anchor>
+                  ) : (
+                    item.orgName
+                  )}
+                </Table.Td>
+                <Table.Td>{item.title}</Table.Td>
+                <Table.Td>{item.submissionDate.slice(0, 10)}</Table.Td>
+                <Table.Td>{item.reason}</Table.Td>
+              </Table.Tr>
+            ))}
+          </Table.Tbody>
+        </Table>
+        <Group justify="flex-end">
+          <Button variant="default" onClick={onClose}>
+            Later
+          </Button>
+          <Button onClick={handleWithdraw} loading={withdrawing} disabled={selected.length === 0}>
+            Mark Withdrawn
+          </Button>
+        </Group>
+      </Stack>
+    </Modal>
+  );
+}
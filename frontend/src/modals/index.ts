@@ -9,3 +9,5 @@ export { ImportReviewModal } from './ImportReviewModal';
 export { PartSelectionModal } from './PartSelectionModal';
 export { BatchUpdateModal } from './BatchUpdateModal';
 export { CreateSubmissionModal } from './CreateSubmissionModal';
+export { WithdrawalChecklistModal } from './WithdrawalChecklistModal';
+export { PacketBuilderModal } from './PacketBuilderModal';
@@ -245,9 +245,9 @@ export function CollectionsList({ onCollectionClick, onFilteredDataChange }: Col
       },
       {
         key: 'nItems',
-        label: 'Works',
+        label: 'Items',
         width: '10%',
-        render: (c) => c.nItems,
+        render: (c) => (c.entity && c.entity !== 'works' ? `${c.nItems} ${c.entity}` : c.nItems),
       },
       {
         key: 'modifiedAt',
@@ -2,10 +2,11 @@ import { useCallback, useEffect, useRef, useState, useMemo } from 'react';
 import { useNavigate, useParams } from 'react-router-dom';
 import { IconList, IconFileText } from '@tabler/icons-react';
 import { TabView, Tab } from '@/components';
-import { GetAppState, SetTab, GetCollections } from '@app';
+import { GetAppState, SetTab, GetCollections, GetCollection } from '@app';
 import { NavigationProvider } from '@trueblocks/scaffold';
 import { CollectionsList } from './CollectionsList';
 import { CollectionDetail } from './CollectionDetail';
+import { SavedQueryDetail } from './SavedQueryDetail';
 import { models } from '@models';
 
 export function CollectionsPage() {
@@ -15,6 +16,7 @@ export function CollectionsPage() {
   const [filteredSortedCollections, setFilteredSortedCollections] = useState<
     models.CollectionView[]
   >([]);
+  const [entity, setEntity] = useState<{ collID: number; entity: string } | null>(null);
   const lastCollectionIdRef = useRef<number | undefined>(undefined);
   const hasInitialized = useRef(false);
 
@@ -24,6 +26,13 @@ export function CollectionsPage() {
     }
   }, [collectionId]);
 
+  useEffect(() => {
+    if (collectionId === undefined) return;
+    GetCollection(collectionId).then((coll) =>
+      setEntity({ collID: collectionId, entity: coll?.entity || 'works' })
+    );
+  }, [collectionId]);
+
   useEffect(() => {
     if (hasInitialized.current) return;
     hasInitialized.current = true;
@@ -87,17 +96,25 @@ export function CollectionsPage() {
         value: 'detail',
         label: 'Detail',
         icon: <IconFileText size={16} />,
-        content: collectionId ? (
+        content: !collectionId ? (
+          <div>Select a collection to view details</div>
+        ) : entity?.collID !== collectionId ? null : entity.entity !== 'works' ? (
+          <SavedQueryDetail collectionId={collectionId} />
+        ) : (
           <CollectionDetail
             collectionId={collectionId}
             filteredCollections={filteredSortedCollections}
           />
-        ) : (
-          <div>Select a collection to view details</div>
         ),
       },
     ],
-    [collectionId, handleCollectionClick, handleFilteredDataChange, filteredSortedCollections]
+    [
+      collectionId,
+      entity,
+      handleCollectionClick,
+      handleFilteredDataChange,
+      filteredSortedCollections,
+    ]
   );
 
   return (
@@ -20,6 +20,7 @@ import {
   IconClock,
   IconCalendar,
   IconChartPie,
+  IconFilter,
 } from '@tabler/icons-react';
 import { GetDashboardStats, SetTableState, GetAppState, SetDashboardTimeframe } from '@app';
 import { app, state } from '@models';
@@ -416,11 +417,26 @@ export function DashboardPage() {
               </Group>
             </div>
           </Group>
-          {stats.pendingAlerts && stats.pendingAlerts.length > 0 && (
-            <Badge color="orange" variant="light" size="lg" leftSection={<IconClock size={14} />}>
-              {stats.pendingAlerts.length} pending 60+ days
-            </Badge>
-          )}
+          <Group gap="xs">
+            {stats.savedQueries?.map((q) => (
+              <Badge
+                key={q.collID}
+                color="blue"
+                variant="light"
+                size="lg"
+                leftSection={<IconFilter size={14} />}
+                style={{ cursor: 'pointer' }}
+                onClick={() => navigate(`/collections/${q.collID}`)}
+              >
+                {q.name}: {q.count}
+              </Badge>
+            ))}
+            {stats.pendingAlerts && stats.pendingAlerts.length > 0 && (
+              <Badge color="orange" variant="light" size="lg" leftSection={<IconClock size={14} />}>
+                {stats.pendingAlerts.length} pending 60+ days
+              </Badge>
+            )}
+          </Group>
         </Group>
       </Paper>
 
@@ -1,7 +1,7 @@
 import { useState, useEffect, useCallback, useRef, useMemo } from 'react';
 import { useNavigate, useLocation } from 'react-router-dom';
 import { Stack, Grid, Loader, Flex, Text, Group, Badge, Button, Tooltip } from '@mantine/core';
-import { IconExternalLink, IconBuilding } from '@tabler/icons-react';
+import { IconExternalLink, IconBuilding, IconFiles } from '@tabler/icons-react';
 import { useHotkeys } from '@mantine/hooks';
 import { notifications } from '@mantine/notifications';
 import { LogErr, showValidationResult } from '@/utils';
@@ -27,7 +27,7 @@ import {
 import { models, db } from '@models';
 import { OrganizationDetailPanel } from './OrganizationDetailPanel';
 import { NotesPortal, SubmissionsPortal } from '@/portals';
-import { ConfirmDeleteModal, CreateSubmissionModal } from '@/modals';
+import { ConfirmDeleteModal, CreateSubmissionModal, PacketBuilderModal } from '@/modals';
 import { DetailHeader, EditableField, EntityFieldSelect } from '@trueblocks/ui';
 import { orgStatusColors } from '@/types';
 
@@ -54,6 +54,7 @@ export function OrganizationDetail({
   const [deleteConfirmation, setDeleteConfirmation] = useState<db.DeleteConfirmation | null>(null);
   const [deleteLoading, setDeleteLoading] = useState(false);
   const [submissionModalOpen, setSubmissionModalOpen] = useState(false);
+  const [packetModalOpen, setPacketModalOpen] = useState(false);
 
   const {
     notes,
@@ -456,6 +457,14 @@ export function OrganizationDetail({
                 Duotrope
               </Button>
             )}
+            <Button
+              size="xs"
+              variant="light"
+              leftSection={<IconFiles size={14} />}
+              onClick={() => setPacketModalOpen(true)}
+            >
+              Packet
+            </Button>
           </Group>
         }
         isDeleted={org.attributes?.includes('deleted')}
@@ -502,6 +511,16 @@ export function OrganizationDetail({
                 setSubmissions(updated || []);
               }}
             />
+            <PacketBuilderModal
+              opened={packetModalOpen}
+              onClose={() => setPacketModalOpen(false)}
+              orgID={organizationId}
+              orgName={org.name}
+              onBuilt={async () => {
+                const updated = await GetSubmissionViewsByOrg(organizationId);
+                setSubmissions(updated || []);
+              }}
+            />
             <NotesPortal
               title="Journal Notes"
               notes={notes}
@@ -1,4 +1,4 @@
-import { Paper, SimpleGrid, Text, Stack, Box } from '@mantine/core';
+import { Paper, SimpleGrid, Text, Stack, Box, Switch } from '@mantine/core';
 import { models } from '@models';
 import { EditableField, EntityFieldSelect } from '@trueblocks/ui';
 import { GetDistinctValues, UpdateOrganization } from '@app';
@@ -34,6 +34,14 @@ export function OrganizationDetailPanel({ org, onUpdate }: OrganizationDetailPan
     if (showValidationResult(result)) return { hasErrors: true };
   }, []);
 
+  const attributes = (org.attributes || '').split(',').filter(Boolean);
+  const allowsSimultaneous = !attributes.includes('no-simultaneous');
+
+  const handleSimultaneousChange = (allowed: boolean) => {
+    const rest = attributes.filter((a) => a !== 'no-simultaneous');
+    handleFieldChange('attributes', (allowed ? rest : [...rest, 'no-simultaneous']).join(','));
+  };
+
   const loadMyInterestOptions = useMemo(
     () => () => GetDistinctValues('Organizations', 'my_interest').then((v) => v || []),
     []
@@ -55,6 +63,12 @@ export function OrganizationDetailPanel({ org, onUpdate }: OrganizationDetailPan
             value={org.otherURL || ''}
             onChange={(value) => handleFieldChange('otherURL', value)}
           />
+          <EditableField
+            label="File Naming"
+            placeholder="{author} - {titles}"
+            value={org.fileNaming || ''}
+            onChange={(value) => handleFieldChange('fileNaming', value)}
+          />
         </SimpleGrid>
 
         <SimpleGrid cols={{ base: 1, sm: 2, md: 3 }} spacing="md">
@@ -74,6 +88,17 @@ export function OrganizationDetailPanel({ org, onUpdate }: OrganizationDetailPan
               onError={(err, field) => LogErr(`Failed to update ${field}:`, err)}
             />
           </Box>
+          <Box>
+            <Text size="xs" c="dimmed" tt="uppercase" fw={500}>
+              Simultaneous Submissions
+            </Text>
+            <Switch
+              mt={4}
+              checked={allowsSimultaneous}
+              label={allowsSimultaneous ? 'Allowed' : 'Not allowed'}
+              onChange={(e) => handleSimultaneousChange(e.currentTarget.checked)}
+            />
+          </Box>
           <Field label="Source" value={org.source} />
           <Field label="Duotrope #" value={org.duotropeNum} />
           <Field label="Website Menu" value={org.websiteMenu} />
@@ -26,6 +26,8 @@ import {
   IconChevronDown,
   IconChevronRight,
   IconCircleCheck,
+  IconFilter,
+  IconBulb,
 } from '@tabler/icons-react';
 import { EventsOn } from '@wailsjs/runtime/runtime';
 import { StartReportGeneration, RefreshReport, GetReportNames } from '@app';
@@ -64,6 +66,8 @@ const iconMap: Record<string, React.ReactNode> = {
   IconNote: <IconNote size={16} />,
   IconAlertTriangle: <IconAlertTriangle size={16} />,
   IconHistory: <IconHistory size={16} />,
+  IconFilter: <IconFilter size={16} />,
+  IconBulb: <IconBulb size={16} />,
 };
 
 const entityTypeIcon: Record<string, React.ReactNode> = {
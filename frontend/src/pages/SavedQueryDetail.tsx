@@ -0,0 +1,198 @@
+import { useState, useEffect, useCallback } from 'react';
+import { useNavigate } from 'react-router-dom';
+import {
+  Stack,
+  Group,
+  Text,
+  TextInput,
+  Button,
+  ActionIcon,
+  Tooltip,
+  Table,
+  Badge,
+  Loader,
+  Flex,
+} from '@mantine/core';
+import { IconFilter, IconRefresh } from '@tabler/icons-react';
+import { notifications } from '@mantine/notifications';
+import {
+  GetCollection,
+  GetCollectionSubmissions,
+  GetCollectionOrganizations,
+  PreviewSmartQuery,
+  SetCollectionSmartQuery,
+} from '@app';
+import { models } from '@models';
+import { LogErr } from '@/utils';
+
+interface SavedQueryDetailProps {
+  collectionId: number;
+}
+
+// SavedQueryDetail shows a collection whose smart query selects
+// submissions or organizations rather than works
+export function SavedQueryDetail({ collectionId }: SavedQueryDetailProps) {
+  const navigate = useNavigate();
+  const [collection, setCollection] = useState<models.Collection | null>(null);
+  const [submissions, setSubmissions] = useState<models.SubmissionView[]>([]);
+  const [organizations, setOrganizations] = useState<models.Organization[]>([]);
+  const [query, setQuery] = useState('');
+  const [queryError, setQueryError] = useState('');
+  const [loading, setLoading] = useState(true);
+
+  const loadData = useCallback(async () => {
+    try {
+      const coll = await GetCollection(collectionId);
+      setCollection(coll);
+      setQuery(coll?.smartQuery || '');
+      if (coll?.entity === 'submissions') {
+        setSubmissions((await GetCollectionSubmissions(collectionId)) || []);
+      } else if (coll?.entity === 'organizations') {
+        setOrganizations((await GetCollectionOrganizations(collectionId)) || []);
+      }
+    } catch (err) {
+      LogErr(`Failed to load saved query ${collectionId}:`, err);
+    } finally {
+      setLoading(false);
+    }
+  }, [collectionId]);
+
+  useEffect(() => {
+    loadData();
+  }, [loadData]);
+
+  useEffect(() => {
+    const handleReload = () => loadData();
+    window.addEventListener('reloadCurrentView', handleReload);
+    return () => window.removeEventListener('reloadCurrentView', handleReload);
+  }, [loadData]);
+
+  const handleSave = useCallback(async () => {
+    if (!collection) return;
+    const preview = await PreviewSmartQuery(collection.entity, query, 0);
+    if (preview.error) {
+      setQueryError(`Column ${preview.errorPos + 1}: ${preview.error}`);
+      return;
+    }
+    try {
+      await SetCollectionSmartQuery(collectionId, preview.query);
+      setQueryError('');
+      loadData();
+    } catch (err) {
+      notifications.show({ message: String(err), color: 'red', autoClose: 5000 });
+    }
+  }, [collection, collectionId, query, loadData]);
+
+  if (loading) {
+    return (
+      <Flex justify="center" align="center" h="100%">
+        <Loader />
+      </Flex>
+    );
+  }
+
+  if (!collection) {
+    return (
+      <Flex justify="center" align="center" h="100%">
+        <Text c="dimmed">Collection not found</Text>
+      </Flex>
+    );
+  }
+
+  const count = collection.entity === 'submissions' ? submissions.length : organizations.length;
+
+  return (
+    <Stack gap="md">
+      <Group gap="md" align="center">
+        <IconFilter size={20} color="var(--mantine-color-blue-6)" />
+        <Text size="xl">{collection.collectionName}</Text>
+        <Text c="dark.3" size="md">
+          (#{collection.collID})
+        </Text>
+        <Badge variant="light">{collection.entity}</Badge>
+        <Text size="sm" c="dimmed">
+          {count} selected
+        </Text>
+        <Tooltip label="Refresh">
+          <ActionIcon variant="light" ml="auto" onClick={loadData} aria-label="Refresh">
+            <IconRefresh size={18} />
+          </ActionIcon>
+        </Tooltip>
+      </Group>
+
+      <Group align="flex-start">
+        <TextInput
+          style={{ flex: 1 }}
+          value={query}
+          error={queryError || undefined}
+          onChange={(e) => setQuery(e.currentTarget.value)}
+          onKeyDown={(e) => e.key === 'Enter' && handleSave()}
+          placeholder={
+            collection.entity === 'submissions'
+              ? 'is:pending age>120'
+              : 'status:Open accepts:poetry ranking<=3 -has:submission'
+          }
+        />
+        <Button onClick={handleSave} disabled={query === (collection.smartQuery || '')}>
+          Save
+        </Button>
+      </Group>
+
+      {collection.entity === 'submissions' ? (
+        <Table striped highlightOnHover>
+          <Table.Thead>
+            <Table.Tr>
+              <Table.Th>Work</Table.Th>
+              <Table.Th>Journal</Table.Th>
+              <Table.Th>Submitted</Table.Th>
+              <Table.Th>Response</Table.Th>
+              <Table.Th>Responded</Table.Th>
+            </Table.Tr>
+          </Table.Thead>
+          <Table.Tbody>
+            {submissions.map((s) => (
+              <Table.Tr
+                key={s.submissionID}
+                style={{ cursor: 'pointer' }}
+                onClick={() => navigate(`/submissions/${s.submissionID}`)}
+              >
+                <Table.Td>{s.titleOfWork}</Table.Td>
+                <Table.Td>{s.journalName}</Table.Td>
+                <Table.Td>{s.submissionDate || '-'}</Table.Td>
+                <Table.Td>{s.responseType || '-'}</Table.Td>
+                <Table.Td>{s.responseDate || '-'}</Table.Td>
+              </Table.Tr>
+            ))}
+          </Table.Tbody>
+        </Table>
+      ) : (
+        <Table striped highlightOnHover>
+          <Table.Thead>
+            <Table.Tr>
+              <Table.Th>Name</Table.Th>
+              <Table.Th>Status</Table.Th>
+              <Table.Th>Type</Table.Th>
+              <Table.Th>Accepts</Table.Th>
+              <Table.Th>Ranking</Table.Th>
+            </Table.Tr>
+          </Table.Thead>
+          <Table.Tbody>
+            {organizations.map((o) => (
+              <Table.Tr
+                key={o.orgID}
+                style={{ cursor: 'pointer' }}
+                onClick={() => navigate(`/organizations/${o.orgID}`)}
+              >
+                <Table.Td>{o.name}</Table.Td>
+                <Table.Td>{o.status}</Table.Td>
+                <Table.Td>{o.type}</Table.Td>
+                <Table.Td>{o.accepts || '-'}</Table.Td>
+                <Table.Td>{o.ranking ?? '-'}</Table.Td>
+              </Table.Tr>
+            ))}
+          </Table.Tbody>
+        </Table>
+      )}
+    </Stack>
+  );
+}
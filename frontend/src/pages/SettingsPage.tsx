@@ -2,6 +2,7 @@ import { useState, useEffect } from 'react';
 import {
   Stack,
   TextInput,
+  Textarea,
   Button,
   Group,
   Text,
@@ -11,6 +12,10 @@ import {
   Switch,
   Select,
   PasswordInput,
+  NumberInput,
+  SimpleGrid,
+  MultiSelect,
+  Badge,
 } from '@mantine/core';
 import { notifications } from '@mantine/notifications';
 import {
@@ -21,9 +26,17 @@ import {
   IconPlayerPlay,
   IconSearch,
   IconBrain,
+  IconArchive,
+  IconFileTypePdf,
 } from '@tabler/icons-react';
-import { GetSettings, UpdateSettings, BrowseForFolder, ClearAnalysisTabs } from '@app';
-import { settings } from '@models';
+import {
+  GetSettings,
+  UpdateSettings,
+  BrowseForFolder,
+  ClearAnalysisTabs,
+  GetPDFConverters,
+} from '@app';
+import { settings, fileops } from '@models';
 import { TabView, Tab, EnumManagement, FTSStatus } from '@/components';
 import { SplashScreen } from '@trueblocks/ui';
 import { ThemeSelector } from '@/components/ThemeSelector';
@@ -36,6 +49,7 @@ export function SettingsPage() {
   const [saving, setSaving] = useState(false);
   const [saved, setSaved] = useState(false);
   const [showSplashPreview, setShowSplashPreview] = useState(false);
+  const [converters, setConverters] = useState<fileops.ConverterStatus[]>([]);
   const { setPageTabs } = useTabContext();
 
   const loadData = () => {
@@ -49,10 +63,16 @@ export function SettingsPage() {
     loadData();
   }, []);
 
+  useEffect(() => {
+    if (config) {
+      GetPDFConverters().then((c) => setConverters(c || []));
+    }
+  }, [config]);
+
   // Update tab cycle based on analysis feature
   useEffect(() => {
     if (config) {
-      const baseTabs = ['paths', 'field-values', 'search'];
+      const baseTabs = ['paths', 'field-values', 'search', 'backups', 'previews'];
       if (config.analysisEnabled) {
         setPageTabs('settings', [...baseTabs, 'analysis']);
       } else {
@@ -177,6 +197,28 @@ export function SettingsPage() {
                 }
               />
 
+              <TextInput
+                label="Author Name"
+                description="Used in submission packet file names; left out of blind copies"
+                value={config.authorName || ''}
+                onChange={(e) => {
+                  setConfig({ ...config, authorName: e.currentTarget.value });
+                  setSaved(false);
+                }}
+              />
+
+              <Textarea
+                label="Contact Header"
+                description="Printed at the top of submission packets, one line per row"
+                autosize
+                minRows={3}
+                value={config.contactHeader || ''}
+                onChange={(e) => {
+                  setConfig({ ...config, contactHeader: e.currentTarget.value });
+                  setSaved(false);
+                }}
+              />
+
               <TextInput
                 label="Template Folder"
                 description="Folder containing template files for new works"
@@ -252,6 +294,127 @@ export function SettingsPage() {
         </Stack>
       ),
     },
+    {
+      value: 'backups',
+      label: 'Backups',
+      icon: <IconArchive size={16} />,
+      content: (
+        <Stack gap="lg" maw={700}>
+          <Paper p="md" withBorder>
+            <Stack gap="md">
+              <Select
+                label="Compression"
+                description="zstd requires the zstd command; gzip is used when it is missing"
+                value={config.backupCompression || 'gzip'}
+                onChange={(value) => autoSave({ backupCompression: value ?? 'gzip' })}
+                data={[
+                  { value: 'gzip', label: 'gzip' },
+                  { value: 'zstd', label: 'zstd' },
+                  { value: 'none', label: 'None' },
+                ]}
+              />
+
+              <Text size="sm" fw={500}>
+                Retention
+              </Text>
+              <Text size="xs" c="dimmed">
+                Keep the newest backup from each of the most recent days, weeks, months and years.
+              </Text>
+              <SimpleGrid cols={4}>
+                <NumberInput
+                  label="Daily"
+                  min={0}
+                  value={config.backupKeepDaily}
+                  onChange={(v) => autoSave({ backupKeepDaily: Number(v) || 0 })}
+                />
+                <NumberInput
+                  label="Weekly"
+                  min={0}
+                  value={config.backupKeepWeekly}
+                  onChange={(v) => autoSave({ backupKeepWeekly: Number(v) || 0 })}
+                />
+                <NumberInput
+                  label="Monthly"
+                  min={0}
+                  value={config.backupKeepMonthly}
+                  onChange={(v) => autoSave({ backupKeepMonthly: Number(v) || 0 })}
+                />
+                <NumberInput
+                  label="Yearly"
+                  min={0}
+                  value={config.backupKeepYearly}
+                  onChange={(v) => autoSave({ backupKeepYearly: Number(v) || 0 })}
+                />
+              </SimpleGrid>
+
+              <Switch
+                label="Include search index"
+                description="Larger archives, but no index rebuild after a restore"
+                checked={config.backupIncludeFTS}
+                onChange={(e) => autoSave({ backupIncludeFTS: e.currentTarget.checked })}
+              />
+              <Switch
+                label="Include templates"
+                checked={config.backupIncludeTemplates}
+                onChange={(e) => autoSave({ backupIncludeTemplates: e.currentTarget.checked })}
+              />
+              <Switch
+                label="Include settings (config.json)"
+                checked={config.backupIncludeConfig}
+                onChange={(e) => autoSave({ backupIncludeConfig: e.currentTarget.checked })}
+              />
+            </Stack>
+          </Paper>
+        </Stack>
+      ),
+    },
+    {
+      value: 'previews',
+      label: 'Previews',
+      icon: <IconFileTypePdf size={16} />,
+      content: (
+        <Stack gap="lg" maw={700}>
+          <Paper p="md" withBorder>
+            <Stack gap="md">
+              <MultiSelect
+                label="Converters"
+                description="Tried in this order until one succeeds. Leave empty for the platform default."
+                placeholder="Platform default"
+                value={config.pdfConverterPriority || []}
+                onChange={(value) => autoSave({ pdfConverterPriority: value })}
+                data={[
+                  { value: 'word', label: 'Microsoft Word' },
+                  { value: 'excel', label: 'Microsoft Excel' },
+                  { value: 'libreoffice', label: 'LibreOffice' },
+                  { value: 'builtin', label: 'Built-in (text only)' },
+                ]}
+              />
+              <TextInput
+                label="LibreOffice"
+                description="Path to the soffice program; found automatically when empty"
+                value={config.libreOfficePath || ''}
+                onChange={(e) => autoSave({ libreOfficePath: e.currentTarget.value })}
+              />
+              <NumberInput
+                label="Concurrent conversions"
+                description="0 runs one conversion per CPU"
+                min={0}
+                value={config.pdfMaxConversions || 0}
+                onChange={(v) => autoSave({ pdfMaxConversions: Number(v) || 0 })}
+              />
+              <Group gap="xs">
+                {converters.map((c) => (
+                  <Badge key={c.name} color={c.available ? 'green' : 'gray'} variant="light">
+                    {c.name}
+                    {c.available ? '' : ' (not installed)'}
+                  </Badge>
+                ))}
+              </Group>
+            </Stack>
+          </Paper>
+        </Stack>
+      ),
+    },
     {
       value: 'analysis',
       label: 'AI Analysis',
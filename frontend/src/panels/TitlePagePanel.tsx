@@ -355,6 +355,26 @@ export function TitlePagePanel({
                   </Group>
                 </Stack>
               </Collapse>
+              <Switch
+                size="xs"
+                label="Index of titles and first lines"
+                description="End the galley with an alphabetical index; first lines come from the search index"
+                checked={book.firstLineIndex ?? book.bookType === 'poetry'}
+                onChange={(e) => onBookChange({ ...book, firstLineIndex: e.currentTarget.checked })}
+              />
+              <Select
+                size="xs"
+                label="Contents Depth"
+                description="Headings inside works are found on their pages in each work's PDF"
+                value={String(book.tocDepth || 1)}
+                onChange={(value) => onBookChange({ ...book, tocDepth: Number(value || '1') })}
+                data={[
+                  { value: '1', label: 'Works only' },
+                  { value: '2', label: 'Works and Heading 2' },
+                  { value: '3', label: 'Works, Heading 2 and Heading 3' },
+                ]}
+                allowDeselect={false}
+              />
               <Group grow>
                 <Select
                   size="xs"
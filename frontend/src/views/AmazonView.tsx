@@ -372,11 +372,20 @@ export function AmazonView({ collectionId, collectionName: _collectionName }: Am
         </Group>
 
         {readiness ? (
-          <Group align="flex-start" grow>
-            <ValidationSection title="Content" result={readiness.content} />
-            <ValidationSection title="Matter" result={readiness.matter} />
-            <ValidationSection title="Cover" result={readiness.cover} />
-          </Group>
+          <Stack gap="xs">
+            <Group align="flex-start" grow>
+              <ValidationSection title="Content" result={readiness.content} />
+              <ValidationSection title="Matter" result={readiness.matter} />
+              <ValidationSection title="Cover" result={readiness.cover} />
+            </Group>
+            {readiness.preflight && (
+              <Text size="xs" c="dimmed">
+                KDP preflight: {readiness.preflight.pageCount} pages ·{' '}
+                {readiness.preflight.bleed ? 'with bleed' : 'no bleed'} · Spine:{' '}
+                {readiness.preflight.spineMM.toFixed(2)}mm
+              </Text>
+            )}
+          </Stack>
         ) : (
           <Text size="sm" c="dimmed">
             Click &quot;Validate All&quot; to check publication readiness
@@ -14,7 +14,13 @@ import {
   Badge,
   Box,
 } from '@mantine/core';
-import { IconFileTypePdf, IconExternalLink, IconPhoto, IconChecks } from '@tabler/icons-react';
+import {
+  IconFileTypePdf,
+  IconExternalLink,
+  IconPhoto,
+  IconChecks,
+  IconRuler,
+} from '@tabler/icons-react';
 import { notifications } from '@mantine/notifications';
 import { OnFileDrop, OnFileDropOff } from '@wailsjs/runtime/runtime';
 import {
@@ -23,6 +29,7 @@ import {
   SelectCoverImage,
   GetCoverImageData,
   ExportCoverPDF,
+  ExportCoverGuide,
   OpenCoverPDF,
   GetCoverPDFPath,
   ValidateCover,
@@ -198,6 +205,28 @@ export function CoversView({ collectionId, onNavigateToAmazon }: CoversViewProps
     }
   }, [book, collectionId, frontCoverData, galleyInfo]);
 
+  const handleCoverGuide = useCallback(async () => {
+    try {
+      const result = await ExportCoverGuide(collectionId);
+      if (!result) return;
+      if (result.success) {
+        notifications.show({
+          title: 'Cover Guide Saved',
+          message: `Saved to ${result.outputPath}`,
+          color: 'green',
+        });
+      } else {
+        notifications.show({
+          title: 'Cover Guide Failed',
+          message: result.error || 'Unknown error',
+          color: 'red',
+        });
+      }
+    } catch (err) {
+      LogErr('Failed to export cover guide:', err);
+    }
+  }, [collectionId]);
+
   const handleOpenCover = useCallback(async () => {
     try {
       const result = await OpenCoverPDF(collectionId);
@@ -412,6 +441,15 @@ export function CoversView({ collectionId, onNavigateToAmazon }: CoversViewProps
             )}
           </Group>
           <Group gap="xs">
+            <Button
+              size="xs"
+              variant="light"
+              leftSection={<IconRuler size={12} />}
+              onClick={handleCoverGuide}
+              disabled={!galleyInfo?.exists}
+            >
+              Cover Guide
+            </Button>
             <Button
               size="xs"
               variant="light"
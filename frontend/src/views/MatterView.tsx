@@ -12,11 +12,13 @@ import {
   IconExternalLink,
   IconChecks,
   IconCopy,
+  IconBook,
 } from '@tabler/icons-react';
 import {
   GetBookByCollection,
   UpdateBook,
   ExportBookPDFWithParts,
+  ExportBookEPUB,
   OpenBookPDF,
   CopyBookPDFText,
   GetTitlePageStyles,
@@ -184,6 +186,37 @@ export function MatterView({
     [buildHtmlContent, collectionId]
   );
 
+  const handleExportEPUB = useCallback(async () => {
+    const htmlContent = buildHtmlContent(false);
+    if (!htmlContent) return;
+
+    setExporting(true);
+    try {
+      const result = await ExportBookEPUB(collectionId, htmlContent);
+      if (!result) return;
+
+      Log(`EPUB exported to: ${result.outputPath}`);
+      notifications.show({
+        title: 'EPUB Export Complete',
+        message: result.warnings?.length
+          ? `Exported ${result.workCount} works with ${result.warnings.length} warnings`
+          : `Exported ${result.workCount} works in ${result.duration}`,
+        color: result.warnings?.length ? 'yellow' : 'green',
+        autoClose: 8000,
+      });
+    } catch (err) {
+      LogErr('EPUB export failed:', err);
+      notifications.show({
+        title: 'EPUB Export Failed',
+        message: String(err),
+        color: 'red',
+        autoClose: 8000,
+      });
+    } finally {
+      setExporting(false);
+    }
+  }, [buildHtmlContent, collectionId]);
+
   const handleExportPDF = useCallback(async () => {
     // Always show part modal - required for cache invalidation on any build
     setPartModalOpen(true);
@@ -405,6 +438,15 @@ export function MatterView({
             >
               {book.identityHidden ? 'Open Blind Galley' : 'Open Galley'}
             </Button>
+            <Button
+              size="xs"
+              variant="light"
+              leftSection={<IconBook size={12} />}
+              onClick={handleExportEPUB}
+              loading={exporting}
+            >
+              Make EPUB
+            </Button>
             <Button
               size="xs"
               leftSection={<IconFileTypePdf size={12} />}
@@ -2,6 +2,8 @@
 // This file is automatically generated. DO NOT EDIT
 import {app} from '../models';
 import {analysis} from '../models';
+import {revisions} from '../models';
+import {textdiff} from '../models';
 import {backup} from '../models';
 import {models} from '../models';
 import {validation} from '../models';
@@ -10,6 +12,8 @@ import {state} from '../models';
 import {db} from '../models';
 import {fileops} from '../models';
 import {settings} from '../models';
+import {bookbuild} from '../models';
+import {recommend} from '../models';
 
 export function AddExtensionAndContinue(arg1:string):Promise<app.ImportResult>;
 
@@ -47,10 +51,14 @@ export function BatchUpdateWorkField(arg1:Array<number>,arg2:string,arg3:string)
 
 export function BrowseForFolder(arg1:string):Promise<string>;
 
+export function BuildSubmissionPacket(arg1:app.PacketRequest):Promise<app.PacketResult>;
+
 export function CancelBuild():Promise<void>;
 
 export function CancelImport():Promise<void>;
 
+export function CancelJob(arg1:number):Promise<void>;
+
 export function CheckImportConflict(arg1:string):Promise<app.ImportConflict>;
 
 export function CheckLibreOffice():Promise<boolean>;
@@ -65,6 +73,8 @@ export function ClearPartCache(arg1:number,arg2:Array<number>):Promise<void>;
 
 export function CloseStatusBar():Promise<void>;
 
+export function CompareWorkVersions(arg1:number,arg2:revisions.Version,arg3:revisions.Version,arg4:number):Promise<textdiff.Result>;
+
 export function CompleteSetup():Promise<void>;
 
 export function CopyBookPDFText(arg1:number):Promise<app.CopyBookPDFTextResult>;
@@ -87,6 +97,8 @@ export function CreateOrganization(arg1:models.Organization):Promise<validation.
 
 export function CreateSubmission(arg1:models.Submission):Promise<validation.ValidationResult>;
 
+export function CreateSubmissionWindow(arg1:models.SubmissionWindow):Promise<validation.ValidationResult>;
+
 export function CreateWork(arg1:models.Work):Promise<validation.ValidationResult>;
 
 export function DeleteBackup(arg1:string):Promise<void>;
@@ -97,6 +109,8 @@ export function DeleteCollection(arg1:number):Promise<void>;
 
 export function DeleteCollectionPermanent(arg1:number):Promise<void>;
 
+export function DeleteManuscriptSnapshot(arg1:string):Promise<void>;
+
 export function DeleteNote(arg1:number):Promise<void>;
 
 export function DeleteNotePermanent(arg1:number):Promise<void>;
@@ -109,12 +123,18 @@ export function DeleteSubmission(arg1:number):Promise<void>;
 
 export function DeleteSubmissionPermanent(arg1:number):Promise<void>;
 
+export function DeleteSubmissionWindow(arg1:number):Promise<void>;
+
 export function DeleteWork(arg1:number):Promise<void>;
 
 export function DeleteWorkPermanent(arg1:number,arg2:boolean):Promise<void>;
 
 export function DetectLibreOffice():Promise<string>;
 
+export function DiffBookBuilds(arg1:number,arg2:number):Promise<bookbuild.BuildDiff>;
+
+export function DiffWorkRevisions(arg1:number,arg2:number):Promise<textdiff.Result>;
+
 export function DismissAnnotation(arg1:number,arg2:string):Promise<void>;
 
 export function DuplicateWork(arg1:number):Promise<number>;
@@ -127,10 +147,18 @@ export function ExportAllCSV():Promise<Array<app.ExportResult>>;
 
 export function ExportAllTables():Promise<Array<app.ExportResult>>;
 
+export function ExportBlindToSubmissions(arg1:number):Promise<fileops.AnonymizeReport>;
+
+export function ExportBookEPUB(arg1:number,arg2:app.FrontBackMatterHTML):Promise<app.BookExportResult>;
+
 export function ExportBookPDFWithParts(arg1:number,arg2:boolean,arg3:app.FrontBackMatterHTML,arg4:boolean):Promise<app.BookExportResult>;
 
+export function ExportCalendarICS():Promise<string>;
+
 export function ExportCollectionFolder(arg1:number):Promise<number>;
 
+export function ExportCoverGuide(arg1:number):Promise<app.CoverExportResult>;
+
 export function ExportCoverPDF(arg1:number,arg2:string):Promise<app.CoverExportResult>;
 
 export function ExportToSubmissions(arg1:number):Promise<string>;
@@ -153,6 +181,8 @@ export function FTSUpdateIndex():Promise<fts.BuildReport>;
 
 export function GeneratePath(arg1:number):Promise<string>;
 
+export function GetAllOrgResponseStats():Promise<Array<models.OrgResponseStats>>;
+
 export function GetAllSubmissionViews():Promise<Array<models.SubmissionView>>;
 
 export function GetAnalysisEnabled():Promise<boolean>;
@@ -165,10 +195,16 @@ export function GetBatchPDFPageSizes(arg1:Array<number>):Promise<Record<number,
 
 export function GetBook(arg1:number):Promise<models.Book>;
 
+export function GetBookBuild(arg1:number):Promise<models.BookBuild>;
+
 export function GetBookByCollection(arg1:number):Promise<models.Book>;
 
 export function GetBookParts(arg1:number):Promise<Array<app.PartInfo>>;
 
+export function GetCalendarEvents(arg1:string,arg2:string):Promise<Array<models.CalendarEvent>>;
+
+export function GetCalendarURL():Promise<string>;
+
 export function GetCollection(arg1:number):Promise<models.Collection>;
 
 export function GetCollectionAnalysis(arg1:number):Promise<analysis.CollectionResult>;
@@ -183,10 +219,16 @@ export function GetCollectionHasSuppressedWorks(arg1:number):Promise<boolean>;
 
 export function GetCollectionIsBook(arg1:number):Promise<boolean>;
 
+export function GetCollectionOrganizations(arg1:number):Promise<Array<models.Organization>>;
+
+export function GetCollectionSubmissions(arg1:number):Promise<Array<models.SubmissionView>>;
+
 export function GetCollectionWorks(arg1:number):Promise<Array<models.CollectionWork>>;
 
 export function GetCollections():Promise<Array<models.CollectionView>>;
 
+export function GetCoverGeometry(arg1:number):Promise<bookbuild.CoverGeometry>;
+
 export function GetCoverImageData(arg1:string):Promise<string>;
 
 export function GetCoverPDFPath(arg1:number):Promise<string>;
@@ -201,6 +243,8 @@ export function GetDistinctValues(arg1:string,arg2:string):Promise<Array<string>
 
 export function GetDuotropeURL(arg1:number):Promise<string>;
 
+export function GetEntityHistory(arg1:string,arg2:number):Promise<Array<models.AuditEntry>>;
+
 export function GetEnumLists():Promise<app.EnumLists>;
 
 export function GetExportFolderPath():Promise<string>;
@@ -215,10 +259,20 @@ export function GetFileServerPort():Promise<number>;
 
 export function GetGalleyInfo(arg1:number):Promise<app.GalleyInfo>;
 
+export function GetJob(arg1:number):Promise<models.Job>;
+
+export function GetManuscriptHistory(arg1:number):Promise<Array<backup.ManuscriptVersion>>;
+
+export function GetManuscriptSnapshot(arg1:string):Promise<backup.ManuscriptSnapshot>;
+
+export function GetManuscriptSnapshots():Promise<Array<backup.ManuscriptSnapshotInfo>>;
+
 export function GetMarkedWorksInCollection(arg1:number):Promise<Array<app.MarkedWorkInfo>>;
 
 export function GetNotes(arg1:string,arg2:number):Promise<Array<models.Note>>;
 
+export function GetOrgResponseStats(arg1:number):Promise<models.OrgResponseStats>;
+
 export function GetOrganization(arg1:number):Promise<models.Organization>;
 
 export function GetOrganizationDeleteConfirmation(arg1:number):Promise<db.DeleteConfirmation>;
@@ -229,14 +283,22 @@ export function GetOrganizationsWithNotes():Promise<Array<models.OrganizationWit
 
 export function GetOrgsFilterOptions():Promise<app.OrgsFilterOptions>;
 
+export function GetOverdueSubmissions():Promise<Array<models.OverdueSubmission>>;
+
+export function GetPDFConverters():Promise<Array<fileops.ConverterStatus>>;
+
 export function GetPDFPageSize(arg1:number):Promise<string>;
 
+export function GetPacketSubmissions(arg1:number):Promise<Array<models.SubmissionView>>;
+
 export function GetPartCacheStatus(arg1:number):Promise<Record<number, boolean>>;
 
 export function GetPreviewURL(arg1:number):Promise<string>;
 
 export function GetPublicationReadiness(arg1:number):Promise<app.PublicationReadiness>;
 
+export function GetRecentOperations(arg1:number):Promise<Array<models.AuditOperation>>;
+
 export function GetReportNames():Promise<Array<string>>;
 
 export function GetSearchHistory():Promise<Array<string>>;
@@ -247,12 +309,16 @@ export function GetSubmission(arg1:number):Promise<models.Submission>;
 
 export function GetSubmissionDeleteConfirmation(arg1:number):Promise<db.DeleteConfirmation>;
 
+export function GetSubmissionPackets(arg1:number):Promise<Array<models.SubmissionPacket>>;
+
 export function GetSubmissionViewsByCollection(arg1:number):Promise<Array<models.SubmissionView>>;
 
 export function GetSubmissionViewsByOrg(arg1:number):Promise<Array<models.SubmissionView>>;
 
 export function GetSubmissionViewsByWork(arg1:number):Promise<Array<models.SubmissionView>>;
 
+export function GetSubmissionWindows(arg1:number):Promise<Array<models.SubmissionWindow>>;
+
 export function GetSubmissions():Promise<Array<models.Submission>>;
 
 export function GetSubmissionsByWork(arg1:number):Promise<Array<models.Submission>>;
@@ -271,6 +337,8 @@ export function GetTitlePageStyles(arg1:string):Promise<app.TitlePageStyleInfo>;
 
 export function GetValidExtensions():Promise<Array<string>>;
 
+export function GetWithdrawalChecklist(arg1:number):Promise<models.WithdrawalChecklist>;
+
 export function GetWork(arg1:number):Promise<models.Work>;
 
 export function GetWorkAnalysis(arg1:number):Promise<analysis.WorkResult>;
@@ -287,6 +355,8 @@ export function GetWorkFullPath(arg1:number):Promise<string>;
 
 export function GetWorkMarked(arg1:number):Promise<boolean>;
 
+export function GetWorkRevisions(arg1:number):Promise<Array<models.WorkRevision>>;
+
 export function GetWorkSkipAudits(arg1:number):Promise<boolean>;
 
 export function GetWorkTemplatePath(arg1:number):Promise<string>;
@@ -303,6 +373,10 @@ export function IsValidExtension(arg1:string):Promise<boolean>;
 
 export function ListBackups():Promise<Array<backup.BackupInfo>>;
 
+export function ListBookBuilds(arg1:number):Promise<Array<models.BookBuildInfo>>;
+
+export function ListJobs(arg1:string,arg2:number):Promise<Array<models.Job>>;
+
 export function ListTemplates():Promise<Array<string>>;
 
 export function MoveWorkFile(arg1:number):Promise<void>;
@@ -335,16 +409,32 @@ export function PathExists(arg1:string):Promise<boolean>;
 
 export function PreviewImportFiles():Promise<app.ImportPreview>;
 
+export function PreviewSmartQuery(arg1:string,arg2:string,arg3:number):Promise<models.SmartQueryPreview>;
+
 export function PrintWork(arg1:number):Promise<void>;
 
+export function RebuildBookBuild(arg1:number):Promise<app.BookExportResult>;
+
+export function RecommendPackets(arg1:recommend.Options):Promise<Array<recommend.Packet>>;
+
+export function RecommendSubmissions(arg1:recommend.Options):Promise<Array<recommend.Suggestion>>;
+
+export function Redo(arg1:number):Promise<Array<models.AuditOperation>>;
+
 export function RefreshReport(arg1:string):Promise<void>;
 
+export function RefreshResponseStats():Promise<void>;
+
 export function RegeneratePDF(arg1:number):Promise<string>;
 
+export function RegeneratePDFs(arg1:Array<number>):Promise<models.Job>;
+
 export function RemoveWorkFromCollection(arg1:number,arg2:number):Promise<void>;
 
 export function RenameFieldValue(arg1:string,arg2:string,arg3:string,arg4:string):Promise<number>;
 
+export function RenderWorkDiff(arg1:number,arg2:revisions.Version,arg3:revisions.Version,arg4:number,arg5:string):Promise<string>;
+
 export function ReorderCollectionWorks(arg1:number,arg2:Array<number>):Promise<void>;
 
 export function ReportFileSystemChecks():Promise<app.ReportCategory>;
@@ -353,6 +443,14 @@ export function RestoreBackup(arg1:string):Promise<void>;
 
 export function RestoreBackupAndQuit(arg1:string):Promise<void>;
 
+export function RestoreEntityVersion(arg1:number):Promise<void>;
+
+export function RestoreWorkFile(arg1:number,arg2:string):Promise<backup.ManuscriptVersion>;
+
+export function RestoreWorkRevision(arg1:number):Promise<models.WorkRevision>;
+
+export function RetryJob(arg1:number):Promise<models.Job>;
+
 export function SaveCoverFromBytes(arg1:number,arg2:string,arg3:string,arg4:string):Promise<string>;
 
 export function SaveWindowGeometry(arg1:number,arg2:number,arg3:number,arg4:number):Promise<void>;
@@ -369,6 +467,8 @@ export function SelectExportFolder():Promise<string>;
 
 export function SetCollectionIsBook(arg1:number,arg2:boolean):Promise<void>;
 
+export function SetCollectionSmartQuery(arg1:number,arg2:string):Promise<void>;
+
 export function SetDashboardTimeframe(arg1:string):Promise<void>;
 
 export function SetLastCollectionID(arg1:number):Promise<void>;
@@ -399,6 +499,8 @@ export function SetWorkSuppressed(arg1:number,arg2:number,arg3:boolean):Promise<
 
 export function SetWorksMarked(arg1:Array<number>,arg2:boolean):Promise<void>;
 
+export function SnapshotManuscripts(arg1:string):Promise<backup.ManuscriptSnapshotInfo>;
+
 export function StartReportGeneration():Promise<void>;
 
 export function SyncWorkTemplate(arg1:number):Promise<void>;
@@ -425,6 +527,8 @@ export function UndeleteWork(arg1:number):Promise<validation.ValidationResult>;
 
 export function UndismissAnnotation(arg1:number):Promise<void>;
 
+export function Undo(arg1:number):Promise<Array<models.AuditOperation>>;
+
 export function UpdateBook(arg1:models.Book):Promise<void>;
 
 export function UpdateCollection(arg1:models.Collection):Promise<validation.ValidationResult>;
@@ -437,6 +541,8 @@ export function UpdateSettings(arg1:settings.Settings):Promise<void>;
 
 export function UpdateSubmission(arg1:models.Submission):Promise<validation.ValidationResult>;
 
+export function UpdateSubmissionWindow(arg1:models.SubmissionWindow):Promise<validation.ValidationResult>;
+
 export function UpdateWork(arg1:models.Work):Promise<validation.ValidationResult>;
 
 export function UpdateWorkWithWorkflow(arg1:models.Work):Promise<app.WorkUpdateResult>;
@@ -450,3 +556,7 @@ export function ValidateCoverImagePath(arg1:string):Promise<boolean>;
 export function ValidateMatter(arg1:number):Promise<app.ValidationResult>;
 
 export function ValidateTemplate(arg1:string):Promise<app.TemplateValidation>;
+
+export function VerifyBackup(arg1:string):Promise<backup.BackupInfo>;
+
+export function WithdrawConflictingSubmissions(arg1:number,arg2:Array<number>,arg3:string):Promise<models.WithdrawalResult>;
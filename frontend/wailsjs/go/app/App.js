@@ -74,6 +74,10 @@ export function BrowseForFolder(arg1) {
   return window['go']['app']['App']['BrowseForFolder'](arg1);
 }
 
+export function BuildSubmissionPacket(arg1) {
+  return window['go']['app']['App']['BuildSubmissionPacket'](arg1);
+}
+
 export function CancelBuild() {
   return window['go']['app']['App']['CancelBuild']();
 }
@@ -82,6 +86,10 @@ export function CancelImport() {
   return window['go']['app']['App']['CancelImport']();
 }
 
+export function CancelJob(arg1) {
+  return window['go']['app']['App']['CancelJob'](arg1);
+}
+
 export function CheckImportConflict(arg1) {
   return window['go']['app']['App']['CheckImportConflict'](arg1);
 }
@@ -110,6 +118,10 @@ export function CloseStatusBar() {
   return window['go']['app']['App']['CloseStatusBar']();
 }
 
+export function CompareWorkVersions(arg1, arg2, arg3, arg4) {
+  return window['go']['app']['App']['CompareWorkVersions'](arg1, arg2, arg3, arg4);
+}
+
 export function CompleteSetup() {
   return window['go']['app']['App']['CompleteSetup']();
 }
@@ -154,6 +166,10 @@ export function CreateSubmission(arg1) {
   return window['go']['app']['App']['CreateSubmission'](arg1);
 }
 
+export function CreateSubmissionWindow(arg1) {
+  return window['go']['app']['App']['CreateSubmissionWindow'](arg1);
+}
+
 export function CreateWork(arg1) {
   return window['go']['app']['App']['CreateWork'](arg1);
 }
@@ -174,6 +190,10 @@ export function DeleteCollectionPermanent(arg1) {
   return window['go']['app']['App']['DeleteCollectionPermanent'](arg1);
 }
 
+export function DeleteManuscriptSnapshot(arg1) {
+  return window['go']['app']['App']['DeleteManuscriptSnapshot'](arg1);
+}
+
 export function DeleteNote(arg1) {
   return window['go']['app']['App']['DeleteNote'](arg1);
 }
@@ -198,6 +218,10 @@ export function DeleteSubmissionPermanent(arg1) {
   return window['go']['app']['App']['DeleteSubmissionPermanent'](arg1);
 }
 
+export function DeleteSubmissionWindow(arg1) {
+  return window['go']['app']['App']['DeleteSubmissionWindow'](arg1);
+}
+
 export function DeleteWork(arg1) {
   return window['go']['app']['App']['DeleteWork'](arg1);
 }
@@ -210,6 +234,14 @@ export function DetectLibreOffice() {
   return window['go']['app']['App']['DetectLibreOffice']();
 }
 
+export function DiffBookBuilds(arg1, arg2) {
+  return window['go']['app']['App']['DiffBookBuilds'](arg1, arg2);
+}
+
+export function DiffWorkRevisions(arg1, arg2) {
+  return window['go']['app']['App']['DiffWorkRevisions'](arg1, arg2);
+}
+
 export function DismissAnnotation(arg1, arg2) {
   return window['go']['app']['App']['DismissAnnotation'](arg1, arg2);
 }
@@ -234,14 +266,30 @@ export function ExportAllTables() {
   return window['go']['app']['App']['ExportAllTables']();
 }
 
+export function ExportBlindToSubmissions(arg1) {
+  return window['go']['app']['App']['ExportBlindToSubmissions'](arg1);
+}
+
+export function ExportBookEPUB(arg1, arg2) {
+  return window['go']['app']['App']['ExportBookEPUB'](arg1, arg2);
+}
+
 export function ExportBookPDFWithParts(arg1, arg2, arg3, arg4) {
   return window['go']['app']['App']['ExportBookPDFWithParts'](arg1, arg2, arg3, arg4);
 }
 
+export function ExportCalendarICS() {
+  return window['go']['app']['App']['ExportCalendarICS']();
+}
+
 export function ExportCollectionFolder(arg1) {
   return window['go']['app']['App']['ExportCollectionFolder'](arg1);
 }
 
+export function ExportCoverGuide(arg1) {
+  return window['go']['app']['App']['ExportCoverGuide'](arg1);
+}
+
 export function ExportCoverPDF(arg1, arg2) {
   return window['go']['app']['App']['ExportCoverPDF'](arg1, arg2);
 }
@@ -286,6 +334,10 @@ export function GeneratePath(arg1) {
   return window['go']['app']['App']['GeneratePath'](arg1);
 }
 
+export function GetAllOrgResponseStats() {
+  return window['go']['app']['App']['GetAllOrgResponseStats']();
+}
+
 export function GetAllSubmissionViews() {
   return window['go']['app']['App']['GetAllSubmissionViews']();
 }
@@ -310,6 +362,10 @@ export function GetBook(arg1) {
   return window['go']['app']['App']['GetBook'](arg1);
 }
 
+export function GetBookBuild(arg1) {
+  return window['go']['app']['App']['GetBookBuild'](arg1);
+}
+
 export function GetBookByCollection(arg1) {
   return window['go']['app']['App']['GetBookByCollection'](arg1);
 }
@@ -318,6 +374,14 @@ export function GetBookParts(arg1) {
   return window['go']['app']['App']['GetBookParts'](arg1);
 }
 
+export function GetCalendarEvents(arg1, arg2) {
+  return window['go']['app']['App']['GetCalendarEvents'](arg1, arg2);
+}
+
+export function GetCalendarURL() {
+  return window['go']['app']['App']['GetCalendarURL']();
+}
+
 export function GetCollection(arg1) {
   return window['go']['app']['App']['GetCollection'](arg1);
 }
@@ -346,6 +410,14 @@ export function GetCollectionIsBook(arg1) {
   return window['go']['app']['App']['GetCollectionIsBook'](arg1);
 }
 
+export function GetCollectionOrganizations(arg1) {
+  return window['go']['app']['App']['GetCollectionOrganizations'](arg1);
+}
+
+export function GetCollectionSubmissions(arg1) {
+  return window['go']['app']['App']['GetCollectionSubmissions'](arg1);
+}
+
 export function GetCollectionWorks(arg1) {
   return window['go']['app']['App']['GetCollectionWorks'](arg1);
 }
@@ -354,6 +426,10 @@ export function GetCollections() {
   return window['go']['app']['App']['GetCollections']();
 }
 
+export function GetCoverGeometry(arg1) {
+  return window['go']['app']['App']['GetCoverGeometry'](arg1);
+}
+
 export function GetCoverImageData(arg1) {
   return window['go']['app']['App']['GetCoverImageData'](arg1);
 }
@@ -382,6 +458,10 @@ export function GetDuotropeURL(arg1) {
   return window['go']['app']['App']['GetDuotropeURL'](arg1);
 }
 
+export function GetEntityHistory(arg1, arg2) {
+  return window['go']['app']['App']['GetEntityHistory'](arg1, arg2);
+}
+
 export function GetEnumLists() {
   return window['go']['app']['App']['GetEnumLists']();
 }
@@ -410,6 +490,22 @@ export function GetGalleyInfo(arg1) {
   return window['go']['app']['App']['GetGalleyInfo'](arg1);
 }
 
+export function GetJob(arg1) {
+  return window['go']['app']['App']['GetJob'](arg1);
+}
+
+export function GetManuscriptHistory(arg1) {
+  return window['go']['app']['App']['GetManuscriptHistory'](arg1);
+}
+
+export function GetManuscriptSnapshot(arg1) {
+  return window['go']['app']['App']['GetManuscriptSnapshot'](arg1);
+}
+
+export function GetManuscriptSnapshots() {
+  return window['go']['app']['App']['GetManuscriptSnapshots']();
+}
+
 export function GetMarkedWorksInCollection(arg1) {
   return window['go']['app']['App']['GetMarkedWorksInCollection'](arg1);
 }
@@ -418,6 +514,10 @@ export function GetNotes(arg1, arg2) {
   return window['go']['app']['App']['GetNotes'](arg1, arg2);
 }
 
+export function GetOrgResponseStats(arg1) {
+  return window['go']['app']['App']['GetOrgResponseStats'](arg1);
+}
+
 export function GetOrganization(arg1) {
   return window['go']['app']['App']['GetOrganization'](arg1);
 }
@@ -438,10 +538,22 @@ export function GetOrgsFilterOptions() {
   return window['go']['app']['App']['GetOrgsFilterOptions']();
 }
 
+export function GetOverdueSubmissions() {
+  return window['go']['app']['App']['GetOverdueSubmissions']();
+}
+
+export function GetPDFConverters() {
+  return window['go']['app']['App']['GetPDFConverters']();
+}
+
 export function GetPDFPageSize(arg1) {
   return window['go']['app']['App']['GetPDFPageSize'](arg1);
 }
 
+export function GetPacketSubmissions(arg1) {
+  return window['go']['app']['App']['GetPacketSubmissions'](arg1);
+}
+
 export function GetPartCacheStatus(arg1) {
   return window['go']['app']['App']['GetPartCacheStatus'](arg1);
 }
@@ -454,6 +566,10 @@ export function GetPublicationReadiness(arg1) {
   return window['go']['app']['App']['GetPublicationReadiness'](arg1);
 }
 
+export function GetRecentOperations(arg1) {
+  return window['go']['app']['App']['GetRecentOperations'](arg1);
+}
+
 export function GetReportNames() {
   return window['go']['app']['App']['GetReportNames']();
 }
@@ -474,6 +590,10 @@ export function GetSubmissionDeleteConfirmation(arg1) {
   return window['go']['app']['App']['GetSubmissionDeleteConfirmation'](arg1);
 }
 
+export function GetSubmissionPackets(arg1) {
+  return window['go']['app']['App']['GetSubmissionPackets'](arg1);
+}
+
 export function GetSubmissionViewsByCollection(arg1) {
   return window['go']['app']['App']['GetSubmissionViewsByCollection'](arg1);
 }
@@ -486,6 +606,10 @@ export function GetSubmissionViewsByWork(arg1) {
   return window['go']['app']['App']['GetSubmissionViewsByWork'](arg1);
 }
 
+export function GetSubmissionWindows(arg1) {
+  return window['go']['app']['App']['GetSubmissionWindows'](arg1);
+}
+
 export function GetSubmissions() {
   return window['go']['app']['App']['GetSubmissions']();
 }
@@ -522,6 +646,10 @@ export function GetValidExtensions() {
   return window['go']['app']['App']['GetValidExtensions']();
 }
 
+export function GetWithdrawalChecklist(arg1) {
+  return window['go']['app']['App']['GetWithdrawalChecklist'](arg1);
+}
+
 export function GetWork(arg1) {
   return window['go']['app']['App']['GetWork'](arg1);
 }
@@ -554,6 +682,10 @@ export function GetWorkMarked(arg1) {
   return window['go']['app']['App']['GetWorkMarked'](arg1);
 }
 
+export function GetWorkRevisions(arg1) {
+  return window['go']['app']['App']['GetWorkRevisions'](arg1);
+}
+
 export function GetWorkSkipAudits(arg1) {
   return window['go']['app']['App']['GetWorkSkipAudits'](arg1);
 }
@@ -586,6 +718,14 @@ export function ListBackups() {
   return window['go']['app']['App']['ListBackups']();
 }
 
+export function ListBookBuilds(arg1) {
+  return window['go']['app']['App']['ListBookBuilds'](arg1);
+}
+
+export function ListJobs(arg1, arg2) {
+  return window['go']['app']['App']['ListJobs'](arg1, arg2);
+}
+
 export function ListTemplates() {
   return window['go']['app']['App']['ListTemplates']();
 }
@@ -650,18 +790,46 @@ export function PreviewImportFiles() {
   return window['go']['app']['App']['PreviewImportFiles']();
 }
 
+export function PreviewSmartQuery(arg1, arg2, arg3) {
+  return window['go']['app']['App']['PreviewSmartQuery'](arg1, arg2, arg3);
+}
+
 export function PrintWork(arg1) {
   return window['go']['app']['App']['PrintWork'](arg1);
 }
 
+export function RebuildBookBuild(arg1) {
+  return window['go']['app']['App']['RebuildBookBuild'](arg1);
+}
+
+export function RecommendPackets(arg1) {
+  return window['go']['app']['App']['RecommendPackets'](arg1);
+}
+
+export function RecommendSubmissions(arg1) {
+  return window['go']['app']['App']['RecommendSubmissions'](arg1);
+}
+
+export function Redo(arg1) {
+  return window['go']['app']['App']['Redo'](arg1);
+}
+
 export function RefreshReport(arg1) {
   return window['go']['app']['App']['RefreshReport'](arg1);
 }
 
+export function RefreshResponseStats() {
+  return window['go']['app']['App']['RefreshResponseStats']();
+}
+
 export function RegeneratePDF(arg1) {
   return window['go']['app']['App']['RegeneratePDF'](arg1);
 }
 
+export function RegeneratePDFs(arg1) {
+  return window['go']['app']['App']['RegeneratePDFs'](arg1);
+}
+
 export function RemoveWorkFromCollection(arg1, arg2) {
   return window['go']['app']['App']['RemoveWorkFromCollection'](arg1, arg2);
 }
@@ -670,6 +838,10 @@ export function RenameFieldValue(arg1, arg2, arg3, arg4) {
   return window['go']['app']['App']['RenameFieldValue'](arg1, arg2, arg3, arg4);
 }
 
+export function RenderWorkDiff(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['app']['App']['RenderWorkDiff'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function ReorderCollectionWorks(arg1, arg2) {
   return window['go']['app']['App']['ReorderCollectionWorks'](arg1, arg2);
 }
@@ -686,6 +858,22 @@ export function RestoreBackupAndQuit(arg1) {
   return window['go']['app']['App']['RestoreBackupAndQuit'](arg1);
 }
 
+export function RestoreEntityVersion(arg1) {
+  return window['go']['app']['App']['RestoreEntityVersion'](arg1);
+}
+
+export function RestoreWorkFile(arg1, arg2) {
+  return window['go']['app']['App']['RestoreWorkFile'](arg1, arg2);
+}
+
+export function RestoreWorkRevision(arg1) {
+  return window['go']['app']['App']['RestoreWorkRevision'](arg1);
+}
+
+export function RetryJob(arg1) {
+  return window['go']['app']['App']['RetryJob'](arg1);
+}
+
 export function SaveCoverFromBytes(arg1, arg2, arg3, arg4) {
   return window['go']['app']['App']['SaveCoverFromBytes'](arg1, arg2, arg3, arg4);
 }
@@ -718,6 +906,10 @@ export function SetCollectionIsBook(arg1, arg2) {
   return window['go']['app']['App']['SetCollectionIsBook'](arg1, arg2);
 }
 
+export function SetCollectionSmartQuery(arg1, arg2) {
+  return window['go']['app']['App']['SetCollectionSmartQuery'](arg1, arg2);
+}
+
 export function SetDashboardTimeframe(arg1) {
   return window['go']['app']['App']['SetDashboardTimeframe'](arg1);
 }
@@ -778,6 +970,10 @@ export function SetWorksMarked(arg1, arg2) {
   return window['go']['app']['App']['SetWorksMarked'](arg1, arg2);
 }
 
+export function SnapshotManuscripts(arg1) {
+  return window['go']['app']['App']['SnapshotManuscripts'](arg1);
+}
+
 export function StartReportGeneration() {
   return window['go']['app']['App']['StartReportGeneration']();
 }
@@ -830,6 +1026,10 @@ export function UndismissAnnotation(arg1) {
   return window['go']['app']['App']['UndismissAnnotation'](arg1);
 }
 
+export function Undo(arg1) {
+  return window['go']['app']['App']['Undo'](arg1);
+}
+
 export function UpdateBook(arg1) {
   return window['go']['app']['App']['UpdateBook'](arg1);
 }
@@ -854,6 +1054,10 @@ export function UpdateSubmission(arg1) {
   return window['go']['app']['App']['UpdateSubmission'](arg1);
 }
 
+export function UpdateSubmissionWindow(arg1) {
+  return window['go']['app']['App']['UpdateSubmissionWindow'](arg1);
+}
+
 export function UpdateWork(arg1) {
   return window['go']['app']['App']['UpdateWork'](arg1);
 }
@@ -881,3 +1085,11 @@ export function ValidateMatter(arg1) {
 export function ValidateTemplate(arg1) {
   return window['go']['app']['App']['ValidateTemplate'](arg1);
 }
+
+export function VerifyBackup(arg1) {
+  return window['go']['app']['App']['VerifyBackup'](arg1);
+}
+
+export function WithdrawConflictingSubmissions(arg1, arg2, arg3) {
+  return window['go']['app']['App']['WithdrawConflictingSubmissions'](arg1, arg2, arg3);
+}
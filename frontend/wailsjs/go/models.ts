@@ -570,6 +570,26 @@ export namespace app {
 	        this.createdAt = source["createdAt"];
 	    }
 	}
+	export class SavedQueryCount {
+	    collID: number;
+	    name: string;
+	    entity: string;
+	    query: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SavedQueryCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.collID = source["collID"];
+	        this.name = source["name"];
+	        this.entity = source["entity"];
+	        this.query = source["query"];
+	        this.count = source["count"];
+	    }
+	}
 	export class YearProgressStats {
 	    year: number;
 	    submissions: number;
@@ -698,6 +718,7 @@ export namespace app {
 	    yearProgress: YearProgressStats;
 	    recentItems: RecentItem[];
 	    pendingAlerts: PendingAlert[];
+	    savedQueries: SavedQueryCount[];
 	
 	    static createFrom(source: any = {}) {
 	        return new DashboardStats(source);
@@ -712,6 +733,7 @@ export namespace app {
 	        this.yearProgress = this.convertValues(source["yearProgress"], YearProgressStats);
 	        this.recentItems = this.convertValues(source["recentItems"], RecentItem);
 	        this.pendingAlerts = this.convertValues(source["pendingAlerts"], PendingAlert);
+	        this.savedQueries = this.convertValues(source["savedQueries"], SavedQueryCount);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -1064,6 +1086,72 @@ export namespace app {
 	        this.timings = source["timings"];
 	    }
 	}
+	export class PacketRequest {
+	    orgID: number;
+	    workIDs: number[];
+	    isBlind: boolean;
+	    includeHeader: boolean;
+	    logSubmissions: boolean;
+	    submissionDate?: string;
+	    submissionType?: string;
+	    cost?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PacketRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.orgID = source["orgID"];
+	        this.workIDs = source["workIDs"];
+	        this.isBlind = source["isBlind"];
+	        this.includeHeader = source["includeHeader"];
+	        this.logSubmissions = source["logSubmissions"];
+	        this.submissionDate = source["submissionDate"];
+	        this.submissionType = source["submissionType"];
+	        this.cost = source["cost"];
+	    }
+	}
+	export class PacketResult {
+	    packet?: models.SubmissionPacket;
+	    docPath: string;
+	    pdfPath?: string;
+	    submissionIDs: number[];
+	    warnings: string[];
+	    removed: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PacketResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.packet = this.convertValues(source["packet"], models.SubmissionPacket);
+	        this.docPath = source["docPath"];
+	        this.pdfPath = source["pdfPath"];
+	        this.submissionIDs = source["submissionIDs"];
+	        this.warnings = source["warnings"];
+	        this.removed = source["removed"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class PartInfo {
 	    index: number;
 	    partId: number;
@@ -1125,6 +1213,7 @@ export namespace app {
 	    content: ValidationResult;
 	    matter: ValidationResult;
 	    cover: ValidationResult;
+	    preflight?: kdp.Report;
 	
 	    static createFrom(source: any = {}) {
 	        return new PublicationReadiness(source);
@@ -1135,6 +1224,7 @@ export namespace app {
 	        this.content = this.convertValues(source["content"], ValidationResult);
 	        this.matter = this.convertValues(source["matter"], ValidationResult);
 	        this.cover = this.convertValues(source["cover"], ValidationResult);
+	        this.preflight = this.convertValues(source["preflight"], kdp.Report);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -1465,6 +1555,8 @@ export namespace backup {
 	    path: string;
 	    size: number;
 	    createdAt: string;
+	    format: string;
+	    contents?: string[];
 	
 	    static createFrom(source: any = {}) {
 	        return new BackupInfo(source);
@@ -1476,7 +1568,282 @@ export namespace backup {
 	        this.path = source["path"];
 	        this.size = source["size"];
 	        this.createdAt = source["createdAt"];
+	        this.format = source["format"];
+	        this.contents = source["contents"];
+	    }
+	}
+	export class ManuscriptFile {
+	    workID: number;
+	    title: string;
+	    path: string;
+	    sha256?: string;
+	    size?: number;
+	    modTime?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ManuscriptFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.workID = source["workID"];
+	        this.title = source["title"];
+	        this.path = source["path"];
+	        this.sha256 = source["sha256"];
+	        this.size = source["size"];
+	        this.modTime = source["modTime"];
+	    }
+	}
+	export class ManuscriptSnapshot {
+	    id: string;
+	    createdAt: string;
+	    label?: string;
+	    baseFolder: string;
+	    partial?: boolean;
+	    files: ManuscriptFile[];
+	    missing?: ManuscriptFile[];
+	    totalBytes: number;
+	    newBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ManuscriptSnapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.createdAt = source["createdAt"];
+	        this.label = source["label"];
+	        this.baseFolder = source["baseFolder"];
+	        this.partial = source["partial"];
+	        this.files = this.convertValues(source["files"], ManuscriptFile);
+	        this.missing = this.convertValues(source["missing"], ManuscriptFile);
+	        this.totalBytes = source["totalBytes"];
+	        this.newBytes = source["newBytes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ManuscriptSnapshotInfo {
+	    id: string;
+	    createdAt: string;
+	    label?: string;
+	    partial?: boolean;
+	    fileCount: number;
+	    missingCount: number;
+	    totalBytes: number;
+	    newBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ManuscriptSnapshotInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.createdAt = source["createdAt"];
+	        this.label = source["label"];
+	        this.partial = source["partial"];
+	        this.fileCount = source["fileCount"];
+	        this.missingCount = source["missingCount"];
+	        this.totalBytes = source["totalBytes"];
+	        this.newBytes = source["newBytes"];
+	    }
+	}
+	export class ManuscriptVersion {
+	    snapshotID: string;
+	    createdAt: string;
+	    path: string;
+	    sha256: string;
+	    size: number;
+	    modTime: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ManuscriptVersion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.snapshotID = source["snapshotID"];
+	        this.createdAt = source["createdAt"];
+	        this.path = source["path"];
+	        this.sha256 = source["sha256"];
+	        this.size = source["size"];
+	        this.modTime = source["modTime"];
+	    }
+	}
+
+}
+
+export namespace bookbuild {
+	
+	export class BuildInputChange {
+	    key: string;
+	    label: string;
+	    change: string;
+	    old?: string;
+	    new?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BuildInputChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.label = source["label"];
+	        this.change = source["change"];
+	        this.old = source["old"];
+	        this.new = source["new"];
+	    }
+	}
+	export class BuildSettingChange {
+	    field: string;
+	    old: string;
+	    new: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BuildSettingChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.old = source["old"];
+	        this.new = source["new"];
+	    }
+	}
+	export class BuildDiff {
+	    from: number;
+	    to: number;
+	    inputs: BuildInputChange[];
+	    settings: BuildSettingChange[];
+	    orderChanged: boolean;
+	    sameOutput: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BuildDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.inputs = this.convertValues(source["inputs"], BuildInputChange);
+	        this.settings = this.convertValues(source["settings"], BuildSettingChange);
+	        this.orderChanged = source["orderChanged"];
+	        this.sameOutput = source["sameOutput"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CoverRect {
+	    x: number;
+	    y: number;
+	    width: number;
+	    height: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CoverRect(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.x = source["x"];
+	        this.y = source["y"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	    }
+	}
+	export class CoverGeometry {
+	    trimSize: string;
+	    paperType: string;
+	    pageCount: number;
+	    widthMM: number;
+	    heightMM: number;
+	    spineMM: number;
+	    bleedMM: number;
+	    back: CoverRect;
+	    spine: CoverRect;
+	    front: CoverRect;
+	    backSafe: CoverRect;
+	    spineSafe: CoverRect;
+	    frontSafe: CoverRect;
+	    barcode: CoverRect;
+	    spineTextAllowed: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CoverGeometry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.trimSize = source["trimSize"];
+	        this.paperType = source["paperType"];
+	        this.pageCount = source["pageCount"];
+	        this.widthMM = source["widthMM"];
+	        this.heightMM = source["heightMM"];
+	        this.spineMM = source["spineMM"];
+	        this.bleedMM = source["bleedMM"];
+	        this.back = this.convertValues(source["back"], CoverRect);
+	        this.spine = this.convertValues(source["spine"], CoverRect);
+	        this.front = this.convertValues(source["front"], CoverRect);
+	        this.backSafe = this.convertValues(source["backSafe"], CoverRect);
+	        this.spineSafe = this.convertValues(source["spineSafe"], CoverRect);
+	        this.frontSafe = this.convertValues(source["frontSafe"], CoverRect);
+	        this.barcode = this.convertValues(source["barcode"], CoverRect);
+	        this.spineTextAllowed = source["spineTextAllowed"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
@@ -1512,11 +1879,28 @@ export namespace db {
 
 export namespace fileops {
 	
+	export class AnonymizeReport {
+	    path: string;
+	    removed: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AnonymizeReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.removed = source["removed"];
+	    }
+	}
 	export class Config {
 	    BaseFolderPath: string;
 	    PDFPreviewPath: string;
 	    SubmissionExportPath: string;
 	    TemplateFolderPath: string;
+	    LibreOfficePath: string;
+	    ConverterPriority: string[];
+	    MaxConversions: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new Config(source);
@@ -1528,6 +1912,25 @@ export namespace fileops {
 	        this.PDFPreviewPath = source["PDFPreviewPath"];
 	        this.SubmissionExportPath = source["SubmissionExportPath"];
 	        this.TemplateFolderPath = source["TemplateFolderPath"];
+	        this.LibreOfficePath = source["LibreOfficePath"];
+	        this.ConverterPriority = source["ConverterPriority"];
+	        this.MaxConversions = source["MaxConversions"];
+	    }
+	}
+	export class ConverterStatus {
+	    name: string;
+	    available: boolean;
+	    maxConcurrent: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConverterStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.available = source["available"];
+	        this.maxConcurrent = source["maxConcurrent"];
 	    }
 	}
 	export class ParsedFilename {
@@ -1893,14 +2296,145 @@ export namespace fts {
 
 }
 
-export namespace models {
+export namespace kdp {
 	
-	export class Book {
-	    bookID: number;
-	    collID: number;
-	    title: string;
-	    subtitle?: string;
-	    author: string;
+	export class Finding {
+	    check: string;
+	    severity: string;
+	    message: string;
+	    pages?: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Finding(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.check = source["check"];
+	        this.severity = source["severity"];
+	        this.message = source["message"];
+	        this.pages = source["pages"];
+	    }
+	}
+	export class Report {
+	    pageCount: number;
+	    bleed: boolean;
+	    spineMM: number;
+	    galley: Finding[];
+	    cover: Finding[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pageCount = source["pageCount"];
+	        this.bleed = source["bleed"];
+	        this.spineMM = source["spineMM"];
+	        this.galley = this.convertValues(source["galley"], Finding);
+	        this.cover = this.convertValues(source["cover"], Finding);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace models {
+	
+	export class AuditEntry {
+	    id: number;
+	    opID: number;
+	    opLabel: string;
+	    tableName: string;
+	    entityType: string;
+	    entityID: number;
+	    action: string;
+	    before: string;
+	    after: string;
+	    undone: boolean;
+	    createdAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.opID = source["opID"];
+	        this.opLabel = source["opLabel"];
+	        this.tableName = source["tableName"];
+	        this.entityType = source["entityType"];
+	        this.entityID = source["entityID"];
+	        this.action = source["action"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	        this.undone = source["undone"];
+	        this.createdAt = source["createdAt"];
+	    }
+	}
+	export class AuditOperation {
+	    opID: number;
+	    label: string;
+	    createdAt: string;
+	    undone: boolean;
+	    entries: AuditEntry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditOperation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.opID = source["opID"];
+	        this.label = source["label"];
+	        this.createdAt = source["createdAt"];
+	        this.undone = source["undone"];
+	        this.entries = this.convertValues(source["entries"], AuditEntry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Book {
+	    bookID: number;
+	    collID: number;
+	    title: string;
+	    subtitle?: string;
+	    author: string;
 	    copyright?: string;
 	    dedication?: string;
 	    afterword?: string;
@@ -1928,6 +2462,8 @@ export namespace models {
 	    pageNumberPosition: string;
 	    suppressPageNumbers: string;
 	    bookType: string;
+	    firstLineIndex?: boolean;
+	    tocDepth: number;
 	    selectedParts?: string;
 	    paperType: string;
 	    trimSize: string;
@@ -1979,6 +2515,8 @@ export namespace models {
 	        this.pageNumberPosition = source["pageNumberPosition"];
 	        this.suppressPageNumbers = source["suppressPageNumbers"];
 	        this.bookType = source["bookType"];
+	        this.firstLineIndex = source["firstLineIndex"];
+	        this.tocDepth = source["tocDepth"];
 	        this.selectedParts = source["selectedParts"];
 	        this.paperType = source["paperType"];
 	        this.trimSize = source["trimSize"];
@@ -1993,6 +2531,148 @@ export namespace models {
 	        this.modifiedAt = source["modifiedAt"];
 	    }
 	}
+	export class BookBuildInfo {
+	    buildID: number;
+	    collID: number;
+	    outputPath: string;
+	    outputSha256: string;
+	    pagesSha256: string;
+	    totalPages: number;
+	    inputCount: number;
+	    duration: string;
+	    rebuiltFrom?: number;
+	    createdAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BookBuildInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.buildID = source["buildID"];
+	        this.collID = source["collID"];
+	        this.outputPath = source["outputPath"];
+	        this.outputSha256 = source["outputSha256"];
+	        this.pagesSha256 = source["pagesSha256"];
+	        this.totalPages = source["totalPages"];
+	        this.inputCount = source["inputCount"];
+	        this.duration = source["duration"];
+	        this.rebuiltFrom = source["rebuiltFrom"];
+	        this.createdAt = source["createdAt"];
+	    }
+	}
+	export class BuildInput {
+	    key: string;
+	    label: string;
+	    path: string;
+	    sha256: string;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BuildInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.label = source["label"];
+	        this.path = source["path"];
+	        this.sha256 = source["sha256"];
+	        this.size = source["size"];
+	    }
+	}
+	export class BookBuild {
+	    buildID: number;
+	    collID: number;
+	    manifest: string;
+	    settings: string;
+	    inputs: BuildInput[];
+	    outputPath: string;
+	    outputSha256: string;
+	    pagesSha256: string;
+	    totalPages: number;
+	    duration: string;
+	    rebuiltFrom?: number;
+	    createdAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BookBuild(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.buildID = source["buildID"];
+	        this.collID = source["collID"];
+	        this.manifest = source["manifest"];
+	        this.settings = source["settings"];
+	        this.inputs = this.convertValues(source["inputs"], BuildInput);
+	        this.outputPath = source["outputPath"];
+	        this.outputSha256 = source["outputSha256"];
+	        this.pagesSha256 = source["pagesSha256"];
+	        this.totalPages = source["totalPages"];
+	        this.duration = source["duration"];
+	        this.rebuiltFrom = source["rebuiltFrom"];
+	        this.createdAt = source["createdAt"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CalendarEvent {
+	    uid: string;
+	    windowID: number;
+	    orgID: number;
+	    orgName: string;
+	    kind: string;
+	    name: string;
+	    type: string;
+	    date: string;
+	    opens: string;
+	    closes: string;
+	    fee?: number;
+	    prize: string;
+	    url: string;
+	    isOpen: boolean;
+	    daysAway: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CalendarEvent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.uid = source["uid"];
+	        this.windowID = source["windowID"];
+	        this.orgID = source["orgID"];
+	        this.orgName = source["orgName"];
+	        this.kind = source["kind"];
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.date = source["date"];
+	        this.opens = source["opens"];
+	        this.closes = source["closes"];
+	        this.fee = source["fee"];
+	        this.prize = source["prize"];
+	        this.url = source["url"];
+	        this.isOpen = source["isOpen"];
+	        this.daysAway = source["daysAway"];
+	    }
+	}
 	export class Collection {
 	    collID: number;
 	    collectionName: string;
@@ -2002,6 +2682,7 @@ export namespace models {
 	    modifiedAt: string;
 	    isBook: boolean;
 	    smartQuery?: string;
+	    entity: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Collection(source);
@@ -2017,6 +2698,7 @@ export namespace models {
 	        this.modifiedAt = source["modifiedAt"];
 	        this.isBook = source["isBook"];
 	        this.smartQuery = source["smartQuery"];
+	        this.entity = source["entity"];
 	    }
 	}
 	export class CollectionDetail {
@@ -2050,6 +2732,7 @@ export namespace models {
 	    modifiedAt: string;
 	    isBook: boolean;
 	    smartQuery?: string;
+	    entity: string;
 	    isDeleted: boolean;
 	    nItems: number;
 	    frontCoverPath?: string;
@@ -2068,6 +2751,7 @@ export namespace models {
 	        this.modifiedAt = source["modifiedAt"];
 	        this.isBook = source["isBook"];
 	        this.smartQuery = source["smartQuery"];
+	        this.entity = source["entity"];
 	        this.isDeleted = source["isDeleted"];
 	        this.nItems = source["nItems"];
 	        this.frontCoverPath = source["frontCoverPath"];
@@ -2125,6 +2809,48 @@ export namespace models {
 	        this.isSuppressed = source["isSuppressed"];
 	    }
 	}
+	export class Job {
+	    jobID: number;
+	    kind: string;
+	    label: string;
+	    params: string;
+	    status: string;
+	    progressCurrent: number;
+	    progressTotal: number;
+	    message: string;
+	    result?: string;
+	    error?: string;
+	    log?: string;
+	    attempts: number;
+	    retryOf?: number;
+	    createdAt: string;
+	    startedAt?: string;
+	    finishedAt?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Job(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.jobID = source["jobID"];
+	        this.kind = source["kind"];
+	        this.label = source["label"];
+	        this.params = source["params"];
+	        this.status = source["status"];
+	        this.progressCurrent = source["progressCurrent"];
+	        this.progressTotal = source["progressTotal"];
+	        this.message = source["message"];
+	        this.result = source["result"];
+	        this.error = source["error"];
+	        this.log = source["log"];
+	        this.attempts = source["attempts"];
+	        this.retryOf = source["retryOf"];
+	        this.createdAt = source["createdAt"];
+	        this.startedAt = source["startedAt"];
+	        this.finishedAt = source["finishedAt"];
+	    }
+	}
 	export class Note {
 	    id: number;
 	    entityType: string;
@@ -2151,6 +2877,52 @@ export namespace models {
 	        this.createdAt = source["createdAt"];
 	    }
 	}
+	export class OrgResponseStats {
+	    orgID: number;
+	    orgName: string;
+	    submitted: number;
+	    pending: number;
+	    decided: number;
+	    timed: number;
+	    accepted: number;
+	    personal: number;
+	    noResponse: number;
+	    acceptRate: number;
+	    personalRate: number;
+	    minDays: number;
+	    p25Days: number;
+	    medianDays: number;
+	    p75Days: number;
+	    p90Days: number;
+	    maxDays: number;
+	    computedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrgResponseStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.orgID = source["orgID"];
+	        this.orgName = source["orgName"];
+	        this.submitted = source["submitted"];
+	        this.pending = source["pending"];
+	        this.decided = source["decided"];
+	        this.timed = source["timed"];
+	        this.accepted = source["accepted"];
+	        this.personal = source["personal"];
+	        this.noResponse = source["noResponse"];
+	        this.acceptRate = source["acceptRate"];
+	        this.personalRate = source["personalRate"];
+	        this.minDays = source["minDays"];
+	        this.p25Days = source["p25Days"];
+	        this.medianDays = source["medianDays"];
+	        this.p75Days = source["p75Days"];
+	        this.p90Days = source["p90Days"];
+	        this.maxDays = source["maxDays"];
+	        this.computedAt = source["computedAt"];
+	    }
+	}
 	export class Organization {
 	    orgID: number;
 	    name: string;
@@ -2174,6 +2946,7 @@ export namespace models {
 	    contestFee?: string;
 	    contestPrize?: string;
 	    contestPrize2?: string;
+	    fileNaming?: string;
 	    attributes: string;
 	    dateAdded?: string;
 	    modifiedAt?: string;
@@ -2206,6 +2979,7 @@ export namespace models {
 	        this.contestFee = source["contestFee"];
 	        this.contestPrize = source["contestPrize"];
 	        this.contestPrize2 = source["contestPrize2"];
+	        this.fileNaming = source["fileNaming"];
 	        this.attributes = source["attributes"];
 	        this.dateAdded = source["dateAdded"];
 	        this.modifiedAt = source["modifiedAt"];
@@ -2234,6 +3008,7 @@ export namespace models {
 	    contestFee?: string;
 	    contestPrize?: string;
 	    contestPrize2?: string;
+	    fileNaming?: string;
 	    attributes: string;
 	    dateAdded?: string;
 	    modifiedAt?: string;
@@ -2269,6 +3044,7 @@ export namespace models {
 	        this.contestFee = source["contestFee"];
 	        this.contestPrize = source["contestPrize"];
 	        this.contestPrize2 = source["contestPrize2"];
+	        this.fileNaming = source["fileNaming"];
 	        this.attributes = source["attributes"];
 	        this.dateAdded = source["dateAdded"];
 	        this.modifiedAt = source["modifiedAt"];
@@ -2277,6 +3053,34 @@ export namespace models {
 	        this.notes = source["notes"];
 	    }
 	}
+	export class OverdueSubmission {
+	    submissionID: number;
+	    orgID: number;
+	    workTitle: string;
+	    orgName: string;
+	    submissionDate: string;
+	    daysWaiting: number;
+	    medianDays: number;
+	    p90Days: number;
+	    sample: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new OverdueSubmission(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.submissionID = source["submissionID"];
+	        this.orgID = source["orgID"];
+	        this.workTitle = source["workTitle"];
+	        this.orgName = source["orgName"];
+	        this.submissionDate = source["submissionDate"];
+	        this.daysWaiting = source["daysWaiting"];
+	        this.medianDays = source["medianDays"];
+	        this.p90Days = source["p90Days"];
+	        this.sample = source["sample"];
+	    }
+	}
 	export class ParsedQuery {
 	    terms: string[];
 	    phrases: string[];
@@ -2356,6 +3160,51 @@ export namespace models {
 		}
 	}
 	
+	export class SmartQueryPreview {
+	    entity: string;
+	    query: string;
+	    count: number;
+	    works: CollectionWork[];
+	    submissions: SubmissionView[];
+	    organizations: Organization[];
+	    error?: string;
+	    errorPos: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SmartQueryPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entity = source["entity"];
+	        this.query = source["query"];
+	        this.count = source["count"];
+	        this.works = this.convertValues(source["works"], CollectionWork);
+	        this.submissions = this.convertValues(source["submissions"], SubmissionView);
+	        this.organizations = this.convertValues(source["organizations"], Organization);
+	        this.error = source["error"];
+	        this.errorPos = source["errorPos"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
 	export class Submission {
 	    submissionID: number;
 	    workID: number;
@@ -2372,6 +3221,7 @@ export namespace models {
 	    userID?: string;
 	    password?: string;
 	    webAddress?: string;
+	    packetID?: number;
 	    attributes: string;
 	    createdAt: string;
 	    modifiedAt: string;
@@ -2397,13 +3247,40 @@ export namespace models {
 	        this.userID = source["userID"];
 	        this.password = source["password"];
 	        this.webAddress = source["webAddress"];
+	        this.packetID = source["packetID"];
 	        this.attributes = source["attributes"];
 	        this.createdAt = source["createdAt"];
 	        this.modifiedAt = source["modifiedAt"];
 	    }
 	}
-	export class SubmissionView {
-	    submissionID: number;
+	export class SubmissionPacket {
+	    packetID: number;
+	    orgID: number;
+	    docPath: string;
+	    pdfPath?: string;
+	    isBlind: boolean;
+	    createdAt: string;
+	    modifiedAt: string;
+	    orgName: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SubmissionPacket(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.packetID = source["packetID"];
+	        this.orgID = source["orgID"];
+	        this.docPath = source["docPath"];
+	        this.pdfPath = source["pdfPath"];
+	        this.isBlind = source["isBlind"];
+	        this.createdAt = source["createdAt"];
+	        this.modifiedAt = source["modifiedAt"];
+	        this.orgName = source["orgName"];
+	    }
+	}
+	export class SubmissionView {
+	    submissionID: number;
 	    workID: number;
 	    orgID: number;
 	    isCollection: boolean;
@@ -2418,6 +3295,7 @@ export namespace models {
 	    userID?: string;
 	    password?: string;
 	    webAddress?: string;
+	    packetID?: number;
 	    attributes: string;
 	    createdAt: string;
 	    modifiedAt: string;
@@ -2448,6 +3326,7 @@ export namespace models {
 	        this.userID = source["userID"];
 	        this.password = source["password"];
 	        this.webAddress = source["webAddress"];
+	        this.packetID = source["packetID"];
 	        this.attributes = source["attributes"];
 	        this.createdAt = source["createdAt"];
 	        this.modifiedAt = source["modifiedAt"];
@@ -2458,6 +3337,127 @@ export namespace models {
 	        this.decisionPending = source["decisionPending"];
 	    }
 	}
+	export class SubmissionWindow {
+	    windowID: number;
+	    orgID: number;
+	    kind: string;
+	    name?: string;
+	    opens?: string;
+	    closes: string;
+	    recursYearly: boolean;
+	    fee?: number;
+	    prize?: string;
+	    url?: string;
+	    createdAt: string;
+	    modifiedAt: string;
+	    orgName: string;
+	    orgURL: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SubmissionWindow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.windowID = source["windowID"];
+	        this.orgID = source["orgID"];
+	        this.kind = source["kind"];
+	        this.name = source["name"];
+	        this.opens = source["opens"];
+	        this.closes = source["closes"];
+	        this.recursYearly = source["recursYearly"];
+	        this.fee = source["fee"];
+	        this.prize = source["prize"];
+	        this.url = source["url"];
+	        this.createdAt = source["createdAt"];
+	        this.modifiedAt = source["modifiedAt"];
+	        this.orgName = source["orgName"];
+	        this.orgURL = source["orgURL"];
+	    }
+	}
+	export class WithdrawalItem {
+	    submissionID: number;
+	    orgID: number;
+	    orgName: string;
+	    workID: number;
+	    isCollection: boolean;
+	    title: string;
+	    submissionDate: string;
+	    webAddress: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WithdrawalItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.submissionID = source["submissionID"];
+	        this.orgID = source["orgID"];
+	        this.orgName = source["orgName"];
+	        this.workID = source["workID"];
+	        this.isCollection = source["isCollection"];
+	        this.title = source["title"];
+	        this.submissionDate = source["submissionDate"];
+	        this.webAddress = source["webAddress"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class WithdrawalChecklist {
+	    acceptedID: number;
+	    orgName: string;
+	    workID: number;
+	    isCollection: boolean;
+	    title: string;
+	    items: WithdrawalItem[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WithdrawalChecklist(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.acceptedID = source["acceptedID"];
+	        this.orgName = source["orgName"];
+	        this.workID = source["workID"];
+	        this.isCollection = source["isCollection"];
+	        this.title = source["title"];
+	        this.items = this.convertValues(source["items"], WithdrawalItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class WithdrawalResult {
+	    withdrawn: number[];
+	    errors: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WithdrawalResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.withdrawn = source["withdrawn"];
+	        this.errors = source["errors"];
+	    }
+	}
 	export class Work {
 	    workID: number;
 	    title: string;
@@ -2504,6 +3504,36 @@ export namespace models {
 	        this.modifiedAt = source["modifiedAt"];
 	    }
 	}
+	export class WorkRevision {
+	    revisionID: number;
+	    workID: number;
+	    sha256: string;
+	    size: number;
+	    docType: string;
+	    fileMtime: number;
+	    nWords: number;
+	    wordDelta: number;
+	    note?: string;
+	    createdAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkRevision(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.revisionID = source["revisionID"];
+	        this.workID = source["workID"];
+	        this.sha256 = source["sha256"];
+	        this.size = source["size"];
+	        this.docType = source["docType"];
+	        this.fileMtime = source["fileMtime"];
+	        this.nWords = source["nWords"];
+	        this.wordDelta = source["wordDelta"];
+	        this.note = source["note"];
+	        this.createdAt = source["createdAt"];
+	    }
+	}
 	export class WorkView {
 	    workID: number;
 	    title: string;
@@ -2567,6 +3597,153 @@ export namespace models {
 
 }
 
+export namespace recommend {
+	
+	export class Options {
+	    workID?: number;
+	    orgID?: number;
+	    minQuality?: string;
+	    statuses?: string[];
+	    maxWords?: number;
+	    packetSize?: number;
+	    limit?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Options(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.workID = source["workID"];
+	        this.orgID = source["orgID"];
+	        this.minQuality = source["minQuality"];
+	        this.statuses = source["statuses"];
+	        this.maxWords = source["maxWords"];
+	        this.packetSize = source["packetSize"];
+	        this.limit = source["limit"];
+	    }
+	}
+	
+	export class Reason {
+	    points: number;
+	    text: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Reason(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.points = source["points"];
+	        this.text = source["text"];
+	    }
+	}
+	
+	export class Suggestion {
+	    workID: number;
+	    title: string;
+	    type: string;
+	    nWords: number;
+	    orgID: number;
+	    orgName: string;
+	    score: number;
+	    reasons: Reason[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Suggestion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.workID = source["workID"];
+	        this.title = source["title"];
+	        this.type = source["type"];
+	        this.nWords = source["nWords"];
+	        this.orgID = source["orgID"];
+	        this.orgName = source["orgName"];
+	        this.score = source["score"];
+	        this.reasons = this.convertValues(source["reasons"], Reason);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class Packet {
+	    orgID: number;
+	    orgName: string;
+	    score: number;
+	    works: Suggestion[];
+	    notes: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Packet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.orgID = source["orgID"];
+	        this.orgName = source["orgName"];
+	        this.score = source["score"];
+	        this.works = this.convertValues(source["works"], Suggestion);
+	        this.notes = source["notes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace revisions {
+	
+	export class Version {
+	    kind: string;
+	    revisionID?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Version(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.revisionID = source["revisionID"];
+	    }
+	}
+
+}
+
 export namespace settings {
 	
 	export class Settings {
@@ -2584,6 +3761,18 @@ export namespace settings {
 	    validExtensions?: string[];
 	    skipDeleteBackupConfirm?: boolean;
 	    skipNumberAsSortedConfirm?: boolean;
+	    authorName?: string;
+	    contactHeader?: string;
+	    backupCompression: string;
+	    backupKeepDaily: number;
+	    backupKeepWeekly: number;
+	    backupKeepMonthly: number;
+	    backupKeepYearly: number;
+	    backupIncludeFTS: boolean;
+	    backupIncludeTemplates: boolean;
+	    backupIncludeConfig: boolean;
+	    pdfConverterPriority?: string[];
+	    pdfMaxConversions?: number;
 	    analysisEnabled?: boolean;
 	    analysisProvider?: string;
 	    analysisModel?: string;
@@ -2611,6 +3800,18 @@ export namespace settings {
 	        this.validExtensions = source["validExtensions"];
 	        this.skipDeleteBackupConfirm = source["skipDeleteBackupConfirm"];
 	        this.skipNumberAsSortedConfirm = source["skipNumberAsSortedConfirm"];
+	        this.authorName = source["authorName"];
+	        this.contactHeader = source["contactHeader"];
+	        this.backupCompression = source["backupCompression"];
+	        this.backupKeepDaily = source["backupKeepDaily"];
+	        this.backupKeepWeekly = source["backupKeepWeekly"];
+	        this.backupKeepMonthly = source["backupKeepMonthly"];
+	        this.backupKeepYearly = source["backupKeepYearly"];
+	        this.backupIncludeFTS = source["backupIncludeFTS"];
+	        this.backupIncludeTemplates = source["backupIncludeTemplates"];
+	        this.backupIncludeConfig = source["backupIncludeConfig"];
+	        this.pdfConverterPriority = source["pdfConverterPriority"];
+	        this.pdfMaxConversions = source["pdfMaxConversions"];
 	        this.analysisEnabled = source["analysisEnabled"];
 	        this.analysisProvider = source["analysisProvider"];
 	        this.analysisModel = source["analysisModel"];
@@ -2834,6 +4035,161 @@ export namespace state {
 	
 	
 
+}
+
+export namespace textdiff {
+	
+	export class Segment {
+	    op: string;
+	    text: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Segment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.op = source["op"];
+	        this.text = source["text"];
+	    }
+	}
+	export class Paragraph {
+	    op: string;
+	    from?: string;
+	    to?: string;
+	    fromLine?: number;
+	    toLine?: number;
+	    words?: Segment[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Paragraph(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.op = source["op"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.fromLine = source["fromLine"];
+	        this.toLine = source["toLine"];
+	        this.words = this.convertValues(source["words"], Segment);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Hunk {
+	    fromStart: number;
+	    fromCount: number;
+	    toStart: number;
+	    toCount: number;
+	    paragraphs: Paragraph[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Hunk(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.fromStart = source["fromStart"];
+	        this.fromCount = source["fromCount"];
+	        this.toStart = source["toStart"];
+	        this.toCount = source["toCount"];
+	        this.paragraphs = this.convertValues(source["paragraphs"], Paragraph);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class Stats {
+	    paragraphsAdded: number;
+	    paragraphsRemoved: number;
+	    paragraphsChanged: number;
+	    wordsAdded: number;
+	    wordsRemoved: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Stats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.paragraphsAdded = source["paragraphsAdded"];
+	        this.paragraphsRemoved = source["paragraphsRemoved"];
+	        this.paragraphsChanged = source["paragraphsChanged"];
+	        this.wordsAdded = source["wordsAdded"];
+	        this.wordsRemoved = source["wordsRemoved"];
+	    }
+	}
+	export class Result {
+	    from?: string;
+	    to?: string;
+	    hunks: Hunk[];
+	    stats: Stats;
+	
+	    static createFrom(source: any = {}) {
+	        return new Result(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.hunks = this.convertValues(source["hunks"], Hunk);
+	        this.stats = this.convertValues(source["stats"], Stats);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+
 }
 
 export namespace validation {
@@ -0,0 +1,387 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+const (
+	manifestName        = "manifest.json"
+	archiveDBName       = "works.db"
+	archiveFTSName      = "fulltext.db"
+	archiveConfigName   = "config.json"
+	archiveTemplatesDir = "templates"
+
+	manifestVersion = 1
+)
+
+type archiveFile struct {
+	name string // path inside the archive
+	path string // path on disk
+}
+
+// manifest is the first entry of every archive and records what the archive
+// holds so it can be verified without trusting the tar headers alone
+type manifest struct {
+	Version   int             `json:"version"`
+	CreatedAt string          `json:"createdAt"`
+	Label     string          `json:"label,omitempty"`
+	Files     []manifestEntry `json:"files"`
+}
+
+type manifestEntry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+func (mf *manifest) names() []string {
+	names := make([]string, 0, len(mf.Files))
+	for _, f := range mf.Files {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func (mf *manifest) hash(name string) string {
+	for _, f := range mf.Files {
+		if f.Name == name {
+			return f.SHA256
+		}
+	}
+	return ""
+}
+
+// collectDir lists the regular files under dir, named prefix/<relative path>.
+// A missing directory contributes nothing.
+func collectDir(dir, prefix string) ([]archiveFile, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var files []archiveFile
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, archiveFile{name: prefix + "/" + filepath.ToSlash(rel), path: path})
+		return nil
+	})
+	return files, err
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeArchive writes files to a tar archive at path, manifest first
+func writeArchive(path, compression, label string, now time.Time, files []archiveFile) (*manifest, error) {
+	mf := &manifest{
+		Version:   manifestVersion,
+		CreatedAt: now.Format(time.RFC3339),
+		Label:     label,
+		Files:     make([]manifestEntry, 0, len(files)),
+	}
+	for _, f := range files {
+		stat, err := os.Stat(f.path)
+		if err != nil {
+			return nil, err
+		}
+		sum, err := hashFile(f.path)
+		if err != nil {
+			return nil, err
+		}
+		mf.Files = append(mf.Files, manifestEntry{Name: f.name, Size: stat.Size(), SHA256: sum})
+	}
+	mfData, err := json.MarshalIndent(mf, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := openArchiveWriter(path, compression)
+	if err != nil {
+		return nil, err
+	}
+	tw := tar.NewWriter(w)
+
+	writeErr := func() error {
+		hdr := &tar.Header{Name: manifestName, Mode: 0644, Size: int64(len(mfData)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(mfData); err != nil {
+			return err
+		}
+
+		for i, f := range files {
+			hdr := &tar.Header{Name: f.name, Mode: 0644, Size: mf.Files[i].Size, ModTime: now}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			src, err := os.Open(f.path)
+			if err != nil {
+				return err
+			}
+			_, err = io.CopyN(tw, src, hdr.Size)
+			src.Close()
+			if err != nil {
+				return fmt.Errorf("%s changed while archiving: %w", f.name, err)
+			}
+		}
+		return tw.Close()
+	}()
+
+	if err := w.Close(); err != nil && writeErr == nil {
+		writeErr = err
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
+	return mf, nil
+}
+
+// readManifest returns an archive's manifest without reading the rest of it
+func readManifest(path string) (*manifest, error) {
+	r, err := openArchiveReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return nextManifest(tar.NewReader(r))
+}
+
+func nextManifest(tr *tar.Reader) (*manifest, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	if hdr.Name != manifestName {
+		return nil, fmt.Errorf("archive does not start with %s", manifestName)
+	}
+	mf := &manifest{}
+	if err := json.NewDecoder(tr).Decode(mf); err != nil {
+		return nil, fmt.Errorf("decode manifest: %w", err)
+	}
+	return mf, nil
+}
+
+// verifyArchive reads an entire archive and checks each file against the
+// manifest's size and hash
+func verifyArchive(path string) (*manifest, error) {
+	r, err := openArchiveReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	tr := tar.NewReader(r)
+	mf, err := nextManifest(tr)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := make(map[string]manifestEntry, len(mf.Files))
+	for _, f := range mf.Files {
+		expected[f.Name] = f
+	}
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		want, ok := expected[hdr.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s is not in the manifest", hdr.Name)
+		}
+		h := sha256.New()
+		n, err := io.Copy(h, tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", hdr.Name, err)
+		}
+		if n != want.Size || hex.EncodeToString(h.Sum(nil)) != want.SHA256 {
+			return nil, fmt.Errorf("%s does not match its recorded checksum", hdr.Name)
+		}
+		delete(expected, hdr.Name)
+	}
+
+	for name := range expected {
+		return nil, fmt.Errorf("%s is missing from the archive", name)
+	}
+	return mf, nil
+}
+
+// extractArchive writes the named archive entries to the given paths and
+// reports which were found. Entries mapped to an empty path are skipped.
+func extractArchive(path string, targets map[string]string) (map[string]bool, error) {
+	r, err := openArchiveReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	found := map[string]bool{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return found, err
+		}
+		dst := targets[hdr.Name]
+		if dst == "" {
+			continue
+		}
+		out, err := os.Create(dst)
+		if err != nil {
+			return found, err
+		}
+		_, err = io.Copy(out, tr)
+		if syncErr := out.Sync(); err == nil {
+			err = syncErr
+		}
+		out.Close()
+		if err != nil {
+			return found, fmt.Errorf("extract %s: %w", hdr.Name, err)
+		}
+		found[hdr.Name] = true
+	}
+	return found, nil
+}
+
+type closerFunc func() error
+
+type writeCloser struct {
+	io.Writer
+	close closerFunc
+}
+
+func (w writeCloser) Close() error { return w.close() }
+
+type readCloser struct {
+	io.Reader
+	close closerFunc
+}
+
+func (r readCloser) Close() error { return r.close() }
+
+// openArchiveWriter returns a writer that compresses into path. zstd is
+// delegated to the zstd command since the standard library lacks it.
+func openArchiveWriter(path, compression string) (io.WriteCloser, error) {
+	if compression == CompressionZstd {
+		cmd := exec.Command("zstd", "-q", "-f", "-o", path)
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("start zstd: %w", err)
+		}
+		return writeCloser{Writer: stdin, close: func() error {
+			closeErr := stdin.Close()
+			if err := cmd.Wait(); err != nil {
+				return fmt.Errorf("zstd: %w", err)
+			}
+			return closeErr
+		}}, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if compression == CompressionNone {
+		return writeCloser{Writer: f, close: func() error {
+			if err := f.Sync(); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		}}, nil
+	}
+
+	gz := gzip.NewWriter(f)
+	return writeCloser{Writer: gz, close: func() error {
+		if err := gz.Close(); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}}, nil
+}
+
+// openArchiveReader returns the decompressed tar stream of an archive
+func openArchiveReader(path string) (io.ReadCloser, error) {
+	format := backupFormat(path)
+	if format == CompressionZstd {
+		cmd := exec.Command("zstd", "-q", "-d", "-c", path)
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("start zstd: %w", err)
+		}
+		return readCloser{Reader: stdout, close: func() error {
+			// The caller may stop early, e.g. after the manifest
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+			return nil
+		}}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if format == CompressionNone {
+		return f, nil
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return readCloser{Reader: gz, close: func() error {
+		gz.Close()
+		return f.Close()
+	}}, nil
+}
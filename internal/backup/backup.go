@@ -1,6 +1,7 @@
 package backup
 
 import (
+	"database/sql"
 	"fmt"
 	"io"
 	"os"
@@ -8,13 +9,14 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	_ "modernc.org/sqlite"
 )
 
 type Manager struct {
 	dbPath    string
 	backupDir string
-	maxAge    time.Duration
-	maxCount  int
+	options   Options
 }
 
 type BackupInfo struct {
@@ -22,6 +24,8 @@ type BackupInfo struct {
 	Path        string    `json:"path"`
 	Size        int64     `json:"size"`
 	CreatedAt   string    `json:"createdAt"`
+	Format      string    `json:"format"`
+	Contents    []string  `json:"contents,omitempty"`
 	createdTime time.Time `json:"-"` // internal use only, not exported to JSON
 }
 
@@ -32,40 +36,109 @@ func NewManager(dbPath string) *Manager {
 	return &Manager{
 		dbPath:    dbPath,
 		backupDir: backupDir,
-		maxAge:    30 * 24 * time.Hour,
-		maxCount:  10,
+		options:   DefaultOptions(),
 	}
 }
 
+// SetOptions replaces the compression, retention and inclusion options
+func (m *Manager) SetOptions(o Options) {
+	m.options = o
+}
+
+// CreateBackup writes a verified archive holding a consistent snapshot of
+// the database plus whatever optional files the options include
 func (m *Manager) CreateBackup(label string) (*BackupInfo, error) {
+	return m.createBackup(label, "")
+}
+
+// createBackup does the work of CreateBackup. When skipIfHash matches the
+// new snapshot's hash, nothing is written and the returned info is nil.
+func (m *Manager) createBackup(label, skipIfHash string) (*BackupInfo, error) {
+	if _, err := os.Stat(m.dbPath); err != nil {
+		return nil, fmt.Errorf("database not found: %w", err)
+	}
 	if err := os.MkdirAll(m.backupDir, 0755); err != nil {
 		return nil, fmt.Errorf("create backup dir: %w", err)
 	}
 
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	filename := fmt.Sprintf("works_%s", timestamp)
-	if label != "" {
-		filename = fmt.Sprintf("works_%s_%s", timestamp, sanitizeLabel(label))
+	staging, err := os.MkdirTemp(m.backupDir, ".staging-")
+	if err != nil {
+		return nil, fmt.Errorf("create staging dir: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	files := []archiveFile{}
+
+	dbSnapshot := filepath.Join(staging, archiveDBName)
+	if err := snapshotDatabase(m.dbPath, dbSnapshot); err != nil {
+		return nil, fmt.Errorf("snapshot database: %w", err)
+	}
+	dbHash, err := hashFile(dbSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	if skipIfHash != "" && dbHash == skipIfHash {
+		return nil, nil
+	}
+	files = append(files, archiveFile{name: archiveDBName, path: dbSnapshot})
+
+	if m.options.FTSPath != "" {
+		if _, err := os.Stat(m.options.FTSPath); err == nil {
+			ftsSnapshot := filepath.Join(staging, archiveFTSName)
+			if err := snapshotDatabase(m.options.FTSPath, ftsSnapshot); err != nil {
+				return nil, fmt.Errorf("snapshot search index: %w", err)
+			}
+			files = append(files, archiveFile{name: archiveFTSName, path: ftsSnapshot})
+		}
+	}
+
+	if m.options.ConfigPath != "" {
+		if _, err := os.Stat(m.options.ConfigPath); err == nil {
+			files = append(files, archiveFile{name: archiveConfigName, path: m.options.ConfigPath})
+		}
+	}
+
+	if m.options.TemplateDir != "" {
+		templates, err := collectDir(m.options.TemplateDir, archiveTemplatesDir)
+		if err != nil {
+			return nil, fmt.Errorf("collect templates: %w", err)
+		}
+		files = append(files, templates...)
 	}
-	filename += ".db"
 
+	compression := m.options.effectiveCompression()
+	now := time.Now()
+	filename := fmt.Sprintf("works_%s", now.Format("2006-01-02_15-04-05"))
+	if label != "" {
+		filename = fmt.Sprintf("works_%s_%s", now.Format("2006-01-02_15-04-05"), sanitizeLabel(label))
+	}
+	filename += archiveExtension(compression)
 	backupPath := filepath.Join(m.backupDir, filename)
 
-	if err := copyFile(m.dbPath, backupPath); err != nil {
-		return nil, fmt.Errorf("copy database: %w", err)
+	tempPath := filepath.Join(staging, filename)
+	mf, err := writeArchive(tempPath, compression, label, now, files)
+	if err != nil {
+		return nil, fmt.Errorf("write archive: %w", err)
+	}
+	if _, err := verifyArchive(tempPath); err != nil {
+		return nil, fmt.Errorf("verify archive: %w", err)
+	}
+	if err := os.Rename(tempPath, backupPath); err != nil {
+		return nil, fmt.Errorf("move archive into place: %w", err)
 	}
 
-	info, err := os.Stat(backupPath)
+	stat, err := os.Stat(backupPath)
 	if err != nil {
 		return nil, err
 	}
 
-	now := time.Now()
 	return &BackupInfo{
 		Name:        filename,
 		Path:        backupPath,
-		Size:        info.Size(),
+		Size:        stat.Size(),
 		CreatedAt:   now.Format(time.RFC3339),
+		Format:      compression,
+		Contents:    mf.names(),
 		createdTime: now,
 	}, nil
 }
@@ -81,7 +154,8 @@ func (m *Manager) ListBackups() ([]BackupInfo, error) {
 
 	backups := make([]BackupInfo, 0, len(entries))
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "works_") || !strings.HasSuffix(entry.Name(), ".db") {
+		format := backupFormat(entry.Name())
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "works_") || format == "" {
 			continue
 		}
 
@@ -96,6 +170,7 @@ func (m *Manager) ListBackups() ([]BackupInfo, error) {
 			Path:        filepath.Join(m.backupDir, entry.Name()),
 			Size:        info.Size(),
 			CreatedAt:   modTime.Format(time.RFC3339),
+			Format:      format,
 			createdTime: modTime,
 		})
 	}
@@ -107,19 +182,100 @@ func (m *Manager) ListBackups() ([]BackupInfo, error) {
 	return backups, nil
 }
 
+// VerifyBackup checks that every file in an archive matches its recorded
+// hash and that the database inside passes SQLite's integrity check. Plain
+// .db backups from older versions are integrity-checked directly.
+func (m *Manager) VerifyBackup(backupPath string) (*BackupInfo, error) {
+	stat, err := os.Stat(backupPath)
+	if err != nil {
+		return nil, fmt.Errorf("backup not found: %w", err)
+	}
+
+	info := &BackupInfo{
+		Name:      filepath.Base(backupPath),
+		Path:      backupPath,
+		Size:      stat.Size(),
+		CreatedAt: stat.ModTime().Format(time.RFC3339),
+		Format:    backupFormat(backupPath),
+	}
+
+	if info.Format == formatLegacy {
+		if err := integrityCheck(backupPath); err != nil {
+			return nil, err
+		}
+		info.Contents = []string{archiveDBName}
+		return info, nil
+	}
+
+	mf, err := verifyArchive(backupPath)
+	if err != nil {
+		return nil, err
+	}
+	info.Contents = mf.names()
+
+	temp, err := os.CreateTemp("", "works-verify-*.db")
+	if err != nil {
+		return nil, err
+	}
+	temp.Close()
+	defer os.Remove(temp.Name())
+
+	if _, err := extractArchive(backupPath, map[string]string{archiveDBName: temp.Name()}); err != nil {
+		return nil, fmt.Errorf("extract database: %w", err)
+	}
+	if err := integrityCheck(temp.Name()); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// RestoreBackup replaces the database (and the search index, when the
+// archive holds one) with the backup's copy. No connection to either
+// database may be open. Templates and config.json are not restored
+// automatically; extract them from the archive by hand if needed.
 func (m *Manager) RestoreBackup(backupPath string) error {
 	if _, err := os.Stat(backupPath); err != nil {
 		return fmt.Errorf("backup not found: %w", err)
 	}
 
 	tempPath := m.dbPath + ".restore-temp"
-	if err := copyFile(backupPath, tempPath); err != nil {
-		return fmt.Errorf("copy backup: %w", err)
+	defer os.Remove(tempPath)
+
+	if backupFormat(backupPath) == formatLegacy {
+		if err := copyFile(backupPath, tempPath); err != nil {
+			return fmt.Errorf("copy backup: %w", err)
+		}
+		return replaceDatabase(tempPath, m.dbPath)
 	}
 
-	if err := os.Rename(tempPath, m.dbPath); err != nil {
-		os.Remove(tempPath)
-		return fmt.Errorf("replace database: %w", err)
+	if _, err := verifyArchive(backupPath); err != nil {
+		return fmt.Errorf("verify archive: %w", err)
+	}
+
+	ftsTemp := ""
+	if m.options.FTSPath != "" {
+		ftsTemp = m.options.FTSPath + ".restore-temp"
+		defer os.Remove(ftsTemp)
+	}
+
+	found, err := extractArchive(backupPath, map[string]string{
+		archiveDBName:  tempPath,
+		archiveFTSName: ftsTemp,
+	})
+	if err != nil {
+		return fmt.Errorf("extract backup: %w", err)
+	}
+	if !found[archiveDBName] {
+		return fmt.Errorf("backup has no %s", archiveDBName)
+	}
+
+	if err := replaceDatabase(tempPath, m.dbPath); err != nil {
+		return err
+	}
+	if found[archiveFTSName] {
+		if err := replaceDatabase(ftsTemp, m.options.FTSPath); err != nil {
+			return fmt.Errorf("restore search index: %w", err)
+		}
 	}
 
 	return nil
@@ -132,50 +288,50 @@ func (m *Manager) DeleteBackup(backupPath string) error {
 	return os.Remove(backupPath)
 }
 
+// CleanupOldBackups removes backups the retention policy no longer keeps
 func (m *Manager) CleanupOldBackups() (int, error) {
 	backups, err := m.ListBackups()
 	if err != nil {
 		return 0, err
 	}
 
-	removed := 0
-	cutoff := time.Now().Add(-m.maxAge)
-
-	for i, backup := range backups {
-		shouldRemove := false
+	keep := retained(backups, m.options.Retention)
 
-		if i >= m.maxCount {
-			shouldRemove = true
-		}
-
-		if backup.createdTime.Before(cutoff) {
-			shouldRemove = true
+	removed := 0
+	for _, backup := range backups {
+		if keep[backup.Path] {
+			continue
 		}
-
-		if shouldRemove {
-			if err := os.Remove(backup.Path); err == nil {
-				removed++
-			}
+		if err := os.Remove(backup.Path); err == nil {
+			removed++
 		}
 	}
 
 	return removed, nil
 }
 
+// AutoBackup takes a daily backup, skipping it when the database has not
+// changed since the most recent archive
 func (m *Manager) AutoBackup() (*BackupInfo, error) {
 	backups, err := m.ListBackups()
 	if err != nil {
 		return nil, err
 	}
 
+	lastHash := ""
 	if len(backups) > 0 {
 		lastBackup := backups[0]
 		if time.Since(lastBackup.createdTime) < 24*time.Hour {
 			return nil, nil
 		}
+		if lastBackup.Format != formatLegacy {
+			if mf, err := readManifest(lastBackup.Path); err == nil {
+				lastHash = mf.hash(archiveDBName)
+			}
+		}
 	}
 
-	backup, err := m.CreateBackup("auto")
+	backup, err := m.createBackup("auto", lastHash)
 	if err != nil {
 		return nil, err
 	}
@@ -185,6 +341,53 @@ func (m *Manager) AutoBackup() (*BackupInfo, error) {
 	return backup, nil
 }
 
+// snapshotDatabase writes a transactionally consistent, compacted copy of a
+// live SQLite database (including pages still in its WAL) and checks it
+func snapshotDatabase(src, dst string) error {
+	conn, err := sql.Open("sqlite", src)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Exec(`VACUUM INTO ?`, dst); err != nil {
+		return err
+	}
+	return integrityCheck(dst)
+}
+
+// integrityCheck runs PRAGMA integrity_check on a database file
+func integrityCheck(path string) error {
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var result string
+	if err := conn.QueryRow(`PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("integrity check %s: %w", filepath.Base(path), err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("integrity check %s failed: %s", filepath.Base(path), result)
+	}
+	return nil
+}
+
+// replaceDatabase moves a checked copy over a closed database, dropping any
+// WAL or shared-memory files that belonged to the old one
+func replaceDatabase(src, dst string) error {
+	if err := integrityCheck(src); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("replace database: %w", err)
+	}
+	_ = os.Remove(dst + "-wal")
+	_ = os.Remove(dst + "-shm")
+	return nil
+}
+
 func copyFile(src, dst string) error {
 	srcFile, err := os.Open(src)
 	if err != nil {
@@ -0,0 +1,202 @@
+package backup
+
+import (
+	"database/sql"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestManager(t *testing.T, compression string) (*Manager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "works.db")
+
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	for _, stmt := range []string{
+		`PRAGMA journal_mode = WAL`,
+		`CREATE TABLE Works (workID INTEGER PRIMARY KEY, title TEXT)`,
+		`INSERT INTO Works (title) VALUES ('Rain')`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	templates := filepath.Join(dir, "templates")
+	if err := os.MkdirAll(templates, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(templates, "Template.docx"), []byte("template"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{
+		dbPath:    dbPath,
+		backupDir: filepath.Join(dir, "backups"),
+		options: Options{
+			Compression: compression,
+			Retention:   Retention{Daily: 7},
+			TemplateDir: templates,
+		},
+	}
+	return m, dbPath
+}
+
+func countWorks(t *testing.T, dbPath string) int {
+	t.Helper()
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	var n int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM Works`).Scan(&n); err != nil {
+		t.Fatalf("count works: %v", err)
+	}
+	return n
+}
+
+func TestCreateVerifyRestore(t *testing.T) {
+	for _, compression := range []string{CompressionNone, CompressionGzip, CompressionZstd} {
+		t.Run(compression, func(t *testing.T) {
+			if compression == CompressionZstd {
+				if _, err := exec.LookPath("zstd"); err != nil {
+					t.Skip("zstd not installed")
+				}
+			}
+			m, dbPath := newTestManager(t, compression)
+
+			info, err := m.CreateBackup("before edit")
+			if err != nil {
+				t.Fatalf("create backup: %v", err)
+			}
+			if info.Format != compression {
+				t.Errorf("expected format %s, got %s", compression, info.Format)
+			}
+			want := map[string]bool{archiveDBName: true, "templates/Template.docx": true}
+			for _, name := range info.Contents {
+				delete(want, name)
+			}
+			if len(want) > 0 {
+				t.Errorf("archive is missing %v (has %v)", want, info.Contents)
+			}
+
+			if _, err := m.VerifyBackup(info.Path); err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+
+			conn, _ := sql.Open("sqlite", dbPath)
+			_, _ = conn.Exec(`INSERT INTO Works (title) VALUES ('Snow')`)
+			conn.Close()
+			if n := countWorks(t, dbPath); n != 2 {
+				t.Fatalf("expected 2 works before restore, got %d", n)
+			}
+
+			if err := m.RestoreBackup(info.Path); err != nil {
+				t.Fatalf("restore: %v", err)
+			}
+			if n := countWorks(t, dbPath); n != 1 {
+				t.Fatalf("expected 1 work after restore, got %d", n)
+			}
+		})
+	}
+}
+
+func TestVerifyDetectsCorruption(t *testing.T) {
+	m, _ := newTestManager(t, CompressionNone)
+
+	info, err := m.CreateBackup("")
+	if err != nil {
+		t.Fatalf("create backup: %v", err)
+	}
+
+	data, err := os.ReadFile(info.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Flip a byte well past the manifest, inside the database payload
+	data[len(data)/2] ^= 0xff
+	if err := os.WriteFile(info.Path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.VerifyBackup(info.Path); err == nil {
+		t.Fatal("expected verification to fail on a corrupted archive")
+	}
+	if err := m.RestoreBackup(info.Path); err == nil {
+		t.Fatal("expected restore to refuse a corrupted archive")
+	}
+}
+
+func TestAutoBackupSkipsUnchanged(t *testing.T) {
+	m, _ := newTestManager(t, CompressionGzip)
+
+	first, err := m.CreateBackup("auto")
+	if err != nil {
+		t.Fatalf("create backup: %v", err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(first.Path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := m.AutoBackup()
+	if err != nil {
+		t.Fatalf("auto backup: %v", err)
+	}
+	if info != nil {
+		t.Fatalf("expected no backup of an unchanged database, got %s", info.Name)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	var backups []BackupInfo
+	// Two backups a day for the last 400 days, newest first
+	for i := 0; i < 800; i++ {
+		created := now.Add(-time.Duration(i) * 12 * time.Hour)
+		backups = append(backups, BackupInfo{
+			Path:        created.Format(time.RFC3339),
+			createdTime: created,
+		})
+	}
+
+	keep := retained(backups, Retention{Daily: 7, Weekly: 4, Monthly: 12, Yearly: 3})
+
+	if !keep[backups[0].Path] {
+		t.Error("expected the newest backup to be kept")
+	}
+	if keep[backups[1].Path] {
+		t.Error("expected the older backup on the same day to be dropped")
+	}
+
+	// 7 daily + up to 4 weekly + up to 12 monthly + up to 3 yearly, with overlaps
+	if len(keep) < 12 || len(keep) > 26 {
+		t.Errorf("unexpected number of kept backups: %d", len(keep))
+	}
+
+	years := map[int]bool{}
+	for _, b := range backups {
+		if keep[b.Path] {
+			years[b.createdTime.Year()] = true
+		}
+	}
+	if !years[2024] || !years[2025] {
+		t.Errorf("expected a backup kept for each year, got %v", years)
+	}
+
+	if len(retained(nil, Retention{Daily: 7})) != 0 {
+		t.Error("expected nothing to keep from no backups")
+	}
+	if keep := retained(backups, Retention{}); len(keep) != 1 {
+		t.Errorf("expected only the newest backup with an empty policy, got %d", len(keep))
+	}
+}
@@ -0,0 +1,444 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Manuscript snapshots record every work's document by content hash. File
+// contents live once in objects/ no matter how many snapshots reference
+// them; each snapshot is a JSON manifest in snapshots/ mapping works to
+// hashes. The store is independent of works.db so it survives a database
+// restore.
+const (
+	manuscriptsDirName  = "manuscripts"
+	manuscriptIDLayout  = "2006-01-02_15-04-05"
+	manuscriptTimestamp = time.RFC3339
+
+	// partialSnapshotMaxAge is how long a partial snapshot, such as the copy
+	// taken before a restore, is kept
+	partialSnapshotMaxAge = 30 * 24 * time.Hour
+)
+
+// ManuscriptSource is a work document to include in a snapshot
+type ManuscriptSource struct {
+	WorkID int64
+	Title  string
+	Path   string // absolute path on disk
+}
+
+// ManuscriptFile is one work's document as recorded in a snapshot. Path is
+// relative to the snapshot's base folder when the file lives under it.
+type ManuscriptFile struct {
+	WorkID  int64  `json:"workID"`
+	Title   string `json:"title"`
+	Path    string `json:"path"`
+	SHA256  string `json:"sha256,omitempty"`
+	Size    int64  `json:"size,omitempty"`
+	ModTime string `json:"modTime,omitempty"`
+}
+
+type ManuscriptSnapshot struct {
+	ID         string           `json:"id"`
+	CreatedAt  string           `json:"createdAt"`
+	Label      string           `json:"label,omitempty"`
+	BaseFolder string           `json:"baseFolder"`
+	Partial    bool             `json:"partial,omitempty"`
+	Files      []ManuscriptFile `json:"files"`
+	Missing    []ManuscriptFile `json:"missing,omitempty"`
+	TotalBytes int64            `json:"totalBytes"`
+	NewBytes   int64            `json:"newBytes"`
+}
+
+// ManuscriptSnapshotInfo summarizes a snapshot without its file list
+type ManuscriptSnapshotInfo struct {
+	ID           string `json:"id"`
+	CreatedAt    string `json:"createdAt"`
+	Label        string `json:"label,omitempty"`
+	Partial      bool   `json:"partial,omitempty"`
+	FileCount    int    `json:"fileCount"`
+	MissingCount int    `json:"missingCount"`
+	TotalBytes   int64  `json:"totalBytes"`
+	NewBytes     int64  `json:"newBytes"`
+}
+
+// ManuscriptVersion is a distinct version of a work's document and the
+// first snapshot that captured it
+type ManuscriptVersion struct {
+	SnapshotID string `json:"snapshotID"`
+	CreatedAt  string `json:"createdAt"`
+	Path       string `json:"path"`
+	SHA256     string `json:"sha256"`
+	Size       int64  `json:"size"`
+	ModTime    string `json:"modTime"`
+}
+
+func (s *ManuscriptSnapshot) info() ManuscriptSnapshotInfo {
+	return ManuscriptSnapshotInfo{
+		ID:           s.ID,
+		CreatedAt:    s.CreatedAt,
+		Label:        s.Label,
+		Partial:      s.Partial,
+		FileCount:    len(s.Files),
+		MissingCount: len(s.Missing),
+		TotalBytes:   s.TotalBytes,
+		NewBytes:     s.NewBytes,
+	}
+}
+
+func (m *Manager) manuscriptsDir() string {
+	return filepath.Join(m.backupDir, manuscriptsDirName)
+}
+
+func (m *Manager) objects() *ObjectStore {
+	return NewObjectStore(filepath.Join(m.manuscriptsDir(), "objects"))
+}
+
+func (m *Manager) manifestPath(id string) string {
+	return filepath.Join(m.manuscriptsDir(), "snapshots", id+".json")
+}
+
+// SnapshotManuscripts stores the current document of every source. Files
+// unchanged since the previous snapshot (same size and modification time)
+// are not re-read.
+func (m *Manager) SnapshotManuscripts(baseFolder string, sources []ManuscriptSource, label string) (*ManuscriptSnapshotInfo, error) {
+	return m.snapshotManuscripts(baseFolder, sources, label, false)
+}
+
+// PreserveManuscripts snapshots a few documents, e.g. before they are
+// overwritten. Partial snapshots are exempt from retention so they never
+// displace a full snapshot.
+func (m *Manager) PreserveManuscripts(baseFolder string, sources []ManuscriptSource, label string) (*ManuscriptSnapshotInfo, error) {
+	return m.snapshotManuscripts(baseFolder, sources, label, true)
+}
+
+func (m *Manager) snapshotManuscripts(baseFolder string, sources []ManuscriptSource, label string, partial bool) (*ManuscriptSnapshotInfo, error) {
+	if err := os.MkdirAll(filepath.Join(m.manuscriptsDir(), "snapshots"), 0755); err != nil {
+		return nil, fmt.Errorf("create snapshot dir: %w", err)
+	}
+
+	// Reuse hashes from the latest snapshot for files that have not changed
+	known := map[string]ManuscriptFile{}
+	if infos, err := m.ListManuscriptSnapshots(); err == nil {
+		if latest := latestFull(infos); latest != nil {
+			if prev, err := m.GetManuscriptSnapshot(latest.ID); err == nil {
+				for _, f := range prev.Files {
+					known[f.Path] = f
+				}
+			}
+		}
+	}
+
+	now := time.Now()
+	snap := &ManuscriptSnapshot{
+		ID:         m.uniqueManuscriptID(now),
+		CreatedAt:  now.UTC().Format(manuscriptTimestamp),
+		Label:      label,
+		BaseFolder: baseFolder,
+		Partial:    partial,
+		Files:      []ManuscriptFile{},
+	}
+
+	for _, src := range sources {
+		file := ManuscriptFile{WorkID: src.WorkID, Title: src.Title, Path: relativeTo(baseFolder, src.Path)}
+
+		stat, err := os.Stat(src.Path)
+		if err != nil || !stat.Mode().IsRegular() {
+			snap.Missing = append(snap.Missing, file)
+			continue
+		}
+		file.Size = stat.Size()
+		file.ModTime = stat.ModTime().UTC().Format(manuscriptTimestamp)
+
+		if prev, ok := known[file.Path]; ok && prev.Size == file.Size && prev.ModTime == file.ModTime {
+			if m.objects().Has(prev.SHA256) {
+				file.SHA256 = prev.SHA256
+			}
+		}
+		if file.SHA256 == "" {
+			sum, added, err := m.objects().Put(src.Path)
+			if err != nil {
+				// Typically a document saved mid-snapshot; the next one will catch it
+				snap.Missing = append(snap.Missing, file)
+				continue
+			}
+			file.SHA256 = sum
+			if added {
+				snap.NewBytes += file.Size
+			}
+		}
+
+		snap.TotalBytes += file.Size
+		snap.Files = append(snap.Files, file)
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	tmp := m.manifestPath(snap.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return nil, fmt.Errorf("write manifest: %w", err)
+	}
+	if err := os.Rename(tmp, m.manifestPath(snap.ID)); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("write manifest: %w", err)
+	}
+
+	info := snap.info()
+	return &info, nil
+}
+
+func (m *Manager) uniqueManuscriptID(now time.Time) string {
+	id := now.Format(manuscriptIDLayout)
+	for i := 2; ; i++ {
+		if _, err := os.Stat(m.manifestPath(id)); os.IsNotExist(err) {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", now.Format(manuscriptIDLayout), i)
+	}
+}
+
+func relativeTo(base, path string) string {
+	if base != "" {
+		if rel, err := filepath.Rel(base, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
+
+// ListManuscriptSnapshots returns snapshot summaries, newest first
+func (m *Manager) ListManuscriptSnapshots() ([]ManuscriptSnapshotInfo, error) {
+	entries, err := os.ReadDir(filepath.Join(m.manuscriptsDir(), "snapshots"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []ManuscriptSnapshotInfo{}, nil
+		}
+		return nil, err
+	}
+
+	infos := make([]ManuscriptSnapshotInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		snap, err := m.GetManuscriptSnapshot(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		infos = append(infos, snap.info())
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt > infos[j].CreatedAt ||
+			(infos[i].CreatedAt == infos[j].CreatedAt && infos[i].ID > infos[j].ID)
+	})
+	return infos, nil
+}
+
+// latestFull returns the newest snapshot that covers every work
+func latestFull(infos []ManuscriptSnapshotInfo) *ManuscriptSnapshotInfo {
+	for i := range infos {
+		if !infos[i].Partial {
+			return &infos[i]
+		}
+	}
+	return nil
+}
+
+func (m *Manager) GetManuscriptSnapshot(id string) (*ManuscriptSnapshot, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid snapshot id")
+	}
+	data, err := os.ReadFile(m.manifestPath(id))
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot %s: %w", id, err)
+	}
+	snap := &ManuscriptSnapshot{}
+	if err := json.Unmarshal(data, snap); err != nil {
+		return nil, fmt.Errorf("decode snapshot %s: %w", id, err)
+	}
+	return snap, nil
+}
+
+// ManuscriptHistory returns each distinct version of a work's document,
+// newest first
+func (m *Manager) ManuscriptHistory(workID int64) ([]ManuscriptVersion, error) {
+	infos, err := m.ListManuscriptSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []ManuscriptVersion{}
+	// Walk oldest to newest so each version is credited to its first snapshot
+	for i := len(infos) - 1; i >= 0; i-- {
+		snap, err := m.GetManuscriptSnapshot(infos[i].ID)
+		if err != nil {
+			continue
+		}
+		for _, f := range snap.Files {
+			if f.WorkID != workID {
+				continue
+			}
+			if n := len(versions); n > 0 && versions[n-1].SHA256 == f.SHA256 {
+				break
+			}
+			versions = append(versions, ManuscriptVersion{
+				SnapshotID: snap.ID,
+				CreatedAt:  snap.CreatedAt,
+				Path:       f.Path,
+				SHA256:     f.SHA256,
+				Size:       f.Size,
+				ModTime:    f.ModTime,
+			})
+			break
+		}
+	}
+
+	for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
+		versions[i], versions[j] = versions[j], versions[i]
+	}
+	return versions, nil
+}
+
+// ManuscriptVersionAt returns the version of a work's document recorded by
+// the latest snapshot taken at or before the given time
+func (m *Manager) ManuscriptVersionAt(workID int64, at time.Time) (*ManuscriptVersion, error) {
+	versions, err := m.ManuscriptHistory(workID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range versions {
+		created, err := time.Parse(manuscriptTimestamp, versions[i].CreatedAt)
+		if err == nil && !created.After(at) {
+			return &versions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no snapshot of work %d at or before %s", workID, at.Format("2006-01-02 15:04"))
+}
+
+// WriteManuscriptVersion writes a stored version of a document to dest
+func (m *Manager) WriteManuscriptVersion(version *ManuscriptVersion, dest string) error {
+	return m.objects().WriteTo(version.SHA256, dest)
+}
+
+// RestoreManuscript writes a work's document as it was at the given time
+// (from the latest snapshot taken at or before it) to dest
+func (m *Manager) RestoreManuscript(workID int64, at time.Time, dest string) (*ManuscriptVersion, error) {
+	version, err := m.ManuscriptVersionAt(workID, at)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.WriteManuscriptVersion(version, dest); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// DeleteManuscriptSnapshot removes a snapshot and any stored files no
+// other snapshot references
+func (m *Manager) DeleteManuscriptSnapshot(id string) error {
+	if _, err := m.GetManuscriptSnapshot(id); err != nil {
+		return err
+	}
+	if err := os.Remove(m.manifestPath(id)); err != nil {
+		return err
+	}
+	_, err := m.collectManuscriptGarbage()
+	return err
+}
+
+// collectManuscriptGarbage deletes objects no snapshot references
+func (m *Manager) collectManuscriptGarbage() (int, error) {
+	infos, err := m.ListManuscriptSnapshots()
+	if err != nil {
+		return 0, err
+	}
+	live := map[string]bool{}
+	for _, info := range infos {
+		snap, err := m.GetManuscriptSnapshot(info.ID)
+		if err != nil {
+			// Never delete objects a manifest we cannot read might need
+			return 0, err
+		}
+		for _, f := range snap.Files {
+			live[f.SHA256] = true
+		}
+	}
+
+	return m.objects().Prune(live)
+}
+
+// CleanupManuscriptSnapshots applies the retention policy to full
+// snapshots, drops partial snapshots older than partialSnapshotMaxAge and
+// removes files no kept snapshot references
+func (m *Manager) CleanupManuscriptSnapshots() (int, error) {
+	infos, err := m.ListManuscriptSnapshots()
+	if err != nil {
+		return 0, err
+	}
+
+	asBackups := make([]BackupInfo, 0, len(infos))
+	var expired []string
+	for _, info := range infos {
+		created, err := time.Parse(manuscriptTimestamp, info.CreatedAt)
+		if err != nil {
+			continue
+		}
+		if info.Partial {
+			if time.Since(created) > partialSnapshotMaxAge {
+				expired = append(expired, info.ID)
+			}
+			continue
+		}
+		asBackups = append(asBackups, BackupInfo{Path: info.ID, createdTime: created})
+	}
+	keep := retained(asBackups, m.options.Retention)
+	for _, b := range asBackups {
+		if !keep[b.Path] {
+			expired = append(expired, b.Path)
+		}
+	}
+
+	removed := 0
+	for _, id := range expired {
+		if os.Remove(m.manifestPath(id)) == nil {
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		if _, err := m.collectManuscriptGarbage(); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// AutoSnapshotManuscripts takes a daily snapshot and applies retention
+func (m *Manager) AutoSnapshotManuscripts(baseFolder string, sources []ManuscriptSource) (*ManuscriptSnapshotInfo, error) {
+	infos, err := m.ListManuscriptSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	if latest := latestFull(infos); latest != nil {
+		if last, err := time.Parse(manuscriptTimestamp, latest.CreatedAt); err == nil && time.Since(last) < 24*time.Hour {
+			return nil, nil
+		}
+	}
+
+	info, err := m.SnapshotManuscripts(baseFolder, sources, "auto")
+	if err != nil {
+		return nil, err
+	}
+
+	_, _ = m.CleanupManuscriptSnapshots()
+
+	return info, nil
+}
@@ -0,0 +1,178 @@
+package backup
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManuscriptSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "Home")
+	m := &Manager{backupDir: filepath.Join(dir, "backups"), options: DefaultOptions()}
+
+	poem := filepath.Join(base, "Poems", "Rain.docx")
+	essay := filepath.Join(base, "Essays", "Snow.docx")
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(poem, "first draft")
+	write(essay, "essay")
+
+	sources := []ManuscriptSource{
+		{WorkID: 1, Title: "Rain", Path: poem},
+		{WorkID: 2, Title: "Snow", Path: essay},
+		{WorkID: 3, Title: "Gone", Path: filepath.Join(base, "Gone.docx")},
+	}
+
+	first, err := m.SnapshotManuscripts(base, sources, "")
+	if err != nil {
+		t.Fatalf("first snapshot: %v", err)
+	}
+	if first.FileCount != 2 || first.MissingCount != 1 {
+		t.Fatalf("expected 2 files and 1 missing, got %+v", first)
+	}
+
+	snap, err := m.GetManuscriptSnapshot(first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Files[0].Path != "Poems/Rain.docx" {
+		t.Errorf("expected path relative to base, got %s", snap.Files[0].Path)
+	}
+
+	checkpoint := time.Now()
+	time.Sleep(1100 * time.Millisecond)
+	write(poem, "second draft")
+
+	second, err := m.SnapshotManuscripts(base, sources, "")
+	if err != nil {
+		t.Fatalf("second snapshot: %v", err)
+	}
+	if second.NewBytes != int64(len("second draft")) {
+		t.Errorf("expected only the changed file to be stored, got %d new bytes", second.NewBytes)
+	}
+
+	history, err := m.ManuscriptHistory(1)
+	if err != nil || len(history) != 2 {
+		t.Fatalf("expected 2 versions of work 1, got %d (%v)", len(history), err)
+	}
+	if history, _ := m.ManuscriptHistory(2); len(history) != 1 {
+		t.Errorf("expected 1 version of the unchanged work, got %d", len(history))
+	}
+
+	restored := filepath.Join(dir, "restored.docx")
+	if _, err := m.RestoreManuscript(1, checkpoint, restored); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if data, _ := os.ReadFile(restored); string(data) != "first draft" {
+		t.Errorf("expected first draft, got %q", data)
+	}
+	if _, err := m.RestoreManuscript(1, checkpoint.Add(-time.Hour), restored); err == nil {
+		t.Error("expected no version before the first snapshot")
+	}
+
+	// Deleting the first snapshot drops the object only it referenced
+	if err := m.DeleteManuscriptSnapshot(first.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := m.RestoreManuscript(1, checkpoint, restored); err == nil {
+		t.Error("expected the deleted version to be gone")
+	}
+	if _, err := m.RestoreManuscript(2, time.Now(), restored); err != nil {
+		t.Errorf("expected the shared version to survive: %v", err)
+	}
+}
+
+func TestRestoreBeforePreserving(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manager{backupDir: filepath.Join(dir, "backups"), options: DefaultOptions()}
+	poem := filepath.Join(dir, "Rain.docx")
+	if err := os.WriteFile(poem, []byte("first draft"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sources := []ManuscriptSource{{WorkID: 1, Title: "Rain", Path: poem}}
+	if _, err := m.SnapshotManuscripts(dir, sources, ""); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if err := os.WriteFile(poem, []byte("second draft"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restoring "today" must pick the snapshot that existed before the
+	// current file was preserved, not the preserved copy itself
+	endOfDay := time.Now().Add(time.Hour)
+	version, err := m.ManuscriptVersionAt(1, endOfDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.PreserveManuscripts(dir, sources, "before restore"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.WriteManuscriptVersion(version, poem); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(poem); string(data) != "first draft" {
+		t.Errorf("expected first draft, got %q", data)
+	}
+	if latest, _ := m.ManuscriptVersionAt(1, endOfDay); latest == nil || latest.Size != int64(len("second draft")) {
+		t.Errorf("expected the replaced draft to be preserved, got %+v", latest)
+	}
+}
+
+func TestCleanupExpiresPartialSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manager{backupDir: filepath.Join(dir, "backups"), options: DefaultOptions()}
+	poem := filepath.Join(dir, "Rain.docx")
+	sources := []ManuscriptSource{{WorkID: 1, Title: "Rain", Path: poem}}
+
+	preserve := func(content string, age time.Duration) string {
+		t.Helper()
+		if err := os.WriteFile(poem, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		info, err := m.PreserveManuscripts(dir, sources, "before restore")
+		if err != nil {
+			t.Fatal(err)
+		}
+		snap, err := m.GetManuscriptSnapshot(info.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		snap.CreatedAt = time.Now().Add(-age).Format(manuscriptTimestamp)
+		data, _ := json.Marshal(snap)
+		if err := os.WriteFile(m.manifestPath(info.ID), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return info.ID
+	}
+	old := preserve("old draft", partialSnapshotMaxAge+time.Hour)
+	recent := preserve("recent draft", time.Hour)
+	oldSnap, err := m.GetManuscriptSnapshot(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := m.CleanupManuscriptSnapshots()
+	if err != nil || removed != 1 {
+		t.Fatalf("expected 1 snapshot removed, got %d (%v)", removed, err)
+	}
+	if _, err := m.GetManuscriptSnapshot(old); err == nil {
+		t.Error("expected the old partial snapshot to be removed")
+	}
+	if _, err := m.GetManuscriptSnapshot(recent); err != nil {
+		t.Errorf("expected the recent partial snapshot to be kept: %v", err)
+	}
+	if m.objects().Has(oldSnap.Files[0].SHA256) {
+		t.Error("expected the old draft's stored file to be pruned")
+	}
+}
@@ -0,0 +1,122 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ObjectStore keeps file contents under their SHA-256 so identical files
+// are stored once. Objects are laid out as dir/ab/abcdef... to keep
+// directories small.
+type ObjectStore struct {
+	dir string
+}
+
+func NewObjectStore(dir string) *ObjectStore {
+	return &ObjectStore{dir: dir}
+}
+
+// Path returns where the object with the given hash lives
+func (s *ObjectStore) Path(sum string) string {
+	if len(sum) < 2 {
+		return filepath.Join(s.dir, sum)
+	}
+	return filepath.Join(s.dir, sum[:2], sum)
+}
+
+// Has reports whether an object is present
+func (s *ObjectStore) Has(sum string) bool {
+	if sum == "" {
+		return false
+	}
+	_, err := os.Stat(s.Path(sum))
+	return err == nil
+}
+
+// Put copies a file into the store under its hash and reports whether it
+// was new
+func (s *ObjectStore) Put(path string) (string, bool, error) {
+	sum, err := hashFile(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	dst := s.Path(sum)
+	if _, err := os.Stat(dst); err == nil {
+		return sum, false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", false, err
+	}
+
+	tmp := dst + ".tmp"
+	if err := copyFile(path, tmp); err != nil {
+		os.Remove(tmp)
+		return "", false, err
+	}
+	// The file may have changed between hashing and copying
+	if copied, err := hashFile(tmp); err != nil || copied != sum {
+		os.Remove(tmp)
+		return "", false, fmt.Errorf("file changed while copying")
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return "", false, err
+	}
+	return sum, true, nil
+}
+
+// WriteTo copies a stored object to dest, replacing it atomically
+func (s *ObjectStore) WriteTo(sum, dest string) error {
+	src, err := os.Open(s.Path(sum))
+	if err != nil {
+		return fmt.Errorf("stored object missing: %w", err)
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp := dest + ".restore-temp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, src)
+	if syncErr := out.Sync(); err == nil {
+		err = syncErr
+	}
+	out.Close()
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Prune deletes every object whose hash is not in live
+func (s *ObjectStore) Prune(live map[string]bool) (int, error) {
+	removed := 0
+	err := filepath.WalkDir(s.dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || live[d.Name()] {
+			return nil
+		}
+		if os.Remove(path) == nil {
+			removed++
+		}
+		return nil
+	})
+	return removed, err
+}
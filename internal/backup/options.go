@@ -0,0 +1,113 @@
+package backup
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/settings"
+)
+
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+
+	// formatLegacy marks a bare .db copy made before backups were archives
+	formatLegacy = "db"
+)
+
+// Retention is a grandfather-father-son policy: the newest backup of each of
+// the last Daily days, Weekly ISO weeks, Monthly months and Yearly years is
+// kept. The newest backup is always kept.
+type Retention struct {
+	Daily   int `json:"daily"`
+	Weekly  int `json:"weekly"`
+	Monthly int `json:"monthly"`
+	Yearly  int `json:"yearly"`
+}
+
+// Options control what goes into a backup and how long it is kept. Empty
+// paths leave that item out of the archive.
+type Options struct {
+	Compression string
+	Retention   Retention
+	FTSPath     string
+	TemplateDir string
+	ConfigPath  string
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Compression: CompressionGzip,
+		Retention:   Retention{Daily: 7, Weekly: 4, Monthly: 12, Yearly: 3},
+	}
+}
+
+// OptionsFromSettings builds backup options from the user's settings
+func OptionsFromSettings(s settings.Settings) Options {
+	homeDir, _ := os.UserHomeDir()
+	worksDir := filepath.Join(homeDir, ".works")
+
+	o := Options{
+		Compression: s.BackupCompression,
+		Retention: Retention{
+			Daily:   s.BackupKeepDaily,
+			Weekly:  s.BackupKeepWeekly,
+			Monthly: s.BackupKeepMonthly,
+			Yearly:  s.BackupKeepYearly,
+		},
+	}
+	if s.BackupIncludeFTS {
+		o.FTSPath = filepath.Join(worksDir, "fulltext.db")
+	}
+	if s.BackupIncludeTemplates {
+		o.TemplateDir = filepath.Join(worksDir, "templates")
+	}
+	if s.BackupIncludeConfig {
+		o.ConfigPath = filepath.Join(worksDir, "config.json")
+	}
+	return o
+}
+
+// effectiveCompression falls back to gzip when zstd is requested but the
+// zstd command is not installed, or the setting is unrecognized
+func (o Options) effectiveCompression() string {
+	switch o.Compression {
+	case CompressionNone:
+		return CompressionNone
+	case CompressionZstd:
+		if _, err := exec.LookPath("zstd"); err == nil {
+			return CompressionZstd
+		}
+	}
+	return CompressionGzip
+}
+
+func archiveExtension(compression string) string {
+	switch compression {
+	case CompressionNone:
+		return ".tar"
+	case CompressionZstd:
+		return ".tar.zst"
+	default:
+		return ".tar.gz"
+	}
+}
+
+// backupFormat identifies a backup by file name, or returns "" for files
+// that are not backups
+func backupFormat(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"):
+		return CompressionGzip
+	case strings.HasSuffix(name, ".tar.zst"):
+		return CompressionZstd
+	case strings.HasSuffix(name, ".tar"):
+		return CompressionNone
+	case strings.HasSuffix(name, ".db"):
+		return formatLegacy
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+)
+
+// retained returns the paths of the backups a retention policy keeps.
+// backups must be sorted newest first.
+func retained(backups []BackupInfo, r Retention) map[string]bool {
+	keep := make(map[string]bool)
+	if len(backups) == 0 {
+		return keep
+	}
+	keep[backups[0].Path] = true
+
+	tiers := []struct {
+		count  int
+		period func(time.Time) string
+	}{
+		{r.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{r.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{r.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+		{r.Yearly, func(t time.Time) string { return t.Format("2006") }},
+	}
+
+	for _, tier := range tiers {
+		seen := make(map[string]bool)
+		for _, b := range backups {
+			if len(seen) >= tier.count {
+				break
+			}
+			p := tier.period(b.createdTime)
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			keep[b.Path] = true
+		}
+	}
+	return keep
+}
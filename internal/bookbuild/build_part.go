@@ -18,18 +18,26 @@ func PartMergedPath(cacheDir string, partID int64) string {
 	return filepath.Join(cacheDir, fmt.Sprintf("part-%d-merged.pdf", partID))
 }
 
+// IsPartCached reports whether the part as last built is still in the
+// keyed cache. A later build reuses it only if none of its inputs changed.
 func IsPartCached(cacheDir string, partID int64) bool {
-	cachePath := PartCachePath(cacheDir, partID)
-	_, err := os.Stat(cachePath)
+	keys := readPartKeys(cacheDir, partID)
+	if len(keys) == 0 {
+		return false
+	}
+	_, err := os.Stat(keys[0])
 	return err == nil
 }
 
+// ClearPartCache forces a part to be rebuilt by dropping the keyed entries
+// its last build used
 func ClearPartCache(cacheDir string, partID int64) error {
-	cachePath := PartCachePath(cacheDir, partID)
-	mergedPath := PartMergedPath(cacheDir, partID)
-
-	_ = os.Remove(cachePath)
-	_ = os.Remove(mergedPath)
+	for _, path := range readPartKeys(cacheDir, partID) {
+		_ = os.Remove(path)
+	}
+	_ = os.Remove(partKeysPath(cacheDir, partID))
+	_ = os.Remove(PartCachePath(cacheDir, partID))
+	_ = os.Remove(PartMergedPath(cacheDir, partID))
 
 	return nil
 }
@@ -52,7 +60,7 @@ func ClearAllPartsCache(cacheDir string) error {
 		}
 	}
 
-	return nil
+	return os.RemoveAll(filepath.Join(cacheDir, keyedCacheName))
 }
 
 func mergeFilesRaw(inFiles []string, outFile string) error {
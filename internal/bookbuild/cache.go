@@ -0,0 +1,161 @@
+package bookbuild
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Overlaid works and merged parts are cached under a hash of everything
+// that shapes them, so a stale entry is never found and an entry made
+// before a setting was toggled is found again when it is toggled back.
+// Entries are immutable; ones no build has used for cacheMaxAge are pruned.
+const (
+	cacheKeyVersion = "1"
+	cacheMaxAge     = 30 * 24 * time.Hour
+	keyedCacheName  = "keyed"
+)
+
+// workCacheInputs is what an overlaid work depends on besides its source
+// PDF. Page numbers are printed, so the first body number is part of the
+// key; the starting page only matters for parity, which decides headers,
+// number placement and which way landscape pages turn.
+type workCacheInputs struct {
+	Version      string        `json:"version"`
+	SourceSHA256 string        `json:"sourceSha256"`
+	Type         ContentType   `json:"type"`
+	Title        string        `json:"title"`
+	PartTitle    string        `json:"partTitle"`
+	PageCount    int           `json:"pageCount"`
+	StartsRecto  bool          `json:"startsRecto"`
+	StartBodyNum int           `json:"startBodyNum"`
+	Config       OverlayConfig `json:"config"`
+}
+
+// WorkCacheKey returns the cache key of a work overlaid from a source PDF
+// with the given hash
+func WorkCacheKey(sourceSHA256 string, item *ContentItem, config OverlayConfig, startBodyNum int) (string, error) {
+	data, err := json.Marshal(workCacheInputs{
+		Version:      cacheKeyVersion,
+		SourceSHA256: sourceSHA256,
+		Type:         item.Type,
+		Title:        item.Title,
+		PartTitle:    item.PartTitle,
+		PageCount:    item.PageCount,
+		StartsRecto:  IsRecto(item.StartPage),
+		StartBodyNum: startBodyNum,
+		Config:       config,
+	})
+	if err != nil {
+		return "", fmt.Errorf("encode cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// PartCacheKey returns the cache key of a part merged from members in
+// order. Members are work cache keys, or blankMember for a blank page.
+func PartCacheKey(members []string) string {
+	h := sha256.New()
+	h.Write([]byte(cacheKeyVersion + "\n"))
+	for _, m := range members {
+		h.Write([]byte(m + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// blankMember stands for a blank page of the given size in a part key
+func blankMember(width, height float64) string {
+	return fmt.Sprintf("blank %.2fx%.2f", width, height)
+}
+
+func keyedWorkPath(cacheDir, key string) string {
+	return filepath.Join(cacheDir, keyedCacheName, "work-"+key+".pdf")
+}
+
+func keyedPartPath(cacheDir, key string) string {
+	return filepath.Join(cacheDir, keyedCacheName, "part-"+key+".pdf")
+}
+
+// partKeysPath lists the keyed entries the last build of a part used, so
+// the part can be cleared by ID
+func partKeysPath(cacheDir string, partID int64) string {
+	return filepath.Join(cacheDir, fmt.Sprintf("part-%d-keys.txt", partID))
+}
+
+// useCached reports whether a keyed entry exists, marking it as used
+func useCached(path string) bool {
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return true
+}
+
+// storeCached copies a freshly built file into the keyed cache
+func storeCached(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	tmp := dst + ".tmp"
+	if err := copyFile(src, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func writePartKeys(cacheDir string, partID int64, paths []string) error {
+	names := make([]string, 0, len(paths))
+	for _, p := range paths {
+		names = append(names, filepath.Base(p))
+	}
+	return os.WriteFile(partKeysPath(cacheDir, partID), []byte(strings.Join(names, "\n")+"\n"), 0644)
+}
+
+func readPartKeys(cacheDir string, partID int64) []string {
+	f, err := os.Open(partKeysPath(cacheDir, partID))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var paths []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" {
+			paths = append(paths, filepath.Join(cacheDir, keyedCacheName, filepath.Base(name)))
+		}
+	}
+	return paths
+}
+
+// pruneKeyedCache removes entries no build has used within maxAge
+func pruneKeyedCache(cacheDir string, maxAge time.Duration) {
+	dir := filepath.Join(cacheDir, keyedCacheName)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		if info.ModTime().Before(cutoff) {
+			_ = os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package bookbuild
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPartCacheFollowsInputs(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, "cache")
+	pdfs := map[string]string{}
+	for _, name := range []string{"rain", "drizzle", "snow", "sleet"} {
+		pdfs[name] = filepath.Join(dir, name+".pdf")
+		if err := CreateTestPortraitPDF(pdfs[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	build := func(rectoHeader string) *PipelineResult {
+		t.Helper()
+		result, err := BuildWithParts(PipelineOptions{
+			Ctx: context.Background(),
+			Manifest: &Manifest{
+				Title:       "Weather",
+				Typography:  DefaultTypography(),
+				RectoHeader: rectoHeader,
+				Parts: []Part{
+					{ID: 1, Title: "Rain", PDF: pdfs["rain"], Works: []Work{{ID: 10, Title: "Drizzle", PDF: pdfs["drizzle"]}}},
+					{ID: 2, Title: "Snow", PDF: pdfs["snow"], Works: []Work{{ID: 20, Title: "Sleet", PDF: pdfs["sleet"]}}},
+				},
+			},
+			CacheDir:   cacheDir,
+			OutputPath: filepath.Join(dir, "book.pdf"),
+			ObjectsDir: filepath.Join(dir, "objects"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	check := func(r *PipelineResult, built, cached int) {
+		t.Helper()
+		if r.PartsBuilt != built || r.PartsCached != cached {
+			t.Errorf("built %d and reused %d parts, want %d and %d", r.PartsBuilt, r.PartsCached, built, cached)
+		}
+	}
+
+	check(build(HeaderBookTitle), 2, 0)
+	check(build(HeaderBookTitle), 0, 2)
+	if !IsPartCached(cacheDir, 1) || !IsPartCached(cacheDir, 2) {
+		t.Error("both parts should be cached")
+	}
+
+	// A setting change rebuilds everything; toggling it back reuses the
+	// earlier entries
+	check(build(HeaderEssayTitle), 2, 0)
+	check(build(HeaderBookTitle), 0, 2)
+
+	// A changed source rebuilds only its part, without the watcher
+	if err := CreateTestMixedPDF(pdfs["sleet"], []bool{false, false}); err != nil {
+		t.Fatal(err)
+	}
+	check(build(HeaderBookTitle), 1, 1)
+
+	if err := ClearPartCache(cacheDir, 1); err != nil {
+		t.Fatal(err)
+	}
+	if IsPartCached(cacheDir, 1) {
+		t.Error("cleared part should not be cached")
+	}
+	check(build(HeaderBookTitle), 1, 1)
+
+	if err := ClearAllPartsCache(cacheDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, keyedCacheName)); !os.IsNotExist(err) {
+		t.Errorf("keyed cache should be gone, got %v", err)
+	}
+	check(build(HeaderBookTitle), 2, 0)
+}
+
+func TestWorkCacheKey(t *testing.T) {
+	item := &ContentItem{Type: ContentTypeWork, Title: "Rain", PageCount: 3, StartPage: 5}
+	config := DefaultOverlayConfig("Weather")
+	key := func(sum string, item *ContentItem, config OverlayConfig, bodyNum int) string {
+		k, err := WorkCacheKey(sum, item, config, bodyNum)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	base := key("aa", item, config, 1)
+
+	moved := *item
+	moved.StartPage = 7
+	if key("aa", &moved, config, 1) != base {
+		t.Error("moving a work by two pages keeps its parity and should keep its key")
+	}
+	moved.StartPage = 6
+	if key("aa", &moved, config, 1) == base {
+		t.Error("a parity change should change the key")
+	}
+	if key("aa", item, config, 2) == base || key("bb", item, config, 1) == base {
+		t.Error("page numbers and source bytes should change the key")
+	}
+	config.PageNumberPosition = PageNumberOuter
+	if key("aa", item, config, 1) == base {
+		t.Error("overlay settings should change the key")
+	}
+}
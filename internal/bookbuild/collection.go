@@ -0,0 +1,168 @@
+package bookbuild
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+const workTypeSection = "Section"
+
+// CollectionManifestOptions describes the inputs needed to turn a collection
+// and its book configuration into a build manifest.
+type CollectionManifestOptions struct {
+	Works          []models.CollectionWork
+	Book           *models.Book
+	CollectionName string
+	PDFPreviewPath string
+	BaseFolderPath string // resolves relative work paths into Work.Source
+	TemplatePath   string
+	BuildDir       string
+	OutputPath     string
+	// FirstLines are the works' opening lines by work ID, used when the
+	// book includes an index
+	FirstLines map[int64]string
+}
+
+// NewCollectionManifest builds a part-based manifest from a collection's works.
+// Front and back matter PDFs are picked up from BuildDir when present.
+func NewCollectionManifest(opts CollectionManifestOptions) (*Manifest, error) {
+	if len(opts.Works) == 0 {
+		return nil, fmt.Errorf("collection has no works")
+	}
+	if opts.Book == nil {
+		return nil, fmt.Errorf("book is required")
+	}
+
+	book := opts.Book
+	manifest := &Manifest{
+		Title:               book.Title,
+		Author:              book.Author,
+		OutputPath:          opts.OutputPath,
+		TemplatePath:        opts.TemplatePath,
+		Typography:          DefaultTypography(),
+		WorksStartRecto:     book.WorksStartRecto == nil || *book.WorksStartRecto,
+		VersoHeader:         book.VersoHeader,
+		RectoHeader:         book.RectoHeader,
+		PageNumberPosition:  book.PageNumberPosition,
+		SuppressPageNumbers: book.SuppressPageNumbers,
+		Index:               IncludesIndex(book),
+	}
+
+	var styles map[string]bool
+	if depth := TOCDepth(book); depth > 1 {
+		manifest.TOCDepth = depth
+		styles = headingStyles(opts.TemplatePath)
+	}
+
+	if manifest.Title == "" {
+		manifest.Title = opts.CollectionName
+	}
+
+	for _, fm := range []struct{ typ, file string }{
+		{"titlepage", "titlepage.pdf"},
+		{"copyright", "copyright.pdf"},
+		{"dedication", "dedication.pdf"},
+	} {
+		path := filepath.Join(opts.BuildDir, fm.file)
+		if fileExists(path) {
+			manifest.FrontMatter = append(manifest.FrontMatter, FrontMatterItem{Type: fm.typ, PDF: path})
+		}
+	}
+
+	manifest.FrontMatter = append(manifest.FrontMatter, FrontMatterItem{Type: "toc", Placeholder: true})
+
+	var currentPart *Part
+	var prologueWorks []Work
+	hasParts := false
+
+	for _, w := range opts.Works {
+		if w.IsSuppressed {
+			continue
+		}
+		pdfPath := filepath.Join(opts.PDFPreviewPath, fmt.Sprintf("%d.pdf", w.WorkID))
+		source := workSource(w.Path, opts.BaseFolderPath)
+		if w.Type == workTypeSection {
+			hasParts = true
+			if currentPart != nil {
+				manifest.Parts = append(manifest.Parts, *currentPart)
+			}
+			currentPart = &Part{
+				ID:    w.WorkID,
+				Title: w.Title,
+				PDF:   pdfPath,
+				Works: []Work{},
+			}
+		} else if currentPart != nil {
+			currentPart.Works = append(currentPart.Works, Work{
+				ID:        w.WorkID,
+				Title:     w.Title,
+				PDF:       pdfPath,
+				Source:    source,
+				FirstLine: opts.FirstLines[w.WorkID],
+				Headings:  workHeadings(source, styles, manifest.TOCDepth),
+			})
+		} else {
+			prologueWorks = append(prologueWorks, Work{
+				ID:        w.WorkID,
+				Title:     w.Title,
+				PDF:       pdfPath,
+				Source:    source,
+				FirstLine: opts.FirstLines[w.WorkID],
+				Headings:  workHeadings(source, styles, manifest.TOCDepth),
+			})
+		}
+	}
+
+	if currentPart != nil {
+		manifest.Parts = append(manifest.Parts, *currentPart)
+	}
+
+	// Always create a part for prologue works - use NoDivider for section-less books
+	if len(prologueWorks) > 0 {
+		prologuePart := Part{
+			ID:        0,
+			Works:     prologueWorks,
+			NoDivider: !hasParts,
+		}
+		if hasParts {
+			prologuePart.Title = "Prologue"
+		}
+		manifest.Parts = append([]Part{prologuePart}, manifest.Parts...)
+	}
+
+	if len(manifest.Parts) == 0 {
+		return nil, fmt.Errorf("collection has no works")
+	}
+
+	for _, bm := range []struct{ typ, file string }{
+		{"afterword", "afterword.pdf"},
+		{"ack", "ack.pdf"},
+		{"about", "about.pdf"},
+	} {
+		path := filepath.Join(opts.BuildDir, bm.file)
+		if fileExists(path) {
+			manifest.BackMatter = append(manifest.BackMatter, BackMatterItem{Type: bm.typ, PDF: path})
+		}
+	}
+
+	return manifest, nil
+}
+
+// workSource resolves a work's stored path against the base folder
+func workSource(path *string, baseFolder string) string {
+	if path == nil || *path == "" {
+		return ""
+	}
+	if filepath.IsAbs(*path) || baseFolder == "" {
+		return *path
+	}
+	return filepath.Join(baseFolder, *path)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
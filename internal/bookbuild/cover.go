@@ -0,0 +1,98 @@
+package bookbuild
+
+import "github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+
+// Cover geometry is in millimetres measured from the top-left corner of
+// the full wrap, bleed included, the way cover designers lay it out.
+const (
+	// CoverSafeMM keeps cover text and important artwork this far inside
+	// the trim
+	CoverSafeMM = 0.125 * mmPerInch
+	// SpineSafeMM keeps spine text this far from each fold
+	SpineSafeMM = 0.0625 * mmPerInch
+
+	barcodeWidthMM  = 2 * mmPerInch
+	barcodeHeightMM = 1.2 * mmPerInch
+	barcodeInsetMM  = 0.25 * mmPerInch
+)
+
+// CoverRect is an area of the cover in mm from the top-left of the full wrap
+type CoverRect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Inset shrinks the rectangle by dx on the left and right and dy on the
+// top and bottom
+func (r CoverRect) Inset(dx, dy float64) CoverRect {
+	return CoverRect{X: r.X + dx, Y: r.Y + dy, Width: r.Width - 2*dx, Height: r.Height - 2*dy}
+}
+
+// CoverGeometry lays out a wraparound cover: back, spine and front panels
+// at trim size, the safe zone inside each, and the box KDP reserves for
+// the ISBN barcode on the back.
+type CoverGeometry struct {
+	TrimSize         string    `json:"trimSize"`
+	PaperType        string    `json:"paperType"`
+	PageCount        int       `json:"pageCount"`
+	WidthMM          float64   `json:"widthMM"`
+	HeightMM         float64   `json:"heightMM"`
+	SpineMM          float64   `json:"spineMM"`
+	BleedMM          float64   `json:"bleedMM"`
+	Back             CoverRect `json:"back"`
+	Spine            CoverRect `json:"spine"`
+	Front            CoverRect `json:"front"`
+	BackSafe         CoverRect `json:"backSafe"`
+	SpineSafe        CoverRect `json:"spineSafe"`
+	FrontSafe        CoverRect `json:"frontSafe"`
+	Barcode          CoverRect `json:"barcode"`
+	SpineTextAllowed bool      `json:"spineTextAllowed"`
+}
+
+// NewCoverGeometry computes the cover for a galley of pageCount pages
+func NewCoverGeometry(trimSize, paperType string, pageCount int) CoverGeometry {
+	if _, ok := TrimSizes[trimSize]; !ok {
+		trimSize = DefaultTrimSize
+	}
+	if _, ok := spinePerPageMM[paperType]; !ok {
+		paperType = PaperPremiumColor
+	}
+	trim := TrimSizeFor(trimSize)
+	spine := SpineWidthMM(pageCount, paperType)
+	width, height := CoverSizeMM(trimSize, paperType, pageCount)
+
+	g := CoverGeometry{
+		TrimSize:         trimSize,
+		PaperType:        paperType,
+		PageCount:        pageCount,
+		WidthMM:          width,
+		HeightMM:         height,
+		SpineMM:          spine,
+		BleedMM:          BleedMM,
+		Back:             CoverRect{X: BleedMM, Y: BleedMM, Width: trim.WidthMM, Height: trim.HeightMM},
+		Spine:            CoverRect{X: BleedMM + trim.WidthMM, Y: BleedMM, Width: spine, Height: trim.HeightMM},
+		Front:            CoverRect{X: BleedMM + trim.WidthMM + spine, Y: BleedMM, Width: trim.WidthMM, Height: trim.HeightMM},
+		SpineTextAllowed: pageCount >= MinSpineTextPages,
+	}
+	g.BackSafe = g.Back.Inset(CoverSafeMM, CoverSafeMM)
+	g.FrontSafe = g.Front.Inset(CoverSafeMM, CoverSafeMM)
+	g.SpineSafe = g.Spine.Inset(SpineSafeMM, CoverSafeMM)
+	if g.SpineSafe.Width < 0 {
+		g.SpineSafe.X, g.SpineSafe.Width = g.Spine.X+g.Spine.Width/2, 0
+	}
+	// Lower right of the back cover, against the spine side
+	g.Barcode = CoverRect{
+		X:      g.Back.X + g.Back.Width - barcodeInsetMM - barcodeWidthMM,
+		Y:      g.Back.Y + g.Back.Height - barcodeInsetMM - barcodeHeightMM,
+		Width:  barcodeWidthMM,
+		Height: barcodeHeightMM,
+	}
+	return g
+}
+
+// CoverGeometryForBook computes the cover for a book's trim size and paper
+func CoverGeometryForBook(book *models.Book, pageCount int) CoverGeometry {
+	return NewCoverGeometry(book.TrimSize, book.PaperType, pageCount)
+}
@@ -0,0 +1,214 @@
+package bookbuild
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/create"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+const ptPerMM = 72 / mmPerInch
+
+// guideShape is one outlined or tinted area of a cover guide
+type guideShape struct {
+	rect   CoverRect
+	stroke string // hex color, empty for none
+	fill   string // hex color, empty for none
+	dashed bool
+}
+
+// guideLabel is text centered on a point, rotated for the spine
+type guideLabel struct {
+	x, y    float64
+	size    float64 // points
+	text    string
+	rotated bool
+}
+
+const (
+	guideBleedColor   = "#e53935"
+	guideTrimColor    = "#000000"
+	guideFoldColor    = "#1e88e5"
+	guideSafeColor    = "#43a047"
+	guideBarcodeColor = "#fdd835"
+)
+
+func (g CoverGeometry) guideShapes() []guideShape {
+	trim := CoverRect{X: g.BleedMM, Y: g.BleedMM, Width: g.WidthMM - 2*g.BleedMM, Height: g.HeightMM - 2*g.BleedMM}
+	return []guideShape{
+		// Bleed: the band between the sheet edge and the trim
+		{rect: CoverRect{Width: g.WidthMM, Height: g.BleedMM}, fill: guideBleedColor},
+		{rect: CoverRect{Y: g.HeightMM - g.BleedMM, Width: g.WidthMM, Height: g.BleedMM}, fill: guideBleedColor},
+		{rect: CoverRect{Y: g.BleedMM, Width: g.BleedMM, Height: trim.Height}, fill: guideBleedColor},
+		{rect: CoverRect{X: g.WidthMM - g.BleedMM, Y: g.BleedMM, Width: g.BleedMM, Height: trim.Height}, fill: guideBleedColor},
+		{rect: trim, stroke: guideTrimColor},
+		{rect: g.Spine, stroke: guideFoldColor, dashed: true},
+		{rect: g.BackSafe, stroke: guideSafeColor, dashed: true},
+		{rect: g.SpineSafe, stroke: guideSafeColor, dashed: true},
+		{rect: g.FrontSafe, stroke: guideSafeColor, dashed: true},
+		{rect: g.Barcode, stroke: guideTrimColor, fill: guideBarcodeColor},
+	}
+}
+
+func (g CoverGeometry) guideLabels() []guideLabel {
+	center := func(r CoverRect) (float64, float64) { return r.X + r.Width/2, r.Y + r.Height/2 }
+	backX, backY := center(g.Back)
+	frontX, frontY := center(g.Front)
+	spineX, spineY := center(g.Spine)
+	barcodeX, barcodeY := center(g.Barcode)
+
+	spine := fmt.Sprintf("SPINE %.2f mm", g.SpineMM)
+	if !g.SpineTextAllowed {
+		spine += fmt.Sprintf(" - no text under %d pages", MinSpineTextPages)
+	}
+	return []guideLabel{
+		{x: g.WidthMM / 2, y: g.BleedMM + CoverSafeMM/2 + 1, size: 7, text: g.summary()},
+		{x: backX, y: backY, size: 18, text: "BACK"},
+		{x: frontX, y: frontY, size: 18, text: "FRONT"},
+		{x: spineX, y: spineY, size: 7, text: spine, rotated: true},
+		{x: barcodeX, y: barcodeY, size: 8, text: "ISBN barcode area"},
+	}
+}
+
+func (g CoverGeometry) summary() string {
+	return fmt.Sprintf("%s trim, %s paper, %d pages: %.2f x %.2f mm with %.2f mm bleed",
+		g.TrimSize, g.PaperType, g.PageCount, g.WidthMM, g.HeightMM, g.BleedMM)
+}
+
+// WriteCoverGuideSVG draws the cover geometry as an SVG sized in mm, for
+// designers to lay over their artwork: bleed in red, trim in black, folds
+// in blue, safe zones in green and the barcode box in yellow.
+func WriteCoverGuideSVG(w io.Writer, g CoverGeometry) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.3fmm" height="%.3fmm" viewBox="0 0 %.3f %.3f">`+"\n",
+		g.WidthMM, g.HeightMM, g.WidthMM, g.HeightMM)
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(g.summary()))
+	for _, s := range g.guideShapes() {
+		fill, opacity := "none", ""
+		if s.fill != "" {
+			fill, opacity = s.fill, ` fill-opacity="0.35"`
+		}
+		stroke := ""
+		if s.stroke != "" {
+			stroke = fmt.Sprintf(` stroke="%s" stroke-width="0.25"`, s.stroke)
+			if s.dashed {
+				stroke += ` stroke-dasharray="2 1"`
+			}
+		}
+		fmt.Fprintf(&b, `<rect x="%.3f" y="%.3f" width="%.3f" height="%.3f" fill="%s"%s%s/>`+"\n",
+			s.rect.X, s.rect.Y, s.rect.Width, s.rect.Height, fill, opacity, stroke)
+	}
+	for _, l := range g.guideLabels() {
+		transform := ""
+		if l.rotated {
+			transform = fmt.Sprintf(` transform="rotate(90 %.3f %.3f)"`, l.x, l.y)
+		}
+		fmt.Fprintf(&b, `<text x="%.3f" y="%.3f" font-family="sans-serif" font-size="%.3f" text-anchor="middle" dominant-baseline="middle" fill="#555555"%s>%s</text>`+"\n",
+			l.x, l.y, l.size/ptPerMM, transform, html.EscapeString(l.text))
+	}
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteCoverGuidePDF draws the same guide as a single-page PDF at the full
+// wrap size, with tints instead of transparency
+func WriteCoverGuidePDF(w io.Writer, g CoverGeometry) error {
+	if err := EnsureFontsInstalled(); err != nil {
+		return fmt.Errorf("failed to install fonts: %w", err)
+	}
+
+	width, height := g.WidthMM*ptPerMM, g.HeightMM*ptPerMM
+	conf := model.NewDefaultConfiguration()
+	ctx, err := pdfcpu.CreateContextWithXRefTable(conf, &types.Dim{Width: width, Height: height})
+	if err != nil {
+		return fmt.Errorf("create pdf: %w", err)
+	}
+
+	box := types.RectForDim(width, height)
+	p := model.NewPage(box, box)
+	fonts := model.FontMap{}
+
+	// PDF space runs up from the bottom-left corner
+	pdfRect := func(r CoverRect) (x, y, w, h float64) {
+		return r.X * ptPerMM, height - (r.Y+r.Height)*ptPerMM, r.Width * ptPerMM, r.Height * ptPerMM
+	}
+	for _, s := range g.guideShapes() {
+		x, y, w, h := pdfRect(s.rect)
+		if s.fill != "" {
+			r, gr, bl := tint(s.fill, 0.35)
+			fmt.Fprintf(p.Buf, "%.3f %.3f %.3f rg %.3f %.3f %.3f %.3f re f\n", r, gr, bl, x, y, w, h)
+		}
+		if s.stroke != "" {
+			r, gr, bl := tint(s.stroke, 1)
+			dash := "[] 0 d"
+			if s.dashed {
+				dash = fmt.Sprintf("[%.2f %.2f] 0 d", 2*ptPerMM, ptPerMM)
+			}
+			fmt.Fprintf(p.Buf, "%.3f %.3f %.3f RG %.3f w %s %.3f %.3f %.3f %.3f re S\n", r, gr, bl, 0.25*ptPerMM, dash, x, y, w, h)
+		}
+	}
+
+	id := p.Fm.EnsureKey(EBGaramondFontName)
+	fonts.EnsureKey(EBGaramondFontName)
+	for _, l := range g.guideLabels() {
+		text := model.PrepBytes(ctx.XRefTable, l.text, EBGaramondFontName, true, false, false)
+		half := font.TextWidth(l.text, EBGaramondFontName, 1000) * l.size / 1000 / 2
+		x, y := l.x*ptPerMM, height-l.y*ptPerMM
+		matrix := fmt.Sprintf("1 0 0 1 %.3f %.3f", x-half, y-l.size/3)
+		if l.rotated {
+			// Spine text reads top to bottom
+			matrix = fmt.Sprintf("0 -1 1 0 %.3f %.3f", x-l.size/3, y+half)
+		}
+		fmt.Fprintf(p.Buf, "BT 0.33 0.33 0.33 rg /%s %.2f Tf %s Tm (%s) Tj ET\n", id, l.size, matrix, text)
+	}
+
+	if _, _, err := create.UpdatePageTree(ctx, []*model.Page{&p}, fonts); err != nil {
+		return fmt.Errorf("build page: %w", err)
+	}
+	if err := api.WriteContext(ctx, w); err != nil {
+		return fmt.Errorf("write pdf: %w", err)
+	}
+	return nil
+}
+
+// WriteCoverGuide writes a PDF or SVG guide depending on the extension
+func WriteCoverGuide(path string, g CoverGeometry) error {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".pdf" && ext != ".svg" {
+		return fmt.Errorf("cover guide must be .pdf or .svg, got %q", filepath.Base(path))
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create cover guide: %w", err)
+	}
+	if ext == ".svg" {
+		err = WriteCoverGuideSVG(f, g)
+	} else {
+		err = WriteCoverGuidePDF(f, g)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// tint blends a hex color toward white, strength 1 being the color itself
+func tint(hex string, strength float64) (r, g, b float64) {
+	var ri, gi, bi int
+	fmt.Sscanf(strings.TrimPrefix(hex, "#"), "%02x%02x%02x", &ri, &gi, &bi)
+	mix := func(c int) float64 { return 1 - strength*(1-float64(c)/255) }
+	return mix(ri), mix(gi), mix(bi)
+}
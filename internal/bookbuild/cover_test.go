@@ -0,0 +1,103 @@
+package bookbuild
+
+import (
+	"bytes"
+	"encoding/xml"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "bookbuild-fonts")
+	if err != nil {
+		panic(err)
+	}
+	// Keep pdfcpu from pointing the font directory back at the user's
+	// config directory
+	api.DisableConfigDir()
+	font.UserFontDir = dir
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+func TestNewCoverGeometry(t *testing.T) {
+	g := NewCoverGeometry("6x9", PaperCream, 200)
+
+	if !near(g.SpineMM, 12.7) {
+		t.Errorf("spine = %v, want 12.7", g.SpineMM)
+	}
+	if !near(g.WidthMM, 152.4*2+12.7+BleedMM*2) || !near(g.HeightMM, 228.6+BleedMM*2) {
+		t.Errorf("full wrap = %v x %v", g.WidthMM, g.HeightMM)
+	}
+	if !near(g.Spine.X, g.Back.X+g.Back.Width) || !near(g.Front.X, g.Spine.X+g.Spine.Width) {
+		t.Errorf("panels should abut: back %+v spine %+v front %+v", g.Back, g.Spine, g.Front)
+	}
+	if !near(g.Front.X+g.Front.Width+BleedMM, g.WidthMM) {
+		t.Errorf("front panel should end at the bleed, got %+v", g.Front)
+	}
+	if !near(g.FrontSafe.X-g.Front.X, CoverSafeMM) || !near(g.SpineSafe.X-g.Spine.X, SpineSafeMM) {
+		t.Errorf("safe zones: front %+v spine %+v", g.FrontSafe, g.SpineSafe)
+	}
+	if !near(g.Back.X+g.Back.Width-(g.Barcode.X+g.Barcode.Width), 6.35) ||
+		!near(g.Back.Y+g.Back.Height-(g.Barcode.Y+g.Barcode.Height), 6.35) {
+		t.Errorf("barcode should sit a quarter inch inside the lower spine-side corner of the back, got %+v", g.Barcode)
+	}
+	if !g.SpineTextAllowed {
+		t.Error("200 pages should allow spine text")
+	}
+
+	thin := NewCoverGeometry("bogus", "bogus", 24)
+	if thin.TrimSize != DefaultTrimSize || thin.PaperType != PaperPremiumColor || thin.SpineTextAllowed {
+		t.Errorf("unexpected fallback geometry %+v", thin)
+	}
+	if thin.SpineSafe.Width < 0 {
+		t.Errorf("spine safe zone should not go negative, got %+v", thin.SpineSafe)
+	}
+}
+
+func TestWriteCoverGuide(t *testing.T) {
+	g := NewCoverGeometry("5x8", PaperWhite, 120)
+
+	var svg bytes.Buffer
+	if err := WriteCoverGuideSVG(&svg, g); err != nil {
+		t.Fatal(err)
+	}
+	if err := xml.Unmarshal(svg.Bytes(), new(struct{})); err != nil {
+		t.Errorf("guide SVG is not well-formed: %v", err)
+	}
+	for _, want := range []string{`width="267.204mm"`, "ISBN barcode area", "SPINE 6.86 mm"} {
+		if !bytes.Contains(svg.Bytes(), []byte(want)) {
+			t.Errorf("guide SVG missing %q", want)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "guide.pdf")
+	if err := WriteCoverGuide(path, g); err != nil {
+		t.Fatal(err)
+	}
+	w, h, err := GetPDFPageSize(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(w-g.WidthMM*ptPerMM) > 0.5 || math.Abs(h-g.HeightMM*ptPerMM) > 0.5 {
+		t.Errorf("guide PDF is %v x %v pt, want the full wrap", w, h)
+	}
+	issues, err := CheckFontsEmbedded(path)
+	if err != nil || len(issues) > 0 {
+		t.Errorf("guide fonts should be embedded: %v %v", issues, err)
+	}
+
+	if err := WriteCoverGuide(filepath.Join(t.TempDir(), "guide.png"), g); err == nil {
+		t.Error("expected an error for an unsupported extension")
+	}
+}
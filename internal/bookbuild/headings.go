@@ -0,0 +1,166 @@
+package bookbuild
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/fts"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxTOCDepth is the deepest heading level the TOC can list
+const MaxTOCDepth = 3
+
+// TOCDepth is how many levels a book's TOC lists: 1 for parts and works,
+// 2 adds Heading 2 sections inside works, 3 adds Heading 3 as well
+func TOCDepth(book *models.Book) int {
+	switch {
+	case book.TOCDepth < 1:
+		return 1
+	case book.TOCDepth > MaxTOCDepth:
+		return MaxTOCDepth
+	}
+	return book.TOCDepth
+}
+
+// headingStyles returns the styles a work's headings may use: the
+// template's, or the built-in heading styles without a template
+func headingStyles(templatePath string) map[string]bool {
+	if templatePath != "" {
+		if styles, err := fts.LoadTemplateStyles(templatePath); err == nil {
+			return styles
+		}
+	}
+	styles := make(map[string]bool)
+	for level := 2; level <= MaxTOCDepth; level++ {
+		styles[fmt.Sprintf("Heading%d", level)] = true
+		styles[fmt.Sprintf("heading %d", level)] = true
+	}
+	return styles
+}
+
+// workHeadings reads the headings from level 2 down to depth out of a
+// work's document. Works that are not Word documents have none.
+func workHeadings(source string, styles map[string]bool, depth int) []Heading {
+	if source == "" || depth < 2 {
+		return nil
+	}
+	result, err := fts.ExtractHeadings(ExpandPath(source), styles)
+	if err != nil {
+		return nil
+	}
+	var headings []Heading
+	for _, h := range result.Headings {
+		if h.Level >= 2 && h.Level <= depth {
+			headings = append(headings, Heading{Level: h.Level, Text: h.Text})
+		}
+	}
+	return headings
+}
+
+// locatedHeading is a heading with the page it falls on, counted from the
+// work's first page
+type locatedHeading struct {
+	Heading
+	Offset int
+}
+
+// LocateHeadings finds the page of each heading in a work's PDF by
+// searching the page text in order, so a heading is looked for only after
+// the one before it. Offsets count from 0 at the work's first page. A
+// heading that cannot be found takes the page of the one before it and
+// its text is returned in missing.
+func LocateHeadings(pdfPath string, headings []Heading) (offsets []int, missing []string, err error) {
+	texts, err := PageTexts(pdfPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	pages := make([]string, len(texts))
+	for i, t := range texts {
+		pages[i] = headingKey(t)
+	}
+
+	page, pos := 0, 0
+	for _, h := range headings {
+		key := headingKey(h.Text)
+		found := false
+		for p := page; key != "" && p < len(pages); p++ {
+			from := 0
+			if p == page {
+				from = pos
+			}
+			if i := strings.Index(pages[p][from:], key); i >= 0 {
+				page, pos = p, from+i+len(key)
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, h.Text)
+		}
+		offsets = append(offsets, page)
+	}
+	return offsets, missing, nil
+}
+
+// headingKey reduces text to lowercase letters and digits so a heading
+// matches the page regardless of spacing, punctuation and ligatures
+func headingKey(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKC.String(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// locateManifestHeadings locates the headings of every work in the
+// manifest, by work ID, with a warning for each one not found
+func locateManifestHeadings(m *Manifest) (map[int64][]locatedHeading, []string) {
+	located := make(map[int64][]locatedHeading)
+	var warnings []string
+	for _, w := range m.AllWorks() {
+		if len(w.Headings) == 0 {
+			continue
+		}
+		offsets, missing, err := LocateHeadings(ExpandPath(w.PDF), w.Headings)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: headings left out of the TOC: %v", w.Title, err))
+			continue
+		}
+		for _, text := range missing {
+			warnings = append(warnings, fmt.Sprintf("%s: heading %q not found in the PDF; its TOC page may be early", w.Title, text))
+		}
+		for i, h := range w.Headings {
+			located[w.ID] = append(located[w.ID], locatedHeading{Heading: h, Offset: offsets[i]})
+		}
+	}
+	return located, warnings
+}
+
+// addHeadingEntries lists each work's headings beneath its TOC entry
+func addHeadingEntries(entries []TOCEntry, located map[int64][]locatedHeading) []TOCEntry {
+	if len(located) == 0 {
+		return entries
+	}
+	out := make([]TOCEntry, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e)
+		if e.WorkID == 0 || e.IsPart || e.IsBackMatter {
+			continue
+		}
+		for _, h := range located[e.WorkID] {
+			out = append(out, TOCEntry{
+				Title:      h.Text,
+				PageNumber: e.PageNumber + h.Offset,
+				IsPrologue: e.IsPrologue,
+				WorkID:     e.WorkID,
+				Level:      h.Level,
+			})
+		}
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package bookbuild
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func TestLocateHeadings(t *testing.T) {
+	config := DefaultOverlayConfig("Test")
+	perPage := int((config.PageHeight - 144) / 14)
+
+	var lines []string
+	page := func(heading string, at int, body string) {
+		for i := 0; i < perPage; i++ {
+			switch {
+			case i == at:
+				lines = append(lines, heading)
+			case i == 0:
+				lines = append(lines, body)
+			default:
+				lines = append(lines, "")
+			}
+		}
+	}
+	// The first page mentions rain before the Spring heading; the Rain
+	// heading is on the second page
+	page("Spring", 2, "It looked like rain.")
+	page("RAIN (first)", 5, "More words.")
+	page("", 1, "The end.")
+
+	path := filepath.Join(t.TempDir(), "work.pdf")
+	if err := createTextPDF(path, strings.Join(lines, "\n"), config); err != nil {
+		t.Fatal(err)
+	}
+
+	headings := []Heading{
+		{Level: 2, Text: "Spring"},
+		{Level: 3, Text: "Rain: First"},
+		{Level: 2, Text: "Nowhere"},
+	}
+	offsets, missing, err := LocateHeadings(path, headings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(offsets) != "[0 1 1]" {
+		t.Errorf("offsets = %v, want [0 1 1]", offsets)
+	}
+	if len(missing) != 1 || missing[0] != "Nowhere" {
+		t.Errorf("missing = %v, want [Nowhere]", missing)
+	}
+}
+
+func TestContentTextToUnicode(t *testing.T) {
+	cmap := []byte(`/CIDInit /ProcSet findresource begin
+begincmap
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+1 beginbfchar
+<004A> <FB01>
+endbfchar
+2 beginbfrange
+<0048> <0049> <0048>
+<0050> <0051> [<0041> <0042>]
+endbfrange
+endcmap`)
+	toUnicode, width := parseToUnicode(cmap, 1)
+	if width != 2 {
+		t.Fatalf("width = %d, want 2", width)
+	}
+	fonts := map[string]*fontDecoder{"F1": {width: width, toUnicode: toUnicode}}
+
+	content := []byte(`BT /F1 12 Tf [<00480049> -300 <004A>] TJ 0 -14 Td <00500051> Tj ET
+BT /F2 10 Tf (caf\351 \(ok\)) Tj ET`)
+	got := contentText(content, fonts)
+	want := "HI ﬁ\nAB\ncafé (ok)\n"
+	if got != want {
+		t.Errorf("contentText() = %q, want %q", got, want)
+	}
+}
+
+func TestAddHeadingEntries(t *testing.T) {
+	entries := []TOCEntry{
+		{Title: "Part One", PageNumber: 1, IsPart: true},
+		{Title: "Essay", PageNumber: 3, WorkID: 7},
+		{Title: "Next", PageNumber: 9, WorkID: 8},
+	}
+	located := map[int64][]locatedHeading{
+		7: {
+			{Heading: Heading{Level: 2, Text: "Beginnings"}, Offset: 0},
+			{Heading: Heading{Level: 3, Text: "A Detail"}, Offset: 2},
+		},
+	}
+
+	got := addHeadingEntries(entries, located)
+	var titles []string
+	for _, e := range got {
+		titles = append(titles, fmt.Sprintf("%s %d %d", e.Title, e.PageNumber, e.Level))
+	}
+	want := "Part One 1 0|Essay 3 0|Beginnings 3 2|A Detail 5 3|Next 9 0"
+	if strings.Join(titles, "|") != want {
+		t.Errorf("entries = %s, want %s", strings.Join(titles, "|"), want)
+	}
+
+	if indent := tocIndent(got[3], 720); indent != 1440 {
+		t.Errorf("level 3 indent = %d, want 1440", indent)
+	}
+	content := buildTOCContent(got, OverlayConfig{})
+	if !strings.Contains(content, "      A Detail ") {
+		t.Errorf("expected level 3 heading indented beneath its work:\n%s", content)
+	}
+}
+
+func TestTOCDepth(t *testing.T) {
+	for depth, want := range map[int]int{0: 1, 1: 1, 2: 2, 3: 3, 9: 3} {
+		if got := TOCDepth(&models.Book{TOCDepth: depth}); got != want {
+			t.Errorf("TOCDepth(%d) = %d, want %d", depth, got, want)
+		}
+	}
+}
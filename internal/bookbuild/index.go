@@ -0,0 +1,201 @@
+package bookbuild
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+// IndexTitle heads the index of titles and first lines
+const IndexTitle = "Index of Titles and First Lines"
+
+// IndexEntry is one line of the index: a work's title or its first line,
+// with the body page the work starts on
+type IndexEntry struct {
+	Text       string
+	PageNumber int
+	IsTitle    bool
+}
+
+// IncludesIndex reports whether a book's galley ends with an index of
+// titles and first lines. Unless set, poetry books have one.
+func IncludesIndex(book *models.Book) bool {
+	if book.FirstLineIndex != nil {
+		return *book.FirstLineIndex
+	}
+	return book.BookType == "poetry"
+}
+
+// GenerateIndex lists every work in the analysis under its title and its
+// first line, alphabetically. Page numbers match the TOC's. A first line
+// that only repeats the title is left out.
+func GenerateIndex(analysis *AnalysisResult, m *Manifest) []IndexEntry {
+	firstLines := make(map[int64]string)
+	for _, w := range m.AllWorks() {
+		firstLines[w.ID] = w.FirstLine
+	}
+
+	var entries []IndexEntry
+	for _, item := range analysis.Items {
+		if item.Type != ContentTypeWork {
+			continue
+		}
+		page := item.StartPage - analysis.FrontMatterPages
+		title := strings.ReplaceAll(item.Title, " | ", " ")
+		entries = append(entries, IndexEntry{Text: title, PageNumber: page, IsTitle: true})
+
+		line := strings.TrimSpace(firstLines[item.WorkID])
+		if line != "" && indexSortKey(line) != indexSortKey(title) {
+			entries = append(entries, IndexEntry{Text: line, PageNumber: page})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		ki, kj := indexSortKey(entries[i].Text), indexSortKey(entries[j].Text)
+		if ki != kj {
+			return ki < kj
+		}
+		return entries[i].PageNumber < entries[j].PageNumber
+	})
+	return entries
+}
+
+// indexSortKey orders entries by their letters and digits, ignoring case,
+// leading quotes and punctuation
+func indexSortKey(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		case unicode.IsSpace(r):
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// indexLetter is the heading letter an entry files under
+func indexLetter(s string) string {
+	for _, r := range indexSortKey(s) {
+		if unicode.IsLetter(r) {
+			return string(unicode.ToUpper(r))
+		}
+		return "#"
+	}
+	return ""
+}
+
+// createIndexPDFWithTemplate renders the index through the template when
+// there is one, otherwise with the raw PDF generator
+func createIndexPDFWithTemplate(opts PipelineOptions, entries []IndexEntry, outputPath, templatePath string, config OverlayConfig) error {
+	if templatePath != "" {
+		if _, err := os.Stat(templatePath); err == nil {
+			return CreateIndexPDFViaDocx(opts.Ctx, opts.ConvertToPDF, entries, templatePath, outputPath)
+		}
+	}
+	return CreateIndexPDF(entries, outputPath, config)
+}
+
+// CreateIndexPDF writes the index as a plain text PDF
+func CreateIndexPDF(entries []IndexEntry, outputPath string, config OverlayConfig) error {
+	lines := []string{strings.ToUpper(IndexTitle), ""}
+	letter := ""
+	for _, e := range entries {
+		if l := indexLetter(e.Text); l != letter {
+			if letter != "" {
+				lines = append(lines, "")
+			}
+			letter = l
+		}
+		lines = append(lines, formatTOCLine(e.Text, e.PageNumber, false))
+	}
+	return createTextPDF(outputPath, strings.Join(lines, "\n"), config)
+}
+
+// CreateIndexDocx writes the index as a DOTM styled by the book template.
+// Titles are set in italics; entries are grouped by initial letter.
+func CreateIndexDocx(entries []IndexEntry, templatePath, outputPath string) error {
+	tmpl, err := readTemplateBody(templatePath)
+	if err != nil {
+		return fmt.Errorf("build document xml: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(tmpl.open)
+
+	buf.WriteString(`<w:p>`)
+	buf.WriteString(`<w:pPr><w:pStyle w:val="Title"/></w:pPr>`)
+	buf.WriteString(`<w:r><w:t>` + escapeXMLString(IndexTitle) + `</w:t></w:r>`)
+	buf.WriteString(`</w:p>`)
+
+	letter := ""
+	for _, e := range entries {
+		if l := indexLetter(e.Text); l != letter {
+			buf.WriteString(`<w:p/>`)
+			letter = l
+		}
+		buf.WriteString(buildIndexEntryParagraph(e))
+	}
+
+	buf.WriteString(tmpl.sectPr)
+	buf.WriteString(tmpl.close)
+
+	return writeTemplateDocx(templatePath, outputPath, buf.Bytes())
+}
+
+func buildIndexEntryParagraph(e IndexEntry) string {
+	var buf bytes.Buffer
+
+	buf.WriteString(`<w:p>`)
+	buf.WriteString(`<w:pPr><w:pStyle w:val="Normal"/>`)
+	// Hang long first lines under their opening words
+	buf.WriteString(`<w:ind w:left="360" w:hanging="360"/>`)
+	buf.WriteString(`<w:tabs><w:tab w:val="right" w:leader="dot" w:pos="9360"/></w:tabs>`)
+	buf.WriteString(`</w:pPr>`)
+	buf.WriteString(`<w:r>`)
+	if e.IsTitle {
+		buf.WriteString(`<w:rPr><w:i/></w:rPr>`)
+	}
+	buf.WriteString(`<w:t xml:space="preserve">`)
+	buf.WriteString(escapeXMLString(e.Text))
+	buf.WriteString(`</w:t></w:r>`)
+	buf.WriteString(`<w:r><w:tab/></w:r>`)
+	buf.WriteString(`<w:r><w:t>`)
+	buf.WriteString(fmt.Sprintf("%d", e.PageNumber))
+	buf.WriteString(`</w:t></w:r>`)
+	buf.WriteString(`</w:p>`)
+
+	return buf.String()
+}
+
+// CreateIndexPDFViaDocx renders the index to a DOTM beside outputPath and
+// converts it to PDF with convert
+func CreateIndexPDFViaDocx(ctx context.Context, convert ConvertFunc, entries []IndexEntry, templatePath, outputPath string) error {
+	tempDotm := filepath.Join(filepath.Dir(outputPath), "index_temp.dotm")
+	defer os.Remove(tempDotm)
+
+	if err := CreateIndexDocx(entries, templatePath, tempDotm); err != nil {
+		return fmt.Errorf("create index dotm: %w", err)
+	}
+
+	if convert == nil {
+		convert = func(_ context.Context, src, dst string) error { return ConvertDocxToPDF(src, dst) }
+	}
+	if err := convert(ctx, tempDotm, outputPath); err != nil {
+		return fmt.Errorf("convert to pdf: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package bookbuild
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func TestGenerateIndex(t *testing.T) {
+	m := &Manifest{Works: []Work{
+		{ID: 1, Title: "Winter Light", FirstLine: "The snow came early that year"},
+		{ID: 2, Title: "Crows", FirstLine: "“Black on the wire,”"},
+		{ID: 3, Title: "After | the Flood", FirstLine: "After the flood."},
+	}}
+	analysis := &AnalysisResult{
+		FrontMatterPages: 4,
+		Items: []ContentItem{
+			{Type: ContentTypeTOC, StartPage: 3},
+			{Type: ContentTypeWork, Title: "Winter Light", WorkID: 1, StartPage: 5},
+			{Type: ContentTypeWork, Title: "Crows", WorkID: 2, StartPage: 7},
+			{Type: ContentTypeBlank, StartPage: 8},
+			{Type: ContentTypeWork, Title: "After | the Flood", WorkID: 3, StartPage: 9},
+		},
+	}
+
+	var got []string
+	for _, e := range GenerateIndex(analysis, m) {
+		kind := "line"
+		if e.IsTitle {
+			kind = "title"
+		}
+		got = append(got, fmt.Sprintf("%s %s %d", kind, e.Text, e.PageNumber))
+	}
+	want := []string{
+		"title After the Flood 5",
+		"line “Black on the wire,” 3",
+		"title Crows 3",
+		"line The snow came early that year 1",
+		"title Winter Light 1",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("GenerateIndex() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestIncludesIndex(t *testing.T) {
+	off := false
+	tests := []struct {
+		book models.Book
+		want bool
+	}{
+		{models.Book{BookType: "poetry"}, true},
+		{models.Book{BookType: "prose"}, false},
+		{models.Book{BookType: "poetry", FirstLineIndex: &off}, false},
+	}
+	for _, tt := range tests {
+		if got := IncludesIndex(&tt.book); got != tt.want {
+			t.Errorf("IncludesIndex(%s) = %v, want %v", tt.book.BookType, got, tt.want)
+		}
+	}
+}
+
+func TestCreateIndexDocx(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "template.dotm")
+	f, err := os.Create(templatePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create(documentXMLPath)
+	_, _ = w.Write([]byte(`<w:document><w:body><w:p><w:r><w:t>Sample</w:t></w:r></w:p><w:sectPr><w:pgSz w:w="8640"/></w:sectPr></w:body></w:document>`))
+	w, _ = zw.Create("word/styles.xml")
+	_, _ = w.Write([]byte(`<w:styles/>`))
+	zw.Close()
+	f.Close()
+
+	out := filepath.Join(dir, "index.dotm")
+	entries := []IndexEntry{
+		{Text: "Crows", PageNumber: 3, IsTitle: true},
+		{Text: "The snow & the rain", PageNumber: 1},
+	}
+	if err := CreateIndexDocx(entries, templatePath, out); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.OpenReader(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var doc string
+	var names []string
+	for _, file := range zr.File {
+		names = append(names, file.Name)
+		if file.Name == documentXMLPath {
+			rc, _ := file.Open()
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			doc = string(data)
+		}
+	}
+	if len(names) != 2 {
+		t.Errorf("expected the template's parts to be copied, got %v", names)
+	}
+	for _, want := range []string{IndexTitle, `<w:i/></w:rPr><w:t xml:space="preserve">Crows`, "The snow &amp; the rain", `<w:pgSz w:w="8640"/>`} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("document.xml is missing %q", want)
+		}
+	}
+	if strings.Contains(doc, "Sample") {
+		t.Error("the template body should be replaced")
+	}
+}
+
+func TestBuildWithIndex(t *testing.T) {
+	dir := t.TempDir()
+	var works []Work
+	for i, title := range []string{"Winter Light", "Crows", "Rain"} {
+		path := filepath.Join(dir, title+".pdf")
+		if err := CreateTestPortraitPDF(path); err != nil {
+			t.Fatal(err)
+		}
+		works = append(works, Work{ID: int64(i + 1), Title: title, PDF: path})
+	}
+	works[0].FirstLine = "The snow came early"
+
+	build := func(index bool) *PipelineResult {
+		t.Helper()
+		result, err := BuildWithParts(PipelineOptions{
+			Ctx: context.Background(),
+			Manifest: &Manifest{
+				Title:      "Weather",
+				Typography: DefaultTypography(),
+				Parts:      []Part{{ID: 0, NoDivider: true, Works: works}},
+				Index:      index,
+			},
+			CacheDir:   filepath.Join(dir, "cache"),
+			OutputPath: filepath.Join(dir, "out.pdf"),
+			ObjectsDir: filepath.Join(dir, "objects"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	plain := build(false)
+	indexed := build(true)
+	// Three one-page works end on page 3, so the index starts on page 5
+	if indexed.TotalPages != plain.TotalPages+2 {
+		t.Errorf("expected a blank and a one-page index, got %d pages after %d", indexed.TotalPages, plain.TotalPages)
+	}
+	found := false
+	for _, w := range indexed.Warnings {
+		if strings.Contains(w, "2 works are indexed by title only") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a warning about missing first lines, got %v", indexed.Warnings)
+	}
+}
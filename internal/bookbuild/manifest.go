@@ -30,6 +30,21 @@ type Work struct {
 	ID    int64  `json:"id"`
 	Title string `json:"title"`
 	PDF   string `json:"pdf"`
+	// Source is the work's document, for exports that reflow the text
+	// rather than reuse the PDF
+	Source string `json:"source,omitempty"`
+	// FirstLine is the work's opening line, for the index
+	FirstLine string `json:"firstLine,omitempty"`
+	// Headings are the section headings inside the work that the TOC
+	// lists beneath it
+	Headings []Heading `json:"headings,omitempty"`
+}
+
+// Heading is a section heading inside a work. Level follows the heading
+// style: 2 for Heading 2, 3 for Heading 3.
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
 }
 
 type Part struct {
@@ -55,6 +70,8 @@ type Manifest struct {
 	Parts               []Part            `json:"parts,omitempty"`
 	Works               []Work            `json:"works,omitempty"`
 	BackMatter          []BackMatterItem  `json:"backMatter"`
+	Index               bool              `json:"index,omitempty"`    // end with an index of titles and first lines
+	TOCDepth            int               `json:"tocDepth,omitempty"` // 1 lists works; 2 and 3 add their headings
 }
 
 func DefaultTypography() Typography {
@@ -0,0 +1,248 @@
+package bookbuild
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// PacketWork is one work going into a submission packet
+type PacketWork struct {
+	Title string
+	Path  string // a Word document
+}
+
+// PacketDocxOptions describes a submission packet document
+type PacketDocxOptions struct {
+	TemplatePath string
+	OutputPath   string
+	Header       []string // contact lines for the first page; none for blind copies
+	Works        []PacketWork
+}
+
+// externalParts are the pieces of a work's body that point outside it, to
+// media, comments, notes or link targets the template does not have. They
+// are dropped from the packet; the text around them is kept.
+var externalParts = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"images", regexp.MustCompile(`(?s)<w:drawing\b.*?</w:drawing>|<w:pict\b.*?</w:pict>|<w:object\b.*?</w:object>`)},
+	{"comments", regexp.MustCompile(`<w:comment(?:RangeStart|RangeEnd|Reference)\b[^>]*/>`)},
+	{"footnotes", regexp.MustCompile(`<w:(?:footnote|endnote)Reference\b[^>]*/>`)},
+	{"links", regexp.MustCompile(`<w:hyperlink\b[^>]*>|</w:hyperlink>`)},
+}
+
+var (
+	reSectPr    = regexp.MustCompile(`(?s)<w:sectPr\b.*?</w:sectPr>|<w:sectPr\b[^>]*/>`)
+	reParagraph = regexp.MustCompile(`(?s)<w:p\b.*?</w:p>`)
+	reText      = regexp.MustCompile(`<w:t(?:\s[^>]*)?>([^<]*)</w:t>`)
+	reNamespace = regexp.MustCompile(`\sxmlns:(\w+)="[^"]*"`)
+)
+
+const packetPageBreak = `<w:p><w:r><w:br w:type="page"/></w:r></w:p>`
+
+// CreatePacketDocx merges the works' documents into one file styled by the
+// template: the contact header, then each work on its own page under its
+// title. A title is added only when the work doesn't already open with it.
+// Images, comments, footnotes and links can't be carried over; the
+// warnings say which works lost them.
+func CreatePacketDocx(opts PacketDocxOptions) ([]string, error) {
+	if len(opts.Works) == 0 {
+		return nil, fmt.Errorf("a packet needs at least one work")
+	}
+	tmpl, err := readTemplateBody(opts.TemplatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	var buf bytes.Buffer
+	open := tmpl.open
+	for i, w := range opts.Works {
+		docXML, err := readDocumentXML(w.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", w.Title, err)
+		}
+		doc, err := splitDocumentBody(string(docXML))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", w.Title, err)
+		}
+		open = mergeNamespaces(open, doc.open)
+
+		body, lost := stripExternalParts(doc.content)
+		if len(lost) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s: %s left out", w.Title, strings.Join(lost, ", ")))
+		}
+
+		if i == 0 {
+			for _, line := range opts.Header {
+				buf.WriteString(`<w:p><w:pPr><w:pStyle w:val="Normal"/><w:spacing w:after="0"/></w:pPr>`)
+				buf.WriteString(`<w:r><w:t xml:space="preserve">` + escapeXMLString(line) + `</w:t></w:r></w:p>`)
+			}
+			if len(opts.Header) > 0 {
+				buf.WriteString(`<w:p/>`)
+			}
+		} else {
+			buf.WriteString(packetPageBreak)
+		}
+
+		title := strings.ReplaceAll(w.Title, " | ", " ")
+		if !opensWith(body, title) {
+			buf.WriteString(`<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr>`)
+			buf.WriteString(`<w:r><w:t xml:space="preserve">` + escapeXMLString(title) + `</w:t></w:r></w:p>`)
+		}
+		buf.WriteString(body)
+	}
+
+	docXML := open + buf.String() + tmpl.sectPr + tmpl.close
+	if err := writeTemplateDocx(opts.TemplatePath, opts.OutputPath, []byte(docXML)); err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
+
+// stripExternalParts removes what can't travel with a work's body and
+// names the kinds of things removed. Section breaks go too: the packet
+// takes its page setup from the template.
+func stripExternalParts(body string) (string, []string) {
+	var lost []string
+	for _, part := range externalParts {
+		if part.re.MatchString(body) {
+			body = part.re.ReplaceAllString(body, "")
+			lost = append(lost, part.name)
+		}
+	}
+	return reSectPr.ReplaceAllString(body, ""), lost
+}
+
+// mergeNamespaces adds to the template's root element the namespace
+// prefixes a work's root declares and the template lacks, so markup such
+// as w14 paragraph IDs stays well formed
+func mergeNamespaces(open, workOpen string) string {
+	root := strings.Index(open, "<w:document")
+	if root == -1 {
+		return open
+	}
+	end := strings.Index(open[root:], ">")
+	if end == -1 {
+		return open
+	}
+	end += root
+	if open[end-1] == '/' {
+		return open
+	}
+
+	tag := open[root:end]
+	var add strings.Builder
+	for _, m := range reNamespace.FindAllStringSubmatch(workOpen, -1) {
+		if !strings.Contains(tag, "xmlns:"+m[1]+"=") && !strings.Contains(add.String(), "xmlns:"+m[1]+"=") {
+			add.WriteString(m[0])
+		}
+	}
+	return open[:end] + add.String() + open[end:]
+}
+
+// opensWith reports whether the first paragraph with text reads title,
+// ignoring case, spacing and punctuation
+func opensWith(body, title string) bool {
+	for _, p := range reParagraph.FindAllString(body, -1) {
+		var text strings.Builder
+		for _, m := range reText.FindAllStringSubmatch(p, -1) {
+			text.WriteString(m[1])
+		}
+		if strings.TrimSpace(text.String()) == "" {
+			continue
+		}
+		return titleKey(html.UnescapeString(text.String())) == titleKey(title)
+	}
+	return false
+}
+
+func titleKey(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// DefaultPacketFileName names a packet when the organization has no rule
+const DefaultPacketFileName = "{author} - {titles}"
+
+// PacketName holds the values a packet file-naming rule can use
+type PacketName struct {
+	Author  string
+	Journal string
+	Titles  []string
+	Date    time.Time
+}
+
+const maxPacketFileName = 120
+
+var (
+	reDanglingDash  = regexp.MustCompile(`\s*-\s*(?:-\s*)+`)
+	reDanglingUnder = regexp.MustCompile(`_{2,}`)
+	reSpaces        = regexp.MustCompile(`\s+`)
+	filenameUnsafe  = strings.NewReplacer("/", "-", "\\", "-", ":", "-", "*", "", "?", "", "\"", "", "<", "", ">", "", "|", "")
+)
+
+// PacketFileName applies a journal's naming rule, such as
+// "{author}_{title}" or "{journal} {date} {titles}", and returns a file
+// name without extension. {title} is the first work's title, {titles} all
+// of them, {count} the number of works and {date} the day as YYYY-MM-DD.
+// Author is left empty for blind copies; separators left dangling by an
+// empty value are dropped.
+func PacketFileName(rule string, n PacketName) string {
+	if strings.TrimSpace(rule) == "" {
+		rule = DefaultPacketFileName
+	}
+	titles := make([]string, len(n.Titles))
+	for i, t := range n.Titles {
+		titles[i] = strings.ReplaceAll(t, " | ", " ")
+	}
+	first := ""
+	if len(titles) > 0 {
+		first = titles[0]
+	}
+
+	name := strings.NewReplacer(
+		"{author}", n.Author,
+		"{journal}", n.Journal,
+		"{title}", first,
+		"{titles}", strings.Join(titles, ", "),
+		"{count}", fmt.Sprint(len(titles)),
+		"{date}", n.Date.Format("2006-01-02"),
+	).Replace(rule)
+
+	name = filenameUnsafe.Replace(name)
+	name = reSpaces.ReplaceAllString(name, " ")
+	name = reDanglingDash.ReplaceAllString(name, " - ")
+	name = reDanglingUnder.ReplaceAllString(name, "_")
+	name = strings.Trim(name, " -_,.")
+	if r := []rune(name); len(r) > maxPacketFileName {
+		name = strings.TrimRight(string(r[:maxPacketFileName]), " -_,.")
+	}
+	if name == "" {
+		return "Packet"
+	}
+	return name
+}
+
+// UniquePacketPath joins dir and a packet file name, adding " (2)", " (3)"
+// and so on while a .docx or .pdf of that name exists, so a new packet
+// never replaces an earlier one. The result has no extension.
+func UniquePacketPath(dir, name string) string {
+	base := filepath.Join(dir, name)
+	for n := 2; fileExists(base+".docx") || fileExists(base+".pdf"); n++ {
+		base = filepath.Join(dir, fmt.Sprintf("%s (%d)", name, n))
+	}
+	return base
+}
@@ -0,0 +1,126 @@
+package bookbuild
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestDocx(t *testing.T, path, docXML string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create(documentXMLPath)
+	_, _ = w.Write([]byte(docXML))
+	zw.Close()
+	f.Close()
+}
+
+func TestCreatePacketDocx(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "template.dotm")
+	writeTestDocx(t, templatePath, `<w:document xmlns:w="w"><w:body><w:p><w:r><w:t>Sample</w:t></w:r></w:p><w:sectPr><w:pgSz w:w="8640"/></w:sectPr></w:body></w:document>`)
+
+	crows := filepath.Join(dir, "crows.docx")
+	writeTestDocx(t, crows, `<w:document xmlns:w="w" xmlns:w14="w14"><w:body><w:p w14:paraId="1"><w:r><w:t>CROWS</w:t></w:r></w:p><w:p><w:r><w:t>Black on the snow</w:t></w:r><w:r><w:drawing><wp:inline/></w:drawing></w:r></w:p><w:sectPr><w:pgSz w:w="12240"/></w:sectPr></w:body></w:document>`)
+	rain := filepath.Join(dir, "rain.docx")
+	writeTestDocx(t, rain, `<w:document xmlns:w="w"><w:body><w:p><w:r><w:t>It falls &amp; falls</w:t></w:r></w:p></w:body></w:document>`)
+
+	out := filepath.Join(dir, "packet.docx")
+	warnings, err := CreatePacketDocx(PacketDocxOptions{
+		TemplatePath: templatePath,
+		OutputPath:   out,
+		Header:       []string{"Jo Poet", "jo@example.com"},
+		Works:        []PacketWork{{Title: "Crows", Path: crows}, {Title: "Rain", Path: rain}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0] != "Crows: images left out" {
+		t.Errorf("warnings = %v", warnings)
+	}
+
+	zr, err := zip.OpenReader(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	rc, _ := zr.File[0].Open()
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	doc := string(data)
+
+	for _, want := range []string{`xmlns:w14="w14"`, "Jo Poet", "jo@example.com", "Black on the snow", packetPageBreak, `<w:pStyle w:val="Title"/></w:pPr><w:r><w:t xml:space="preserve">Rain`, `<w:pgSz w:w="8640"/>`} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("document.xml is missing %q", want)
+		}
+	}
+	for _, absent := range []string{"Sample", "<w:drawing", `w:w="12240"`, `preserve">Crows`} {
+		if strings.Contains(doc, absent) {
+			t.Errorf("document.xml should not contain %q", absent)
+		}
+	}
+	if strings.Index(doc, "Jo Poet") > strings.Index(doc, "CROWS") {
+		t.Error("the contact header belongs before the first work")
+	}
+}
+
+func TestPacketFileName(t *testing.T) {
+	n := PacketName{
+		Author:  "Jo Poet",
+		Journal: "The Review",
+		Titles:  []string{"Crows", "Rain | Snow", "What?"},
+		Date:    time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC),
+	}
+	blind := n
+	blind.Author = ""
+	tests := []struct {
+		rule string
+		n    PacketName
+		want string
+	}{
+		{"", n, "Jo Poet - Crows, Rain Snow, What"},
+		{"", blind, "Crows, Rain Snow, What"},
+		{"{author}_{title}", n, "Jo Poet_Crows"},
+		{"{author}_{title}", blind, "Crows"},
+		{"{journal} {date} ({count} poems)", n, "The Review 2026-03-05 (3 poems)"},
+		{"{title} - {author} - {date}", blind, "Crows - 2026-03-05"},
+		{"{author}", blind, "Packet"},
+	}
+	for _, tt := range tests {
+		if got := PacketFileName(tt.rule, tt.n); got != tt.want {
+			t.Errorf("PacketFileName(%q) = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestUniquePacketPath(t *testing.T) {
+	dir := t.TempDir()
+	name := "Jo Poet - Crows"
+
+	first := UniquePacketPath(dir, name)
+	if first != filepath.Join(dir, name) {
+		t.Errorf("a new name should be used as is, got %q", first)
+	}
+	if err := os.WriteFile(first+".docx", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	second := UniquePacketPath(dir, name)
+	if second != filepath.Join(dir, name+" (2)") {
+		t.Errorf("want a suffix after an earlier packet, got %q", second)
+	}
+	// A PDF left from an earlier packet counts too
+	if err := os.WriteFile(second+".pdf", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := UniquePacketPath(dir, name); got != filepath.Join(dir, name+" (3)") {
+		t.Errorf("want the next free suffix, got %q", got)
+	}
+}
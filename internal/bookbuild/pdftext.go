@@ -0,0 +1,227 @@
+package bookbuild
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/pdfcontent"
+)
+
+// PageTexts returns the text shown on each page of a PDF in content
+// stream order. It is meant for finding words on a page, not for
+// reproducing layout: text runs are separated by newlines and spacing
+// inside a line is approximate.
+func PageTexts(path string) ([]string, error) {
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF %s: %w", path, err)
+	}
+
+	texts := make([]string, 0, ctx.PageCount)
+	for pageNr := 1; pageNr <= ctx.PageCount; pageNr++ {
+		d, _, inherited, err := ctx.PageDict(pageNr, true)
+		if err != nil {
+			return nil, fmt.Errorf("read page %d: %w", pageNr, err)
+		}
+		content, err := ctx.PageContent(d, pageNr)
+		if err == model.ErrNoContent {
+			texts = append(texts, "")
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read page %d content: %w", pageNr, err)
+		}
+		var fonts map[string]*fontDecoder
+		if inherited != nil {
+			fonts = pageFonts(ctx, inherited.Resources)
+		}
+		texts = append(texts, contentText(content, fonts))
+	}
+	return texts, nil
+}
+
+// fontDecoder maps a font's character codes to text
+type fontDecoder struct {
+	width     int // bytes per character code
+	toUnicode map[uint32]string
+}
+
+func (f *fontDecoder) decode(b []byte) string {
+	if f == nil {
+		return latin1(b)
+	}
+	if f.toUnicode == nil {
+		if f.width == 1 {
+			return latin1(b)
+		}
+		// Multi-byte codes without a ToUnicode map are glyph IDs
+		return ""
+	}
+	var sb strings.Builder
+	for i := 0; i+f.width <= len(b); i += f.width {
+		var code uint32
+		for _, c := range b[i : i+f.width] {
+			code = code<<8 | uint32(c)
+		}
+		sb.WriteString(f.toUnicode[code])
+	}
+	return sb.String()
+}
+
+func latin1(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// pageFonts reads the decoders for the fonts in a page's resources.
+// Fonts that cannot be read are left out and fall back to Latin-1.
+func pageFonts(ctx *model.Context, resources types.Dict) map[string]*fontDecoder {
+	fonts := make(map[string]*fontDecoder)
+	if resources == nil {
+		return fonts
+	}
+	obj, found := resources.Find("Font")
+	if !found {
+		return fonts
+	}
+	fontDict, err := ctx.DereferenceDict(obj)
+	if err != nil || fontDict == nil {
+		return fonts
+	}
+
+	for name, o := range fontDict {
+		fd, err := ctx.DereferenceDict(o)
+		if err != nil || fd == nil {
+			continue
+		}
+		dec := &fontDecoder{width: 1}
+		if subtype := fd.NameEntry("Subtype"); subtype != nil && *subtype == "Type0" {
+			dec.width = 2
+		}
+		if tu, found := fd.Find("ToUnicode"); found {
+			sd, _, err := ctx.DereferenceStreamDict(tu)
+			if err == nil && sd != nil && sd.Decode() == nil {
+				dec.toUnicode, dec.width = parseToUnicode(sd.Content, dec.width)
+			}
+		}
+		fonts[name] = dec
+	}
+	return fonts
+}
+
+// parseToUnicode reads the bfchar and bfrange mappings of a ToUnicode
+// CMap. The code width comes from its codespace range when it has one.
+func parseToUnicode(cmap []byte, width int) (map[uint32]string, int) {
+	m := make(map[uint32]string)
+
+	code := func(b []byte) uint32 {
+		var c uint32
+		for _, x := range b {
+			c = c<<8 | uint32(x)
+		}
+		return c
+	}
+
+	pdfcontent.Scan(cmap, func(op string, operands []pdfcontent.Token) {
+		switch op {
+		case "endcodespacerange":
+			if len(operands) > 0 && len(operands[0].Str) > 0 {
+				width = len(operands[0].Str)
+			}
+
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				m[code(operands[i].Str)] = utf16BE(operands[i+1].Str)
+			}
+
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, hi := code(operands[i].Str), code(operands[i+1].Str)
+				if operands[i+2].Kind == pdfcontent.Array {
+					for j, el := range operands[i+2].Array {
+						m[lo+uint32(j)] = utf16BE(el.Str)
+					}
+					continue
+				}
+				dst := []rune(utf16BE(operands[i+2].Str))
+				if len(dst) == 0 || hi < lo || hi-lo > 0xFFFF {
+					continue
+				}
+				for c := lo; c <= hi; c++ {
+					r := append([]rune{}, dst...)
+					r[len(r)-1] += rune(c - lo)
+					m[c] = string(r)
+				}
+			}
+		}
+	})
+	return m, width
+}
+
+func utf16BE(b []byte) string {
+	if len(b)%2 == 1 {
+		return latin1(b)
+	}
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
+
+// contentText runs the text operators of a content stream. Tf selects the
+// font, Tj, TJ, ' and " show text, and line moves become newlines.
+func contentText(content []byte, fonts map[string]*fontDecoder) string {
+	var sb strings.Builder
+	var font *fontDecoder
+
+	pdfcontent.Scan(content, func(op string, operands []pdfcontent.Token) {
+		last := func(kind pdfcontent.Kind) *pdfcontent.Token {
+			for i := len(operands) - 1; i >= 0; i-- {
+				if operands[i].Kind == kind {
+					return &operands[i]
+				}
+			}
+			return nil
+		}
+		show := func() {
+			if s := last(pdfcontent.String); s != nil {
+				sb.WriteString(font.decode(s.Str))
+			}
+		}
+
+		switch op {
+		case "Tf":
+			if name := last(pdfcontent.Name); name != nil {
+				font = fonts[name.Text]
+			}
+		case "Tj":
+			show()
+		case "'", "\"":
+			sb.WriteByte('\n')
+			show()
+		case "TJ":
+			if array := last(pdfcontent.Array); array != nil {
+				for _, el := range array.Array {
+					if el.Kind == pdfcontent.String {
+						sb.WriteString(font.decode(el.Str))
+					} else if el.Num < -200 {
+						// A wide negative adjustment is a word space
+						sb.WriteByte(' ')
+					}
+				}
+			}
+		case "Td", "TD", "T*", "Tm", "ET":
+			sb.WriteByte('\n')
+		}
+	})
+	return sb.String()
+}
@@ -9,8 +9,12 @@ import (
 	"runtime"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"golang.org/x/sync/errgroup"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/backup"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
 )
 
 // ProgressFunc is the signature for progress callback functions
@@ -41,10 +45,10 @@ func reanalyzeWithTOC(m *Manifest, tocPages int) (*AnalysisResult, error) {
 
 // createTOCPDFWithTemplate uses the template-based DOCX approach if a template
 // exists, otherwise falls back to the raw PDF generator.
-func createTOCPDFWithTemplate(entries []TOCEntry, outputPath, templatePath string, config OverlayConfig) error {
+func createTOCPDFWithTemplate(opts PipelineOptions, entries []TOCEntry, outputPath, templatePath string, config OverlayConfig) error {
 	if templatePath != "" {
 		if _, err := os.Stat(templatePath); err == nil {
-			return CreateTOCPDFViaDocx(entries, templatePath, outputPath)
+			return CreateTOCPDFViaDocx(opts.Ctx, opts.ConvertToPDF, entries, templatePath, outputPath)
 		}
 	}
 	return CreateTOCPDF(entries, outputPath, config)
@@ -58,6 +62,14 @@ type PipelineOptions struct {
 	OutputPath   string
 	RebuildAll   bool
 	OnProgress   ProgressFunc
+	ConvertToPDF ConvertFunc
+	// Book is recorded with the build as its settings; ObjectsDir is where
+	// the inputs are archived and defaults to GetBuildObjectsDir
+	Book       *models.Book
+	ObjectsDir string
+	// Finish, when set, rewrites the written PDF in place before it is
+	// hashed and recorded. If it fails, the output is removed.
+	Finish func(outputPath string) error
 }
 
 type PipelineResult struct {
@@ -69,6 +81,8 @@ type PipelineResult struct {
 	PartsCached int
 	Duration    string
 	Warnings    []string
+	// Record describes the build for the database; it is not saved here
+	Record *models.BookBuild `json:"-"`
 }
 
 func GetCacheDir(collectionID int64) string {
@@ -80,6 +94,7 @@ func GetCacheDir(collectionID int64) string {
 }
 
 func BuildWithParts(opts PipelineOptions) (*PipelineResult, error) {
+	startTime := time.Now()
 	result := &PipelineResult{
 		Warnings: []string{},
 	}
@@ -103,6 +118,9 @@ func BuildWithParts(opts PipelineOptions) (*PipelineResult, error) {
 	if err := os.MkdirAll(opts.CacheDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create cache directory: %w", err)
 	}
+	if opts.ObjectsDir == "" {
+		opts.ObjectsDir = GetBuildObjectsDir()
+	}
 
 	// Create blank.pdf once at the start, matching the template page size
 	blankPagePath := filepath.Join(opts.CacheDir, "blank.pdf")
@@ -128,6 +146,13 @@ func BuildWithParts(opts PipelineOptions) (*PipelineResult, error) {
 
 	progress("Analyzing", 1, 5, "Analyzing manifest...")
 
+	// Archive the inputs before reading them so the record matches what
+	// was built even if a file changes mid-build
+	inputs, err := ArchiveInputs(backup.NewObjectStore(opts.ObjectsDir), opts.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive build inputs: %w", err)
+	}
+
 	analysis, err := AnalyzeManifest(opts.Manifest)
 	if err != nil {
 		return nil, fmt.Errorf("analysis failed: %w", err)
@@ -148,7 +173,23 @@ func BuildWithParts(opts PipelineOptions) (*PipelineResult, error) {
 
 	progress("TOC", 2, 5, "Generating table of contents...")
 
-	tocEntries, err := GenerateTOC(analysis, config)
+	// Headings are found in the work PDFs once; only their works' start
+	// pages move if the TOC length changes
+	var headings map[int64][]locatedHeading
+	if opts.Manifest.TOCDepth > 1 {
+		var warnings []string
+		headings, warnings = locateManifestHeadings(opts.Manifest)
+		result.Warnings = append(result.Warnings, warnings...)
+	}
+	generateTOC := func() ([]TOCEntry, error) {
+		entries, err := GenerateTOC(analysis, config)
+		if err != nil {
+			return nil, err
+		}
+		return addHeadingEntries(entries, headings), nil
+	}
+
+	tocEntries, err := generateTOC()
 	if err != nil {
 		return nil, fmt.Errorf("TOC generation failed: %w", err)
 	}
@@ -157,7 +198,7 @@ func BuildWithParts(opts PipelineOptions) (*PipelineResult, error) {
 	var tocPDFPath string
 	if analysis.TOCIndex >= 0 && len(tocEntries) > 0 {
 		tocPDFPath = filepath.Join(opts.CacheDir, "toc.pdf")
-		if err := createTOCPDFWithTemplate(tocEntries, tocPDFPath, opts.Manifest.TemplatePath, config); err != nil {
+		if err := createTOCPDFWithTemplate(opts, tocEntries, tocPDFPath, opts.Manifest.TemplatePath, config); err != nil {
 			return nil, fmt.Errorf("TOC PDF creation failed: %w", err)
 		}
 
@@ -172,11 +213,11 @@ func BuildWithParts(opts PipelineOptions) (*PipelineResult, error) {
 				return nil, fmt.Errorf("TOC re-analysis failed: %w", err)
 			}
 			// Regenerate TOC entries and PDF with correct page numbers
-			tocEntries, err = GenerateTOC(analysis, config)
+			tocEntries, err = generateTOC()
 			if err != nil {
 				return nil, fmt.Errorf("TOC regeneration failed: %w", err)
 			}
-			if err := createTOCPDFWithTemplate(tocEntries, tocPDFPath, opts.Manifest.TemplatePath, config); err != nil {
+			if err := createTOCPDFWithTemplate(opts, tocEntries, tocPDFPath, opts.Manifest.TemplatePath, config); err != nil {
 				return nil, fmt.Errorf("TOC PDF recreation failed: %w", err)
 			}
 		}
@@ -243,14 +284,21 @@ frontMatterLoop:
 		}
 	}
 
+	// Source hashes were taken when the inputs were archived
+	sourceHashes := make(map[string]string, len(inputs))
+	for _, in := range inputs {
+		sourceHashes[ExpandPath(in.Path)] = in.SHA256
+	}
+
 	type workResult struct {
 		partIdx int
 		itemIdx int
 		pdfPath string
+		key     string
 	}
 
 	results := make([]workResult, len(workItems))
-	var worksCompleted atomic.Int32
+	var worksCompleted, worksCached atomic.Int32
 	var progressMu sync.Mutex
 
 	safeProgress := func(stage string, current, total int, message string) {
@@ -271,23 +319,45 @@ frontMatterLoop:
 				return gCtx.Err()
 			}
 
-			safeProgress("Parts", 3, 5, fmt.Sprintf("Processing: %s", w.item.Title))
-
-			outputPath, err := PrepareAndOverlayWork(WorkOverlayOptions{
-				CacheDir:            opts.CacheDir,
-				Item:                w.item,
-				Config:              config,
-				SuppressPageNumbers: config.SuppressPageNumbers,
-				StartBodyNum:        w.bodyNum,
-			})
+			sourceSHA := sourceHashes[ExpandPath(w.item.PDF)]
+			if sourceSHA == "" {
+				sum, err := hashFile(ExpandPath(w.item.PDF))
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", w.item.Title, err)
+				}
+				sourceSHA = sum
+			}
+			key, err := WorkCacheKey(sourceSHA, w.item, config, w.bodyNum)
 			if err != nil {
-				return fmt.Errorf("failed to process %s: %w", w.item.Title, err)
+				return err
+			}
+			cachedPath := keyedWorkPath(opts.CacheDir, key)
+
+			if !opts.RebuildAll && useCached(cachedPath) {
+				worksCached.Add(1)
+			} else {
+				safeProgress("Parts", 3, 5, fmt.Sprintf("Processing: %s", w.item.Title))
+
+				outputPath, err := PrepareAndOverlayWork(WorkOverlayOptions{
+					CacheDir:            opts.CacheDir,
+					Item:                w.item,
+					Config:              config,
+					SuppressPageNumbers: config.SuppressPageNumbers,
+					StartBodyNum:        w.bodyNum,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to process %s: %w", w.item.Title, err)
+				}
+				if err := storeCached(outputPath, cachedPath); err != nil {
+					return fmt.Errorf("failed to cache %s: %w", w.item.Title, err)
+				}
 			}
 
 			results[wi] = workResult{
 				partIdx: w.partIdx,
 				itemIdx: w.itemIdx,
-				pdfPath: outputPath,
+				pdfPath: cachedPath,
+				key:     key,
 			}
 
 			completed := worksCompleted.Add(1)
@@ -301,27 +371,31 @@ frontMatterLoop:
 		return nil, err
 	}
 
-	resultsByPart := make(map[int]map[int]string)
+	resultsByPart := make(map[int]map[int]workResult)
 	for _, r := range results {
 		if resultsByPart[r.partIdx] == nil {
-			resultsByPart[r.partIdx] = make(map[int]string)
+			resultsByPart[r.partIdx] = make(map[int]workResult)
 		}
-		resultsByPart[r.partIdx][r.itemIdx] = r.pdfPath
+		resultsByPart[r.partIdx][r.itemIdx] = r
 	}
 
+	blank := blankMember(width, height)
 	partPDFs := make([]string, len(analysis.PartAnalyses))
 	for partIdx := range analysis.PartAnalyses {
 		pa := analysis.PartAnalyses[partIdx]
 		partItems := analysis.GetPartItems(partIdx)
 
-		var partFiles []string
+		var partFiles, members, used []string
 		for itemIdx, item := range partItems {
 			if item.Type == ContentTypeBlank {
 				partFiles = append(partFiles, blankPagePath)
+				members = append(members, blank)
 				continue
 			}
-			if pdfPath, ok := resultsByPart[partIdx][itemIdx]; ok {
-				partFiles = append(partFiles, pdfPath)
+			if r, ok := resultsByPart[partIdx][itemIdx]; ok {
+				partFiles = append(partFiles, r.pdfPath)
+				members = append(members, r.key)
+				used = append(used, r.pdfPath)
 			}
 		}
 
@@ -329,23 +403,39 @@ frontMatterLoop:
 			continue
 		}
 
-		if len(partFiles) == 1 {
-			cachePath := PartCachePath(opts.CacheDir, pa.PartID)
-			if err := copyFile(partFiles[0], cachePath); err != nil {
-				return nil, fmt.Errorf("failed to cache part %d: %w", partIdx, err)
-			}
-			partPDFs[partIdx] = cachePath
+		keyedPath := keyedPartPath(opts.CacheDir, PartCacheKey(members))
+		if !opts.RebuildAll && useCached(keyedPath) {
+			result.PartsCached++
 		} else {
-			cachePath := PartCachePath(opts.CacheDir, pa.PartID)
-			if err := mergeFilesRaw(partFiles, cachePath); err != nil {
+			mergedPath := PartMergedPath(opts.CacheDir, pa.PartID)
+			if len(partFiles) == 1 {
+				if err := copyFile(partFiles[0], mergedPath); err != nil {
+					return nil, fmt.Errorf("failed to cache part %d: %w", partIdx, err)
+				}
+			} else if err := mergeFilesRaw(partFiles, mergedPath); err != nil {
 				return nil, fmt.Errorf("failed to merge part %d: %w", partIdx, err)
 			}
-			partPDFs[partIdx] = cachePath
+			if err := storeCached(mergedPath, keyedPath); err != nil {
+				return nil, fmt.Errorf("failed to cache part %d: %w", partIdx, err)
+			}
+			_ = os.Remove(mergedPath)
+			result.PartsBuilt++
 		}
+
+		cachePath := PartCachePath(opts.CacheDir, pa.PartID)
+		if err := copyFile(keyedPath, cachePath); err != nil {
+			return nil, fmt.Errorf("failed to cache part %d: %w", partIdx, err)
+		}
+		if err := writePartKeys(opts.CacheDir, pa.PartID, append([]string{keyedPath}, used...)); err != nil {
+			return nil, fmt.Errorf("failed to record part %d cache keys: %w", partIdx, err)
+		}
+		partPDFs[partIdx] = cachePath
 	}
 
-	result.PartsBuilt = int(worksCompleted.Load())
-	result.PartsCached = 0
+	if n := worksCached.Load(); n > 0 {
+		progress("Parts", 3, 5, fmt.Sprintf("Reused %d of %d works from the cache", n, len(workItems)))
+	}
+	pruneKeyedCache(opts.CacheDir, cacheMaxAge)
 
 	var backMatterPDFs []string
 	for _, bm := range opts.Manifest.BackMatter {
@@ -354,6 +444,40 @@ frontMatterLoop:
 		}
 	}
 
+	if opts.Manifest.Index {
+		progress("Index", 4, 5, "Generating index of titles and first lines...")
+		indexPDFPath := filepath.Join(opts.CacheDir, "index.pdf")
+		entries := GenerateIndex(analysis, opts.Manifest)
+		if err := createIndexPDFWithTemplate(opts, entries, indexPDFPath, opts.Manifest.TemplatePath, config); err != nil {
+			return nil, fmt.Errorf("index PDF creation failed: %w", err)
+		}
+
+		// Start the index on a recto
+		pages := analysis.FrontMatterPages + analysis.BodyPages
+		for _, p := range backMatterPDFs {
+			n, err := GetPageCount(p)
+			if err != nil {
+				return nil, err
+			}
+			pages += n
+		}
+		if pages%2 == 1 {
+			backMatterPDFs = append(backMatterPDFs, blankPagePath)
+		}
+		backMatterPDFs = append(backMatterPDFs, indexPDFPath)
+
+		missing := 0
+		for _, w := range opts.Manifest.AllWorks() {
+			if w.FirstLine == "" {
+				missing++
+			}
+		}
+		if missing > 0 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf(
+				"%d works are indexed by title only; build the search index to include their first lines", missing))
+		}
+	}
+
 	progress("Stitching", 4, 5, "Stitching final book...")
 
 	allPDFs := make([]string, 0, len(frontMatterPDFs)+len(partPDFs)+len(backMatterPDFs))
@@ -394,6 +518,12 @@ frontMatterLoop:
 	if err := copyFile(stitchedPath, opts.OutputPath); err != nil {
 		return nil, fmt.Errorf("failed to write output: %w", err)
 	}
+	if opts.Finish != nil {
+		if err := opts.Finish(opts.OutputPath); err != nil {
+			os.Remove(opts.OutputPath)
+			return nil, fmt.Errorf("failed to finish output: %w", err)
+		}
+	}
 
 	totalPages, err := GetPageCount(opts.OutputPath)
 	if err != nil {
@@ -403,6 +533,12 @@ frontMatterLoop:
 	result.WorkCount = len(opts.Manifest.AllWorks())
 	result.Success = true
 	result.OutputPath = opts.OutputPath
+	result.Duration = time.Since(startTime).Round(time.Millisecond).String()
+
+	result.Record, err = NewBuildRecord(opts.CollectionID, opts.Manifest, opts.Book, inputs, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record build: %w", err)
+	}
 
 	return result, nil
 }
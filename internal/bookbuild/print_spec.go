@@ -0,0 +1,72 @@
+package bookbuild
+
+// Print specifications for KDP paperbacks, shared by the cover tools and
+// the preflight checks. Sizes are in millimetres.
+const (
+	mmPerInch = 25.4
+
+	// BleedMM is the bleed on every outside edge, 0.125 inches for all trim sizes
+	BleedMM = 3.17
+	// MinSpineTextPages is the page count below which KDP refuses spine text
+	MinSpineTextPages = 80
+)
+
+// Paper types, as stored in models.Book.PaperType
+const (
+	PaperPremiumColor  = "premium-color"
+	PaperStandardColor = "standard-color"
+	PaperWhite         = "bw-white"
+	PaperCream         = "bw-cream"
+)
+
+// spinePerPageMM is the thickness of one page for each paper type
+var spinePerPageMM = map[string]float64{
+	PaperPremiumColor:  0.0596,
+	PaperStandardColor: 0.0572,
+	PaperWhite:         0.0572,
+	PaperCream:         0.0635,
+}
+
+// TrimSize holds the dimensions for a specific trim size
+type TrimSize struct {
+	WidthMM  float64 // trim width in mm
+	HeightMM float64 // trim height in mm
+}
+
+// TrimSizes maps trim size codes to their dimensions
+var TrimSizes = map[string]TrimSize{
+	"5x8":     {WidthMM: 127.0, HeightMM: 203.2}, // 5" x 8"
+	"5.5x8.5": {WidthMM: 139.7, HeightMM: 215.9}, // 5.5" x 8.5"
+	"6x9":     {WidthMM: 152.4, HeightMM: 228.6}, // 6" x 9"
+	"7x10":    {WidthMM: 177.8, HeightMM: 254.0}, // 7" x 10"
+	"8.5x11":  {WidthMM: 215.9, HeightMM: 279.4}, // 8.5" x 11"
+}
+
+// DefaultTrimSize is used when a book's trim size is unset or unknown
+const DefaultTrimSize = "6x9"
+
+// TrimSizeFor returns the dimensions for a trim size, defaulting to 6x9
+func TrimSizeFor(code string) TrimSize {
+	if dims, ok := TrimSizes[code]; ok {
+		return dims
+	}
+	return TrimSizes[DefaultTrimSize]
+}
+
+// SpineWidthMM returns the spine width for a page count on a paper type,
+// defaulting to premium color
+func SpineWidthMM(pageCount int, paperType string) float64 {
+	perPage, ok := spinePerPageMM[paperType]
+	if !ok {
+		perPage = spinePerPageMM[PaperPremiumColor]
+	}
+	return float64(pageCount) * perPage
+}
+
+// CoverSizeMM returns the full wraparound cover dimensions: back, spine
+// and front, with bleed on every side
+func CoverSizeMM(trimSize, paperType string, pageCount int) (widthMM, heightMM float64) {
+	trim := TrimSizeFor(trimSize)
+	widthMM = trim.WidthMM*2 + SpineWidthMM(pageCount, paperType) + BleedMM*2
+	return widthMM, trim.HeightMM + BleedMM*2
+}
@@ -0,0 +1,439 @@
+package bookbuild
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/backup"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+// Every build copies its input files into a shared object store keyed by
+// content hash, so a build record can be reproduced after the works, the
+// template or the front matter have moved on. Identical files across builds
+// are stored once.
+
+// GetBuildObjectsDir returns the object store shared by all book builds
+func GetBuildObjectsDir() string {
+	return filepath.Join(filepath.Dir(GetCacheDir(0)), "objects")
+}
+
+// MaxBuildsPerCollection is how many build records a collection keeps. Older
+// records are deleted along with the archived inputs only they refer to.
+const MaxBuildsPerCollection = 20
+
+// eachInput calls fn with every file a build reads, in book order, and a
+// pointer to its path so callers can rewrite it
+func (m *Manifest) eachInput(fn func(key, label string, path *string)) {
+	if m.TemplatePath != "" {
+		fn("template", "Template", &m.TemplatePath)
+	}
+	for i := range m.FrontMatter {
+		if m.FrontMatter[i].PDF != "" {
+			fn("front:"+m.FrontMatter[i].Type, "Front matter: "+m.FrontMatter[i].Type, &m.FrontMatter[i].PDF)
+		}
+	}
+	work := func(w *Work) {
+		id := fmt.Sprint(w.ID)
+		if w.PDF != "" {
+			fn("work:"+id, w.Title, &w.PDF)
+		}
+		if w.Source != "" {
+			fn("source:"+id, w.Title+" (document)", &w.Source)
+		}
+	}
+	for i := range m.Parts {
+		part := &m.Parts[i]
+		if part.PDF != "" {
+			fn(fmt.Sprintf("part:%d", part.ID), "Part: "+part.Title, &part.PDF)
+		}
+		for j := range part.Works {
+			work(&part.Works[j])
+		}
+	}
+	for i := range m.Works {
+		work(&m.Works[i])
+	}
+	for i := range m.BackMatter {
+		if m.BackMatter[i].PDF != "" {
+			fn("back:"+m.BackMatter[i].Type, "Back matter: "+m.BackMatter[i].Type, &m.BackMatter[i].PDF)
+		}
+	}
+}
+
+// ArchiveInputs copies every input of a manifest into the store and
+// returns them with their hashes. A work's document is optional, so a
+// missing one is recorded without a hash rather than failing the build.
+func ArchiveInputs(store *backup.ObjectStore, m *Manifest) ([]models.BuildInput, error) {
+	inputs := []models.BuildInput{}
+	var err error
+	m.eachInput(func(key, label string, path *string) {
+		if err != nil {
+			return
+		}
+		in := models.BuildInput{Key: key, Label: label, Path: *path}
+		expanded := ExpandPath(*path)
+		stat, statErr := os.Stat(expanded)
+		if statErr != nil {
+			if !strings.HasPrefix(key, "source:") {
+				err = fmt.Errorf("build input %s: %w", label, statErr)
+			}
+			inputs = append(inputs, in)
+			return
+		}
+		in.Size = stat.Size()
+		in.SHA256, _, err = store.Put(expanded)
+		if err != nil {
+			err = fmt.Errorf("archive %s: %w", label, err)
+		}
+		inputs = append(inputs, in)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inputs, nil
+}
+
+// NewBuildRecord describes a finished build for the database. book may be
+// nil when the manifest was not made from a book.
+func NewBuildRecord(collID int64, m *Manifest, book *models.Book, inputs []models.BuildInput, result *PipelineResult) (*models.BookBuild, error) {
+	manifest, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("encode manifest: %w", err)
+	}
+	settings := ""
+	if book != nil {
+		data, err := json.Marshal(book)
+		if err != nil {
+			return nil, fmt.Errorf("encode book settings: %w", err)
+		}
+		settings = string(data)
+	}
+	sum, err := hashFile(result.OutputPath)
+	if err != nil {
+		return nil, fmt.Errorf("hash output: %w", err)
+	}
+	pages, err := PageFingerprint(result.OutputPath)
+	if err != nil {
+		return nil, err
+	}
+	return &models.BookBuild{
+		CollID:       collID,
+		Manifest:     string(manifest),
+		Settings:     settings,
+		Inputs:       inputs,
+		OutputPath:   result.OutputPath,
+		OutputSHA256: sum,
+		PagesSHA256:  pages,
+		TotalPages:   result.TotalPages,
+		Duration:     result.Duration,
+	}, nil
+}
+
+// RestoreBuildManifest writes the inputs of a recorded build from the store
+// into dir and returns its manifest pointing at those copies
+func RestoreBuildManifest(store *backup.ObjectStore, b *models.BookBuild, dir string) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal([]byte(b.Manifest), &m); err != nil {
+		return nil, fmt.Errorf("decode manifest of build %d: %w", b.BuildID, err)
+	}
+
+	recorded := make(map[string]models.BuildInput, len(b.Inputs))
+	for _, in := range b.Inputs {
+		recorded[in.Key] = in
+	}
+
+	var err error
+	m.eachInput(func(key, label string, path *string) {
+		if err != nil {
+			return
+		}
+		in, ok := recorded[key]
+		if !ok || in.SHA256 == "" {
+			// The PDF build never reads a work's document, so an
+			// unrecorded one keeps its original path
+			if strings.HasPrefix(key, "source:") {
+				return
+			}
+			err = fmt.Errorf("build %d did not record %s", b.BuildID, label)
+			return
+		}
+		restored := filepath.Join(dir, in.SHA256+filepath.Ext(in.Path))
+		if _, statErr := os.Stat(restored); statErr != nil {
+			if err = store.WriteTo(in.SHA256, restored); err != nil {
+				err = fmt.Errorf("restore %s: %w", label, err)
+				return
+			}
+		}
+		*path = restored
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// BuildInputChange is an input that differs between two builds
+type BuildInputChange struct {
+	Key    string `json:"key"`
+	Label  string `json:"label"`
+	Change string `json:"change"` // added, removed, changed
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// BuildSettingChange is a book or manifest setting that differs between
+// two builds
+type BuildSettingChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// BuildDiff compares the inputs of two builds
+type BuildDiff struct {
+	From         int64                `json:"from"`
+	To           int64                `json:"to"`
+	Inputs       []BuildInputChange   `json:"inputs"`
+	Settings     []BuildSettingChange `json:"settings"`
+	OrderChanged bool                 `json:"orderChanged"`
+	SameOutput   bool                 `json:"sameOutput"` // same printed pages
+}
+
+// Empty reports whether the two builds read the same inputs
+func (d *BuildDiff) Empty() bool {
+	return len(d.Inputs) == 0 && len(d.Settings) == 0 && !d.OrderChanged
+}
+
+// DiffBuilds compares what two builds read: which input files were added,
+// removed or changed, which settings differ, and whether the works were
+// reordered. Paths are ignored so a moved but unchanged file is not a
+// change.
+func DiffBuilds(from, to *models.BookBuild) (*BuildDiff, error) {
+	diff := &BuildDiff{
+		From:       from.BuildID,
+		To:         to.BuildID,
+		Inputs:     []BuildInputChange{},
+		Settings:   []BuildSettingChange{},
+		SameOutput: from.PagesSHA256 != "" && from.PagesSHA256 == to.PagesSHA256,
+	}
+
+	old := make(map[string]models.BuildInput, len(from.Inputs))
+	for _, in := range from.Inputs {
+		old[in.Key] = in
+	}
+	seen := map[string]bool{}
+	var shared []string
+	for _, in := range to.Inputs {
+		seen[in.Key] = true
+		prev, ok := old[in.Key]
+		switch {
+		case !ok:
+			diff.Inputs = append(diff.Inputs, BuildInputChange{Key: in.Key, Label: in.Label, Change: "added", New: in.SHA256})
+		case prev.SHA256 != in.SHA256:
+			diff.Inputs = append(diff.Inputs, BuildInputChange{Key: in.Key, Label: in.Label, Change: "changed", Old: prev.SHA256, New: in.SHA256})
+			shared = append(shared, in.Key)
+		default:
+			shared = append(shared, in.Key)
+		}
+	}
+	var sharedBefore []string
+	for _, in := range from.Inputs {
+		if seen[in.Key] {
+			sharedBefore = append(sharedBefore, in.Key)
+			continue
+		}
+		diff.Inputs = append(diff.Inputs, BuildInputChange{Key: in.Key, Label: in.Label, Change: "removed", Old: in.SHA256})
+	}
+	diff.OrderChanged = strings.Join(shared, "\n") != strings.Join(sharedBefore, "\n")
+
+	for _, pair := range []struct {
+		prefix   string
+		from, to string
+	}{
+		{"book.", from.Settings, to.Settings},
+		{"manifest.", from.Manifest, to.Manifest},
+	} {
+		changes, err := diffSettings(pair.prefix, pair.from, pair.to)
+		if err != nil {
+			return nil, err
+		}
+		diff.Settings = append(diff.Settings, changes...)
+	}
+	return diff, nil
+}
+
+// ignoredSettings are covered by the input hashes, or change without
+// changing what is printed
+var ignoredSettings = map[string]bool{
+	"manifest.outputPath": true, "manifest.templatePath": true,
+	"manifest.frontMatter": true, "manifest.parts": true, "manifest.works": true, "manifest.backMatter": true,
+	"book.exportPath": true, "book.createdAt": true, "book.modifiedAt": true,
+	"book.kdpUploaded": true, "book.kdpPreviewed": true, "book.kdpProofOrdered": true, "book.kdpPublished": true,
+	"book.amazonUrl": true, "book.lastPublished": true, "book.status": true,
+}
+
+// diffSettings compares two JSON objects field by field, one level of
+// nesting deep
+func diffSettings(prefix, from, to string) ([]BuildSettingChange, error) {
+	flatten := func(doc string) (map[string]string, error) {
+		fields := map[string]string{}
+		if doc == "" {
+			return fields, nil
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(doc), &obj); err != nil {
+			return nil, fmt.Errorf("decode build settings: %w", err)
+		}
+		for k, v := range obj {
+			if ignoredSettings[prefix+k] {
+				continue
+			}
+			var nested map[string]json.RawMessage
+			if json.Unmarshal(v, &nested) == nil {
+				for nk, nv := range nested {
+					fields[k+"."+nk] = string(nv)
+				}
+				continue
+			}
+			fields[k] = string(v)
+		}
+		return fields, nil
+	}
+
+	a, err := flatten(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := flatten(to)
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		if a[k] != b[k] {
+			sorted = append(sorted, k)
+		}
+	}
+	sort.Strings(sorted)
+
+	changes := []BuildSettingChange{}
+	for _, k := range sorted {
+		changes = append(changes, BuildSettingChange{Field: prefix + k, Old: a[k], New: b[k]})
+	}
+	return changes, nil
+}
+
+// PageFingerprint hashes what a PDF prints: each page's size, rotation and
+// content streams. pdfcpu stamps every file it writes with a new ID and date, so two
+// builds from the same inputs never match byte for byte, but they do match
+// here.
+func PageFingerprint(path string) (string, error) {
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read PDF %s: %w", path, err)
+	}
+	h := sha256.New()
+	for pageNr := 1; pageNr <= ctx.PageCount; pageNr++ {
+		d, _, inherited, err := ctx.PageDict(pageNr, true)
+		if err != nil {
+			return "", fmt.Errorf("read page %d: %w", pageNr, err)
+		}
+		var box types.Rectangle
+		if inherited != nil && inherited.MediaBox != nil {
+			box = *inherited.MediaBox
+		}
+		content, err := ctx.PageContent(d, pageNr)
+		if err != nil && err != model.ErrNoContent {
+			return "", fmt.Errorf("read page %d content: %w", pageNr, err)
+		}
+		rotate := 0
+		if inherited != nil {
+			rotate = inherited.Rotate
+		}
+		fmt.Fprintf(h, "page %d %.2fx%.2f %d %d\n", pageNr, box.Width(), box.Height(), rotate, len(content))
+		h.Write(content)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Rebuild reproduces a recorded build from its archived inputs. opts
+// supplies the context, output path, progress and converter; the manifest,
+// book settings and a scratch cache come from the record. The new build's
+// record points back at the one it reproduces.
+func Rebuild(b *models.BookBuild, opts PipelineOptions) (*PipelineResult, error) {
+	if opts.ObjectsDir == "" {
+		opts.ObjectsDir = GetBuildObjectsDir()
+	}
+	scratch, err := os.MkdirTemp("", fmt.Sprintf("works-rebuild-%d-", b.BuildID))
+	if err != nil {
+		return nil, fmt.Errorf("create rebuild directory: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	inputsDir := filepath.Join(scratch, "inputs")
+	if err := os.MkdirAll(inputsDir, 0755); err != nil {
+		return nil, err
+	}
+	m, err := RestoreBuildManifest(backup.NewObjectStore(opts.ObjectsDir), b, inputsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var book *models.Book
+	if b.Settings != "" {
+		book = &models.Book{}
+		if err := json.Unmarshal([]byte(b.Settings), book); err != nil {
+			return nil, fmt.Errorf("decode settings of build %d: %w", b.BuildID, err)
+		}
+	}
+
+	if opts.OutputPath == "" {
+		opts.OutputPath = b.OutputPath
+	}
+	m.OutputPath = opts.OutputPath
+	opts.Manifest = m
+	opts.Book = book
+	opts.CollectionID = b.CollID
+	opts.CacheDir = filepath.Join(scratch, "cache")
+	opts.RebuildAll = true
+
+	result, err := BuildWithParts(opts)
+	if err != nil {
+		return nil, err
+	}
+	result.Record.RebuiltFrom = &b.BuildID
+	return result, nil
+}
@@ -0,0 +1,162 @@
+package bookbuild
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func TestBuildRecordAndRebuild(t *testing.T) {
+	dir := t.TempDir()
+	divider := filepath.Join(dir, "part.pdf")
+	first := filepath.Join(dir, "first.pdf")
+	second := filepath.Join(dir, "second.pdf")
+	for _, path := range []string{divider, first, second} {
+		if err := CreateTestPortraitPDF(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifest := func() *Manifest {
+		return &Manifest{
+			Title:      "Weather",
+			Typography: DefaultTypography(),
+			Parts: []Part{{ID: 1, Title: "Rain", PDF: divider, Works: []Work{
+				{ID: 10, Title: "Drizzle", PDF: first},
+				{ID: 11, Title: "Downpour", PDF: second, Source: filepath.Join(dir, "missing.docx")},
+			}}},
+		}
+	}
+	build := func(m *Manifest, book *models.Book, out string) *PipelineResult {
+		t.Helper()
+		result, err := BuildWithParts(PipelineOptions{
+			Ctx:          context.Background(),
+			Manifest:     m,
+			CollectionID: 3,
+			CacheDir:     filepath.Join(dir, "cache"),
+			OutputPath:   out,
+			Book:         book,
+			ObjectsDir:   filepath.Join(dir, "objects"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	book := &models.Book{Title: "Weather", TrimSize: "6x9"}
+	one := build(manifest(), book, filepath.Join(dir, "one.pdf"))
+	rec := one.Record
+	if rec == nil || rec.CollID != 3 || rec.OutputSHA256 == "" || rec.TotalPages != one.TotalPages {
+		t.Fatalf("unexpected record %+v", rec)
+	}
+	keys := []string{}
+	for _, in := range rec.Inputs {
+		keys = append(keys, in.Key)
+	}
+	if got, want := strings.Join(keys, " "), "part:1 work:10 work:11 source:11"; got != want {
+		t.Fatalf("inputs = %s, want %s", got, want)
+	}
+	if rec.Inputs[3].SHA256 != "" || rec.Inputs[1].SHA256 == "" {
+		t.Errorf("a missing document should be recorded without a hash: %+v", rec.Inputs)
+	}
+	rec.BuildID = 1
+
+	// Change a work and a setting, then build again
+	if err := CreateTestMixedPDF(second, []bool{false, true}); err != nil {
+		t.Fatal(err)
+	}
+	book.TrimSize = "5x8"
+	two := build(manifest(), book, filepath.Join(dir, "two.pdf")).Record
+	two.BuildID = 2
+
+	diff, err := DiffBuilds(rec, two)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Inputs) != 1 || diff.Inputs[0].Key != "work:11" || diff.Inputs[0].Change != "changed" {
+		t.Errorf("input changes = %+v", diff.Inputs)
+	}
+	if len(diff.Settings) != 1 || diff.Settings[0].Field != "book.trimSize" {
+		t.Errorf("setting changes = %+v", diff.Settings)
+	}
+	if diff.OrderChanged || diff.SameOutput {
+		t.Errorf("diff = %+v", diff)
+	}
+
+	// The first build is reproduced from its archived inputs, not the
+	// changed files on disk
+	again, err := Rebuild(rec, PipelineOptions{
+		Ctx:        context.Background(),
+		OutputPath: filepath.Join(dir, "again.pdf"),
+		ObjectsDir: filepath.Join(dir, "objects"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Record.RebuiltFrom == nil || *again.Record.RebuiltFrom != 1 {
+		t.Errorf("rebuild should point at build 1, got %+v", again.Record.RebuiltFrom)
+	}
+	d, err := DiffBuilds(rec, again.Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Empty() {
+		t.Errorf("rebuild read different inputs: %+v", d)
+	}
+	if !d.SameOutput {
+		t.Error("rebuild should print the same pages as the original")
+	}
+}
+
+func TestBuildFinishRunsBeforeRecord(t *testing.T) {
+	dir := t.TempDir()
+	work := filepath.Join(dir, "work.pdf")
+	if err := CreateTestPortraitPDF(work); err != nil {
+		t.Fatal(err)
+	}
+	build := func(out string, finish func(string) error) (*PipelineResult, error) {
+		return BuildWithParts(PipelineOptions{
+			Ctx:          context.Background(),
+			Manifest:     &Manifest{Title: "Fog", Typography: DefaultTypography(), Parts: []Part{{ID: 1, Title: "Haze", PDF: work, Works: []Work{{ID: 2, Title: "Mist", PDF: work}}}}},
+			CollectionID: 4,
+			CacheDir:     filepath.Join(dir, "cache"),
+			OutputPath:   out,
+			ObjectsDir:   filepath.Join(dir, "objects"),
+			Finish:       finish,
+		})
+	}
+
+	// The recorded hash is that of the rewritten file
+	out := filepath.Join(dir, "blind.pdf")
+	result, err := build(out, func(path string) error {
+		return CreateTestMixedPDF(path, []bool{true, false})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := hashFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Record.OutputSHA256 != sum {
+		t.Errorf("recorded %s, the output hashes to %s", result.Record.OutputSHA256, sum)
+	}
+	if result.TotalPages != 2 {
+		t.Errorf("pages = %d, want the rewritten file's 2", result.TotalPages)
+	}
+
+	// A failed finish leaves no output behind
+	failed := filepath.Join(dir, "failed.pdf")
+	if _, err := build(failed, func(string) error { return errors.New("scrub failed") }); err == nil {
+		t.Fatal("expected the build to fail")
+	}
+	if _, err := os.Stat(failed); !os.IsNotExist(err) {
+		t.Errorf("output should be removed, stat err = %v", err)
+	}
+}
@@ -12,6 +12,8 @@ type TOCEntry struct {
 	IsPart       bool
 	IsBackMatter bool
 	IsPrologue   bool // Works appearing before the first Section/Part
+	WorkID       int64
+	Level        int // 0 for parts, works and back matter; 2 or 3 for headings inside a work
 }
 
 func GenerateTOC(analysis *AnalysisResult, _ OverlayConfig) ([]TOCEntry, error) {
@@ -39,6 +41,7 @@ func GenerateTOC(analysis *AnalysisResult, _ OverlayConfig) ([]TOCEntry, error)
 				PageNumber: item.StartPage - analysis.FrontMatterPages,
 				IsPart:     false,
 				IsPrologue: !seenPartDivider,
+				WorkID:     item.WorkID,
 			})
 
 		case ContentTypeBackMatter:
@@ -89,7 +92,11 @@ func buildTOCContent(entries []TOCEntry, _ OverlayConfig) string {
 		}
 
 		indent := currentPart != ""
-		lines = append(lines, formatTOCLine(entry.Title, entry.PageNumber, indent))
+		title := entry.Title
+		if entry.Level > 1 {
+			title = strings.Repeat("  ", entry.Level-1) + title
+		}
+		lines = append(lines, formatTOCLine(title, entry.PageNumber, indent))
 	}
 
 	return strings.Join(lines, "\n")
@@ -20,7 +20,12 @@ func CreateTOCDocx(entries []TOCEntry, templatePath, outputPath string) error {
 	if err != nil {
 		return fmt.Errorf("build document xml: %w", err)
 	}
+	return writeTemplateDocx(templatePath, outputPath, docXML)
+}
 
+// writeTemplateDocx copies the template to outputPath with its document
+// body replaced by docXML and its media left out
+func writeTemplateDocx(templatePath, outputPath string, docXML []byte) error {
 	reader, err := zip.OpenReader(templatePath)
 	if err != nil {
 		return fmt.Errorf("open template: %w", err)
@@ -93,35 +98,50 @@ func copyZipFileToWriter(zw *zip.Writer, src *zip.File) error {
 	return err
 }
 
-func buildTOCDocumentXML(entries []TOCEntry, templatePath string) ([]byte, error) {
-	reader, err := zip.OpenReader(templatePath)
+// templateBody splits the template's document.xml around its body: the
+// markup up to and including <w:body>, the content, the final section
+// properties, and the markup from </w:body> on
+type templateBody struct {
+	open    string
+	content string
+	sectPr  string
+	close   string
+}
+
+func readTemplateBody(templatePath string) (*templateBody, error) {
+	docXML, err := readDocumentXML(templatePath)
 	if err != nil {
-		return nil, fmt.Errorf("open template: %w", err)
+		return nil, err
+	}
+	return splitDocumentBody(string(docXML))
+}
+
+// readDocumentXML reads word/document.xml out of a Word file
+func readDocumentXML(path string) ([]byte, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", filepath.Base(path), err)
 	}
 	defer reader.Close()
 
-	var templateDocXML []byte
 	for _, file := range reader.File {
 		if file.Name == documentXMLPath {
 			rc, err := file.Open()
 			if err != nil {
 				return nil, fmt.Errorf("open document.xml: %w", err)
 			}
-			templateDocXML, err = io.ReadAll(rc)
-			rc.Close()
+			defer rc.Close()
+			data, err := io.ReadAll(rc)
 			if err != nil {
 				return nil, fmt.Errorf("read document.xml: %w", err)
 			}
-			break
+			return data, nil
 		}
 	}
+	return nil, fmt.Errorf("document.xml not found in %s", filepath.Base(path))
+}
 
-	if templateDocXML == nil {
-		return nil, fmt.Errorf("document.xml not found in template")
-	}
-
-	content := string(templateDocXML)
-
+func splitDocumentBody(content string) (*templateBody, error) {
 	bodyStart := strings.Index(content, "<w:body>")
 	if bodyStart == -1 {
 		bodyStart = strings.Index(content, "<w:body ")
@@ -141,14 +161,31 @@ func buildTOCDocumentXML(entries []TOCEntry, templatePath string) ([]byte, error
 	}
 	bodyTagEnd += bodyStart + 1
 
+	contentEnd := bodyEnd
 	sectPrStart := strings.LastIndex(content[:bodyEnd], "<w:sectPr")
 	var sectPr string
-	if sectPrStart != -1 {
+	// A section break inside the last paragraph is part of the content
+	if sectPrStart >= bodyTagEnd && !strings.Contains(content[sectPrStart:bodyEnd], "</w:p>") {
 		sectPr = content[sectPrStart:bodyEnd]
+		contentEnd = sectPrStart
+	}
+
+	return &templateBody{
+		open:    content[:bodyTagEnd],
+		content: content[bodyTagEnd:contentEnd],
+		sectPr:  sectPr,
+		close:   content[bodyEnd:],
+	}, nil
+}
+
+func buildTOCDocumentXML(entries []TOCEntry, templatePath string) ([]byte, error) {
+	tmpl, err := readTemplateBody(templatePath)
+	if err != nil {
+		return nil, err
 	}
 
 	var buf bytes.Buffer
-	buf.WriteString(content[:bodyTagEnd])
+	buf.WriteString(tmpl.open)
 
 	buf.WriteString(`<w:p>`)
 	buf.WriteString(`<w:pPr><w:pStyle w:val="Title"/></w:pPr>`)
@@ -164,7 +201,7 @@ func buildTOCDocumentXML(entries []TOCEntry, templatePath string) ([]byte, error
 		if !entry.IsPrologue {
 			continue
 		}
-		buf.WriteString(buildTOCEntryParagraph(entry.Title, entry.PageNumber, false))
+		buf.WriteString(buildTOCEntryParagraph(entry.Title, entry.PageNumber, tocIndent(entry, 0)))
 	}
 
 	for _, entry := range entries {
@@ -182,25 +219,31 @@ func buildTOCDocumentXML(entries []TOCEntry, templatePath string) ([]byte, error
 			continue
 		}
 
-		buf.WriteString(buildTOCEntryParagraph(entry.Title, entry.PageNumber, true))
+		buf.WriteString(buildTOCEntryParagraph(entry.Title, entry.PageNumber, tocIndent(entry, 720)))
 	}
 
-	if sectPr != "" {
-		buf.WriteString(sectPr)
-	}
-
-	buf.WriteString(content[bodyEnd:])
+	buf.WriteString(tmpl.sectPr)
+	buf.WriteString(tmpl.close)
 
 	return buf.Bytes(), nil
 }
 
-func buildTOCEntryParagraph(title string, pageNum int, indented bool) string {
+// tocIndent is an entry's left indent in twips: base for works, and a
+// further quarter inch per heading level below them
+func tocIndent(entry TOCEntry, base int) int {
+	if entry.Level > 1 {
+		return base + 360*(entry.Level-1)
+	}
+	return base
+}
+
+func buildTOCEntryParagraph(title string, pageNum int, indent int) string {
 	var buf bytes.Buffer
 
 	buf.WriteString(`<w:p>`)
 	buf.WriteString(`<w:pPr><w:pStyle w:val="Normal"/>`)
-	if indented {
-		buf.WriteString(`<w:ind w:left="720"/>`)
+	if indent > 0 {
+		buf.WriteString(fmt.Sprintf(`<w:ind w:left="%d"/>`, indent))
 	}
 	buf.WriteString(`<w:tabs><w:tab w:val="right" w:leader="dot" w:pos="9360"/></w:tabs>`)
 	buf.WriteString(`</w:pPr>`)
@@ -276,9 +319,13 @@ end if`, absDocx, absPDF)
 	return nil
 }
 
+// ConvertFunc converts a document to a PDF. The app passes its configured
+// converters; nil means ConvertDocxToPDF.
+type ConvertFunc func(ctx context.Context, src, dst string) error
+
 // CreateTOCPDFViaDocx creates a TOC PDF by first generating a DOTM, converting
-// it to PDF via Word, then deleting the temp file.
-func CreateTOCPDFViaDocx(entries []TOCEntry, templatePath, outputPath string) error {
+// it to PDF with convert, then deleting the temp file.
+func CreateTOCPDFViaDocx(ctx context.Context, convert ConvertFunc, entries []TOCEntry, templatePath, outputPath string) error {
 	tempDir := filepath.Dir(outputPath)
 	tempDotm := filepath.Join(tempDir, "toc_temp.dotm")
 
@@ -286,7 +333,10 @@ func CreateTOCPDFViaDocx(entries []TOCEntry, templatePath, outputPath string) er
 		return fmt.Errorf("create toc dotm: %w", err)
 	}
 
-	if err := ConvertDocxToPDF(tempDotm, outputPath); err != nil {
+	if convert == nil {
+		convert = func(_ context.Context, src, dst string) error { return ConvertDocxToPDF(src, dst) }
+	}
+	if err := convert(ctx, tempDotm, outputPath); err != nil {
 		os.Remove(tempDotm)
 		return fmt.Errorf("convert to pdf: %w", err)
 	}
@@ -0,0 +1,105 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestExpandRecurringWrapsYear(t *testing.T) {
+	windows := []models.SubmissionWindow{{
+		WindowID:     1,
+		OrgName:      "Rattle",
+		Kind:         models.WindowKindReadingPeriod,
+		Opens:        strPtr("2000-11-01"),
+		Closes:       "2000-02-15",
+		RecursYearly: true,
+	}}
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	today := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	events := Expand(windows, from, to, today)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d: %+v", len(events), events)
+	}
+
+	if events[0].Type != EventCloses || events[0].Date != "2025-02-15" || events[0].Opens != "2024-11-01" {
+		t.Errorf("unexpected first event: %+v", events[0])
+	}
+	if !events[0].IsOpen {
+		t.Error("window should be open on 2025-01-10")
+	}
+	if events[1].Type != EventOpens || events[1].Date != "2025-11-01" || events[1].Closes != "2026-02-15" {
+		t.Errorf("unexpected second event: %+v", events[1])
+	}
+	if events[1].IsOpen {
+		t.Error("next year's window should not be open yet")
+	}
+}
+
+func TestExpandOneOffContest(t *testing.T) {
+	windows := []models.SubmissionWindow{{
+		WindowID: 2,
+		OrgName:  "Narrative",
+		Kind:     models.WindowKindContest,
+		Name:     strPtr("Spring Contest"),
+		Closes:   "2025-05-31",
+	}}
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := Expand(windows, from, from.AddDate(0, 3, 0), from)
+	if len(events) != 0 {
+		t.Errorf("deadline outside range should be skipped, got %+v", events)
+	}
+
+	events = Expand(windows, from, from.AddDate(1, 0, 0), from)
+	if len(events) != 1 || Summary(events[0]) != "Narrative Spring Contest deadline" {
+		t.Errorf("unexpected events: %+v", events)
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	fee := 20.0
+	events := []models.CalendarEvent{{
+		UID:     "window-1-20250531-closes@works",
+		OrgName: "Journal; of Things, Inc",
+		Kind:    models.WindowKindContest,
+		Type:    EventCloses,
+		Date:    "2025-05-31",
+		Closes:  "2025-05-31",
+		Fee:     &fee,
+		Prize:   strings.Repeat("A very large prize ", 6),
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, events, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WriteICS: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTART;VALUE=DATE:20250531\r\n",
+		"DTEND;VALUE=DATE:20250601\r\n",
+		`SUMMARY:Journal\; of Things\, Inc deadline`,
+		"DTSTAMP:20250101T120000Z\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line not folded (%d octets): %q", len(line), line)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+const dateLayout = "2006-01-02"
+
+const (
+	EventOpens  = "opens"
+	EventCloses = "closes"
+)
+
+type occurrence struct {
+	opens  time.Time // zero if the window has no opening date
+	closes time.Time
+}
+
+// Expand turns submission windows into dated open/close events falling
+// within [from, to]. Yearly windows are repeated for every year the range
+// touches. today determines IsOpen and DaysAway.
+func Expand(windows []models.SubmissionWindow, from, to, today time.Time) []models.CalendarEvent {
+	from = truncateDay(from)
+	to = truncateDay(to)
+	today = truncateDay(today)
+
+	events := []models.CalendarEvent{}
+	for _, w := range windows {
+		for _, occ := range occurrences(w, from, to) {
+			if !occ.opens.IsZero() && inRange(occ.opens, from, to) {
+				events = append(events, newEvent(w, occ, EventOpens, occ.opens, today))
+			}
+			if inRange(occ.closes, from, to) {
+				events = append(events, newEvent(w, occ, EventCloses, occ.closes, today))
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Date != events[j].Date {
+			return events[i].Date < events[j].Date
+		}
+		return events[i].OrgName < events[j].OrgName
+	})
+	return events
+}
+
+func occurrences(w models.SubmissionWindow, from, to time.Time) []occurrence {
+	closes, err := time.Parse(dateLayout, w.Closes)
+	if err != nil {
+		return nil
+	}
+	var opens time.Time
+	if w.Opens != nil {
+		opens, _ = time.Parse(dateLayout, *w.Opens)
+	}
+
+	if !w.RecursYearly {
+		return []occurrence{{opens: opens, closes: closes}}
+	}
+
+	// A window that wraps the new year can close in the first year of the range
+	var result []occurrence
+	for year := from.Year() - 1; year <= to.Year(); year++ {
+		occ := occurrence{closes: time.Date(year, closes.Month(), closes.Day(), 0, 0, 0, 0, time.UTC)}
+		if !opens.IsZero() {
+			occ.opens = time.Date(year, opens.Month(), opens.Day(), 0, 0, 0, 0, time.UTC)
+			if occ.closes.Before(occ.opens) {
+				occ.closes = occ.closes.AddDate(1, 0, 0)
+			}
+		}
+		result = append(result, occ)
+	}
+	return result
+}
+
+func newEvent(w models.SubmissionWindow, occ occurrence, typ string, date, today time.Time) models.CalendarEvent {
+	ev := models.CalendarEvent{
+		UID:      fmt.Sprintf("window-%d-%s-%s@works", w.WindowID, date.Format("20060102"), typ),
+		WindowID: w.WindowID,
+		OrgID:    w.OrgID,
+		OrgName:  w.OrgName,
+		Kind:     w.Kind,
+		Type:     typ,
+		Date:     date.Format(dateLayout),
+		Closes:   occ.closes.Format(dateLayout),
+		Fee:      w.Fee,
+		URL:      w.OrgURL,
+		DaysAway: int(date.Sub(today).Hours() / 24),
+	}
+	if w.Name != nil {
+		ev.Name = *w.Name
+	}
+	if w.Prize != nil {
+		ev.Prize = *w.Prize
+	}
+	if w.URL != nil && *w.URL != "" {
+		ev.URL = *w.URL
+	}
+	if !occ.opens.IsZero() {
+		ev.Opens = occ.opens.Format(dateLayout)
+		ev.IsOpen = !today.Before(occ.opens) && !today.After(occ.closes)
+	} else {
+		ev.IsOpen = !today.After(occ.closes)
+	}
+	return ev
+}
+
+func inRange(t, from, to time.Time) bool {
+	return !t.Before(from) && !t.After(to)
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
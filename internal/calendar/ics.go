@@ -0,0 +1,119 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+const maxLineOctets = 75
+
+// WriteICS writes events as an RFC 5545 iCalendar feed of all-day events
+func WriteICS(w io.Writer, events []models.CalendarEvent, stamp time.Time) error {
+	bw := bufio.NewWriter(w)
+	dtstamp := stamp.UTC().Format("20060102T150405Z")
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:-//TrueBlocks//Works//EN")
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	writeLine(bw, "METHOD:PUBLISH")
+	writeLine(bw, "X-WR-CALNAME:Submission Windows")
+
+	for _, ev := range events {
+		date, err := time.Parse(dateLayout, ev.Date)
+		if err != nil {
+			continue
+		}
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+ev.UID)
+		writeLine(bw, "DTSTAMP:"+dtstamp)
+		writeLine(bw, "DTSTART;VALUE=DATE:"+date.Format("20060102"))
+		writeLine(bw, "DTEND;VALUE=DATE:"+date.AddDate(0, 0, 1).Format("20060102"))
+		writeLine(bw, "SUMMARY:"+escapeText(Summary(ev)))
+		if desc := description(ev); desc != "" {
+			writeLine(bw, "DESCRIPTION:"+escapeText(desc))
+		}
+		if ev.URL != "" {
+			writeLine(bw, "URL:"+ev.URL)
+		}
+		writeLine(bw, "CATEGORIES:"+escapeText(ev.Kind))
+		writeLine(bw, "TRANSP:TRANSPARENT")
+		writeLine(bw, "END:VEVENT")
+	}
+
+	writeLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+// Summary is the one-line title shown for an event in calendar apps
+func Summary(ev models.CalendarEvent) string {
+	label := ev.OrgName
+	if ev.Name != "" {
+		label += " " + ev.Name
+	}
+
+	switch {
+	case ev.Kind == models.WindowKindContest && ev.Type == EventCloses:
+		return label + " deadline"
+	case ev.Type == EventOpens:
+		return label + " opens"
+	default:
+		return label + " closes"
+	}
+}
+
+func description(ev models.CalendarEvent) string {
+	var parts []string
+	if ev.Opens != "" {
+		parts = append(parts, fmt.Sprintf("%s: %s to %s", ev.Kind, ev.Opens, ev.Closes))
+	} else {
+		parts = append(parts, fmt.Sprintf("%s closes %s", ev.Kind, ev.Closes))
+	}
+	if ev.Fee != nil && *ev.Fee > 0 {
+		parts = append(parts, fmt.Sprintf("Fee: $%.2f", *ev.Fee))
+	}
+	if ev.Prize != "" {
+		parts = append(parts, "Prize: "+ev.Prize)
+	}
+	if ev.URL != "" {
+		parts = append(parts, ev.URL)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func escapeText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return r.Replace(s)
+}
+
+// writeLine folds content lines longer than 75 octets without splitting
+// a UTF-8 sequence, then terminates with CRLF
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		_, _ = w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// Continuation lines spend one octet on the leading space
+		limit = maxLineOctets - 1
+	}
+	_, _ = w.WriteString(line + "\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,523 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+// auditedTable is a table whose row changes are captured in AuditLog by
+// triggers. Every audited table has a single INTEGER PRIMARY KEY.
+type auditedTable struct {
+	name       string
+	entityType string
+}
+
+var auditedTables = []auditedTable{
+	{"Works", "work"},
+	{"Organizations", "organization"},
+	{"Submissions", "submission"},
+	{"Collections", "collection"},
+	{"CollectionDetails", "collection_detail"},
+	{"Notes", "note"},
+	{"Books", "book"},
+	{"SubmissionWindows", "window"},
+	{"SubmissionPackets", "packet"},
+}
+
+// auditVolatileColumns are bookkeeping columns written in the background
+// (the file watcher, the search indexer, list marking). An update that
+// changes only these is not recorded, so it never becomes an undo step.
+var auditVolatileColumns = map[string]bool{
+	"file_mtime": true,
+	"n_words":    true,
+	"is_marked":  true,
+}
+
+// auditJSONChunk bounds the number of columns per json_object/json_insert
+// call so wide tables stay under SQLite's function argument limit
+const auditJSONChunk = 40
+
+// auditOp starts an undoable operation and returns the DB its statements
+// must run on, with the function that ends it. The operation is a
+// transaction that sets its op in AuditState, so the triggers attribute to
+// it only the row changes made through the returned DB; other writers wait
+// for the single connection until it ends. Nested calls join the outermost
+// operation, so DeleteWork's cascade of submission and note deletes undoes
+// as a unit. The end function commits, or rolls back if *errp is set:
+//
+//	db, end := db.auditOp("Update work")
+//	defer end(&err)
+func (db *DB) auditOp(label string) (*DB, func(errp *error)) {
+	if db.tx != nil {
+		return db, func(*error) {}
+	}
+
+	tx, err := db.pool.Begin()
+	if err != nil {
+		return db, func(errp *error) {
+			if *errp == nil {
+				*errp = fmt.Errorf("begin audit operation: %w", err)
+			}
+		}
+	}
+	var opID int64
+	err = tx.QueryRow(`UPDATE AuditState SET op_id = next_op, op_label = ?, next_op = next_op + 1
+		WHERE id = 1 RETURNING op_id`, label).Scan(&opID)
+	op := &DB{pool: db.pool, conn: tx, path: db.path, tx: tx}
+
+	return op, func(errp *error) {
+		if *errp == nil && err != nil {
+			*errp = fmt.Errorf("begin audit operation: %w", err)
+		}
+		if *errp != nil {
+			_ = tx.Rollback()
+			return
+		}
+		if *errp = endAuditOp(tx, opID); *errp != nil {
+			_ = tx.Rollback()
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			*errp = fmt.Errorf("commit %s: %w", strings.ToLower(label), err)
+		}
+	}
+}
+
+func endAuditOp(tx *sql.Tx, opID int64) error {
+	if _, err := tx.Exec(`UPDATE AuditState SET op_id = NULL, op_label = NULL WHERE id = 1`); err != nil {
+		return fmt.Errorf("end audit operation: %w", err)
+	}
+
+	// A new change invalidates anything that could have been redone
+	if _, err := tx.Exec(`DELETE FROM AuditLog WHERE undone = 1
+		AND EXISTS (SELECT 1 FROM AuditLog WHERE op_id = ?)`, opID); err != nil {
+		return fmt.Errorf("clear redo history: %w", err)
+	}
+	return nil
+}
+
+// dropAuditTriggers removes the capture triggers so migrations that rebuild
+// tables are neither blocked by nor recorded in the audit log
+func (db *DB) dropAuditTriggers() error {
+	for _, t := range auditedTables {
+		for _, suffix := range []string{"ai", "au", "ad"} {
+			if _, err := db.conn.Exec(fmt.Sprintf(`DROP TRIGGER IF EXISTS audit_%s_%s`, t.name, suffix)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// installAuditTriggers (re)creates the capture triggers from each table's
+// current columns, so columns added by later migrations are always included
+func (db *DB) installAuditTriggers() error {
+	if err := db.dropAuditTriggers(); err != nil {
+		return err
+	}
+
+	for _, t := range auditedTables {
+		cols, pk, err := db.tableColumns(t.name)
+		if err != nil {
+			return err
+		}
+		if len(cols) == 0 || pk == "" {
+			continue
+		}
+
+		oldJSON := auditRowJSON("OLD", cols)
+		newJSON := auditRowJSON("NEW", cols)
+
+		var tracked []string
+		for _, c := range cols {
+			if !auditVolatileColumns[c] {
+				tracked = append(tracked, c)
+			}
+		}
+		changed := fmt.Sprintf("%s IS NOT %s", auditRowJSON("OLD", tracked), auditRowJSON("NEW", tracked))
+		insert := `INSERT INTO AuditLog (op_id, op_label, table_name, entity_type, entity_id, action, before_json, after_json)
+			SELECT op_id, op_label, '%s', '%s', %s, '%s', %s, %s FROM AuditState WHERE id = 1;`
+		active := `(SELECT suspended FROM AuditState WHERE id = 1) = 0`
+
+		stmts := []string{
+			fmt.Sprintf(`CREATE TRIGGER audit_%s_ai AFTER INSERT ON %s WHEN %s BEGIN `+insert+` END`,
+				t.name, t.name, active, t.name, t.entityType, "NEW."+pk, "insert", "NULL", newJSON),
+			fmt.Sprintf(`CREATE TRIGGER audit_%s_au AFTER UPDATE ON %s WHEN %s AND %s BEGIN `+insert+` END`,
+				t.name, t.name, active, changed, t.name, t.entityType, "NEW."+pk, "update", oldJSON, newJSON),
+			fmt.Sprintf(`CREATE TRIGGER audit_%s_ad AFTER DELETE ON %s WHEN %s BEGIN `+insert+` END`,
+				t.name, t.name, active, t.name, t.entityType, "OLD."+pk, "delete", oldJSON, "NULL"),
+		}
+		for _, stmt := range stmts {
+			if _, err := db.conn.Exec(stmt); err != nil {
+				return fmt.Errorf("create audit trigger on %s: %w", t.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func auditRowJSON(row string, cols []string) string {
+	expr := ""
+	for i := 0; i < len(cols); i += auditJSONChunk {
+		chunk := cols[i:min(i+auditJSONChunk, len(cols))]
+		pairs := make([]string, 0, len(chunk))
+		for _, c := range chunk {
+			if expr == "" {
+				pairs = append(pairs, fmt.Sprintf(`'%s', %s."%s"`, c, row, c))
+			} else {
+				pairs = append(pairs, fmt.Sprintf(`'$.%s', %s."%s"`, c, row, c))
+			}
+		}
+		if expr == "" {
+			expr = "json_object(" + strings.Join(pairs, ", ") + ")"
+		} else {
+			expr = "json_insert(" + expr + ", " + strings.Join(pairs, ", ") + ")"
+		}
+	}
+	return expr
+}
+
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func (db *DB) tableColumns(table string) ([]string, string, error) {
+	return tableColumns(db.conn, table)
+}
+
+func tableColumns(q queryer, table string) ([]string, string, error) {
+	rows, err := q.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return nil, "", fmt.Errorf("table info %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var cols []string
+	var pk string
+	for rows.Next() {
+		var cid, notNull, pkOrdinal int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pkOrdinal); err != nil {
+			return nil, "", err
+		}
+		cols = append(cols, name)
+		if pkOrdinal == 1 {
+			pk = name
+		}
+	}
+	return cols, pk, rows.Err()
+}
+
+const selectAuditEntries = `SELECT id, COALESCE(op_id, -id), COALESCE(op_label, ''), table_name, entity_type,
+	COALESCE(entity_id, 0), action, COALESCE(before_json, ''), COALESCE(after_json, ''), undone, created_at
+	FROM AuditLog`
+
+func scanAuditEntries(rows *sql.Rows) ([]models.AuditEntry, error) {
+	defer rows.Close()
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(&e.ID, &e.OpID, &e.OpLabel, &e.TableName, &e.EntityType,
+			&e.EntityID, &e.Action, &e.Before, &e.After, &e.Undone, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// GetEntityHistory returns every recorded change to one entity, newest first
+func (db *DB) GetEntityHistory(entityType string, entityID int64) ([]models.AuditEntry, error) {
+	rows, err := db.conn.Query(selectAuditEntries+` WHERE entity_type = ? AND entity_id = ? ORDER BY id DESC`,
+		entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("query entity history: %w", err)
+	}
+	return scanAuditEntries(rows)
+}
+
+// ListAuditOperations returns the most recent operations, newest first,
+// including undone operations that can still be redone
+func (db *DB) ListAuditOperations(limit int) ([]models.AuditOperation, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	return db.auditOperations(`SELECT COALESCE(op_id, -id) AS op, MAX(id) FROM AuditLog
+		GROUP BY op ORDER BY MAX(id) DESC LIMIT ?`, limit)
+}
+
+// auditOperations loads the operations whose keys are selected by query
+func (db *DB) auditOperations(query string, args ...any) ([]models.AuditOperation, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query audit operations: %w", err)
+	}
+	var keys []int64
+	for rows.Next() {
+		var key, maxID int64
+		if err := rows.Scan(&key, &maxID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+
+	ops := make([]models.AuditOperation, 0, len(keys))
+	for _, key := range keys {
+		entries, err := db.operationEntries(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		ops = append(ops, models.AuditOperation{
+			OpID:      key,
+			Label:     entries[0].OpLabel,
+			CreatedAt: entries[0].CreatedAt,
+			Undone:    entries[0].Undone,
+			Entries:   entries,
+		})
+	}
+	return ops, nil
+}
+
+// operationEntries returns an operation's rows in the order they were written.
+// Negative keys identify single changes made outside any operation.
+func (db *DB) operationEntries(key int64) ([]models.AuditEntry, error) {
+	var rows *sql.Rows
+	var err error
+	if key < 0 {
+		rows, err = db.conn.Query(selectAuditEntries+` WHERE id = ? AND op_id IS NULL`, -key)
+	} else {
+		rows, err = db.conn.Query(selectAuditEntries+` WHERE op_id = ? ORDER BY id`, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query operation entries: %w", err)
+	}
+	return scanAuditEntries(rows)
+}
+
+// Undo reverts the last n operations that have not been undone, newest first
+func (db *DB) Undo(n int) ([]models.AuditOperation, error) {
+	ops, err := db.auditOperations(`SELECT COALESCE(op_id, -id) AS op, MAX(id) FROM AuditLog
+		WHERE undone = 0 GROUP BY op ORDER BY MAX(id) DESC LIMIT ?`, max(n, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		if err := db.replayOperation(op, true); err != nil {
+			return ops[:i], fmt.Errorf("undo %q: %w", op.Label, err)
+		}
+	}
+	return ops, nil
+}
+
+// Redo re-applies the n most recently undone operations. Undo works back
+// from the newest operation, so the most recently undone is the oldest.
+func (db *DB) Redo(n int) ([]models.AuditOperation, error) {
+	ops, err := db.auditOperations(`SELECT COALESCE(op_id, -id) AS op, MIN(id) FROM AuditLog
+		WHERE undone = 1 GROUP BY op ORDER BY MIN(id) LIMIT ?`, max(n, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		if err := db.replayOperation(op, false); err != nil {
+			return ops[:i], fmt.Errorf("redo %q: %w", op.Label, err)
+		}
+	}
+	return ops, nil
+}
+
+// replayOperation writes an operation's before images (undo) or after
+// images (redo) with capture suspended, then flips its undone flag
+func (db *DB) replayOperation(op models.AuditOperation, undo bool) error {
+	tx, err := db.pool.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Cascaded child rows may be logged before their parent
+	if _, err := tx.Exec(`PRAGMA defer_foreign_keys = ON`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE AuditState SET suspended = 1 WHERE id = 1`); err != nil {
+		return err
+	}
+
+	entries := op.Entries
+	for i := range entries {
+		e := entries[i]
+		if undo {
+			e = entries[len(entries)-1-i]
+		}
+		image, other := e.After, e.Before
+		if undo {
+			image, other = e.Before, e.After
+		}
+		if err := applyRowImage(tx, e.TableName, image, other); err != nil {
+			return fmt.Errorf("%s %d: %w", e.EntityType, e.EntityID, err)
+		}
+		if _, err := tx.Exec(`UPDATE AuditLog SET undone = ? WHERE id = ?`, undo, e.ID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE AuditState SET suspended = 0 WHERE id = 1`); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	db.invalidateAfterReplay(entries)
+	return nil
+}
+
+// invalidateAfterReplay drops caches derived from rows that were rewritten
+// outside the usual create/update paths
+func (db *DB) invalidateAfterReplay(entries []models.AuditEntry) {
+	for _, e := range entries {
+		if e.TableName == "Submissions" {
+			_, _ = db.conn.Exec(`DELETE FROM OrgResponseStats`)
+			return
+		}
+	}
+}
+
+// RestoreEntityVersion sets an entity back to the state recorded by one
+// audit entry (its after image, or its before image for a delete). The
+// restore is itself recorded, so it can be undone.
+func (db *DB) RestoreEntityVersion(auditID int64) (err error) {
+	rows, err := db.conn.Query(selectAuditEntries+` WHERE id = ?`, auditID)
+	if err != nil {
+		return fmt.Errorf("query audit entry: %w", err)
+	}
+	entries, err := scanAuditEntries(rows)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("audit entry %d not found", auditID)
+	}
+	e := entries[0]
+
+	image, other := e.After, e.Before
+	if e.Action == "delete" {
+		image, other = e.Before, ""
+	}
+
+	db, end := db.auditOp(fmt.Sprintf("Restore %s %d", e.EntityType, e.EntityID))
+	defer end(&err)
+
+	if err := applyRowImage(db.conn, e.TableName, image, other); err != nil {
+		return err
+	}
+	db.invalidateAfterReplay(entries)
+	return nil
+}
+
+// PruneAuditLog drops history older than maxAge
+func (db *DB) PruneAuditLog(maxAge time.Duration) error {
+	cutoff := time.Now().Add(-maxAge).UTC().Format("2006-01-02T15:04:05Z")
+	_, err := db.conn.Exec(`DELETE FROM AuditLog WHERE created_at < ?`, cutoff)
+	return err
+}
+
+// applyRowImage makes a row match a JSON image. An empty image deletes the
+// row identified by the primary key in other.
+func applyRowImage(q querier, table, image, other string) error {
+	valid := false
+	for _, t := range auditedTables {
+		if t.name == table {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("table %s is not audited", table)
+	}
+
+	cols, pk, err := tableColumns(q, table)
+	if err != nil {
+		return err
+	}
+
+	source := image
+	if source == "" {
+		source = other
+	}
+	values, err := decodeRowImage(source)
+	if err != nil {
+		return err
+	}
+	key, ok := values[pk]
+	if !ok || key == nil {
+		return fmt.Errorf("row image has no %s", pk)
+	}
+
+	if image == "" {
+		_, err := q.Exec(fmt.Sprintf(`DELETE FROM %s WHERE "%s" = ?`, table, pk), key)
+		return err
+	}
+
+	var exists int
+	if err := q.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE "%s" = ?`, table, pk), key).Scan(&exists); err != nil {
+		return err
+	}
+
+	args := make([]any, 0, len(cols))
+	if exists > 0 {
+		sets := make([]string, 0, len(cols))
+		for _, c := range cols {
+			// Bookkeeping columns describe the present, not the version being restored
+			if c == pk || auditVolatileColumns[c] {
+				continue
+			}
+			sets = append(sets, fmt.Sprintf(`"%s" = ?`, c))
+			args = append(args, values[c])
+		}
+		args = append(args, key)
+		_, err = q.Exec(fmt.Sprintf(`UPDATE %s SET %s WHERE "%s" = ?`, table, strings.Join(sets, ", "), pk), args...)
+		return err
+	}
+
+	quoted := make([]string, 0, len(cols))
+	for _, c := range cols {
+		quoted = append(quoted, `"`+c+`"`)
+		args = append(args, values[c])
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", ")
+	_, err = q.Exec(fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s)`, table, strings.Join(quoted, ", "), placeholders), args...)
+	return err
+}
+
+// decodeRowImage parses a row image, keeping integers as int64 so keys and
+// flags round-trip exactly
+func decodeRowImage(image string) (map[string]any, error) {
+	dec := json.NewDecoder(strings.NewReader(image))
+	dec.UseNumber()
+	raw := map[string]any{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decode row image: %w", err)
+	}
+	for k, v := range raw {
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				raw[k] = i
+			} else if f, err := n.Float64(); err == nil {
+				raw[k] = f
+			}
+		}
+	}
+	return raw, nil
+}
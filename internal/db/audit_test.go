@@ -0,0 +1,232 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func TestUndoRedoUpdate(t *testing.T) {
+	database := setupTestDB(t)
+
+	work := &models.Work{Title: "Rain", Type: "Poem", Status: "Working", Quality: "Okay"}
+	if _, err := database.CreateWork(work); err != nil {
+		t.Fatalf("create work: %v", err)
+	}
+	work.Title = "Snow"
+	if _, err := database.UpdateWork(work); err != nil {
+		t.Fatalf("update work: %v", err)
+	}
+
+	ops, err := database.Undo(1)
+	if err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if len(ops) != 1 || ops[0].Label != "Update work" {
+		t.Fatalf("expected to undo the update, got %+v", ops)
+	}
+	got, _ := database.GetWork(work.WorkID)
+	if got == nil || got.Title != "Rain" {
+		t.Fatalf("expected title Rain after undo, got %+v", got)
+	}
+
+	if _, err := database.Redo(1); err != nil {
+		t.Fatalf("redo: %v", err)
+	}
+	got, _ = database.GetWork(work.WorkID)
+	if got == nil || got.Title != "Snow" {
+		t.Fatalf("expected title Snow after redo, got %+v", got)
+	}
+
+	if _, err := database.Undo(2); err != nil {
+		t.Fatalf("undo twice: %v", err)
+	}
+	if got, _ = database.GetWork(work.WorkID); got != nil {
+		t.Fatalf("expected work to be gone after undoing its creation")
+	}
+
+	// Redo replays the most recently undone operation first
+	ops, err = database.Redo(1)
+	if err != nil || len(ops) != 1 || ops[0].Label != "Create work" {
+		t.Fatalf("expected to redo the create, got %+v (%v)", ops, err)
+	}
+	got, _ = database.GetWork(work.WorkID)
+	if got == nil || got.Title != "Rain" {
+		t.Fatalf("expected title Rain after redoing the create, got %+v", got)
+	}
+
+	// A new change clears the redo stack
+	other := &models.Work{Title: "Hail", Type: "Poem", Status: "Working", Quality: "Okay"}
+	if _, err := database.CreateWork(other); err != nil {
+		t.Fatalf("create work: %v", err)
+	}
+	if ops, _ := database.Redo(1); len(ops) != 0 {
+		t.Fatalf("expected nothing to redo, got %+v", ops)
+	}
+}
+
+func TestUndoPermanentDeleteRestoresCascade(t *testing.T) {
+	database := setupTestDB(t)
+
+	work := &models.Work{Title: "Rain", Type: "Poem", Status: "Working", Quality: "Okay"}
+	if _, err := database.CreateWork(work); err != nil {
+		t.Fatalf("create work: %v", err)
+	}
+	coll := &models.Collection{CollectionName: "Weather"}
+	if _, err := database.CreateCollection(coll); err != nil {
+		t.Fatalf("create collection: %v", err)
+	}
+	if err := database.AddWorkToCollection(coll.CollID, work.WorkID); err != nil {
+		t.Fatalf("add work: %v", err)
+	}
+
+	if err := database.DeleteWorkPermanent(work.WorkID); err != nil {
+		t.Fatalf("delete work: %v", err)
+	}
+	if ops, err := database.Undo(1); err != nil || len(ops) != 1 {
+		t.Fatalf("undo: %v %+v", err, ops)
+	}
+
+	if got, _ := database.GetWork(work.WorkID); got == nil {
+		t.Fatalf("expected work to be restored")
+	}
+	var members int
+	if err := database.conn.QueryRow(`SELECT COUNT(*) FROM CollectionDetails WHERE collID = ? AND workID = ?`,
+		coll.CollID, work.WorkID).Scan(&members); err != nil || members != 1 {
+		t.Fatalf("expected collection membership to be restored, got %d (%v)", members, err)
+	}
+}
+
+func TestRestoreEntityVersion(t *testing.T) {
+	database := setupTestDB(t)
+
+	work := &models.Work{Title: "Rain", Type: "Poem", Status: "Working", Quality: "Okay"}
+	if _, err := database.CreateWork(work); err != nil {
+		t.Fatalf("create work: %v", err)
+	}
+	work.Title = "Snow"
+	if _, err := database.UpdateWork(work); err != nil {
+		t.Fatalf("update work: %v", err)
+	}
+
+	history, err := database.GetEntityHistory("work", work.WorkID)
+	if err != nil || len(history) != 2 {
+		t.Fatalf("expected two history entries, got %d (%v)", len(history), err)
+	}
+	if history[1].Action != "insert" {
+		t.Fatalf("expected oldest entry to be the insert, got %s", history[1].Action)
+	}
+
+	if err := database.RestoreEntityVersion(history[1].ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	got, _ := database.GetWork(work.WorkID)
+	if got == nil || got.Title != "Rain" {
+		t.Fatalf("expected title Rain after restore, got %+v", got)
+	}
+
+	// The restore is itself undoable
+	if _, err := database.Undo(1); err != nil {
+		t.Fatalf("undo restore: %v", err)
+	}
+	got, _ = database.GetWork(work.WorkID)
+	if got == nil || got.Title != "Snow" {
+		t.Fatalf("expected title Snow after undoing restore, got %+v", got)
+	}
+}
+
+func TestVolatileUpdatesNotRecorded(t *testing.T) {
+	database := setupTestDB(t)
+
+	work := &models.Work{Title: "Rain", Type: "Poem", Status: "Working", Quality: "Okay"}
+	if _, err := database.CreateWork(work); err != nil {
+		t.Fatalf("create work: %v", err)
+	}
+	if _, err := database.conn.Exec(`UPDATE Works SET file_mtime = 12345, n_words = 99 WHERE workID = ?`, work.WorkID); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	ops, err := database.ListAuditOperations(10)
+	if err != nil || len(ops) != 1 || ops[0].Label != "Create work" {
+		t.Fatalf("expected only the create to be recorded, got %+v (%v)", ops, err)
+	}
+}
+
+func TestBatchUpdateIsOneOperation(t *testing.T) {
+	database := setupTestDB(t)
+
+	var ids []int64
+	for _, title := range []string{"Rain", "Snow", "Hail"} {
+		w := &models.Work{Title: title, Type: "Poem", Status: "Working", Quality: "Okay"}
+		if _, err := database.CreateWork(w); err != nil {
+			t.Fatalf("create work: %v", err)
+		}
+		ids = append(ids, w.WorkID)
+	}
+	if _, err := database.Undo(1); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+
+	if n, err := database.UpdateWorksField(ids[:2], "status", "Out"); err != nil || n != 2 {
+		t.Fatalf("UpdateWorksField = %d, %v", n, err)
+	}
+	if err := database.SetWorkSkipAudits(ids[0], true); err != nil {
+		t.Fatal(err)
+	}
+
+	// The batch cleared the redo stack left by the undo
+	if ops, err := database.Redo(1); err != nil || len(ops) != 0 {
+		t.Fatalf("expected nothing to redo, got %+v (%v)", ops, err)
+	}
+
+	ops, err := database.Undo(2)
+	if err != nil || len(ops) != 2 || ops[0].Label != "Skip audits" || ops[1].Label != "Batch update works" {
+		t.Fatalf("expected to undo the skip and the whole batch, got %+v (%v)", ops, err)
+	}
+	for _, id := range ids[:2] {
+		if got, _ := database.GetWork(id); got == nil || got.Status != "Working" || got.SkipAudits {
+			t.Errorf("work %d not restored: %+v", id, got)
+		}
+	}
+}
+
+func TestAuditOpLeavesOutOtherWriters(t *testing.T) {
+	database := setupTestDB(t)
+
+	work := &models.Work{Title: "Rain", Type: "Poem", Status: "Working", Quality: "Okay"}
+	if _, err := database.CreateWork(work); err != nil {
+		t.Fatalf("create work: %v", err)
+	}
+
+	op, end := database.auditOp("Retitle work")
+	if _, err := op.conn.Exec(`UPDATE Works SET title = 'Snow' WHERE workID = ?`, work.WorkID); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	// A write from elsewhere while the operation is open, as from the watcher
+	done := make(chan error)
+	go func() {
+		_, err := database.conn.Exec(`UPDATE Works SET status = 'Out' WHERE workID = ?`, work.WorkID)
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	var err error
+	end(&err)
+	if err != nil {
+		t.Fatalf("end operation: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("concurrent update: %v", err)
+	}
+
+	ops, err := database.ListAuditOperations(10)
+	if err != nil || len(ops) != 3 {
+		t.Fatalf("expected three operations, got %+v (%v)", ops, err)
+	}
+	if ops[0].Label != "" || len(ops[0].Entries) != 1 {
+		t.Errorf("the other write should be a change of its own, got %+v", ops[0])
+	}
+	if ops[1].Label != "Retitle work" || len(ops[1].Entries) != 1 {
+		t.Errorf("the operation should hold only its own change, got %+v", ops[1])
+	}
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+// AddBookBuild records a finished galley build
+func (db *DB) AddBookBuild(b *models.BookBuild) error {
+	inputs, err := json.Marshal(b.Inputs)
+	if err != nil {
+		return fmt.Errorf("encode build inputs: %w", err)
+	}
+	if b.CreatedAt == "" {
+		b.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	result, err := db.conn.Exec(`INSERT INTO BookBuilds
+		(collID, manifest, settings, inputs, output_path, output_sha256, pages_sha256, total_pages, duration, rebuilt_from, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		b.CollID, b.Manifest, b.Settings, string(inputs), b.OutputPath, b.OutputSHA256, b.PagesSHA256,
+		b.TotalPages, b.Duration, b.RebuiltFrom, b.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert book build: %w", err)
+	}
+	b.BuildID, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get last insert id: %w", err)
+	}
+	return nil
+}
+
+// GetBookBuild returns a build record with its manifest and inputs, or nil
+// if there is none
+func (db *DB) GetBookBuild(buildID int64) (*models.BookBuild, error) {
+	b := &models.BookBuild{}
+	var inputs string
+	err := db.conn.QueryRow(`SELECT buildID, collID, manifest, settings, inputs, output_path,
+		output_sha256, pages_sha256, total_pages, duration, rebuilt_from, created_at
+		FROM BookBuilds WHERE buildID = ?`, buildID).Scan(
+		&b.BuildID, &b.CollID, &b.Manifest, &b.Settings, &inputs, &b.OutputPath,
+		&b.OutputSHA256, &b.PagesSHA256, &b.TotalPages, &b.Duration, &b.RebuiltFrom, &b.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query book build %d: %w", buildID, err)
+	}
+	if err := json.Unmarshal([]byte(inputs), &b.Inputs); err != nil {
+		return nil, fmt.Errorf("decode inputs of build %d: %w", buildID, err)
+	}
+	return b, nil
+}
+
+// ListBookBuilds returns a collection's builds, newest first
+func (db *DB) ListBookBuilds(collID int64) ([]models.BookBuildInfo, error) {
+	rows, err := db.conn.Query(`SELECT buildID, collID, output_path, output_sha256, pages_sha256, total_pages,
+		json_array_length(inputs), duration, rebuilt_from, created_at
+		FROM BookBuilds WHERE collID = ? ORDER BY buildID DESC`, collID)
+	if err != nil {
+		return nil, fmt.Errorf("query book builds: %w", err)
+	}
+	defer rows.Close()
+
+	builds := []models.BookBuildInfo{}
+	for rows.Next() {
+		var b models.BookBuildInfo
+		if err := rows.Scan(&b.BuildID, &b.CollID, &b.OutputPath, &b.OutputSHA256, &b.PagesSHA256, &b.TotalPages,
+			&b.InputCount, &b.Duration, &b.RebuiltFrom, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan book build: %w", err)
+		}
+		builds = append(builds, b)
+	}
+	return builds, rows.Err()
+}
+
+// PruneBookBuilds deletes all but the newest keep builds of a collection
+// and returns how many it removed. Rebuilds of a deleted build no longer
+// point at it.
+func (db *DB) PruneBookBuilds(collID int64, keep int) (int64, error) {
+	result, err := db.conn.Exec(`DELETE FROM BookBuilds WHERE collID = ? AND buildID NOT IN
+		(SELECT buildID FROM BookBuilds WHERE collID = ? ORDER BY buildID DESC LIMIT ?)`, collID, collID, keep)
+	if err != nil {
+		return 0, fmt.Errorf("prune book builds: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil || removed == 0 {
+		return removed, err
+	}
+	if _, err := db.conn.Exec(`UPDATE BookBuilds SET rebuilt_from = NULL
+		WHERE rebuilt_from NOT IN (SELECT buildID FROM BookBuilds)`); err != nil {
+		return removed, fmt.Errorf("clear rebuilt_from: %w", err)
+	}
+	return removed, nil
+}
+
+// BookBuildObjects returns the hash of every archived input a build record
+// still refers to
+func (db *DB) BookBuildObjects() (map[string]bool, error) {
+	rows, err := db.conn.Query(`SELECT DISTINCT json_extract(input.value, '$.sha256')
+		FROM BookBuilds, json_each(BookBuilds.inputs) AS input
+		WHERE json_extract(input.value, '$.sha256') != ''`)
+	if err != nil {
+		return nil, fmt.Errorf("query build objects: %w", err)
+	}
+	defer rows.Close()
+
+	live := map[string]bool{}
+	for rows.Next() {
+		var sum string
+		if err := rows.Scan(&sum); err != nil {
+			return nil, fmt.Errorf("scan build object: %w", err)
+		}
+		live[sum] = true
+	}
+	return live, rows.Err()
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func TestBookBuilds(t *testing.T) {
+	database := setupTestDB(t)
+
+	coll := &models.Collection{CollectionName: "Weather"}
+	if _, err := database.CreateCollection(coll); err != nil {
+		t.Fatalf("create collection: %v", err)
+	}
+
+	first := &models.BookBuild{
+		CollID:   coll.CollID,
+		Manifest: `{"title":"Weather"}`,
+		Inputs: []models.BuildInput{
+			{Key: "work:1", Label: "Rain", Path: "/tmp/1.pdf", SHA256: "aa", Size: 10},
+			{Key: "work:2", Label: "Snow", Path: "/tmp/2.pdf", SHA256: "bb", Size: 20},
+		},
+		TotalPages: 12,
+	}
+	if err := database.AddBookBuild(first); err != nil {
+		t.Fatalf("add build: %v", err)
+	}
+	second := &models.BookBuild{CollID: coll.CollID, Manifest: "{}", Inputs: []models.BuildInput{}, RebuiltFrom: &first.BuildID}
+	if err := database.AddBookBuild(second); err != nil {
+		t.Fatalf("add build: %v", err)
+	}
+
+	builds, err := database.ListBookBuilds(coll.CollID)
+	if err != nil {
+		t.Fatalf("list builds: %v", err)
+	}
+	if len(builds) != 2 || builds[0].BuildID != second.BuildID || builds[1].InputCount != 2 {
+		t.Fatalf("unexpected builds %+v", builds)
+	}
+	if builds[0].RebuiltFrom == nil || *builds[0].RebuiltFrom != first.BuildID {
+		t.Errorf("rebuild should point at build %d, got %v", first.BuildID, builds[0].RebuiltFrom)
+	}
+
+	got, err := database.GetBookBuild(first.BuildID)
+	if err != nil {
+		t.Fatalf("get build: %v", err)
+	}
+	if got == nil || len(got.Inputs) != 2 || got.Inputs[1].SHA256 != "bb" || got.TotalPages != 12 {
+		t.Errorf("unexpected build %+v", got)
+	}
+	if missing, err := database.GetBookBuild(999); err != nil || missing != nil {
+		t.Errorf("missing build = %v, %v", missing, err)
+	}
+}
+
+func TestPruneBookBuilds(t *testing.T) {
+	database := setupTestDB(t)
+
+	var colls []int64
+	for _, name := range []string{"Weather", "Seasons"} {
+		coll := &models.Collection{CollectionName: name}
+		if _, err := database.CreateCollection(coll); err != nil {
+			t.Fatalf("create collection: %v", err)
+		}
+		colls = append(colls, coll.CollID)
+	}
+	add := func(collID int64, rebuiltFrom *int64, sums ...string) int64 {
+		b := &models.BookBuild{CollID: collID, Manifest: "{}", Inputs: []models.BuildInput{}, RebuiltFrom: rebuiltFrom}
+		for _, sum := range sums {
+			b.Inputs = append(b.Inputs, models.BuildInput{Key: "work:" + sum, SHA256: sum})
+		}
+		if err := database.AddBookBuild(b); err != nil {
+			t.Fatalf("add build: %v", err)
+		}
+		return b.BuildID
+	}
+
+	oldest := add(colls[0], nil, "aa", "bb")
+	add(colls[0], nil, "bb", "cc")
+	kept := add(colls[0], &oldest, "cc", "")
+	other := add(colls[1], nil, "dd")
+
+	removed, err := database.PruneBookBuilds(colls[0], 2)
+	if err != nil {
+		t.Fatalf("prune builds: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("want 1 build removed, got %d", removed)
+	}
+	if b, _ := database.GetBookBuild(oldest); b != nil {
+		t.Error("the oldest build should be gone")
+	}
+	if b, _ := database.GetBookBuild(kept); b == nil || b.RebuiltFrom != nil {
+		t.Errorf("a rebuild of a pruned build should be kept without its link, got %+v", b)
+	}
+	if b, _ := database.GetBookBuild(other); b == nil {
+		t.Error("builds of other collections should be left alone")
+	}
+
+	live, err := database.BookBuildObjects()
+	if err != nil {
+		t.Fatalf("build objects: %v", err)
+	}
+	want := map[string]bool{"bb": true, "cc": true, "dd": true}
+	if len(live) != len(want) {
+		t.Errorf("want objects %v, got %v", want, live)
+	}
+	for sum := range want {
+		if !live[sum] {
+			t.Errorf("object %s should still be referenced", sum)
+		}
+	}
+}
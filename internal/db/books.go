@@ -8,7 +8,10 @@ import (
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
 )
 
-func (db *DB) CreateBook(b *models.Book) error {
+func (db *DB) CreateBook(b *models.Book) (err error) {
+	db, end := db.auditOp("Create book")
+	defer end(&err)
+
 	now := time.Now().Format(time.RFC3339)
 	query := `INSERT INTO Books (
 		collID, title, subtitle, author, copyright, dedication, afterword,
@@ -18,10 +21,10 @@ func (db *DB) CreateBook(b *models.Book) error {
 		title_offset_y, subtitle_offset_y, author_offset_y,
 		publisher, background_color,
 		works_start_recto, verso_header, recto_header, page_number_position, suppress_page_numbers,
-		book_type, selected_parts,
+		book_type, selected_parts, first_line_index, toc_depth,
 		kdp_uploaded, kdp_previewed, kdp_proof_ordered, kdp_published, amazon_url, last_published,
 		created_at, updated_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	result, err := db.conn.Exec(query,
 		b.CollID, b.Title, b.Subtitle, b.Author, b.Copyright, b.Dedication, b.Afterword,
@@ -31,7 +34,7 @@ func (db *DB) CreateBook(b *models.Book) error {
 		b.TitleOffsetY, b.SubtitleOffsetY, b.AuthorOffsetY,
 		b.Publisher, b.BackgroundColor,
 		b.WorksStartRecto, b.VersoHeader, b.RectoHeader, b.PageNumberPosition, b.SuppressPageNumbers,
-		b.BookType, b.SelectedParts,
+		b.BookType, b.SelectedParts, b.FirstLineIndex, b.TOCDepth,
 		b.KdpUploaded, b.KdpPreviewed, b.KdpProofOrdered, b.KdpPublished, b.AmazonUrl, b.LastPublished,
 		now, now,
 	)
@@ -60,7 +63,7 @@ func (db *DB) GetBook(id int64) (*models.Book, error) {
 		COALESCE(recto_header, 'essay_title') as recto_header,
 		COALESCE(page_number_position, 'centered') as page_number_position,
 		COALESCE(suppress_page_numbers, 'never') as suppress_page_numbers,
-		COALESCE(book_type, 'prose') as book_type, selected_parts,
+		COALESCE(book_type, 'prose') as book_type, selected_parts, first_line_index, COALESCE(toc_depth, 1) as toc_depth,
 		kdp_uploaded, kdp_previewed, kdp_proof_ordered, kdp_published, amazon_url, last_published,
 		COALESCE(identity_hidden, 0) as identity_hidden,
 		created_at, updated_at
@@ -77,7 +80,7 @@ func (db *DB) GetBook(id int64) (*models.Book, error) {
 		&b.Publisher, &b.BackgroundColor,
 		&b.WorksStartRecto, &b.VersoHeader, &b.RectoHeader,
 		&b.PageNumberPosition, &b.SuppressPageNumbers,
-		&b.BookType, &b.SelectedParts,
+		&b.BookType, &b.SelectedParts, &b.FirstLineIndex, &b.TOCDepth,
 		&b.KdpUploaded, &b.KdpPreviewed, &b.KdpProofOrdered, &b.KdpPublished, &b.AmazonUrl, &b.LastPublished,
 		&b.IdentityHidden,
 		&b.CreatedAt, &b.ModifiedAt,
@@ -102,7 +105,7 @@ func (db *DB) GetBookByCollection(collID int64) (*models.Book, error) {
 		COALESCE(recto_header, 'essay_title') as recto_header,
 		COALESCE(page_number_position, 'centered') as page_number_position,
 		COALESCE(suppress_page_numbers, 'never') as suppress_page_numbers,
-		COALESCE(book_type, 'prose') as book_type, selected_parts,
+		COALESCE(book_type, 'prose') as book_type, selected_parts, first_line_index, COALESCE(toc_depth, 1) as toc_depth,
 		kdp_uploaded, kdp_previewed, kdp_proof_ordered, kdp_published, amazon_url, last_published,
 		COALESCE(identity_hidden, 0) as identity_hidden,
 		created_at, updated_at
@@ -119,7 +122,7 @@ func (db *DB) GetBookByCollection(collID int64) (*models.Book, error) {
 		&b.Publisher, &b.BackgroundColor,
 		&b.WorksStartRecto, &b.VersoHeader, &b.RectoHeader,
 		&b.PageNumberPosition, &b.SuppressPageNumbers,
-		&b.BookType, &b.SelectedParts,
+		&b.BookType, &b.SelectedParts, &b.FirstLineIndex, &b.TOCDepth,
 		&b.KdpUploaded, &b.KdpPreviewed, &b.KdpProofOrdered, &b.KdpPublished, &b.AmazonUrl, &b.LastPublished,
 		&b.IdentityHidden,
 		&b.CreatedAt, &b.ModifiedAt,
@@ -133,7 +136,10 @@ func (db *DB) GetBookByCollection(collID int64) (*models.Book, error) {
 	return b, nil
 }
 
-func (db *DB) UpdateBook(b *models.Book) error {
+func (db *DB) UpdateBook(b *models.Book) (err error) {
+	db, end := db.auditOp("Update book")
+	defer end(&err)
+
 	query := `UPDATE Books SET
 		title = ?, subtitle = ?, author = ?, copyright = ?, dedication = ?, afterword = ?,
 		acknowledgements = ?, about_author = ?, cover_path = ?,
@@ -144,13 +150,13 @@ func (db *DB) UpdateBook(b *models.Book) error {
 		publisher = ?, background_color = ?,
 		works_start_recto = ?, verso_header = ?, recto_header = ?,
 		page_number_position = ?, suppress_page_numbers = ?,
-		book_type = ?, selected_parts = ?,
+		book_type = ?, selected_parts = ?, first_line_index = ?, toc_depth = ?,
 		kdp_uploaded = ?, kdp_previewed = ?, kdp_proof_ordered = ?, kdp_published = ?, amazon_url = ?, last_published = ?,
 		identity_hidden = ?,
 		updated_at = CURRENT_TIMESTAMP
 		WHERE bookID = ?`
 
-	_, err := db.conn.Exec(query,
+	_, err = db.conn.Exec(query,
 		b.Title, b.Subtitle, b.Author, b.Copyright, b.Dedication, b.Afterword,
 		b.Acknowledgements, b.AboutAuthor, b.CoverPath,
 		b.FrontCoverPath, b.BackCoverPath, b.SpineText,
@@ -160,7 +166,7 @@ func (db *DB) UpdateBook(b *models.Book) error {
 		b.Publisher, b.BackgroundColor,
 		b.WorksStartRecto, b.VersoHeader, b.RectoHeader,
 		b.PageNumberPosition, b.SuppressPageNumbers,
-		b.BookType, b.SelectedParts,
+		b.BookType, b.SelectedParts, b.FirstLineIndex, b.TOCDepth,
 		b.KdpUploaded, b.KdpPreviewed, b.KdpProofOrdered, b.KdpPublished, b.AmazonUrl, b.LastPublished,
 		b.IdentityHidden,
 		b.BookID,
@@ -178,20 +184,26 @@ func (db *DB) UpdateBook(b *models.Book) error {
 	return nil
 }
 
-func (db *DB) DeleteBook(id int64) error {
-	_, err := db.conn.Exec("DELETE FROM Books WHERE bookID = ?", id)
+func (db *DB) DeleteBook(id int64) (err error) {
+	db, end := db.auditOp("Delete book")
+	defer end(&err)
+
+	_, err = db.conn.Exec("DELETE FROM Books WHERE bookID = ?", id)
 	if err != nil {
 		return fmt.Errorf("delete book: %w", err)
 	}
 	return nil
 }
 
-func (db *DB) SetCollectionIsBook(collID int64, isBook bool) error {
+func (db *DB) SetCollectionIsBook(collID int64, isBook bool) (err error) {
+	db, end := db.auditOp("Set collection book flag")
+	defer end(&err)
+
 	value := 0
 	if isBook {
 		value = 1
 	}
-	_, err := db.conn.Exec("UPDATE Collections SET is_book = ? WHERE collID = ?", value, collID)
+	_, err = db.conn.Exec("UPDATE Collections SET is_book = ? WHERE collID = ?", value, collID)
 	if err != nil {
 		return fmt.Errorf("set collection is_book: %w", err)
 	}
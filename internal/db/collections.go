@@ -3,28 +3,36 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/smartquery"
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/validation"
 )
 
 const excludeDeletedFilter = ` AND (w.attributes IS NULL OR w.attributes NOT LIKE '%deleted%')`
 
-func (db *DB) CreateCollection(c *models.Collection) (*validation.ValidationResult, error) {
+func (db *DB) CreateCollection(c *models.Collection) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Create collection")
+	defer end(&err)
+
 	// Validate the collection
 	result := db.validateCollection(c)
 	if !result.IsValid() {
 		return &result, nil
 	}
 
+	if c.Entity == "" {
+		c.Entity = smartquery.Works.Name
+	}
 	now := time.Now().Format(time.RFC3339)
 	query := `INSERT INTO Collections (
-		collection_name, type, attributes, created_at, modified_at
-	) VALUES (?, ?, ?, ?, ?)`
+		collection_name, type, attributes, smart_query, entity, created_at, modified_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?)`
 
 	sqlResult, err := db.conn.Exec(query,
-		c.CollectionName, c.Type, c.Attributes, now, now,
+		c.CollectionName, c.Type, c.Attributes, c.SmartQuery, c.Entity, now, now,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("insert collection: %w", err)
@@ -42,13 +50,13 @@ func (db *DB) CreateCollection(c *models.Collection) (*validation.ValidationResu
 
 func (db *DB) GetCollection(id int64) (*models.Collection, error) {
 	query := `SELECT collID, collection_name, type, attributes,
-		created_at, modified_at, is_book, smart_query
+		created_at, modified_at, is_book, smart_query, entity
 		FROM Collections WHERE collID = ?`
 
 	c := &models.Collection{}
 	err := db.conn.QueryRow(query, id).Scan(
 		&c.CollID, &c.CollectionName, &c.Type, &c.Attributes,
-		&c.CreatedAt, &c.ModifiedAt, &c.IsBook, &c.SmartQuery,
+		&c.CreatedAt, &c.ModifiedAt, &c.IsBook, &c.SmartQuery, &c.Entity,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -71,6 +79,50 @@ func (db *DB) GetSmartQuery(collID int64) (*string, error) {
 	return smartQuery, nil
 }
 
+// collectionEntity returns what a collection holds
+func (db *DB) collectionEntity(collID int64) (string, error) {
+	var entity string
+	err := db.conn.QueryRow(`SELECT entity FROM Collections WHERE collID = ?`, collID).Scan(&entity)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("collection with ID %d does not exist", collID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("get collection entity: %w", err)
+	}
+	return entity, nil
+}
+
+// SetSmartQuery stores a collection's smart query after checking it
+// compiles for the collection's entity. An empty query makes a collection
+// of works manual again; collections of anything else need a query.
+func (db *DB) SetSmartQuery(collID int64, query string) (err error) {
+	db, end := db.auditOp("Update smart query")
+	defer end(&err)
+
+	entity, err := db.collectionEntity(collID)
+	if err != nil {
+		return err
+	}
+	var value *string
+	if strings.TrimSpace(query) != "" {
+		schema, err := smartquery.SchemaFor(entity)
+		if err != nil {
+			return err
+		}
+		if err := smartquery.Validate(query, schema); err != nil {
+			return err
+		}
+		value = &query
+	} else if entity != smartquery.Works.Name {
+		return fmt.Errorf("a collection of %s needs a smart query", entity)
+	}
+	_, err = db.conn.Exec(`UPDATE Collections SET smart_query = ?, modified_at = CURRENT_TIMESTAMP WHERE collID = ?`, value, collID)
+	if err != nil {
+		return fmt.Errorf("set smart query: %w", err)
+	}
+	return nil
+}
+
 func (db *DB) IsSmartCollection(collID int64) (bool, error) {
 	sq, err := db.GetSmartQuery(collID)
 	if err != nil {
@@ -79,7 +131,10 @@ func (db *DB) IsSmartCollection(collID int64) (bool, error) {
 	return sq != nil && *sq != "", nil
 }
 
-func (db *DB) UpdateCollection(c *models.Collection) (*validation.ValidationResult, error) {
+func (db *DB) UpdateCollection(c *models.Collection) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Update collection")
+	defer end(&err)
+
 	// Validate the collection
 	result := db.validateCollection(c)
 	if !result.IsValid() {
@@ -90,7 +145,7 @@ func (db *DB) UpdateCollection(c *models.Collection) (*validation.ValidationResu
 		collection_name = ?, type = ?, attributes = ?, modified_at = CURRENT_TIMESTAMP
 		WHERE collID = ?`
 
-	_, err := db.conn.Exec(query,
+	_, err = db.conn.Exec(query,
 		c.CollectionName, c.Type, c.Attributes, c.CollID,
 	)
 	if err != nil {
@@ -109,7 +164,7 @@ func (db *DB) UpdateCollection(c *models.Collection) (*validation.ValidationResu
 
 func (db *DB) ListCollections(showDeleted bool) ([]models.CollectionView, error) {
 	query := `SELECT c.collID, c.collection_name, c.type, c.attributes,
-		c.created_at, c.modified_at, c.is_book, c.smart_query,
+		c.created_at, c.modified_at, c.is_book, c.smart_query, c.entity,
 		COALESCE((SELECT COUNT(*) FROM CollectionDetails cd WHERE cd.collID = c.collID), 0) as n_items,
 		b.front_cover_path
 		FROM Collections c
@@ -132,7 +187,7 @@ func (db *DB) ListCollections(showDeleted bool) ([]models.CollectionView, error)
 		var c models.CollectionView
 		err := rows.Scan(
 			&c.CollID, &c.CollectionName, &c.Type, &c.Attributes,
-			&c.CreatedAt, &c.ModifiedAt, &c.IsBook, &c.SmartQuery, &c.NItems, &c.FrontCoverPath,
+			&c.CreatedAt, &c.ModifiedAt, &c.IsBook, &c.SmartQuery, &c.Entity, &c.NItems, &c.FrontCoverPath,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan collection: %w", err)
@@ -146,12 +201,12 @@ func (db *DB) ListCollections(showDeleted bool) ([]models.CollectionView, error)
 
 	for i := range cols {
 		if cols[i].SmartQuery != nil && *cols[i].SmartQuery != "" {
-			var count int
-			countQuery := `SELECT COUNT(*) FROM Works w WHERE ` + *cols[i].SmartQuery
-			if !showDeleted {
-				countQuery += excludeDeletedFilter
+			from, args, err := smartQuerySQL(cols[i].Entity, *cols[i].SmartQuery, showDeleted)
+			if err != nil {
+				continue
 			}
-			if err := db.conn.QueryRow(countQuery).Scan(&count); err == nil {
+			var count int
+			if err := db.conn.QueryRow(`SELECT COUNT(*) `+from, args...).Scan(&count); err == nil {
 				cols[i].NItems = count
 			}
 		}
@@ -160,18 +215,21 @@ func (db *DB) ListCollections(showDeleted bool) ([]models.CollectionView, error)
 	return cols, nil
 }
 
-func (db *DB) AddWorkToCollection(collID, workID int64) error {
-	// Validate that the collection exists
-	var exists bool
-	err := db.conn.QueryRow(`SELECT EXISTS(SELECT 1 FROM Collections WHERE collID = ?)`, collID).Scan(&exists)
+func (db *DB) AddWorkToCollection(collID, workID int64) (err error) {
+	db, end := db.auditOp("Add work to collection")
+	defer end(&err)
+
+	// Validate that the collection exists and holds works
+	entity, err := db.collectionEntity(collID)
 	if err != nil {
-		return fmt.Errorf("check collection exists: %w", err)
+		return err
 	}
-	if !exists {
-		return fmt.Errorf("collection with ID %d does not exist", collID)
+	if entity != smartquery.Works.Name {
+		return fmt.Errorf("collection with ID %d holds %s, not works", collID, entity)
 	}
 
 	// Validate that the work exists
+	var exists bool
 	err = db.conn.QueryRow(`SELECT EXISTS(SELECT 1 FROM Works WHERE workID = ?)`, workID).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("check work exists: %w", err)
@@ -205,9 +263,12 @@ func (db *DB) AddWorkToCollection(collID, workID int64) error {
 	return nil
 }
 
-func (db *DB) RemoveWorkFromCollection(collID, workID int64) error {
+func (db *DB) RemoveWorkFromCollection(collID, workID int64) (err error) {
+	db, end := db.auditOp("Remove work from collection")
+	defer end(&err)
+
 	query := `DELETE FROM CollectionDetails WHERE collID = ? AND workID = ?`
-	_, err := db.conn.Exec(query, collID, workID)
+	_, err = db.conn.Exec(query, collID, workID)
 	if err != nil {
 		return fmt.Errorf("remove work from collection: %w", err)
 	}
@@ -250,13 +311,13 @@ func (db *DB) GetWorkCollections(workID int64) ([]models.CollectionDetail, error
 }
 
 func (db *DB) GetCollectionWorks(collID int64, showDeleted bool) ([]models.CollectionWork, error) {
-	smartQuery, err := db.GetSmartQuery(collID)
+	smartQuery, err := db.smartCollection(collID, smartquery.Works.Name)
 	if err != nil {
-		return nil, fmt.Errorf("check smart collection: %w", err)
+		return nil, err
 	}
 
 	if smartQuery != nil && *smartQuery != "" {
-		return db.getSmartCollectionWorks(*smartQuery, showDeleted)
+		return db.SmartQueryWorks(*smartQuery, showDeleted)
 	}
 
 	query := `SELECT w.workID, w.title, w.type, w.year, w.status, w.quality, w.doc_type,
@@ -295,20 +356,20 @@ func (db *DB) GetCollectionWorks(collID int64, showDeleted bool) ([]models.Colle
 	return works, rows.Err()
 }
 
-func (db *DB) getSmartCollectionWorks(smartQuery string, showDeleted bool) ([]models.CollectionWork, error) {
+// SmartQueryWorks returns the works a smart query selects, by title. The
+// query is compiled with its values bound, never pasted into the SQL.
+func (db *DB) SmartQueryWorks(smartQuery string, showDeleted bool) ([]models.CollectionWork, error) {
+	from, args, err := smartQuerySQL(smartquery.Works.Name, smartQuery, showDeleted)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `SELECT w.workID, w.title, w.type, w.year, w.status, w.quality, w.doc_type,
 		w.path, w.draft, w.n_words, w.course_name, w.attributes, w.access_date, w.created_at, w.modified_at,
 		0, COALESCE(w.is_marked, 0), 0, COALESCE(w.skip_audits, 0)
-		FROM Works w
-		WHERE ` + smartQuery
+		` + from + ` ORDER BY w.title`
 
-	if !showDeleted {
-		query += excludeDeletedFilter
-	}
-
-	query += ` ORDER BY w.title`
-
-	rows, err := db.conn.Query(query)
+	rows, err := db.conn.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query smart collection works: %w", err)
 	}
@@ -332,12 +393,15 @@ func (db *DB) getSmartCollectionWorks(smartQuery string, showDeleted bool) ([]mo
 }
 
 // SetWorkSuppressed sets or clears the suppressed flag for a work in a collection
-func (db *DB) SetWorkSuppressed(collID, workID int64, suppressed bool) error {
+func (db *DB) SetWorkSuppressed(collID, workID int64, suppressed bool) (err error) {
+	db, end := db.auditOp("Suppress work in collection")
+	defer end(&err)
+
 	suppressedVal := 0
 	if suppressed {
 		suppressedVal = 1
 	}
-	_, err := db.conn.Exec(
+	_, err = db.conn.Exec(
 		`UPDATE CollectionDetails SET is_suppressed = ? WHERE collID = ? AND workID = ?`,
 		suppressedVal, collID, workID,
 	)
@@ -349,14 +413,28 @@ func (db *DB) SetWorkSuppressed(collID, workID int64, suppressed bool) error {
 	return nil
 }
 
-func (db *DB) ReorderCollectionWorks(collID int64, workIDs []int64) error {
-	tx, err := db.conn.Begin()
-	if err != nil {
-		return fmt.Errorf("begin transaction: %w", err)
+// SetCollectionSuppressed sets or clears the suppressed flag on every work
+// in a collection
+func (db *DB) SetCollectionSuppressed(collID int64, suppressed bool) (err error) {
+	db, end := db.auditOp("Suppress collection works")
+	defer end(&err)
+
+	suppressedVal := 0
+	if suppressed {
+		suppressedVal = 1
 	}
-	defer func() { _ = tx.Rollback() }()
+	if _, err := db.conn.Exec(`UPDATE CollectionDetails SET is_suppressed = ? WHERE collID = ?`, suppressedVal, collID); err != nil {
+		return fmt.Errorf("update suppressed: %w", err)
+	}
+	_, _ = db.conn.Exec(`UPDATE Collections SET modified_at = CURRENT_TIMESTAMP WHERE collID = ?`, collID)
+	return nil
+}
 
-	stmt, err := tx.Prepare(`UPDATE CollectionDetails SET position = ? WHERE collID = ? AND workID = ?`)
+func (db *DB) ReorderCollectionWorks(collID int64, workIDs []int64) (err error) {
+	db, end := db.auditOp("Reorder collection")
+	defer end(&err)
+
+	stmt, err := db.conn.Prepare(`UPDATE CollectionDetails SET position = ? WHERE collID = ? AND workID = ?`)
 	if err != nil {
 		return fmt.Errorf("prepare statement: %w", err)
 	}
@@ -370,16 +448,11 @@ func (db *DB) ReorderCollectionWorks(collID int64, workIDs []int64) error {
 	}
 
 	// Update the collection's modified_at timestamp
-	_, err = tx.Exec(`UPDATE Collections SET modified_at = datetime('now') WHERE collID = ?`, collID)
+	_, err = db.conn.Exec(`UPDATE Collections SET modified_at = datetime('now') WHERE collID = ?`, collID)
 	if err != nil {
 		return fmt.Errorf("update collection modified_at: %w", err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit transaction: %w", err)
-	}
-
-	// Recalculate part_ids after reorder (outside transaction since it's already committed)
 	if err := db.RecalculatePartIDs(collID); err != nil {
 		return fmt.Errorf("recalculate part_ids: %w", err)
 	}
@@ -387,7 +460,10 @@ func (db *DB) ReorderCollectionWorks(collID int64, workIDs []int64) error {
 	return nil
 }
 
-func (db *DB) DeleteCollection(id int64) error {
+func (db *DB) DeleteCollection(id int64) (err error) {
+	db, end := db.auditOp("Delete collection")
+	defer end(&err)
+
 	collection, err := db.GetCollection(id)
 	if err != nil {
 		return fmt.Errorf("get collection: %w", err)
@@ -414,7 +490,10 @@ func (db *DB) DeleteCollection(id int64) error {
 	return nil
 }
 
-func (db *DB) UndeleteCollection(id int64) (*validation.ValidationResult, error) {
+func (db *DB) UndeleteCollection(id int64) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Undelete collection")
+	defer end(&err)
+
 	collection, err := db.GetCollection(id)
 	if err != nil {
 		return nil, fmt.Errorf("get collection: %w", err)
@@ -505,9 +584,12 @@ func (db *DB) GetCollectionDeleteConfirmation(collID int64) (*DeleteConfirmation
 }
 
 // DeleteCollectionPermanent permanently deletes a collection and all its orphaned data
-func (db *DB) DeleteCollectionPermanent(collID int64) error {
+func (db *DB) DeleteCollectionPermanent(collID int64) (err error) {
+	db, end := db.auditOp("Permanently delete collection")
+	defer end(&err)
+
 	// Delete notes manually (polymorphic FK)
-	_, err := db.conn.Exec(`DELETE FROM Notes WHERE entity_type = 'collection' AND entity_id = ?`, collID)
+	_, err = db.conn.Exec(`DELETE FROM Notes WHERE entity_type = 'collection' AND entity_id = ?`, collID)
 	if err != nil {
 		return fmt.Errorf("delete collection notes: %w", err)
 	}
@@ -525,7 +607,10 @@ func (db *DB) DeleteCollectionPermanent(collID int64) error {
 // based on their position relative to Section-type works.
 // part_id = 0 for works before any Section
 // part_id = Section's workID for works after that Section
-func (db *DB) RecalculatePartIDs(collID int64) error {
+func (db *DB) RecalculatePartIDs(collID int64) (err error) {
+	db, end := db.auditOp("Recalculate parts")
+	defer end(&err)
+
 	// Get works ordered by position with their type
 	rows, err := db.conn.Query(`
 		SELECT cd.workID, w.type
@@ -569,35 +654,3 @@ func (db *DB) RecalculatePartIDs(collID int64) error {
 
 	return nil
 }
-
-// GetWorkPartInfo returns the collID and part_id for all collections containing the given work.
-// This is used by the file watcher to know which part caches to invalidate.
-func (db *DB) GetWorkPartInfo(workID int64) ([]struct {
-	CollID int64
-	PartID int64
-}, error) {
-	rows, err := db.conn.Query(`
-		SELECT collID, part_id
-		FROM CollectionDetails
-		WHERE workID = ?`, workID)
-	if err != nil {
-		return nil, fmt.Errorf("query work part info: %w", err)
-	}
-	defer rows.Close()
-
-	var result []struct {
-		CollID int64
-		PartID int64
-	}
-	for rows.Next() {
-		var info struct {
-			CollID int64
-			PartID int64
-		}
-		if err := rows.Scan(&info.CollID, &info.PartID); err != nil {
-			return nil, fmt.Errorf("scan part info: %w", err)
-		}
-		result = append(result, info)
-	}
-	return result, rows.Err()
-}
@@ -0,0 +1,304 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func TestSmartQueryWorks(t *testing.T) {
+	database := setupTestDB(t)
+
+	words := func(n int) *int { return &n }
+	year := func(y string) *string { return &y }
+	works := []*models.Work{
+		{Title: "Rain", Type: "Poem", Status: "Out", Quality: "Best", Year: year("2021"), NWords: words(120)},
+		{Title: "Snow", Type: "Poem", Status: "Out", Quality: "Good", Year: year("2022"), NWords: words(90)},
+		{Title: "Hail", Type: "Essay", Status: "Out", Quality: "Better", Year: year("2023"), NWords: words(2400)},
+		{Title: "Fog", Type: "Poem", Status: "Working", Quality: "Better", Year: year("2019"), NWords: words(80)},
+	}
+	for _, w := range works {
+		if _, err := database.CreateWork(w); err != nil {
+			t.Fatalf("create work: %v", err)
+		}
+	}
+	book := &models.Collection{CollectionName: "Book X"}
+	if _, err := database.CreateCollection(book); err != nil {
+		t.Fatalf("create collection: %v", err)
+	}
+	if err := database.AddWorkToCollection(book.CollID, works[0].WorkID); err != nil {
+		t.Fatalf("add work: %v", err)
+	}
+
+	titles := func(query string) string {
+		t.Helper()
+		list, err := database.SmartQueryWorks(query, false)
+		if err != nil {
+			t.Fatalf("SmartQueryWorks(%q): %v", query, err)
+		}
+		var out []string
+		for _, w := range list {
+			out = append(out, w.Title)
+		}
+		return strings.Join(out, ",")
+	}
+
+	if got := titles(`type:poem status:Out year>=2020 quality:Best,Better,Good words<300 -in:"Book X"`); got != "Snow" {
+		t.Errorf("got %q, want Snow", got)
+	}
+	if got := titles(`quality:Better`); got != "Fog,Hail" {
+		t.Errorf("got %q, want Fog,Hail", got)
+	}
+	if got := titles(`in:"book x"`); got != "Rain" {
+		t.Errorf("got %q, want Rain", got)
+	}
+	if got := titles(`"ai"`); got != "Hail,Rain" {
+		t.Errorf("got %q, want Hail,Rain", got)
+	}
+	if _, err := database.SmartQueryWorks(`status:Out'; DROP TABLE Works; --`, false); err != nil {
+		t.Fatalf("a hostile value should be a harmless value: %v", err)
+	}
+	if got := titles(`id>0`); got != "Fog,Hail,Rain,Snow" {
+		t.Errorf("Works should be intact, got %q", got)
+	}
+
+	// The migration rewrote the Not Collected collection in the language
+	colls, err := database.FindCollectionsByName("Not Collected")
+	if err != nil || len(colls) != 1 {
+		t.Fatalf("Not Collected: %v %v", colls, err)
+	}
+	notCollected := colls[0].CollID
+	if sq, _ := database.GetSmartQuery(notCollected); sq == nil || *sq != "-has:collection" {
+		t.Errorf("Not Collected query = %v, want -has:collection", sq)
+	}
+	members, err := database.GetCollectionWorks(notCollected, false)
+	if err != nil || len(members) != 3 {
+		t.Errorf("Not Collected has %d works (%v), want 3", len(members), err)
+	}
+
+	preview, err := database.PreviewSmartQuery("works", `type:Poem   status:"Out"`, 1, false)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if preview.Query != "type:Poem status:Out" || preview.Count != 2 || len(preview.Works) != 1 || preview.Error != "" {
+		t.Errorf("unexpected preview %+v", preview)
+	}
+	preview, err = database.PreviewSmartQuery("works", `type:Poem words>lots`, 10, false)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if preview.ErrorPos != 10 || !strings.Contains(preview.Error, "whole number") || len(preview.Works) != 0 {
+		t.Errorf("expected an error at words>lots, got %+v", preview)
+	}
+
+	if err := database.SetSmartQuery(book.CollID, "colour:red"); err == nil {
+		t.Error("SetSmartQuery should reject an unknown field")
+	}
+	if err := database.SetSmartQuery(book.CollID, "type:Essay"); err != nil {
+		t.Fatalf("SetSmartQuery: %v", err)
+	}
+	list, err := database.ListCollections(false)
+	if err != nil {
+		t.Fatalf("list collections: %v", err)
+	}
+	for _, c := range list {
+		if c.CollID == book.CollID && c.NItems != 1 {
+			t.Errorf("smart collection counts %d works, want 1", c.NItems)
+		}
+	}
+}
+
+func TestSmartQueryWorksIgnoresCollectionSubmissions(t *testing.T) {
+	database := setupTestDB(t)
+
+	coll := &models.Collection{CollectionName: "Chapbook"}
+	if _, err := database.CreateCollection(coll); err != nil {
+		t.Fatalf("create collection: %v", err)
+	}
+	// Make a work that shares the collection's ID, and one more
+	var shared, other *models.Work
+	for other == nil {
+		w := &models.Work{Title: "Work", Type: "Poem", Status: "Out", Quality: "Good"}
+		if _, err := database.CreateWork(w); err != nil {
+			t.Fatalf("create work: %v", err)
+		}
+		switch {
+		case w.WorkID == coll.CollID:
+			shared = w
+		case shared != nil || w.WorkID > coll.CollID:
+			other = w
+		}
+	}
+	if shared == nil {
+		t.Fatalf("no work shares collection ID %d", coll.CollID)
+	}
+
+	org := &models.Organization{Name: "Alpha", Status: "Open", Type: "Journal"}
+	if _, err := database.CreateOrganization(org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	accepted := "Accepted"
+	if _, err := database.CreateSubmission(&models.Submission{
+		WorkID: coll.CollID, OrgID: org.OrgID, IsCollection: true, ResponseType: &accepted,
+	}); err != nil {
+		t.Fatalf("create submission: %v", err)
+	}
+	if _, err := database.CreateSubmission(&models.Submission{WorkID: other.WorkID, OrgID: org.OrgID}); err != nil {
+		t.Fatalf("create submission: %v", err)
+	}
+
+	for _, tt := range []struct {
+		query string
+		want  []int64
+	}{
+		{"has:submission", []int64{other.WorkID}},
+		{"has:pending", []int64{other.WorkID}},
+		{"has:acceptance", nil},
+	} {
+		list, err := database.SmartQueryWorks(tt.query, false)
+		if err != nil {
+			t.Fatalf("SmartQueryWorks(%q): %v", tt.query, err)
+		}
+		var ids []int64
+		for _, w := range list {
+			ids = append(ids, w.WorkID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: got works %v, want %v", tt.query, ids, tt.want)
+		}
+	}
+}
+
+func TestSmartQuerySubmissionsAndOrganizations(t *testing.T) {
+	database := setupTestDB(t)
+
+	work := &models.Work{Title: "Rain", Type: "Poem", Status: "Out"}
+	if _, err := database.CreateWork(work); err != nil {
+		t.Fatalf("create work: %v", err)
+	}
+	ranking := func(n int) *int { return &n }
+	accepts := func(s string) *string { return &s }
+	orgs := []*models.Organization{
+		{Name: "Alpha Review", Status: "Open", Type: "Journal", Accepts: accepts("Poetry, Fiction"), Ranking: ranking(2)},
+		{Name: "Beta Quarterly", Status: "Open", Type: "Journal", Accepts: accepts("poetry"), Ranking: ranking(3)},
+		{Name: "Gamma", Status: "Open", Type: "Journal", Accepts: accepts("Poetry"), Ranking: ranking(1)},
+		{Name: "Delta", Status: "Closed", Type: "Journal", Accepts: accepts("Poetry"), Ranking: ranking(1)},
+	}
+	for _, o := range orgs {
+		if _, err := database.CreateOrganization(o); err != nil {
+			t.Fatalf("create organization: %v", err)
+		}
+	}
+
+	daysAgo := func(n int) *string {
+		d := time.Now().AddDate(0, 0, -n).Format("2006-01-02")
+		return &d
+	}
+	accepted := "Accepted"
+	subs := []*models.Submission{
+		{WorkID: work.WorkID, OrgID: orgs[0].OrgID, SubmissionDate: daysAgo(200)},
+		{WorkID: work.WorkID, OrgID: orgs[1].OrgID, SubmissionDate: daysAgo(30)},
+		{WorkID: work.WorkID, OrgID: orgs[3].OrgID, SubmissionDate: daysAgo(150), ResponseDate: daysAgo(0), ResponseType: &accepted},
+	}
+	for _, s := range subs {
+		if result, err := database.CreateSubmission(s); err != nil || !result.IsValid() {
+			t.Fatalf("create submission: %v %v", result, err)
+		}
+	}
+
+	journals := func(query string) string {
+		t.Helper()
+		list, err := database.SmartQuerySubmissions(query, false)
+		if err != nil {
+			t.Fatalf("SmartQuerySubmissions(%q): %v", query, err)
+		}
+		var out []string
+		for _, s := range list {
+			out = append(out, s.JournalName)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := journals(`is:pending age>120`); got != "Alpha Review" {
+		t.Errorf("pending > 120 days = %q, want Alpha Review", got)
+	}
+	if got := journals(`is:pending submitted<120d`); got != "Alpha Review" {
+		t.Errorf("pending since before 120 days ago = %q, want Alpha Review", got)
+	}
+	if got := journals(`is:accepted responded:thisyear`); got != "Delta" {
+		t.Errorf("accepted this year = %q, want Delta", got)
+	}
+	if got := journals(`journal:review,quarterly rain`); got != "Beta Quarterly,Alpha Review" {
+		t.Errorf("got %q, want Beta Quarterly,Alpha Review", got)
+	}
+
+	names := func(query string) string {
+		t.Helper()
+		list, err := database.SmartQueryOrganizations(query, false)
+		if err != nil {
+			t.Fatalf("SmartQueryOrganizations(%q): %v", query, err)
+		}
+		var out []string
+		for _, o := range list {
+			out = append(out, o.Name)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := names(`status:Open accepts:poetry ranking<=3 -has:submission`); got != "Gamma" {
+		t.Errorf("open, poetry, never submitted to = %q, want Gamma", got)
+	}
+	if got := names(`has:acceptance`); got != "Delta" {
+		t.Errorf("has:acceptance = %q, want Delta", got)
+	}
+
+	// Saved queries list, count and refresh like any collection
+	query := "is:pending age>120"
+	waiting := &models.Collection{CollectionName: "Waiting", Entity: "submissions", SmartQuery: &query}
+	if result, err := database.CreateCollection(waiting); err != nil || !result.IsValid() {
+		t.Fatalf("create submissions collection: %v %v", result, err)
+	}
+	bad := "words>100"
+	if result, _ := database.CreateCollection(&models.Collection{CollectionName: "Bad", Entity: "submissions", SmartQuery: &bad}); result.IsValid() {
+		t.Error("a submissions collection should reject a works field")
+	}
+	if result, _ := database.CreateCollection(&models.Collection{CollectionName: "Empty", Entity: "organizations"}); result.IsValid() {
+		t.Error("an organizations collection needs a query")
+	}
+	if err := database.SetSmartQuery(waiting.CollID, ""); err == nil {
+		t.Error("a submissions collection cannot become manual")
+	}
+	if err := database.AddWorkToCollection(waiting.CollID, work.WorkID); err == nil {
+		t.Error("works cannot be added to a submissions collection")
+	}
+	if _, err := database.GetCollectionWorks(waiting.CollID, false); err == nil {
+		t.Error("a submissions collection has no works")
+	}
+
+	list, err := database.ListCollections(false)
+	if err != nil {
+		t.Fatalf("list collections: %v", err)
+	}
+	for _, c := range list {
+		if c.CollID == waiting.CollID && (c.NItems != 1 || c.Entity != "submissions") {
+			t.Errorf("saved query lists %d %s, want 1 submissions", c.NItems, c.Entity)
+		}
+	}
+
+	if err := database.SetSmartQuery(waiting.CollID, "is:pending"); err != nil {
+		t.Fatalf("SetSmartQuery: %v", err)
+	}
+	members, err := database.GetCollectionSubmissions(waiting.CollID, false)
+	if err != nil || len(members) != 2 {
+		t.Errorf("refreshed collection has %d submissions (%v), want 2", len(members), err)
+	}
+
+	preview, err := database.PreviewSmartQuery("organizations", "ranking:1", 10, false)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if preview.Count != 2 || len(preview.Organizations) != 2 || len(preview.Works) != 0 {
+		t.Errorf("unexpected preview %+v", preview)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/smartquery"
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/validation"
 )
 
@@ -17,6 +18,24 @@ func (db *DB) validateCollection(c *models.Collection) validation.ValidationResu
 	// Field constraints
 	result.AddIfError(validation.MaxLength(c.CollectionName, 200, "collectionName"))
 
+	// A new collection may be created smart; one of anything but works
+	// must be
+	if c.CollID == 0 {
+		entity := c.Entity
+		if entity == "" {
+			entity = smartquery.Works.Name
+		}
+		if schema, err := smartquery.SchemaFor(entity); err != nil {
+			result.AddError("entity", err.Error())
+		} else if c.SmartQuery != nil && *c.SmartQuery != "" {
+			if err := smartquery.Validate(*c.SmartQuery, schema); err != nil {
+				result.AddError("smartQuery", err.Error())
+			}
+		} else if entity != smartquery.Works.Name {
+			result.AddError("smartQuery", "A collection of "+entity+" needs a smart query")
+		}
+	}
+
 	// Check for duplicate name (only if required fields are present)
 	if result.IsValid() {
 		matches, err := db.FindCollectionsByName(c.CollectionName)
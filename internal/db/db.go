@@ -14,9 +14,22 @@ const (
 	andNotDeleted   = ` AND (attributes IS NULL OR attributes NOT LIKE '%deleted%')`
 )
 
+// querier is what the methods of DB run their statements on: the
+// database, or the transaction of an audited operation
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+	Prepare(query string) (*sql.Stmt, error)
+}
+
 type DB struct {
-	conn *sql.DB
+	pool *sql.DB
+	conn querier
 	path string
+
+	// tx is set on the copy of DB that an audited operation runs on
+	tx *sql.Tx
 }
 
 func New(dbPath string) (*DB, error) {
@@ -57,18 +70,18 @@ func New(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("set WAL mode: %w", err)
 	}
 
-	return &DB{conn: conn, path: dbPath}, nil
+	return &DB{pool: conn, conn: conn, path: dbPath}, nil
 }
 
 func (db *DB) Close() error {
-	if db.conn != nil {
-		return db.conn.Close()
+	if db.pool != nil {
+		return db.pool.Close()
 	}
 	return nil
 }
 
 func (db *DB) Conn() *sql.DB {
-	return db.conn
+	return db.pool
 }
 
 func (db *DB) Path() string {
@@ -0,0 +1,23 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func setupTestDB(t *testing.T) *DB {
+	t.Helper()
+	database, err := New(filepath.Join(t.TempDir(), "works.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.InitSchemaFromFile("../migrations/sql/001_initial_schema.sql"); err != nil {
+		t.Fatalf("init schema: %v", err)
+	}
+	if err := database.RunMigrations(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return database
+}
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+const jobColumns = `jobID, kind, label, params, status, progress_current, progress_total,
+	message, result, error, log, attempts, retry_of, created_at, started_at, finished_at`
+
+// jobSummaryColumns leaves out the log and params, which can be long
+const jobSummaryColumns = `jobID, kind, label, '', status, progress_current, progress_total,
+	message, result, error, '', attempts, retry_of, created_at, started_at, finished_at`
+
+func scanJob(row rowScanner) (*models.Job, error) {
+	j := &models.Job{}
+	err := row.Scan(&j.JobID, &j.Kind, &j.Label, &j.Params, &j.Status, &j.ProgressCurrent, &j.ProgressTotal,
+		&j.Message, &j.Result, &j.Error, &j.Log, &j.Attempts, &j.RetryOf, &j.CreatedAt, &j.StartedAt, &j.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func jobTimestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// AddJob queues a job
+func (db *DB) AddJob(j *models.Job) error {
+	j.Status = models.JobQueued
+	j.CreatedAt = jobTimestamp()
+	if j.Params == "" {
+		j.Params = "{}"
+	}
+	result, err := db.conn.Exec(`INSERT INTO Jobs (kind, label, params, status, retry_of, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`, j.Kind, j.Label, j.Params, j.Status, j.RetryOf, j.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert job: %w", err)
+	}
+	j.JobID, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get last insert id: %w", err)
+	}
+	return nil
+}
+
+// GetJob returns a job with its params and log, or nil if there is none
+func (db *DB) GetJob(jobID int64) (*models.Job, error) {
+	j, err := scanJob(db.conn.QueryRow(`SELECT `+jobColumns+` FROM Jobs WHERE jobID = ?`, jobID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query job %d: %w", jobID, err)
+	}
+	return j, nil
+}
+
+// ListJobs returns jobs newest first without their logs, optionally only
+// those with a given status
+func (db *DB) ListJobs(status string, limit int) ([]models.Job, error) {
+	query := `SELECT ` + jobSummaryColumns + ` FROM Jobs`
+	args := []any{}
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY jobID DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan job: %w", err)
+		}
+		jobs = append(jobs, *j)
+	}
+	return jobs, rows.Err()
+}
+
+// ClaimNextJob marks the oldest queued job of one of the given kinds as
+// running and returns it, or nil if none is waiting
+func (db *DB) ClaimNextJob(kinds []string) (*models.Job, error) {
+	if len(kinds) == 0 {
+		return nil, nil
+	}
+	tx, err := db.pool.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	args := make([]any, len(kinds))
+	for i, k := range kinds {
+		args[i] = k
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(kinds)), ",")
+	j, err := scanJob(tx.QueryRow(`SELECT `+jobColumns+` FROM Jobs
+		WHERE status = 'queued' AND kind IN (`+placeholders+`)
+		ORDER BY jobID LIMIT 1`, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query next job: %w", err)
+	}
+
+	j.Status = models.JobRunning
+	j.Attempts++
+	j.StartedAt = jobTimestamp()
+	j.FinishedAt = ""
+	_, err = tx.Exec(`UPDATE Jobs SET status = ?, attempts = ?, started_at = ?, finished_at = ''
+		WHERE jobID = ?`, j.Status, j.Attempts, j.StartedAt, j.JobID)
+	if err != nil {
+		return nil, fmt.Errorf("claim job %d: %w", j.JobID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// UpdateJobProgress records a running job's progress and log so far
+func (db *DB) UpdateJobProgress(jobID int64, current, total int, message, log string) error {
+	_, err := db.conn.Exec(`UPDATE Jobs SET progress_current = ?, progress_total = ?, message = ?, log = ?
+		WHERE jobID = ?`, current, total, message, log, jobID)
+	if err != nil {
+		return fmt.Errorf("update job %d: %w", jobID, err)
+	}
+	return nil
+}
+
+// FinishJob records how a job ended
+func (db *DB) FinishJob(jobID int64, status, result, errMsg, log string) error {
+	_, err := db.conn.Exec(`UPDATE Jobs SET status = ?, result = ?, error = ?, log = ?, finished_at = ?
+		WHERE jobID = ?`, status, result, errMsg, log, jobTimestamp(), jobID)
+	if err != nil {
+		return fmt.Errorf("finish job %d: %w", jobID, err)
+	}
+	return nil
+}
+
+// CancelQueuedJob cancels a job that has not started and reports whether it
+// was still queued
+func (db *DB) CancelQueuedJob(jobID int64) (bool, error) {
+	result, err := db.conn.Exec(`UPDATE Jobs SET status = ?, finished_at = ?
+		WHERE jobID = ? AND status = 'queued'`, models.JobCancelled, jobTimestamp(), jobID)
+	if err != nil {
+		return false, fmt.Errorf("cancel job %d: %w", jobID, err)
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// RecoverInterruptedJobs puts jobs left running by a previous session back
+// in the queue, or fails them once they have been tried maxAttempts times
+func (db *DB) RecoverInterruptedJobs(maxAttempts int) error {
+	_, err := db.conn.Exec(`UPDATE Jobs SET status = 'queued',
+		log = log || 'Interrupted when the app closed; queued again' || char(10)
+		WHERE status = 'running' AND attempts < ?`, maxAttempts)
+	if err != nil {
+		return fmt.Errorf("requeue interrupted jobs: %w", err)
+	}
+	_, err = db.conn.Exec(`UPDATE Jobs SET status = ?, error = 'interrupted too many times', finished_at = ?
+		WHERE status = 'running'`, models.JobFailed, jobTimestamp())
+	if err != nil {
+		return fmt.Errorf("fail interrupted jobs: %w", err)
+	}
+	return nil
+}
+
+// PruneJobs deletes finished jobs older than the given age
+func (db *DB) PruneJobs(olderThan time.Duration) error {
+	cutoff := time.Now().Add(-olderThan).UTC().Format(time.RFC3339)
+	_, err := db.conn.Exec(`DELETE FROM Jobs WHERE status IN (?, ?, ?) AND finished_at != '' AND finished_at < ?`,
+		models.JobSucceeded, models.JobFailed, models.JobCancelled, cutoff)
+	if err != nil {
+		return fmt.Errorf("prune jobs: %w", err)
+	}
+	return nil
+}
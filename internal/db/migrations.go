@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/smartquery"
 )
 
 // Migration represents a database schema migration.
@@ -197,6 +199,61 @@ var migrations = []Migration{
 		Name:    "remove_workid_fk_from_submissions",
 		Up:      migrateRemoveWorkIDFKFromSubmissions,
 	},
+	{
+		Version: 46,
+		Name:    "add_org_response_stats",
+		Up:      migrateAddOrgResponseStats,
+	},
+	{
+		Version: 47,
+		Name:    "add_submission_windows",
+		Up:      migrateAddSubmissionWindows,
+	},
+	{
+		Version: 48,
+		Name:    "add_audit_log",
+		Up:      migrateAddAuditLog,
+	},
+	{
+		Version: 49,
+		Name:    "add_work_revisions",
+		Up:      migrateAddWorkRevisions,
+	},
+	{
+		Version: 50,
+		Name:    "add_book_builds",
+		Up:      migrateAddBookBuilds,
+	},
+	{
+		Version: 51,
+		Name:    "add_jobs",
+		Up:      migrateAddJobs,
+	},
+	{
+		Version: 52,
+		Name:    "add_first_line_index_to_books",
+		Up:      migrateAddFirstLineIndexToBooks,
+	},
+	{
+		Version: 53,
+		Name:    "add_toc_depth_to_books",
+		Up:      migrateAddTOCDepthToBooks,
+	},
+	{
+		Version: 54,
+		Name:    "convert_smart_queries",
+		Up:      migrateConvertSmartQueries,
+	},
+	{
+		Version: 55,
+		Name:    "add_entity_to_collections",
+		Up:      migrateAddEntityToCollections,
+	},
+	{
+		Version: 56,
+		Name:    "add_submission_packets",
+		Up:      migrateAddSubmissionPackets,
+	},
 }
 
 // RunMigrations applies any pending migrations to the database.
@@ -205,6 +262,10 @@ func (db *DB) RunMigrations() error {
 		return fmt.Errorf("ensure migrations table: %w", err)
 	}
 
+	if err := db.dropAuditTriggers(); err != nil {
+		return fmt.Errorf("drop audit triggers: %w", err)
+	}
+
 	for _, m := range migrations {
 		applied, err := db.isMigrationApplied(m.Version)
 		if err != nil {
@@ -219,6 +280,10 @@ func (db *DB) RunMigrations() error {
 		}
 	}
 
+	if err := db.installAuditTriggers(); err != nil {
+		return fmt.Errorf("install audit triggers: %w", err)
+	}
+
 	return nil
 }
 
@@ -227,7 +292,7 @@ func (db *DB) runMigrationInTransaction(m Migration) error {
 	_, _ = db.conn.Exec(`PRAGMA foreign_keys = OFF`)
 	defer func() { _, _ = db.conn.Exec(`PRAGMA foreign_keys = ON`) }()
 
-	tx, err := db.conn.Begin()
+	tx, err := db.pool.Begin()
 	if err != nil {
 		return fmt.Errorf("begin transaction: %w", err)
 	}
@@ -1780,3 +1845,327 @@ func migrateRemoveWorkIDFKFromSubmissions(tx *sql.Tx) error {
 
 	return nil
 }
+
+func migrateAddOrgResponseStats(tx *sql.Tx) error {
+	// Cached per-organization aggregates; rows are recomputed from Submissions
+	// whenever one of the organization's submissions changes.
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS OrgResponseStats (
+		orgID INTEGER PRIMARY KEY REFERENCES Organizations(orgID) ON DELETE CASCADE,
+		submitted INTEGER NOT NULL DEFAULT 0,
+		pending INTEGER NOT NULL DEFAULT 0,
+		decided INTEGER NOT NULL DEFAULT 0,
+		timed INTEGER NOT NULL DEFAULT 0,
+		accepted INTEGER NOT NULL DEFAULT 0,
+		personal INTEGER NOT NULL DEFAULT 0,
+		no_response INTEGER NOT NULL DEFAULT 0,
+		accept_rate REAL NOT NULL DEFAULT 0,
+		personal_rate REAL NOT NULL DEFAULT 0,
+		min_days REAL NOT NULL DEFAULT 0,
+		p25_days REAL NOT NULL DEFAULT 0,
+		median_days REAL NOT NULL DEFAULT 0,
+		p75_days REAL NOT NULL DEFAULT 0,
+		p90_days REAL NOT NULL DEFAULT 0,
+		max_days REAL NOT NULL DEFAULT 0,
+		computed_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create OrgResponseStats table: %w", err)
+	}
+	return nil
+}
+
+func migrateAddSubmissionWindows(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS SubmissionWindows (
+		windowID INTEGER PRIMARY KEY,
+		orgID INTEGER NOT NULL REFERENCES Organizations(orgID) ON DELETE CASCADE,
+		kind TEXT NOT NULL,
+		name TEXT,
+		opens TEXT,
+		closes TEXT NOT NULL,
+		recurs_yearly INTEGER NOT NULL DEFAULT 0,
+		fee REAL,
+		prize TEXT,
+		url TEXT,
+		created_at TEXT,
+		modified_at TEXT
+	)`)
+	if err != nil {
+		return fmt.Errorf("create SubmissionWindows table: %w", err)
+	}
+
+	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_submission_windows_org ON SubmissionWindows(orgID)`)
+	if err != nil {
+		return fmt.Errorf("create SubmissionWindows index: %w", err)
+	}
+
+	return nil
+}
+
+func migrateAddAuditLog(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS AuditLog (
+		id INTEGER PRIMARY KEY,
+		op_id INTEGER,
+		op_label TEXT,
+		table_name TEXT NOT NULL,
+		entity_type TEXT NOT NULL,
+		entity_id INTEGER,
+		action TEXT NOT NULL,
+		before_json TEXT,
+		after_json TEXT,
+		undone INTEGER NOT NULL DEFAULT 0,
+		created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+	)`)
+	if err != nil {
+		return fmt.Errorf("create AuditLog table: %w", err)
+	}
+
+	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON AuditLog(entity_type, entity_id)`)
+	if err != nil {
+		return fmt.Errorf("create AuditLog entity index: %w", err)
+	}
+
+	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_audit_log_op ON AuditLog(op_id)`)
+	if err != nil {
+		return fmt.Errorf("create AuditLog op index: %w", err)
+	}
+
+	// Single-row state read by the capture triggers: the operation currently
+	// open, and whether capture is suspended while undo/redo replays history
+	_, err = tx.Exec(`CREATE TABLE IF NOT EXISTS AuditState (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		op_id INTEGER,
+		op_label TEXT,
+		next_op INTEGER NOT NULL DEFAULT 1,
+		suspended INTEGER NOT NULL DEFAULT 0
+	)`)
+	if err != nil {
+		return fmt.Errorf("create AuditState table: %w", err)
+	}
+
+	_, err = tx.Exec(`INSERT OR IGNORE INTO AuditState (id) VALUES (1)`)
+	if err != nil {
+		return fmt.Errorf("seed AuditState: %w", err)
+	}
+
+	return nil
+}
+
+func migrateAddWorkRevisions(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS WorkRevisions (
+		revisionID INTEGER PRIMARY KEY,
+		workID INTEGER NOT NULL REFERENCES Works(workID) ON DELETE CASCADE,
+		sha256 TEXT NOT NULL,
+		size INTEGER NOT NULL DEFAULT 0,
+		doc_type TEXT,
+		file_mtime INTEGER,
+		n_words INTEGER NOT NULL DEFAULT 0,
+		word_delta INTEGER NOT NULL DEFAULT 0,
+		text TEXT,
+		note TEXT,
+		created_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create WorkRevisions table: %w", err)
+	}
+
+	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_work_revisions_work ON WorkRevisions(workID, revisionID)`)
+	if err != nil {
+		return fmt.Errorf("create WorkRevisions index: %w", err)
+	}
+
+	return nil
+}
+
+func migrateAddBookBuilds(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS BookBuilds (
+		buildID INTEGER PRIMARY KEY,
+		collID INTEGER NOT NULL REFERENCES Collections(collID) ON DELETE CASCADE,
+		manifest TEXT NOT NULL,
+		settings TEXT NOT NULL DEFAULT '',
+		inputs TEXT NOT NULL DEFAULT '[]',
+		output_path TEXT NOT NULL DEFAULT '',
+		output_sha256 TEXT NOT NULL DEFAULT '',
+		pages_sha256 TEXT NOT NULL DEFAULT '',
+		total_pages INTEGER NOT NULL DEFAULT 0,
+		duration TEXT NOT NULL DEFAULT '',
+		rebuilt_from INTEGER,
+		created_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create BookBuilds table: %w", err)
+	}
+
+	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_book_builds_coll ON BookBuilds(collID, buildID)`)
+	if err != nil {
+		return fmt.Errorf("create BookBuilds index: %w", err)
+	}
+
+	return nil
+}
+
+func migrateAddJobs(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS Jobs (
+		jobID INTEGER PRIMARY KEY,
+		kind TEXT NOT NULL,
+		label TEXT NOT NULL DEFAULT '',
+		params TEXT NOT NULL DEFAULT '{}',
+		status TEXT NOT NULL DEFAULT 'queued',
+		progress_current INTEGER NOT NULL DEFAULT 0,
+		progress_total INTEGER NOT NULL DEFAULT 0,
+		message TEXT NOT NULL DEFAULT '',
+		result TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		log TEXT NOT NULL DEFAULT '',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		retry_of INTEGER,
+		created_at TEXT NOT NULL,
+		started_at TEXT NOT NULL DEFAULT '',
+		finished_at TEXT NOT NULL DEFAULT ''
+	)`)
+	if err != nil {
+		return fmt.Errorf("create Jobs table: %w", err)
+	}
+
+	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_jobs_status ON Jobs(status, jobID)`)
+	if err != nil {
+		return fmt.Errorf("create Jobs index: %w", err)
+	}
+
+	return nil
+}
+
+// migrateAddFirstLineIndexToBooks adds the index of titles and first lines
+// switch. NULL follows the book type.
+func migrateAddFirstLineIndexToBooks(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE Books ADD COLUMN first_line_index INTEGER`)
+	if err != nil {
+		return fmt.Errorf("add first_line_index column: %w", err)
+	}
+	return nil
+}
+
+// migrateAddTOCDepthToBooks adds how many heading levels the TOC lists.
+// 1, the default, lists works only.
+func migrateAddTOCDepthToBooks(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE Books ADD COLUMN toc_depth INTEGER NOT NULL DEFAULT 1`)
+	if err != nil {
+		return fmt.Errorf("add toc_depth column: %w", err)
+	}
+	return nil
+}
+
+// migrateConvertSmartQueries rewrites smart queries stored as raw SQL in
+// the smart query language. A query too complex to translate is run one
+// last time to fill the collection with its current works, which then
+// becomes a manual collection; its SQL is kept in legacy_smart_query and
+// each one is reported. Translated queries compare text ignoring case (see
+// smartquery.FromSQL).
+func migrateConvertSmartQueries(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE Collections ADD COLUMN legacy_smart_query TEXT`)
+	if err != nil {
+		return fmt.Errorf("add legacy_smart_query column: %w", err)
+	}
+
+	type legacy struct {
+		collID int64
+		name   string
+		sql    string
+	}
+	rows, err := tx.Query(`SELECT collID, collection_name, smart_query FROM Collections WHERE COALESCE(smart_query, '') != ''`)
+	if err != nil {
+		return fmt.Errorf("query smart collections: %w", err)
+	}
+	var queries []legacy
+	for rows.Next() {
+		var q legacy
+		if err := rows.Scan(&q.collID, &q.name, &q.sql); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan smart collection: %w", err)
+		}
+		queries = append(queries, q)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, q := range queries {
+		if query, ok := smartquery.FromSQL(q.sql); ok {
+			if _, err := tx.Exec(`UPDATE Collections SET smart_query = ? WHERE collID = ?`, query, q.collID); err != nil {
+				return fmt.Errorf("convert smart query of collection %d: %w", q.collID, err)
+			}
+			continue
+		}
+
+		var workIDs []int64
+		members, err := tx.Query(`SELECT w.workID FROM Works w WHERE ` + q.sql + ` ORDER BY w.title`)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARN | Migration 054: Smart query of collection %q (%d) could not be run and the collection is now empty: %v; its SQL is kept in legacy_smart_query: %s\n",
+				q.name, q.collID, err, q.sql)
+		} else {
+			for members.Next() {
+				var id int64
+				if members.Scan(&id) == nil {
+					workIDs = append(workIDs, id)
+				}
+			}
+			members.Close()
+			fmt.Fprintf(os.Stderr, "WARN | Migration 054: Smart query of collection %q (%d) could not be converted; its %d works were kept as a manual collection and its SQL in legacy_smart_query: %s\n",
+				q.name, q.collID, len(workIDs), q.sql)
+		}
+		for i, id := range workIDs {
+			_, err := tx.Exec(`INSERT OR IGNORE INTO CollectionDetails (collID, workID, position) VALUES (?, ?, ?)`, q.collID, id, i)
+			if err != nil {
+				return fmt.Errorf("keep works of collection %d: %w", q.collID, err)
+			}
+		}
+		if _, err := tx.Exec(`UPDATE Collections SET smart_query = NULL, legacy_smart_query = ? WHERE collID = ?`, q.sql, q.collID); err != nil {
+			return fmt.Errorf("retire smart query of collection %d: %w", q.collID, err)
+		}
+	}
+	return nil
+}
+
+// migrateAddEntityToCollections adds what a collection's smart query
+// selects. Existing collections hold works.
+func migrateAddEntityToCollections(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE Collections ADD COLUMN entity TEXT NOT NULL DEFAULT 'works'`)
+	if err != nil {
+		return fmt.Errorf("add entity column: %w", err)
+	}
+	return nil
+}
+
+// migrateAddSubmissionPackets adds packets of works sent to a journal in
+// one file, the submissions they were logged as, and each journal's rule
+// for naming the file
+func migrateAddSubmissionPackets(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS SubmissionPackets (
+		packetID INTEGER PRIMARY KEY,
+		orgID INTEGER NOT NULL REFERENCES Organizations(orgID) ON DELETE CASCADE,
+		doc_path TEXT NOT NULL,
+		pdf_path TEXT,
+		is_blind INTEGER NOT NULL DEFAULT 0,
+		created_at TEXT,
+		modified_at TEXT
+	)`)
+	if err != nil {
+		return fmt.Errorf("create SubmissionPackets table: %w", err)
+	}
+
+	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_submission_packets_org ON SubmissionPackets(orgID)`)
+	if err != nil {
+		return fmt.Errorf("create SubmissionPackets index: %w", err)
+	}
+
+	_, err = tx.Exec(`ALTER TABLE Submissions ADD COLUMN packetID INTEGER REFERENCES SubmissionPackets(packetID) ON DELETE SET NULL`)
+	if err != nil {
+		return fmt.Errorf("add packetID column: %w", err)
+	}
+
+	_, err = tx.Exec(`ALTER TABLE Organizations ADD COLUMN file_naming TEXT`)
+	if err != nil {
+		return fmt.Errorf("add file_naming column: %w", err)
+	}
+	return nil
+}
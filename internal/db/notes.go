@@ -8,7 +8,10 @@ import (
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/validation"
 )
 
-func (db *DB) CreateNote(n *models.Note) (*validation.ValidationResult, error) {
+func (db *DB) CreateNote(n *models.Note) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Create note")
+	defer end(&err)
+
 	// Validate the note
 	result := db.validateNote(n)
 	if !result.IsValid() {
@@ -78,7 +81,10 @@ func (db *DB) GetNotes(entityType string, entityID int64, showDeleted bool) ([]m
 	return notes, rows.Err()
 }
 
-func (db *DB) UpdateNote(n *models.Note) (*validation.ValidationResult, error) {
+func (db *DB) UpdateNote(n *models.Note) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Update note")
+	defer end(&err)
+
 	// Validate the note
 	result := db.validateNote(n)
 	if !result.IsValid() {
@@ -87,7 +93,7 @@ func (db *DB) UpdateNote(n *models.Note) (*validation.ValidationResult, error) {
 
 	now := time.Now().Format(time.RFC3339)
 	query := `UPDATE Notes SET type=?, note=?, attributes=?, modified_at=? WHERE id=?`
-	_, err := db.conn.Exec(query, n.Type, n.Note, n.Attributes, now, n.ID)
+	_, err = db.conn.Exec(query, n.Type, n.Note, n.Attributes, now, n.ID)
 	if err != nil {
 		return nil, fmt.Errorf("update note: %w", err)
 	}
@@ -95,7 +101,10 @@ func (db *DB) UpdateNote(n *models.Note) (*validation.ValidationResult, error) {
 	return &result, nil
 }
 
-func (db *DB) DeleteNote(id int64) error {
+func (db *DB) DeleteNote(id int64) (err error) {
+	db, end := db.auditOp("Delete note")
+	defer end(&err)
+
 	note, err := db.GetNote(id)
 	if err != nil {
 		return fmt.Errorf("get note: %w", err)
@@ -109,7 +118,10 @@ func (db *DB) DeleteNote(id int64) error {
 	return nil
 }
 
-func (db *DB) UndeleteNote(id int64) error {
+func (db *DB) UndeleteNote(id int64) (err error) {
+	db, end := db.auditOp("Undelete note")
+	defer end(&err)
+
 	note, err := db.GetNote(id)
 	if err != nil {
 		return fmt.Errorf("get note: %w", err)
@@ -123,9 +135,12 @@ func (db *DB) UndeleteNote(id int64) error {
 	return nil
 }
 
-func (db *DB) DeleteNotePermanent(id int64) error {
+func (db *DB) DeleteNotePermanent(id int64) (err error) {
+	db, end := db.auditOp("Permanently delete note")
+	defer end(&err)
+
 	query := `DELETE FROM Notes WHERE id = ?`
-	_, err := db.conn.Exec(query, id)
+	_, err = db.conn.Exec(query, id)
 	if err != nil {
 		return fmt.Errorf("delete note permanently: %w", err)
 	}
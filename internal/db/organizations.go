@@ -54,7 +54,10 @@ func (db *DB) validateOrganization(o *models.Organization) validation.Validation
 	return result
 }
 
-func (db *DB) CreateOrganization(o *models.Organization) (*validation.ValidationResult, error) {
+func (db *DB) CreateOrganization(o *models.Organization) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Create organization")
+	defer end(&err)
+
 	// Validate the organization
 	result := db.validateOrganization(o)
 	if !result.IsValid() {
@@ -67,15 +70,15 @@ func (db *DB) CreateOrganization(o *models.Organization) (*validation.Validation
 		submission_types, accepts, my_interest, ranking, source,
 		website_menu, duotrope_num, n_push_fiction, n_push_nonfiction,
 		n_push_poetry, contest_ends, contest_fee, contest_prize,
-		contest_prize_2, attributes, date_added, modified_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		contest_prize_2, file_naming, attributes, date_added, modified_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	sqlResult, err := db.conn.Exec(query,
 		o.Name, o.OtherName, o.URL, o.OtherURL, o.Status, o.Type,
 		o.Timing, o.SubmissionType, o.Accepts, o.MyInterest, o.Ranking,
 		o.Source, o.WebsiteMenu, o.DuotropeNum, o.NPushFiction,
 		o.NPushNonfict, o.NPushPoetry, o.ContestEnds, o.ContestFee,
-		o.ContestPrize, o.ContestPrize2, o.Attributes, now, now,
+		o.ContestPrize, o.ContestPrize2, o.FileNaming, o.Attributes, now, now,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("insert organization: %w", err)
@@ -96,7 +99,7 @@ func (db *DB) GetOrganization(id int64) (*models.Organization, error) {
 		timing, submission_types, accepts, my_interest, ranking, source,
 		website_menu, duotrope_num, n_push_fiction, n_push_nonfiction,
 		n_push_poetry, contest_ends, contest_fee, contest_prize,
-		contest_prize_2, attributes, date_added, modified_at
+		contest_prize_2, file_naming, attributes, date_added, modified_at
 		FROM Organizations WHERE orgID = ?`
 
 	o := &models.Organization{}
@@ -105,7 +108,7 @@ func (db *DB) GetOrganization(id int64) (*models.Organization, error) {
 		&o.Status, &o.Type, &o.Timing, &o.SubmissionType, &o.Accepts,
 		&o.MyInterest, &o.Ranking, &o.Source, &o.WebsiteMenu,
 		&o.DuotropeNum, &o.NPushFiction, &o.NPushNonfict, &o.NPushPoetry,
-		&o.ContestEnds, &o.ContestFee, &o.ContestPrize, &o.ContestPrize2,
+		&o.ContestEnds, &o.ContestFee, &o.ContestPrize, &o.ContestPrize2, &o.FileNaming,
 		&o.Attributes, &o.DateAdded, &o.ModifiedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -117,7 +120,10 @@ func (db *DB) GetOrganization(id int64) (*models.Organization, error) {
 	return o, nil
 }
 
-func (db *DB) UpdateOrganization(o *models.Organization) (*validation.ValidationResult, error) {
+func (db *DB) UpdateOrganization(o *models.Organization) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Update organization")
+	defer end(&err)
+
 	// Validate the organization
 	result := db.validateOrganization(o)
 	if !result.IsValid() {
@@ -130,15 +136,15 @@ func (db *DB) UpdateOrganization(o *models.Organization) (*validation.Validation
 		timing=?, submission_types=?, accepts=?, my_interest=?, ranking=?,
 		source=?, website_menu=?, duotrope_num=?, n_push_fiction=?,
 		n_push_nonfiction=?, n_push_poetry=?, contest_ends=?, contest_fee=?,
-		contest_prize=?, contest_prize_2=?, attributes=?, modified_at=?
+		contest_prize=?, contest_prize_2=?, file_naming=?, attributes=?, modified_at=?
 		WHERE orgID=?`
 
-	_, err := db.conn.Exec(query,
+	_, err = db.conn.Exec(query,
 		o.Name, o.OtherName, o.URL, o.OtherURL, o.Status, o.Type,
 		o.Timing, o.SubmissionType, o.Accepts, o.MyInterest, o.Ranking,
 		o.Source, o.WebsiteMenu, o.DuotropeNum, o.NPushFiction,
 		o.NPushNonfict, o.NPushPoetry, o.ContestEnds, o.ContestFee,
-		o.ContestPrize, o.ContestPrize2, o.Attributes, now, o.OrgID,
+		o.ContestPrize, o.ContestPrize2, o.FileNaming, o.Attributes, now, o.OrgID,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("update organization: %w", err)
@@ -147,7 +153,10 @@ func (db *DB) UpdateOrganization(o *models.Organization) (*validation.Validation
 	return &result, nil
 }
 
-func (db *DB) DeleteOrganization(id int64) error {
+func (db *DB) DeleteOrganization(id int64) (err error) {
+	db, end := db.auditOp("Delete organization")
+	defer end(&err)
+
 	org, err := db.GetOrganization(id)
 	if err != nil {
 		return fmt.Errorf("get organization: %w", err)
@@ -169,7 +178,10 @@ func (db *DB) DeleteOrganization(id int64) error {
 	return nil
 }
 
-func (db *DB) UndeleteOrganization(id int64) (*validation.ValidationResult, error) {
+func (db *DB) UndeleteOrganization(id int64) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Undelete organization")
+	defer end(&err)
+
 	org, err := db.GetOrganization(id)
 	if err != nil {
 		return nil, fmt.Errorf("get organization: %w", err)
@@ -206,7 +218,7 @@ func (db *DB) FindOrganizationsByName(name string) ([]models.Organization, error
 		timing, submission_types, accepts, my_interest, ranking, source,
 		website_menu, duotrope_num, n_push_fiction, n_push_nonfiction,
 		n_push_poetry, contest_ends, contest_fee, contest_prize,
-		contest_prize_2, attributes, date_added, modified_at
+		contest_prize_2, file_naming, attributes, date_added, modified_at
 		FROM Organizations WHERE name = ?` + andNotDeleted
 
 	rows, err := db.conn.Query(query, name)
@@ -223,7 +235,7 @@ func (db *DB) FindOrganizationsByName(name string) ([]models.Organization, error
 			&o.Status, &o.Type, &o.Timing, &o.SubmissionType, &o.Accepts,
 			&o.MyInterest, &o.Ranking, &o.Source, &o.WebsiteMenu,
 			&o.DuotropeNum, &o.NPushFiction, &o.NPushNonfict, &o.NPushPoetry,
-			&o.ContestEnds, &o.ContestFee, &o.ContestPrize, &o.ContestPrize2,
+			&o.ContestEnds, &o.ContestFee, &o.ContestPrize, &o.ContestPrize2, &o.FileNaming,
 			&o.Attributes, &o.DateAdded, &o.ModifiedAt,
 		)
 		if err != nil {
@@ -240,7 +252,7 @@ func (db *DB) ListOrganizations(showDeleted bool) ([]models.Organization, error)
 		timing, submission_types, accepts, my_interest, ranking, source,
 		website_menu, duotrope_num, n_push_fiction, n_push_nonfiction,
 		n_push_poetry, contest_ends, contest_fee, contest_prize,
-		contest_prize_2, attributes, date_added, modified_at
+		contest_prize_2, file_naming, attributes, date_added, modified_at
 		FROM Organizations`
 
 	if !showDeleted {
@@ -263,7 +275,7 @@ func (db *DB) ListOrganizations(showDeleted bool) ([]models.Organization, error)
 			&o.Status, &o.Type, &o.Timing, &o.SubmissionType, &o.Accepts,
 			&o.MyInterest, &o.Ranking, &o.Source, &o.WebsiteMenu,
 			&o.DuotropeNum, &o.NPushFiction, &o.NPushNonfict, &o.NPushPoetry,
-			&o.ContestEnds, &o.ContestFee, &o.ContestPrize, &o.ContestPrize2,
+			&o.ContestEnds, &o.ContestFee, &o.ContestPrize, &o.ContestPrize2, &o.FileNaming,
 			&o.Attributes, &o.DateAdded, &o.ModifiedAt,
 		)
 		if err != nil {
@@ -279,7 +291,7 @@ func (db *DB) ListOrganizationsWithNotes() ([]models.OrganizationWithNotes, erro
 		o.timing, o.submission_types, o.accepts, o.my_interest, o.ranking, o.source,
 		o.website_menu, o.duotrope_num, o.n_push_fiction, o.n_push_nonfiction,
 		o.n_push_poetry, o.contest_ends, o.contest_fee, o.contest_prize,
-		o.contest_prize_2, o.attributes, o.date_added, o.modified_at,
+		o.contest_prize_2, o.file_naming, o.attributes, o.date_added, o.modified_at,
 		(SELECT COUNT(*) FROM Submissions s WHERE s.orgID = o.orgID) as n_submissions,
 		(SELECT COUNT(*) FROM Submissions s WHERE s.orgID = o.orgID AND s.response_type = 'Accepted') as n_accepted,
 		GROUP_CONCAT(n.note, ' ') as notes
@@ -302,7 +314,7 @@ func (db *DB) ListOrganizationsWithNotes() ([]models.OrganizationWithNotes, erro
 			&o.Status, &o.Type, &o.Timing, &o.SubmissionType, &o.Accepts,
 			&o.MyInterest, &o.Ranking, &o.Source, &o.WebsiteMenu,
 			&o.DuotropeNum, &o.NPushFiction, &o.NPushNonfict, &o.NPushPoetry,
-			&o.ContestEnds, &o.ContestFee, &o.ContestPrize, &o.ContestPrize2,
+			&o.ContestEnds, &o.ContestFee, &o.ContestPrize, &o.ContestPrize2, &o.FileNaming,
 			&o.Attributes, &o.DateAdded, &o.ModifiedAt, &o.NSubmissions, &o.NAccepted, &o.Notes,
 		)
 		if err != nil {
@@ -341,9 +353,12 @@ func (db *DB) GetOrganizationDeleteConfirmation(orgID int64) (*DeleteConfirmatio
 }
 
 // DeleteOrganizationPermanent permanently deletes an organization and all its orphaned data
-func (db *DB) DeleteOrganizationPermanent(orgID int64) error {
+func (db *DB) DeleteOrganizationPermanent(orgID int64) (err error) {
+	db, end := db.auditOp("Permanently delete organization")
+	defer end(&err)
+
 	// Delete notes manually (polymorphic FK)
-	_, err := db.conn.Exec(`DELETE FROM Notes WHERE entity_type = 'journal' AND entity_id = ?`, orgID)
+	_, err = db.conn.Exec(`DELETE FROM Notes WHERE entity_type = 'journal' AND entity_id = ?`, orgID)
 	if err != nil {
 		return fmt.Errorf("delete organization notes: %w", err)
 	}
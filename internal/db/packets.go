@@ -0,0 +1,164 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/validation"
+)
+
+const selectSubmissionPackets = `SELECT p.packetID, p.orgID, p.doc_path, p.pdf_path,
+	COALESCE(p.is_blind, 0), COALESCE(p.created_at, ''), COALESCE(p.modified_at, ''),
+	COALESCE(o.name, '')
+	FROM SubmissionPackets p
+	LEFT JOIN Organizations o ON p.orgID = o.orgID`
+
+func scanSubmissionPacket(row rowScanner) (*models.SubmissionPacket, error) {
+	p := &models.SubmissionPacket{}
+	err := row.Scan(
+		&p.PacketID, &p.OrgID, &p.DocPath, &p.PDFPath,
+		&p.IsBlind, &p.CreatedAt, &p.ModifiedAt,
+		&p.OrgName,
+	)
+	return p, err
+}
+
+// validateSubmissionPacket validates a packet and the submissions it will
+// be logged as. Every submission goes to the packet's organization.
+func (db *DB) validateSubmissionPacket(p *models.SubmissionPacket, subs []*models.Submission) validation.ValidationResult {
+	result := validation.ValidationResult{}
+
+	if p.OrgID <= 0 {
+		result.AddError("orgID", "orgID is required")
+	} else if org, err := db.GetOrganization(p.OrgID); err != nil {
+		result.AddError("orgID", "Error validating orgID: "+err.Error())
+	} else if org == nil {
+		result.AddError("orgID", "Organization does not exist")
+	}
+	result.AddIfError(validation.Required(p.DocPath, "docPath"))
+
+	if len(subs) == 0 {
+		result.AddError("works", "A packet needs at least one work")
+	}
+	seen := map[int64]bool{}
+	for _, s := range subs {
+		if s.IsCollection {
+			result.AddError("works", "A packet holds works, not collections")
+		}
+		if seen[s.WorkID] {
+			result.AddError("works", fmt.Sprintf("Work %d is in the packet twice", s.WorkID))
+		}
+		seen[s.WorkID] = true
+		s.OrgID = p.OrgID
+		sub := db.validateSubmission(s)
+		result.Errors = append(result.Errors, sub.Errors...)
+		result.Warnings = append(result.Warnings, sub.Warnings...)
+	}
+
+	return result
+}
+
+// CreateSubmissionPacket records a packet and logs each of subs as a
+// submission of it, in one transaction and as one undoable operation.
+// Nothing is recorded unless the packet and every submission are valid and
+// written.
+func (db *DB) CreateSubmissionPacket(p *models.SubmissionPacket, subs []*models.Submission) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Create submission packet")
+	defer end(&err)
+
+	result := db.validateSubmissionPacket(p, subs)
+	if !result.IsValid() {
+		return &result, nil
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	res, err := db.conn.Exec(`INSERT INTO SubmissionPackets (
+		orgID, doc_path, pdf_path, is_blind, created_at, modified_at
+	) VALUES (?, ?, ?, ?, ?, ?)`,
+		p.OrgID, p.DocPath, p.PDFPath, p.IsBlind, now, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("insert submission packet: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("get last insert id: %w", err)
+	}
+	for _, s := range subs {
+		s.PacketID = &id
+		if err := insertSubmission(db.conn, s); err != nil {
+			return nil, fmt.Errorf("log submission of work %d: %w", s.WorkID, err)
+		}
+	}
+
+	p.PacketID = id
+	p.CreatedAt = now
+	p.ModifiedAt = now
+	db.refreshResponseStatsFor(p.OrgID)
+	return &result, nil
+}
+
+func (db *DB) GetSubmissionPacket(id int64) (*models.SubmissionPacket, error) {
+	p, err := scanSubmissionPacket(db.conn.QueryRow(selectSubmissionPackets+` WHERE p.packetID = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query submission packet: %w", err)
+	}
+	return p, nil
+}
+
+// ListSubmissionPackets returns an organization's packets, or every
+// packet when orgID is zero, newest first
+func (db *DB) ListSubmissionPackets(orgID int64) ([]models.SubmissionPacket, error) {
+	query := selectSubmissionPackets
+	args := []any{}
+	if orgID > 0 {
+		query += ` WHERE p.orgID = ?`
+		args = append(args, orgID)
+	}
+	query += ` ORDER BY p.created_at DESC, p.packetID DESC`
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query submission packets: %w", err)
+	}
+	defer rows.Close()
+
+	packets := []models.SubmissionPacket{}
+	for rows.Next() {
+		p, err := scanSubmissionPacket(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan submission packet: %w", err)
+		}
+		packets = append(packets, *p)
+	}
+	return packets, rows.Err()
+}
+
+// ListPacketSubmissions returns the submissions logged for a packet
+func (db *DB) ListPacketSubmissions(packetID int64, showDeleted bool) ([]models.SubmissionView, error) {
+	p, err := db.GetSubmissionPacket(packetID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, fmt.Errorf("packet not found")
+	}
+
+	views, err := db.ListSubmissionViewsByOrg(p.OrgID, showDeleted)
+	if err != nil {
+		return nil, err
+	}
+	result := []models.SubmissionView{}
+	for _, v := range views {
+		if v.PacketID != nil && *v.PacketID == packetID {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
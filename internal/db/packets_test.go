@@ -0,0 +1,114 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func TestCreateSubmissionPacket(t *testing.T) {
+	database := setupTestDB(t)
+
+	var works []*models.Work
+	for _, title := range []string{"Rain", "Snow", "Hail"} {
+		w := &models.Work{Title: title, Type: "Poem", Status: "Out", Quality: "Good"}
+		if _, err := database.CreateWork(w); err != nil {
+			t.Fatalf("create work: %v", err)
+		}
+		works = append(works, w)
+	}
+	rule := "{author}_{title}"
+	org := &models.Organization{Name: "Alpha", Status: "Open", Type: "Journal", FileNaming: &rule}
+	if _, err := database.CreateOrganization(org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	if got, err := database.GetOrganization(org.OrgID); err != nil || got.FileNaming == nil || *got.FileNaming != rule {
+		t.Errorf("file naming did not round trip: %+v %v", got, err)
+	}
+
+	// A work listed twice invalidates the whole packet
+	packet := &models.SubmissionPacket{OrgID: org.OrgID, DocPath: "/tmp/packet.docx", IsBlind: true}
+	result, err := database.CreateSubmissionPacket(packet, []*models.Submission{
+		{WorkID: works[0].WorkID}, {WorkID: works[0].WorkID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsValid() {
+		t.Error("a duplicate work should be invalid")
+	}
+	if packets, _ := database.ListSubmissionPackets(org.OrgID); len(packets) != 0 {
+		t.Errorf("an invalid packet should not be recorded, got %d", len(packets))
+	}
+
+	date := "2026-03-05"
+	subs := []*models.Submission{
+		{WorkID: works[0].WorkID, SubmissionDate: &date},
+		{WorkID: works[1].WorkID, SubmissionDate: &date},
+	}
+	if result, err := database.CreateSubmissionPacket(packet, subs); err != nil || !result.IsValid() {
+		t.Fatalf("CreateSubmissionPacket: %v %v", result, err)
+	}
+	// A submission of the same journal outside the packet
+	if _, err := database.CreateSubmission(&models.Submission{WorkID: works[2].WorkID, OrgID: org.OrgID}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := database.GetSubmissionPacket(packet.PacketID)
+	if err != nil || got == nil || got.OrgName != "Alpha" || !got.IsBlind || got.DocPath != "/tmp/packet.docx" {
+		t.Errorf("GetSubmissionPacket = %+v, %v", got, err)
+	}
+	views, err := database.ListPacketSubmissions(packet.PacketID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(views) != 2 {
+		t.Fatalf("want the packet's 2 submissions, got %d", len(views))
+	}
+	for _, v := range views {
+		if v.OrgID != org.OrgID || v.PacketID == nil || *v.PacketID != packet.PacketID {
+			t.Errorf("submission %d is not linked to the packet: %+v", v.SubmissionID, v.Submission)
+		}
+	}
+}
+
+func TestCreateSubmissionPacketRollsBack(t *testing.T) {
+	database := setupTestDB(t)
+
+	var works []*models.Work
+	for _, title := range []string{"Rain", "Snow"} {
+		w := &models.Work{Title: title, Type: "Poem", Status: "Out", Quality: "Good"}
+		if _, err := database.CreateWork(w); err != nil {
+			t.Fatalf("create work: %v", err)
+		}
+		works = append(works, w)
+	}
+	org := &models.Organization{Name: "Alpha", Status: "Open", Type: "Journal"}
+	if _, err := database.CreateOrganization(org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+
+	// The second submission fails after the packet and the first are written
+	if _, err := database.conn.Exec(`CREATE TEMP TRIGGER fail_snow BEFORE INSERT ON Submissions
+		WHEN NEW.workID = ` + fmt.Sprint(works[1].WorkID) + ` BEGIN SELECT RAISE(ABORT, 'disk full'); END`); err != nil {
+		t.Fatal(err)
+	}
+
+	packet := &models.SubmissionPacket{OrgID: org.OrgID, DocPath: "/tmp/packet.docx"}
+	_, err := database.CreateSubmissionPacket(packet, []*models.Submission{
+		{WorkID: works[0].WorkID}, {WorkID: works[1].WorkID},
+	})
+	if err == nil {
+		t.Fatal("want the failed insert reported")
+	}
+	if packet.PacketID != 0 {
+		t.Errorf("a packet that was rolled back should have no id, got %d", packet.PacketID)
+	}
+	if packets, _ := database.ListSubmissionPackets(org.OrgID); len(packets) != 0 {
+		t.Errorf("want no packet recorded, got %d", len(packets))
+	}
+	if subs, _ := database.ListSubmissionViewsByOrg(org.OrgID, true); len(subs) != 0 {
+		t.Errorf("want no submissions logged, got %d", len(subs))
+	}
+}
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/recommend"
+)
+
+// RecommendInput gathers the live works, organizations, submissions and
+// reading periods the recommendation engine scores
+func (db *DB) RecommendInput(today time.Time) (recommend.Input, error) {
+	in := recommend.Input{Today: today}
+
+	works, err := db.ListWorks(false)
+	if err != nil {
+		return in, fmt.Errorf("list works: %w", err)
+	}
+	for _, w := range works {
+		in.Works = append(in.Works, w.Work)
+	}
+	if in.Orgs, err = db.ListOrganizations(false); err != nil {
+		return in, fmt.Errorf("list organizations: %w", err)
+	}
+	if in.Submissions, err = db.ListSubmissions(false); err != nil {
+		return in, fmt.Errorf("list submissions: %w", err)
+	}
+	if in.Windows, err = db.ListSubmissionWindows(0); err != nil {
+		return in, fmt.Errorf("list submission windows: %w", err)
+	}
+	return in, nil
+}
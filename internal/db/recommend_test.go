@@ -0,0 +1,53 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/recommend"
+)
+
+func TestRecommendInput(t *testing.T) {
+	database := setupTestDB(t)
+
+	rain := &models.Work{Title: "Rain", Type: "Poem", Status: "Out", Quality: "Best"}
+	snow := &models.Work{Title: "Snow", Type: "Poem", Status: "Out", Quality: "Good"}
+	for _, w := range []*models.Work{rain, snow} {
+		if _, err := database.CreateWork(w); err != nil {
+			t.Fatalf("create work: %v", err)
+		}
+	}
+	poetry := "poetry"
+	alpha := &models.Organization{Name: "Alpha", Status: "Open", Type: "Journal", Accepts: &poetry}
+	beta := &models.Organization{Name: "Beta", Status: "Open", Type: "Journal", Accepts: &poetry}
+	for _, o := range []*models.Organization{alpha, beta} {
+		if _, err := database.CreateOrganization(o); err != nil {
+			t.Fatalf("create organization: %v", err)
+		}
+	}
+	sent := time.Now().AddDate(0, 0, -10).Format("2006-01-02")
+	if result, err := database.CreateSubmission(&models.Submission{WorkID: rain.WorkID, OrgID: alpha.OrgID, SubmissionDate: &sent}); err != nil || !result.IsValid() {
+		t.Fatalf("create submission: %v %v", result, err)
+	}
+
+	suggest := func() []recommend.Suggestion {
+		t.Helper()
+		in, err := database.RecommendInput(time.Now())
+		if err != nil {
+			t.Fatalf("RecommendInput: %v", err)
+		}
+		return recommend.Recommend(in, recommend.Options{})
+	}
+	if got := suggest(); len(got) != 2 || got[0].Title != "Rain" || got[0].OrgName != "Beta" {
+		t.Errorf("want Rain and Snow to Beta, got %+v", got)
+	}
+
+	alpha.Attributes = models.AddAttribute(alpha.Attributes, models.AttrNoSimultaneous)
+	if _, err := database.UpdateOrganization(alpha); err != nil {
+		t.Fatalf("update organization: %v", err)
+	}
+	if got := suggest(); len(got) != 1 || got[0].Title != "Snow" {
+		t.Errorf("Rain is held by Alpha; want only Snow, got %+v", got)
+	}
+}
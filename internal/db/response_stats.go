@@ -0,0 +1,284 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+// minResponseSample is the number of timed responses an organization needs
+// before its history is trusted to decide whether a submission is overdue.
+const minResponseSample = 3
+
+const selectOrgResponseStats = `SELECT r.orgID, COALESCE(o.name, ''), r.submitted, r.pending, r.decided, r.timed,
+	r.accepted, r.personal, r.no_response, r.accept_rate, r.personal_rate,
+	r.min_days, r.p25_days, r.median_days, r.p75_days, r.p90_days, r.max_days, r.computed_at
+	FROM OrgResponseStats r
+	LEFT JOIN Organizations o ON r.orgID = o.orgID`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanOrgResponseStats(row rowScanner) (*models.OrgResponseStats, error) {
+	s := &models.OrgResponseStats{}
+	err := row.Scan(
+		&s.OrgID, &s.OrgName, &s.Submitted, &s.Pending, &s.Decided, &s.Timed,
+		&s.Accepted, &s.Personal, &s.NoResponse, &s.AcceptRate, &s.PersonalRate,
+		&s.MinDays, &s.P25Days, &s.MedianDays, &s.P75Days, &s.P90Days, &s.MaxDays, &s.ComputedAt,
+	)
+	return s, err
+}
+
+// computeOrgResponseStats aggregates an organization's submission history
+func (db *DB) computeOrgResponseStats(orgID int64) (*models.OrgResponseStats, error) {
+	rows, err := db.conn.Query(`
+		SELECT COALESCE(response_type, ''),
+			julianday(response_date) - julianday(submission_date)
+		FROM Submissions
+		WHERE orgID = ?
+		AND (attributes IS NULL OR attributes NOT LIKE '%deleted%')`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("query submissions: %w", err)
+	}
+	defer rows.Close()
+
+	stats := &models.OrgResponseStats{OrgID: orgID}
+	var days []float64
+	for rows.Next() {
+		var responseType string
+		var elapsed *float64
+		if err := rows.Scan(&responseType, &elapsed); err != nil {
+			return nil, fmt.Errorf("scan submission: %w", err)
+		}
+
+		stats.Submitted++
+		switch responseType {
+		case "", "Waiting":
+			stats.Pending++
+			continue
+		case "Withdrawn":
+			// Our decision, not theirs
+			continue
+		case "Accepted":
+			stats.Accepted++
+		case "Personal", "Personal Note":
+			stats.Personal++
+		case "No Response":
+			stats.NoResponse++
+		}
+		stats.Decided++
+
+		// A "No Response" close-out date says nothing about how fast the editors read
+		if responseType != "No Response" && elapsed != nil && *elapsed >= 0 {
+			days = append(days, *elapsed)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if stats.Decided > 0 {
+		stats.AcceptRate = float64(stats.Accepted) / float64(stats.Decided) * 100
+		stats.PersonalRate = float64(stats.Personal) / float64(stats.Decided) * 100
+	}
+
+	stats.Timed = len(days)
+	if len(days) > 0 {
+		sort.Float64s(days)
+		stats.MinDays = days[0]
+		stats.P25Days = percentile(days, 25)
+		stats.MedianDays = percentile(days, 50)
+		stats.P75Days = percentile(days, 75)
+		stats.P90Days = percentile(days, 90)
+		stats.MaxDays = days[len(days)-1]
+	}
+
+	stats.ComputedAt = time.Now().Format(time.RFC3339)
+	return stats, nil
+}
+
+// percentile returns the p-th percentile of sorted values using linear
+// interpolation between closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	frac := rank - float64(lo)
+	return math.Round((sorted[lo]+(sorted[hi]-sorted[lo])*frac)*10) / 10
+}
+
+// RefreshOrgResponseStats recomputes and caches one organization's aggregates
+func (db *DB) RefreshOrgResponseStats(orgID int64) error {
+	stats, err := db.computeOrgResponseStats(orgID)
+	if err != nil {
+		return err
+	}
+
+	if stats.Submitted == 0 {
+		_, err = db.conn.Exec(`DELETE FROM OrgResponseStats WHERE orgID = ?`, orgID)
+		return err
+	}
+
+	_, err = db.conn.Exec(`INSERT OR REPLACE INTO OrgResponseStats (
+		orgID, submitted, pending, decided, timed, accepted, personal, no_response,
+		accept_rate, personal_rate, min_days, p25_days, median_days, p75_days, p90_days, max_days, computed_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		stats.OrgID, stats.Submitted, stats.Pending, stats.Decided, stats.Timed, stats.Accepted, stats.Personal, stats.NoResponse,
+		stats.AcceptRate, stats.PersonalRate, stats.MinDays, stats.P25Days, stats.MedianDays, stats.P75Days, stats.P90Days, stats.MaxDays, stats.ComputedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("save response stats: %w", err)
+	}
+	return nil
+}
+
+// RefreshAllOrgResponseStats rebuilds the cache for every organization
+func (db *DB) RefreshAllOrgResponseStats() error {
+	rows, err := db.conn.Query(`SELECT DISTINCT s.orgID FROM Submissions s JOIN Organizations o ON s.orgID = o.orgID`)
+	if err != nil {
+		return fmt.Errorf("query organizations: %w", err)
+	}
+	var orgIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		orgIDs = append(orgIDs, id)
+	}
+	rows.Close()
+
+	if _, err := db.conn.Exec(`DELETE FROM OrgResponseStats`); err != nil {
+		return fmt.Errorf("clear response stats: %w", err)
+	}
+	for _, id := range orgIDs {
+		if err := db.RefreshOrgResponseStats(id); err != nil {
+			return fmt.Errorf("refresh org %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// refreshResponseStatsFor keeps the cache current after a submission write.
+// Failures only leave the cache stale, so they never fail the write itself.
+func (db *DB) refreshResponseStatsFor(orgIDs ...int64) {
+	seen := make(map[int64]bool)
+	for _, id := range orgIDs {
+		if id <= 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		_ = db.RefreshOrgResponseStats(id)
+	}
+}
+
+// ensureOrgResponseStats populates the cache the first time it is read
+func (db *DB) ensureOrgResponseStats() error {
+	var count int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM OrgResponseStats`).Scan(&count); err != nil {
+		return fmt.Errorf("count response stats: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+	return db.RefreshAllOrgResponseStats()
+}
+
+// GetOrgResponseStats returns the cached aggregates for an organization,
+// computing them on first use. Returns an empty record if the organization
+// has no submissions.
+func (db *DB) GetOrgResponseStats(orgID int64) (*models.OrgResponseStats, error) {
+	if err := db.ensureOrgResponseStats(); err != nil {
+		return nil, err
+	}
+
+	stats, err := scanOrgResponseStats(db.conn.QueryRow(selectOrgResponseStats+` WHERE r.orgID = ?`, orgID))
+	if err == nil {
+		return stats, nil
+	}
+
+	if err := db.RefreshOrgResponseStats(orgID); err != nil {
+		return nil, err
+	}
+	stats, err = scanOrgResponseStats(db.conn.QueryRow(selectOrgResponseStats+` WHERE r.orgID = ?`, orgID))
+	if err != nil {
+		return &models.OrgResponseStats{OrgID: orgID}, nil
+	}
+	return stats, nil
+}
+
+// ListOrgResponseStats returns cached aggregates for every organization with
+// submissions, most-submitted first
+func (db *DB) ListOrgResponseStats() ([]models.OrgResponseStats, error) {
+	if err := db.ensureOrgResponseStats(); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.conn.Query(selectOrgResponseStats + ` ORDER BY r.submitted DESC, o.name`)
+	if err != nil {
+		return nil, fmt.Errorf("query response stats: %w", err)
+	}
+	defer rows.Close()
+
+	result := []models.OrgResponseStats{}
+	for rows.Next() {
+		s, err := scanOrgResponseStats(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan response stats: %w", err)
+		}
+		result = append(result, *s)
+	}
+	return result, rows.Err()
+}
+
+// ListOverdueSubmissions returns pending submissions that have waited longer
+// than the organization's own 90th-percentile response time. Organizations
+// with fewer than minResponseSample timed responses are skipped.
+func (db *DB) ListOverdueSubmissions() ([]models.OverdueSubmission, error) {
+	if err := db.ensureOrgResponseStats(); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT s.submissionID, s.orgID,
+			CASE WHEN s.is_collection = 1 THEN COALESCE(c.collection_name, '') ELSE COALESCE(w.title, '') END,
+			COALESCE(o.name, ''),
+			COALESCE(s.submission_date, ''),
+			julianday('now') - julianday(s.submission_date) as days_waiting,
+			r.median_days, r.p90_days, r.timed
+		FROM Submissions s
+		JOIN OrgResponseStats r ON s.orgID = r.orgID
+		LEFT JOIN Works w ON s.is_collection = 0 AND s.workID = w.workID
+		LEFT JOIN Collections c ON s.is_collection = 1 AND s.workID = c.collID
+		LEFT JOIN Organizations o ON s.orgID = o.orgID
+		WHERE (s.response_type IS NULL OR s.response_type = '' OR s.response_type = 'Waiting')
+		AND (s.attributes IS NULL OR s.attributes NOT LIKE '%deleted%')
+		AND r.timed >= ?
+		AND julianday('now') - julianday(s.submission_date) > r.p90_days
+		ORDER BY (julianday('now') - julianday(s.submission_date)) / MAX(r.p90_days, 1) DESC`, minResponseSample)
+	if err != nil {
+		return nil, fmt.Errorf("query overdue submissions: %w", err)
+	}
+	defer rows.Close()
+
+	result := []models.OverdueSubmission{}
+	for rows.Next() {
+		var o models.OverdueSubmission
+		var daysWaiting float64
+		if err := rows.Scan(&o.SubmissionID, &o.OrgID, &o.WorkTitle, &o.OrgName, &o.SubmissionDate,
+			&daysWaiting, &o.MedianDays, &o.P90Days, &o.Sample); err != nil {
+			return nil, fmt.Errorf("scan overdue submission: %w", err)
+		}
+		o.DaysWaiting = int(daysWaiting)
+		result = append(result, o)
+	}
+	return result, rows.Err()
+}
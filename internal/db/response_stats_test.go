@@ -0,0 +1,170 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+// testResponse is one submission in a response-stats fixture: sent on 1
+// January 2025 and answered days later (nil for no response date)
+type testResponse struct {
+	responseType string
+	days         *int
+}
+
+func daysPtr(n int) *int { return &n }
+
+// rate computes a percentage the way the stats do, so floats compare equal
+func rate(n, of int) float64 { return float64(n) / float64(of) * 100 }
+
+func addTestSubmissions(t *testing.T, database *DB, orgID int64, responses []testResponse) {
+	t.Helper()
+	work := &models.Work{Title: "Rain", Type: "Poem", Status: "Out", Quality: "Good"}
+	if _, err := database.CreateWork(work); err != nil {
+		t.Fatalf("create work: %v", err)
+	}
+	sent := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, r := range responses {
+		s := &models.Submission{WorkID: work.WorkID, OrgID: orgID}
+		date := sent.Format("2006-01-02")
+		s.SubmissionDate = &date
+		if r.responseType != "" {
+			responseType := r.responseType
+			s.ResponseType = &responseType
+		}
+		if r.days != nil {
+			answered := sent.AddDate(0, 0, *r.days).Format("2006-01-02")
+			s.ResponseDate = &answered
+		}
+		if result, err := database.CreateSubmission(s); err != nil || !result.IsValid() {
+			t.Fatalf("create submission: %v %v", result, err)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{"single value", []float64{12}, 90, 12},
+		{"lowest", []float64{10, 20, 30}, 0, 10},
+		{"median of odd", []float64{10, 20, 30}, 50, 20},
+		{"median of even", []float64{10, 20, 30, 40}, 50, 25},
+		{"interpolated", []float64{10, 20, 30}, 90, 28},
+		{"highest", []float64{10, 20, 30}, 100, 30},
+		{"rounded to a tenth", []float64{1, 2, 4}, 25, 1.5},
+	}
+	for _, tt := range tests {
+		if got := percentile(tt.sorted, tt.p); got != tt.want {
+			t.Errorf("%s: percentile(%v, %v) = %v, want %v", tt.name, tt.sorted, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestOrgResponseStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []testResponse
+		want      models.OrgResponseStats
+	}{
+		{
+			name: "no submissions",
+			want: models.OrgResponseStats{},
+		},
+		{
+			name:      "single response",
+			responses: []testResponse{{"Accepted", daysPtr(30)}},
+			want: models.OrgResponseStats{Submitted: 1, Decided: 1, Timed: 1, Accepted: 1, AcceptRate: 100,
+				MinDays: 30, P25Days: 30, MedianDays: 30, P75Days: 30, P90Days: 30, MaxDays: 30},
+		},
+		{
+			name:      "null response date is decided but not timed",
+			responses: []testResponse{{"Declined", nil}, {"Declined", daysPtr(10)}},
+			want: models.OrgResponseStats{Submitted: 2, Decided: 2, Timed: 1,
+				MinDays: 10, P25Days: 10, MedianDays: 10, P75Days: 10, P90Days: 10, MaxDays: 10},
+		},
+		{
+			name: "pending, withdrawn and no response",
+			responses: []testResponse{
+				{"", nil}, {"Waiting", nil}, {"Withdrawn", daysPtr(5)},
+				{"No Response", daysPtr(200)}, {"Personal", daysPtr(20)}, {"Declined", daysPtr(40)},
+			},
+			want: models.OrgResponseStats{Submitted: 6, Pending: 2, Decided: 3, Timed: 2, Personal: 1, NoResponse: 1,
+				PersonalRate: rate(1, 3), MinDays: 20, P25Days: 25, MedianDays: 30, P75Days: 35, P90Days: 38, MaxDays: 40},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := setupTestDB(t)
+			org := &models.Organization{Name: "Alpha", Status: "Open", Type: "Journal"}
+			if _, err := database.CreateOrganization(org); err != nil {
+				t.Fatalf("create organization: %v", err)
+			}
+			addTestSubmissions(t, database, org.OrgID, tt.responses)
+
+			got, err := database.GetOrgResponseStats(org.OrgID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tt.want
+			want.OrgID = org.OrgID
+			if tt.responses != nil {
+				want.OrgName = "Alpha"
+			}
+			want.ComputedAt = got.ComputedAt
+			if *got != want {
+				t.Errorf("got  %+v\nwant %+v", *got, want)
+			}
+		})
+	}
+}
+
+func TestListOverdueSubmissions(t *testing.T) {
+	database := setupTestDB(t)
+
+	newOrg := func(name string, responseDays ...int) int64 {
+		org := &models.Organization{Name: name, Status: "Open", Type: "Journal"}
+		if _, err := database.CreateOrganization(org); err != nil {
+			t.Fatalf("create organization: %v", err)
+		}
+		var responses []testResponse
+		for _, d := range responseDays {
+			responses = append(responses, testResponse{"Declined", daysPtr(d)})
+		}
+		addTestSubmissions(t, database, org.OrgID, responses)
+		return org.OrgID
+	}
+	pending := func(orgID int64, daysAgo int) int64 {
+		date := time.Now().AddDate(0, 0, -daysAgo).Format("2006-01-02")
+		s := &models.Submission{WorkID: 1, OrgID: orgID, SubmissionDate: &date}
+		if _, err := database.CreateSubmission(s); err != nil {
+			t.Fatal(err)
+		}
+		return s.SubmissionID
+	}
+
+	// 90th percentile of 10, 20 and 30 days is 28
+	timed := newOrg("Alpha", 10, 20, 30)
+	overdue := pending(timed, 40)
+	pending(timed, 20)
+	// Two timed responses are too few to judge by
+	sparse := newOrg("Beta", 10, 20)
+	pending(sparse, 400)
+
+	got, err := database.ListOverdueSubmissions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].SubmissionID != overdue {
+		t.Fatalf("want only submission %d overdue, got %+v", overdue, got)
+	}
+	if got[0].P90Days != 28 || got[0].Sample != 3 || got[0].DaysWaiting < 39 {
+		t.Errorf("unexpected overdue details: %+v", got[0])
+	}
+}
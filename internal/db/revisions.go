@@ -0,0 +1,157 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+const revisionColumns = `revisionID, workID, sha256, size, COALESCE(doc_type, ''),
+	COALESCE(file_mtime, 0), n_words, word_delta, note, created_at`
+
+func scanRevision(row rowScanner) (*models.WorkRevision, error) {
+	r := &models.WorkRevision{}
+	err := row.Scan(&r.RevisionID, &r.WorkID, &r.SHA256, &r.Size, &r.DocType,
+		&r.FileMtime, &r.NWords, &r.WordDelta, &r.Note, &r.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// AddWorkRevision records a new revision of a work's document with its
+// extracted text. The word delta is computed against the previous
+// revision. When the document is identical to the latest revision nothing
+// is recorded and nil is returned; a note is added to that revision instead.
+func (db *DB) AddWorkRevision(r *models.WorkRevision, text string) (*models.WorkRevision, error) {
+	latest, err := db.LatestWorkRevision(r.WorkID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && latest.SHA256 == r.SHA256 {
+		if r.Note != nil && *r.Note != "" {
+			_, err := db.conn.Exec(`UPDATE WorkRevisions
+				SET note = CASE WHEN note IS NULL OR note = '' THEN ? ELSE note || '; ' || ? END
+				WHERE revisionID = ?`, *r.Note, *r.Note, latest.RevisionID)
+			if err != nil {
+				return nil, fmt.Errorf("update revision note: %w", err)
+			}
+		}
+		return nil, nil
+	}
+
+	r.WordDelta = r.NWords
+	if latest != nil {
+		r.WordDelta = r.NWords - latest.NWords
+	}
+	r.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	result, err := db.conn.Exec(`INSERT INTO WorkRevisions
+		(workID, sha256, size, doc_type, file_mtime, n_words, word_delta, text, note, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.WorkID, r.SHA256, r.Size, r.DocType, r.FileMtime, r.NWords, r.WordDelta, text, r.Note, r.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("insert revision: %w", err)
+	}
+	r.RevisionID, err = result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("get last insert id: %w", err)
+	}
+	return r, nil
+}
+
+// LatestWorkRevision returns the newest revision of a work, or nil if it
+// has none
+func (db *DB) LatestWorkRevision(workID int64) (*models.WorkRevision, error) {
+	row := db.conn.QueryRow(`SELECT `+revisionColumns+` FROM WorkRevisions
+		WHERE workID = ? ORDER BY revisionID DESC LIMIT 1`, workID)
+	r, err := scanRevision(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query latest revision: %w", err)
+	}
+	return r, nil
+}
+
+// LatestWorkRevisions returns the newest revision of every work that has one
+func (db *DB) LatestWorkRevisions() (map[int64]models.WorkRevision, error) {
+	rows, err := db.conn.Query(`SELECT ` + revisionColumns + ` FROM WorkRevisions
+		WHERE revisionID IN (SELECT MAX(revisionID) FROM WorkRevisions GROUP BY workID)`)
+	if err != nil {
+		return nil, fmt.Errorf("query latest revisions: %w", err)
+	}
+	defer rows.Close()
+
+	latest := map[int64]models.WorkRevision{}
+	for rows.Next() {
+		r, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan revision: %w", err)
+		}
+		latest[r.WorkID] = *r
+	}
+	return latest, rows.Err()
+}
+
+// ListWorkRevisions returns a work's revisions, newest first
+func (db *DB) ListWorkRevisions(workID int64) ([]models.WorkRevision, error) {
+	rows, err := db.conn.Query(`SELECT `+revisionColumns+` FROM WorkRevisions
+		WHERE workID = ? ORDER BY revisionID DESC`, workID)
+	if err != nil {
+		return nil, fmt.Errorf("query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []models.WorkRevision{}
+	for rows.Next() {
+		r, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan revision: %w", err)
+		}
+		revisions = append(revisions, *r)
+	}
+	return revisions, rows.Err()
+}
+
+func (db *DB) GetWorkRevision(revisionID int64) (*models.WorkRevision, error) {
+	row := db.conn.QueryRow(`SELECT `+revisionColumns+` FROM WorkRevisions WHERE revisionID = ?`, revisionID)
+	r, err := scanRevision(row)
+	if err != nil {
+		return nil, fmt.Errorf("query revision %d: %w", revisionID, err)
+	}
+	return r, nil
+}
+
+// GetWorkRevisionText returns the text extracted from a revision
+func (db *DB) GetWorkRevisionText(revisionID int64) (string, error) {
+	var text sql.NullString
+	err := db.conn.QueryRow(`SELECT text FROM WorkRevisions WHERE revisionID = ?`, revisionID).Scan(&text)
+	if err != nil {
+		return "", fmt.Errorf("query revision %d: %w", revisionID, err)
+	}
+	return text.String, nil
+}
+
+// WorkRevisionHashes returns every document hash any revision refers to,
+// so the object store can drop files no revision needs
+func (db *DB) WorkRevisionHashes() (map[string]bool, error) {
+	rows, err := db.conn.Query(`SELECT DISTINCT sha256 FROM WorkRevisions`)
+	if err != nil {
+		return nil, fmt.Errorf("query revision hashes: %w", err)
+	}
+	defer rows.Close()
+
+	hashes := map[string]bool{}
+	for rows.Next() {
+		var sum string
+		if err := rows.Scan(&sum); err != nil {
+			return nil, err
+		}
+		hashes[sum] = true
+	}
+	return hashes, rows.Err()
+}
@@ -0,0 +1,247 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/smartquery"
+)
+
+// smartTable is what a smart query over one entity selects from
+type smartTable struct {
+	from       string // FROM clause with the aliases of the entity's schema
+	notDeleted string // appended to the WHERE clause to hide deleted rows
+}
+
+var smartTables = map[string]smartTable{
+	smartquery.Works.Name: {
+		from:       `FROM Works w`,
+		notDeleted: excludeDeletedFilter,
+	},
+	smartquery.Submissions.Name: {
+		from: `FROM Submissions s
+		LEFT JOIN Works w ON s.is_collection = 0 AND s.workID = w.workID
+		LEFT JOIN Collections c ON s.is_collection = 1 AND s.workID = c.collID
+		LEFT JOIN Organizations o ON s.orgID = o.orgID`,
+		notDeleted: andSubmissionsNotDeleted,
+	},
+	smartquery.Organizations.Name: {
+		from:       `FROM Organizations o`,
+		notDeleted: ` AND (o.attributes IS NULL OR o.attributes NOT LIKE '%deleted%')`,
+	},
+}
+
+// smartQuerySQL compiles a smart query over an entity to the FROM and
+// WHERE clauses of a SELECT, and their arguments
+func smartQuerySQL(entity, query string, showDeleted bool) (string, []any, error) {
+	schema, err := smartquery.SchemaFor(entity)
+	if err != nil {
+		return "", nil, err
+	}
+	where, args, err := smartquery.Compile(query, schema)
+	if err != nil {
+		return "", nil, err
+	}
+	t := smartTables[entity]
+	from := t.from + ` WHERE ` + where
+	if !showDeleted {
+		from += t.notDeleted
+	}
+	return from, args, nil
+}
+
+// smartCollection returns the smart query of a collection of entity, or
+// nil for a manual collection or one that does not exist
+func (db *DB) smartCollection(collID int64, entity string) (*string, error) {
+	var have string
+	var smartQuery *string
+	err := db.conn.QueryRow(`SELECT entity, smart_query FROM Collections WHERE collID = ?`, collID).Scan(&have, &smartQuery)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("check smart collection: %w", err)
+	}
+	if have != entity {
+		return nil, fmt.Errorf("collection with ID %d holds %s, not %s", collID, have, entity)
+	}
+	if smartQuery != nil && *smartQuery == "" {
+		return nil, nil
+	}
+	return smartQuery, nil
+}
+
+// GetCollectionSubmissions returns the submissions a collection of
+// submissions selects, newest first
+func (db *DB) GetCollectionSubmissions(collID int64, showDeleted bool) ([]models.SubmissionView, error) {
+	smartQuery, err := db.smartCollection(collID, smartquery.Submissions.Name)
+	if err != nil || smartQuery == nil {
+		return []models.SubmissionView{}, err
+	}
+	return db.SmartQuerySubmissions(*smartQuery, showDeleted)
+}
+
+// GetCollectionOrganizations returns the organizations a collection of
+// organizations selects, by name
+func (db *DB) GetCollectionOrganizations(collID int64, showDeleted bool) ([]models.Organization, error) {
+	smartQuery, err := db.smartCollection(collID, smartquery.Organizations.Name)
+	if err != nil || smartQuery == nil {
+		return []models.Organization{}, err
+	}
+	return db.SmartQueryOrganizations(*smartQuery, showDeleted)
+}
+
+// SmartQuerySubmissions returns the submissions a smart query selects,
+// newest first
+func (db *DB) SmartQuerySubmissions(smartQuery string, showDeleted bool) ([]models.SubmissionView, error) {
+	from, args, err := smartQuerySQL(smartquery.Submissions.Name, smartQuery, showDeleted)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT
+		s.submissionID, s.workID, s.orgID, COALESCE(s.is_collection, 0), s.draft, s.submission_date,
+		s.submission_type, s.query_date, s.response_date, s.response_type,
+		s.contest_name, s.cost, s.user_id, s.password, s.web_address, s.packetID, s.attributes,
+		s.created_at, s.modified_at,
+		CASE WHEN s.is_collection = 1 THEN COALESCE(c.collection_name, '') ELSE COALESCE(w.title, '') END as title_of_work,
+		COALESCE(o.name, '') as journal_name,
+		COALESCE(o.status, 'Open') as journal_status,
+		CASE WHEN s.response_date IS NULL AND (s.response_type IS NULL OR s.response_type = '' OR s.response_type = 'Waiting') THEN 'yes' ELSE 'no' END as decision_pending
+		` + from + orderBySubmissionDateDesc
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query smart collection submissions: %w", err)
+	}
+	defer rows.Close()
+
+	views := []models.SubmissionView{}
+	for rows.Next() {
+		var v models.SubmissionView
+		err := rows.Scan(
+			&v.SubmissionID, &v.WorkID, &v.OrgID, &v.IsCollection, &v.Draft, &v.SubmissionDate,
+			&v.SubmissionType, &v.QueryDate, &v.ResponseDate, &v.ResponseType,
+			&v.ContestName, &v.Cost, &v.UserID, &v.Password, &v.WebAddress, &v.PacketID,
+			&v.Attributes, &v.CreatedAt, &v.ModifiedAt,
+			&v.TitleOfWork, &v.JournalName, &v.JournalStatus, &v.DecisionPending,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan smart collection submission: %w", err)
+		}
+		v.IsDeleted = v.Submission.IsDeleted()
+		views = append(views, v)
+	}
+	return views, rows.Err()
+}
+
+// SmartQueryOrganizations returns the organizations a smart query
+// selects, by name
+func (db *DB) SmartQueryOrganizations(smartQuery string, showDeleted bool) ([]models.Organization, error) {
+	from, args, err := smartQuerySQL(smartquery.Organizations.Name, smartQuery, showDeleted)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT o.orgID, o.name, o.other_name, o.url, o.other_url, o.status, o.type,
+		o.timing, o.submission_types, o.accepts, o.my_interest, o.ranking, o.source,
+		o.website_menu, o.duotrope_num, o.n_push_fiction, o.n_push_nonfiction,
+		o.n_push_poetry, o.contest_ends, o.contest_fee, o.contest_prize,
+		o.contest_prize_2, o.file_naming, o.attributes, o.date_added, o.modified_at
+		` + from + ` ORDER BY o.name`
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query smart collection organizations: %w", err)
+	}
+	defer rows.Close()
+
+	orgs := []models.Organization{}
+	for rows.Next() {
+		var o models.Organization
+		err := rows.Scan(
+			&o.OrgID, &o.Name, &o.OtherName, &o.URL, &o.OtherURL,
+			&o.Status, &o.Type, &o.Timing, &o.SubmissionType, &o.Accepts,
+			&o.MyInterest, &o.Ranking, &o.Source, &o.WebsiteMenu,
+			&o.DuotropeNum, &o.NPushFiction, &o.NPushNonfict, &o.NPushPoetry,
+			&o.ContestEnds, &o.ContestFee, &o.ContestPrize, &o.ContestPrize2, &o.FileNaming,
+			&o.Attributes, &o.DateAdded, &o.ModifiedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan smart collection organization: %w", err)
+		}
+		orgs = append(orgs, o)
+	}
+	return orgs, rows.Err()
+}
+
+// PreviewSmartQuery runs a smart query over an entity without saving it,
+// returning up to limit of its rows and how many there are in all. Errors
+// in the query are reported in the preview rather than returned.
+func (db *DB) PreviewSmartQuery(entity, query string, limit int, showDeleted bool) (*models.SmartQueryPreview, error) {
+	if entity == "" {
+		entity = smartquery.Works.Name
+	}
+	preview := &models.SmartQueryPreview{
+		Entity:        entity,
+		Query:         query,
+		Works:         []models.CollectionWork{},
+		Submissions:   []models.SubmissionView{},
+		Organizations: []models.Organization{},
+	}
+
+	schema, err := smartquery.SchemaFor(entity)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := smartquery.Parse(query)
+	if err == nil {
+		preview.Query = parsed.String()
+		_, _, err = parsed.Compile(schema)
+	}
+	var qe *smartquery.Error
+	if errors.As(err, &qe) {
+		preview.Error = qe.Message
+		preview.ErrorPos = qe.Pos
+		return preview, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch entity {
+	case smartquery.Submissions.Name:
+		subs, err := db.SmartQuerySubmissions(query, showDeleted)
+		if err != nil {
+			return nil, err
+		}
+		preview.Count = len(subs)
+		preview.Submissions = firstN(subs, limit)
+	case smartquery.Organizations.Name:
+		orgs, err := db.SmartQueryOrganizations(query, showDeleted)
+		if err != nil {
+			return nil, err
+		}
+		preview.Count = len(orgs)
+		preview.Organizations = firstN(orgs, limit)
+	default:
+		works, err := db.SmartQueryWorks(query, showDeleted)
+		if err != nil {
+			return nil, err
+		}
+		preview.Count = len(works)
+		if works != nil {
+			preview.Works = firstN(works, limit)
+		}
+	}
+	return preview, nil
+}
+
+func firstN[T any](rows []T, limit int) []T {
+	if limit > 0 && len(rows) > limit {
+		return rows[:limit]
+	}
+	return rows
+}
@@ -69,43 +69,60 @@ func (db *DB) validateSubmission(s *models.Submission) validation.ValidationResu
 	return result
 }
 
-func (db *DB) CreateSubmission(s *models.Submission) (*validation.ValidationResult, error) {
+func (db *DB) CreateSubmission(s *models.Submission) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Create submission")
+	defer end(&err)
+
 	// Validate the submission
 	result := db.validateSubmission(s)
 	if !result.IsValid() {
 		return &result, nil
 	}
 
+	if err := insertSubmission(db.conn, s); err != nil {
+		return nil, err
+	}
+	db.refreshResponseStatsFor(s.OrgID)
+	return &result, nil
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// insertSubmission writes a validated submission through the connection
+// or a transaction
+func insertSubmission(exec execer, s *models.Submission) error {
 	now := time.Now().Format(time.RFC3339)
 	query := `INSERT INTO Submissions (
 		workID, orgID, is_collection, draft, submission_date, submission_type,
 		query_date, response_date, response_type, contest_name,
-		cost, user_id, password, web_address, attributes, created_at, modified_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		cost, user_id, password, web_address, packetID, attributes, created_at, modified_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	sqlResult, err := db.conn.Exec(query,
+	sqlResult, err := exec.Exec(query,
 		s.WorkID, s.OrgID, s.IsCollection, s.Draft, s.SubmissionDate, s.SubmissionType,
 		s.QueryDate, s.ResponseDate, s.ResponseType, s.ContestName,
-		s.Cost, s.UserID, s.Password, s.WebAddress, s.Attributes, now, now,
+		s.Cost, s.UserID, s.Password, s.WebAddress, s.PacketID, s.Attributes, now, now,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("insert submission: %w", err)
+		return fmt.Errorf("insert submission: %w", err)
 	}
 
 	id, err := sqlResult.LastInsertId()
 	if err != nil {
-		return nil, fmt.Errorf("get last insert id: %w", err)
+		return fmt.Errorf("get last insert id: %w", err)
 	}
 	s.SubmissionID = id
 	s.CreatedAt = now
 	s.ModifiedAt = now
-	return &result, nil
+	return nil
 }
 
 func (db *DB) GetSubmission(id int64) (*models.Submission, error) {
 	query := `SELECT submissionID, workID, orgID, COALESCE(is_collection, 0), draft, submission_date,
 		submission_type, query_date, response_date, response_type,
-		contest_name, cost, user_id, password, web_address, attributes,
+		contest_name, cost, user_id, password, web_address, packetID, attributes,
 		created_at, modified_at
 		FROM Submissions WHERE submissionID = ?`
 
@@ -113,7 +130,7 @@ func (db *DB) GetSubmission(id int64) (*models.Submission, error) {
 	err := db.conn.QueryRow(query, id).Scan(
 		&s.SubmissionID, &s.WorkID, &s.OrgID, &s.IsCollection, &s.Draft, &s.SubmissionDate,
 		&s.SubmissionType, &s.QueryDate, &s.ResponseDate, &s.ResponseType,
-		&s.ContestName, &s.Cost, &s.UserID, &s.Password, &s.WebAddress,
+		&s.ContestName, &s.Cost, &s.UserID, &s.Password, &s.WebAddress, &s.PacketID,
 		&s.Attributes, &s.CreatedAt, &s.ModifiedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -125,33 +142,43 @@ func (db *DB) GetSubmission(id int64) (*models.Submission, error) {
 	return s, nil
 }
 
-func (db *DB) UpdateSubmission(s *models.Submission) (*validation.ValidationResult, error) {
+func (db *DB) UpdateSubmission(s *models.Submission) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Update submission")
+	defer end(&err)
+
 	// Validate the submission
 	result := db.validateSubmission(s)
 	if !result.IsValid() {
 		return &result, nil
 	}
 
+	var prevOrgID int64
+	_ = db.conn.QueryRow(`SELECT orgID FROM Submissions WHERE submissionID = ?`, s.SubmissionID).Scan(&prevOrgID)
+
 	now := time.Now().Format(time.RFC3339)
 	query := `UPDATE Submissions SET
 		workID=?, orgID=?, is_collection=?, draft=?, submission_date=?, submission_type=?,
 		query_date=?, response_date=?, response_type=?, contest_name=?,
-		cost=?, user_id=?, password=?, web_address=?, attributes=?, modified_at=?
+		cost=?, user_id=?, password=?, web_address=?, packetID=?, attributes=?, modified_at=?
 		WHERE submissionID=?`
 
-	_, err := db.conn.Exec(query,
+	_, err = db.conn.Exec(query,
 		s.WorkID, s.OrgID, s.IsCollection, s.Draft, s.SubmissionDate, s.SubmissionType,
 		s.QueryDate, s.ResponseDate, s.ResponseType, s.ContestName,
-		s.Cost, s.UserID, s.Password, s.WebAddress, s.Attributes, now, s.SubmissionID,
+		s.Cost, s.UserID, s.Password, s.WebAddress, s.PacketID, s.Attributes, now, s.SubmissionID,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("update submission: %w", err)
 	}
 	s.ModifiedAt = now
+	db.refreshResponseStatsFor(s.OrgID, prevOrgID)
 	return &result, nil
 }
 
-func (db *DB) DeleteSubmission(id int64) error {
+func (db *DB) DeleteSubmission(id int64) (err error) {
+	db, end := db.auditOp("Delete submission")
+	defer end(&err)
+
 	submission, err := db.GetSubmission(id)
 	if err != nil {
 		return fmt.Errorf("get submission: %w", err)
@@ -173,7 +200,10 @@ func (db *DB) DeleteSubmission(id int64) error {
 	return nil
 }
 
-func (db *DB) UndeleteSubmission(id int64) (*validation.ValidationResult, error) {
+func (db *DB) UndeleteSubmission(id int64) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Undelete submission")
+	defer end(&err)
+
 	submission, err := db.GetSubmission(id)
 	if err != nil {
 		return nil, fmt.Errorf("get submission: %w", err)
@@ -207,7 +237,7 @@ func (db *DB) UndeleteSubmission(id int64) (*validation.ValidationResult, error)
 func (db *DB) ListSubmissions(showDeleted bool) ([]models.Submission, error) {
 	query := `SELECT submissionID, workID, orgID, COALESCE(is_collection, 0), draft, submission_date,
 		submission_type, query_date, response_date, response_type,
-		contest_name, cost, user_id, password, web_address, attributes,
+		contest_name, cost, user_id, password, web_address, packetID, attributes,
 		created_at, modified_at
 		FROM Submissions`
 
@@ -229,7 +259,7 @@ func (db *DB) ListSubmissions(showDeleted bool) ([]models.Submission, error) {
 		err := rows.Scan(
 			&s.SubmissionID, &s.WorkID, &s.OrgID, &s.IsCollection, &s.Draft, &s.SubmissionDate,
 			&s.SubmissionType, &s.QueryDate, &s.ResponseDate, &s.ResponseType,
-			&s.ContestName, &s.Cost, &s.UserID, &s.Password, &s.WebAddress,
+			&s.ContestName, &s.Cost, &s.UserID, &s.Password, &s.WebAddress, &s.PacketID,
 			&s.Attributes, &s.CreatedAt, &s.ModifiedAt,
 		)
 		if err != nil {
@@ -243,7 +273,7 @@ func (db *DB) ListSubmissions(showDeleted bool) ([]models.Submission, error) {
 func (db *DB) ListSubmissionsByWork(workID int64) ([]models.Submission, error) {
 	query := `SELECT submissionID, workID, orgID, COALESCE(is_collection, 0), draft, submission_date,
 		submission_type, query_date, response_date, response_type,
-		contest_name, cost, user_id, password, web_address, attributes,
+		contest_name, cost, user_id, password, web_address, packetID, attributes,
 		created_at, modified_at
 		FROM Submissions WHERE workID = ? AND COALESCE(is_collection, 0) = 0 ORDER BY submission_date DESC`
 
@@ -259,7 +289,7 @@ func (db *DB) ListSubmissionsByWork(workID int64) ([]models.Submission, error) {
 		err := rows.Scan(
 			&s.SubmissionID, &s.WorkID, &s.OrgID, &s.IsCollection, &s.Draft, &s.SubmissionDate,
 			&s.SubmissionType, &s.QueryDate, &s.ResponseDate, &s.ResponseType,
-			&s.ContestName, &s.Cost, &s.UserID, &s.Password, &s.WebAddress,
+			&s.ContestName, &s.Cost, &s.UserID, &s.Password, &s.WebAddress, &s.PacketID,
 			&s.Attributes, &s.CreatedAt, &s.ModifiedAt,
 		)
 		if err != nil {
@@ -274,7 +304,7 @@ func (db *DB) ListSubmissionViewsByWork(workID int64, showDeleted bool) ([]model
 	query := `SELECT 
 		s.submissionID, s.workID, s.orgID, COALESCE(s.is_collection, 0), s.draft, s.submission_date,
 		s.submission_type, s.query_date, s.response_date, s.response_type,
-		s.contest_name, s.cost, s.user_id, s.password, s.web_address, s.attributes,
+		s.contest_name, s.cost, s.user_id, s.password, s.web_address, s.packetID, s.attributes,
 		s.created_at, s.modified_at,
 		CASE WHEN s.is_collection = 1 THEN COALESCE(c.collection_name, '') ELSE COALESCE(w.title, '') END as title_of_work,
 		COALESCE(o.name, '') as journal_name,
@@ -307,7 +337,7 @@ func (db *DB) ListSubmissionViewsByWork(workID int64, showDeleted bool) ([]model
 		err := rows.Scan(
 			&v.SubmissionID, &v.WorkID, &v.OrgID, &v.IsCollection, &v.Draft, &v.SubmissionDate,
 			&v.SubmissionType, &v.QueryDate, &v.ResponseDate, &v.ResponseType,
-			&v.ContestName, &v.Cost, &v.UserID, &v.Password, &v.WebAddress,
+			&v.ContestName, &v.Cost, &v.UserID, &v.Password, &v.WebAddress, &v.PacketID,
 			&v.Attributes, &v.CreatedAt, &v.ModifiedAt,
 			&v.TitleOfWork, &v.JournalName, &v.JournalStatus, &v.DecisionPending,
 		)
@@ -324,7 +354,7 @@ func (db *DB) ListAllSubmissionViews(showDeleted bool) ([]models.SubmissionView,
 	query := `SELECT 
 		s.submissionID, s.workID, s.orgID, COALESCE(s.is_collection, 0), s.draft, s.submission_date,
 		s.submission_type, s.query_date, s.response_date, s.response_type,
-		s.contest_name, s.cost, s.user_id, s.password, s.web_address, s.attributes,
+		s.contest_name, s.cost, s.user_id, s.password, s.web_address, s.packetID, s.attributes,
 		s.created_at, s.modified_at,
 		CASE WHEN s.is_collection = 1 THEN COALESCE(c.collection_name, '') ELSE COALESCE(w.title, '') END as title_of_work,
 		COALESCE(o.name, '') as journal_name,
@@ -353,7 +383,7 @@ func (db *DB) ListAllSubmissionViews(showDeleted bool) ([]models.SubmissionView,
 		err := rows.Scan(
 			&v.SubmissionID, &v.WorkID, &v.OrgID, &v.IsCollection, &v.Draft, &v.SubmissionDate,
 			&v.SubmissionType, &v.QueryDate, &v.ResponseDate, &v.ResponseType,
-			&v.ContestName, &v.Cost, &v.UserID, &v.Password, &v.WebAddress,
+			&v.ContestName, &v.Cost, &v.UserID, &v.Password, &v.WebAddress, &v.PacketID,
 			&v.Attributes, &v.CreatedAt, &v.ModifiedAt,
 			&v.TitleOfWork, &v.JournalName, &v.JournalStatus, &v.DecisionPending,
 		)
@@ -370,7 +400,7 @@ func (db *DB) ListSubmissionViewsByOrg(orgID int64, showDeleted bool) ([]models.
 	query := `SELECT 
 		s.submissionID, s.workID, s.orgID, COALESCE(s.is_collection, 0), s.draft, s.submission_date,
 		s.submission_type, s.query_date, s.response_date, s.response_type,
-		s.contest_name, s.cost, s.user_id, s.password, s.web_address, s.attributes,
+		s.contest_name, s.cost, s.user_id, s.password, s.web_address, s.packetID, s.attributes,
 		s.created_at, s.modified_at,
 		CASE WHEN s.is_collection = 1 THEN COALESCE(c.collection_name, '') ELSE COALESCE(w.title, '') END as title_of_work,
 		COALESCE(o.name, '') as journal_name,
@@ -400,7 +430,7 @@ func (db *DB) ListSubmissionViewsByOrg(orgID int64, showDeleted bool) ([]models.
 		err := rows.Scan(
 			&v.SubmissionID, &v.WorkID, &v.OrgID, &v.IsCollection, &v.Draft, &v.SubmissionDate,
 			&v.SubmissionType, &v.QueryDate, &v.ResponseDate, &v.ResponseType,
-			&v.ContestName, &v.Cost, &v.UserID, &v.Password, &v.WebAddress,
+			&v.ContestName, &v.Cost, &v.UserID, &v.Password, &v.WebAddress, &v.PacketID,
 			&v.Attributes, &v.CreatedAt, &v.ModifiedAt,
 			&v.TitleOfWork, &v.JournalName, &v.JournalStatus, &v.DecisionPending,
 		)
@@ -417,7 +447,7 @@ func (db *DB) ListSubmissionViewsByCollection(collID int64, showDeleted bool) ([
 	query := `SELECT 
 		s.submissionID, s.workID, s.orgID, COALESCE(s.is_collection, 0), s.draft, s.submission_date,
 		s.submission_type, s.query_date, s.response_date, s.response_type,
-		s.contest_name, s.cost, s.user_id, s.password, s.web_address, s.attributes,
+		s.contest_name, s.cost, s.user_id, s.password, s.web_address, s.packetID, s.attributes,
 		s.created_at, s.modified_at,
 		CASE WHEN s.is_collection = 1 THEN COALESCE(c.collection_name, '') ELSE COALESCE(w.title, '') END as title_of_work,
 		COALESCE(o.name, '') as journal_name,
@@ -450,7 +480,7 @@ func (db *DB) ListSubmissionViewsByCollection(collID int64, showDeleted bool) ([
 		err := rows.Scan(
 			&v.SubmissionID, &v.WorkID, &v.OrgID, &v.IsCollection, &v.Draft, &v.SubmissionDate,
 			&v.SubmissionType, &v.QueryDate, &v.ResponseDate, &v.ResponseType,
-			&v.ContestName, &v.Cost, &v.UserID, &v.Password, &v.WebAddress,
+			&v.ContestName, &v.Cost, &v.UserID, &v.Password, &v.WebAddress, &v.PacketID,
 			&v.Attributes, &v.CreatedAt, &v.ModifiedAt,
 			&v.TitleOfWork, &v.JournalName, &v.JournalStatus, &v.DecisionPending,
 		)
@@ -499,18 +529,25 @@ func (db *DB) GetSubmissionDeleteConfirmation(submissionID int64) (*DeleteConfir
 }
 
 // DeleteSubmissionPermanent permanently deletes a submission and all its orphaned data
-func (db *DB) DeleteSubmissionPermanent(submissionID int64) error {
+func (db *DB) DeleteSubmissionPermanent(submissionID int64) (err error) {
+	db, end := db.auditOp("Permanently delete submission")
+	defer end(&err)
+
 	// Delete notes manually (polymorphic FK)
-	_, err := db.conn.Exec(`DELETE FROM Notes WHERE entity_type = 'submission' AND entity_id = ?`, submissionID)
+	_, err = db.conn.Exec(`DELETE FROM Notes WHERE entity_type = 'submission' AND entity_id = ?`, submissionID)
 	if err != nil {
 		return fmt.Errorf("delete submission notes: %w", err)
 	}
 
+	var orgID int64
+	_ = db.conn.QueryRow(`SELECT orgID FROM Submissions WHERE submissionID = ?`, submissionID).Scan(&orgID)
+
 	// Delete submission
 	_, err = db.conn.Exec(`DELETE FROM Submissions WHERE submissionID = ?`, submissionID)
 	if err != nil {
 		return fmt.Errorf("delete submission: %w", err)
 	}
 
+	db.refreshResponseStatsFor(orgID)
 	return nil
 }
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/validation"
+)
+
+const selectSubmissionWindows = `SELECT w.windowID, w.orgID, w.kind, w.name, w.opens, w.closes,
+	COALESCE(w.recurs_yearly, 0), w.fee, w.prize, w.url,
+	COALESCE(w.created_at, ''), COALESCE(w.modified_at, ''),
+	COALESCE(o.name, ''), COALESCE(o.url, '')
+	FROM SubmissionWindows w
+	LEFT JOIN Organizations o ON w.orgID = o.orgID`
+
+func scanSubmissionWindow(row rowScanner) (*models.SubmissionWindow, error) {
+	w := &models.SubmissionWindow{}
+	err := row.Scan(
+		&w.WindowID, &w.OrgID, &w.Kind, &w.Name, &w.Opens, &w.Closes,
+		&w.RecursYearly, &w.Fee, &w.Prize, &w.URL,
+		&w.CreatedAt, &w.ModifiedAt,
+		&w.OrgName, &w.OrgURL,
+	)
+	return w, err
+}
+
+// validateSubmissionWindow validates a SubmissionWindow entity
+func (db *DB) validateSubmissionWindow(w *models.SubmissionWindow) validation.ValidationResult {
+	result := validation.ValidationResult{}
+
+	if w.OrgID <= 0 {
+		result.AddError("orgID", "orgID is required")
+	} else if org, err := db.GetOrganization(w.OrgID); err != nil {
+		result.AddError("orgID", "Error validating orgID: "+err.Error())
+	} else if org == nil {
+		result.AddError("orgID", "Organization does not exist")
+	}
+
+	if !slices.Contains(models.WindowKindList, w.Kind) {
+		result.AddError("kind", "kind must be one of Reading Period or Contest")
+	}
+
+	closes, err := time.Parse("2006-01-02", w.Closes)
+	if err != nil {
+		result.AddError("closes", "closes must be a YYYY-MM-DD date")
+	}
+
+	if w.Opens != nil && *w.Opens == "" {
+		w.Opens = nil
+	}
+	if w.Opens != nil {
+		opens, err := time.Parse("2006-01-02", *w.Opens)
+		switch {
+		case err != nil:
+			result.AddError("opens", "opens must be a YYYY-MM-DD date")
+		case !w.RecursYearly && !closes.IsZero() && opens.After(closes):
+			result.AddError("opens", "opens must not be after closes")
+		}
+	} else if w.Kind == models.WindowKindReadingPeriod {
+		result.AddWarning("opens", "Reading period has no opening date")
+	}
+
+	if w.Fee != nil {
+		result.AddIfError(validation.NonNegativeFloat(*w.Fee, "fee"))
+	}
+	if w.URL != nil {
+		result.AddIfError(validation.ValidURL(*w.URL, "url"))
+	}
+
+	return result
+}
+
+func (db *DB) CreateSubmissionWindow(w *models.SubmissionWindow) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Create submission window")
+	defer end(&err)
+
+	result := db.validateSubmissionWindow(w)
+	if !result.IsValid() {
+		return &result, nil
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	res, err := db.conn.Exec(`INSERT INTO SubmissionWindows (
+		orgID, kind, name, opens, closes, recurs_yearly, fee, prize, url, created_at, modified_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		w.OrgID, w.Kind, w.Name, w.Opens, w.Closes, w.RecursYearly, w.Fee, w.Prize, w.URL, now, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("insert submission window: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("get last insert id: %w", err)
+	}
+	w.WindowID = id
+	w.CreatedAt = now
+	w.ModifiedAt = now
+	return &result, nil
+}
+
+func (db *DB) GetSubmissionWindow(id int64) (*models.SubmissionWindow, error) {
+	w, err := scanSubmissionWindow(db.conn.QueryRow(selectSubmissionWindows+` WHERE w.windowID = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query submission window: %w", err)
+	}
+	return w, nil
+}
+
+func (db *DB) UpdateSubmissionWindow(w *models.SubmissionWindow) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Update submission window")
+	defer end(&err)
+
+	result := db.validateSubmissionWindow(w)
+	if !result.IsValid() {
+		return &result, nil
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	_, err = db.conn.Exec(`UPDATE SubmissionWindows SET
+		orgID=?, kind=?, name=?, opens=?, closes=?, recurs_yearly=?, fee=?, prize=?, url=?, modified_at=?
+		WHERE windowID=?`,
+		w.OrgID, w.Kind, w.Name, w.Opens, w.Closes, w.RecursYearly, w.Fee, w.Prize, w.URL, now, w.WindowID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("update submission window: %w", err)
+	}
+	w.ModifiedAt = now
+	return &result, nil
+}
+
+func (db *DB) DeleteSubmissionWindow(id int64) (err error) {
+	db, end := db.auditOp("Delete submission window")
+	defer end(&err)
+
+	if _, err := db.conn.Exec(`DELETE FROM SubmissionWindows WHERE windowID = ?`, id); err != nil {
+		return fmt.Errorf("delete submission window: %w", err)
+	}
+	return nil
+}
+
+// ListSubmissionWindows returns an organization's windows, or every window
+// when orgID is zero. Windows of deleted organizations are omitted.
+func (db *DB) ListSubmissionWindows(orgID int64) ([]models.SubmissionWindow, error) {
+	query := selectSubmissionWindows + ` WHERE (o.attributes IS NULL OR o.attributes NOT LIKE '%deleted%')`
+	args := []any{}
+	if orgID > 0 {
+		query += ` AND w.orgID = ?`
+		args = append(args, orgID)
+	}
+	query += ` ORDER BY w.closes`
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query submission windows: %w", err)
+	}
+	defer rows.Close()
+
+	windows := []models.SubmissionWindow{}
+	for rows.Next() {
+		w, err := scanSubmissionWindow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan submission window: %w", err)
+		}
+		windows = append(windows, *w)
+	}
+	return windows, rows.Err()
+}
@@ -0,0 +1,183 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/validation"
+)
+
+const responseWithdrawn = "Withdrawn"
+
+// GetWithdrawalChecklist returns the pending submissions that conflict with
+// an accepted submission: other submissions of the same work or collection
+// and, when a collection is accepted, pending submissions of its works (they
+// will no longer be unpublished). Accepting a single work does not conflict
+// with pending collection submissions that contain it. It is an error to
+// ask for the checklist of a submission that was not accepted.
+func (db *DB) GetWithdrawalChecklist(acceptedID int64) (*models.WithdrawalChecklist, error) {
+	accepted, err := db.GetSubmission(acceptedID)
+	if err != nil {
+		return nil, err
+	}
+	if accepted == nil {
+		return nil, fmt.Errorf("submission not found")
+	}
+	if !accepted.IsAccepted() {
+		return nil, fmt.Errorf("submission %d has not been accepted", acceptedID)
+	}
+
+	checklist := &models.WithdrawalChecklist{
+		AcceptedID:   accepted.SubmissionID,
+		WorkID:       accepted.WorkID,
+		IsCollection: accepted.IsCollection,
+		Items:        []models.WithdrawalItem{},
+	}
+	if org, err := db.GetOrganization(accepted.OrgID); err == nil && org != nil {
+		checklist.OrgName = org.Name
+	}
+	if accepted.IsCollection {
+		if coll, err := db.GetCollection(accepted.WorkID); err == nil && coll != nil {
+			checklist.Title = coll.CollectionName
+		}
+	} else if work, err := db.GetWork(accepted.WorkID); err == nil && work != nil {
+		checklist.Title = work.Title
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT s.submissionID, s.orgID, COALESCE(o.name, ''), s.workID, COALESCE(s.is_collection, 0),
+			CASE WHEN s.is_collection = 1 THEN COALESCE(c.collection_name, '') ELSE COALESCE(w.title, '') END,
+			COALESCE(s.submission_date, ''),
+			COALESCE(NULLIF(s.web_address, ''), o.url, '')
+		FROM Submissions s
+		LEFT JOIN Works w ON s.is_collection = 0 AND s.workID = w.workID
+		LEFT JOIN Collections c ON s.is_collection = 1 AND s.workID = c.collID
+		LEFT JOIN Organizations o ON s.orgID = o.orgID
+		WHERE s.submissionID != ?
+		AND (s.response_type IS NULL OR s.response_type = '' OR s.response_type = 'Waiting')
+		AND (s.attributes IS NULL OR s.attributes NOT LIKE '%deleted%')
+		AND (
+			(COALESCE(s.is_collection, 0) = ? AND s.workID = ?)
+			OR (? = 1 AND COALESCE(s.is_collection, 0) = 0
+				AND s.workID IN (SELECT workID FROM CollectionDetails WHERE collID = ?))
+		)
+		ORDER BY s.submission_date`,
+		accepted.SubmissionID, accepted.IsCollection, accepted.WorkID, accepted.IsCollection, accepted.WorkID)
+	if err != nil {
+		return nil, fmt.Errorf("query conflicting submissions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.WithdrawalItem
+		if err := rows.Scan(&item.SubmissionID, &item.OrgID, &item.OrgName, &item.WorkID, &item.IsCollection,
+			&item.Title, &item.SubmissionDate, &item.WebAddress); err != nil {
+			return nil, fmt.Errorf("scan conflicting submission: %w", err)
+		}
+		switch {
+		case item.IsCollection == accepted.IsCollection && accepted.IsCollection:
+			item.Reason = "Same collection"
+		case item.IsCollection == accepted.IsCollection:
+			item.Reason = "Same work"
+		default:
+			item.Reason = "Included in accepted collection"
+		}
+		checklist.Items = append(checklist.Items, item)
+	}
+	return checklist, rows.Err()
+}
+
+// WithdrawSubmission records a "Withdrawn" response dated date (today if
+// empty) and attaches reason as a note on the submission.
+func (db *DB) WithdrawSubmission(id int64, date, reason string) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Withdraw submission")
+	defer end(&err)
+
+	sub, err := db.GetSubmission(id)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, fmt.Errorf("submission not found")
+	}
+	if !sub.IsPending() {
+		return nil, fmt.Errorf("submission %d already has a response (%s)", id, *sub.ResponseType)
+	}
+
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	withdrawn := responseWithdrawn
+	sub.ResponseType = &withdrawn
+	sub.ResponseDate = &date
+
+	result, err := db.UpdateSubmission(sub)
+	if err != nil || !result.IsValid() {
+		return result, err
+	}
+
+	noteType := "Response"
+	note := &models.Note{
+		EntityType: "submission",
+		EntityID:   id,
+		Type:       &noteType,
+		Note:       &reason,
+	}
+	if _, err := db.CreateNote(note); err != nil {
+		return result, fmt.Errorf("create withdrawal note: %w", err)
+	}
+
+	return result, nil
+}
+
+// WithdrawConflictingSubmissions withdraws the given entries from an
+// accepted submission's checklist, or all of them when ids is empty.
+// Each withdrawal is independent; failures are reported, not fatal.
+func (db *DB) WithdrawConflictingSubmissions(acceptedID int64, ids []int64, date string) (_ *models.WithdrawalResult, err error) {
+	db, end := db.auditOp("Withdraw conflicting submissions")
+	defer end(&err)
+
+	checklist, err := db.GetWithdrawalChecklist(acceptedID)
+	if err != nil {
+		return nil, err
+	}
+
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+
+	wanted := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	result := &models.WithdrawalResult{Withdrawn: []int64{}, Errors: []string{}}
+	for _, item := range checklist.Items {
+		if len(wanted) > 0 && !wanted[item.SubmissionID] {
+			continue
+		}
+		delete(wanted, item.SubmissionID)
+
+		reason := fmt.Sprintf("Withdrawn %s: %s was accepted by %s", date, checklist.Title, checklist.OrgName)
+		if item.Reason == "Included in accepted collection" {
+			reason = fmt.Sprintf("Withdrawn %s: %s was accepted by %s as part of %s", date, item.Title, checklist.OrgName, checklist.Title)
+		}
+
+		vr, err := db.WithdrawSubmission(item.SubmissionID, date, reason)
+		switch {
+		case err != nil:
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", item.OrgName, err))
+		case !vr.IsValid():
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", item.OrgName, vr.Errors[0].Message))
+		default:
+			result.Withdrawn = append(result.Withdrawn, item.SubmissionID)
+		}
+	}
+
+	for id := range wanted {
+		result.Errors = append(result.Errors, fmt.Sprintf("submission %d does not conflict with submission %d", id, acceptedID))
+	}
+
+	return result, nil
+}
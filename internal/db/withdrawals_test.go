@@ -0,0 +1,75 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func TestWithdrawConflictingSubmissions(t *testing.T) {
+	database := setupTestDB(t)
+
+	work := &models.Work{Title: "Rain", Type: "Poem", Status: "Out", Quality: "Good"}
+	if _, err := database.CreateWork(work); err != nil {
+		t.Fatalf("create work: %v", err)
+	}
+	submit := func(org, response string) int64 {
+		o := &models.Organization{Name: org, Status: "Open", Type: "Journal"}
+		if _, err := database.CreateOrganization(o); err != nil {
+			t.Fatalf("create organization: %v", err)
+		}
+		s := &models.Submission{WorkID: work.WorkID, OrgID: o.OrgID}
+		if response != "" {
+			s.ResponseType = &response
+		}
+		if result, err := database.CreateSubmission(s); err != nil || !result.IsValid() {
+			t.Fatalf("create submission: %v %v", result, err)
+		}
+		return s.SubmissionID
+	}
+
+	accepted := submit("Alpha", "Accepted")
+	pending := submit("Beta", "")
+	alsoPending := submit("Gamma", "Waiting")
+	withdrawn := submit("Delta", "Withdrawn")
+
+	tests := []struct {
+		name    string
+		id      int64
+		wantErr bool
+	}{
+		{"pending submission", pending, true},
+		{"already withdrawn submission", withdrawn, true},
+		{"missing submission", 9999, true},
+		{"accepted submission", accepted, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checklist, err := database.GetWithdrawalChecklist(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetWithdrawalChecklist: err = %v, wantErr %v", err, tt.wantErr)
+			}
+			result, err := database.WithdrawConflictingSubmissions(tt.id, nil, "2026-01-02")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WithdrawConflictingSubmissions: err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				for _, id := range []int64{pending, alsoPending} {
+					if sub, _ := database.GetSubmission(id); !sub.IsPending() {
+						t.Errorf("submission %d should still be pending", id)
+					}
+				}
+				return
+			}
+			if len(checklist.Items) != 2 {
+				t.Errorf("want the 2 pending submissions on the checklist, got %+v", checklist.Items)
+			}
+			if len(result.Withdrawn) != 2 || len(result.Errors) != 0 {
+				t.Errorf("want 2 withdrawn, got %+v", result)
+			}
+			if sub, _ := database.GetSubmission(withdrawn); sub.ResponseDate != nil {
+				t.Error("an already withdrawn submission should be left alone")
+			}
+		})
+	}
+}
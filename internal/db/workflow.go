@@ -20,7 +20,10 @@ func (db *DB) GetCollectionByName(name string) (int64, error) {
 	return collID, nil
 }
 
-func (db *DB) UpdateCollectionMembership(workID int64, oldStatus, newStatus string) error {
+func (db *DB) UpdateCollectionMembership(workID int64, oldStatus, newStatus string) (err error) {
+	db, end := db.auditOp("Update collection membership")
+	defer end(&err)
+
 	if oldStatus == newStatus {
 		return nil
 	}
@@ -43,7 +43,10 @@ func (db *DB) validateWork(w *models.Work) validation.ValidationResult {
 	return result
 }
 
-func (db *DB) CreateWork(w *models.Work) (*validation.ValidationResult, error) {
+func (db *DB) CreateWork(w *models.Work) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Create work")
+	defer end(&err)
+
 	// Validate the work
 	result := db.validateWork(w)
 	if !result.IsValid() {
@@ -134,7 +137,10 @@ func (db *DB) GetWorkByPath(path string) (*models.Work, error) {
 	return w, nil
 }
 
-func (db *DB) UpdateWork(w *models.Work) (*validation.ValidationResult, error) {
+func (db *DB) UpdateWork(w *models.Work) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Update work")
+	defer end(&err)
+
 	// Validate the work
 	result := db.validateWork(w)
 	if !result.IsValid() {
@@ -147,7 +153,7 @@ func (db *DB) UpdateWork(w *models.Work) (*validation.ValidationResult, error) {
 		access_date=?, modified_at=CURRENT_TIMESTAMP
 		WHERE workID=?`
 
-	_, err := db.conn.Exec(query,
+	_, err = db.conn.Exec(query,
 		w.Title, w.Type, w.Year, w.Status, w.Quality, w.QualityAtPublish, w.DocType,
 		w.Path, w.Draft, w.NWords, w.CourseName, w.Attributes,
 		w.AccessDate, w.WorkID,
@@ -166,7 +172,57 @@ func (db *DB) UpdateWork(w *models.Work) (*validation.ValidationResult, error) {
 	return &result, nil
 }
 
-func (db *DB) DeleteWork(id int64) error {
+// UpdateWorksField sets one column to the same value on several works as a
+// single undoable change. The caller checks column against its whitelist.
+func (db *DB) UpdateWorksField(workIDs []int64, column, value string) (_ int, err error) {
+	db, end := db.auditOp("Batch update works")
+	defer end(&err)
+
+	query := fmt.Sprintf("UPDATE Works SET %s = ? WHERE workID = ?", column)
+	updated := 0
+	for _, id := range workIDs {
+		if _, err := db.conn.Exec(query, value, id); err != nil {
+			return 0, fmt.Errorf("update work %d: %w", id, err)
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// SetWorkSkipAudits sets or clears the skip_audits flag for a work
+func (db *DB) SetWorkSkipAudits(workID int64, skip bool) (err error) {
+	db, end := db.auditOp("Skip audits")
+	defer end(&err)
+
+	skipVal := 0
+	if skip {
+		skipVal = 1
+	}
+	if _, err := db.conn.Exec("UPDATE Works SET skip_audits = ? WHERE workID = ?", skipVal, workID); err != nil {
+		return fmt.Errorf("set skip audits: %w", err)
+	}
+	return nil
+}
+
+// RenameFieldValue replaces one value of a column with another in every
+// row of table, as a single undoable change. The caller checks table and
+// column against its whitelist.
+func (db *DB) RenameFieldValue(table, column, oldValue, newValue string) (_ int64, err error) {
+	db, end := db.auditOp("Rename value")
+	defer end(&err)
+
+	query := fmt.Sprintf(`UPDATE %s SET %s = ? WHERE %s = ?`, table, column, column)
+	result, err := db.conn.Exec(query, newValue, oldValue)
+	if err != nil {
+		return 0, fmt.Errorf("update failed: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (db *DB) DeleteWork(id int64) (err error) {
+	db, end := db.auditOp("Delete work")
+	defer end(&err)
+
 	work, err := db.GetWork(id)
 	if err != nil {
 		return fmt.Errorf("get work: %w", err)
@@ -193,7 +249,10 @@ func (db *DB) DeleteWork(id int64) error {
 	return nil
 }
 
-func (db *DB) UndeleteWork(id int64) (*validation.ValidationResult, error) {
+func (db *DB) UndeleteWork(id int64) (_ *validation.ValidationResult, err error) {
+	db, end := db.auditOp("Undelete work")
+	defer end(&err)
+
 	work, err := db.GetWork(id)
 	if err != nil {
 		return nil, fmt.Errorf("get work: %w", err)
@@ -303,9 +362,12 @@ func (db *DB) GetWorkDeleteConfirmation(workID int64) (*DeleteConfirmation, erro
 // DeleteWorkPermanent permanently deletes a work and all its orphaned data
 // CASCADE will automatically delete submissions and collection_details
 // We manually delete notes since they use a polymorphic pattern
-func (db *DB) DeleteWorkPermanent(workID int64) error {
+func (db *DB) DeleteWorkPermanent(workID int64) (err error) {
+	db, end := db.auditOp("Permanently delete work")
+	defer end(&err)
+
 	// Delete notes manually (polymorphic FK not supported by CASCADE)
-	_, err := db.conn.Exec(`DELETE FROM Notes WHERE entity_type = 'work' AND entity_id = ?`, workID)
+	_, err = db.conn.Exec(`DELETE FROM Notes WHERE entity_type = 'work' AND entity_id = ?`, workID)
 	if err != nil {
 		return fmt.Errorf("delete work notes: %w", err)
 	}
@@ -0,0 +1,357 @@
+package epub
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// baseCSS lays out the pages the exporter generates itself. Template styles
+// are appended after it.
+const baseCSS = `body { margin: 0; }
+p { margin: 0; }
+h1, h2, h3, h4, h5, h6 { page-break-after: avoid; }
+.tab { display: inline-block; width: 2em; }
+.small-caps { font-variant: small-caps; }
+.page-break { page-break-before: always; }
+.cover { margin: 0; padding: 0; text-align: center; }
+.cover img { max-width: 100%; height: 100%; }
+.part { margin-top: 30%; text-align: center; }
+.part-title { font-weight: normal; }
+.matter { margin-top: 10%; }
+.matter-titlepage, .matter-dedication { text-align: center; }
+.matter-copyright { font-size: 0.85em; }
+nav#toc ol { list-style-type: none; padding-left: 1em; }
+`
+
+type xmlVal struct {
+	Val string `xml:"val,attr"`
+}
+
+// xmlOnOff is a toggle such as <w:b/>, on unless its value says otherwise
+type xmlOnOff struct {
+	Val string `xml:"val,attr"`
+}
+
+func (o *xmlOnOff) on() bool {
+	switch strings.ToLower(o.Val) {
+	case "0", "false", "off", "none":
+		return false
+	}
+	return true
+}
+
+type xmlRunProps struct {
+	Style *xmlVal `xml:"rStyle"`
+	Fonts *struct {
+		ASCII string `xml:"ascii,attr"`
+	} `xml:"rFonts"`
+	Size      *xmlVal   `xml:"sz"`
+	Bold      *xmlOnOff `xml:"b"`
+	Italic    *xmlOnOff `xml:"i"`
+	Underline *xmlVal   `xml:"u"`
+	Caps      *xmlOnOff `xml:"caps"`
+	SmallCaps *xmlOnOff `xml:"smallCaps"`
+	Vanish    *xmlOnOff `xml:"vanish"`
+	VertAlign *xmlVal   `xml:"vertAlign"`
+}
+
+// format is the run's direct formatting
+func (r *xmlRunProps) format() runFormat {
+	var f runFormat
+	if r.Style != nil {
+		f.class = r.Style.Val
+	}
+	f.bold = r.Bold != nil && r.Bold.on()
+	f.italic = r.Italic != nil && r.Italic.on()
+	f.underline = r.Underline != nil && r.Underline.Val != "" && r.Underline.Val != "none"
+	f.smallCaps = r.SmallCaps != nil && r.SmallCaps.on()
+	if r.VertAlign != nil {
+		f.vertAlign = r.VertAlign.Val
+	}
+	return f
+}
+
+type xmlParaProps struct {
+	Jc      *xmlVal `xml:"jc"`
+	Spacing *struct {
+		Before string `xml:"before,attr"`
+		After  string `xml:"after,attr"`
+	} `xml:"spacing"`
+	Ind *struct {
+		Left      string `xml:"left,attr"`
+		Start     string `xml:"start,attr"`
+		Right     string `xml:"right,attr"`
+		End       string `xml:"end,attr"`
+		FirstLine string `xml:"firstLine,attr"`
+		Hanging   string `xml:"hanging,attr"`
+	} `xml:"ind"`
+	PageBreakBefore *xmlOnOff `xml:"pageBreakBefore"`
+}
+
+type xmlStyle struct {
+	Type    string        `xml:"type,attr"`
+	ID      string        `xml:"styleId,attr"`
+	Default string        `xml:"default,attr"`
+	BasedOn *xmlVal       `xml:"basedOn"`
+	PPr     *xmlParaProps `xml:"pPr"`
+	RPr     *xmlRunProps  `xml:"rPr"`
+}
+
+type xmlStyleSheet struct {
+	RunDefaults  *xmlRunProps  `xml:"docDefaults>rPrDefault>rPr"`
+	ParaDefaults *xmlParaProps `xml:"docDefaults>pPrDefault>pPr"`
+	Styles       []xmlStyle    `xml:"style"`
+}
+
+// cssRule collects the CSS declarations of a style, later ones replacing
+// earlier ones as a style overrides the one it is based on
+type cssRule map[string]string
+
+// templateCSS turns the paragraph and character styles of the template into
+// classes for the converted works: .s-<id> for paragraph styles and
+// .c-<id> for character styles. Sizes and spacing are in ems relative to
+// the default paragraph style so readers can still scale the text. Without
+// a readable template only the base styles are returned.
+func templateCSS(templatePath string) (string, error) {
+	if templatePath == "" {
+		return baseCSS, nil
+	}
+	data, err := readStylesXML(templatePath)
+	if err != nil {
+		return baseCSS, err
+	}
+	var sheet xmlStyleSheet
+	if err := xml.Unmarshal(data, &sheet); err != nil {
+		return baseCSS, fmt.Errorf("parse styles.xml: %w", err)
+	}
+
+	styles := map[string]*xmlStyle{}
+	defaultPara := ""
+	for i := range sheet.Styles {
+		s := &sheet.Styles[i]
+		styles[s.ID] = s
+		if s.Type == "paragraph" && (s.Default == "1" || s.Default == "true") {
+			defaultPara = s.ID
+		}
+	}
+
+	// chain lists a style and its ancestors, base first
+	chain := func(id string) []*xmlStyle {
+		var out []*xmlStyle
+		for seen := map[string]bool{}; id != "" && !seen[id]; {
+			seen[id] = true
+			s, ok := styles[id]
+			if !ok {
+				break
+			}
+			out = append([]*xmlStyle{s}, out...)
+			id = ""
+			if s.BasedOn != nil {
+				id = s.BasedOn.Val
+			}
+		}
+		return out
+	}
+
+	base := 12.0
+	if sheet.RunDefaults != nil {
+		base = halfPoints(sheet.RunDefaults.Size, base)
+	}
+	for _, s := range chain(defaultPara) {
+		if s.RPr != nil {
+			base = halfPoints(s.RPr.Size, base)
+		}
+	}
+
+	resolve := func(id string, withDefaults bool) cssRule {
+		rule := cssRule{}
+		if withDefaults {
+			rule.applyPara(sheet.ParaDefaults, base)
+			rule.applyRun(sheet.RunDefaults, base)
+		}
+		for _, s := range chain(id) {
+			rule.applyPara(s.PPr, base)
+			rule.applyRun(s.RPr, base)
+		}
+		return rule
+	}
+
+	var out strings.Builder
+	out.WriteString(baseCSS)
+	out.WriteString("\n/* template styles */\n")
+	if defaultPara != "" {
+		out.WriteString(resolve(defaultPara, true).css("p"))
+	}
+
+	ids := make([]string, 0, len(styles))
+	for id := range styles {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		switch styles[id].Type {
+		case "paragraph":
+			out.WriteString(resolve(id, true).css(".s-" + cssIdent(id)))
+		case "character":
+			out.WriteString(resolve(id, false).css(".c-" + cssIdent(id)))
+		}
+	}
+	return out.String(), nil
+}
+
+func readStylesXML(docxPath string) ([]byte, error) {
+	reader, err := zip.OpenReader(docxPath)
+	if err != nil {
+		return nil, fmt.Errorf("open template: %w", err)
+	}
+	defer reader.Close()
+	for _, file := range reader.File {
+		if file.Name != "word/styles.xml" {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("open styles.xml: %w", err)
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("styles.xml not found in template")
+}
+
+func (r cssRule) applyPara(p *xmlParaProps, base float64) {
+	if p == nil {
+		return
+	}
+	if p.Jc != nil {
+		switch p.Jc.Val {
+		case "center":
+			r["text-align"] = "center"
+		case "right", "end":
+			r["text-align"] = "right"
+		case "both", "distribute":
+			r["text-align"] = "justify"
+		default:
+			r["text-align"] = "left"
+		}
+	}
+	if sp := p.Spacing; sp != nil {
+		r.setEm("margin-top", sp.Before, base)
+		r.setEm("margin-bottom", sp.After, base)
+	}
+	if ind := p.Ind; ind != nil {
+		r.setEm("margin-left", ind.Start, base)
+		r.setEm("margin-left", ind.Left, base)
+		r.setEm("margin-right", ind.End, base)
+		r.setEm("margin-right", ind.Right, base)
+		r.setEm("text-indent", ind.FirstLine, base)
+		if ind.Hanging != "" {
+			r.setEm("text-indent", "-"+ind.Hanging, base)
+		}
+	}
+	if p.PageBreakBefore != nil {
+		if p.PageBreakBefore.on() {
+			r["page-break-before"] = "always"
+		} else {
+			delete(r, "page-break-before")
+		}
+	}
+}
+
+func (r cssRule) applyRun(p *xmlRunProps, base float64) {
+	if p == nil {
+		return
+	}
+	if p.Fonts != nil && p.Fonts.ASCII != "" {
+		r["font-family"] = strconv.Quote(p.Fonts.ASCII) + ", serif"
+	}
+	if size := halfPoints(p.Size, 0); size > 0 {
+		r["font-size"] = ems(size / base)
+	}
+	toggle := func(o *xmlOnOff, prop, on, off string) {
+		if o == nil {
+			return
+		}
+		if o.on() {
+			r[prop] = on
+		} else {
+			r[prop] = off
+		}
+	}
+	toggle(p.Bold, "font-weight", "bold", "normal")
+	toggle(p.Italic, "font-style", "italic", "normal")
+	toggle(p.SmallCaps, "font-variant", "small-caps", "normal")
+	toggle(p.Caps, "text-transform", "uppercase", "none")
+	if p.Underline != nil {
+		if p.Underline.Val != "" && p.Underline.Val != "none" {
+			r["text-decoration"] = "underline"
+		} else {
+			r["text-decoration"] = "none"
+		}
+	}
+}
+
+// setEm sets prop from a length in twips
+func (r cssRule) setEm(prop, twips string, base float64) {
+	if twips == "" {
+		return
+	}
+	v, err := strconv.ParseFloat(twips, 64)
+	if err != nil {
+		return
+	}
+	r[prop] = ems(v / 20 / base)
+}
+
+func (r cssRule) css(selector string) string {
+	if len(r) == 0 {
+		return ""
+	}
+	props := make([]string, 0, len(r))
+	for prop := range r {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+	var b strings.Builder
+	b.WriteString(selector + " {")
+	for _, prop := range props {
+		fmt.Fprintf(&b, " %s: %s;", prop, r[prop])
+	}
+	b.WriteString(" }\n")
+	return b.String()
+}
+
+func ems(v float64) string {
+	v = math.Round(v*1000) / 1000
+	if v == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64) + "em"
+}
+
+func halfPoints(v *xmlVal, fallback float64) float64 {
+	if v == nil {
+		return fallback
+	}
+	n, err := strconv.ParseFloat(v.Val, 64)
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n / 2
+}
+
+// cssIdent makes a style ID safe for use as a class name
+func cssIdent(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,279 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// runFormat is the direct formatting of a run that carries over to XHTML.
+// Everything else comes from the paragraph and character style classes.
+type runFormat struct {
+	class     string // character style
+	bold      bool
+	italic    bool
+	underline bool
+	smallCaps bool
+	vertAlign string
+}
+
+type run struct {
+	format runFormat
+	text   string // escaped XHTML
+}
+
+// workXHTML converts the body of a work's DOCX to XHTML. Paragraphs keep
+// their style as a class; drawings, deleted text and field codes are left
+// out.
+func workXHTML(docxPath string) (string, error) {
+	if docxPath == "" {
+		return "", fmt.Errorf("no document")
+	}
+	reader, err := zip.OpenReader(docxPath)
+	if err != nil {
+		return "", fmt.Errorf("open docx: %w", err)
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		if file.Name != "word/document.xml" {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return "", fmt.Errorf("open document.xml: %w", err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			return "", fmt.Errorf("read document.xml: %w", err)
+		}
+		return documentXHTML(data)
+	}
+	return "", fmt.Errorf("document.xml not found")
+}
+
+func documentXHTML(data []byte) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var out strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return out.String(), nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("parse document.xml: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "p":
+			if err := writeParagraph(dec, &out); err != nil {
+				return "", err
+			}
+		case "del", "moveFrom", "drawing", "pict", "object", "sectPr":
+			if err := dec.Skip(); err != nil {
+				return "", err
+			}
+		}
+	}
+}
+
+// writeParagraph converts a <w:p> element up to its end tag
+func writeParagraph(dec *xml.Decoder, out *strings.Builder) error {
+	style := ""
+	pageBreak := false
+	var runs []run
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("parse paragraph: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			depth--
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "pPr":
+				var pPr struct {
+					Style *struct {
+						Val string `xml:"val,attr"`
+					} `xml:"pStyle"`
+					PageBreakBefore *struct{} `xml:"pageBreakBefore"`
+				}
+				if err := dec.DecodeElement(&pPr, &t); err != nil {
+					return fmt.Errorf("parse paragraph properties: %w", err)
+				}
+				if pPr.Style != nil {
+					style = pPr.Style.Val
+				}
+				pageBreak = pPr.PageBreakBefore != nil
+			case "r":
+				r, err := readRun(dec)
+				if err != nil {
+					return err
+				}
+				runs = append(runs, r...)
+			case "del", "moveFrom", "drawing", "pict", "object":
+				if err := dec.Skip(); err != nil {
+					return err
+				}
+			default:
+				// hyperlinks, insertions and content controls wrap runs
+				depth++
+			}
+		}
+	}
+
+	tag := "p"
+	if level := headingLevel(style); level > 0 {
+		tag = fmt.Sprintf("h%d", level)
+	}
+	var classes []string
+	if style != "" {
+		classes = append(classes, "s-"+cssIdent(style))
+	}
+	if pageBreak {
+		classes = append(classes, "page-break")
+	}
+	out.WriteString("<" + tag)
+	if len(classes) > 0 {
+		out.WriteString(` class="` + strings.Join(classes, " ") + `"`)
+	}
+	out.WriteString(">")
+
+	content := joinRuns(runs)
+	if strings.TrimSpace(strings.ReplaceAll(content, "<br/>", "")) == "" {
+		// an empty paragraph is a blank line, such as a stanza break
+		content += "\u00a0"
+	}
+	out.WriteString(content)
+	out.WriteString("</" + tag + ">\n")
+	return nil
+}
+
+// readRun reads a <w:r> element into runs of text, one per break so breaks
+// stay outside the formatting elements
+func readRun(dec *xml.Decoder) ([]run, error) {
+	var format runFormat
+	var runs []run
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			runs = append(runs, run{text: text.String()})
+			text.Reset()
+		}
+	}
+	hidden := false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("parse run: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			flush()
+			if hidden {
+				return nil, nil
+			}
+			for i := range runs {
+				if runs[i].text != "<br/>" {
+					runs[i].format = format
+				}
+			}
+			return runs, nil
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "rPr":
+				var rPr xmlRunProps
+				if err := dec.DecodeElement(&rPr, &t); err != nil {
+					return nil, fmt.Errorf("parse run properties: %w", err)
+				}
+				format = rPr.format()
+				hidden = rPr.Vanish != nil && rPr.Vanish.on()
+				continue
+			case "t":
+				var s string
+				if err := dec.DecodeElement(&s, &t); err != nil {
+					return nil, fmt.Errorf("parse text: %w", err)
+				}
+				text.WriteString(xmlEscape(s))
+				continue
+			case "tab", "ptab":
+				text.WriteString("<span class=\"tab\">\u2003</span>")
+			case "br", "cr":
+				flush()
+				runs = append(runs, run{text: "<br/>"})
+			case "noBreakHyphen":
+				text.WriteString("\u2011")
+			}
+			if err := dec.Skip(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// joinRuns writes runs as XHTML, merging neighbours with the same format
+func joinRuns(runs []run) string {
+	var out strings.Builder
+	for i := 0; i < len(runs); {
+		f := runs[i].format
+		var text strings.Builder
+		j := i
+		for j < len(runs) && runs[j].format == f && runs[j].text != "<br/>" {
+			text.WriteString(runs[j].text)
+			j++
+		}
+		if j == i {
+			out.WriteString(runs[i].text)
+			i++
+			continue
+		}
+		out.WriteString(wrapFormat(f, text.String()))
+		i = j
+	}
+	return out.String()
+}
+
+func wrapFormat(f runFormat, s string) string {
+	if f.vertAlign == "superscript" {
+		s = "<sup>" + s + "</sup>"
+	} else if f.vertAlign == "subscript" {
+		s = "<sub>" + s + "</sub>"
+	}
+	if f.smallCaps {
+		s = `<span class="small-caps">` + s + "</span>"
+	}
+	if f.underline {
+		s = "<u>" + s + "</u>"
+	}
+	if f.italic {
+		s = "<i>" + s + "</i>"
+	}
+	if f.bold {
+		s = "<b>" + s + "</b>"
+	}
+	if f.class != "" {
+		s = `<span class="c-` + cssIdent(f.class) + `">` + s + "</span>"
+	}
+	return s
+}
+
+// headingLevel maps Word's title and heading styles to XHTML headings
+func headingLevel(style string) int {
+	switch s := strings.ToLower(style); {
+	case s == "title":
+		return 1
+	case strings.HasPrefix(s, "heading") && len(s) == len("heading")+1:
+		if n := int(s[len(s)-1] - '0'); n >= 1 && n <= 5 {
+			return n + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,201 @@
+// Package epub writes a book collection as an EPUB 3 file. It walks the
+// same manifest as the PDF pipeline: each work's DOCX is reflowed as XHTML
+// styled from the book template, front and back matter come from the HTML
+// the app renders for the galley, and a navigation document follows the
+// parts and works.
+package epub
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+// Metadata is the book information written to the package document
+type Metadata struct {
+	Title       string
+	Subtitle    string
+	Author      string
+	ISBN        string
+	Description string
+	Publisher   string
+	Date        string // publication date, YYYY-MM-DD
+	Language    string // BCP 47 tag, "en" when empty
+	// Modified is the dcterms:modified stamp; the build time when zero
+	Modified time.Time
+}
+
+// MetadataFromBook maps a book's settings onto package metadata
+func MetadataFromBook(book *models.Book) Metadata {
+	description := deref(book.DescriptionLong)
+	if description == "" {
+		description = deref(book.DescriptionShort)
+	}
+	return Metadata{
+		Title:       book.Title,
+		Subtitle:    deref(book.Subtitle),
+		Author:      book.Author,
+		ISBN:        deref(book.ISBN),
+		Description: description,
+		Publisher:   deref(book.Publisher),
+		Date:        deref(book.PublishedDate),
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// Section is a front or back matter page given as a complete HTML document
+type Section struct {
+	Type  string // titlepage, copyright, dedication, afterword, ack, about
+	Title string
+	HTML  string
+}
+
+type Options struct {
+	Ctx         context.Context
+	Manifest    *bookbuild.Manifest
+	Metadata    Metadata
+	FrontMatter []Section
+	BackMatter  []Section
+	CoverPath   string
+	OutputPath  string
+	OnProgress  bookbuild.ProgressFunc
+}
+
+type Result struct {
+	OutputPath string   `json:"outputPath"`
+	WorkCount  int      `json:"workCount"`
+	Warnings   []string `json:"warnings,omitempty"`
+}
+
+// Build writes the EPUB. Works whose documents cannot be read are left out
+// with a warning; a structural problem in the assembled book is an error
+// and nothing is written.
+func Build(opts Options) (*Result, error) {
+	if opts.Manifest == nil {
+		return nil, fmt.Errorf("manifest is required")
+	}
+	if opts.OutputPath == "" {
+		return nil, fmt.Errorf("output path is required")
+	}
+	ctx := opts.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	progress := opts.OnProgress
+	if progress == nil {
+		progress = func(string, int, int, string) {}
+	}
+
+	meta := opts.Metadata
+	if meta.Title == "" {
+		meta.Title = opts.Manifest.Title
+	}
+	if meta.Author == "" {
+		meta.Author = opts.Manifest.Author
+	}
+	if meta.Language == "" {
+		meta.Language = "en"
+	}
+	if meta.Modified.IsZero() {
+		meta.Modified = time.Now()
+	}
+
+	b := newBook(meta)
+	result := &Result{OutputPath: opts.OutputPath}
+
+	styles, err := templateCSS(opts.Manifest.TemplatePath)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("template styles: %v", err))
+	}
+	b.add(&item{id: "css", href: "styles.css", mediaType: "text/css", data: []byte(styles)})
+
+	if opts.CoverPath != "" {
+		if err := b.addCover(opts.CoverPath); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("cover: %v", err))
+		}
+	}
+
+	for i, s := range opts.FrontMatter {
+		b.addMatter(fmt.Sprintf("front-%d", i+1), s, "frontmatter")
+	}
+	b.addNavPage()
+
+	total := 0
+	for _, part := range opts.Manifest.Parts {
+		total += len(part.Works)
+	}
+	total += len(opts.Manifest.Works)
+
+	done := 0
+	addWork := func(w bookbuild.Work, parent *navEntry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		done++
+		progress("Converting", done, total, w.Title)
+		body, err := workXHTML(w.Source)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", w.Title, err))
+			return nil
+		}
+		b.addWork(w, body, parent)
+		result.WorkCount++
+		return nil
+	}
+
+	for i, part := range opts.Manifest.Parts {
+		var parent *navEntry
+		if !part.NoDivider {
+			parent = b.addPart(i+1, part.Title)
+		}
+		for _, w := range part.Works {
+			if err := addWork(w, parent); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, w := range opts.Manifest.Works {
+		if err := addWork(w, nil); err != nil {
+			return nil, err
+		}
+	}
+	if result.WorkCount == 0 {
+		return nil, fmt.Errorf("no works could be converted")
+	}
+
+	for i, s := range opts.BackMatter {
+		b.addMatter(fmt.Sprintf("back-%d", i+1), s, "backmatter")
+	}
+
+	progress("Packaging", 1, 1, "Writing EPUB...")
+	b.finish()
+	if problems := b.validate(); len(problems) > 0 {
+		return nil, fmt.Errorf("invalid epub: %s", strings.Join(problems, "; "))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(opts.OutputPath), 0755); err != nil {
+		return nil, fmt.Errorf("create output dir: %w", err)
+	}
+	tmp := opts.OutputPath + ".tmp"
+	if err := b.write(tmp); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, opts.OutputPath); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("move epub into place: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,204 @@
+package epub
+
+import (
+	"archive/zip"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+)
+
+const testStyles = `<?xml version="1.0" encoding="UTF-8"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:docDefaults><w:rPrDefault><w:rPr><w:sz w:val="24"/></w:rPr></w:rPrDefault></w:docDefaults>
+  <w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:rPr><w:rFonts w:ascii="Garamond"/></w:rPr></w:style>
+  <w:style w:type="paragraph" w:styleId="Title"><w:basedOn w:val="Normal"/><w:pPr><w:jc w:val="center"/></w:pPr><w:rPr><w:sz w:val="36"/></w:rPr></w:style>
+  <w:style w:type="paragraph" w:styleId="Verse"><w:basedOn w:val="Normal"/><w:pPr><w:ind w:left="720" w:hanging="360"/></w:pPr></w:style>
+  <w:style w:type="character" w:styleId="Emphasis"><w:rPr><w:i/></w:rPr></w:style>
+</w:styles>`
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeWork(t *testing.T, path, title string, lines ...string) {
+	t.Helper()
+	body := `<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr><w:r><w:t>` + title + `</w:t></w:r></w:p>`
+	for _, line := range lines {
+		body += `<w:p><w:pPr><w:pStyle w:val="Verse"/></w:pPr>` +
+			`<w:r><w:t xml:space="preserve">` + line + ` </w:t></w:r>` +
+			`<w:r><w:rPr><w:rStyle w:val="Emphasis"/><w:b/></w:rPr><w:t>&amp; more</w:t></w:r></w:p>`
+	}
+	body += `<w:p/>`
+	writeZip(t, path, map[string]string{
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` + body + `<w:sectPr/></w:body></w:document>`,
+	})
+}
+
+func readEPUB(t *testing.T, path string) (map[string]string, []string) {
+	t.Helper()
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	files := map[string]string{}
+	var order []string
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+		order = append(order, f.Name)
+	}
+	if r.File[0].Method != zip.Store {
+		t.Error("mimetype must be stored uncompressed")
+	}
+	return files, order
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	template := filepath.Join(dir, "template.dotx")
+	writeZip(t, template, map[string]string{"word/styles.xml": testStyles})
+	first := filepath.Join(dir, "first.docx")
+	writeWork(t, first, "First Poem", "one line", "another line")
+	second := filepath.Join(dir, "second.docx")
+	writeWork(t, second, "Second Poem", "a line")
+
+	cover := filepath.Join(dir, "cover.png")
+	f, err := os.Create(cover)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, 4, 6))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	manifest := &bookbuild.Manifest{
+		Title:        "Collected",
+		Author:       "A. Poet",
+		TemplatePath: template,
+		Parts: []bookbuild.Part{{
+			ID:    10,
+			Title: "Part One",
+			Works: []bookbuild.Work{
+				{ID: 1, Title: "First Poem", Source: first},
+				{ID: 2, Title: "Second Poem", Source: second},
+				{ID: 3, Title: "Missing", Source: filepath.Join(dir, "missing.docx")},
+			},
+		}},
+	}
+	out := filepath.Join(dir, "book.epub")
+	result, err := Build(Options{
+		Manifest: manifest,
+		Metadata: Metadata{ISBN: "978-1-23456-789-7", Description: "Poems & more"},
+		FrontMatter: []Section{
+			{Type: "copyright", HTML: `<html><head><style>@page { size: 6in 9in }</style></head>` +
+				`<body><div class="page" style="height: 9in"><p class="copyrightText">&copy; 2026&nbsp;A. Poet<br>All rights reserved</p><script>x()</script></div></body></html>`},
+		},
+		BackMatter: []Section{{Type: "about", HTML: `<p>Lives by the sea.</p>`}},
+		CoverPath:  cover,
+		OutputPath: out,
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if result.WorkCount != 2 || len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "Missing") {
+		t.Errorf("expected two works and a warning for the missing one, got %+v", result)
+	}
+
+	files, order := readEPUB(t, out)
+	if order[0] != "mimetype" || files["mimetype"] != "application/epub+zip" {
+		t.Errorf("mimetype must come first, got %v", order)
+	}
+	opf := files["OEBPS/content.opf"]
+	for _, want := range []string{
+		"urn:isbn:9781234567897", "<dc:title id=\"title\">Collected</dc:title>", "A. Poet",
+		"Poems &amp; more", `properties="cover-image"`, `properties="nav"`, "dcterms:modified",
+		`<itemref idref="cover"/>`, `<itemref idref="work-2"/>`,
+	} {
+		if !strings.Contains(opf, want) {
+			t.Errorf("content.opf missing %q", want)
+		}
+	}
+
+	nav := files["OEBPS/nav.xhtml"]
+	if !strings.Contains(nav, `<a href="part-1.xhtml">Part One</a><ol><li><a href="work-1.xhtml">First Poem</a>`) {
+		t.Errorf("nav should nest works under their part:\n%s", nav)
+	}
+	if !strings.Contains(nav, "About the Author") {
+		t.Error("nav should list back matter")
+	}
+
+	work := files["OEBPS/work-1.xhtml"]
+	for _, want := range []string{`<h1 class="s-Title">First Poem</h1>`, `<p class="s-Verse">one line <span class="c-Emphasis"><b>&amp; more</b></span></p>`} {
+		if !strings.Contains(work, want) {
+			t.Errorf("work page missing %q:\n%s", want, work)
+		}
+	}
+
+	matter := files["OEBPS/front-1.xhtml"]
+	if strings.Contains(matter, "script") || strings.Contains(matter, "style=") || !strings.Contains(matter, "<br/>") {
+		t.Errorf("front matter not cleaned up:\n%s", matter)
+	}
+
+	css := files["OEBPS/styles.css"]
+	if !strings.Contains(css, `.s-Verse { font-family: "Garamond", serif; font-size: 1em; margin-left: 3em; text-indent: -1.5em; }`) {
+		t.Errorf("template styles not carried into the CSS:\n%s", css)
+	}
+}
+
+func TestValidateCatchesBrokenBooks(t *testing.T) {
+	b := newBook(Metadata{Title: "T", Language: "en"})
+	b.add(&item{id: "css", href: "styles.css", mediaType: "text/css", data: []byte("")})
+	b.addNavPage()
+	b.addPage(&item{id: "1bad", href: "a.xhtml", data: xhtmlPage("A", "en", `<p>&nbsp;<img src="images/gone.png"/></p>`)})
+	b.addPage(&item{id: "dup", href: "b.xhtml", data: xhtmlPage("B", "en", `<p>fine</p>`)})
+	b.addPage(&item{id: "dup", href: "c.xhtml", data: xhtmlPage("C", "en", `<p>fine</p>`)})
+	b.finish()
+
+	problems := strings.Join(b.validate(), "\n")
+	for _, want := range []string{`invalid manifest id "1bad"`, `duplicate manifest id "dup"`, "a.xhtml"} {
+		if !strings.Contains(problems, want) {
+			t.Errorf("expected a problem mentioning %q, got:\n%s", want, problems)
+		}
+	}
+
+	b = newBook(Metadata{Title: "T", Language: "en"})
+	b.add(&item{id: "css", href: "styles.css", mediaType: "text/css", data: []byte("")})
+	b.addNavPage()
+	b.addPage(&item{id: "a", href: "a.xhtml", data: xhtmlPage("A", "en", `<p>fine</p>`)})
+	b.finish()
+	if problems := b.validate(); len(problems) > 0 {
+		t.Errorf("expected a valid book, got %v", problems)
+	}
+}
@@ -0,0 +1,74 @@
+package epub
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// droppedElements do not belong in a reflowable page or cannot be carried
+// into the package
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Link: true, atom.Meta: true,
+	atom.Iframe: true, atom.Object: true, atom.Embed: true, atom.Form: true,
+	atom.Input: true, atom.Button: true, atom.Img: true, atom.Video: true,
+	atom.Audio: true, atom.Canvas: true, atom.Svg: true,
+}
+
+// matterXHTML converts a front or back matter page, written as a complete
+// HTML document for the print galley, into XHTML body content. The page's
+// own style sheet and inline styles size it for print, so they are dropped
+// and the classes are kept for the book's style sheet.
+func matterXHTML(doc string) string {
+	root, err := html.Parse(strings.NewReader(doc))
+	if err != nil {
+		return ""
+	}
+	body := findElement(root, atom.Body)
+	if body == nil {
+		return ""
+	}
+
+	var out strings.Builder
+	clean(body)
+	for c := body.FirstChild; c != nil; c = c.NextSibling {
+		_ = html.Render(&out, c)
+	}
+	return out.String()
+}
+
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// clean removes dropped elements, comments, inline styles and event
+// handlers below n
+func clean(n *html.Node) {
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		if a.Namespace != "" || a.Key == "style" || strings.HasPrefix(a.Key, "on") {
+			continue
+		}
+		attrs = append(attrs, a)
+	}
+	n.Attr = attrs
+
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.CommentNode || c.Type == html.ElementNode && droppedElements[c.DataAtom] {
+			n.RemoveChild(c)
+		} else {
+			clean(c)
+		}
+		c = next
+	}
+}
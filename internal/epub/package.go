@@ -0,0 +1,402 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+)
+
+// contentDir holds everything but the mimetype and container files
+const contentDir = "OEBPS"
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// item is a file in the package manifest. Paths are relative to contentDir.
+type item struct {
+	id         string
+	href       string
+	mediaType  string
+	properties string
+	data       []byte
+}
+
+type navEntry struct {
+	title    string
+	href     string
+	children []*navEntry
+}
+
+type landmark struct {
+	epubType string
+	title    string
+	href     string
+}
+
+type book struct {
+	meta      Metadata
+	items     []*item
+	spine     []*item
+	toc       []*navEntry
+	landmarks []landmark
+	nav       *item
+	opf       []byte
+	ncx       []byte
+}
+
+func newBook(meta Metadata) *book {
+	return &book{meta: meta}
+}
+
+func (b *book) add(it *item) {
+	b.items = append(b.items, it)
+}
+
+// addPage adds an XHTML page to the manifest and the reading order
+func (b *book) addPage(it *item) {
+	it.mediaType = "application/xhtml+xml"
+	b.add(it)
+	b.spine = append(b.spine, it)
+}
+
+func (b *book) addCover(coverPath string) error {
+	mediaType := imageMediaType(coverPath)
+	if mediaType == "" {
+		return fmt.Errorf("unsupported image type %s", filepath.Ext(coverPath))
+	}
+	data, err := os.ReadFile(coverPath)
+	if err != nil {
+		return err
+	}
+	href := "images/cover" + strings.ToLower(filepath.Ext(coverPath))
+	b.add(&item{id: "cover-image", href: href, mediaType: mediaType, properties: "cover-image", data: data})
+
+	body := fmt.Sprintf(`<section epub:type="cover" class="cover"><img src="%s" alt="%s"/></section>`,
+		href, xmlEscape(b.meta.Title))
+	b.addPage(&item{id: "cover", href: "cover.xhtml", data: xhtmlPage("Cover", b.meta.Language, body)})
+	b.landmarks = append(b.landmarks, landmark{"cover", "Cover", "cover.xhtml"})
+	return nil
+}
+
+// matterTypes gives each kind of front and back matter its default title
+// and structural semantics
+var matterTypes = map[string]struct{ title, epubType string }{
+	"titlepage":  {"Title Page", "titlepage"},
+	"copyright":  {"Copyright", "copyright-page"},
+	"dedication": {"Dedication", "dedication"},
+	"afterword":  {"Afterword", "afterword"},
+	"ack":        {"Acknowledgements", "acknowledgments"},
+	"about":      {"About the Author", "appendix"},
+}
+
+func (b *book) addMatter(id string, s Section, division string) {
+	info := matterTypes[s.Type]
+	title := s.Title
+	if title == "" {
+		title = info.title
+	}
+	if title == "" {
+		title = s.Type
+	}
+	epubType := info.epubType
+	if epubType == "" {
+		epubType = division
+	}
+
+	body := fmt.Sprintf(`<section epub:type="%s" class="matter matter-%s">%s</section>`,
+		epubType, cssIdent(s.Type), matterXHTML(s.HTML))
+	href := id + ".xhtml"
+	b.addPage(&item{id: id, href: href, data: xhtmlPage(title, b.meta.Language, body)})
+
+	switch epubType {
+	case "titlepage", "copyright-page":
+		b.landmarks = append(b.landmarks, landmark{epubType, title, href})
+	}
+	if division == "backmatter" {
+		b.toc = append(b.toc, &navEntry{title: title, href: href})
+	}
+}
+
+// addNavPage places the table of contents in the reading order. Its content
+// is written by finish, once every entry is known.
+func (b *book) addNavPage() {
+	b.nav = &item{id: "nav", href: "nav.xhtml", properties: "nav"}
+	b.addPage(b.nav)
+	b.landmarks = append(b.landmarks, landmark{"toc", "Contents", "nav.xhtml"})
+}
+
+func (b *book) addPart(n int, title string) *navEntry {
+	id := fmt.Sprintf("part-%d", n)
+	href := id + ".xhtml"
+	body := fmt.Sprintf(`<section epub:type="part" class="part"><h1 class="part-title">%s</h1></section>`, xmlEscape(title))
+	b.addPage(&item{id: id, href: href, data: xhtmlPage(title, b.meta.Language, body)})
+	b.markBodyStart(href)
+
+	entry := &navEntry{title: title, href: href}
+	b.toc = append(b.toc, entry)
+	return entry
+}
+
+func (b *book) addWork(w bookbuild.Work, body string, parent *navEntry) {
+	id := fmt.Sprintf("work-%d", w.ID)
+	for n := 2; b.hasID(id); n++ {
+		id = fmt.Sprintf("work-%d-%d", w.ID, n)
+	}
+	href := id + ".xhtml"
+	page := fmt.Sprintf(`<section epub:type="chapter" class="work">%s</section>`, body)
+	b.addPage(&item{id: id, href: href, data: xhtmlPage(w.Title, b.meta.Language, page)})
+	b.markBodyStart(href)
+
+	entry := &navEntry{title: w.Title, href: href}
+	if parent != nil {
+		parent.children = append(parent.children, entry)
+	} else {
+		b.toc = append(b.toc, entry)
+	}
+}
+
+func (b *book) markBodyStart(href string) {
+	for _, l := range b.landmarks {
+		if l.epubType == "bodymatter" {
+			return
+		}
+	}
+	b.landmarks = append(b.landmarks, landmark{"bodymatter", "Start", href})
+}
+
+func (b *book) hasID(id string) bool {
+	for _, it := range b.items {
+		if it.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// finish writes the navigation document, the NCX for older readers and the
+// package document
+func (b *book) finish() {
+	if b.nav != nil {
+		b.nav.data = b.navXHTML()
+	}
+	b.ncx = b.ncxXML()
+	b.add(&item{id: "ncx", href: "toc.ncx", mediaType: "application/x-dtbncx+xml"})
+	b.opf = b.packageXML()
+}
+
+func (b *book) navXHTML() []byte {
+	var s strings.Builder
+	s.WriteString(`<nav epub:type="toc" id="toc"><h1>Contents</h1>`)
+	writeNavList(&s, b.toc)
+	s.WriteString("</nav>\n")
+	s.WriteString(`<nav epub:type="landmarks" id="landmarks" hidden="hidden"><h2>Guide</h2><ol>`)
+	for _, l := range b.landmarks {
+		fmt.Fprintf(&s, `<li><a epub:type="%s" href="%s">%s</a></li>`, l.epubType, l.href, xmlEscape(l.title))
+	}
+	s.WriteString("</ol></nav>")
+	return xhtmlPage("Contents", b.meta.Language, s.String())
+}
+
+func writeNavList(s *strings.Builder, entries []*navEntry) {
+	s.WriteString("<ol>")
+	for _, e := range entries {
+		fmt.Fprintf(s, `<li><a href="%s">%s</a>`, e.href, xmlEscape(e.title))
+		if len(e.children) > 0 {
+			writeNavList(s, e.children)
+		}
+		s.WriteString("</li>")
+	}
+	s.WriteString("</ol>")
+}
+
+func (b *book) ncxXML() []byte {
+	var s strings.Builder
+	s.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+<head><meta name="dtb:uid" content="` + xmlEscape(b.identifier()) + `"/></head>
+<docTitle><text>` + xmlEscape(b.meta.Title) + `</text></docTitle>
+<navMap>
+`)
+	order := 0
+	var walk func([]*navEntry)
+	walk = func(entries []*navEntry) {
+		for _, e := range entries {
+			order++
+			fmt.Fprintf(&s, `<navPoint id="nav-%d" playOrder="%d"><navLabel><text>%s</text></navLabel><content src="%s"/>`,
+				order, order, xmlEscape(e.title), e.href)
+			walk(e.children)
+			s.WriteString("</navPoint>\n")
+		}
+	}
+	walk(b.toc)
+	s.WriteString("</navMap>\n</ncx>\n")
+	return []byte(s.String())
+}
+
+func (b *book) packageXML() []byte {
+	m := b.meta
+	var s strings.Builder
+	fmt.Fprintf(&s, `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="bookid" xml:lang="%s">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="bookid">%s</dc:identifier>
+<dc:title id="title">%s</dc:title>
+<meta refines="#title" property="title-type">main</meta>
+`, xmlEscape(m.Language), xmlEscape(b.identifier()), xmlEscape(m.Title))
+	if m.Subtitle != "" {
+		fmt.Fprintf(&s, "<dc:title id=\"subtitle\">%s</dc:title>\n<meta refines=\"#subtitle\" property=\"title-type\">subtitle</meta>\n", xmlEscape(m.Subtitle))
+	}
+	if m.Author != "" {
+		fmt.Fprintf(&s, "<dc:creator id=\"creator\">%s</dc:creator>\n<meta refines=\"#creator\" property=\"role\" scheme=\"marc:relators\">aut</meta>\n", xmlEscape(m.Author))
+	}
+	fmt.Fprintf(&s, "<dc:language>%s</dc:language>\n", xmlEscape(m.Language))
+	for _, el := range []struct{ name, value string }{
+		{"publisher", m.Publisher},
+		{"date", m.Date},
+		{"description", m.Description},
+	} {
+		if el.value != "" {
+			fmt.Fprintf(&s, "<dc:%s>%s</dc:%s>\n", el.name, xmlEscape(el.value), el.name)
+		}
+	}
+	fmt.Fprintf(&s, "<meta property=\"dcterms:modified\">%s</meta>\n", m.Modified.UTC().Format("2006-01-02T15:04:05Z"))
+	for _, it := range b.items {
+		if it.properties == "cover-image" {
+			fmt.Fprintf(&s, "<meta name=\"cover\" content=\"%s\"/>\n", it.id)
+		}
+	}
+	s.WriteString("</metadata>\n<manifest>\n")
+	for _, it := range b.items {
+		fmt.Fprintf(&s, `<item id="%s" href="%s" media-type="%s"`, it.id, it.href, it.mediaType)
+		if it.properties != "" {
+			fmt.Fprintf(&s, ` properties="%s"`, it.properties)
+		}
+		s.WriteString("/>\n")
+	}
+	s.WriteString("</manifest>\n<spine toc=\"ncx\">\n")
+	for _, it := range b.spine {
+		fmt.Fprintf(&s, "<itemref idref=\"%s\"/>\n", it.id)
+	}
+	s.WriteString("</spine>\n</package>\n")
+	return []byte(s.String())
+}
+
+// identifier is the book's ISBN as a URN, or a UUID derived from the title
+// and author so rebuilds of the same book keep the same identity
+func (b *book) identifier() string {
+	isbn := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || r == 'X' || r == 'x' {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, b.meta.ISBN)
+	if len(isbn) == 10 || len(isbn) == 13 {
+		return "urn:isbn:" + isbn
+	}
+
+	sum := sha1.Sum([]byte(b.meta.Title + "\x00" + b.meta.Author))
+	sum[6] = sum[6]&0x0f | 0x50 // version 5
+	sum[8] = sum[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// write stores the package as a zip with the uncompressed mimetype first,
+// as the OCF container format requires
+func (b *book) write(dst string) error {
+	f, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("create epub: %w", err)
+	}
+	zw := zip.NewWriter(f)
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"META-INF/container.xml", []byte(containerXML)},
+		{path.Join(contentDir, "content.opf"), b.opf},
+		{path.Join(contentDir, "toc.ncx"), b.ncx},
+	}
+	for _, it := range b.items {
+		if it.data != nil {
+			files = append(files, struct {
+				name string
+				data []byte
+			}{path.Join(contentDir, it.href), it.data})
+		}
+	}
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err == nil {
+		_, err = w.Write([]byte("application/epub+zip"))
+	}
+	for _, file := range files {
+		if err != nil {
+			break
+		}
+		if w, err = zw.Create(file.name); err == nil {
+			_, err = w.Write(file.data)
+		}
+	}
+	if err != nil {
+		zw.Close()
+		f.Close()
+		return fmt.Errorf("write epub: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return fmt.Errorf("write epub: %w", err)
+	}
+	return f.Close()
+}
+
+// xhtmlPage wraps body content in an XHTML content document
+func xhtmlPage(title, lang, body string) []byte {
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="%[2]s" xml:lang="%[2]s">
+<head>
+<meta charset="UTF-8"/>
+<title>%[1]s</title>
+<link rel="stylesheet" type="text/css" href="styles.css"/>
+</head>
+<body>
+%[3]s
+</body>
+</html>
+`, xmlEscape(title), xmlEscape(lang), body))
+}
+
+func imageMediaType(p string) string {
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".gif":
+		return "image/gif"
+	case ".svg":
+		return "image/svg+xml"
+	}
+	return ""
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,151 @@
+package epub
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// validate checks the assembled book against the structural rules an EPUB
+// reader depends on: required metadata, one navigation document, unique
+// manifest entries, a spine over XHTML pages, well-formed XML throughout
+// and no links to files missing from the package
+func (b *book) validate() []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if strings.TrimSpace(b.meta.Title) == "" {
+		add("missing title")
+	}
+	if strings.TrimSpace(b.meta.Language) == "" {
+		add("missing language")
+	}
+
+	ids := map[string]bool{}
+	hrefs := map[string]*item{}
+	navs, covers := 0, 0
+	for _, it := range b.items {
+		if !validID(it.id) {
+			add("invalid manifest id %q", it.id)
+		}
+		if ids[it.id] {
+			add("duplicate manifest id %q", it.id)
+		}
+		ids[it.id] = true
+		if hrefs[it.href] != nil {
+			add("duplicate manifest href %q", it.href)
+		}
+		hrefs[it.href] = it
+		if it.mediaType == "" {
+			add("%s has no media type", it.href)
+		}
+		switch it.properties {
+		case "nav":
+			navs++
+		case "cover-image":
+			covers++
+		}
+	}
+	if navs != 1 {
+		add("expected one navigation document, found %d", navs)
+	}
+	if covers > 1 {
+		add("expected at most one cover image, found %d", covers)
+	}
+
+	if len(b.spine) == 0 {
+		add("empty spine")
+	}
+	for _, it := range b.spine {
+		if hrefs[it.href] != it {
+			add("spine item %q is not in the manifest", it.id)
+		}
+		if it.mediaType != "application/xhtml+xml" {
+			add("spine item %q is not XHTML", it.id)
+		}
+	}
+
+	for name, data := range map[string][]byte{"content.opf": b.opf, "toc.ncx": b.ncx} {
+		if _, err := xmlLinks(data); err != nil {
+			add("%s: %v", name, err)
+		}
+	}
+	for _, it := range b.items {
+		if it.mediaType != "application/xhtml+xml" {
+			continue
+		}
+		links, err := xmlLinks(it.data)
+		if err != nil {
+			add("%s: %v", it.href, err)
+			continue
+		}
+		for _, link := range links {
+			target := resolveLink(it.href, link)
+			if target != "" && hrefs[target] == nil {
+				add("%s links to missing %s", it.href, link)
+			}
+		}
+	}
+	return problems
+}
+
+// xmlLinks parses data as XML, returning the href and src attributes it
+// contains. Unknown entities such as &nbsp; are errors, as they are for an
+// XML reader.
+func xmlLinks(data []byte) ([]string, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty document")
+	}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var links []string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return links, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			for _, a := range start.Attr {
+				if a.Name.Local == "href" || a.Name.Local == "src" {
+					links = append(links, a.Value)
+				}
+			}
+		}
+	}
+}
+
+// resolveLink turns a link in the page at from into a manifest href. Links
+// outside the package and fragments within the page resolve to "".
+func resolveLink(from, link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return ""
+	}
+	return path.Clean(path.Join(path.Dir(from), u.Path))
+}
+
+// validID reports whether s is an XML name made of ASCII letters, digits,
+// hyphens, underscores and periods, starting with a letter
+func validID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		letter := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+		if i == 0 && !letter {
+			return false
+		}
+		if !letter && !(r >= '0' && r <= '9') && r != '-' && r != '_' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
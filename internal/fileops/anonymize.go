@@ -0,0 +1,481 @@
+package fileops
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+// AnonymizeReport lists what was taken out of a file to hide its author
+type AnonymizeReport struct {
+	Path    string   `json:"path"`
+	Removed []string `json:"removed"`
+}
+
+func (r *AnonymizeReport) add(format string, args ...any) {
+	r.Removed = append(r.Removed, fmt.Sprintf(format, args...))
+}
+
+// droppedDocxParts are the parts of a Word document that are removed
+// whole, by exact name or, when ending in a slash, by folder
+var droppedDocxParts = []struct {
+	name  string
+	label string
+}{
+	{"docProps/custom.xml", "custom document properties"},
+	{"customXml/", "custom XML data"},
+	{"word/comments.xml", ""}, // counted when read
+	{"word/commentsExtended.xml", ""},
+	{"word/commentsExtensible.xml", ""},
+	{"word/commentsIds.xml", ""},
+	{"word/people.xml", "reviewer list"},
+}
+
+var (
+	reTextNode      = regexp.MustCompile(`(<w:t(?:\s[^>]*)?>)([^<]*)(</w:t>)`)
+	reAuthorAttr    = regexp.MustCompile(`\sw:author="([^"]*)"`)
+	reInitialsAttr  = regexp.MustCompile(`\sw:initials="[^"]*"`)
+	reCommentRun    = regexp.MustCompile(`(?s)<w:r\b[^>]*>(?:<w:rPr>.*?</w:rPr>)?<w:commentReference\b[^>]*/></w:r>`)
+	reCommentMark   = regexp.MustCompile(`<w:comment(?:RangeStart|RangeEnd|Reference)\b[^>]*/>`)
+	reComment       = regexp.MustCompile(`<w:comment\b`)
+	reRelationship  = regexp.MustCompile(`<Relationship\b[^>]*/>`)
+	reTarget        = regexp.MustCompile(`\sTarget="([^"]*)"`)
+	reOverride      = regexp.MustCompile(`<Override\b[^>]*\sPartName="([^"]*)"[^>]*/>`)
+	reAttachedTmpl  = regexp.MustCompile(`<w:attachedTemplate\b[^>]*/>`)
+	reWordTextParts = regexp.MustCompile(`^word/(document|header\d*|footer\d*|footnotes|endnotes)\.xml$`)
+)
+
+// propertyFields are the document properties that name a person
+var propertyFields = []struct {
+	part, tag, label string
+}{
+	{"docProps/core.xml", "dc:creator", "document author"},
+	{"docProps/core.xml", "cp:lastModifiedBy", "last modified by"},
+	{"docProps/app.xml", "Company", "company"},
+	{"docProps/app.xml", "Manager", "manager"},
+}
+
+// anonymousAuthor replaces the names on tracked changes, which Word
+// requires to be present
+const anonymousAuthor = "Author"
+
+// AnonymizeDocx rewrites a Word document in place so that it no longer
+// identifies its author. It clears the author fields of the document
+// properties, drops custom properties, custom XML, comments and the
+// reviewer list, renames the authors of tracked changes, forgets the
+// path of the attached template and removes names from the text.
+//
+// The names removed from the text are those given, the ones the document
+// itself records as author, modifier or reviewer, and the last word of
+// each given name, which is looked for in headers and footers only so
+// that a surname used as an ordinary word in the body survives.
+func AnonymizeDocx(docPath string, names []string) (*AnonymizeReport, error) {
+	zr, err := zip.OpenReader(docPath)
+	if err != nil {
+		return nil, fmt.Errorf("open docx: %w", err)
+	}
+	parts := map[string][]byte{}
+	var files []*zip.FileHeader
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			zr.Close()
+			return nil, fmt.Errorf("read %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			zr.Close()
+			return nil, fmt.Errorf("read %s: %w", f.Name, err)
+		}
+		parts[f.Name] = data
+		header := f.FileHeader
+		files = append(files, &header)
+	}
+	zr.Close()
+
+	report := &AnonymizeReport{Path: docPath, Removed: []string{}}
+	found := map[string]bool{}
+
+	// Names the document records go on the list before they are cleared
+	for _, field := range propertyFields {
+		data, ok := parts[field.part]
+		if !ok {
+			continue
+		}
+		value, cleared := clearElement(string(data), field.tag)
+		if value != "" {
+			report.add("%s %q", field.label, value)
+			found[value] = true
+			parts[field.part] = []byte(cleared)
+		}
+	}
+	if data, ok := parts["word/comments.xml"]; ok {
+		if n := len(reComment.FindAllIndex(data, -1)); n > 0 {
+			report.add("comments (%d)", n)
+		}
+		for _, m := range reAuthorAttr.FindAllSubmatch(data, -1) {
+			found[html.UnescapeString(string(m[1]))] = true
+		}
+	}
+
+	dropped := map[string]bool{}
+	for _, d := range droppedDocxParts {
+		hit := false
+		for name := range parts {
+			if name == d.name || strings.HasSuffix(d.name, "/") && strings.HasPrefix(name, d.name) {
+				dropped[name] = true
+				hit = true
+			}
+		}
+		if hit && d.label != "" {
+			report.add("%s", d.label)
+		}
+	}
+
+	authors := map[string]bool{}
+	for name, data := range parts {
+		if dropped[name] || !strings.HasPrefix(name, "word/") || !strings.HasSuffix(name, ".xml") {
+			continue
+		}
+		s := string(data)
+		s = reCommentRun.ReplaceAllString(s, "")
+		s = reCommentMark.ReplaceAllString(s, "")
+		s = reAuthorAttr.ReplaceAllStringFunc(s, func(attr string) string {
+			author := html.UnescapeString(reAuthorAttr.FindStringSubmatch(attr)[1])
+			if author != anonymousAuthor {
+				authors[author] = true
+			}
+			return ` w:author="` + anonymousAuthor + `"`
+		})
+		s = reInitialsAttr.ReplaceAllString(s, "")
+		if name == "word/settings.xml" && reAttachedTmpl.MatchString(s) {
+			s = reAttachedTmpl.ReplaceAllString(s, "")
+			report.add("attached template path")
+		}
+		parts[name] = []byte(s)
+	}
+	if len(authors) > 0 {
+		report.add("tracked-change authors (%d)", len(authors))
+		for a := range authors {
+			found[a] = true
+		}
+	}
+
+	// Relationships and content types must not point at removed parts
+	for name, data := range parts {
+		if dropped[name] || !strings.HasSuffix(name, ".rels") {
+			continue
+		}
+		parts[name] = []byte(dropRelationships(string(data), relsBase(name), dropped))
+	}
+	if data, ok := parts["[Content_Types].xml"]; ok {
+		parts["[Content_Types].xml"] = []byte(reOverride.ReplaceAllStringFunc(string(data), func(o string) string {
+			if dropped[strings.TrimPrefix(reOverride.FindStringSubmatch(o)[1], "/")] {
+				return ""
+			}
+			return o
+		}))
+	}
+
+	everywhere, marginsOnly := anonymizeNames(names, found)
+	scrubbed := map[string]int{}
+	for name, data := range parts {
+		m := reWordTextParts.FindStringSubmatch(name)
+		if m == nil || dropped[name] {
+			continue
+		}
+		list := everywhere
+		if strings.HasPrefix(m[1], "header") || strings.HasPrefix(m[1], "footer") {
+			list = append(append([]string{}, everywhere...), marginsOnly...)
+		}
+		s, n := removeNames(string(data), list)
+		if n > 0 {
+			parts[name] = []byte(s)
+			scrubbed[partLabel(m[1])] += n
+		}
+	}
+	if len(scrubbed) > 0 {
+		var where []string
+		for label, n := range scrubbed {
+			where = append(where, fmt.Sprintf("%d in %s", n, label))
+		}
+		sort.Strings(where)
+		report.add("author's name: %s", strings.Join(where, ", "))
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, header := range files {
+		if dropped[header.Name] {
+			continue
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: header.Name, Method: header.Method, Modified: header.Modified})
+		if err != nil {
+			return nil, fmt.Errorf("write %s: %w", header.Name, err)
+		}
+		if _, err := w.Write(parts[header.Name]); err != nil {
+			return nil, fmt.Errorf("write %s: %w", header.Name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("close docx: %w", err)
+	}
+	if err := os.WriteFile(docPath, buf.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("write docx: %w", err)
+	}
+	return report, nil
+}
+
+// AnonymizePDF removes the author, title and other document information
+// from a PDF in place, along with its XMP metadata
+func AnonymizePDF(pdfPath string) (*AnonymizeReport, error) {
+	ctx, err := api.ReadContextFile(pdfPath)
+	if err != nil {
+		return nil, fmt.Errorf("read pdf: %w", err)
+	}
+
+	report := &AnonymizeReport{Path: pdfPath, Removed: []string{}}
+	if ctx.Info != nil {
+		d, err := ctx.DereferenceDict(*ctx.Info)
+		if err != nil {
+			return nil, fmt.Errorf("read pdf info: %w", err)
+		}
+		var keys []string
+		for key := range d {
+			switch key {
+			case "Producer", "CreationDate", "ModDate", "Trapped":
+			default:
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			delete(d, key)
+			report.add("PDF %s", strings.ToLower(key))
+		}
+	}
+	if root, err := ctx.Catalog(); err == nil {
+		if _, ok := root["Metadata"]; ok {
+			delete(root, "Metadata")
+			report.add("PDF XMP metadata")
+		}
+	}
+
+	tmpPath := pdfPath + ".anonymized"
+	if err := api.WriteContextFile(ctx, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("write pdf: %w", err)
+	}
+	if err := os.Rename(tmpPath, pdfPath); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("rename anonymized pdf: %w", err)
+	}
+	return report, nil
+}
+
+// clearElement empties the first <tag>...</tag> in s and returns its
+// former text
+func clearElement(s, tag string) (string, string) {
+	open := strings.Index(s, "<"+tag)
+	if open == -1 {
+		return "", s
+	}
+	start := strings.Index(s[open:], ">")
+	if start == -1 || s[open+start-1] == '/' {
+		return "", s
+	}
+	start += open + 1
+	end := strings.Index(s[start:], "</"+tag+">")
+	if end == -1 {
+		return "", s
+	}
+	end += start
+	return strings.TrimSpace(html.UnescapeString(s[start:end])), s[:start] + s[end:]
+}
+
+// relsBase is the folder against which a relationships part resolves
+// its targets: "word/_rels/document.xml.rels" points into "word"
+func relsBase(relsName string) string {
+	return path.Dir(path.Dir(relsName))
+}
+
+func dropRelationships(rels, base string, dropped map[string]bool) string {
+	return reRelationship.ReplaceAllStringFunc(rels, func(rel string) string {
+		m := reTarget.FindStringSubmatch(rel)
+		if m == nil {
+			return rel
+		}
+		target := m[1]
+		if strings.Contains(rel, `TargetMode="External"`) {
+			// An attached template is linked by its full path, which
+			// includes the user's home folder
+			if strings.HasPrefix(target, "file:") {
+				return ""
+			}
+			return rel
+		}
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join(base, target)
+		}
+		if dropped[target] {
+			return ""
+		}
+		return rel
+	})
+}
+
+// anonymizeNames returns the names to remove everywhere and the bare
+// surnames to remove from headers and footers only. Very short names and
+// the placeholder author are skipped.
+func anonymizeNames(given []string, found map[string]bool) ([]string, []string) {
+	seen := map[string]bool{}
+	var everywhere, marginsOnly []string
+	keep := func(name string) string {
+		name = strings.Join(strings.Fields(name), " ")
+		key := strings.ToLower(name)
+		if len([]rune(name)) < 3 || seen[key] || strings.EqualFold(name, anonymousAuthor) {
+			return ""
+		}
+		seen[key] = true
+		return name
+	}
+	for _, name := range given {
+		if n := keep(name); n != "" {
+			everywhere = append(everywhere, n)
+		}
+	}
+	var recorded []string
+	for name := range found {
+		recorded = append(recorded, name)
+	}
+	sort.Strings(recorded)
+	for _, name := range recorded {
+		if n := keep(name); n != "" {
+			everywhere = append(everywhere, n)
+		}
+	}
+	for _, name := range given {
+		if words := strings.Fields(name); len(words) > 1 {
+			if n := keep(words[len(words)-1]); n != "" {
+				marginsOnly = append(marginsOnly, n)
+			}
+		}
+	}
+	// Longer names first so "Jane Doe" goes before "Doe" can split it
+	sort.SliceStable(everywhere, func(i, j int) bool { return len(everywhere[i]) > len(everywhere[j]) })
+	return everywhere, marginsOnly
+}
+
+// removeNames deletes whole-word, case-insensitive occurrences of names
+// from the text of a WordprocessingML part. Word often splits a line
+// into several runs, so a paragraph's text is searched as one string and
+// a match may span runs.
+func removeNames(part string, names []string) (string, int) {
+	locs := reTextNode.FindAllStringSubmatchIndex(part, -1)
+	if len(locs) == 0 || len(names) == 0 {
+		return part, 0
+	}
+
+	var text []rune
+	var owner []int // the text node each rune came from; -1 between paragraphs
+	prev := 0
+	for i, loc := range locs {
+		if i > 0 && strings.Contains(part[prev:loc[0]], "</w:p>") {
+			text = append(text, '\n')
+			owner = append(owner, -1)
+		}
+		for _, r := range html.UnescapeString(part[loc[4]:loc[5]]) {
+			text = append(text, unicode.ToLower(r))
+			owner = append(owner, i)
+		}
+		prev = loc[1]
+	}
+	original := []rune{}
+	for _, loc := range locs {
+		original = append(original, []rune(html.UnescapeString(part[loc[4]:loc[5]]))...)
+	}
+
+	cut := make([]bool, len(text))
+	count := 0
+	for _, name := range names {
+		var n []rune
+		for _, r := range name {
+			n = append(n, unicode.ToLower(r))
+		}
+		for i := 0; i+len(n) <= len(text); i++ {
+			if !matchAt(text, n, i, cut) {
+				continue
+			}
+			for j := i; j < i+len(n); j++ {
+				cut[j] = true
+			}
+			count++
+			i += len(n) - 1
+		}
+	}
+	if count == 0 {
+		return part, 0
+	}
+
+	kept := make([]strings.Builder, len(locs))
+	k := 0
+	for i := range text {
+		if owner[i] < 0 {
+			continue
+		}
+		if !cut[i] {
+			kept[owner[i]].WriteRune(original[k])
+		}
+		k++
+	}
+	var b strings.Builder
+	last := 0
+	for i, loc := range locs {
+		b.WriteString(part[last:loc[4]])
+		b.WriteString(xmlTextEscaper.Replace(kept[i].String()))
+		last = loc[5]
+	}
+	b.WriteString(part[last:])
+	return b.String(), count
+}
+
+var xmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// matchAt reports whether name occurs as a whole word at text[i:]
+// without overlapping text already cut
+func matchAt(text, name []rune, i int, cut []bool) bool {
+	for j, r := range name {
+		if cut[i+j] || text[i+j] != r {
+			return false
+		}
+	}
+	isWord := func(k int) bool {
+		return k >= 0 && k < len(text) && (unicode.IsLetter(text[k]) || unicode.IsDigit(text[k]))
+	}
+	return !isWord(i-1) && !isWord(i+len(name))
+}
+
+func partLabel(part string) string {
+	switch {
+	case part == "document":
+		return "body"
+	case strings.HasPrefix(part, "header"):
+		return "header"
+	case strings.HasPrefix(part, "footer"):
+		return "footer"
+	}
+	return part
+}
@@ -0,0 +1,150 @@
+package fileops
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+func TestAnonymizeDocx(t *testing.T) {
+	dir := t.TempDir()
+	docPath := filepath.Join(dir, "poems.docx")
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", `<Types><Override PartName="/word/document.xml" ContentType="d"/><Override PartName="/word/comments.xml" ContentType="c"/><Override PartName="/docProps/custom.xml" ContentType="p"/></Types>`},
+		{"_rels/.rels", `<Relationships><Relationship Id="rId1" Target="word/document.xml"/><Relationship Id="rId2" Target="docProps/custom.xml"/></Relationships>`},
+		{"docProps/core.xml", `<cp:coreProperties><dc:title>Crows</dc:title><dc:creator>Jane Q. Doe</dc:creator><cp:lastModifiedBy>jdoe</cp:lastModifiedBy></cp:coreProperties>`},
+		{"docProps/app.xml", `<Properties><Company>Doe Press</Company><Manager/></Properties>`},
+		{"docProps/custom.xml", `<Properties><property name="Owner">Jane</property></Properties>`},
+		{"customXml/item1.xml", `<owner>Jane Doe</owner>`},
+		{"word/_rels/document.xml.rels", `<Relationships><Relationship Id="rId5" Target="comments.xml"/><Relationship Id="rId6" Target="../customXml/item1.xml"/><Relationship Id="rId7" Target="header1.xml"/></Relationships>`},
+		{"word/_rels/settings.xml.rels", `<Relationships><Relationship Id="rId1" Target="file:///Users/jdoe/Templates/Poems.dotm" TargetMode="External"/></Relationships>`},
+		{"word/settings.xml", `<w:settings><w:attachedTemplate r:id="rId1"/></w:settings>`},
+		{"word/comments.xml", `<w:comments><w:comment w:id="0" w:author="Sam Editor" w:initials="SE"><w:p/></w:comment></w:comments>`},
+		{"word/people.xml", `<w15:people><w15:person w15:author="Sam Editor"/></w15:people>`},
+		{"word/header1.xml", `<w:hdr><w:p><w:r><w:t>Doe / Crows</w:t></w:r></w:p></w:hdr>`},
+		{"word/document.xml", `<w:document><w:body>` +
+			`<w:p><w:commentRangeStart w:id="0"/><w:r><w:t>Crows</w:t></w:r><w:commentRangeEnd w:id="0"/><w:r><w:rPr><w:rStyle w:val="CommentReference"/></w:rPr><w:commentReference w:id="0"/></w:r></w:p>` +
+			`<w:p><w:r><w:t xml:space="preserve">by Ja</w:t></w:r><w:r><w:t>ne Q. DOE &amp; friends</w:t></w:r></w:p>` +
+			`<w:p><w:ins w:id="1" w:author="Sam Editor" w:date="2026-01-01T00:00:00Z"><w:r><w:t>Black on the snow, Doe said</w:t></w:r></w:ins></w:p>` +
+			`</w:body></w:document>`},
+	}
+	f, err := os.Create(docPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, p := range parts {
+		w, _ := zw.Create(p.name)
+		_, _ = w.Write([]byte(p.body))
+	}
+	zw.Close()
+	f.Close()
+
+	report, err := AnonymizeDocx(docPath, []string{"Jane Q. Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`document author "Jane Q. Doe"`,
+		`last modified by "jdoe"`,
+		`company "Doe Press"`,
+		"comments (1)",
+		"custom document properties",
+		"custom XML data",
+		"reviewer list",
+		"attached template path",
+		"tracked-change authors (1)",
+		"author's name: 1 in body, 1 in header",
+	}
+	if strings.Join(report.Removed, "\n") != strings.Join(want, "\n") {
+		t.Errorf("report =\n%s\nwant\n%s", strings.Join(report.Removed, "\n"), strings.Join(want, "\n"))
+	}
+
+	zr, err := zip.OpenReader(docPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	got := map[string]string{}
+	for _, file := range zr.File {
+		rc, _ := file.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		got[file.Name] = string(data)
+	}
+	for _, gone := range []string{"docProps/custom.xml", "customXml/item1.xml", "word/comments.xml", "word/people.xml"} {
+		if _, ok := got[gone]; ok {
+			t.Errorf("%s should be removed", gone)
+		}
+	}
+	checks := []struct {
+		part    string
+		want    []string
+		notWant []string
+	}{
+		{"[Content_Types].xml", []string{"/word/document.xml"}, []string{"comments", "custom"}},
+		{"_rels/.rels", []string{"word/document.xml"}, []string{"custom"}},
+		{"word/_rels/document.xml.rels", []string{"header1.xml"}, []string{"comments", "customXml"}},
+		{"word/_rels/settings.xml.rels", nil, []string{"jdoe"}},
+		{"word/settings.xml", nil, []string{"attachedTemplate"}},
+		{"docProps/core.xml", []string{"<dc:title>Crows</dc:title>", "<dc:creator></dc:creator>"}, []string{"Doe", "jdoe"}},
+		{"docProps/app.xml", []string{"<Company></Company>"}, []string{"Doe"}},
+		{"word/header1.xml", []string{"<w:t> / Crows</w:t>"}, []string{"Doe"}},
+		{"word/document.xml", []string{
+			`<w:t xml:space="preserve">by </w:t>`, "<w:t> &amp; friends</w:t>",
+			`w:author="Author"`, "Black on the snow, Doe said",
+		}, []string{"comment", "Sam Editor", "Jane"}},
+	}
+	for _, c := range checks {
+		for _, w := range c.want {
+			if !strings.Contains(got[c.part], w) {
+				t.Errorf("%s is missing %q:\n%s", c.part, w, got[c.part])
+			}
+		}
+		for _, w := range c.notWant {
+			if strings.Contains(got[c.part], w) {
+				t.Errorf("%s still contains %q:\n%s", c.part, w, got[c.part])
+			}
+		}
+	}
+}
+
+func TestAnonymizePDF(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "poem.txt")
+	if err := os.WriteFile(src, []byte("Crows\n\nBlack on the snow."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pdfPath := filepath.Join(dir, "poem.pdf")
+	f := New(Config{ConverterPriority: []string{ConverterBuiltin}})
+	if _, err := f.ConvertToPDF(context.Background(), src, pdfPath); err != nil {
+		t.Fatal(err)
+	}
+	props := map[string]string{"Author": "Jane Doe", "Title": "Crows"}
+	if err := api.AddPropertiesFile(pdfPath, "", props, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := AnonymizePDF(pdfPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed := strings.Join(report.Removed, ", ")
+	if !strings.Contains(removed, "PDF author") || !strings.Contains(removed, "PDF title") {
+		t.Errorf("report = %q", removed)
+	}
+
+	ctx, err := api.ReadContextFile(pdfPath)
+	if err != nil {
+		t.Fatalf("output is not a valid PDF: %v", err)
+	}
+	if ctx.Author != "" || ctx.Title != "" {
+		t.Errorf("metadata left: author %q, title %q", ctx.Author, ctx.Title)
+	}
+}
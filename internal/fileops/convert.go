@@ -0,0 +1,280 @@
+package fileops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const (
+	ConverterWord        = "word"
+	ConverterExcel       = "excel"
+	ConverterLibreOffice = "libreoffice"
+	ConverterBuiltin     = "builtin"
+)
+
+// Converter turns a document into a PDF
+type Converter interface {
+	Name() string
+	// Available reports whether the converter can run on this machine
+	Available() bool
+	// Handles reports whether the converter accepts files with this
+	// lower-case extension (including the dot)
+	Handles(ext string) bool
+	// MaxConcurrent is how many conversions the converter can run at once
+	MaxConcurrent() int
+	Convert(ctx context.Context, src, dst string) error
+}
+
+// ConverterStatus describes a configured converter for the settings page
+type ConverterStatus struct {
+	Name          string `json:"name"`
+	Available     bool   `json:"available"`
+	MaxConcurrent int    `json:"maxConcurrent"`
+}
+
+// DefaultConverterPriority is the order converters are tried in when none
+// is configured: the office suites a platform usually has, then the
+// built-in renderer
+func DefaultConverterPriority() []string {
+	if runtime.GOOS == "darwin" {
+		return []string{ConverterWord, ConverterExcel, ConverterLibreOffice, ConverterBuiltin}
+	}
+	return []string{ConverterLibreOffice, ConverterBuiltin}
+}
+
+// ConverterNames lists every converter that can be configured
+func ConverterNames() []string {
+	return []string{ConverterWord, ConverterExcel, ConverterLibreOffice, ConverterBuiltin}
+}
+
+// newConverter builds the named converter from the config
+func newConverter(name string, cfg Config) Converter {
+	switch name {
+	case ConverterWord:
+		return &wordConverter{}
+	case ConverterExcel:
+		return &excelConverter{}
+	case ConverterLibreOffice:
+		return &libreOfficeConverter{path: cfg.LibreOfficePath}
+	case ConverterBuiltin:
+		return &builtinConverter{}
+	}
+	return nil
+}
+
+// ConversionQueue runs PDF conversions through the first available
+// converter that handles the file, falling back to the next one when a
+// conversion fails. Each converter has its own concurrency limit and the
+// queue as a whole runs at most maxConcurrent conversions; callers wait for
+// a slot. Requests for an output that is already being produced wait for
+// that conversion instead of starting another.
+type ConversionQueue struct {
+	converters []Converter
+	slots      chan struct{}
+	limits     map[string]chan struct{}
+
+	mu       sync.Mutex
+	inflight map[string]*conversion
+}
+
+type conversion struct {
+	done chan struct{}
+	used string
+	err  error
+}
+
+// NewConversionQueue builds a queue over converters in priority order.
+// maxConcurrent <= 0 allows one conversion per CPU.
+func NewConversionQueue(converters []Converter, maxConcurrent int) *ConversionQueue {
+	if maxConcurrent <= 0 {
+		maxConcurrent = runtime.NumCPU()
+	}
+	q := &ConversionQueue{
+		converters: converters,
+		slots:      make(chan struct{}, maxConcurrent),
+		limits:     map[string]chan struct{}{},
+		inflight:   map[string]*conversion{},
+	}
+	for _, c := range converters {
+		q.limits[c.Name()] = make(chan struct{}, max(c.MaxConcurrent(), 1))
+	}
+	return q
+}
+
+// Converters reports the queue's converters in priority order
+func (q *ConversionQueue) Converters() []ConverterStatus {
+	status := make([]ConverterStatus, 0, len(q.converters))
+	for _, c := range q.converters {
+		status = append(status, ConverterStatus{Name: c.Name(), Available: c.Available(), MaxConcurrent: c.MaxConcurrent()})
+	}
+	return status
+}
+
+// Handles reports whether an available converter accepts the file
+func (q *ConversionQueue) Handles(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, c := range q.converters {
+		if c.Handles(ext) && c.Available() {
+			return true
+		}
+	}
+	return false
+}
+
+// Convert writes src as a PDF to dst and returns the name of the converter
+// that produced it. dst is replaced only when a conversion succeeds.
+func (q *ConversionQueue) Convert(ctx context.Context, src, dst string) (string, error) {
+	// The office converters run outside this process and need full paths
+	src, err := filepath.Abs(src)
+	if err != nil {
+		return "", err
+	}
+	if dst, err = filepath.Abs(dst); err != nil {
+		return "", err
+	}
+
+	q.mu.Lock()
+	if c, ok := q.inflight[dst]; ok {
+		q.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.used, c.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	c := &conversion{done: make(chan struct{})}
+	q.inflight[dst] = c
+	q.mu.Unlock()
+
+	c.used, c.err = q.convert(ctx, src, dst)
+
+	q.mu.Lock()
+	delete(q.inflight, dst)
+	q.mu.Unlock()
+	close(c.done)
+	return c.used, c.err
+}
+
+func (q *ConversionQueue) convert(ctx context.Context, src, dst string) (string, error) {
+	if err := acquire(ctx, q.slots); err != nil {
+		return "", err
+	}
+	defer func() { <-q.slots }()
+
+	ext := strings.ToLower(filepath.Ext(src))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", fmt.Errorf("create output directory: %w", err)
+	}
+
+	var failures []error
+	for _, conv := range q.converters {
+		if !conv.Handles(ext) || !conv.Available() {
+			continue
+		}
+		if err := q.convertWith(ctx, conv, src, dst); err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			failures = append(failures, fmt.Errorf("%s: %w", conv.Name(), err))
+			continue
+		}
+		return conv.Name(), nil
+	}
+
+	if len(failures) == 0 {
+		return "", fmt.Errorf("no PDF converter available for %s files", ext)
+	}
+	return "", fmt.Errorf("PDF conversion failed: %w", errors.Join(failures...))
+}
+
+// convertWith runs one converter into a temporary file next to dst and
+// moves the result into place
+func (q *ConversionQueue) convertWith(ctx context.Context, conv Converter, src, dst string) error {
+	limit := q.limits[conv.Name()]
+	if err := acquire(ctx, limit); err != nil {
+		return err
+	}
+	defer func() { <-limit }()
+
+	tmp := strings.TrimSuffix(dst, filepath.Ext(dst)) + ".converting.pdf"
+	_ = os.Remove(tmp)
+	if err := conv.Convert(ctx, src, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if info, err := os.Stat(tmp); err != nil || info.Size() == 0 {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("converter produced no output")
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func acquire(ctx context.Context, sem chan struct{}) error {
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// converterQueue returns the queue built from the current config
+func (f *FileOps) converterQueue() *ConversionQueue {
+	f.convMu.Lock()
+	defer f.convMu.Unlock()
+	if f.queue == nil {
+		f.queue = f.buildQueue()
+	}
+	return f.queue
+}
+
+func (f *FileOps) buildQueue() *ConversionQueue {
+	priority := f.Config.ConverterPriority
+	if len(priority) == 0 {
+		priority = DefaultConverterPriority()
+	}
+
+	seen := map[string]bool{}
+	converters := []Converter{}
+	for _, name := range priority {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if c := newConverter(name, f.Config); c != nil {
+			converters = append(converters, c)
+		}
+	}
+	return NewConversionQueue(converters, f.Config.MaxConversions)
+}
+
+// ReloadConverters rebuilds the converter queue after the config changes.
+// Conversions already running finish on the old queue.
+func (f *FileOps) ReloadConverters() {
+	f.convMu.Lock()
+	defer f.convMu.Unlock()
+	f.queue = f.buildQueue()
+}
+
+// Converters reports the configured converters in priority order
+func (f *FileOps) Converters() []ConverterStatus {
+	return f.converterQueue().Converters()
+}
+
+// ConvertToPDF converts any supported document to a PDF at dst and returns
+// the name of the converter used
+func (f *FileOps) ConvertToPDF(ctx context.Context, src, dst string) (string, error) {
+	return f.converterQueue().Convert(ctx, src, dst)
+}
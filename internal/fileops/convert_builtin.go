@@ -0,0 +1,204 @@
+package fileops
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/fts"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/render"
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// builtinConverter needs nothing installed, so there is always a preview to
+// look at. Word documents are laid out from their paragraph styles by
+// internal/render; anything it cannot read, and plain text, is set in plain
+// Times on a US Letter page without formatting.
+type builtinConverter struct{}
+
+const (
+	builtinFont       = "Times-Roman"
+	builtinFontSize   = 12
+	builtinLeading    = 15.0
+	builtinParaSpace  = 6.0
+	builtinPageWidth  = 612.0
+	builtinPageHeight = 792.0
+	builtinMargin     = 72.0
+)
+
+func (c *builtinConverter) Name() string { return ConverterBuiltin }
+
+func (c *builtinConverter) Available() bool { return true }
+
+func (c *builtinConverter) Handles(ext string) bool {
+	switch ext {
+	case ".docx", ".dotx", ".dotm", ".md", ".txt":
+		return true
+	}
+	return false
+}
+
+func (c *builtinConverter) MaxConcurrent() int { return runtime.NumCPU() }
+
+func (c *builtinConverter) Convert(ctx context.Context, src, dst string) error {
+	docType := strings.TrimPrefix(extOf(src), ".")
+	if docType == "dotx" || docType == "dotm" {
+		docType = "docx"
+	}
+	if docType == "docx" {
+		if err := render.RenderFile(src, dst, render.Options{}); err == nil {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	text, err := fts.ExtractByType(src, docType)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, textPDF(layoutText(text)), 0644)
+}
+
+// layoutText wraps each paragraph to the text width and breaks the lines
+// into pages. Lines are WinAnsi encoded, ready for the standard fonts.
+func layoutText(text string) [][]textLine {
+	width := builtinPageWidth - 2*builtinMargin
+	top := builtinPageHeight - builtinMargin - builtinFontSize
+
+	pages := [][]textLine{}
+	var page []textLine
+	y := top
+	add := func(s string) {
+		if y < builtinMargin {
+			pages = append(pages, page)
+			page, y = nil, top
+		}
+		page = append(page, textLine{text: s, y: y})
+		y -= builtinLeading
+	}
+
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		for _, line := range wrapLine(toWinAnsi(para), width) {
+			add(line)
+		}
+		y -= builtinParaSpace
+	}
+	if len(page) > 0 || len(pages) == 0 {
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+type textLine struct {
+	text string
+	y    float64
+}
+
+// wrapLine breaks s into lines no wider than width, splitting words that
+// would not fit on a line of their own
+func wrapLine(s string, width float64) []string {
+	lines := []string{}
+	current := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if measureBuiltin(candidate) <= width {
+			current = candidate
+			continue
+		}
+		if current != "" {
+			lines = append(lines, current)
+		}
+		for measureBuiltin(word) > width {
+			n := 1
+			for n < len(word) && measureBuiltin(word[:n+1]) <= width {
+				n++
+			}
+			lines = append(lines, word[:n])
+			word = word[n:]
+		}
+		current = word
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+func measureBuiltin(s string) float64 {
+	return font.TextWidth(s, builtinFont, builtinFontSize)
+}
+
+// toWinAnsi encodes s for the standard PDF fonts, replacing characters they
+// cannot show with a question mark
+func toWinAnsi(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if c, ok := charmap.Windows1252.EncodeRune(r); ok {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// textPDF writes laid-out pages as a PDF using one standard font
+func textPDF(pages [][]textLine) []byte {
+	var out strings.Builder
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+i*2)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", builtinFont))
+
+	for i, lines := range pages {
+		var stream strings.Builder
+		fmt.Fprintf(&stream, "BT\n/F1 %d Tf\n", builtinFontSize)
+		for _, line := range lines {
+			fmt.Fprintf(&stream, "1 0 0 1 %.2f %.2f Tm\n(%s) Tj\n", builtinMargin, line.y, escapePDFString(line.text))
+		}
+		stream.WriteString("ET\n")
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Contents %d 0 R /Resources << /Font << /F1 3 0 R >> >> >>",
+			builtinPageWidth, builtinPageHeight, 5+i*2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", stream.Len(), stream.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return []byte(out.String())
+}
+
+func escapePDFString(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "(", "\\(")
+	return strings.ReplaceAll(s, ")", "\\)")
+}
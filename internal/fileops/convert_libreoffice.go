@@ -0,0 +1,84 @@
+package fileops
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// libreOfficeTimeout is longer than the office timeout because a cold
+// LibreOffice start can take most of a minute on its own
+const libreOfficeTimeout = 120 * time.Second
+
+// libreOfficeConverter runs soffice headless. Each conversion gets its own
+// user profile so several can run at once without fighting over the lock
+// on the default one.
+type libreOfficeConverter struct {
+	path string // configured soffice binary, or empty to search for one
+}
+
+func (c *libreOfficeConverter) Name() string { return ConverterLibreOffice }
+
+func (c *libreOfficeConverter) Available() bool {
+	soffice := findSoffice(c.path)
+	if filepath.IsAbs(soffice) {
+		return FileExists(soffice)
+	}
+	_, err := exec.LookPath(soffice)
+	return err == nil
+}
+
+func (c *libreOfficeConverter) Handles(ext string) bool {
+	return isWordExt(ext) || isTemplateExt(ext) || isExcelExt(ext) || ext == ".ods"
+}
+
+func (c *libreOfficeConverter) MaxConcurrent() int { return 2 }
+
+func (c *libreOfficeConverter) Convert(ctx context.Context, src, dst string) error {
+	workDir, err := os.MkdirTemp("", "works-soffice-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	ctx, cancel := context.WithTimeout(ctx, libreOfficeTimeout)
+	defer cancel()
+
+	profile := "file://" + filepath.ToSlash(filepath.Join(workDir, "profile"))
+	outDir := filepath.Join(workDir, "out")
+	cmd := exec.CommandContext(ctx, findSoffice(c.path),
+		"--headless", "--norestore", "--nolockcheck",
+		"-env:UserInstallation="+profile,
+		"--convert-to", "pdf", "--outdir", outDir, src)
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("libreoffice PDF conversion timed out")
+	}
+	if err != nil {
+		return fmt.Errorf("libreoffice PDF conversion failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+
+	// soffice exits cleanly even when it could not load the file, so the
+	// output is the only reliable sign of success
+	base := strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))
+	produced := filepath.Join(outDir, base+".pdf")
+	if !FileExists(produced) {
+		return fmt.Errorf("libreoffice produced no PDF (output: %s)", strings.TrimSpace(string(output)))
+	}
+	return moveFile(produced, dst)
+}
+
+// moveFile renames src to dst, copying when they are on different devices
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
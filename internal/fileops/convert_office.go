@@ -0,0 +1,209 @@
+package fileops
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// officeTimeout keeps a conversion from hanging when Word or Excel shows a
+// dialog
+const officeTimeout = 30 * time.Second
+
+const (
+	wordAppPath  = "/Applications/Microsoft Word.app"
+	excelAppPath = "/Applications/Microsoft Excel.app"
+)
+
+// wordConverter drives Microsoft Word through AppleScript. Word works on one
+// document at a time, so conversions are serialized.
+type wordConverter struct{}
+
+func (c *wordConverter) Name() string { return ConverterWord }
+
+func (c *wordConverter) Available() bool {
+	return runtime.GOOS == "darwin" && FileExists(wordAppPath)
+}
+
+func (c *wordConverter) Handles(ext string) bool {
+	return isWordExt(ext) || isTemplateExt(ext)
+}
+
+func (c *wordConverter) MaxConcurrent() int { return 1 }
+
+func (c *wordConverter) Convert(ctx context.Context, src, dst string) error {
+	script := fmt.Sprintf(wordScript, src, src, src, dst)
+	if isTemplateExt(extOf(src)) {
+		// Opening a template creates a new untitled document rather than
+		// one whose path matches, so save whichever document opened
+		script = fmt.Sprintf(wordTemplateScript, src, dst)
+	}
+	return runOSAScript(ctx, script, "Word")
+}
+
+// excelConverter drives Microsoft Excel through AppleScript
+type excelConverter struct{}
+
+func (c *excelConverter) Name() string { return ConverterExcel }
+
+func (c *excelConverter) Available() bool {
+	return runtime.GOOS == "darwin" && FileExists(excelAppPath)
+}
+
+func (c *excelConverter) Handles(ext string) bool { return isExcelExt(ext) }
+
+func (c *excelConverter) MaxConcurrent() int { return 1 }
+
+func (c *excelConverter) Convert(ctx context.Context, src, dst string) error {
+	return runOSAScript(ctx, fmt.Sprintf(excelScript, src, src, src, dst), "Excel")
+}
+
+func runOSAScript(ctx context.Context, script, app string) error {
+	ctx, cancel := context.WithTimeout(ctx, officeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "osascript", "-e", script)
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s PDF conversion timed out - %s may have a dialog open", strings.ToLower(app), app)
+	}
+	if err != nil {
+		return fmt.Errorf("%s PDF conversion failed: %w (output: %s)", strings.ToLower(app), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+const wordScript = `
+tell application "System Events"
+	set wasRunning to (name of processes) contains "Microsoft Word"
+end tell
+
+-- Wait for Word to be ready (handles race condition after template sync)
+repeat 3 times
+	try
+		tell application "Microsoft Word"
+			set docCount to count of documents
+		end tell
+		exit repeat
+	on error
+		delay 1
+	end try
+end repeat
+
+tell application "Microsoft Word"
+	-- Check if doc is already open
+	set docWasOpen to false
+	set docList to every document
+	repeat with d in docList
+		set thePath to POSIX path of (full name of d as text)
+		if thePath is "%s" then
+			set docWasOpen to true
+			exit repeat
+		end if
+	end repeat
+	
+	-- Open the document (may already be open)
+	open POSIX file "%s"
+	
+	-- Find it by path to get a reliable reference
+	set theDoc to missing value
+	set docList to every document
+	repeat with d in docList
+		set thePath to POSIX path of (full name of d as text)
+		if thePath is "%s" then
+			set theDoc to d
+			exit repeat
+		end if
+	end repeat
+	
+	if theDoc is missing value then
+		error "Could not find opened document"
+	end if
+	
+	save as theDoc file name POSIX file "%s" file format format PDF
+	
+	-- Only close if we opened it (never save - we only needed the PDF)
+	if not docWasOpen then
+		close theDoc saving no
+	end if
+end tell
+
+-- Only quit Word if it wasn't running before
+if not wasRunning then
+	tell application "Microsoft Word" to quit
+end if
+`
+
+const wordTemplateScript = `tell application "System Events"
+	set wasRunning to (name of processes) contains "Microsoft Word"
+end tell
+
+tell application "Microsoft Word"
+	set docsBefore to count of documents
+	open POSIX file "%s"
+	
+	-- Wait for the new document to appear
+	repeat 20 times
+		if (count of documents) > docsBefore then exit repeat
+		delay 0.25
+	end repeat
+	
+	set theDoc to active document
+	save as theDoc file name (POSIX file "%s" as text) file format format PDF
+	close theDoc saving no
+end tell
+
+if not wasRunning then
+	tell application "Microsoft Word" to quit
+end if`
+
+const excelScript = `
+tell application "System Events"
+	set wasRunning to (name of processes) contains "Microsoft Excel"
+end tell
+tell application "Microsoft Excel"
+	-- Check if workbook is already open
+	set wbWasOpen to false
+	set wbList to every workbook
+	repeat with w in wbList
+		set thePath to POSIX path of (full name of w as text)
+		if thePath is "%s" then
+			set wbWasOpen to true
+			exit repeat
+		end if
+	end repeat
+	
+	-- Open the workbook (may already be open)
+	open POSIX file "%s"
+	
+	-- Find it by path to get a reliable reference
+	set theWorkbook to missing value
+	set wbList to every workbook
+	repeat with w in wbList
+		set thePath to POSIX path of (full name of w as text)
+		if thePath is "%s" then
+			set theWorkbook to w
+			exit repeat
+		end if
+	end repeat
+	
+	if theWorkbook is missing value then
+		error "Could not find opened workbook"
+	end if
+	
+	save theWorkbook in POSIX file "%s" as PDF file format
+	
+	-- Only close if we opened it
+	if not wbWasOpen then
+		close theWorkbook saving ask
+	end if
+end tell
+
+-- Only quit Excel if it wasn't running before
+if not wasRunning then
+	tell application "Microsoft Excel" to quit
+end if
+`
@@ -0,0 +1,134 @@
+package fileops
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+type fakeConverter struct {
+	name    string
+	fail    bool
+	delay   time.Duration
+	calls   atomic.Int32
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (c *fakeConverter) Name() string            { return c.name }
+func (c *fakeConverter) Available() bool         { return true }
+func (c *fakeConverter) Handles(ext string) bool { return ext == ".docx" }
+func (c *fakeConverter) MaxConcurrent() int      { return 1 }
+
+func (c *fakeConverter) Convert(ctx context.Context, src, dst string) error {
+	c.calls.Add(1)
+	n := c.running.Add(1)
+	defer c.running.Add(-1)
+	if n > c.peak.Load() {
+		c.peak.Store(n)
+	}
+	time.Sleep(c.delay)
+	if c.fail {
+		return errors.New("broken")
+	}
+	return os.WriteFile(dst, []byte(c.name), 0644)
+}
+
+func TestConversionQueueFallback(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "out.pdf")
+	broken := &fakeConverter{name: "broken", fail: true}
+	good := &fakeConverter{name: "good"}
+	q := NewConversionQueue([]Converter{broken, good}, 0)
+
+	used, err := q.Convert(context.Background(), "a.docx", dst)
+	if err != nil || used != "good" {
+		t.Fatalf("expected the second converter to be used, got %q, %v", used, err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "good" {
+		t.Errorf("unexpected output %q", data)
+	}
+	if FileExists(strings.TrimSuffix(dst, ".pdf") + ".converting.pdf") {
+		t.Error("temporary output was left behind")
+	}
+
+	if _, err := q.Convert(context.Background(), "a.xlsx", dst); err == nil {
+		t.Error("expected an error when no converter handles the file")
+	}
+
+	failing := NewConversionQueue([]Converter{broken}, 0)
+	if _, err := failing.Convert(context.Background(), "a.docx", dst); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected the converter's error, got %v", err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "good" {
+		t.Error("a failed conversion replaced the previous output")
+	}
+}
+
+func TestConversionQueueLimitsAndDedup(t *testing.T) {
+	dir := t.TempDir()
+	slow := &fakeConverter{name: "slow", delay: 20 * time.Millisecond}
+	q := NewConversionQueue([]Converter{slow}, 4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dst := filepath.Join(dir, "same.pdf")
+			if i%2 == 1 {
+				dst = filepath.Join(dir, string(rune('a'+i))+".pdf")
+			}
+			if _, err := q.Convert(context.Background(), "a.docx", dst); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if peak := slow.peak.Load(); peak != 1 {
+		t.Errorf("converter limit of 1 was exceeded: %d at once", peak)
+	}
+	if calls := slow.calls.Load(); calls < 4 || calls > 6 {
+		t.Errorf("unexpected number of conversions: %d", calls)
+	}
+}
+
+func TestBuiltinConverter(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "poem.txt")
+	para := strings.Repeat("The rain came down on the “old” house — again and again. ", 20)
+	text := strings.Repeat(para+"\n\n", 30)
+	if err := os.WriteFile(src, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := New(Config{ConverterPriority: []string{ConverterBuiltin}})
+	dst := filepath.Join(dir, "poem.pdf")
+	used, err := f.ConvertToPDF(context.Background(), src, dst)
+	if err != nil || used != ConverterBuiltin {
+		t.Fatalf("conversion failed: %q, %v", used, err)
+	}
+
+	ctx, err := api.ReadContextFile(dst)
+	if err != nil {
+		t.Fatalf("output is not a valid PDF: %v", err)
+	}
+	if ctx.PageCount < 2 {
+		t.Errorf("expected the text to flow onto several pages, got %d", ctx.PageCount)
+	}
+
+	for _, line := range wrapLine(toWinAnsi(para), builtinPageWidth-2*builtinMargin) {
+		if w := measureBuiltin(line); w > builtinPageWidth-2*builtinMargin {
+			t.Errorf("line is wider than the text block (%.1f): %q", w, line)
+		}
+	}
+}
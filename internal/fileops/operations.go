@@ -351,14 +351,19 @@ func (f *FileOps) MoveFile(w *models.Work) (string, error) {
 	return relativePath, nil
 }
 
+// SubmissionCopyPath returns where CopyToSubmissions puts a work's file.
+// The copy may not exist yet.
+func (f *FileOps) SubmissionCopyPath(w *models.Work) string {
+	return filepath.Join(f.Config.SubmissionExportPath, w.Title+filepath.Ext(derefStringOps(w.Path)))
+}
+
 func (f *FileOps) CopyToSubmissions(w *models.Work) (string, error) {
 	sourcePath, err := FindFileWithExtension(f.GetFilename(derefStringOps(w.Path)))
 	if err != nil {
 		return "", fmt.Errorf("source file not found: %w", err)
 	}
 
-	ext := filepath.Ext(sourcePath)
-	destPath := filepath.Join(f.Config.SubmissionExportPath, w.Title+ext)
+	destPath := f.SubmissionCopyPath(w)
 
 	if err := os.MkdirAll(f.Config.SubmissionExportPath, 0755); err != nil {
 		return "", fmt.Errorf("failed to create directory: %w", err)
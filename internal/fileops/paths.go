@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
 )
@@ -15,6 +16,13 @@ type Config struct {
 	PDFPreviewPath       string
 	SubmissionExportPath string
 	TemplateFolderPath   string
+
+	// PDF conversion: converter names in the order to try them (empty uses
+	// DefaultConverterPriority), the soffice binary, and how many
+	// conversions may run at once (0 means one per CPU)
+	LibreOfficePath   string
+	ConverterPriority []string
+	MaxConversions    int
 }
 
 func DefaultConfig() Config {
@@ -29,6 +37,9 @@ func DefaultConfig() Config {
 
 type FileOps struct {
 	Config Config
+
+	convMu sync.Mutex
+	queue  *ConversionQueue
 }
 
 func New(cfg Config) *FileOps {
@@ -7,14 +7,16 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"time"
 )
 
 func (f *FileOps) CheckWord() bool {
-	return FileExists("/Applications/Microsoft Word.app")
+	return FileExists(wordAppPath)
 }
 
 func (f *FileOps) CheckLibreOffice() bool {
+	if f.Config.LibreOfficePath != "" && FileExists(f.Config.LibreOfficePath) {
+		return true
+	}
 	paths := []string{
 		"/Applications/LibreOffice.app/Contents/MacOS/soffice",
 		"/usr/local/bin/soffice",
@@ -31,6 +33,16 @@ func (f *FileOps) CheckLibreOffice() bool {
 }
 
 func (f *FileOps) GetSofficePath() string {
+	return findSoffice(f.Config.LibreOfficePath)
+}
+
+// findSoffice returns the configured soffice binary if it exists, otherwise
+// the first one found in the usual places
+func findSoffice(configured string) string {
+	if configured != "" && FileExists(configured) {
+		return configured
+	}
+
 	macPath := "/Applications/LibreOffice.app/Contents/MacOS/soffice"
 	if FileExists(macPath) {
 		return macPath
@@ -60,190 +72,21 @@ func (f *FileOps) NeedsRegeneration(docPath string, workID int64) bool {
 	return docInfo.ModTime().After(pdfInfo.ModTime())
 }
 
-func (f *FileOps) generatePDFWithWord(docPath string, pdfPath string) error {
-	script := fmt.Sprintf(`
-tell application "System Events"
-	set wasRunning to (name of processes) contains "Microsoft Word"
-end tell
-
--- Wait for Word to be ready (handles race condition after template sync)
-repeat 3 times
-	try
-		tell application "Microsoft Word"
-			set docCount to count of documents
-		end tell
-		exit repeat
-	on error
-		delay 1
-	end try
-end repeat
-
-tell application "Microsoft Word"
-	-- Check if doc is already open
-	set docWasOpen to false
-	set docList to every document
-	repeat with d in docList
-		set thePath to POSIX path of (full name of d as text)
-		if thePath is "%s" then
-			set docWasOpen to true
-			exit repeat
-		end if
-	end repeat
-	
-	-- Open the document (may already be open)
-	open POSIX file "%s"
-	
-	-- Find it by path to get a reliable reference
-	set theDoc to missing value
-	set docList to every document
-	repeat with d in docList
-		set thePath to POSIX path of (full name of d as text)
-		if thePath is "%s" then
-			set theDoc to d
-			exit repeat
-		end if
-	end repeat
-	
-	if theDoc is missing value then
-		error "Could not find opened document"
-	end if
-	
-	save as theDoc file name POSIX file "%s" file format format PDF
-	
-	-- Only close if we opened it (never save - we only needed the PDF)
-	if not docWasOpen then
-		close theDoc saving no
-	end if
-end tell
-
--- Only quit Word if it wasn't running before
-if not wasRunning then
-	tell application "Microsoft Word" to quit
-end if
-`, docPath, docPath, docPath, pdfPath)
-
-	// 30 second timeout to prevent indefinite hangs when Word has dialogs open
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "osascript", "-e", script)
-	output, err := cmd.CombinedOutput()
-	if ctx.Err() == context.DeadlineExceeded {
-		return fmt.Errorf("PDF conversion timed out - Word may have a dialog open")
-	}
-	if err != nil {
-		return fmt.Errorf("PDF conversion failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
-	}
-	return nil
-}
-
-// func (f *FileOps) generatePDFWithLibreOffice(docPath string, pdfPath string) error {
-// 	soffice := f.GetSofficePath()
-// 	cmd := exec.Command(soffice,
-// 		"--headless",
-// 		"--convert-to", "pdf",
-// 		"--outdir", f.Config.PDFPreviewPath,
-// 		docPath,
-// 	)
-
-// 	if err := cmd.Run(); err != nil {
-// 		return fmt.Errorf("LibreOffice PDF conversion failed: %w", err)
-// 	}
-
-// 	baseName := filepath.Base(docPath)
-// 	baseName = baseName[:len(baseName)-len(filepath.Ext(baseName))] + ".pdf"
-// 	tempPath := filepath.Join(f.Config.PDFPreviewPath, baseName)
-
-// 	if _, err := os.Stat(tempPath); err != nil {
-// 		return fmt.Errorf("PDF conversion succeeded but output file not found at %s: %w", tempPath, err)
-// 	}
-
-// 	if tempPath != pdfPath {
-// 		if err := os.Rename(tempPath, pdfPath); err != nil {
-// 			return fmt.Errorf("failed to rename PDF from %s to %s: %w", tempPath, pdfPath, err)
-// 		}
-// 	}
-
-// 	return nil
-// }
-
 func (f *FileOps) CheckExcel() bool {
-	return FileExists("/Applications/Microsoft Excel.app")
+	return FileExists(excelAppPath)
 }
 
-func (f *FileOps) generatePDFWithExcel(docPath string, pdfPath string) error {
-	script := fmt.Sprintf(`
-tell application "System Events"
-	set wasRunning to (name of processes) contains "Microsoft Excel"
-end tell
-tell application "Microsoft Excel"
-	-- Check if workbook is already open
-	set wbWasOpen to false
-	set wbList to every workbook
-	repeat with w in wbList
-		set thePath to POSIX path of (full name of w as text)
-		if thePath is "%s" then
-			set wbWasOpen to true
-			exit repeat
-		end if
-	end repeat
-	
-	-- Open the workbook (may already be open)
-	open POSIX file "%s"
-	
-	-- Find it by path to get a reliable reference
-	set theWorkbook to missing value
-	set wbList to every workbook
-	repeat with w in wbList
-		set thePath to POSIX path of (full name of w as text)
-		if thePath is "%s" then
-			set theWorkbook to w
-			exit repeat
-		end if
-	end repeat
-	
-	if theWorkbook is missing value then
-		error "Could not find opened workbook"
-	end if
-	
-	save theWorkbook in POSIX file "%s" as PDF file format
-	
-	-- Only close if we opened it
-	if not wbWasOpen then
-		close theWorkbook saving ask
-	end if
-end tell
-
--- Only quit Excel if it wasn't running before
-if not wasRunning then
-	tell application "Microsoft Excel" to quit
-end if
-`, docPath, docPath, docPath, pdfPath)
-
-	// 30 second timeout to prevent indefinite hangs when Excel has dialogs open
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "osascript", "-e", script)
-	output, err := cmd.CombinedOutput()
-	if ctx.Err() == context.DeadlineExceeded {
-		return fmt.Errorf("excel PDF conversion timed out - Excel may have a dialog open")
-	}
-	if err != nil {
-		return fmt.Errorf("excel PDF conversion failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
-	}
-	return nil
+func extOf(path string) string {
+	return strings.ToLower(filepath.Ext(path))
 }
 
-// isExcelFile returns true if the file is an Excel spreadsheet
-func isExcelFile(docPath string) bool {
-	ext := strings.ToLower(filepath.Ext(docPath))
+// isExcelExt returns true for Excel spreadsheet extensions
+func isExcelExt(ext string) bool {
 	return ext == ".xls" || ext == ".xlsx"
 }
 
-// isWordFile returns true if the file can be opened by Word
-func isWordFile(docPath string) bool {
-	ext := strings.ToLower(filepath.Ext(docPath))
+// isWordExt returns true for extensions Word can open
+func isWordExt(ext string) bool {
 	wordExtensions := map[string]bool{
 		".doc":  true,
 		".docx": true,
@@ -254,46 +97,28 @@ func isWordFile(docPath string) bool {
 	return wordExtensions[ext]
 }
 
-// CanGeneratePDF returns true if the file type can be converted to PDF
+// isTemplateExt returns true for Word template extensions
+func isTemplateExt(ext string) bool {
+	return ext == ".dotm" || ext == ".dotx"
+}
+
+// CanGeneratePDF returns true if an available converter can turn the file
+// into a PDF
 func (f *FileOps) CanGeneratePDF(docPath string) bool {
-	return isWordFile(docPath) || isExcelFile(docPath)
+	return f.converterQueue().Handles(docPath)
 }
 
+// GeneratePDF writes the work's preview PDF using the configured
+// converters. A previous preview is kept if conversion fails.
 func (f *FileOps) GeneratePDF(docPath string, workID int64) (string, error) {
-	// Check if this file type can be converted
 	if !f.CanGeneratePDF(docPath) {
-		ext := filepath.Ext(docPath)
-		return "", fmt.Errorf("cannot generate PDF preview for %s files", ext)
+		return "", fmt.Errorf("cannot generate PDF preview for %s files", filepath.Ext(docPath))
 	}
 
 	pdfPath := filepath.Join(f.Config.PDFPreviewPath, fmt.Sprintf("%d.pdf", workID))
-
-	if err := os.MkdirAll(f.Config.PDFPreviewPath, 0755); err != nil {
-		return "", fmt.Errorf("failed to create previews directory: %w", err)
-	}
-
-	_ = os.Remove(pdfPath)
-
-	// Use Excel for spreadsheets
-	if isExcelFile(docPath) {
-		if !f.CheckExcel() {
-			return "", fmt.Errorf("MS Excel not found at /Applications/Microsoft Excel.app")
-		}
-		if err := f.generatePDFWithExcel(docPath, pdfPath); err != nil {
-			return "", err
-		}
-		return pdfPath, nil
-	}
-
-	// Use Word for documents
-	if !f.CheckWord() {
-		return "", fmt.Errorf("MS Word not found at /Applications/Microsoft Word.app")
-	}
-
-	if err := f.generatePDFWithWord(docPath, pdfPath); err != nil {
+	if _, err := f.ConvertToPDF(context.Background(), docPath, pdfPath); err != nil {
 		return "", err
 	}
-
 	return pdfPath, nil
 }
 
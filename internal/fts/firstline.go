@@ -0,0 +1,61 @@
+package fts
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FirstLines returns the opening line of each work's indexed text, keyed
+// by work ID. titles maps the works to look up to their titles, which are
+// skipped when a document repeats them above the text. Works that are not
+// indexed are left out.
+func (s *Searcher) FirstLines(titles map[int64]string) (map[int64]string, error) {
+	ids := make([]int, 0, len(titles))
+	for id := range titles {
+		ids = append(ids, int(id))
+	}
+	contents, err := s.BatchGetContent(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[int64]string, len(contents))
+	for _, c := range contents {
+		id := int64(c.WorkID)
+		if line := FirstLine(c.TextContent, titles[id]); line != "" {
+			lines[id] = line
+		}
+	}
+	return lines, nil
+}
+
+// FirstLine returns the first line of extracted text, skipping blank lines
+// and lines that repeat the title. A title may span lines, marked " | ".
+func FirstLine(text, title string) string {
+	skip := map[string]bool{}
+	for _, part := range strings.Split(title, " | ") {
+		if key := lineKey(part); key != "" {
+			skip[key] = true
+		}
+	}
+	skip[lineKey(strings.ReplaceAll(title, " | ", " "))] = true
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || skip[lineKey(line)] {
+			continue
+		}
+		return line
+	}
+	return ""
+}
+
+// lineKey compares lines by their letters and digits, ignoring case
+func lineKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
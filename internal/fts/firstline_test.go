@@ -0,0 +1,47 @@
+package fts
+
+import "testing"
+
+func TestFirstLine(t *testing.T) {
+	tests := []struct {
+		name, text, title, want string
+	}{
+		{"skips title", "Winter Light\n\nThe snow came early that year", "Winter Light", "The snow came early that year"},
+		{"title case and punctuation", "WINTER LIGHT.\n\nThe snow came early", "Winter Light", "The snow came early"},
+		{"multi-line title", "After\nthe Flood\n\nWe counted what was left", "After | the Flood", "We counted what was left"},
+		{"line break inside stanza", "Crows\n\nBlack on the wire,\nthree of them", "Crows", "Black on the wire,"},
+		{"no title in document", "Black on the wire", "Crows", "Black on the wire"},
+		{"only the title", "Crows", "Crows", ""},
+		{"empty", "", "Crows", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FirstLine(tt.text, tt.title); got != tt.want {
+				t.Errorf("FirstLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearcherFirstLines(t *testing.T) {
+	dir := t.TempDir()
+	ftsDB, mainDB, _ := setupSearchTest(t, dir)
+	defer mainDB.Close()
+	defer ftsDB.Close()
+
+	if err := ftsDB.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ftsDB.Conn().Exec(`INSERT INTO content (work_id, text_content, word_count, extracted_at, source_mtime, source_size)
+		VALUES (7, 'Crows' || char(10) || char(10) || 'Black on the wire', 5, '2025-01-01T00:00:00Z', 0, 0)`); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := NewSearcher(ftsDB, mainDB).FirstLines(map[int64]string{7: "Crows", 8: "Unindexed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || lines[7] != "Black on the wire" {
+		t.Errorf("FirstLines() = %v", lines)
+	}
+}
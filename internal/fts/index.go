@@ -1,6 +1,7 @@
 package fts
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
@@ -16,6 +17,7 @@ type IndexBuilder struct {
 	mainDB     *sql.DB
 	basePath   string
 	onProgress ProgressCallback
+	ctx        context.Context
 }
 
 func NewIndexBuilder(ftsDB *Database, mainDB *sql.DB, basePath string) *IndexBuilder {
@@ -30,6 +32,19 @@ func (b *IndexBuilder) SetProgressCallback(cb ProgressCallback) {
 	b.onProgress = cb
 }
 
+// SetContext makes a build stop between works once ctx is cancelled. A
+// cancelled full build leaves the previous index in place.
+func (b *IndexBuilder) SetContext(ctx context.Context) {
+	b.ctx = ctx
+}
+
+func (b *IndexBuilder) cancelled() error {
+	if b.ctx == nil {
+		return nil
+	}
+	return b.ctx.Err()
+}
+
 func (b *IndexBuilder) emitProgress(progress BuildProgress) {
 	if b.onProgress != nil {
 		b.onProgress(progress)
@@ -55,16 +70,16 @@ func (b *IndexBuilder) BuildFull() (*BuildReport, error) {
 	})
 
 	conn := b.ftsDB.Conn()
-	if _, err := conn.Exec("DELETE FROM content"); err != nil {
-		return nil, fmt.Errorf("clear content: %w", err)
-	}
-
 	tx, err := conn.Begin()
 	if err != nil {
 		return nil, fmt.Errorf("begin transaction: %w", err)
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	if _, err := tx.Exec("DELETE FROM content"); err != nil {
+		return nil, fmt.Errorf("clear content: %w", err)
+	}
+
 	stmt, err := tx.Prepare(`
 		INSERT INTO content (work_id, text_content, word_count, extracted_at, source_mtime, source_size)
 		VALUES (?, ?, ?, ?, ?, ?)
@@ -76,6 +91,9 @@ func (b *IndexBuilder) BuildFull() (*BuildReport, error) {
 
 	var totalWords int
 	for i, work := range works {
+		if err := b.cancelled(); err != nil {
+			return nil, err
+		}
 		b.emitProgress(BuildProgress{
 			Phase:       "extracting",
 			Current:     i + 1,
@@ -180,6 +198,9 @@ func (b *IndexBuilder) UpdateIncremental() (*BuildReport, error) {
 	var totalWords int
 
 	for i, work := range works {
+		if err := b.cancelled(); err != nil {
+			return nil, err
+		}
 		b.emitProgress(BuildProgress{
 			Phase:       "extracting",
 			Current:     i + 1,
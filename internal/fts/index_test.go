@@ -2,7 +2,9 @@ package fts
 
 import (
 	"archive/zip"
+	"context"
 	"database/sql"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -119,6 +121,47 @@ func TestIndexBuilderBuildFull(t *testing.T) {
 	ftsDB.Close()
 }
 
+func TestIndexBuilderBuildFullCancelled(t *testing.T) {
+	dir := t.TempDir()
+	docDir := filepath.Join(dir, "docs")
+	os.MkdirAll(docDir, 0755)
+
+	mainDB := setupTestDB(t, dir)
+	defer mainDB.Close()
+
+	createTestDocxFile(t, docDir, "poem1.docx", "The morning light")
+	createTestDocxFile(t, docDir, "poem2.docx", "Water flows beneath")
+
+	mainDB.Exec(`INSERT INTO Works (workID, title, type, year, status, doc_type, path) VALUES (1, 'Poem 1', 'Poem', '2020', 'Active', 'docx', 'poem1.docx')`)
+	mainDB.Exec(`INSERT INTO Works (workID, title, type, year, status, doc_type, path) VALUES (2, 'Poem 2', 'Poem', '2021', 'Active', 'docx', 'poem2.docx')`)
+
+	ftsDB := &Database{path: filepath.Join(dir, "fulltext.db")}
+	defer ftsDB.Close()
+
+	if _, err := NewIndexBuilder(ftsDB, mainDB, docDir).BuildFull(); err != nil {
+		t.Fatalf("BuildFull failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	builder := NewIndexBuilder(ftsDB, mainDB, docDir)
+	builder.SetContext(ctx)
+	builder.SetProgressCallback(func(p BuildProgress) {
+		if p.Phase == "extracting" {
+			cancel()
+		}
+	})
+
+	if _, err := builder.BuildFull(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+
+	var count int
+	ftsDB.Conn().QueryRow("SELECT COUNT(*) FROM content").Scan(&count)
+	if count != 2 {
+		t.Errorf("a cancelled build should keep the old index, got %d rows", count)
+	}
+}
+
 func TestIndexBuilderCheckStaleness(t *testing.T) {
 	dir := t.TempDir()
 	docDir := filepath.Join(dir, "docs")
@@ -0,0 +1,442 @@
+// Package jobs runs long operations such as book builds and index rebuilds
+// in the background. Jobs are queued in the database, so the queue, each
+// job's progress and log, and the history of finished jobs survive a
+// restart. Jobs that were running when the app closed are queued again.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/db"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+const (
+	// MaxAttempts is how many times an interrupted job is started before
+	// it is failed
+	MaxAttempts = 3
+	// History is how long finished jobs are kept
+	History = 30 * 24 * time.Hour
+
+	maxLogLines      = 2000
+	progressInterval = 250 * time.Millisecond
+	pollInterval     = 5 * time.Second
+)
+
+// Handler does the work of one kind of job. It should stop when ctx is
+// cancelled. The returned value is stored as the job's JSON result.
+type Handler func(ctx context.Context, job *models.Job, r *Reporter) (any, error)
+
+type Queue struct {
+	db       *db.DB
+	workers  int
+	mu       sync.Mutex
+	handlers map[string]Handler
+	running  map[int64]context.CancelFunc
+	waiters  map[int64][]chan struct{}
+	onUpdate func(models.Job)
+	wake     chan struct{}
+	ctx      context.Context
+	stop     context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func New(database *db.DB, workers int) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Queue{
+		db:       database,
+		workers:  workers,
+		handlers: map[string]Handler{},
+		running:  map[int64]context.CancelFunc{},
+		waiters:  map[int64][]chan struct{}{},
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Register sets the handler for a kind of job. Only registered kinds are
+// taken from the queue.
+func (q *Queue) Register(kind string, h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[kind] = h
+}
+
+// OnUpdate is called whenever a job is queued, makes progress or finishes.
+// The job passed has no log.
+func (q *Queue) OnUpdate(fn func(models.Job)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onUpdate = fn
+}
+
+// Start recovers jobs interrupted by the last shutdown, prunes old history
+// and starts the workers
+func (q *Queue) Start(ctx context.Context) error {
+	if err := q.db.RecoverInterruptedJobs(MaxAttempts); err != nil {
+		return err
+	}
+	if err := q.db.PruneJobs(History); err != nil {
+		return err
+	}
+
+	q.ctx, q.stop = context.WithCancel(ctx)
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return nil
+}
+
+// Stop cancels running jobs and waits for the workers. The jobs stay
+// marked as running so the next Start queues them again.
+func (q *Queue) Stop() {
+	if q.stop == nil {
+		return
+	}
+	q.stop()
+	q.wg.Wait()
+}
+
+// Submit queues a job. params is stored as JSON for the handler.
+func (q *Queue) Submit(kind, label string, params any) (*models.Job, error) {
+	return q.submit(kind, label, params, nil)
+}
+
+func (q *Queue) submit(kind, label string, params any, retryOf *int64) (*models.Job, error) {
+	q.mu.Lock()
+	_, ok := q.handlers[kind]
+	q.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown job kind %q", kind)
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("encode job params: %w", err)
+	}
+	job := &models.Job{Kind: kind, Label: label, Params: string(data), RetryOf: retryOf}
+	if err := q.db.AddJob(job); err != nil {
+		return nil, err
+	}
+	q.notify(*job)
+	q.signal()
+	return job, nil
+}
+
+// Cancel stops a running job or drops a queued one
+func (q *Queue) Cancel(jobID int64) error {
+	dropped, err := q.db.CancelQueuedJob(jobID)
+	if err != nil {
+		return err
+	}
+	if dropped {
+		if job, err := q.db.GetJob(jobID); err == nil && job != nil {
+			q.finished(*job)
+		}
+		return nil
+	}
+
+	q.mu.Lock()
+	cancel := q.running[jobID]
+	q.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		return nil
+	}
+
+	job, err := q.db.GetJob(jobID)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return fmt.Errorf("job %d not found", jobID)
+	}
+	if job.Finished() {
+		return fmt.Errorf("job %d has already %s", jobID, job.Status)
+	}
+	return fmt.Errorf("job %d is not running here", jobID)
+}
+
+// Retry queues a failed or cancelled job again with the same params
+func (q *Queue) Retry(jobID int64) (*models.Job, error) {
+	job, err := q.db.GetJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("job %d not found", jobID)
+	}
+	if job.Status != models.JobFailed && job.Status != models.JobCancelled {
+		return nil, fmt.Errorf("job %d is %s; only failed or cancelled jobs can be retried", jobID, job.Status)
+	}
+	return q.submit(job.Kind, job.Label, json.RawMessage(job.Params), &job.JobID)
+}
+
+// Wait blocks until a job finishes and returns it
+func (q *Queue) Wait(ctx context.Context, jobID int64) (*models.Job, error) {
+	ch := make(chan struct{})
+	q.mu.Lock()
+	q.waiters[jobID] = append(q.waiters[jobID], ch)
+	q.mu.Unlock()
+
+	for {
+		job, err := q.db.GetJob(jobID)
+		if err != nil {
+			return nil, err
+		}
+		if job == nil {
+			return nil, fmt.Errorf("job %d not found", jobID)
+		}
+		if job.Finished() {
+			return job, nil
+		}
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Run submits a job and waits for it, decoding its result into result.
+// It fails with the job's error if the job does not succeed.
+func (q *Queue) Run(ctx context.Context, kind, label string, params, result any) (*models.Job, error) {
+	job, err := q.Submit(kind, label, params)
+	if err != nil {
+		return nil, err
+	}
+	job, err = q.Wait(ctx, job.JobID)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case models.JobCancelled:
+		return job, context.Canceled
+	case models.JobFailed:
+		return job, fmt.Errorf("%s", job.Error)
+	}
+	if result != nil && job.Result != "" {
+		if err := json.Unmarshal([]byte(job.Result), result); err != nil {
+			return job, fmt.Errorf("decode result of job %d: %w", job.JobID, err)
+		}
+	}
+	return job, nil
+}
+
+// DecodeParams unmarshals a job's params
+func DecodeParams(job *models.Job, v any) error {
+	if err := json.Unmarshal([]byte(job.Params), v); err != nil {
+		return fmt.Errorf("decode params of job %d: %w", job.JobID, err)
+	}
+	return nil
+}
+
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for {
+		if q.ctx.Err() != nil {
+			return
+		}
+		// Claim and register under one lock so Cancel always finds a
+		// running job
+		q.mu.Lock()
+		job, err := q.db.ClaimNextJob(q.kinds())
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if err == nil && job != nil {
+			ctx, cancel = context.WithCancel(q.ctx)
+			q.running[job.JobID] = cancel
+		}
+		q.mu.Unlock()
+		if err != nil || job == nil {
+			select {
+			case <-q.ctx.Done():
+				return
+			case <-q.wake:
+			case <-time.After(pollInterval):
+			}
+			continue
+		}
+		// Another worker may be idle with work still queued
+		q.signal()
+		q.run(ctx, cancel, job)
+	}
+}
+
+// kinds lists the registered kinds; q.mu must be held
+func (q *Queue) kinds() []string {
+	kinds := make([]string, 0, len(q.handlers))
+	for k := range q.handlers {
+		kinds = append(kinds, k)
+	}
+	return kinds
+}
+
+func (q *Queue) run(ctx context.Context, cancel context.CancelFunc, job *models.Job) {
+	q.mu.Lock()
+	handler := q.handlers[job.Kind]
+	q.mu.Unlock()
+
+	r := newReporter(q, job)
+	r.Logf("Started (attempt %d)", job.Attempts)
+	q.notify(*job)
+
+	result, err := call(ctx, handler, job, r)
+	cancelled := ctx.Err() != nil
+
+	cancel()
+	q.mu.Lock()
+	delete(q.running, job.JobID)
+	q.mu.Unlock()
+
+	if q.ctx.Err() != nil {
+		// Shutting down: keep the job running so it is recovered
+		r.save()
+		return
+	}
+
+	job.Status = models.JobSucceeded
+	switch {
+	case err == nil:
+		if result != nil {
+			data, encErr := json.Marshal(result)
+			if encErr != nil {
+				job.Status, job.Error = models.JobFailed, fmt.Sprintf("encode result: %v", encErr)
+			} else {
+				job.Result = string(data)
+			}
+		}
+	case cancelled:
+		job.Status, job.Error = models.JobCancelled, "cancelled"
+	default:
+		job.Status, job.Error = models.JobFailed, err.Error()
+	}
+	if job.Error != "" {
+		r.Logf("Stopped: %s", job.Error)
+	} else {
+		r.Logf("Finished")
+	}
+
+	job.Log = r.log()
+	if err := q.db.FinishJob(job.JobID, job.Status, job.Result, job.Error, job.Log); err != nil {
+		return
+	}
+	q.finished(*job)
+}
+
+// call runs a handler, turning a panic into an error so one bad job does
+// not take down the app
+func call(ctx context.Context, h Handler, job *models.Job, r *Reporter) (result any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+	if h == nil {
+		return nil, fmt.Errorf("no handler for %q", job.Kind)
+	}
+	return h(ctx, job, r)
+}
+
+func (q *Queue) notify(job models.Job) {
+	q.mu.Lock()
+	fn := q.onUpdate
+	q.mu.Unlock()
+	if fn != nil {
+		job.Log = ""
+		fn(job)
+	}
+}
+
+func (q *Queue) finished(job models.Job) {
+	q.notify(job)
+	q.mu.Lock()
+	waiters := q.waiters[job.JobID]
+	delete(q.waiters, job.JobID)
+	q.mu.Unlock()
+	for _, ch := range waiters {
+		close(ch)
+	}
+}
+
+// Reporter records a running job's progress and log
+type Reporter struct {
+	q        *Queue
+	job      *models.Job
+	mu       sync.Mutex
+	lines    []string
+	lastSave time.Time
+}
+
+func newReporter(q *Queue, job *models.Job) *Reporter {
+	r := &Reporter{q: q, job: job}
+	if job.Log != "" {
+		r.lines = strings.Split(strings.TrimSuffix(job.Log, "\n"), "\n")
+	}
+	return r
+}
+
+// Progress updates the job's progress. A new message is also logged.
+// Updates are saved and announced at most a few times a second.
+func (r *Reporter) Progress(current, total int, message string) {
+	r.mu.Lock()
+	if message != "" && message != r.job.Message {
+		r.appendLocked(message)
+	}
+	r.job.ProgressCurrent, r.job.ProgressTotal, r.job.Message = current, total, message
+	due := time.Since(r.lastSave) >= progressInterval || current >= total
+	r.mu.Unlock()
+
+	if due {
+		r.save()
+	}
+}
+
+// Logf adds a line to the job's log
+func (r *Reporter) Logf(format string, args ...any) {
+	r.mu.Lock()
+	r.appendLocked(fmt.Sprintf(format, args...))
+	r.mu.Unlock()
+}
+
+func (r *Reporter) appendLocked(line string) {
+	r.lines = append(r.lines, time.Now().Format("15:04:05")+" "+line)
+	if len(r.lines) > maxLogLines {
+		r.lines = r.lines[len(r.lines)-maxLogLines:]
+	}
+}
+
+func (r *Reporter) log() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.lines) == 0 {
+		return ""
+	}
+	return strings.Join(r.lines, "\n") + "\n"
+}
+
+func (r *Reporter) save() {
+	log := r.log()
+	r.mu.Lock()
+	r.lastSave = time.Now()
+	job := *r.job
+	r.mu.Unlock()
+
+	_ = r.q.db.UpdateJobProgress(job.JobID, job.ProgressCurrent, job.ProgressTotal, job.Message, log)
+	r.q.notify(job)
+}
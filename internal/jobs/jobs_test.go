@@ -0,0 +1,194 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/db"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func setupTestDB(t *testing.T) *db.DB {
+	t.Helper()
+	database, err := db.New(filepath.Join(t.TempDir(), "works.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.InitSchemaFromFile("../migrations/sql/001_initial_schema.sql"); err != nil {
+		t.Fatalf("init schema: %v", err)
+	}
+	if err := database.RunMigrations(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return database
+}
+
+func startQueue(t *testing.T, database *db.DB, register func(q *Queue)) *Queue {
+	t.Helper()
+	q := New(database, 2)
+	register(q)
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(q.Stop)
+	return q
+}
+
+func waitFor(t *testing.T, q *Queue, jobID int64) *models.Job {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	job, err := q.Wait(ctx, jobID)
+	if err != nil {
+		t.Fatalf("wait for job %d: %v", jobID, err)
+	}
+	return job
+}
+
+func TestRunAndRetry(t *testing.T) {
+	database := setupTestDB(t)
+	var calls atomic.Int32
+	q := startQueue(t, database, func(q *Queue) {
+		q.Register("sum", func(ctx context.Context, job *models.Job, r *Reporter) (any, error) {
+			var params struct{ Values []int }
+			if err := DecodeParams(job, &params); err != nil {
+				return nil, err
+			}
+			if calls.Add(1) == 1 {
+				return nil, errors.New("flaky")
+			}
+			total := 0
+			for i, v := range params.Values {
+				total += v
+				r.Progress(i+1, len(params.Values), "adding")
+			}
+			return map[string]int{"total": total}, nil
+		})
+	})
+
+	var result struct{ Total int }
+	failed, err := q.Run(context.Background(), "sum", "Add", map[string][]int{"values": {1, 2, 3}}, &result)
+	if err == nil || failed.Status != models.JobFailed || failed.Error != "flaky" {
+		t.Fatalf("first run should fail, got %+v, %v", failed, err)
+	}
+	if !strings.Contains(failed.Log, "Stopped: flaky") {
+		t.Errorf("log should record the failure:\n%s", failed.Log)
+	}
+
+	retry, err := q.Retry(failed.JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := waitFor(t, q, retry.JobID)
+	if done.Status != models.JobSucceeded || done.Result != `{"total":6}` || done.ProgressCurrent != 3 {
+		t.Fatalf("retry = %+v", done)
+	}
+	if done.RetryOf == nil || *done.RetryOf != failed.JobID {
+		t.Errorf("retry should point at job %d", failed.JobID)
+	}
+	if _, err := q.Retry(done.JobID); err == nil {
+		t.Error("a succeeded job should not be retried")
+	}
+
+	history, err := database.ListJobs("", 0)
+	if err != nil || len(history) != 2 || history[0].JobID != retry.JobID || history[0].Log != "" {
+		t.Errorf("history = %+v, %v", history, err)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	database := setupTestDB(t)
+	started := make(chan int64, 4)
+	q := New(database, 1)
+	q.Register("block", func(ctx context.Context, job *models.Job, r *Reporter) (any, error) {
+		started <- job.JobID
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	var updates atomic.Int32
+	q.OnUpdate(func(models.Job) { updates.Add(1) })
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(q.Stop)
+
+	first, err := q.Submit("block", "First", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := q.Submit("block", "Second", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := <-started; id != first.JobID {
+		t.Fatalf("job %d started first", id)
+	}
+
+	// With one worker the second job is still queued
+	if err := q.Cancel(second.JobID); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Cancel(first.JobID); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int64{first.JobID, second.JobID} {
+		if job := waitFor(t, q, id); job.Status != models.JobCancelled {
+			t.Errorf("job %d is %s", id, job.Status)
+		}
+	}
+	if err := q.Cancel(first.JobID); err == nil {
+		t.Error("cancelling a finished job should fail")
+	}
+	if updates.Load() == 0 {
+		t.Error("expected progress events")
+	}
+	if _, err := q.Submit("unknown", "", nil); err == nil {
+		t.Error("expected an error for an unregistered kind")
+	}
+}
+
+func TestInterruptedJobsResume(t *testing.T) {
+	database := setupTestDB(t)
+	started := make(chan struct{}, 1)
+
+	q := New(database, 1)
+	q.Register("slow", func(ctx context.Context, job *models.Job, r *Reporter) (any, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	job, err := q.Submit("slow", "Slow", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	q.Stop()
+
+	if j, _ := database.GetJob(job.JobID); j.Status != models.JobRunning {
+		t.Fatalf("a job stopped by shutdown should stay running, got %s", j.Status)
+	}
+
+	// The next session picks it up again
+	resumed := startQueue(t, database, func(q *Queue) {
+		q.Register("slow", func(ctx context.Context, job *models.Job, r *Reporter) (any, error) {
+			return "done", nil
+		})
+	})
+	done := waitFor(t, resumed, job.JobID)
+	if done.Status != models.JobSucceeded || done.Attempts != 2 {
+		t.Errorf("resumed job = %+v", done)
+	}
+	if !strings.Contains(done.Log, "queued again") {
+		t.Errorf("log should note the interruption:\n%s", done.Log)
+	}
+}
@@ -0,0 +1,59 @@
+// Package kdp holds the Kindle Direct Publishing print specifications a
+// paperback is held to, and the preflight checks run against a book's
+// galley and cover PDFs before they are uploaded.
+package kdp
+
+import "github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+
+const mmPerInch = 25.4
+
+// pageLimits is the page count range KDP prints for each paper type
+var pageLimits = map[string][2]int{
+	bookbuild.PaperPremiumColor:  {24, 828},
+	bookbuild.PaperStandardColor: {72, 600},
+	bookbuild.PaperWhite:         {24, 828},
+	bookbuild.PaperCream:         {24, 776},
+}
+
+// IsColor reports whether the paper type prints in color
+func IsColor(paperType string) bool {
+	return paperType != bookbuild.PaperWhite && paperType != bookbuild.PaperCream
+}
+
+// PageLimits returns the smallest and largest page counts KDP prints on a
+// paper type
+func PageLimits(paperType string) (minPages, maxPages int) {
+	limits, ok := pageLimits[paperType]
+	if !ok {
+		limits = pageLimits[bookbuild.PaperPremiumColor]
+	}
+	return limits[0], limits[1]
+}
+
+// InsideMarginMM returns the minimum gutter for a page count. Thicker books
+// need more room at the binding.
+func InsideMarginMM(pageCount int) float64 {
+	switch {
+	case pageCount <= 150:
+		return 0.375 * mmPerInch
+	case pageCount <= 300:
+		return 0.5 * mmPerInch
+	case pageCount <= 500:
+		return 0.625 * mmPerInch
+	case pageCount <= 700:
+		return 0.75 * mmPerInch
+	default:
+		return 0.875 * mmPerInch
+	}
+}
+
+// OutsideMarginMM returns the minimum top, bottom and outside margin
+func OutsideMarginMM(bleed bool) float64 {
+	if bleed {
+		return 0.375 * mmPerInch
+	}
+	return 0.25 * mmPerInch
+}
+
+// MinImageDPI is the resolution KDP asks for in placed images
+const MinImageDPI = 300
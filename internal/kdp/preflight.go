@@ -0,0 +1,396 @@
+package kdp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+const ptPerMM = 72 / mmPerInch
+
+// Severity of a finding: errors are things KDP rejects, warnings are
+// things it accepts but that will probably print badly
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Checks a finding can come from
+const (
+	CheckFile         = "file"
+	CheckTrim         = "trim"
+	CheckPageCount    = "pageCount"
+	CheckMargins      = "margins"
+	CheckFonts        = "fonts"
+	CheckColor        = "color"
+	CheckTransparency = "transparency"
+	CheckImages       = "images"
+	CheckCoverSize    = "coverSize"
+	CheckSpine        = "spine"
+)
+
+// Finding is one problem, with the pages it was found on where that applies
+type Finding struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Pages    []int  `json:"pages,omitempty"`
+}
+
+// Spec is what the book is printed as
+type Spec struct {
+	TrimSize  string
+	PaperType string
+	SpineText bool // the cover carries text on the spine
+}
+
+// SpecFromBook returns the print specification set on a book
+func SpecFromBook(book *models.Book) Spec {
+	return Spec{
+		TrimSize:  book.TrimSize,
+		PaperType: book.PaperType,
+		SpineText: book.SpineText != nil && strings.TrimSpace(*book.SpineText) != "",
+	}
+}
+
+// Report is the outcome of a preflight
+type Report struct {
+	PageCount int       `json:"pageCount"`
+	Bleed     bool      `json:"bleed"`
+	SpineMM   float64   `json:"spineMM"`
+	Galley    []Finding `json:"galley"`
+	Cover     []Finding `json:"cover"`
+}
+
+// Passed reports whether the preflight found no errors
+func (r *Report) Passed() bool {
+	for _, findings := range [][]Finding{r.Galley, r.Cover} {
+		for _, f := range findings {
+			if f.Severity == SeverityError {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Preflight checks a galley, and the cover when coverPath is set, against
+// KDP's requirements for the book's trim size and paper. It only fails if
+// the galley cannot be read; everything else is reported as findings.
+func Preflight(galleyPath, coverPath string, spec Spec) (*Report, error) {
+	report, err := CheckGalley(galleyPath, spec)
+	if err != nil {
+		return nil, err
+	}
+	if coverPath != "" {
+		report.Cover = CheckCover(coverPath, spec, report.PageCount)
+	}
+	return report, nil
+}
+
+// CheckGalley preflights the interior alone
+func CheckGalley(path string, spec Spec) (*Report, error) {
+	ctx, err := api.ReadContextFile(bookbuild.ExpandPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("read galley: %w", err)
+	}
+	report := &Report{
+		PageCount: ctx.PageCount,
+		SpineMM:   bookbuild.SpineWidthMM(ctx.PageCount, spec.PaperType),
+		Cover:     []Finding{},
+	}
+	report.Galley = checkGalley(ctx, path, spec, report)
+	return report, nil
+}
+
+// pageGeometry is where the trim edges fall on a page and which side the
+// binding is on
+type pageGeometry struct {
+	trim  types.Rectangle
+	recto bool
+}
+
+func checkGalley(ctx *model.Context, path string, spec Spec, report *Report) []Finding {
+	findings := []Finding{}
+	add := func(check, severity string, pages []int, format string, args ...any) {
+		findings = append(findings, Finding{Check: check, Severity: severity, Message: fmt.Sprintf(format, args...), Pages: pages})
+	}
+
+	trim := bookbuild.TrimSizeFor(spec.TrimSize)
+	trimW, trimH := trim.WidthMM*ptPerMM, trim.HeightMM*ptPerMM
+	bleed := bookbuild.BleedMM * ptPerMM
+
+	// Page count
+	minPages, maxPages := PageLimits(spec.PaperType)
+	switch {
+	case report.PageCount < minPages:
+		add(CheckPageCount, SeverityError, nil, "%d pages; KDP needs at least %d on this paper", report.PageCount, minPages)
+	case report.PageCount > maxPages:
+		add(CheckPageCount, SeverityError, nil, "%d pages; KDP prints at most %d on this paper", report.PageCount, maxPages)
+	}
+	if report.PageCount%2 != 0 {
+		add(CheckPageCount, SeverityWarning, nil, "Odd page count (%d); KDP will add a blank page at the end", report.PageCount)
+	}
+
+	// Trim size and bleed
+	boundaries, err := ctx.PageBoundaries(nil)
+	if err != nil {
+		add(CheckFile, SeverityError, nil, "Could not read page sizes: %v", err)
+		return findings
+	}
+	geometry := make([]*pageGeometry, len(boundaries))
+	var wrongSize, bleedPages, trimPages []int
+	for i, pb := range boundaries {
+		box := pb.TrimBox()
+		if box == nil {
+			continue
+		}
+		page := i + 1
+		w, h := box.Width(), box.Height()
+		if pb.Rot%180 != 0 {
+			w, h = h, w
+		}
+		g := &pageGeometry{recto: bookbuild.IsRecto(page)}
+		switch {
+		case near(w, trimW) && near(h, trimH):
+			g.trim = *types.NewRectangle(box.LL.X, box.LL.Y, box.LL.X+trimW, box.LL.Y+trimH)
+			trimPages = append(trimPages, page)
+		case near(w, trimW+bleed) && near(h, trimH+2*bleed):
+			// Interior bleed is on the outside edge only, away from the binding
+			x := box.LL.X + bleed
+			if g.recto {
+				x = box.LL.X
+			}
+			g.trim = *types.NewRectangle(x, box.LL.Y+bleed, x+trimW, box.LL.Y+bleed+trimH)
+			bleedPages = append(bleedPages, page)
+		default:
+			wrongSize = append(wrongSize, page)
+			continue
+		}
+		geometry[i] = g
+	}
+	report.Bleed = len(bleedPages) > 0 && len(trimPages) == 0
+	if len(wrongSize) > 0 {
+		add(CheckTrim, SeverityError, wrongSize, "Pages do not match the %s trim size (%.3f\" x %.3f\"), with or without bleed: %s",
+			trimCode(spec.TrimSize), trim.WidthMM/mmPerInch, trim.HeightMM/mmPerInch, pageList(wrongSize))
+	}
+	if len(bleedPages) > 0 && len(trimPages) > 0 {
+		add(CheckTrim, SeverityError, bleedPages, "Bleed and non-bleed pages are mixed; pages with bleed: %s", pageList(bleedPages))
+	}
+
+	// Fonts
+	if issues, err := bookbuild.CheckFontsEmbedded(path); err != nil {
+		add(CheckFonts, SeverityWarning, nil, "Could not check font embedding: %v", err)
+	} else {
+		for _, issue := range issues {
+			add(CheckFonts, SeverityError, nil, "Font not embedded: %s (%s)", issue.FontName, issue.FontType)
+		}
+	}
+
+	// Page content
+	inside := InsideMarginMM(report.PageCount) * ptPerMM
+	outside := OutsideMarginMM(report.Bleed) * ptPerMM
+	margins := map[string][]int{}
+	var colorPages, transparentPages, lowResPages, unreadable []int
+	lowestDPI := math.Inf(1)
+	for i := 1; i <= ctx.PageCount; i++ {
+		marks, err := scanPage(ctx, i)
+		if err != nil {
+			unreadable = append(unreadable, i)
+			continue
+		}
+		if g := geometry[i-1]; g != nil {
+			for _, side := range marginViolations(marks.text, g, inside, outside) {
+				margins[side] = appendPage(margins[side], i)
+			}
+		}
+		color := marks.color
+		for _, img := range marks.images {
+			color = color || img.color
+			if img.dpi < MinImageDPI {
+				lowResPages = appendPage(lowResPages, i)
+				lowestDPI = math.Min(lowestDPI, img.dpi)
+			}
+		}
+		if color {
+			colorPages = append(colorPages, i)
+		}
+		if marks.transparency {
+			transparentPages = append(transparentPages, i)
+		}
+	}
+
+	if len(unreadable) > 0 {
+		add(CheckFile, SeverityWarning, unreadable, "Could not read the content of pages %s", pageList(unreadable))
+	}
+	sides := make([]string, 0, len(margins))
+	for side := range margins {
+		sides = append(sides, side)
+	}
+	sort.Strings(sides)
+	for _, side := range sides {
+		minimum := outside
+		if side == "gutter" {
+			minimum = inside
+		}
+		add(CheckMargins, SeverityError, margins[side], "Text inside the %s %s margin on pages %s",
+			inches(minimum), side, pageList(margins[side]))
+	}
+	if len(colorPages) > 0 && !IsColor(spec.PaperType) {
+		add(CheckColor, SeverityWarning, colorPages, "Color on pages %s will print in grayscale on black and white paper", pageList(colorPages))
+	}
+	if len(transparentPages) > 0 {
+		add(CheckTransparency, SeverityWarning, transparentPages, "Transparency on pages %s is flattened by KDP; check them in the print previewer", pageList(transparentPages))
+	}
+	if len(lowResPages) > 0 {
+		add(CheckImages, SeverityWarning, lowResPages, "Images below %d DPI on pages %s (lowest %.0f DPI)", MinImageDPI, pageList(lowResPages), lowestDPI)
+	}
+	return findings
+}
+
+// marginViolations returns the margins, named by side, that text on a page
+// reaches into. Glyph extents are estimated from the font size.
+func marginViolations(text []textRun, g *pageGeometry, inside, outside float64) []string {
+	const tolerance = 0.5
+	left, right := inside, outside
+	leftSide, rightSide := "gutter", "outside"
+	if !g.recto {
+		left, right = outside, inside
+		leftSide, rightSide = rightSide, leftSide
+	}
+
+	hit := map[string]bool{}
+	for _, t := range text {
+		if t.size <= 0 {
+			continue
+		}
+		// Text placed off the page entirely is not printed
+		if t.x < g.trim.LL.X-t.size || t.x > g.trim.UR.X || t.y < g.trim.LL.Y-t.size || t.y > g.trim.UR.Y {
+			continue
+		}
+		if t.x-g.trim.LL.X < left-tolerance {
+			hit[leftSide] = true
+		}
+		if g.trim.UR.X-t.x < right-tolerance {
+			hit[rightSide] = true
+		}
+		if g.trim.UR.Y-(t.y+0.7*t.size) < outside-tolerance {
+			hit["top"] = true
+		}
+		if t.y-0.2*t.size-g.trim.LL.Y < outside-tolerance {
+			hit["bottom"] = true
+		}
+	}
+
+	sides := make([]string, 0, len(hit))
+	for side := range hit {
+		sides = append(sides, side)
+	}
+	sort.Strings(sides)
+	return sides
+}
+
+// CheckCover preflights a wraparound cover for a galley of pageCount pages
+func CheckCover(path string, spec Spec, pageCount int) []Finding {
+	findings := []Finding{}
+	add := func(check, severity string, format string, args ...any) {
+		findings = append(findings, Finding{Check: check, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	ctx, err := api.ReadContextFile(bookbuild.ExpandPath(path))
+	if err != nil {
+		add(CheckFile, SeverityError, "Could not read cover PDF: %v", err)
+		return findings
+	}
+	if ctx.PageCount != 1 {
+		add(CheckCoverSize, SeverityError, "Cover PDF has %d pages; KDP expects a single wraparound page", ctx.PageCount)
+	}
+
+	dims, err := ctx.PageDims()
+	if err != nil || len(dims) == 0 {
+		add(CheckFile, SeverityError, "Could not read cover size")
+		return findings
+	}
+	wantW, wantH := bookbuild.CoverSizeMM(spec.TrimSize, spec.PaperType, pageCount)
+	gotW, gotH := dims[0].Width/ptPerMM, dims[0].Height/ptPerMM
+	if math.Abs(gotW-wantW) > 1 || math.Abs(gotH-wantH) > 1 {
+		add(CheckCoverSize, SeverityError, "Cover is %.2f x %.2f mm; %d pages on this paper need %.2f x %.2f mm (%.2f mm spine) - regenerate the cover",
+			gotW, gotH, pageCount, wantW, wantH, bookbuild.SpineWidthMM(pageCount, spec.PaperType))
+	}
+
+	if spec.SpineText && pageCount < bookbuild.MinSpineTextPages {
+		add(CheckSpine, SeverityError, "Spine text needs at least %d pages; this book has %d", bookbuild.MinSpineTextPages, pageCount)
+	}
+
+	if issues, err := bookbuild.CheckFontsEmbedded(path); err != nil {
+		add(CheckFonts, SeverityWarning, "Could not check font embedding: %v", err)
+	} else {
+		for _, issue := range issues {
+			add(CheckFonts, SeverityError, "Font not embedded: %s (%s)", issue.FontName, issue.FontType)
+		}
+	}
+
+	marks, err := scanPage(ctx, 1)
+	if err != nil {
+		add(CheckFile, SeverityWarning, "Could not read the cover content: %v", err)
+		return findings
+	}
+	lowest := math.Inf(1)
+	for _, img := range marks.images {
+		lowest = math.Min(lowest, img.dpi)
+	}
+	if lowest < MinImageDPI {
+		add(CheckImages, SeverityWarning, "Cover images below %d DPI (lowest %.0f DPI)", MinImageDPI, lowest)
+	}
+	if marks.transparency {
+		add(CheckTransparency, SeverityWarning, "Cover uses transparency, which KDP flattens; check it in the cover previewer")
+	}
+	return findings
+}
+
+// near compares page dimensions in points, allowing for rounding
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1
+}
+
+func appendPage(pages []int, page int) []int {
+	if n := len(pages); n > 0 && pages[n-1] == page {
+		return pages
+	}
+	return append(pages, page)
+}
+
+// pageList lists the first few pages for a message
+func pageList(pages []int) string {
+	const shown = 8
+	parts := make([]string, 0, shown)
+	for i, p := range pages {
+		if i == shown {
+			break
+		}
+		parts = append(parts, fmt.Sprint(p))
+	}
+	s := strings.Join(parts, ", ")
+	if len(pages) > shown {
+		s += fmt.Sprintf(" (+%d more)", len(pages)-shown)
+	}
+	return s
+}
+
+func inches(pt float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", pt/72), "0"), ".") + "\""
+}
+
+func trimCode(code string) string {
+	if _, ok := bookbuild.TrimSizes[code]; ok {
+		return code
+	}
+	return bookbuild.DefaultTrimSize
+}
@@ -0,0 +1,191 @@
+package kdp
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+func TestMain(m *testing.M) {
+	api.DisableConfigDir()
+	os.Exit(m.Run())
+}
+
+// testPage is a page of a generated PDF: its size and content stream.
+// Pages share one resource dictionary with a standard (unembedded) font,
+// an RGB image and a semi-transparent graphics state.
+type testPage struct {
+	width, height float64
+	content       string
+}
+
+// writePDF writes a minimal PDF by hand so the tests control exactly what
+// each page draws
+func writePDF(t *testing.T, path string, pages []testPage) {
+	t.Helper()
+	var objects []string
+	add := func(obj string) int {
+		objects = append(objects, obj)
+		return len(objects)
+	}
+
+	catalog := add("")
+	pagesObj := add("")
+	font := add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+	image := add("<< /Type /XObject /Subtype /Image /Width 2 /Height 2 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Length 12 >>\nstream\n" +
+		"\xff\x00\x00\x00\xff\x00\x00\x00\xff\xff\xff\xff\nendstream")
+	alpha := add("<< /Type /ExtGState /ca 0.5 >>")
+	resources := add(fmt.Sprintf("<< /Font << /F1 %d 0 R >> /XObject << /Im1 %d 0 R >> /ExtGState << /GS1 %d 0 R >> >>", font, image, alpha))
+
+	var kids bytes.Buffer
+	for _, p := range pages {
+		content := add(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(p.content), p.content))
+		page := add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %g %g] /Resources %d 0 R /Contents %d 0 R >>",
+			pagesObj, p.width, p.height, resources, content))
+		fmt.Fprintf(&kids, "%d 0 R ", page)
+	}
+	objects[catalog-1] = fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj)
+	objects[pagesObj-1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids.String(), len(pages))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, catalog, xref)
+
+	if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func text(x, y float64) string {
+	return fmt.Sprintf("BT /F1 11 Tf %g %g Td (Line) Tj ET", x, y)
+}
+
+func findingsByCheck(findings []Finding) map[string][]Finding {
+	out := map[string][]Finding{}
+	for _, f := range findings {
+		out[f.Check] = append(out[f.Check], f)
+	}
+	return out
+}
+
+func TestPreflightGalley(t *testing.T) {
+	const w, h = 432, 648 // 6x9
+	pages := make([]testPage, 25)
+	for i := range pages {
+		pages[i] = testPage{w, h, text(72, 300)}
+	}
+	// Page 1 is recto: text in the gutter. Page 2 is verso: text running
+	// off the bottom, then moved back in with q/cm/Q.
+	pages[0].content = text(10, 300)
+	pages[1].content = "q 1 0 0 1 0 -290 cm " + text(100, 300) + " Q " + text(100, 300)
+	pages[2].content = "/GS1 gs 0.2 0.4 0.6 rg q 72 0 0 72 100 100 cm /Im1 Do Q " + text(100, 300)
+	pages[3] = testPage{612, 792, text(72, 300)}
+
+	dir := t.TempDir()
+	galley := filepath.Join(dir, "galley.pdf")
+	writePDF(t, galley, pages)
+
+	report, err := Preflight(galley, "", Spec{TrimSize: "6x9", PaperType: bookbuild.PaperWhite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.PageCount != 25 || report.Bleed || report.Passed() {
+		t.Errorf("unexpected report summary: %+v", report)
+	}
+
+	byCheck := findingsByCheck(report.Galley)
+	expectPages := func(check string, index int, want []int) {
+		t.Helper()
+		if len(byCheck[check]) <= index {
+			t.Errorf("no %s finding #%d in %+v", check, index, report.Galley)
+			return
+		}
+		if got := byCheck[check][index].Pages; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: pages %v, want %v (%s)", check, got, want, byCheck[check][index].Message)
+		}
+	}
+	expectPages(CheckTrim, 0, []int{4})
+	expectPages(CheckMargins, 0, []int{2}) // bottom
+	expectPages(CheckMargins, 1, []int{1}) // gutter
+	expectPages(CheckColor, 0, []int{3})
+	expectPages(CheckTransparency, 0, []int{3})
+	expectPages(CheckImages, 0, []int{3})
+
+	if f := byCheck[CheckPageCount]; len(f) != 1 || f[0].Severity != SeverityWarning {
+		t.Errorf("expected an odd page count warning, got %+v", f)
+	}
+	if f := byCheck[CheckFonts]; len(f) != 1 || f[0].Severity != SeverityError {
+		t.Errorf("expected the unembedded font to be reported, got %+v", f)
+	}
+
+	report, err = Preflight(galley, "", Spec{TrimSize: "6x9", PaperType: bookbuild.PaperPremiumColor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := findingsByCheck(report.Galley)[CheckColor]; len(f) != 0 {
+		t.Errorf("color should not be flagged on color paper, got %+v", f)
+	}
+}
+
+func TestPreflightCover(t *testing.T) {
+	dir := t.TempDir()
+	galley := filepath.Join(dir, "galley.pdf")
+	pages := make([]testPage, 40)
+	for i := range pages {
+		pages[i] = testPage{432, 648, text(72, 300)}
+	}
+	writePDF(t, galley, pages)
+
+	widthMM, heightMM := bookbuild.CoverSizeMM("6x9", bookbuild.PaperCream, 40)
+	good := filepath.Join(dir, "cover.pdf")
+	writePDF(t, good, []testPage{{widthMM * ptPerMM, heightMM * ptPerMM, ""}})
+
+	report, err := Preflight(galley, good, Spec{TrimSize: "6x9", PaperType: bookbuild.PaperCream})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byCheck := findingsByCheck(report.Cover)
+	if len(byCheck[CheckCoverSize]) != 0 || len(byCheck[CheckSpine]) != 0 {
+		t.Errorf("expected the cover to fit, got %+v", report.Cover)
+	}
+
+	report, err = Preflight(galley, good, Spec{TrimSize: "5x8", PaperType: bookbuild.PaperCream, SpineText: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byCheck = findingsByCheck(report.Cover)
+	if len(byCheck[CheckCoverSize]) != 1 || len(byCheck[CheckSpine]) != 1 {
+		t.Errorf("expected cover size and spine text errors, got %+v", report.Cover)
+	}
+}
+
+func TestSpecs(t *testing.T) {
+	if got := bookbuild.SpineWidthMM(100, bookbuild.PaperCream); got < 6.34 || got > 6.36 {
+		t.Errorf("cream spine for 100 pages = %v", got)
+	}
+	if got := bookbuild.SpineWidthMM(100, "unknown"); got != bookbuild.SpineWidthMM(100, bookbuild.PaperPremiumColor) {
+		t.Errorf("unknown paper should fall back to premium color, got %v", got)
+	}
+	if InsideMarginMM(150) >= InsideMarginMM(151) || InsideMarginMM(828) != 0.875*mmPerInch {
+		t.Error("gutter should grow with page count")
+	}
+	if bookbuild.TrimSizeFor("bogus") != bookbuild.TrimSizes["6x9"] {
+		t.Error("unknown trim size should fall back to 6x9")
+	}
+}
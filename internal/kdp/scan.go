@@ -0,0 +1,320 @@
+package kdp
+
+import (
+	"math"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/pdfcontent"
+)
+
+// maxFormDepth bounds recursion into nested form XObjects
+const maxFormDepth = 8
+
+// matrix is a PDF transformation [a b c d e f]
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns m applied first, then n
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return x*m[0] + y*m[2] + m[4], x*m[1] + y*m[3] + m[5]
+}
+
+// textRun is where a piece of text starts, in default user space
+type textRun struct {
+	x, y float64 // start of the baseline
+	size float64 // font size after scaling
+}
+
+// placedImage is an image drawn on the page
+type placedImage struct {
+	dpi   float64
+	color bool
+}
+
+// pageMarks is what a page draws, as far as preflight cares
+type pageMarks struct {
+	text         []textRun
+	images       []placedImage
+	color        bool // non-gray fill or stroke colors
+	transparency bool
+}
+
+type graphicsState struct {
+	ctm matrix
+}
+
+// scanner interprets a page's content streams, following the graphics
+// and text state closely enough to place text and images. It does not
+// measure glyphs, so only where text starts is known.
+type scanner struct {
+	ctx   *model.Context
+	marks *pageMarks
+}
+
+func scanPage(ctx *model.Context, pageNr int) (*pageMarks, error) {
+	d, _, _, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+	s := &scanner{ctx: ctx, marks: &pageMarks{}}
+	if group := s.dict(d["Group"]); group != nil {
+		if n := group.NameEntry("S"); n != nil && *n == "Transparency" {
+			s.marks.transparency = true
+		}
+	}
+	content, err := ctx.PageContent(d, pageNr)
+	if err == model.ErrNoContent {
+		return s.marks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.run(content, s.pageResources(d), identity, 0)
+	return s.marks, nil
+}
+
+// pageResources finds the resources in effect for a page, which may be
+// inherited from the page tree
+func (s *scanner) pageResources(d types.Dict) types.Dict {
+	for i := 0; d != nil && i < 32; i++ {
+		if res := s.dict(d["Resources"]); res != nil {
+			return res
+		}
+		d = s.dict(d["Parent"])
+	}
+	return nil
+}
+
+func (s *scanner) dict(o types.Object) types.Dict {
+	if o == nil {
+		return nil
+	}
+	d, err := s.ctx.DereferenceDict(o)
+	if err != nil {
+		return nil
+	}
+	return d
+}
+
+func (s *scanner) run(content []byte, res types.Dict, ctm matrix, depth int) {
+	gs := graphicsState{ctm: ctm}
+	var stack []graphicsState
+	var tm, tlm matrix
+	var fontSize, leading float64
+	var operands []pdfcontent.Token
+
+	num := func(i int) float64 {
+		if i < len(operands) {
+			return operands[i].Num
+		}
+		return 0
+	}
+	nums := func() []float64 {
+		out := make([]float64, len(operands))
+		for i, o := range operands {
+			out[i] = o.Num
+		}
+		return out
+	}
+	showText := func() {
+		trm := matrix{fontSize, 0, 0, fontSize, 0, 0}.mul(tm).mul(gs.ctm)
+		x, y := trm.apply(0, 0)
+		size := math.Hypot(trm[2], trm[3])
+		s.marks.text = append(s.marks.text, textRun{x: x, y: y, size: size})
+	}
+	moveText := func(tx, ty float64) {
+		tlm = matrix{1, 0, 0, 1, tx, ty}.mul(tlm)
+		tm = tlm
+	}
+
+	pdfcontent.Scan(content, func(op string, ops []pdfcontent.Token) {
+		operands = ops
+		switch op {
+		case "q":
+			stack = append(stack, gs)
+		case "Q":
+			if n := len(stack); n > 0 {
+				gs, stack = stack[n-1], stack[:n-1]
+			}
+		case "cm":
+			if len(operands) == 6 {
+				v := nums()
+				gs.ctm = matrix{v[0], v[1], v[2], v[3], v[4], v[5]}.mul(gs.ctm)
+			}
+		case "BT":
+			tm, tlm = identity, identity
+		case "Tf":
+			fontSize = num(1)
+		case "TL":
+			leading = num(0)
+		case "Td":
+			moveText(num(0), num(1))
+		case "TD":
+			leading = -num(1)
+			moveText(num(0), num(1))
+		case "Tm":
+			if len(operands) == 6 {
+				v := nums()
+				tm = matrix{v[0], v[1], v[2], v[3], v[4], v[5]}
+				tlm = tm
+			}
+		case "T*":
+			moveText(0, -leading)
+		case "Tj", "TJ":
+			showText()
+		case "'", "\"":
+			moveText(0, -leading)
+			showText()
+		case "rg", "RG":
+			if len(operands) == 3 && !gray(num(0), num(1), num(2)) {
+				s.marks.color = true
+			}
+		case "k", "K":
+			if len(operands) == 4 && (num(0) != 0 || num(1) != 0 || num(2) != 0) {
+				s.marks.color = true
+			}
+		case "gs":
+			if len(operands) == 1 {
+				s.extGState(res, operands[0].Text)
+			}
+		case "Do":
+			if len(operands) == 1 {
+				s.xObject(res, operands[0].Text, gs.ctm, depth)
+			}
+		}
+	})
+}
+
+func gray(r, g, b float64) bool {
+	const eps = 0.01
+	return math.Abs(r-g) < eps && math.Abs(g-b) < eps
+}
+
+func (s *scanner) extGState(res types.Dict, name string) {
+	states := s.dict(res["ExtGState"])
+	if states == nil {
+		return
+	}
+	gs := s.dict(states[name])
+	if gs == nil {
+		return
+	}
+	for _, key := range []string{"CA", "ca"} {
+		if o, ok := gs.Find(key); ok {
+			if v, err := s.ctx.DereferenceNumber(o); err == nil && v < 1 {
+				s.marks.transparency = true
+			}
+		}
+	}
+	if o, ok := gs.Find("SMask"); ok {
+		if n, isName := o.(types.Name); !isName || n != "None" {
+			s.marks.transparency = true
+		}
+	}
+}
+
+func (s *scanner) xObject(res types.Dict, name string, ctm matrix, depth int) {
+	objects := s.dict(res["XObject"])
+	if objects == nil {
+		return
+	}
+	sd, _, err := s.ctx.DereferenceStreamDict(objects[name])
+	if err != nil || sd == nil {
+		return
+	}
+
+	switch subtype := sd.Dict.NameEntry("Subtype"); {
+	case subtype != nil && *subtype == "Image":
+		s.image(sd, ctm)
+	case subtype != nil && *subtype == "Form" && depth < maxFormDepth:
+		if err := sd.Decode(); err != nil {
+			return
+		}
+		formRes := s.dict(sd.Dict["Resources"])
+		if formRes == nil {
+			formRes = res
+		}
+		m := identity
+		if a := sd.Dict.ArrayEntry("Matrix"); len(a) == 6 {
+			for i, o := range a {
+				m[i], _ = s.ctx.DereferenceNumber(o)
+			}
+		}
+		if group := s.dict(sd.Dict["Group"]); group != nil {
+			if n := group.NameEntry("S"); n != nil && *n == "Transparency" {
+				s.marks.transparency = true
+			}
+		}
+		s.run(sd.Content, formRes, m.mul(ctm), depth+1)
+	}
+}
+
+// image records an image's effective resolution as drawn: the unit square
+// of image space is mapped onto the page by the current transformation
+func (s *scanner) image(sd *types.StreamDict, ctm matrix) {
+	width := sd.Dict.IntEntry("Width")
+	height := sd.Dict.IntEntry("Height")
+	if width == nil || height == nil {
+		return
+	}
+	if _, ok := sd.Dict.Find("SMask"); ok {
+		s.marks.transparency = true
+	}
+
+	img := placedImage{dpi: math.Inf(1), color: s.colorSpace(sd.Dict["ColorSpace"])}
+	if w := math.Hypot(ctm[0], ctm[1]) / 72; w > 0 {
+		img.dpi = float64(*width) / w
+	}
+	if h := math.Hypot(ctm[2], ctm[3]) / 72; h > 0 {
+		img.dpi = math.Min(img.dpi, float64(*height)/h)
+	}
+	s.marks.images = append(s.marks.images, img)
+}
+
+// colorSpace reports whether an image color space carries color
+func (s *scanner) colorSpace(o types.Object) bool {
+	o, err := s.ctx.Dereference(o)
+	if err != nil || o == nil {
+		return false
+	}
+	switch cs := o.(type) {
+	case types.Name:
+		return cs == "DeviceRGB" || cs == "DeviceCMYK" || cs == "CalRGB" || cs == "Lab"
+	case types.Array:
+		if len(cs) == 0 {
+			return false
+		}
+		family, _ := cs[0].(types.Name)
+		switch family {
+		case "ICCBased":
+			if len(cs) > 1 {
+				if sd, _, err := s.ctx.DereferenceStreamDict(cs[1]); err == nil && sd != nil {
+					if n := sd.Dict.IntEntry("N"); n != nil {
+						return *n > 1
+					}
+				}
+			}
+		case "Indexed":
+			if len(cs) > 1 {
+				return s.colorSpace(cs[1])
+			}
+		case "CalRGB", "Lab", "DeviceN":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package models
+
+// AuditEntry is one captured row change. Before and After hold the row as
+// JSON; Before is empty for inserts and After is empty for deletes.
+type AuditEntry struct {
+	ID         int64  `json:"id" db:"id"`
+	OpID       int64  `json:"opID" db:"op_id"`
+	OpLabel    string `json:"opLabel" db:"op_label"`
+	TableName  string `json:"tableName" db:"table_name"`
+	EntityType string `json:"entityType" db:"entity_type"`
+	EntityID   int64  `json:"entityID" db:"entity_id"`
+	Action     string `json:"action" db:"action"`
+	Before     string `json:"before" db:"before_json"`
+	After      string `json:"after" db:"after_json"`
+	Undone     bool   `json:"undone" db:"undone"`
+	CreatedAt  string `json:"createdAt" db:"created_at"`
+}
+
+// AuditOperation groups the row changes made by one user action, which
+// undo and redo treat as a unit
+type AuditOperation struct {
+	OpID      int64        `json:"opID"`
+	Label     string       `json:"label"`
+	CreatedAt string       `json:"createdAt"`
+	Undone    bool         `json:"undone"`
+	Entries   []AuditEntry `json:"entries"`
+}
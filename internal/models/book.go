@@ -34,6 +34,8 @@ type Book struct {
 	PageNumberPosition  string  `json:"pageNumberPosition" db:"page_number_position"`
 	SuppressPageNumbers string  `json:"suppressPageNumbers" db:"suppress_page_numbers"`
 	BookType            string  `json:"bookType" db:"book_type"`
+	FirstLineIndex      *bool   `json:"firstLineIndex,omitempty" db:"first_line_index"`
+	TOCDepth            int     `json:"tocDepth" db:"toc_depth"`
 	SelectedParts       *string `json:"selectedParts,omitempty" db:"selected_parts"`
 	PaperType           string  `json:"paperType" db:"paper_type"`
 	TrimSize            string  `json:"trimSize" db:"trim_size"`
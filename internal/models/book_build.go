@@ -0,0 +1,45 @@
+package models
+
+// BookBuild records one galley build: the manifest it ran from, the book
+// settings at the time, and a content hash of every input file so the
+// build can be compared with others or reproduced exactly. Input files are
+// kept in the book build object store under their hashes.
+type BookBuild struct {
+	BuildID      int64        `json:"buildID" db:"buildID"`
+	CollID       int64        `json:"collID" db:"collID"`
+	Manifest     string       `json:"manifest" db:"manifest"`
+	Settings     string       `json:"settings" db:"settings"`
+	Inputs       []BuildInput `json:"inputs" db:"inputs"`
+	OutputPath   string       `json:"outputPath" db:"output_path"`
+	OutputSHA256 string       `json:"outputSha256" db:"output_sha256"`
+	PagesSHA256  string       `json:"pagesSha256" db:"pages_sha256"`
+	TotalPages   int          `json:"totalPages" db:"total_pages"`
+	Duration     string       `json:"duration" db:"duration"`
+	RebuiltFrom  *int64       `json:"rebuiltFrom,omitempty" db:"rebuilt_from"`
+	CreatedAt    string       `json:"createdAt" db:"created_at"`
+}
+
+// BuildInput is one file a build read. Key identifies the input across
+// builds (for example "work:12" or "template") so two builds can be
+// compared even when paths move.
+type BuildInput struct {
+	Key    string `json:"key"`
+	Label  string `json:"label"`
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// BookBuildInfo summarizes a build without its manifest and inputs
+type BookBuildInfo struct {
+	BuildID      int64  `json:"buildID"`
+	CollID       int64  `json:"collID"`
+	OutputPath   string `json:"outputPath"`
+	OutputSHA256 string `json:"outputSha256"`
+	PagesSHA256  string `json:"pagesSha256"`
+	TotalPages   int    `json:"totalPages"`
+	InputCount   int    `json:"inputCount"`
+	Duration     string `json:"duration"`
+	RebuiltFrom  *int64 `json:"rebuiltFrom,omitempty"`
+	CreatedAt    string `json:"createdAt"`
+}
@@ -10,6 +10,7 @@ type Collection struct {
 	ModifiedAt     string  `json:"modifiedAt" db:"modified_at"`
 	IsBook         bool    `json:"isBook" db:"is_book"`
 	SmartQuery     *string `json:"smartQuery,omitempty" db:"smart_query"`
+	Entity         string  `json:"entity" db:"entity"` // works, submissions or organizations
 }
 
 // CollectionView extends Collection with computed fields
@@ -38,6 +39,21 @@ type CollectionWork struct {
 	IsSuppressed bool  `json:"isSuppressed" db:"is_suppressed"`
 }
 
+// SmartQueryPreview is what a smart query would select. Query is its
+// canonical form. Only the rows of Entity are filled. A query that does
+// not compile has Error and ErrorPos, the byte offset of the problem, and
+// no rows.
+type SmartQueryPreview struct {
+	Entity        string           `json:"entity"`
+	Query         string           `json:"query"`
+	Count         int              `json:"count"`
+	Works         []CollectionWork `json:"works"`
+	Submissions   []SubmissionView `json:"submissions"`
+	Organizations []Organization   `json:"organizations"`
+	Error         string           `json:"error,omitempty"`
+	ErrorPos      int              `json:"errorPos"`
+}
+
 func (c *Collection) IsDeleted() bool {
 	return IsDeleted(c.Attributes)
 }
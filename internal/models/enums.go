@@ -38,6 +38,7 @@ var ResponseTypeList = []string{
 	"Personal",
 	"Personal Note",
 	"Waiting",
+	"Withdrawn",
 }
 
 var NoteTypeList = []string{
@@ -0,0 +1,37 @@
+package models
+
+// Job status values. A job moves from queued to running and ends in one of
+// succeeded, failed or cancelled.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job is one unit of background work. Params and Result are JSON owned by
+// the handler for Kind; Log holds the lines the job wrote while running.
+type Job struct {
+	JobID           int64  `json:"jobID" db:"jobID"`
+	Kind            string `json:"kind" db:"kind"`
+	Label           string `json:"label" db:"label"`
+	Params          string `json:"params" db:"params"`
+	Status          string `json:"status" db:"status"`
+	ProgressCurrent int    `json:"progressCurrent" db:"progress_current"`
+	ProgressTotal   int    `json:"progressTotal" db:"progress_total"`
+	Message         string `json:"message" db:"message"`
+	Result          string `json:"result,omitempty" db:"result"`
+	Error           string `json:"error,omitempty" db:"error"`
+	Log             string `json:"log,omitempty" db:"log"`
+	Attempts        int    `json:"attempts" db:"attempts"`
+	RetryOf         *int64 `json:"retryOf,omitempty" db:"retry_of"`
+	CreatedAt       string `json:"createdAt" db:"created_at"`
+	StartedAt       string `json:"startedAt,omitempty" db:"started_at"`
+	FinishedAt      string `json:"finishedAt,omitempty" db:"finished_at"`
+}
+
+// Finished reports whether the job has stopped for good
+func (j *Job) Finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCancelled
+}
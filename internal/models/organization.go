@@ -24,6 +24,7 @@ type Organization struct {
 	ContestFee     *string `json:"contestFee,omitempty" db:"contest_fee"`
 	ContestPrize   *string `json:"contestPrize,omitempty" db:"contest_prize"`
 	ContestPrize2  *string `json:"contestPrize2,omitempty" db:"contest_prize_2"`
+	FileNaming     *string `json:"fileNaming,omitempty" db:"file_naming"`
 	Attributes     string  `json:"attributes" db:"attributes"`
 	DateAdded      *string `json:"dateAdded,omitempty" db:"date_added"`
 	ModifiedAt     *string `json:"modifiedAt,omitempty" db:"modified_at"`
@@ -48,3 +49,11 @@ type OrganizationWithNotes struct {
 func (o *Organization) IsDeleted() bool {
 	return IsDeleted(o.Attributes)
 }
+
+// AttrNoSimultaneous marks an organization that will not read work that
+// is under consideration elsewhere
+const AttrNoSimultaneous = "no-simultaneous"
+
+func (o *Organization) AllowsSimultaneous() bool {
+	return !HasAttribute(o.Attributes, AttrNoSimultaneous)
+}
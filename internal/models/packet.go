@@ -0,0 +1,15 @@
+package models
+
+// SubmissionPacket is a single file of several works prepared for one
+// organization. Each work in it is logged as a Submission carrying the
+// packet's ID.
+type SubmissionPacket struct {
+	PacketID   int64   `json:"packetID" db:"packetID"`
+	OrgID      int64   `json:"orgID" db:"orgID"`
+	DocPath    string  `json:"docPath" db:"doc_path"`
+	PDFPath    *string `json:"pdfPath,omitempty" db:"pdf_path"`
+	IsBlind    bool    `json:"isBlind" db:"is_blind"`
+	CreatedAt  string  `json:"createdAt" db:"created_at"`
+	ModifiedAt string  `json:"modifiedAt" db:"modified_at"`
+	OrgName    string  `json:"orgName"`
+}
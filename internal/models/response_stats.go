@@ -0,0 +1,39 @@
+package models
+
+// OrgResponseStats summarizes how an organization has historically responded
+// to submissions. Day figures are computed only from decided submissions that
+// have both a submission and a response date (Timed).
+type OrgResponseStats struct {
+	OrgID        int64   `json:"orgID" db:"orgID"`
+	OrgName      string  `json:"orgName"`
+	Submitted    int     `json:"submitted" db:"submitted"`
+	Pending      int     `json:"pending" db:"pending"`
+	Decided      int     `json:"decided" db:"decided"`
+	Timed        int     `json:"timed" db:"timed"`
+	Accepted     int     `json:"accepted" db:"accepted"`
+	Personal     int     `json:"personal" db:"personal"`
+	NoResponse   int     `json:"noResponse" db:"no_response"`
+	AcceptRate   float64 `json:"acceptRate" db:"accept_rate"`
+	PersonalRate float64 `json:"personalRate" db:"personal_rate"`
+	MinDays      float64 `json:"minDays" db:"min_days"`
+	P25Days      float64 `json:"p25Days" db:"p25_days"`
+	MedianDays   float64 `json:"medianDays" db:"median_days"`
+	P75Days      float64 `json:"p75Days" db:"p75_days"`
+	P90Days      float64 `json:"p90Days" db:"p90_days"`
+	MaxDays      float64 `json:"maxDays" db:"max_days"`
+	ComputedAt   string  `json:"computedAt" db:"computed_at"`
+}
+
+// OverdueSubmission is a pending submission that has waited longer than the
+// organization usually takes to respond
+type OverdueSubmission struct {
+	SubmissionID   int64   `json:"submissionID"`
+	OrgID          int64   `json:"orgID"`
+	WorkTitle      string  `json:"workTitle"`
+	OrgName        string  `json:"orgName"`
+	SubmissionDate string  `json:"submissionDate"`
+	DaysWaiting    int     `json:"daysWaiting"`
+	MedianDays     float64 `json:"medianDays"`
+	P90Days        float64 `json:"p90Days"`
+	Sample         int     `json:"sample"`
+}
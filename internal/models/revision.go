@@ -0,0 +1,18 @@
+package models
+
+// WorkRevision is one recorded version of a work's document. The file
+// itself lives in the revision object store under SHA256; the extracted
+// text is kept alongside so revisions can be compared without re-reading
+// the documents.
+type WorkRevision struct {
+	RevisionID int64   `json:"revisionID" db:"revisionID"`
+	WorkID     int64   `json:"workID" db:"workID"`
+	SHA256     string  `json:"sha256" db:"sha256"`
+	Size       int64   `json:"size" db:"size"`
+	DocType    string  `json:"docType" db:"doc_type"`
+	FileMtime  int64   `json:"fileMtime" db:"file_mtime"`
+	NWords     int     `json:"nWords" db:"n_words"`
+	WordDelta  int     `json:"wordDelta" db:"word_delta"`
+	Note       *string `json:"note,omitempty" db:"note"`
+	CreatedAt  string  `json:"createdAt" db:"created_at"`
+}
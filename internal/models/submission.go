@@ -17,6 +17,7 @@ type Submission struct {
 	UserID         *string  `json:"userID,omitempty" db:"user_id"`
 	Password       *string  `json:"password,omitempty" db:"password"`
 	WebAddress     *string  `json:"webAddress,omitempty" db:"web_address"`
+	PacketID       *int64   `json:"packetID,omitempty" db:"packetID"`
 	Attributes     string   `json:"attributes" db:"attributes"`
 	CreatedAt      string   `json:"createdAt" db:"created_at"`
 	ModifiedAt     string   `json:"modifiedAt" db:"modified_at"`
@@ -37,6 +38,11 @@ func (s *Submission) IsPending() bool {
 	return s.ResponseType == nil || *s.ResponseType == "" || *s.ResponseType == "Waiting"
 }
 
+// IsAccepted returns true if the submission was accepted
+func (s *Submission) IsAccepted() bool {
+	return s.ResponseType != nil && *s.ResponseType == "Accepted"
+}
+
 func (s *Submission) IsDeleted() bool {
 	return IsDeleted(s.Attributes)
 }
@@ -0,0 +1,52 @@
+package models
+
+const (
+	WindowKindReadingPeriod = "Reading Period"
+	WindowKindContest       = "Contest"
+)
+
+var WindowKindList = []string{
+	WindowKindContest,
+	WindowKindReadingPeriod,
+}
+
+// SubmissionWindow is a reading period or contest deadline for an
+// organization. Dates are YYYY-MM-DD; when RecursYearly is set only the
+// month and day are used and a window whose close precedes its open wraps
+// into the following year.
+type SubmissionWindow struct {
+	WindowID     int64    `json:"windowID" db:"windowID"`
+	OrgID        int64    `json:"orgID" db:"orgID"`
+	Kind         string   `json:"kind" db:"kind"`
+	Name         *string  `json:"name,omitempty" db:"name"`
+	Opens        *string  `json:"opens,omitempty" db:"opens"`
+	Closes       string   `json:"closes" db:"closes"`
+	RecursYearly bool     `json:"recursYearly" db:"recurs_yearly"`
+	Fee          *float64 `json:"fee,omitempty" db:"fee"`
+	Prize        *string  `json:"prize,omitempty" db:"prize"`
+	URL          *string  `json:"url,omitempty" db:"url"`
+	CreatedAt    string   `json:"createdAt" db:"created_at"`
+	ModifiedAt   string   `json:"modifiedAt" db:"modified_at"`
+	OrgName      string   `json:"orgName"`
+	OrgURL       string   `json:"orgURL"`
+}
+
+// CalendarEvent is one dated occurrence of a submission window: the day it
+// opens or the day it closes
+type CalendarEvent struct {
+	UID      string   `json:"uid"`
+	WindowID int64    `json:"windowID"`
+	OrgID    int64    `json:"orgID"`
+	OrgName  string   `json:"orgName"`
+	Kind     string   `json:"kind"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Date     string   `json:"date"`
+	Opens    string   `json:"opens"`
+	Closes   string   `json:"closes"`
+	Fee      *float64 `json:"fee,omitempty"`
+	Prize    string   `json:"prize"`
+	URL      string   `json:"url"`
+	IsOpen   bool     `json:"isOpen"`
+	DaysAway int      `json:"daysAway"`
+}
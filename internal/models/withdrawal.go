@@ -0,0 +1,31 @@
+package models
+
+// WithdrawalItem is a pending submission that must be withdrawn because
+// the same material was accepted elsewhere
+type WithdrawalItem struct {
+	SubmissionID   int64  `json:"submissionID"`
+	OrgID          int64  `json:"orgID"`
+	OrgName        string `json:"orgName"`
+	WorkID         int64  `json:"workID"`
+	IsCollection   bool   `json:"isCollection"`
+	Title          string `json:"title"`
+	SubmissionDate string `json:"submissionDate"`
+	WebAddress     string `json:"webAddress"`
+	Reason         string `json:"reason"`
+}
+
+// WithdrawalChecklist lists everything that conflicts with an accepted submission
+type WithdrawalChecklist struct {
+	AcceptedID   int64            `json:"acceptedID"`
+	OrgName      string           `json:"orgName"`
+	WorkID       int64            `json:"workID"`
+	IsCollection bool             `json:"isCollection"`
+	Title        string           `json:"title"`
+	Items        []WithdrawalItem `json:"items"`
+}
+
+// WithdrawalResult reports the outcome of withdrawing conflicting submissions
+type WithdrawalResult struct {
+	Withdrawn []int64  `json:"withdrawn"`
+	Errors    []string `json:"errors"`
+}
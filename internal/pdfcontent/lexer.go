@@ -0,0 +1,266 @@
+// Package pdfcontent splits PDF content streams, and CMaps, which share
+// their syntax, into operands and operators. It is shared by the page text
+// extraction of book builds and the KDP preflight scanner.
+package pdfcontent
+
+import (
+	"encoding/hex"
+	"strconv"
+)
+
+// Kind tells what a Token is
+type Kind int
+
+const (
+	Number Kind = iota
+	Name
+	String
+	Array
+	Other // dictionaries, booleans and null
+	Operator
+)
+
+// Token is one operand or operator
+type Token struct {
+	Kind  Kind
+	Text  string  // a name without its slash, an operator or a keyword
+	Num   float64 // a number's value
+	Str   []byte  // a string's bytes, with escapes and hex decoded
+	Array []Token // an array's elements
+}
+
+// Scan calls fn for each operator in a content stream with the operands
+// that precede it. Inline images are skipped. The operands slice is reused
+// after fn returns.
+func Scan(content []byte, fn func(op string, operands []Token)) {
+	l := &lexer{data: content}
+	var operands []Token
+	for {
+		tok, ok := l.next(false)
+		if !ok {
+			return
+		}
+		if tok.Kind != Operator {
+			operands = append(operands, tok)
+			continue
+		}
+		fn(tok.Text, operands)
+		operands = operands[:0]
+	}
+}
+
+// lexer reads the tokens of a content stream one at a time. Dictionaries
+// are returned whole as a single Other token since no caller needs their
+// contents.
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isDelimiter(c byte) bool {
+	return isSpace(c) || c == '(' || c == ')' || c == '<' || c == '>' || c == '[' || c == ']' ||
+		c == '{' || c == '}' || c == '/' || c == '%'
+}
+
+// next returns the next token, or false at the end of the stream or, inside
+// an array, at its closing bracket
+func (l *lexer) next(inArray bool) (Token, bool) {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		case c == '/':
+			l.pos++
+			return Token{Kind: Name, Text: l.word()}, true
+		case c == '(':
+			return Token{Kind: String, Str: l.literalString()}, true
+		case c == '[':
+			l.pos++
+			return Token{Kind: Array, Array: l.array()}, true
+		case c == '<' && l.hasPrefix("<<"):
+			l.skipDict()
+			return Token{Kind: Other}, true
+		case c == '<':
+			return Token{Kind: String, Str: l.hexString()}, true
+		case c == ']':
+			l.pos++
+			if inArray {
+				return Token{}, false
+			}
+		case c == ')' || c == '>' || c == '{' || c == '}':
+			l.pos++
+		default:
+			w := l.word()
+			if w == "" {
+				l.pos++
+				continue
+			}
+			if n, err := strconv.ParseFloat(w, 64); err == nil {
+				return Token{Kind: Number, Text: w, Num: n}, true
+			}
+			if w == "true" || w == "false" || w == "null" {
+				return Token{Kind: Other, Text: w}, true
+			}
+			if w == "BI" {
+				l.skipInlineImage()
+				continue
+			}
+			return Token{Kind: Operator, Text: w}, true
+		}
+	}
+	return Token{}, false
+}
+
+func (l *lexer) word() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// array reads elements up to and past the closing bracket
+func (l *lexer) array() []Token {
+	elems := []Token{}
+	for {
+		tok, ok := l.next(true)
+		if !ok {
+			return elems
+		}
+		elems = append(elems, tok)
+	}
+}
+
+// literalString reads a parenthesized string, decoding its escapes
+func (l *lexer) literalString() []byte {
+	var out []byte
+	depth := 0
+	for ; l.pos < len(l.data); l.pos++ {
+		c := l.data[l.pos]
+		switch c {
+		case '(':
+			if depth > 0 {
+				out = append(out, c)
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				l.pos++
+				return out
+			}
+			out = append(out, c)
+		case '\\':
+			l.pos++
+			if l.pos >= len(l.data) {
+				return out
+			}
+			switch e := l.data[l.pos]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if l.pos+1 < len(l.data) && l.data[l.pos+1] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := 0
+					j := l.pos
+					for ; j < len(l.data) && j < l.pos+3 && l.data[j] >= '0' && l.data[j] <= '7'; j++ {
+						v = v*8 + int(l.data[j]-'0')
+					}
+					out = append(out, byte(v))
+					l.pos = j - 1
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// hexString reads a string in angle brackets, ignoring white space. An odd
+// final digit is taken as followed by 0.
+func (l *lexer) hexString() []byte {
+	l.pos++
+	var digits []byte
+	for ; l.pos < len(l.data) && l.data[l.pos] != '>'; l.pos++ {
+		if !isSpace(l.data[l.pos]) {
+			digits = append(digits, l.data[l.pos])
+		}
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	n, _ := hex.Decode(out, digits)
+	return out[:n]
+}
+
+func (l *lexer) skipDict() {
+	depth := 0
+	for l.pos < len(l.data) {
+		switch {
+		case l.data[l.pos] == '(':
+			l.literalString()
+			continue
+		case l.hasPrefix("<<"):
+			depth++
+			l.pos += 2
+			continue
+		case l.hasPrefix(">>"):
+			depth--
+			l.pos += 2
+			if depth == 0 {
+				return
+			}
+			continue
+		}
+		l.pos++
+	}
+}
+
+// skipInlineImage skips from BI past the image data to its EI
+func (l *lexer) skipInlineImage() {
+	for l.pos < len(l.data) {
+		if l.hasPrefix("ID") && l.pos > 0 && isSpace(l.data[l.pos-1]) {
+			l.pos += 3
+			break
+		}
+		l.pos++
+	}
+	for l.pos < len(l.data) {
+		if l.hasPrefix("EI") && isSpace(l.data[l.pos-1]) &&
+			(l.pos+2 == len(l.data) || isDelimiter(l.data[l.pos+2])) {
+			l.pos += 2
+			return
+		}
+		l.pos++
+	}
+}
+
+func (l *lexer) hasPrefix(s string) bool {
+	return l.pos+len(s) <= len(l.data) && string(l.data[l.pos:l.pos+len(s)]) == s
+}
@@ -0,0 +1,59 @@
+package pdfcontent
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// describe renders operands compactly for comparison
+func describe(toks []Token) string {
+	var parts []string
+	for _, t := range toks {
+		switch t.Kind {
+		case Number:
+			parts = append(parts, t.Text)
+		case Name:
+			parts = append(parts, "/"+t.Text)
+		case String:
+			parts = append(parts, fmt.Sprintf("%q", t.Str))
+		case Array:
+			parts = append(parts, "["+describe(t.Array)+"]")
+		default:
+			parts = append(parts, "?"+t.Text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"numbers and names", "/F1 12 Tf 1 0 0 1 72 720 cm", []string{"Tf /F1 12", "cm 1 0 0 1 72 720"}},
+		{"literal string escapes", `(a\(b\) \101\n) Tj`, []string{`Tj "a(b) A\n"`}},
+		{"nested parentheses", "(a (b) c) Tj", []string{`Tj "a (b) c"`}},
+		{"hex string with space and odd digit", "<48 6 5> Tj", []string{`Tj "He"`}},
+		{"array with adjustments", "[(A) -300 <42>] TJ", []string{`TJ ["A" -300 "B"]`}},
+		{"nested arrays", "[[1 2] 3] d0", []string{"d0 [[1 2] 3]"}},
+		{"dictionary is one operand", "/Span <</ActualText (x>>) /MCID 0>> BDC EMC", []string{"BDC /Span ?", "EMC "}},
+		{"comment", "q % a comment Q\nQ", []string{"q ", "Q "}},
+		{"inline image is skipped", "BI /W 1 /H 1 ID \x00EI\xff EI Q", []string{"Q "}},
+		{"stray closing bracket", "] q", []string{"q "}},
+		{"keywords", "true false null op", []string{"op ?true ?false ?null"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			Scan([]byte(tt.content), func(op string, operands []Token) {
+				got = append(got, op+" "+describe(operands))
+			})
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Scan(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
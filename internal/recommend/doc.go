@@ -0,0 +1,10 @@
+// Package recommend proposes which works to send to which organizations.
+//
+// Every open organization is paired with every work that is ready to go
+// out. A pair is dropped when it breaks a rule of the trade: the journal
+// does not take that kind of work, the work was sent there before, the
+// journal is still considering something of ours, its reading period is
+// closed, or a simultaneous submission would not be allowed. The pairs
+// that remain are scored from the work's quality, the journal's ranking
+// and our interest in it, and each score carries the reasons behind it.
+package recommend
@@ -0,0 +1,123 @@
+package recommend
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultPacketSize is how many poems most journals read at once
+const DefaultPacketSize = 3
+
+// Packet proposes a group of works to send to one organization together
+type Packet struct {
+	OrgID   int64        `json:"orgID"`
+	OrgName string       `json:"orgName"`
+	Score   int          `json:"score"`
+	Works   []Suggestion `json:"works"`
+	Notes   []string     `json:"notes"`
+}
+
+// Packets groups the suggestions into one packet per organization, best
+// packet first. Each work goes into one packet only, so the packets can
+// all be sent in the same round. A packet holds one genre, that of the
+// organization's best suggestion, and at most opts.PacketSize works; the
+// packet's score is the average of its works' scores.
+func Packets(in Input, opts Options) []Packet {
+	size := opts.PacketSize
+	if size <= 0 {
+		size = DefaultPacketSize
+	}
+	limit := opts.Limit
+	opts.Limit = 0
+
+	byOrg := map[int64][]Suggestion{}
+	for _, s := range suggest(in, opts) {
+		byOrg[s.OrgID] = append(byOrg[s.OrgID], s)
+	}
+
+	// Journals that would get the strongest packet choose first
+	type candidate struct {
+		orgID int64
+		best  int
+	}
+	var order []candidate
+	for orgID, list := range byOrg {
+		order = append(order, candidate{orgID, average(firstOfGenre(list, size, nil))})
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].best != order[j].best {
+			return order[i].best > order[j].best
+		}
+		return byOrg[order[i].orgID][0].OrgName < byOrg[order[j].orgID][0].OrgName
+	})
+
+	orgs := map[int64]int{}
+	for i := range in.Orgs {
+		orgs[in.Orgs[i].OrgID] = i
+	}
+
+	used := map[int64]bool{}
+	var result []Packet
+	for _, c := range order {
+		works := firstOfGenre(byOrg[c.orgID], size, used)
+		if len(works) == 0 {
+			continue
+		}
+		for _, w := range works {
+			used[w.WorkID] = true
+		}
+		p := Packet{
+			OrgID:   c.orgID,
+			OrgName: works[0].OrgName,
+			Score:   average(works),
+			Works:   works,
+		}
+		if len(works) < size {
+			p.Notes = append(p.Notes, fmt.Sprintf("only %d of %d works left to send", len(works), size))
+		}
+		if i, ok := orgs[c.orgID]; ok && !in.Orgs[i].AllowsSimultaneous() {
+			p.Notes = append(p.Notes, "does not accept simultaneous submissions")
+		}
+		result = append(result, p)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Score > result[j].Score })
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// firstOfGenre takes up to n unused suggestions of the same genre as the
+// first unused one
+func firstOfGenre(list []Suggestion, n int, used map[int64]bool) []Suggestion {
+	var result []Suggestion
+	genre := ""
+	for _, s := range list {
+		if used[s.WorkID] {
+			continue
+		}
+		if genre == "" {
+			genre = genres[s.Type][0]
+		}
+		if genres[s.Type][0] != genre {
+			continue
+		}
+		result = append(result, s)
+		if len(result) == n {
+			break
+		}
+	}
+	return result
+}
+
+func average(list []Suggestion) int {
+	if len(list) == 0 {
+		return 0
+	}
+	total := 0
+	for _, s := range list {
+		total += s.Score
+	}
+	return total / len(list)
+}
@@ -0,0 +1,391 @@
+package recommend
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/calendar"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+// DefaultStatuses are the work statuses that mean a piece is ready to send
+var DefaultStatuses = []string{"Out", "Focus", "Active", "Resting", "Sound"}
+
+// DefaultMinQuality is the weakest work worth sending out
+const DefaultMinQuality = "Okay"
+
+// Options narrows and shapes the suggestions. The zero value suggests
+// every eligible pair using the defaults above.
+type Options struct {
+	WorkID     int64    `json:"workID,omitempty"`
+	OrgID      int64    `json:"orgID,omitempty"`
+	MinQuality string   `json:"minQuality,omitempty"`
+	Statuses   []string `json:"statuses,omitempty"`
+	MaxWords   int      `json:"maxWords,omitempty"`
+	PacketSize int      `json:"packetSize,omitempty"`
+	Limit      int      `json:"limit,omitempty"`
+}
+
+// Input is everything the engine looks at. Submissions and windows may
+// cover every work and organization; deleted records should be left out.
+type Input struct {
+	Works       []models.Work
+	Orgs        []models.Organization
+	Submissions []models.Submission
+	Windows     []models.SubmissionWindow
+	Today       time.Time
+}
+
+// Reason is one line of a suggestion's explanation and the points it
+// added to or took from the score
+type Reason struct {
+	Points int    `json:"points"`
+	Text   string `json:"text"`
+}
+
+// Suggestion proposes sending one work to one organization
+type Suggestion struct {
+	WorkID  int64    `json:"workID"`
+	Title   string   `json:"title"`
+	Type    string   `json:"type"`
+	NWords  int      `json:"nWords"`
+	OrgID   int64    `json:"orgID"`
+	OrgName string   `json:"orgName"`
+	Score   int      `json:"score"`
+	Reasons []Reason `json:"reasons"`
+}
+
+var qualityRank = map[string]int{
+	"Best":   4,
+	"Better": 3,
+	"Good":   2,
+	"Okay":   1,
+	"Bad":    0,
+	"Worst":  -1,
+}
+
+var interestPoints = map[string]int{
+	"Best":   15,
+	"High":   15,
+	"Better": 10,
+	"Medium": 10,
+	"Good":   6,
+	"Okay":   3,
+	"Low":    3,
+}
+
+// Organizations we have said we don't care about are never suggested
+var notInterested = []string{"None", "Bad", "Worst"}
+
+// Organizations of these types don't read single pieces
+var bookOnlyTypes = []string{"Publisher", "Agent"}
+
+// genres maps a work type to the words journals use for it in Accepts.
+// The first word is the usual name of the genre.
+var genres = map[string][]string{
+	"Poem":       {"poetry", "poem"},
+	"Prose Poem": {"poetry", "prose poetry", "poem"},
+	"Story":      {"short fiction", "fiction", "stories"},
+	"Flash":      {"flash fiction", "flash", "short fiction", "fiction"},
+	"Micro":      {"flash fiction", "micro", "flash", "short fiction", "fiction"},
+	"Essay":      {"cnf", "nonfiction", "non-fiction", "essay", "essays"},
+	"Travel":     {"cnf", "travel", "nonfiction", "non-fiction"},
+	"Memoir":     {"cnf", "memoir", "nonfiction", "non-fiction"},
+}
+
+// Prose much longer than this is hard to place
+const longProse = 6000
+
+// Reading periods closing within this many days earn a nudge
+const closingSoon = 30
+
+// history is what the submission record says about one work or one
+// organization
+type history struct {
+	sentTo     map[[2]int64]bool // work and org pairs sent and not withdrawn
+	pendingAt  map[int64][]int64 // work to the orgs considering it
+	pendingOrg map[int64]bool    // orgs considering any work of ours
+	accepted   map[int64]bool    // works accepted anywhere
+	acceptedBy map[int64]bool    // orgs that have taken a work of ours
+	submitted  map[int64]bool    // works ever sent out
+}
+
+func newHistory(subs []models.Submission) *history {
+	h := &history{
+		sentTo:     map[[2]int64]bool{},
+		pendingAt:  map[int64][]int64{},
+		pendingOrg: map[int64]bool{},
+		accepted:   map[int64]bool{},
+		acceptedBy: map[int64]bool{},
+		submitted:  map[int64]bool{},
+	}
+	for i := range subs {
+		s := &subs[i]
+		if s.IsDeleted() {
+			continue
+		}
+		response := ""
+		if !s.IsPending() {
+			response = *s.ResponseType
+		}
+		if response == "Withdrawn" {
+			continue
+		}
+		if response == "Accepted" {
+			h.acceptedBy[s.OrgID] = true
+		}
+		if response == "" {
+			h.pendingOrg[s.OrgID] = true
+		}
+		if s.IsCollection {
+			continue
+		}
+		h.submitted[s.WorkID] = true
+		h.sentTo[[2]int64{s.WorkID, s.OrgID}] = true
+		switch response {
+		case "":
+			h.pendingAt[s.WorkID] = append(h.pendingAt[s.WorkID], s.OrgID)
+		case "Accepted":
+			h.accepted[s.WorkID] = true
+		}
+	}
+	return h
+}
+
+// reading is where an organization's reading period stands today
+type reading struct {
+	open     bool
+	closesIn int // days
+}
+
+// readingPeriods works out which organizations are reading today.
+// Organizations without a reading period, or whose windows have all
+// lapsed, are left out and taken to read year round.
+func readingPeriods(windows []models.SubmissionWindow, today time.Time) map[int64]reading {
+	var periods []models.SubmissionWindow
+	for _, w := range windows {
+		if w.Kind == models.WindowKindReadingPeriod {
+			periods = append(periods, w)
+		}
+	}
+
+	result := map[int64]reading{}
+	for _, ev := range calendar.Expand(periods, today, today.AddDate(1, 0, 0), today) {
+		r := result[ev.OrgID]
+		if ev.Type == calendar.EventCloses && ev.IsOpen {
+			if !r.open || ev.DaysAway < r.closesIn {
+				r = reading{open: true, closesIn: ev.DaysAway}
+			}
+		}
+		result[ev.OrgID] = r
+	}
+	return result
+}
+
+// Recommend scores every eligible (work, organization) pair, best first
+func Recommend(in Input, opts Options) []Suggestion {
+	result := suggest(in, opts)
+	if opts.Limit > 0 && len(result) > opts.Limit {
+		result = result[:opts.Limit]
+	}
+	return result
+}
+
+func suggest(in Input, opts Options) []Suggestion {
+	if opts.MinQuality == "" {
+		opts.MinQuality = DefaultMinQuality
+	}
+	if len(opts.Statuses) == 0 {
+		opts.Statuses = DefaultStatuses
+	}
+	minRank, ok := qualityRank[opts.MinQuality]
+	if !ok {
+		minRank = qualityRank[DefaultMinQuality]
+	}
+
+	h := newHistory(in.Submissions)
+	periods := readingPeriods(in.Windows, in.Today)
+
+	var works []*models.Work
+	for i := range in.Works {
+		w := &in.Works[i]
+		if opts.WorkID != 0 && w.WorkID != opts.WorkID {
+			continue
+		}
+		if w.IsDeleted() || h.accepted[w.WorkID] || !slices.Contains(opts.Statuses, w.Status) {
+			continue
+		}
+		if rank, ok := qualityRank[w.Quality]; !ok || rank < minRank {
+			continue
+		}
+		if opts.MaxWords > 0 && w.NWords != nil && *w.NWords > opts.MaxWords {
+			continue
+		}
+		if _, ok := genres[w.Type]; !ok {
+			continue
+		}
+		if pendingExclusive(h, w.WorkID, in.Orgs) {
+			continue
+		}
+		works = append(works, w)
+	}
+
+	var result []Suggestion
+	for i := range in.Orgs {
+		o := &in.Orgs[i]
+		if opts.OrgID != 0 && o.OrgID != opts.OrgID {
+			continue
+		}
+		if o.IsDeleted() || o.Status != "Open" || slices.Contains(bookOnlyTypes, o.Type) || h.pendingOrg[o.OrgID] {
+			continue
+		}
+		if o.MyInterest != nil && slices.Contains(notInterested, *o.MyInterest) {
+			continue
+		}
+		period, hasPeriod := periods[o.OrgID]
+		if hasPeriod && !period.open {
+			continue
+		}
+		for _, w := range works {
+			if h.sentTo[[2]int64{w.WorkID, o.OrgID}] {
+				continue
+			}
+			if !o.AllowsSimultaneous() && len(h.pendingAt[w.WorkID]) > 0 {
+				continue
+			}
+			genre, ok := genreReason(w.Type, o.Accepts)
+			if !ok {
+				continue
+			}
+			s := Suggestion{
+				WorkID:  w.WorkID,
+				Title:   w.Title,
+				Type:    w.Type,
+				OrgID:   o.OrgID,
+				OrgName: o.Name,
+			}
+			if w.NWords != nil {
+				s.NWords = *w.NWords
+			}
+			s.Reasons = append(s.Reasons, qualityReason(w.Quality), rankingReason(o.Ranking))
+			if r, ok := interestReason(o.MyInterest); ok {
+				s.Reasons = append(s.Reasons, r)
+			}
+			s.Reasons = append(s.Reasons, genre)
+			if w.Status == "Focus" {
+				s.Reasons = append(s.Reasons, Reason{5, "a Focus piece"})
+			}
+			if !h.submitted[w.WorkID] {
+				s.Reasons = append(s.Reasons, Reason{5, "never sent out"})
+			}
+			if h.acceptedBy[o.OrgID] {
+				s.Reasons = append(s.Reasons, Reason{6, "has published our work before"})
+			}
+			if n := len(h.pendingAt[w.WorkID]); n > 0 {
+				s.Reasons = append(s.Reasons, Reason{-3 * n, fmt.Sprintf("already under consideration at %s", plural(n, "journal"))})
+			}
+			if isProse(w.Type) && s.NWords > longProse {
+				s.Reasons = append(s.Reasons, Reason{-8, fmt.Sprintf("%d words is long for most journals", s.NWords)})
+			}
+			if hasPeriod && period.closesIn <= closingSoon {
+				s.Reasons = append(s.Reasons, Reason{5, fmt.Sprintf("reading period closes in %s", plural(period.closesIn, "day"))})
+			}
+			for _, r := range s.Reasons {
+				s.Score += r.Points
+			}
+			result = append(result, s)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		if result[i].Title != result[j].Title {
+			return result[i].Title < result[j].Title
+		}
+		return result[i].OrgName < result[j].OrgName
+	})
+	return result
+}
+
+// pendingExclusive reports whether a work is waiting at an organization
+// that does not allow simultaneous submissions
+func pendingExclusive(h *history, workID int64, orgs []models.Organization) bool {
+	for _, orgID := range h.pendingAt[workID] {
+		for i := range orgs {
+			if orgs[i].OrgID == orgID && !orgs[i].AllowsSimultaneous() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func qualityReason(quality string) Reason {
+	return Reason{qualityRank[quality] * 10, fmt.Sprintf("quality %s", quality)}
+}
+
+func rankingReason(ranking *int) Reason {
+	if ranking == nil || *ranking < 1 {
+		return Reason{8, "unranked journal"}
+	}
+	return Reason{max(0, 25-3*(*ranking-1)), fmt.Sprintf("ranked %d", *ranking)}
+}
+
+func interestReason(interest *string) (Reason, bool) {
+	if interest == nil {
+		return Reason{}, false
+	}
+	points, ok := interestPoints[*interest]
+	if !ok {
+		return Reason{}, false
+	}
+	return Reason{points, fmt.Sprintf("our interest is %s", *interest)}, true
+}
+
+// genreReason matches a work type against what an organization accepts.
+// ok is false when the organization plainly takes other kinds of work.
+func genreReason(workType string, accepts *string) (Reason, bool) {
+	var wanted []string
+	if accepts != nil {
+		for _, a := range strings.Split(*accepts, ",") {
+			if a = strings.ToLower(strings.TrimSpace(a)); a != "" {
+				wanted = append(wanted, a)
+			}
+		}
+	}
+	if len(wanted) == 0 {
+		return Reason{2, "accepts is blank"}, true
+	}
+	if slices.Contains(wanted, "all") {
+		return Reason{10, "accepts all genres"}, true
+	}
+
+	names := genres[workType]
+	if slices.Contains(wanted, names[0]) {
+		return Reason{12, fmt.Sprintf("accepts %s", names[0])}, true
+	}
+	for _, a := range wanted {
+		for _, name := range names {
+			if strings.Contains(a, name) {
+				return Reason{8, fmt.Sprintf("accepts %s", a)}, true
+			}
+		}
+	}
+	return Reason{}, false
+}
+
+func isProse(workType string) bool {
+	return genres[workType][0] != "poetry"
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,145 @@
+package recommend
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func strPtr(s string) *string { return &s }
+func intPtr(n int) *int       { return &n }
+
+var today = time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+func testInput() Input {
+	return Input{
+		Works: []models.Work{
+			{WorkID: 1, Title: "Rain", Type: "Poem", Status: "Out", Quality: "Best"},
+			{WorkID: 2, Title: "Snow", Type: "Poem", Status: "Focus", Quality: "Good"},
+			{WorkID: 3, Title: "Hail", Type: "Poem", Status: "Working", Quality: "Best"},
+			{WorkID: 4, Title: "Fog", Type: "Poem", Status: "Out", Quality: "Bad"},
+			{WorkID: 5, Title: "Dust", Type: "Story", Status: "Out", Quality: "Better", NWords: intPtr(9000)},
+			{WorkID: 6, Title: "Mist", Type: "Poem", Status: "Out", Quality: "Better"},
+		},
+		Orgs: []models.Organization{
+			{OrgID: 10, Name: "Alpha", Status: "Open", Type: "Journal", Accepts: strPtr("poetry, short fiction"), Ranking: intPtr(1), MyInterest: strPtr("High")},
+			{OrgID: 11, Name: "Beta", Status: "Open", Type: "Journal", Accepts: strPtr("Poetry"), Ranking: intPtr(5)},
+			{OrgID: 12, Name: "Gamma", Status: "Boring", Type: "Journal", Accepts: strPtr("poetry")},
+			{OrgID: 13, Name: "Delta", Status: "Open", Type: "Journal", Accepts: strPtr("fiction")},
+			{OrgID: 14, Name: "Epsilon", Status: "Open", Type: "Journal", Accepts: strPtr("poetry"), Attributes: models.AttrNoSimultaneous},
+			{OrgID: 15, Name: "Zeta", Status: "Open", Type: "Journal", Accepts: strPtr("poetry")},
+			{OrgID: 16, Name: "Eta", Status: "Open", Type: "Journal", Accepts: strPtr("poetry")},
+		},
+		Submissions: []models.Submission{
+			// Rain was declined by Beta and is waiting at Eta
+			{WorkID: 1, OrgID: 11, SubmissionDate: strPtr("2024-01-01"), ResponseType: strPtr("Form")},
+			{WorkID: 1, OrgID: 16, SubmissionDate: strPtr("2025-02-01")},
+			// Mist was withdrawn from Alpha, so it may go back
+			{WorkID: 6, OrgID: 10, SubmissionDate: strPtr("2024-01-01"), ResponseType: strPtr("Withdrawn")},
+		},
+		Windows: []models.SubmissionWindow{
+			{WindowID: 1, OrgID: 15, Kind: models.WindowKindReadingPeriod, Opens: strPtr("2000-09-01"), Closes: "2000-11-30", RecursYearly: true},
+			{WindowID: 2, OrgID: 10, Kind: models.WindowKindReadingPeriod, Opens: strPtr("2000-01-01"), Closes: "2000-03-31", RecursYearly: true},
+		},
+		Today: today,
+	}
+}
+
+func pairs(list []Suggestion) string {
+	var out []string
+	for _, s := range list {
+		out = append(out, s.Title+">"+s.OrgName)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestRecommendRules(t *testing.T) {
+	got := pairs(Recommend(testInput(), Options{}))
+	// Hail is not ready, Fog is not good enough, Gamma is not open, Zeta
+	// is between reading periods, Eta is still reading Rain, Delta takes
+	// no poems, Beta already said no to Rain, and Epsilon won't read Rain
+	// while Eta has it
+	want := "Rain>Alpha,Mist>Alpha,Snow>Alpha,Dust>Alpha,Mist>Beta,Mist>Epsilon,Snow>Beta,Snow>Epsilon,Dust>Delta"
+	if got != want {
+		t.Errorf("Recommend() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := pairs(Recommend(testInput(), Options{WorkID: 2, Limit: 1})); got != "Snow>Alpha" {
+		t.Errorf("one work, limit 1 = %s", got)
+	}
+	if got := pairs(Recommend(testInput(), Options{OrgID: 11, MinQuality: "Better"})); got != "Mist>Beta" {
+		t.Errorf("Beta, Better or Best = %s", got)
+	}
+	if got := pairs(Recommend(testInput(), Options{OrgID: 13, MaxWords: 5000})); got != "" {
+		t.Errorf("Dust is too long, got %s", got)
+	}
+
+	// A work waiting at a journal that forbids simultaneous submissions
+	// goes nowhere else
+	in := testInput()
+	in.Submissions = append(in.Submissions, models.Submission{WorkID: 2, OrgID: 14, SubmissionDate: strPtr("2025-03-01")})
+	for _, s := range Recommend(in, Options{}) {
+		if s.WorkID == 2 || s.OrgID == 14 {
+			t.Errorf("unexpected %s>%s", s.Title, s.OrgName)
+		}
+	}
+}
+
+func TestRecommendReasons(t *testing.T) {
+	list := Recommend(testInput(), Options{WorkID: 1})
+	if len(list) != 1 {
+		t.Fatalf("want one suggestion, got %s", pairs(list))
+	}
+	s := list[0]
+	var texts []string
+	total := 0
+	for _, r := range s.Reasons {
+		texts = append(texts, r.Text)
+		total += r.Points
+	}
+	want := "quality Best|ranked 1|our interest is High|accepts poetry|already under consideration at 1 journal|reading period closes in 21 days"
+	if strings.Join(texts, "|") != want {
+		t.Errorf("reasons =\n%s\nwant\n%s", strings.Join(texts, "|"), want)
+	}
+	if s.Score != total || s.Score != 40+25+15+12-3+5 {
+		t.Errorf("score = %d, reasons add to %d", s.Score, total)
+	}
+
+	dust := Recommend(testInput(), Options{WorkID: 5, OrgID: 13})
+	if len(dust) != 1 || dust[0].Reasons[len(dust[0].Reasons)-1].Text != "9000 words is long for most journals" {
+		t.Errorf("long prose should be marked down: %+v", dust)
+	}
+}
+
+func TestPackets(t *testing.T) {
+	packets := Packets(testInput(), Options{PacketSize: 2})
+	var got []string
+	for _, p := range packets {
+		got = append(got, p.OrgName+":"+pairs(p.Works)+":"+strings.Join(p.Notes, ";"))
+	}
+	// Alpha picks first and takes the best poems; Dust, a story, can't
+	// join a poetry packet and goes to Delta; Beta gets what is left and
+	// Epsilon nothing
+	want := []string{
+		"Alpha:Rain>Alpha,Mist>Alpha:",
+		"Beta:Snow>Beta:only 1 of 2 works left to send",
+		"Delta:Dust>Delta:only 1 of 2 works left to send",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Packets() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if packets[0].Score != (packets[0].Works[0].Score+packets[0].Works[1].Score)/2 {
+		t.Errorf("packet score %d is not the average", packets[0].Score)
+	}
+	if n := len(Packets(testInput(), Options{PacketSize: 2, Limit: 1})); n != 1 {
+		t.Errorf("limit 1 gave %d packets", n)
+	}
+
+	packets = Packets(testInput(), Options{OrgID: 14})
+	if len(packets) != 1 || pairs(packets[0].Works) != "Mist>Epsilon,Snow>Epsilon" ||
+		strings.Join(packets[0].Notes, ";") != "only 2 of 3 works left to send;does not accept simultaneous submissions" {
+		t.Errorf("Epsilon packet = %+v", packets)
+	}
+}
@@ -0,0 +1,195 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type xmlSectPr struct {
+	PgSz *struct {
+		W string `xml:"w,attr"`
+		H string `xml:"h,attr"`
+	} `xml:"pgSz"`
+	PgMar *struct {
+		Top    string `xml:"top,attr"`
+		Bottom string `xml:"bottom,attr"`
+		Left   string `xml:"left,attr"`
+		Right  string `xml:"right,attr"`
+	} `xml:"pgMar"`
+}
+
+const (
+	itemText = iota
+	itemTab
+	itemLineBreak
+	itemPageBreak
+)
+
+// item is one piece of a paragraph's content in reading order
+type item struct {
+	kind  int
+	text  string
+	style runStyle
+}
+
+type paragraph struct {
+	style paraStyle
+	mark  runStyle // formatting of the paragraph mark, which sizes an empty line
+	items []item
+	// sectionBreak ends a section after the paragraph; the next one starts
+	// on a new page
+	sectionBreak bool
+}
+
+type document struct {
+	paragraphs []paragraph
+	section    *xmlSectPr // the final section's page setup
+}
+
+// parseDocument reads the body of word/document.xml. Tables are read as
+// their paragraphs in order; drawings, deleted text and field codes are
+// skipped.
+func parseDocument(data []byte, styles *styleSheet) (*document, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	doc := &document{}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return doc, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse document.xml: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "p":
+			p, err := parseParagraph(dec, styles)
+			if err != nil {
+				return nil, err
+			}
+			doc.paragraphs = append(doc.paragraphs, p)
+		case "sectPr":
+			var sect xmlSectPr
+			if err := dec.DecodeElement(&sect, &start); err != nil {
+				return nil, fmt.Errorf("parse section: %w", err)
+			}
+			doc.section = &sect
+		case "del", "moveFrom", "drawing", "pict", "object":
+			if err := dec.Skip(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// parseParagraph reads a <w:p> element up to its end tag
+func parseParagraph(dec *xml.Decoder, styles *styleSheet) (paragraph, error) {
+	var pPr *xmlPPr
+	var raw []item
+	var rPrs []*xmlRPr
+
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			return paragraph{}, fmt.Errorf("parse paragraph: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			depth--
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "pPr":
+				pPr = &xmlPPr{}
+				if err := dec.DecodeElement(pPr, &t); err != nil {
+					return paragraph{}, fmt.Errorf("parse paragraph properties: %w", err)
+				}
+			case "r":
+				items, rPr, err := parseRun(dec)
+				if err != nil {
+					return paragraph{}, err
+				}
+				for _, it := range items {
+					raw = append(raw, it)
+					rPrs = append(rPrs, rPr)
+				}
+			case "del", "moveFrom", "drawing", "pict", "object":
+				if err := dec.Skip(); err != nil {
+					return paragraph{}, err
+				}
+			default:
+				// hyperlinks, insertions, content controls and the like
+				// wrap runs that belong to the paragraph
+				depth++
+			}
+		}
+	}
+
+	p := paragraph{style: styles.paragraph(pPr), mark: styles.run(pPr, nil)}
+	p.sectionBreak = pPr != nil && pPr.SectPr != nil
+	for i, it := range raw {
+		it.style = styles.run(pPr, rPrs[i])
+		if it.style.hidden {
+			continue
+		}
+		if it.kind == itemText && (it.style.caps || it.style.smallCaps) {
+			it.text = strings.ToUpper(it.text)
+		}
+		p.items = append(p.items, it)
+	}
+	return p, nil
+}
+
+// parseRun reads a <w:r> element, returning its content and properties
+func parseRun(dec *xml.Decoder) ([]item, *xmlRPr, error) {
+	var rPr *xmlRPr
+	var items []item
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse run: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			return items, rPr, nil
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "rPr":
+				rPr = &xmlRPr{}
+				if err := dec.DecodeElement(rPr, &t); err != nil {
+					return nil, nil, fmt.Errorf("parse run properties: %w", err)
+				}
+				continue
+			case "t":
+				var text string
+				if err := dec.DecodeElement(&text, &t); err != nil {
+					return nil, nil, fmt.Errorf("parse text: %w", err)
+				}
+				items = append(items, item{kind: itemText, text: text})
+				continue
+			case "tab", "ptab":
+				items = append(items, item{kind: itemTab})
+			case "br", "cr":
+				kind := itemLineBreak
+				for _, a := range t.Attr {
+					if a.Name.Local == "type" && a.Value == "page" {
+						kind = itemPageBreak
+					}
+				}
+				items = append(items, item{kind: kind})
+			case "noBreakHyphen":
+				items = append(items, item{kind: itemText, text: "-"})
+			}
+			// Everything else in a run (field codes, drawings, symbols,
+			// rendered page break markers) is not shown
+			if err := dec.Skip(); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+)
+
+// face is a font as drawn: an installed font, plus the bold or italic it
+// has to fake when no such variant is installed
+type face struct {
+	name   string
+	bold   bool
+	italic bool
+}
+
+// fontCache maps a run's family and weight to the face that draws it
+type fontCache map[runStyle]face
+
+// resolve picks an installed font for a run. A family installed in pdfcpu's
+// font directory is used when it has a matching variant; anything else is
+// drawn with the bundled EB Garamond, which is embedded in the PDF.
+func (c fontCache) resolve(r runStyle) face {
+	key := runStyle{font: r.font, bold: r.bold, italic: r.italic}
+	if f, ok := c[key]; ok {
+		return f
+	}
+
+	base := strings.ReplaceAll(r.font, " ", "")
+	var suffixes []string
+	switch {
+	case r.bold && r.italic:
+		suffixes = []string{"-BoldItalic", "-BoldOblique"}
+	case r.bold:
+		suffixes = []string{"-Bold"}
+	case r.italic:
+		suffixes = []string{"-Italic", "-Oblique"}
+	}
+
+	f := face{name: bookbuild.EBGaramondFontName, bold: r.bold, italic: r.italic}
+	for _, suffix := range suffixes {
+		if font.IsUserFont(base + suffix) {
+			f = face{name: base + suffix}
+			break
+		}
+	}
+	if f.name == bookbuild.EBGaramondFontName && base != "" {
+		for _, regular := range []string{base + "-Regular", base} {
+			if font.IsUserFont(regular) {
+				f.name = regular
+				break
+			}
+		}
+	}
+	c[key] = f
+	return f
+}
+
+// textWidth measures text in points. pdfcpu measures whole point sizes, so
+// widths are scaled from a large size to keep half points accurate.
+func textWidth(text string, f face, size float64) float64 {
+	return font.TextWidth(text, f.name, 1000) * size / 1000
+}
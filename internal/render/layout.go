@@ -0,0 +1,451 @@
+package render
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	// lineFactor is single line spacing as a multiple of the font size
+	lineFactor = 1.2
+	// descentFactor places the baseline above the bottom of the line
+	descentFactor = 0.25
+)
+
+// pageSetup is the page size and margins in points
+type pageSetup struct {
+	width, height            float64
+	top, bottom, left, right float64
+	defaultTab               float64
+}
+
+// glyphRun is text drawn at a baseline position
+type glyphRun struct {
+	x, y float64
+	text string
+	face face
+	size float64
+}
+
+type page struct {
+	runs []glyphRun
+}
+
+// piece is part of a word in one style
+type piece struct {
+	text  string
+	face  face
+	size  float64
+	rise  float64
+	width float64
+}
+
+// atom is the unit of line breaking: a word (which may mix styles), a tab
+// or a break. space is the width of the whitespace after a word.
+type atom struct {
+	kind   int
+	pieces []piece
+	width  float64
+	space  float64
+}
+
+type tabTarget struct {
+	pos    float64
+	align  string
+	leader bool
+}
+
+type layouter struct {
+	setup pageSetup
+	fonts fontCache
+	pages []*page
+	cur   *page
+	y     float64 // top of the next line
+	atTop bool
+}
+
+func layout(doc *document, setup pageSetup) []*page {
+	l := &layouter{setup: setup, fonts: fontCache{}}
+	l.newPage()
+	for i, p := range doc.paragraphs {
+		l.paragraph(p)
+		if p.sectionBreak && i < len(doc.paragraphs)-1 {
+			l.newPage()
+		}
+	}
+	return l.pages
+}
+
+func (l *layouter) newPage() {
+	l.cur = &page{}
+	l.pages = append(l.pages, l.cur)
+	l.y = l.setup.height - l.setup.top
+	l.atTop = true
+}
+
+func (l *layouter) contentLeft() float64  { return l.setup.left }
+func (l *layouter) contentRight() float64 { return l.setup.width - l.setup.right }
+
+func (l *layouter) paragraph(p paragraph) {
+	ps := p.style
+	if ps.pageBreakBefore && !l.atTop {
+		l.newPage()
+	}
+	if !l.atTop {
+		l.y -= ps.before
+	}
+
+	atoms := l.splitLong(ps, l.atoms(p))
+	if len(atoms) == 0 {
+		l.advance(l.lineHeight(ps, p.mark.size))
+		l.y -= ps.after
+		return
+	}
+
+	first := true
+	for i := 0; i < len(atoms); {
+		x0 := l.lineStart(ps, first)
+		right := l.contentRight() - ps.right
+		j := i
+		ending := -1 // the break that ends the line, if any
+		for j < len(atoms) {
+			if k := atoms[j].kind; k == itemLineBreak || k == itemPageBreak {
+				ending = k
+				break
+			}
+			if _, end, _ := l.place(ps, atoms[i:j+1], x0); end > right+0.01 && j > i {
+				break
+			}
+			j++
+		}
+
+		line := atoms[i:j]
+		last := j >= len(atoms) || ending >= 0
+		l.emitLine(ps, p.mark.size, line, x0, right, last)
+		if ending >= 0 {
+			j++
+			if ending == itemPageBreak {
+				l.newPage()
+			}
+		}
+		// whitespace at a wrap is absorbed by the line break
+		for ending < 0 && j < len(atoms) && atoms[j].kind == itemText && len(atoms[j].pieces) == 0 {
+			j++
+		}
+		i = j
+		first = false
+	}
+	l.y -= ps.after
+}
+
+// atoms splits a paragraph's content into words, tabs and breaks
+func (l *layouter) atoms(p paragraph) []atom {
+	var out []atom
+	cur := -1 // index of the word being built
+	for _, it := range p.items {
+		if it.kind != itemText {
+			out = append(out, atom{kind: it.kind})
+			cur = -1
+			continue
+		}
+
+		f := l.fonts.resolve(it.style)
+		size, rise := it.style.size, 0.0
+		if it.style.smallCaps && !it.style.caps {
+			size *= 0.8
+		}
+		switch it.style.vertAlign {
+		case "superscript":
+			size, rise = size*0.65, size*0.35
+		case "subscript":
+			size, rise = size*0.65, -size*0.15
+		}
+
+		var pending strings.Builder
+		flush := func() {
+			if pending.Len() == 0 {
+				return
+			}
+			if cur < 0 || out[cur].space > 0 {
+				out = append(out, atom{kind: itemText})
+				cur = len(out) - 1
+			}
+			text := pending.String()
+			w := textWidth(text, f, size)
+			out[cur].pieces = append(out[cur].pieces, piece{text: text, face: f, size: size, rise: rise, width: w})
+			out[cur].width += w
+			pending.Reset()
+		}
+		for _, r := range it.text {
+			if unicode.IsSpace(r) && r != ' ' {
+				flush()
+				if cur < 0 {
+					// leading spaces indent the line like an empty word
+					out = append(out, atom{kind: itemText})
+					cur = len(out) - 1
+				}
+				out[cur].space += textWidth(" ", f, size)
+				continue
+			}
+			pending.WriteRune(r)
+		}
+		flush()
+	}
+	return out
+}
+
+// splitLong breaks words too wide for any line of the paragraph
+func (l *layouter) splitLong(ps paraStyle, atoms []atom) []atom {
+	limit := l.contentRight() - ps.right - l.lineStart(ps, false)
+	if start := l.lineStart(ps, true); start > l.lineStart(ps, false) {
+		limit = l.contentRight() - ps.right - start
+	}
+	if limit <= 0 {
+		return atoms
+	}
+
+	var out []atom
+	for _, a := range atoms {
+		for a.kind == itemText && a.width > limit {
+			head, tail := splitAtom(a, limit)
+			if len(head.pieces) == 0 {
+				break
+			}
+			out = append(out, head)
+			a = tail
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// splitAtom cuts a word so its first part fits in width
+func splitAtom(a atom, width float64) (atom, atom) {
+	head := atom{kind: itemText}
+	tail := atom{kind: itemText, space: a.space}
+	for i, p := range a.pieces {
+		if head.width+p.width <= width {
+			head.pieces = append(head.pieces, p)
+			head.width += p.width
+			continue
+		}
+		runes := []rune(p.text)
+		n := 0
+		for n < len(runes) && head.width+textWidth(string(runes[:n+1]), p.face, p.size) <= width {
+			n++
+		}
+		if n == 0 && len(head.pieces) == 0 {
+			n = 1 // always make progress
+		}
+		if n > 0 {
+			hp := p
+			hp.text = string(runes[:n])
+			hp.width = textWidth(hp.text, p.face, p.size)
+			head.pieces = append(head.pieces, hp)
+			head.width += hp.width
+		}
+		if n < len(runes) {
+			tp := p
+			tp.text = string(runes[n:])
+			tp.width = textWidth(tp.text, p.face, p.size)
+			tail.pieces = append(tail.pieces, tp)
+			tail.width += tp.width
+		}
+		for _, rest := range a.pieces[i+1:] {
+			tail.pieces = append(tail.pieces, rest)
+			tail.width += rest.width
+		}
+		break
+	}
+	return head, tail
+}
+
+func (l *layouter) lineStart(ps paraStyle, first bool) float64 {
+	x := l.contentLeft() + ps.left
+	if first {
+		x += ps.firstLine
+	}
+	return x
+}
+
+// place positions a line's atoms from x, returning where each starts, where
+// the text ends and the spans to fill with tab leaders
+func (l *layouter) place(ps paraStyle, atoms []atom, x float64) ([]float64, float64, [][2]float64) {
+	xs := make([]float64, len(atoms))
+	end := x
+	var leaders [][2]float64
+	for i, a := range atoms {
+		xs[i] = x
+		if a.kind != itemTab {
+			x += a.width
+			end = x
+			x += a.space
+			continue
+		}
+
+		stop := l.nextTab(ps, x)
+		next := stop.pos
+		switch w := segmentWidth(atoms[i+1:]); stop.align {
+		case "right":
+			next -= w
+		case "center":
+			next -= w / 2
+		}
+		if next < x {
+			next = x
+		}
+		if stop.leader {
+			leaders = append(leaders, [2]float64{x, next})
+		}
+		x, end = next, next
+	}
+	return xs, end, leaders
+}
+
+// segmentWidth is the width of the text up to the next tab, which is what
+// a right or centered tab aligns
+func segmentWidth(atoms []atom) float64 {
+	w, space := 0.0, 0.0
+	for _, a := range atoms {
+		if a.kind != itemText {
+			break
+		}
+		w += space + a.width
+		space = a.space
+	}
+	return w
+}
+
+// nextTab finds the first tab stop after x: a custom stop, the implicit
+// stop at a hanging indent, or the next default stop
+func (l *layouter) nextTab(ps paraStyle, x float64) tabTarget {
+	rel := x - l.contentLeft()
+	best := tabTarget{pos: math.Inf(1)}
+	for _, t := range ps.tabs {
+		if t.pos > rel+0.01 && t.pos < best.pos {
+			best = tabTarget{pos: t.pos, align: t.align, leader: t.leader}
+		}
+	}
+	if ps.firstLine < 0 && ps.left > rel+0.01 && ps.left < best.pos {
+		best = tabTarget{pos: ps.left, align: "left"}
+	}
+	if math.IsInf(best.pos, 1) {
+		step := l.setup.defaultTab
+		best = tabTarget{pos: (math.Floor(rel/step+0.001) + 1) * step, align: "left"}
+	}
+
+	best.pos += l.contentLeft()
+	if limit := l.contentRight() - ps.right; best.align != "left" && best.pos > limit {
+		best.pos = limit
+	}
+	return best
+}
+
+func (l *layouter) lineHeight(ps paraStyle, size float64) float64 {
+	natural := size * lineFactor
+	switch ps.lineRule {
+	case "exact":
+		return ps.line
+	case "atLeast":
+		return math.Max(natural, ps.line)
+	}
+	return natural * ps.line
+}
+
+// advance moves down one line, starting a new page if it does not fit
+func (l *layouter) advance(h float64) {
+	if l.y-h < l.setup.bottom-0.01 && !l.atTop {
+		l.newPage()
+	}
+	l.y -= h
+	l.atTop = false
+}
+
+// emitLine draws one line of a paragraph
+func (l *layouter) emitLine(ps paraStyle, markSize float64, line []atom, x0, right float64, last bool) {
+	size := 0.0
+	hasTab := false
+	for _, a := range line {
+		hasTab = hasTab || a.kind == itemTab
+		for _, p := range a.pieces {
+			size = math.Max(size, p.size-math.Abs(p.rise)/2)
+		}
+	}
+	if size == 0 {
+		size = markSize
+	}
+	h := l.lineHeight(ps, size)
+	l.advance(h)
+	baseline := l.y + size*descentFactor
+
+	xs, end, leaders := l.place(ps, line, x0)
+	extra := math.Max(right-end, 0)
+	offset, gap := 0.0, 0.0
+	if !hasTab {
+		switch ps.align {
+		case "center":
+			offset = extra / 2
+		case "right":
+			offset = extra
+		case "both":
+			if gaps := countGaps(line); !last && gaps > 0 {
+				gap = extra / float64(gaps)
+			}
+		}
+	}
+
+	shift := offset
+	for i, a := range line {
+		x := xs[i] + shift
+		for _, p := range a.pieces {
+			l.cur.runs = append(l.cur.runs, glyphRun{x: x, y: baseline + p.rise, text: p.text, face: p.face, size: p.size})
+			x += p.width
+		}
+		if a.space > 0 && a.kind == itemText && len(a.pieces) > 0 {
+			shift += gap
+		}
+	}
+
+	for _, span := range leaders {
+		l.leader(span[0], span[1], baseline, line)
+	}
+}
+
+// countGaps counts the spaces a justified line can stretch
+func countGaps(line []atom) int {
+	gaps := 0
+	for _, a := range line[:max(len(line)-1, 0)] {
+		if a.kind == itemText && len(a.pieces) > 0 && a.space > 0 {
+			gaps++
+		}
+	}
+	return gaps
+}
+
+// leader fills a tab with dots, set on the same spacing in every line so
+// they line up down the page
+func (l *layouter) leader(from, to, baseline float64, line []atom) {
+	f, size := face{}, 0.0
+	for _, a := range line {
+		if len(a.pieces) > 0 {
+			f, size = a.pieces[0].face, a.pieces[0].size
+			break
+		}
+	}
+	if size == 0 {
+		return
+	}
+
+	pad := size / 4
+	step := textWidth(". ", f, size)
+	if step <= 0 {
+		return
+	}
+	first := math.Ceil((from+pad-l.contentLeft())/step)*step + l.contentLeft()
+	n := int((to - pad - first) / step)
+	if n <= 0 {
+		return
+	}
+	l.cur.runs = append(l.cur.runs, glyphRun{x: first, y: baseline, text: strings.TrimSpace(strings.Repeat(". ", n)), face: f, size: size})
+}
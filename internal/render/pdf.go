@@ -0,0 +1,79 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/create"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+const (
+	// italicSkew slants an upright font to stand in for a missing italic
+	italicSkew = 0.2
+	// boldStroke is the outline width, as a fraction of the font size, that
+	// thickens a regular font to stand in for a missing bold
+	boldStroke = 0.03
+)
+
+// writePDF draws laid-out pages with pdfcpu. Every font is a user font, so
+// pdfcpu embeds a subset of the glyphs used.
+func writePDF(pages []*page, setup pageSetup, w io.Writer) error {
+	conf := model.NewDefaultConfiguration()
+	ctx, err := pdfcpu.CreateContextWithXRefTable(conf, &types.Dim{Width: setup.width, Height: setup.height})
+	if err != nil {
+		return fmt.Errorf("create pdf: %w", err)
+	}
+
+	// Content is encoded for every page before any font is written, so the
+	// embedded subsets cover glyphs from all of them
+	box := types.RectForDim(setup.width, setup.height)
+	fonts := model.FontMap{}
+	out := make([]*model.Page, 0, len(pages))
+	for _, pg := range pages {
+		p := model.NewPage(box, box)
+		for _, run := range pg.runs {
+			drawRun(ctx.XRefTable, &p, fonts, run)
+		}
+		out = append(out, &p)
+	}
+
+	if _, _, err := create.UpdatePageTree(ctx, out, fonts); err != nil {
+		return fmt.Errorf("build pages: %w", err)
+	}
+	if err := api.WriteContext(ctx, w); err != nil {
+		return fmt.Errorf("write pdf: %w", err)
+	}
+	return nil
+}
+
+// drawRun writes one run of text into a page's content stream
+func drawRun(xRefTable *model.XRefTable, p *model.Page, fonts model.FontMap, run glyphRun) {
+	id := p.Fm.EnsureKey(run.face.name)
+	fonts.EnsureKey(run.face.name)
+	text := model.PrepBytes(xRefTable, run.text, run.face.name, true, false, false)
+
+	skew := 0.0
+	if run.face.italic {
+		skew = italicSkew
+	}
+	// The render mode and line width persist past ET, so a fake-bold run
+	// saves and restores the graphics state and a regular run sets fill
+	// mode explicitly
+	var b strings.Builder
+	if run.face.bold {
+		fmt.Fprintf(&b, "q BT 2 Tr %.3f w ", run.size*boldStroke)
+	} else {
+		b.WriteString("BT 0 Tr ")
+	}
+	fmt.Fprintf(&b, "/%s %.2f Tf 1 0 %.2f 1 %.2f %.2f Tm (%s) Tj ET", id, run.size, skew, run.x, run.y, text)
+	if run.face.bold {
+		b.WriteString(" Q")
+	}
+	b.WriteString("\n")
+	p.Buf.WriteString(b.String())
+}
@@ -0,0 +1,137 @@
+// Package render lays out simple DOCX manuscripts and writes them as PDF
+// without an office suite. It understands paragraph and character styles,
+// indents, spacing, tabs and breaks, which covers poems and essays set in
+// the template's styles; tables are read as plain paragraphs and drawings
+// are left out.
+package render
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+)
+
+const (
+	documentXMLPath = "word/document.xml"
+	stylesXMLPath   = "word/styles.xml"
+
+	// US Letter with one inch margins, for documents without a page setup
+	letterWidth   = 612.0
+	letterHeight  = 792.0
+	defaultMargin = 72.0
+)
+
+// Options control how a document is rendered
+type Options struct {
+	// TemplatePath, when set, supplies the page size (a book's trim size)
+	// in place of the document's own
+	TemplatePath string
+}
+
+// RenderFile renders the DOCX at src to a PDF at dst
+func RenderFile(src, dst string, opts Options) error {
+	f, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", dst, err)
+	}
+	if err := Render(src, f, opts); err != nil {
+		f.Close()
+		os.Remove(dst)
+		return err
+	}
+	return f.Close()
+}
+
+// Render renders the DOCX at src as a PDF written to w
+func Render(src string, w io.Writer, opts Options) error {
+	if err := bookbuild.EnsureFontsInstalled(); err != nil {
+		return fmt.Errorf("install fonts: %w", err)
+	}
+
+	docXML, stylesXML, err := readParts(src)
+	if err != nil {
+		return err
+	}
+	styles, err := parseStyles(stylesXML)
+	if err != nil {
+		return err
+	}
+	doc, err := parseDocument(docXML, styles)
+	if err != nil {
+		return err
+	}
+
+	sizeFrom := src
+	if opts.TemplatePath != "" {
+		sizeFrom = opts.TemplatePath
+	}
+	setup := pageSetupFor(doc, sizeFrom)
+	return writePDF(layout(doc, setup), setup, w)
+}
+
+// readParts returns the document and style sheet XML of a DOCX. A missing
+// style sheet is not an error; the document is drawn with Word's defaults.
+func readParts(path string) ([]byte, []byte, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open docx: %w", err)
+	}
+	defer reader.Close()
+
+	var docXML, stylesXML []byte
+	for _, file := range reader.File {
+		if file.Name != documentXMLPath && file.Name != stylesXMLPath {
+			continue
+		}
+		data, err := readZipFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read %s: %w", file.Name, err)
+		}
+		if file.Name == documentXMLPath {
+			docXML = data
+		} else {
+			stylesXML = data
+		}
+	}
+	if docXML == nil {
+		return nil, nil, fmt.Errorf("document.xml not found in %s", path)
+	}
+	return docXML, stylesXML, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// pageSetupFor takes the page size from sizeFrom and the margins from the
+// document's last section
+func pageSetupFor(doc *document, sizeFrom string) pageSetup {
+	setup := pageSetup{
+		width: letterWidth, height: letterHeight,
+		top: defaultMargin, bottom: defaultMargin, left: defaultMargin, right: defaultMargin,
+		defaultTab: defaultTab,
+	}
+	if w, h, err := bookbuild.ExtractTemplatePageSize(sizeFrom); err == nil && w > 0 && h > 0 {
+		setup.width, setup.height = w, h
+	}
+	if doc.section != nil && doc.section.PgMar != nil {
+		m := doc.section.PgMar
+		setPoints(&setup.top, m.Top)
+		setPoints(&setup.bottom, m.Bottom)
+		setPoints(&setup.left, m.Left)
+		setPoints(&setup.right, m.Right)
+	}
+	// Word allows negative top and bottom margins that text may not overlap;
+	// the magnitude is what matters here
+	setup.top, setup.bottom = math.Abs(setup.top), math.Abs(setup.bottom)
+	return setup
+}
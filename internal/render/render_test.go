@@ -0,0 +1,208 @@
+package render
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/bookbuild"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+)
+
+const testStyles = `<?xml version="1.0" encoding="UTF-8"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:docDefaults>
+    <w:rPrDefault><w:rPr><w:sz w:val="24"/></w:rPr></w:rPrDefault>
+    <w:pPrDefault><w:pPr><w:spacing w:after="160" w:line="259" w:lineRule="auto"/></w:pPr></w:pPrDefault>
+  </w:docDefaults>
+  <w:style w:type="paragraph" w:default="1" w:styleId="Normal"/>
+  <w:style w:type="paragraph" w:styleId="Title">
+    <w:basedOn w:val="Normal"/>
+    <w:pPr><w:jc w:val="center"/></w:pPr>
+    <w:rPr><w:b/><w:sz w:val="36"/></w:rPr>
+  </w:style>
+  <w:style w:type="paragraph" w:styleId="Verse">
+    <w:basedOn w:val="Normal"/>
+    <w:pPr><w:spacing w:after="0"/><w:ind w:left="720" w:hanging="360"/></w:pPr>
+  </w:style>
+</w:styles>`
+
+func paragraphXML(style, text string) string {
+	return `<w:p><w:pPr><w:pStyle w:val="` + style + `"/></w:pPr><w:r><w:t xml:space="preserve">` + text + `</w:t></w:r></w:p>`
+}
+
+// writeDocx builds a minimal DOCX with the given body paragraphs
+func writeDocx(t *testing.T, path, body string, width, height int) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` + body +
+		`<w:sectPr><w:pgSz w:w="` + strconv.Itoa(width) + `" w:h="` + strconv.Itoa(height) + `"/>` +
+		`<w:pgMar w:top="1440" w:bottom="1440" w:left="1080" w:right="1080"/></w:sectPr></w:body></w:document>`
+	for name, data := range map[string]string{documentXMLPath: doc, stylesXMLPath: testStyles} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "render-fonts")
+	if err != nil {
+		panic(err)
+	}
+	// Keep pdfcpu from pointing the font directory back at the user's
+	// config directory
+	api.DisableConfigDir()
+	font.UserFontDir = dir
+	if err := bookbuild.EnsureFontsInstalled(); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestParseStylesInheritance(t *testing.T) {
+	sheet, err := parseStyles([]byte(testStyles))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verse := sheet.paragraph(&xmlPPr{Style: &xmlVal{Val: "Verse"}})
+	if verse.left != 36 || verse.firstLine != -18 || verse.after != 0 {
+		t.Errorf("unexpected Verse indents: %+v", verse)
+	}
+	if verse.line < 1.07 || verse.line > 1.08 {
+		t.Errorf("expected line spacing from the defaults, got %v", verse.line)
+	}
+	title := sheet.run(&xmlPPr{Style: &xmlVal{Val: "Title"}}, nil)
+	if !title.bold || title.size != 18 {
+		t.Errorf("unexpected Title run style: %+v", title)
+	}
+	normal := sheet.run(nil, &xmlRPr{Italic: &xmlOnOff{}})
+	if normal.bold || !normal.italic || normal.size != 12 {
+		t.Errorf("unexpected direct run style: %+v", normal)
+	}
+}
+
+func TestLayoutWrapsAndBreaksPages(t *testing.T) {
+	sheet, _ := parseStyles([]byte(testStyles))
+	line := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 8)
+	var body strings.Builder
+	for i := 0; i < 40; i++ {
+		body.WriteString(paragraphXML("Normal", line))
+	}
+	doc, err := parseDocument([]byte(`<w:document xmlns:w="w"><w:body>`+body.String()+`</w:body></w:document>`), sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.paragraphs) != 40 {
+		t.Fatalf("expected 40 paragraphs, got %d", len(doc.paragraphs))
+	}
+
+	setup := pageSetup{width: 612, height: 792, top: 72, bottom: 72, left: 72, right: 72, defaultTab: defaultTab}
+	pages := layout(doc, setup)
+	if len(pages) < 2 {
+		t.Fatalf("expected the text to run over several pages, got %d", len(pages))
+	}
+	for i, pg := range pages {
+		for _, r := range pg.runs {
+			if r.x < setup.left-0.01 || r.x+textWidth(r.text, r.face, r.size) > setup.width-setup.right+0.5 {
+				t.Fatalf("page %d: run %q outside the margins at x=%.1f", i+1, r.text, r.x)
+			}
+			if r.y < setup.bottom-r.size || r.y > setup.height-setup.top {
+				t.Fatalf("page %d: run %q outside the margins at y=%.1f", i+1, r.text, r.y)
+			}
+		}
+	}
+}
+
+func TestRenderFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "poem.docx")
+	body := paragraphXML("Title", "A Poem") +
+		paragraphXML("Verse", "First line of the poem") +
+		paragraphXML("Verse", "Second line, a little longer than the first") +
+		`<w:p><w:r><w:br w:type="page"/></w:r></w:p>` +
+		paragraphXML("Normal", "After the break")
+	writeDocx(t, src, body, 8640, 12960) // 6 x 9 inches
+
+	dst := filepath.Join(dir, "poem.pdf")
+	if err := RenderFile(src, dst, Options{}); err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	ctx, err := api.ReadContextFile(dst)
+	if err != nil {
+		t.Fatalf("read rendered pdf: %v", err)
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		t.Fatalf("rendered pdf is invalid: %v", err)
+	}
+	if ctx.PageCount != 2 {
+		t.Errorf("expected 2 pages, got %d", ctx.PageCount)
+	}
+	dims, err := ctx.PageDims()
+	if err != nil || len(dims) == 0 || dims[0].Width != 432 || dims[0].Height != 648 {
+		t.Errorf("expected a 6x9 inch page, got %v, %v", dims, err)
+	}
+}
+
+func TestFakeBoldDoesNotLeak(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "poem.docx")
+	body := paragraphXML("Title", "A Poem") + paragraphXML("Normal", "Plain text after the title")
+	writeDocx(t, src, body, 8640, 12960)
+
+	dst := filepath.Join(dir, "poem.pdf")
+	if err := RenderFile(src, dst, Options{}); err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	ctx, err := api.ReadContextFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := ctx.PageContent(d, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The bundled font has no bold, so the title is stroked; the text after
+	// it must go back to plain fill
+	var bold, regular int
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.Contains(line, " Tj") {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "q BT 2 Tr ") && strings.HasSuffix(line, " ET Q"):
+			bold++
+		case strings.HasPrefix(line, "BT 0 Tr "):
+			regular++
+		default:
+			t.Errorf("run neither restores its state nor sets the render mode: %q", line)
+		}
+	}
+	if bold == 0 || regular == 0 {
+		t.Errorf("expected bold and regular runs, got %d bold and %d regular", bold, regular)
+	}
+}
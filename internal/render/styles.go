@@ -0,0 +1,343 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Word stores lengths in twentieths of a point and font sizes in half points
+const (
+	twipsPerPoint = 20.0
+	defaultTab    = 36.0
+)
+
+type xmlVal struct {
+	Val string `xml:"val,attr"`
+}
+
+// xmlOnOff is a toggle property such as <w:b/>, which is on unless its
+// value says otherwise
+type xmlOnOff struct {
+	Val string `xml:"val,attr"`
+}
+
+func (o *xmlOnOff) on() bool {
+	switch strings.ToLower(o.Val) {
+	case "0", "false", "off", "none":
+		return false
+	}
+	return true
+}
+
+type xmlFonts struct {
+	ASCII string `xml:"ascii,attr"`
+	HAnsi string `xml:"hAnsi,attr"`
+}
+
+type xmlRPr struct {
+	Style     *xmlVal   `xml:"rStyle"`
+	Fonts     *xmlFonts `xml:"rFonts"`
+	Size      *xmlVal   `xml:"sz"`
+	Bold      *xmlOnOff `xml:"b"`
+	Italic    *xmlOnOff `xml:"i"`
+	Caps      *xmlOnOff `xml:"caps"`
+	SmallCaps *xmlOnOff `xml:"smallCaps"`
+	Vanish    *xmlOnOff `xml:"vanish"`
+	VertAlign *xmlVal   `xml:"vertAlign"`
+}
+
+type xmlSpacing struct {
+	Before   string `xml:"before,attr"`
+	After    string `xml:"after,attr"`
+	Line     string `xml:"line,attr"`
+	LineRule string `xml:"lineRule,attr"`
+}
+
+type xmlInd struct {
+	Left      string `xml:"left,attr"`
+	Start     string `xml:"start,attr"`
+	Right     string `xml:"right,attr"`
+	End       string `xml:"end,attr"`
+	FirstLine string `xml:"firstLine,attr"`
+	Hanging   string `xml:"hanging,attr"`
+}
+
+type xmlTab struct {
+	Val    string `xml:"val,attr"`
+	Pos    string `xml:"pos,attr"`
+	Leader string `xml:"leader,attr"`
+}
+
+type xmlPPr struct {
+	Style           *xmlVal     `xml:"pStyle"`
+	Jc              *xmlVal     `xml:"jc"`
+	Spacing         *xmlSpacing `xml:"spacing"`
+	Ind             *xmlInd     `xml:"ind"`
+	PageBreakBefore *xmlOnOff   `xml:"pageBreakBefore"`
+	Tabs            []xmlTab    `xml:"tabs>tab"`
+	SectPr          *xmlSectPr  `xml:"sectPr"`
+}
+
+type xmlStyle struct {
+	Type    string  `xml:"type,attr"`
+	ID      string  `xml:"styleId,attr"`
+	Default string  `xml:"default,attr"`
+	BasedOn *xmlVal `xml:"basedOn"`
+	PPr     *xmlPPr `xml:"pPr"`
+	RPr     *xmlRPr `xml:"rPr"`
+}
+
+type xmlStyles struct {
+	RunDefaults  *xmlRPr    `xml:"docDefaults>rPrDefault>rPr"`
+	ParaDefaults *xmlPPr    `xml:"docDefaults>pPrDefault>pPr"`
+	Styles       []xmlStyle `xml:"style"`
+}
+
+// tabStop is a custom tab stop, positioned from the left margin
+type tabStop struct {
+	pos    float64
+	align  string // left, center, right
+	leader bool
+}
+
+// paraStyle is a paragraph's resolved formatting, lengths in points
+type paraStyle struct {
+	align           string // left, center, right, both
+	before, after   float64
+	line            float64 // auto: multiple of single spacing; otherwise points
+	lineRule        string  // auto, exact, atLeast
+	left, right     float64
+	firstLine       float64 // negative for a hanging indent
+	pageBreakBefore bool
+	tabs            []tabStop
+}
+
+// runStyle is a run's resolved formatting
+type runStyle struct {
+	font      string
+	size      float64
+	bold      bool
+	italic    bool
+	caps      bool
+	smallCaps bool
+	hidden    bool
+	vertAlign string
+}
+
+// styleSheet resolves style IDs from word/styles.xml against the document
+// defaults
+type styleSheet struct {
+	styles      map[string]*xmlStyle
+	defaultPara string
+	paraBase    paraStyle
+	runBase     runStyle
+}
+
+func parseStyles(data []byte) (*styleSheet, error) {
+	sheet := &styleSheet{
+		styles:   map[string]*xmlStyle{},
+		paraBase: paraStyle{align: "left", line: 1, lineRule: "auto"},
+		runBase:  runStyle{font: "Times New Roman", size: 10},
+	}
+	if data == nil {
+		return sheet, nil
+	}
+
+	var doc xmlStyles
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse styles.xml: %w", err)
+	}
+	sheet.paraBase.apply(doc.ParaDefaults)
+	sheet.runBase.apply(doc.RunDefaults)
+	for i := range doc.Styles {
+		s := &doc.Styles[i]
+		sheet.styles[s.ID] = s
+		if s.Type == "paragraph" && (s.Default == "1" || s.Default == "true") {
+			sheet.defaultPara = s.ID
+		}
+	}
+	return sheet, nil
+}
+
+// chain returns a style and the styles it is based on, base first
+func (s *styleSheet) chain(id string) []*xmlStyle {
+	var chain []*xmlStyle
+	seen := map[string]bool{}
+	for id != "" && !seen[id] {
+		seen[id] = true
+		style, ok := s.styles[id]
+		if !ok {
+			break
+		}
+		chain = append([]*xmlStyle{style}, chain...)
+		id = ""
+		if style.BasedOn != nil {
+			id = style.BasedOn.Val
+		}
+	}
+	return chain
+}
+
+func (s *styleSheet) paraStyleID(pPr *xmlPPr) string {
+	if pPr != nil && pPr.Style != nil {
+		if _, ok := s.styles[pPr.Style.Val]; ok {
+			return pPr.Style.Val
+		}
+	}
+	return s.defaultPara
+}
+
+// paragraph resolves a paragraph's formatting from its style and direct
+// properties
+func (s *styleSheet) paragraph(pPr *xmlPPr) paraStyle {
+	p := s.paraBase
+	p.tabs = append([]tabStop(nil), p.tabs...)
+	for _, style := range s.chain(s.paraStyleID(pPr)) {
+		p.apply(style.PPr)
+	}
+	p.apply(pPr)
+	return p
+}
+
+// run resolves a run's formatting from its paragraph's style, its character
+// style and direct properties
+func (s *styleSheet) run(pPr *xmlPPr, rPr *xmlRPr) runStyle {
+	r := s.runBase
+	for _, style := range s.chain(s.paraStyleID(pPr)) {
+		r.apply(style.RPr)
+	}
+	if rPr != nil && rPr.Style != nil {
+		for _, style := range s.chain(rPr.Style.Val) {
+			r.apply(style.RPr)
+		}
+	}
+	r.apply(rPr)
+	return r
+}
+
+func (p *paraStyle) apply(x *xmlPPr) {
+	if x == nil {
+		return
+	}
+	if x.Jc != nil {
+		switch x.Jc.Val {
+		case "center":
+			p.align = "center"
+		case "right", "end":
+			p.align = "right"
+		case "both", "distribute":
+			p.align = "both"
+		default:
+			p.align = "left"
+		}
+	}
+	if sp := x.Spacing; sp != nil {
+		setPoints(&p.before, sp.Before)
+		setPoints(&p.after, sp.After)
+		if sp.LineRule != "" {
+			p.lineRule = sp.LineRule
+		}
+		if v, err := strconv.ParseFloat(sp.Line, 64); err == nil {
+			if p.lineRule == "auto" {
+				p.line = v / 240
+			} else {
+				p.line = v / twipsPerPoint
+			}
+		}
+	}
+	if ind := x.Ind; ind != nil {
+		setPoints(&p.left, ind.Start)
+		setPoints(&p.left, ind.Left)
+		setPoints(&p.right, ind.End)
+		setPoints(&p.right, ind.Right)
+		setPoints(&p.firstLine, ind.FirstLine)
+		var hanging float64
+		if setPoints(&hanging, ind.Hanging) {
+			p.firstLine = -hanging
+		}
+	}
+	if x.PageBreakBefore != nil {
+		p.pageBreakBefore = x.PageBreakBefore.on()
+	}
+	for _, t := range x.Tabs {
+		var pos float64
+		if !setPoints(&pos, t.Pos) {
+			continue
+		}
+		p.tabs = removeTab(p.tabs, pos)
+		if t.Val == "clear" {
+			continue
+		}
+		align := "left"
+		switch t.Val {
+		case "center", "right":
+			align = t.Val
+		case "end":
+			align = "right"
+		}
+		p.tabs = append(p.tabs, tabStop{pos: pos, align: align, leader: t.Leader == "dot"})
+	}
+	sort.Slice(p.tabs, func(i, j int) bool { return p.tabs[i].pos < p.tabs[j].pos })
+}
+
+func removeTab(tabs []tabStop, pos float64) []tabStop {
+	kept := tabs[:0]
+	for _, t := range tabs {
+		if t.pos != pos {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+func (r *runStyle) apply(x *xmlRPr) {
+	if x == nil {
+		return
+	}
+	if x.Fonts != nil {
+		if x.Fonts.ASCII != "" {
+			r.font = x.Fonts.ASCII
+		} else if x.Fonts.HAnsi != "" {
+			r.font = x.Fonts.HAnsi
+		}
+	}
+	if x.Size != nil {
+		if v, err := strconv.ParseFloat(x.Size.Val, 64); err == nil && v > 0 {
+			r.size = v / 2
+		}
+	}
+	if x.Bold != nil {
+		r.bold = x.Bold.on()
+	}
+	if x.Italic != nil {
+		r.italic = x.Italic.on()
+	}
+	if x.Caps != nil {
+		r.caps = x.Caps.on()
+	}
+	if x.SmallCaps != nil {
+		r.smallCaps = x.SmallCaps.on()
+	}
+	if x.Vanish != nil {
+		r.hidden = x.Vanish.on()
+	}
+	if x.VertAlign != nil {
+		r.vertAlign = x.VertAlign.Val
+	}
+}
+
+// setPoints parses a twips value into dst, reporting whether it was set
+func setPoints(dst *float64, twips string) bool {
+	if twips == "" {
+		return false
+	}
+	v, err := strconv.ParseFloat(twips, 64)
+	if err != nil {
+		return false
+	}
+	*dst = v / twipsPerPoint
+	return true
+}
@@ -0,0 +1,153 @@
+// Package revisions keeps a per-work history of document revisions. Each
+// time a work's file changes its bytes are stored by hash and its text is
+// extracted, so revisions can be listed with word-count deltas, compared
+// and restored.
+package revisions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/backup"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/db"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/fts"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/textdiff"
+)
+
+// Source is a work's document to check for changes
+type Source struct {
+	WorkID  int64
+	Path    string // absolute path on disk
+	DocType string
+}
+
+type Store struct {
+	db      *db.DB
+	objects *backup.ObjectStore
+	mu      sync.Mutex
+}
+
+// DefaultDir is where revision files are kept, outside the database so a
+// database restore does not lose them
+func DefaultDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".works", "revisions")
+}
+
+func NewStore(database *db.DB, dir string) *Store {
+	return &Store{
+		db:      database,
+		objects: backup.NewObjectStore(filepath.Join(dir, "objects")),
+	}
+}
+
+// Capture records the file at path as a new revision of the work. Text is
+// extracted for docx, md and txt documents; other types are stored without
+// text. When the file is identical to the latest revision nothing is
+// recorded and nil is returned.
+func (s *Store) Capture(workID int64, path, docType, note string) (*models.WorkRevision, error) {
+	// The watcher can report the same save more than once in quick succession
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sum, _, err := s.objects.Put(path)
+	if err != nil {
+		return nil, fmt.Errorf("store revision: %w", err)
+	}
+
+	text, err := fts.ExtractByType(path, docType)
+	if err != nil {
+		text = ""
+	}
+
+	r := &models.WorkRevision{
+		WorkID:    workID,
+		SHA256:    sum,
+		Size:      stat.Size(),
+		DocType:   docType,
+		FileMtime: stat.ModTime().Unix(),
+		NWords:    fts.CountWords(text),
+	}
+	if note != "" {
+		r.Note = &note
+	}
+	return s.db.AddWorkRevision(r, text)
+}
+
+// Sync captures every source whose file differs in size or modification
+// time from its latest revision, picking up edits made while nothing was
+// watching. Works with no revision get their first one.
+func (s *Store) Sync(sources []Source) (int, error) {
+	latest, err := s.db.LatestWorkRevisions()
+	if err != nil {
+		return 0, err
+	}
+
+	captured := 0
+	for _, src := range sources {
+		stat, err := os.Stat(src.Path)
+		if err != nil || !stat.Mode().IsRegular() {
+			continue
+		}
+		if prev, ok := latest[src.WorkID]; ok && prev.Size == stat.Size() && prev.FileMtime == stat.ModTime().Unix() {
+			continue
+		}
+		r, err := s.Capture(src.WorkID, src.Path, src.DocType, "")
+		if err != nil {
+			continue
+		}
+		if r != nil {
+			captured++
+		}
+	}
+	return captured, nil
+}
+
+func (s *Store) List(workID int64) ([]models.WorkRevision, error) {
+	return s.db.ListWorkRevisions(workID)
+}
+
+// Diff compares the text of two revisions of the same work
+func (s *Store) Diff(fromID, toID int64, opts textdiff.Options) (*textdiff.Result, error) {
+	from, err := s.db.GetWorkRevision(fromID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Compare(from.WorkID,
+		Version{Kind: VersionRevision, RevisionID: fromID},
+		Version{Kind: VersionRevision, RevisionID: toID},
+		Locations{}, opts)
+}
+
+// Restore writes a revision's file to dest
+func (s *Store) Restore(revisionID int64, dest string) (*models.WorkRevision, error) {
+	r, err := s.db.GetWorkRevision(revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.objects.WriteTo(r.SHA256, dest); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Prune removes stored files no revision refers to, such as those left
+// behind when a work is deleted
+func (s *Store) Prune() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	live, err := s.db.WorkRevisionHashes()
+	if err != nil {
+		return 0, err
+	}
+	return s.objects.Prune(live)
+}
@@ -0,0 +1,110 @@
+package revisions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/db"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/textdiff"
+)
+
+func TestCaptureDiffRestore(t *testing.T) {
+	dir := t.TempDir()
+	database, err := db.New(filepath.Join(dir, "works.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+	if err := database.InitSchemaFromFile("../migrations/sql/001_initial_schema.sql"); err != nil {
+		t.Fatalf("init schema: %v", err)
+	}
+	if err := database.RunMigrations(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	work := &models.Work{Title: "Rain", Type: "Poem", Status: "Working", Quality: "Okay", DocType: "txt"}
+	if _, err := database.CreateWork(work); err != nil {
+		t.Fatalf("create work: %v", err)
+	}
+
+	path := filepath.Join(dir, "Rain.txt")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := NewStore(database, filepath.Join(dir, "revisions"))
+
+	write("The rain came down.\nIt kept falling.")
+	first, err := store.Capture(work.WorkID, path, "txt", "")
+	if err != nil || first == nil {
+		t.Fatalf("first capture: %v", err)
+	}
+	if first.NWords != 7 || first.WordDelta != 7 {
+		t.Errorf("expected 7 words and delta 7, got %d and %d", first.NWords, first.WordDelta)
+	}
+
+	if again, err := store.Capture(work.WorkID, path, "txt", ""); err != nil || again != nil {
+		t.Errorf("expected an unchanged file to be skipped, got %+v (%v)", again, err)
+	}
+
+	write("The rain came down.\nThen it stopped.\nThe end.")
+	second, err := store.Capture(work.WorkID, path, "txt", "")
+	if err != nil || second == nil {
+		t.Fatalf("second capture: %v", err)
+	}
+	if second.WordDelta != 2 {
+		t.Errorf("expected a delta of 2 words, got %d", second.WordDelta)
+	}
+
+	revs, err := store.List(work.WorkID)
+	if err != nil || len(revs) != 2 || revs[0].RevisionID != second.RevisionID {
+		t.Fatalf("expected 2 revisions newest first, got %+v (%v)", revs, err)
+	}
+
+	diff, err := store.Diff(first.RevisionID, second.RevisionID, textdiff.Options{Context: -1})
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if diff.Stats.ParagraphsAdded != 2 || diff.Stats.ParagraphsRemoved != 1 {
+		t.Errorf("expected 2 paragraphs added and 1 removed, got %+v", diff.Stats)
+	}
+
+	// The submission copy and the current file compare like revisions
+	copyPath := filepath.Join(dir, "Submissions", "Rain.txt")
+	_ = os.MkdirAll(filepath.Dir(copyPath), 0755)
+	if err := os.WriteFile(copyPath, []byte("The rain came down.\nIt kept falling."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loc := Locations{Current: path, Copy: copyPath, DocType: "txt"}
+	cmp, err := store.Compare(work.WorkID, Version{Kind: VersionCopy}, Version{Kind: VersionCurrent}, loc, textdiff.Options{})
+	if err != nil {
+		t.Fatalf("compare copy with current: %v", err)
+	}
+	if cmp.From != "Submission copy" || cmp.Stats.ParagraphsAdded != 2 {
+		t.Errorf("unexpected comparison: %+v", cmp)
+	}
+	if _, err := store.Compare(work.WorkID+1, Version{Kind: VersionRevision, RevisionID: first.RevisionID},
+		Version{Kind: VersionCurrent}, loc, textdiff.Options{}); err == nil {
+		t.Error("expected a revision of another work to be refused")
+	}
+
+	if _, err := store.Restore(first.RevisionID, path); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "The rain came down.\nIt kept falling." {
+		t.Errorf("expected the first revision back, got %q", data)
+	}
+
+	// Edits made while nothing was watching are picked up by Sync
+	later := time.Now().Add(time.Minute)
+	_ = os.Chtimes(path, later, later)
+	n, err := store.Sync([]Source{{WorkID: work.WorkID, Path: path, DocType: "txt"}})
+	if err != nil || n != 1 {
+		t.Errorf("expected sync to capture the restored file, got %d (%v)", n, err)
+	}
+}
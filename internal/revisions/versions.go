@@ -0,0 +1,106 @@
+package revisions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/fts"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/textdiff"
+)
+
+const (
+	VersionCurrent  = "current"  // the work's document as it is on disk
+	VersionRevision = "revision" // a stored revision
+	VersionCopy     = "copy"     // the copy made for a submission
+)
+
+// Version names one version of a work's document to compare
+type Version struct {
+	Kind       string `json:"kind"`
+	RevisionID int64  `json:"revisionID,omitempty"`
+}
+
+// Locations tells the store where a work's live files are
+type Locations struct {
+	Current string // absolute path of the work's document
+	Copy    string // absolute path of its submission copy, which may not exist
+	DocType string
+}
+
+// ParseVersion reads "current", "copy" or a revision ID
+func ParseVersion(s string) (Version, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case VersionCurrent, VersionCopy:
+		return Version{Kind: s}, nil
+	}
+	var id int64
+	if _, err := fmt.Sscan(s, &id); err != nil || id <= 0 {
+		return Version{}, fmt.Errorf("version must be current, copy or a revision ID: %s", s)
+	}
+	return Version{Kind: VersionRevision, RevisionID: id}, nil
+}
+
+// Text returns the extracted text of one version of a work and a label for it
+func (s *Store) Text(workID int64, v Version, loc Locations) (string, string, error) {
+	switch v.Kind {
+	case VersionCurrent:
+		text, err := extractFile(loc.Current, loc.DocType)
+		return text, "Current file", err
+
+	case VersionCopy:
+		if loc.Copy == "" {
+			return "", "", fmt.Errorf("work has no submission copy")
+		}
+		if _, err := os.Stat(loc.Copy); err != nil {
+			return "", "", fmt.Errorf("no submission copy at %s", loc.Copy)
+		}
+		docType := strings.TrimPrefix(strings.ToLower(filepath.Ext(loc.Copy)), ".")
+		text, err := extractFile(loc.Copy, docType)
+		return text, "Submission copy", err
+
+	case VersionRevision:
+		r, err := s.db.GetWorkRevision(v.RevisionID)
+		if err != nil {
+			return "", "", err
+		}
+		if r.WorkID != workID {
+			return "", "", fmt.Errorf("revision %d belongs to another work", v.RevisionID)
+		}
+		text, err := s.db.GetWorkRevisionText(v.RevisionID)
+		return text, revisionLabel(r.RevisionID, r.CreatedAt), err
+	}
+	return "", "", fmt.Errorf("unknown version kind: %s", v.Kind)
+}
+
+// Compare diffs two versions of a work's document
+func (s *Store) Compare(workID int64, from, to Version, loc Locations, opts textdiff.Options) (*textdiff.Result, error) {
+	fromText, fromLabel, err := s.Text(workID, from, loc)
+	if err != nil {
+		return nil, err
+	}
+	toText, toLabel, err := s.Text(workID, to, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	result := textdiff.Compare(fromText, toText, opts)
+	result.From, result.To = fromLabel, toLabel
+	return result, nil
+}
+
+func extractFile(path, docType string) (string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("file not found: %s", path)
+	}
+	return fts.ExtractByType(path, docType)
+}
+
+func revisionLabel(id int64, createdAt string) string {
+	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
+		return fmt.Sprintf("Revision %d (%s)", id, t.Local().Format("2006-01-02 15:04"))
+	}
+	return fmt.Sprintf("Revision %d", id)
+}
@@ -0,0 +1,401 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/db"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/validation"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// reservedParams are query parameters that control paging rather than filter fields
+var reservedParams = map[string]bool{
+	"limit":   true,
+	"offset":  true,
+	"sort":    true,
+	"deleted": true,
+	"q":       true,
+}
+
+var errNotFound = errors.New("not found")
+
+// APIServer exposes the works database as a local JSON/REST API.
+type APIServer struct {
+	db     *db.DB
+	addr   string
+	token  string
+	port   int
+	server *http.Server
+}
+
+// Page is the envelope returned by every list endpoint
+type Page struct {
+	Items  any `json:"items"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+type apiError struct {
+	Error  string                    `json:"error"`
+	Errors []validation.FieldError   `json:"errors,omitempty"`
+	Warns  []validation.FieldWarning `json:"warnings,omitempty"`
+}
+
+// NewAPI creates an API server bound to addr (e.g. "127.0.0.1:7474").
+// Every request must carry "Authorization: Bearer <token>"; when token is
+// empty a random one is generated, which Token returns.
+func NewAPI(database *db.DB, addr, token string) *APIServer {
+	if token == "" {
+		token = generateToken()
+	}
+	return &APIServer{
+		db:    database,
+		addr:  addr,
+		token: token,
+	}
+}
+
+func generateToken() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("generate API token: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// Token returns the bearer token clients must send
+func (s *APIServer) Token() string {
+	return s.token
+}
+
+func (s *APIServer) Start() (int, error) {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return 0, fmt.Errorf("listen on %s: %w", s.addr, err)
+	}
+	s.port = listener.Addr().(*net.TCPAddr).Port
+
+	s.server = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+
+	return s.port, nil
+}
+
+func (s *APIServer) Stop() error {
+	if s.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+func (s *APIServer) Port() int {
+	return s.port
+}
+
+// Handler returns the API routes; exposed separately so tests and other
+// servers can mount it without listening on a port.
+func (s *APIServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	s.registerWorks(mux)
+	s.registerOrganizations(mux)
+	s.registerSubmissions(mux)
+	s.registerCollections(mux)
+	s.registerNotes(mux)
+	s.registerBooks(mux)
+	s.registerCalendar(mux)
+	mux.HandleFunc("GET /api/search", s.handleSearch)
+	return s.middleware(mux)
+}
+
+// middleware checks the bearer token on every request. Browsers may only
+// call the API from pages served on this machine; a request from any
+// other origin is refused, so a web page the user happens to visit can't
+// read or change their records.
+func (s *APIServer) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if !allowedOrigin(origin) {
+				writeError(w, http.StatusForbidden, "origin not allowed")
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, If-Match, If-None-Match")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Expose-Headers", "ETag")
+		}
+		w.Header().Add("Vary", "Origin")
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, []byte("Bearer "+s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// extensionSchemes are the origins of browser extensions, which reach the
+// API with the token the user gave them
+var extensionSchemes = map[string]bool{
+	"chrome-extension":     true,
+	"moz-extension":        true,
+	"safari-web-extension": true,
+}
+
+// allowedOrigin reports whether a browser origin is a page on this machine
+// or a browser extension
+func allowedOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if extensionSchemes[u.Scheme] {
+		return u.Host != ""
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+func (s *APIServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	resp, err := s.db.Search(q, limit, showDeleted(r))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// computeETag hashes the JSON form of an entity so that any field change,
+// not just modified_at, invalidates cached copies.
+func computeETag(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// checkPrecondition enforces optimistic concurrency on mutating requests.
+// It returns false (having written the response) if the request must stop.
+func checkPrecondition(w http.ResponseWriter, r *http.Request, current any) bool {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		writeError(w, http.StatusPreconditionRequired, "If-Match header is required")
+		return false
+	}
+	if ifMatch != "*" && ifMatch != computeETag(current) {
+		w.Header().Set("ETag", computeETag(current))
+		writeError(w, http.StatusPreconditionFailed, "entity was modified by another client")
+		return false
+	}
+	return true
+}
+
+func writeEntity(w http.ResponseWriter, r *http.Request, status int, v any) {
+	etag := computeETag(v)
+	w.Header().Set("ETag", etag)
+	if status == http.StatusOK && r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, status, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, apiError{Error: msg})
+}
+
+func writeValidation(w http.ResponseWriter, result *validation.ValidationResult) {
+	writeJSON(w, http.StatusUnprocessableEntity, apiError{
+		Error:  "validation failed",
+		Errors: result.Errors,
+		Warns:  result.Warnings,
+	})
+}
+
+func pathID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return 0, false
+	}
+	return id, true
+}
+
+func showDeleted(r *http.Request) bool {
+	v := r.URL.Query().Get("deleted")
+	return v == "1" || v == "true"
+}
+
+// paginate filters, sorts and slices a list according to the request's
+// query string. Any non-reserved parameter is matched case-insensitively
+// against the JSON field of the same name; "q" matches any string field.
+func paginate[T any](r *http.Request, items []T) (Page, error) {
+	query := r.URL.Query()
+
+	limit := defaultPageSize
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return Page{}, fmt.Errorf("invalid limit")
+		}
+		limit = min(n, maxPageSize)
+	}
+
+	offset := 0
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return Page{}, fmt.Errorf("invalid offset")
+		}
+		offset = n
+	}
+
+	type row struct {
+		item   T
+		fields map[string]any
+	}
+	rows := make([]row, 0, len(items))
+	needle := strings.ToLower(query.Get("q"))
+
+	for _, item := range items {
+		fields, err := toFields(item)
+		if err != nil {
+			return Page{}, err
+		}
+		if !matchesFilters(fields, query) {
+			continue
+		}
+		if needle != "" && !matchesText(fields, needle) {
+			continue
+		}
+		rows = append(rows, row{item: item, fields: fields})
+	}
+
+	if sortKey := query.Get("sort"); sortKey != "" {
+		desc := strings.HasPrefix(sortKey, "-")
+		sortKey = strings.TrimPrefix(sortKey, "-")
+		sort.SliceStable(rows, func(i, j int) bool {
+			cmp := compareFields(rows[i].fields[sortKey], rows[j].fields[sortKey])
+			if desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		})
+	}
+
+	page := Page{Total: len(rows), Limit: limit, Offset: offset}
+	out := make([]T, 0, limit)
+	for i := offset; i < len(rows) && len(out) < limit; i++ {
+		out = append(out, rows[i].item)
+	}
+	page.Items = out
+	return page, nil
+}
+
+func toFields(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]any{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func matchesFilters(fields map[string]any, query map[string][]string) bool {
+	for key, values := range query {
+		if reservedParams[key] || len(values) == 0 {
+			continue
+		}
+		got, ok := fields[key]
+		if !ok {
+			return false
+		}
+		matched := false
+		for _, want := range strings.Split(values[0], ",") {
+			if strings.EqualFold(fmt.Sprint(got), want) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesText(fields map[string]any, needle string) bool {
+	for _, v := range fields {
+		if s, ok := v.(string); ok && strings.Contains(strings.ToLower(s), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareFields(a, b any) int {
+	af, aNum := a.(float64)
+	bf, bNum := b.(float64)
+	if aNum && bNum {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(fmt.Sprint(a)), strings.ToLower(fmt.Sprint(b)))
+}
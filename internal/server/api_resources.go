@@ -0,0 +1,372 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/calendar"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/validation"
+)
+
+// resource wires a database entity to the standard REST routes. L is the
+// (possibly enriched) list type and T the editable entity. Nil functions
+// leave the corresponding route unregistered.
+type resource[L, T any] struct {
+	list   func(r *http.Request) ([]L, error)
+	get    func(id int64) (*T, error)
+	create func(v *T) (*validation.ValidationResult, error)
+	update func(v *T) (*validation.ValidationResult, error)
+	remove func(id int64) error
+	id     func(v *T) int64
+	setID  func(v *T, id int64)
+}
+
+func mount[L, T any](mux *http.ServeMux, base string, res resource[L, T]) {
+	if res.list != nil {
+		mux.HandleFunc("GET "+base, func(w http.ResponseWriter, r *http.Request) {
+			items, err := res.list(r)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			page, err := paginate(r, items)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeJSON(w, http.StatusOK, page)
+		})
+	}
+
+	if res.get != nil {
+		mux.HandleFunc("GET "+base+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+			current, ok := loadEntity(w, r, res.get)
+			if !ok {
+				return
+			}
+			writeEntity(w, r, http.StatusOK, current)
+		})
+	}
+
+	if res.create != nil {
+		mux.HandleFunc("POST "+base, func(w http.ResponseWriter, r *http.Request) {
+			var v T
+			if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+				return
+			}
+			res.setID(&v, 0)
+			result, err := res.create(&v)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			if result != nil && !result.IsValid() {
+				writeValidation(w, result)
+				return
+			}
+			created, err := res.get(res.id(&v))
+			if err != nil || created == nil {
+				created = &v
+			}
+			w.Header().Set("Location", fmt.Sprintf("%s/%d", base, res.id(&v)))
+			writeEntity(w, r, http.StatusCreated, created)
+		})
+	}
+
+	if res.update != nil {
+		mux.HandleFunc("PATCH "+base+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+			current, ok := loadEntity(w, r, res.get)
+			if !ok || !checkPrecondition(w, r, current) {
+				return
+			}
+			id := res.id(current)
+			updated := *current
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+				return
+			}
+			res.setID(&updated, id)
+			result, err := res.update(&updated)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			if result != nil && !result.IsValid() {
+				writeValidation(w, result)
+				return
+			}
+			fresh, err := res.get(id)
+			if err != nil || fresh == nil {
+				fresh = &updated
+			}
+			writeEntity(w, r, http.StatusOK, fresh)
+		})
+	}
+
+	if res.remove != nil {
+		mux.HandleFunc("DELETE "+base+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+			current, ok := loadEntity(w, r, res.get)
+			if !ok || !checkPrecondition(w, r, current) {
+				return
+			}
+			if err := res.remove(res.id(current)); err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+func loadEntity[T any](w http.ResponseWriter, r *http.Request, get func(int64) (*T, error)) (*T, bool) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return nil, false
+	}
+	v, err := get(id)
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, errNotFound) || (err == nil && v == nil) {
+		writeError(w, http.StatusNotFound, "not found")
+		return nil, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return v, true
+}
+
+func (s *APIServer) registerWorks(mux *http.ServeMux) {
+	mount(mux, "/api/works", resource[models.WorkView, models.Work]{
+		list: func(r *http.Request) ([]models.WorkView, error) {
+			return s.db.ListWorks(showDeleted(r))
+		},
+		get:    s.db.GetWork,
+		create: s.db.CreateWork,
+		update: s.db.UpdateWork,
+		remove: s.db.DeleteWork,
+		id:     func(v *models.Work) int64 { return v.WorkID },
+		setID:  func(v *models.Work, id int64) { v.WorkID = id },
+	})
+
+	mux.HandleFunc("GET /api/works/{id}/submissions", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		writeList(w, r, func() ([]models.SubmissionView, error) {
+			return s.db.ListSubmissionViewsByWork(id, showDeleted(r))
+		})
+	})
+
+	mux.HandleFunc("GET /api/works/{id}/notes", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		writeList(w, r, func() ([]models.Note, error) {
+			return s.db.GetNotes("work", id, showDeleted(r))
+		})
+	})
+}
+
+func (s *APIServer) registerOrganizations(mux *http.ServeMux) {
+	mount(mux, "/api/organizations", resource[models.Organization, models.Organization]{
+		list: func(r *http.Request) ([]models.Organization, error) {
+			return s.db.ListOrganizations(showDeleted(r))
+		},
+		get:    s.db.GetOrganization,
+		create: s.db.CreateOrganization,
+		update: s.db.UpdateOrganization,
+		remove: s.db.DeleteOrganization,
+		id:     func(v *models.Organization) int64 { return v.OrgID },
+		setID:  func(v *models.Organization, id int64) { v.OrgID = id },
+	})
+
+	mux.HandleFunc("GET /api/organizations/{id}/submissions", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		writeList(w, r, func() ([]models.SubmissionView, error) {
+			return s.db.ListSubmissionViewsByOrg(id, showDeleted(r))
+		})
+	})
+}
+
+func (s *APIServer) registerSubmissions(mux *http.ServeMux) {
+	mount(mux, "/api/submissions", resource[models.SubmissionView, models.Submission]{
+		list: func(r *http.Request) ([]models.SubmissionView, error) {
+			return s.db.ListAllSubmissionViews(showDeleted(r))
+		},
+		get:    s.db.GetSubmission,
+		create: s.db.CreateSubmission,
+		update: s.db.UpdateSubmission,
+		remove: s.db.DeleteSubmission,
+		id:     func(v *models.Submission) int64 { return v.SubmissionID },
+		setID:  func(v *models.Submission, id int64) { v.SubmissionID = id },
+	})
+}
+
+func (s *APIServer) registerCollections(mux *http.ServeMux) {
+	mount(mux, "/api/collections", resource[models.CollectionView, models.Collection]{
+		list: func(r *http.Request) ([]models.CollectionView, error) {
+			return s.db.ListCollections(showDeleted(r))
+		},
+		get:    s.db.GetCollection,
+		create: s.db.CreateCollection,
+		update: s.db.UpdateCollection,
+		remove: s.db.DeleteCollection,
+		id:     func(v *models.Collection) int64 { return v.CollID },
+		setID:  func(v *models.Collection, id int64) { v.CollID = id },
+	})
+
+	mux.HandleFunc("GET /api/collections/{id}/works", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		writeList(w, r, func() ([]models.CollectionWork, error) {
+			return s.db.GetCollectionWorks(id, showDeleted(r))
+		})
+	})
+
+	mux.HandleFunc("GET /api/collections/{id}/submissions", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		writeList(w, r, func() ([]models.SubmissionView, error) {
+			return s.db.GetCollectionSubmissions(id, showDeleted(r))
+		})
+	})
+
+	mux.HandleFunc("GET /api/collections/{id}/organizations", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		writeList(w, r, func() ([]models.Organization, error) {
+			return s.db.GetCollectionOrganizations(id, showDeleted(r))
+		})
+	})
+
+	mux.HandleFunc("GET /api/collections/{id}/book", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		book, err := s.db.GetBookByCollection(id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if book == nil {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		writeEntity(w, r, http.StatusOK, book)
+	})
+}
+
+func (s *APIServer) registerNotes(mux *http.ServeMux) {
+	mount(mux, "/api/notes", resource[models.Note, models.Note]{
+		list: func(r *http.Request) ([]models.Note, error) {
+			return s.db.GetAllNotes(showDeleted(r))
+		},
+		get:    s.db.GetNote,
+		create: s.db.CreateNote,
+		update: s.db.UpdateNote,
+		remove: s.db.DeleteNote,
+		id:     func(v *models.Note) int64 { return v.ID },
+		setID:  func(v *models.Note, id int64) { v.ID = id },
+	})
+}
+
+func (s *APIServer) registerBooks(mux *http.ServeMux) {
+	mount(mux, "/api/books", resource[models.Book, models.Book]{
+		get: s.db.GetBook,
+		update: func(b *models.Book) (*validation.ValidationResult, error) {
+			return &validation.ValidationResult{}, s.db.UpdateBook(b)
+		},
+		id:    func(v *models.Book) int64 { return v.BookID },
+		setID: func(v *models.Book, id int64) { v.BookID = id },
+	})
+}
+
+func (s *APIServer) registerCalendar(mux *http.ServeMux) {
+	mount(mux, "/api/windows", resource[models.SubmissionWindow, models.SubmissionWindow]{
+		list: func(r *http.Request) ([]models.SubmissionWindow, error) {
+			return s.db.ListSubmissionWindows(0)
+		},
+		get:    s.db.GetSubmissionWindow,
+		create: s.db.CreateSubmissionWindow,
+		update: s.db.UpdateSubmissionWindow,
+		remove: s.db.DeleteSubmissionWindow,
+		id:     func(v *models.SubmissionWindow) int64 { return v.WindowID },
+		setID:  func(v *models.SubmissionWindow, id int64) { v.WindowID = id },
+	})
+
+	// GET /api/calendar?from=YYYY-MM-DD&to=YYYY-MM-DD (defaults: today, +1 year)
+	mux.HandleFunc("GET /api/calendar", func(w http.ResponseWriter, r *http.Request) {
+		events, err := s.calendarEvents(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, events)
+	})
+
+	mux.HandleFunc("GET /api/calendar.ics", func(w http.ResponseWriter, r *http.Request) {
+		events, err := s.calendarEvents(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		_ = calendar.WriteICS(w, events, time.Now())
+	})
+}
+
+func (s *APIServer) calendarEvents(r *http.Request) ([]models.CalendarEvent, error) {
+	today := time.Now()
+	from, to := today, today.AddDate(1, 0, 0)
+
+	var err error
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = time.Parse("2006-01-02", v); err != nil {
+			return nil, fmt.Errorf("invalid from date")
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			return nil, fmt.Errorf("invalid to date")
+		}
+	}
+
+	windows, err := s.db.ListSubmissionWindows(0)
+	if err != nil {
+		return nil, err
+	}
+	return calendar.Expand(windows, from, to, today), nil
+}
+
+// writeList pages the slice returned by fn
+func writeList[T any](w http.ResponseWriter, r *http.Request, fn func() ([]T, error)) {
+	items, err := fn()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	page, err := paginate(r, items)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/db"
+	"github.com/TrueBlocks/trueblocks-works/v2/internal/models"
+)
+
+func setupTestAPI(t *testing.T) (*db.DB, http.Handler) {
+	t.Helper()
+	database, err := db.New(filepath.Join(t.TempDir(), "works.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.InitSchemaFromFile("../migrations/sql/001_initial_schema.sql"); err != nil {
+		t.Fatalf("init schema: %v", err)
+	}
+	if err := database.RunMigrations(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return database, NewAPI(database, "127.0.0.1:0", "secret").Handler()
+}
+
+func doRequest(h http.Handler, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIRequiresToken(t *testing.T) {
+	_, h := setupTestAPI(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/works", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+func TestAPIGeneratesToken(t *testing.T) {
+	api := NewAPI(nil, "127.0.0.1:0", "")
+	if len(api.Token()) < 32 {
+		t.Fatalf("expected a generated token, got %q", api.Token())
+	}
+	if NewAPI(nil, "127.0.0.1:0", "").Token() == api.Token() {
+		t.Error("generated tokens should differ")
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/works/1", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	api.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for an empty token, got %d", rec.Code)
+	}
+}
+
+func TestAPIOrigins(t *testing.T) {
+	_, h := setupTestAPI(t)
+
+	rec := doRequest(h, http.MethodPost, "/api/works", `{"title":"Rain"}`, map[string]string{"Origin": "https://evil.example"})
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a foreign origin, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("foreign origin should get no CORS header, got %q", got)
+	}
+
+	rec = doRequest(h, http.MethodOptions, "/api/works", "", map[string]string{"Origin": "http://localhost:5173"})
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") != "http://localhost:5173" {
+		t.Errorf("expected localhost preflight to be allowed, got %d %q", rec.Code, rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+
+	for _, origin := range []string{"chrome-extension://abcdefghijklmnop", "moz-extension://0b7e1c2d-44aa-4f1e-9c3b-2f6d1e7a8b90"} {
+		rec = doRequest(h, http.MethodGet, "/api/works", "", map[string]string{"Origin": origin})
+		if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != origin {
+			t.Errorf("expected extension origin %s to be allowed, got %d %q", origin, rec.Code, rec.Header().Get("Access-Control-Allow-Origin"))
+		}
+	}
+	if rec := doRequest(h, http.MethodGet, "/api/works", "", map[string]string{"Origin": "file://"}); rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a file origin, got %d", rec.Code)
+	}
+
+	rec = doRequest(h, http.MethodGet, "/api/works", "", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("expected a plain 200 without an origin, got %d", rec.Code)
+	}
+}
+
+func TestAPIWorksCRUD(t *testing.T) {
+	_, h := setupTestAPI(t)
+
+	rec := doRequest(h, http.MethodPost, "/api/works", `{"title":"Rain","type":"Poem","year":"2024","status":"Working","quality":"Okay"}`, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created models.Work
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	path := rec.Header().Get("Location")
+	etag := rec.Header().Get("ETag")
+	if created.WorkID == 0 || path == "" || etag == "" {
+		t.Fatalf("missing id, location or etag: %d %q %q", created.WorkID, path, etag)
+	}
+
+	if rec := doRequest(h, http.MethodGet, path, "", map[string]string{"If-None-Match": etag}); rec.Code != http.StatusNotModified {
+		t.Errorf("conditional get: expected 304, got %d", rec.Code)
+	}
+
+	if rec := doRequest(h, http.MethodPatch, path, `{"title":"Snow"}`, nil); rec.Code != http.StatusPreconditionRequired {
+		t.Errorf("patch without If-Match: expected 428, got %d", rec.Code)
+	}
+	if rec := doRequest(h, http.MethodPatch, path, `{"title":"Snow"}`, map[string]string{"If-Match": `"stale"`}); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("patch with stale etag: expected 412, got %d", rec.Code)
+	}
+
+	rec = doRequest(h, http.MethodPatch, path, `{"title":"Snow"}`, map[string]string{"If-Match": etag})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("patch: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var updated models.Work
+	_ = json.Unmarshal(rec.Body.Bytes(), &updated)
+	if updated.Title != "Snow" || updated.Type != "Poem" {
+		t.Errorf("patch should merge fields, got title=%q type=%q", updated.Title, updated.Type)
+	}
+
+	rec = doRequest(h, http.MethodGet, "/api/works?type=Poem&q=snow", "", nil)
+	var page struct {
+		Items []models.WorkView `json:"items"`
+		Total int               `json:"total"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &page)
+	if page.Total != 1 || len(page.Items) != 1 {
+		t.Errorf("filtered list: expected 1 item, got %d", page.Total)
+	}
+
+	if rec := doRequest(h, http.MethodGet, "/api/works/9999", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("missing work: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+		N    int    `json:"n"`
+	}
+	items := []item{{"a", 3}, {"b", 1}, {"c", 2}, {"d", 5}}
+
+	req := httptest.NewRequest(http.MethodGet, "/?sort=-n&limit=2&offset=1", nil)
+	page, err := paginate(req, items)
+	if err != nil {
+		t.Fatalf("paginate: %v", err)
+	}
+	got := page.Items.([]item)
+	if page.Total != 4 || len(got) != 2 || got[0].Name != "a" || got[1].Name != "c" {
+		t.Errorf("unexpected page: %+v", page)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/?limit=0", nil)
+	if _, err := paginate(req, items); err == nil {
+		t.Error("expected error for limit=0")
+	}
+}
+
+func TestAPICollectionOrganizations(t *testing.T) {
+	database, h := setupTestAPI(t)
+
+	for _, name := range []string{"Alpha", "Beta"} {
+		org := &models.Organization{Name: name, Status: "Open", Type: "Journal"}
+		if _, err := database.CreateOrganization(org); err != nil {
+			t.Fatalf("create organization: %v", err)
+		}
+	}
+	query := "Alpha"
+	coll := &models.Collection{CollectionName: "Journals", Entity: "organizations", SmartQuery: &query}
+	if _, err := database.CreateCollection(coll); err != nil {
+		t.Fatalf("create collection: %v", err)
+	}
+
+	rec := doRequest(h, http.MethodGet, fmt.Sprintf("/api/collections/%d/organizations", coll.CollID), "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var page struct {
+		Items []models.Organization `json:"items"`
+		Total int                   `json:"total"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if page.Total != 1 || len(page.Items) != 1 || page.Items[0].Name != "Alpha" {
+		t.Errorf("expected only Alpha, got %+v", page)
+	}
+}
@@ -1,16 +1,19 @@
 package server
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"path/filepath"
 )
 
 type FileServer struct {
-	pdfPath string
-	port    int
-	server  *http.Server
+	pdfPath  string
+	port     int
+	server   *http.Server
+	calendar func(w io.Writer) error
 }
 
 func New(pdfPath string) *FileServer {
@@ -19,6 +22,11 @@ func New(pdfPath string) *FileServer {
 	}
 }
 
+// SetCalendarHandler supplies the iCalendar feed served at /calendar.ics
+func (s *FileServer) SetCalendarHandler(fn func(w io.Writer) error) {
+	s.calendar = fn
+}
+
 func (s *FileServer) Start() (int, error) {
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -36,6 +44,21 @@ func (s *FileServer) Start() (int, error) {
 		http.ServeFile(w, r, pdfFile)
 	})
 
+	mux.HandleFunc("/calendar.ics", func(w http.ResponseWriter, r *http.Request) {
+		if s.calendar == nil {
+			http.NotFound(w, r)
+			return
+		}
+		var buf bytes.Buffer
+		if err := s.calendar(&buf); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		_, _ = w.Write(buf.Bytes())
+	})
+
 	s.server = &http.Server{Handler: mux}
 
 	go func() {
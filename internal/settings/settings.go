@@ -24,6 +24,24 @@ type Settings struct {
 	SkipDeleteBackupConfirm   bool     `json:"skipDeleteBackupConfirm,omitempty"`
 	SkipNumberAsSortedConfirm bool     `json:"skipNumberAsSortedConfirm,omitempty"`
 
+	// Submission packets
+	AuthorName    string `json:"authorName,omitempty"`
+	ContactHeader string `json:"contactHeader,omitempty"` // one line per row: name, address, email
+
+	// Backups
+	BackupCompression      string `json:"backupCompression"` // 'none', 'gzip', 'zstd'
+	BackupKeepDaily        int    `json:"backupKeepDaily"`
+	BackupKeepWeekly       int    `json:"backupKeepWeekly"`
+	BackupKeepMonthly      int    `json:"backupKeepMonthly"`
+	BackupKeepYearly       int    `json:"backupKeepYearly"`
+	BackupIncludeFTS       bool   `json:"backupIncludeFTS"`
+	BackupIncludeTemplates bool   `json:"backupIncludeTemplates"`
+	BackupIncludeConfig    bool   `json:"backupIncludeConfig"`
+
+	// PDF previews
+	PDFConverterPriority []string `json:"pdfConverterPriority,omitempty"` // 'word', 'excel', 'libreoffice', 'builtin'
+	PDFMaxConversions    int      `json:"pdfMaxConversions,omitempty"`    // 0 means one per CPU
+
 	// Analysis feature
 	AnalysisEnabled  bool   `json:"analysisEnabled,omitempty"`
 	AnalysisProvider string `json:"analysisProvider,omitempty"` // 'openai', 'anthropic', 'ollama'
@@ -64,6 +82,14 @@ func defaultSettings() *Settings {
 		Theme:                "default",
 		DarkMode:             false,
 		ValidExtensions:      DefaultValidExtensions(),
+
+		BackupCompression:      "gzip",
+		BackupKeepDaily:        7,
+		BackupKeepWeekly:       4,
+		BackupKeepMonthly:      12,
+		BackupKeepYearly:       3,
+		BackupIncludeTemplates: true,
+		BackupIncludeConfig:    true,
 	}
 }
 
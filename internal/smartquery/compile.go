@@ -0,0 +1,302 @@
+package smartquery
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is how a field's values are compared
+type Kind int
+
+const (
+	// Text fields equal one of the values, ignoring case
+	Text Kind = iota
+	// Number fields equal one of the values or compare with <, <=, > or >=
+	Number
+	// Contains fields contain one of the values, ignoring case
+	Contains
+	// Date fields fall in one of the periods named by the values, or
+	// compare with the period named by one value
+	Date
+)
+
+// Field maps a query field to a SQL expression
+type Field struct {
+	Column string
+	Kind   Kind
+}
+
+// Schema is what a query may ask about the rows of one table. Column
+// expressions and conditions are trusted SQL; only values from the query
+// are bound as parameters.
+type Schema struct {
+	Name   string           // the rows, for messages: "works"
+	Text   string           // column that bare words and phrases match
+	Fields map[string]Field // field:value
+	Has    map[string]string
+	Is     map[string]string
+	In     string // condition for in:"Collection", with one ? for the name
+}
+
+// Schemas are the tables a smart collection may select from, by name
+var Schemas = map[string]*Schema{
+	Works.Name:         Works,
+	Submissions.Name:   Submissions,
+	Organizations.Name: Organizations,
+}
+
+// SchemaFor returns the schema of a smart collection's entity
+func SchemaFor(entity string) (*Schema, error) {
+	s, ok := Schemas[entity]
+	if !ok {
+		return nil, fmt.Errorf("unknown entity %q; use one of %s", entity, names(Schemas))
+	}
+	return s, nil
+}
+
+// Compile parses a query and compiles it against a schema. The result is
+// a SQL condition and its arguments, ready to follow WHERE.
+func Compile(src string, s *Schema) (string, []any, error) {
+	q, err := Parse(src)
+	if err != nil {
+		return "", nil, err
+	}
+	return q.Compile(s)
+}
+
+// Validate reports the first error in a query, or nil
+func Validate(src string, s *Schema) error {
+	_, _, err := Compile(src, s)
+	return err
+}
+
+// Compile builds the SQL condition for a parsed query
+func (q *Query) Compile(s *Schema) (string, []any, error) {
+	if len(q.Terms) == 0 {
+		return "", nil, errorAt(0, "query is empty")
+	}
+	conds := make([]string, 0, len(q.Terms))
+	var args []any
+	for _, t := range q.Terms {
+		cond, targs, err := t.compile(s)
+		if err != nil {
+			return "", nil, err
+		}
+		if t.Negate {
+			cond = "NOT COALESCE((" + cond + "), 0)"
+		} else {
+			cond = "(" + cond + ")"
+		}
+		conds = append(conds, cond)
+		args = append(args, targs...)
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
+
+func (t Term) compile(s *Schema) (string, []any, error) {
+	switch t.Field {
+	case "":
+		return anyOf(t.Values, func(v string) (string, []any) {
+			return s.Text + ` LIKE ? ESCAPE '\'`, []any{likePattern(v)}
+		})
+
+	case "has", "is":
+		conds := s.Has
+		if t.Field == "is" {
+			conds = s.Is
+		}
+		if t.Op != ":" {
+			return "", nil, errorAt(t.Pos, "use %s:name", t.Field)
+		}
+		for _, v := range t.Values {
+			if _, ok := conds[strings.ToLower(v)]; !ok {
+				if len(conds) == 0 {
+					return "", nil, errorAt(t.Pos, "%s: is not supported for %s", t.Field, s.Name)
+				}
+				return "", nil, errorAt(t.Pos, "unknown %s:%s; use one of %s", t.Field, v, names(conds))
+			}
+		}
+		return anyOf(t.Values, func(v string) (string, []any) {
+			return conds[strings.ToLower(v)], nil
+		})
+
+	case "in":
+		if s.In == "" {
+			return "", nil, errorAt(t.Pos, "in: is not supported for %s", s.Name)
+		}
+		if t.Op != ":" {
+			return "", nil, errorAt(t.Pos, `use in:"Collection name"`)
+		}
+		return anyOf(t.Values, func(v string) (string, []any) {
+			return s.In, []any{v}
+		})
+	}
+
+	f, ok := s.Fields[t.Field]
+	if !ok {
+		return "", nil, errorAt(t.Pos, "unknown field %q; %s have %s", t.Field, s.Name, names(s.Fields))
+	}
+
+	if f.Kind == Date {
+		return t.compileDate(f)
+	}
+
+	if f.Kind != Number {
+		if t.Op != ":" && t.Op != "=" {
+			return "", nil, errorAt(t.Pos, "%s is not a number; use %s:value", t.Field, t.Field)
+		}
+		if f.Kind == Contains {
+			return anyOf(t.Values, func(v string) (string, []any) {
+				return f.Column + ` LIKE ? ESCAPE '\'`, []any{likePattern(v)}
+			})
+		}
+		return f.Column + " COLLATE NOCASE IN (" + placeholders(len(t.Values)) + ")", strArgs(t.Values), nil
+	}
+
+	nums := make([]any, 0, len(t.Values))
+	for _, v := range t.Values {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return "", nil, errorAt(t.Pos, "%s needs a whole number, not %q", t.Field, v)
+		}
+		nums = append(nums, n)
+	}
+	if t.Op == ":" || t.Op == "=" {
+		return f.Column + " IN (" + placeholders(len(nums)) + ")", nums, nil
+	}
+	if len(nums) != 1 {
+		return "", nil, errorAt(t.Pos, "%s%s takes one number", t.Field, t.Op)
+	}
+	return f.Column + " " + t.Op + " ?", nums, nil
+}
+
+// A period is the days from date(from...) up to, but not including,
+// date(from..., length). Relative periods are computed by SQLite when the
+// query runs, so a stored query stays current.
+type period struct {
+	from   []any
+	length string
+}
+
+var (
+	reAgo   = regexp.MustCompile(`^(\d+)([dwmy])$`)
+	agoUnit = map[string]string{"d": "days", "w": "days", "m": "months", "y": "years"}
+	now     = []any{"now", "localtime"}
+)
+
+// periods named by a word
+var namedPeriods = map[string]period{
+	"today":     {now, "+1 day"},
+	"thismonth": {append(now[:2:2], "start of month"), "+1 month"},
+	"lastmonth": {append(now[:2:2], "start of month", "-1 month"), "+1 month"},
+	"thisyear":  {append(now[:2:2], "start of year"), "+1 year"},
+	"lastyear":  {append(now[:2:2], "start of year", "-1 year"), "+1 year"},
+}
+
+// parsePeriod reads a date value: 2024, 2024-03, 2024-03-15, a named
+// period such as thisyear, or a day some days, weeks, months or years ago
+// such as 120d
+func parsePeriod(v string) (period, bool) {
+	if p, ok := namedPeriods[strings.ToLower(v)]; ok {
+		return p, true
+	}
+	if m := reAgo.FindStringSubmatch(strings.ToLower(v)); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return period{}, false
+		}
+		if m[2] == "w" {
+			n *= 7
+		}
+		return period{append(now[:2:2], fmt.Sprintf("-%d %s", n, agoUnit[m[2]])), "+1 day"}, true
+	}
+	for _, layout := range []struct{ layout, length string }{
+		{"2006", "+1 year"}, {"2006-01", "+1 month"}, {"2006-01-02", "+1 day"},
+	} {
+		if d, err := time.Parse(layout.layout, v); err == nil {
+			return period{[]any{d.Format("2006-01-02")}, layout.length}, true
+		}
+	}
+	return period{}, false
+}
+
+func (p period) start() (string, []any) {
+	return "date(" + placeholders(len(p.from)) + ")", p.from
+}
+
+func (p period) end() (string, []any) {
+	return "date(" + placeholders(len(p.from)+1) + ")", append(p.from[:len(p.from):len(p.from)], p.length)
+}
+
+// compileDate compares a date field with periods: field:P is within P,
+// field<P before it, field>P after it, and <= and >= include it
+func (t Term) compileDate(f Field) (string, []any, error) {
+	for _, v := range t.Values {
+		if _, ok := parsePeriod(v); !ok {
+			return "", nil, errorAt(t.Pos, "%s needs a date such as 2024, 2024-03, 2024-03-15, thisyear or 30d, not %q", t.Field, v)
+		}
+	}
+	if t.Op != ":" && t.Op != "=" && len(t.Values) != 1 {
+		return "", nil, errorAt(t.Pos, "%s%s takes one date", t.Field, t.Op)
+	}
+	return anyOf(t.Values, func(v string) (string, []any) {
+		p, _ := parsePeriod(v)
+		start, sargs := p.start()
+		end, eargs := p.end()
+		switch t.Op {
+		case "<", ">=":
+			return f.Column + " " + t.Op + " " + start, sargs
+		case ">":
+			return f.Column + " >= " + end, eargs
+		case "<=":
+			return f.Column + " < " + end, eargs
+		}
+		return f.Column + " >= " + start + " AND " + f.Column + " < " + end, append(sargs[:len(sargs):len(sargs)], eargs...)
+	})
+}
+
+// anyOf ORs the condition for each value
+func anyOf(values []string, cond func(string) (string, []any)) (string, []any, error) {
+	if len(values) == 1 {
+		c, args := cond(values[0])
+		return c, args, nil
+	}
+	conds := make([]string, len(values))
+	var args []any
+	for i, v := range values {
+		c, a := cond(v)
+		conds[i] = "(" + c + ")"
+		args = append(args, a...)
+	}
+	return strings.Join(conds, " OR "), args, nil
+}
+
+func likePattern(v string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + r.Replace(v) + "%"
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func strArgs(values []string) []any {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+func names[V any](m map[string]V) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
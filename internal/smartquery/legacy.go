@@ -0,0 +1,182 @@
+package smartquery
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Smart queries were once stored as raw SQL conditions on Works. FromSQL
+// translates the simple ones: conditions ANDed together that compare a
+// column with literals, and the membership test of the Not Collected
+// collection. ok is false for anything else.
+//
+// A translated query selects the same works with one difference: text is
+// compared ignoring case, as in every smart query, where SQLite compared
+// it exactly. A negated term matches works with no value for the field,
+// which column != 'x' never did, so one on a column that may be NULL is
+// kept from matching them with has:field.
+func FromSQL(sql string) (query string, ok bool) {
+	sql = strings.Join(strings.Fields(sql), " ")
+	if sql == "" {
+		return "", false
+	}
+
+	var terms []Term
+	for _, clause := range splitAnd(sql) {
+		t, ok := legacyTerm(clause)
+		if !ok {
+			return "", false
+		}
+		if t.Negate && legacyNullable[t.Field] {
+			terms = append(terms, Term{Field: "has", Op: ":", Values: []string{t.Field}})
+		}
+		terms = append(terms, t)
+	}
+
+	q := &Query{Terms: terms}
+	query = q.String()
+	if Validate(query, Works) != nil {
+		return "", false
+	}
+	return query, true
+}
+
+var legacyFields = map[string]string{
+	"title":       "title",
+	"type":        "type",
+	"status":      "status",
+	"quality":     "quality",
+	"doc_type":    "doctype",
+	"course_name": "course",
+	"year":        "year",
+	"n_words":     "words",
+	"workid":      "id",
+}
+
+// legacyNullable are the fields whose columns may be NULL. Each has a
+// has: condition of the same name.
+var legacyNullable = map[string]bool{
+	"year":   true,
+	"words":  true,
+	"course": true,
+}
+
+const sqlString = `'(?:[^']|'')*'`
+
+var (
+	reCollected = regexp.MustCompile(`(?i)^(?:w\.)?workid (not )?in \(select (?:distinct )?workid from collectiondetails\)$`)
+	reCompare   = regexp.MustCompile(`(?i)^(?:w\.)?(\w+) ?(=|!=|<>|>=|<=|>|<) ?(` + sqlString + `|-?\d+)$`)
+	reIn        = regexp.MustCompile(`(?i)^(?:w\.)?(\w+) (not )?in ?\((` + sqlString + `(?: ?, ?` + sqlString + `)*)\)$`)
+	reLike      = regexp.MustCompile(`(?i)^(?:w\.)?(\w+) (not )?like '%((?:[^'%_]|'')*)%'$`)
+	reString    = regexp.MustCompile(sqlString)
+)
+
+func legacyTerm(clause string) (Term, bool) {
+	clause = strings.TrimSpace(clause)
+	for enclosed(clause) {
+		clause = strings.TrimSpace(clause[1 : len(clause)-1])
+	}
+
+	if m := reCollected.FindStringSubmatch(clause); m != nil {
+		return Term{Field: "has", Op: ":", Values: []string{"collection"}, Negate: m[1] != ""}, true
+	}
+
+	if m := reCompare.FindStringSubmatch(clause); m != nil {
+		column, op, value := strings.ToLower(m[1]), m[2], unquoteSQL(m[3])
+		if column == "is_marked" && (op == "=" || op == "!=" || op == "<>") && (value == "0" || value == "1") {
+			return Term{Field: "is", Op: ":", Values: []string{"marked"}, Negate: (value == "1") != (op == "=")}, true
+		}
+		field, ok := legacyFields[column]
+		if !ok || Works.Fields[field].Kind == Contains {
+			return Term{}, false
+		}
+		switch op {
+		case "=":
+			return Term{Field: field, Op: ":", Values: []string{value}}, true
+		case "!=", "<>":
+			return Term{Field: field, Op: ":", Values: []string{value}, Negate: true}, true
+		}
+		if Works.Fields[field].Kind != Number {
+			return Term{}, false
+		}
+		return Term{Field: field, Op: op, Values: []string{value}}, true
+	}
+
+	if m := reIn.FindStringSubmatch(clause); m != nil {
+		field, ok := legacyFields[strings.ToLower(m[1])]
+		if !ok {
+			return Term{}, false
+		}
+		var values []string
+		for _, s := range reString.FindAllString(m[3], -1) {
+			values = append(values, unquoteSQL(s))
+		}
+		return Term{Field: field, Op: ":", Values: values, Negate: m[2] != ""}, true
+	}
+
+	if m := reLike.FindStringSubmatch(clause); m != nil {
+		field, ok := legacyFields[strings.ToLower(m[1])]
+		if !ok || Works.Fields[field].Kind != Contains {
+			return Term{}, false
+		}
+		return Term{Field: field, Op: ":", Values: []string{strings.ReplaceAll(m[3], "''", "'")}, Negate: m[2] != ""}, true
+	}
+
+	return Term{}, false
+}
+
+// splitAnd splits a condition on the ANDs outside quotes and parentheses
+func splitAnd(sql string) []string {
+	var parts []string
+	depth, start := 0, 0
+	inQuote := false
+	lower := strings.ToLower(sql)
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && strings.HasPrefix(lower[i:], " and "):
+			parts = append(parts, sql[start:i])
+			start = i + len(" and ")
+			i += len(" and ") - 1
+		}
+	}
+	return append(parts, sql[start:])
+}
+
+// enclosed reports whether a condition is wrapped in one pair of
+// parentheses
+func enclosed(sql string) bool {
+	if !strings.HasPrefix(sql, "(") || !strings.HasSuffix(sql, ")") {
+		return false
+	}
+	depth := 0
+	inQuote := false
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 && i < len(sql)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+func unquoteSQL(s string) string {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
@@ -0,0 +1,28 @@
+package smartquery
+
+// Organizations is the schema for queries over the Organizations table,
+// aliased o
+var Organizations = &Schema{
+	Name: "organizations",
+	Text: "o.name",
+	Fields: map[string]Field{
+		"name":      {Column: "o.name", Kind: Contains},
+		"status":    {Column: "o.status", Kind: Text},
+		"type":      {Column: "o.type", Kind: Text},
+		"accepts":   {Column: "o.accepts", Kind: Contains},
+		"interest":  {Column: "o.my_interest", Kind: Text},
+		"timing":    {Column: "o.timing", Kind: Text},
+		"source":    {Column: "o.source", Kind: Text},
+		"ranking":   {Column: "o.ranking", Kind: Number},
+		"pushcarts": {Column: "COALESCE(o.n_push_fiction, 0) + COALESCE(o.n_push_nonfiction, 0) + COALESCE(o.n_push_poetry, 0)", Kind: Number},
+		"added":     {Column: "date(o.date_added)", Kind: Date},
+		"id":        {Column: "o.orgID", Kind: Number},
+	},
+	Has: map[string]string{
+		"submission": `EXISTS (SELECT 1 FROM Submissions s WHERE s.orgID = o.orgID)`,
+		"pending":    `EXISTS (SELECT 1 FROM Submissions s WHERE s.orgID = o.orgID AND ` + pendingSubmission + `)`,
+		"acceptance": `EXISTS (SELECT 1 FROM Submissions s WHERE s.orgID = o.orgID AND s.response_type = 'Accepted')`,
+		"note":       `EXISTS (SELECT 1 FROM Notes n WHERE n.entity_type = 'journal' AND n.entity_id = o.orgID)`,
+		"contest":    `COALESCE(o.contest_ends, '') != ''`,
+	},
+}
@@ -0,0 +1,271 @@
+// Package smartquery is the query language of smart collections. A query
+// is parsed into terms, checked against the fields of the table it
+// selects from (works, submissions or organizations) and compiled to a
+// SQL condition with bound parameters, so a stored query can never change
+// the statement it is placed in.
+//
+// A query is a list of terms separated by spaces, all of which must match:
+//
+//	type:Poem status:Out year>=2020 quality:Best,Better words<300
+//	has:submission -in:"Book X" "night train"
+//
+// Terms take these forms:
+//
+//   - field:value matches a field; a comma-separated list matches any value
+//   - field>N, field>=N, field<N, field<=N and field=N compare numbers
+//   - date fields take 2024, 2024-03, 2024-03-15, today, thismonth,
+//     lastmonth, thisyear, lastyear, or a day ago such as 120d, 8w, 6m
+//     or 1y; field:D is within D, field<D before it and field>D after it
+//   - has:name and is:name test a relation or flag of the row
+//   - in:"Collection" matches members of a collection
+//   - a bare word or quoted phrase matches the title or name
+//   - a leading - negates any term
+//
+// Values with spaces or commas are quoted; \" and \\ escape inside quotes.
+package smartquery
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Term is one condition of a query
+type Term struct {
+	Pos    int      `json:"pos"`
+	Negate bool     `json:"negate,omitempty"`
+	Field  string   `json:"field,omitempty"` // empty for free text
+	Op     string   `json:"op,omitempty"`    // :, =, <, <=, >, >=
+	Values []string `json:"values"`
+}
+
+// Query is a parsed query; its terms are ANDed
+type Query struct {
+	Terms []Term `json:"terms"`
+}
+
+// Error is a parse or validation error at a byte offset of the query
+type Error struct {
+	Pos     int    `json:"pos"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos+1, e.Message)
+}
+
+func errorAt(pos int, format string, args ...any) *Error {
+	return &Error{Pos: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+// operators in the order they are tried, longest first
+var operators = []string{">=", "<=", ":", "=", ">", "<"}
+
+type parser struct {
+	src string
+	pos int
+}
+
+// Parse splits a query into terms. It checks syntax only; Compile checks
+// the terms against a schema.
+func Parse(src string) (*Query, error) {
+	p := &parser{src: src}
+	q := &Query{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return q, nil
+		}
+		t, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		q.Terms = append(q.Terms, t)
+	}
+}
+
+func (p *parser) term() (Term, error) {
+	t := Term{Pos: p.pos}
+	if p.src[p.pos] == '-' {
+		t.Negate = true
+		p.pos++
+		if p.pos >= len(p.src) || isSpace(p.src[p.pos]) {
+			return t, errorAt(t.Pos, "- must be followed by a term")
+		}
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) && isNameByte(p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos > start {
+		for _, op := range operators {
+			if strings.HasPrefix(p.src[p.pos:], op) {
+				t.Field = strings.ToLower(p.src[start:p.pos])
+				t.Op = op
+				p.pos += len(op)
+				values, err := p.values(t)
+				if err != nil {
+					return t, err
+				}
+				t.Values = values
+				return t, nil
+			}
+		}
+	}
+
+	// Anything else is text to look for
+	p.pos = start
+	if p.src[p.pos] == '"' {
+		v, err := p.quoted()
+		if err != nil {
+			return t, err
+		}
+		if p.pos < len(p.src) && !isSpace(p.src[p.pos]) {
+			return t, errorAt(p.pos, "expected a space after the closing quote")
+		}
+		t.Values = []string{v}
+		return t, nil
+	}
+	for p.pos < len(p.src) && !isSpace(p.src[p.pos]) {
+		if p.src[p.pos] == '"' {
+			return t, errorAt(p.pos, "quote inside a word; quote the whole phrase")
+		}
+		p.pos++
+	}
+	t.Values = []string{p.src[start:p.pos]}
+	return t, nil
+}
+
+// values reads a comma-separated list of quoted or bare values
+func (p *parser) values(t Term) ([]string, error) {
+	var values []string
+	for {
+		if p.pos >= len(p.src) || isSpace(p.src[p.pos]) || p.src[p.pos] == ',' {
+			return nil, errorAt(p.pos, "missing value after %s%s", t.Field, t.Op)
+		}
+		if p.src[p.pos] == '"' {
+			v, err := p.quoted()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		} else {
+			start := p.pos
+			for p.pos < len(p.src) && !isSpace(p.src[p.pos]) && p.src[p.pos] != ',' {
+				if p.src[p.pos] == '"' {
+					return nil, errorAt(p.pos, "quote inside a value; quote the whole value")
+				}
+				p.pos++
+			}
+			values = append(values, p.src[start:p.pos])
+		}
+
+		if p.pos >= len(p.src) || isSpace(p.src[p.pos]) {
+			return values, nil
+		}
+		if p.src[p.pos] != ',' {
+			return nil, errorAt(p.pos, "expected a comma or space after a value")
+		}
+		p.pos++
+	}
+}
+
+// quoted reads a double-quoted string starting at the opening quote
+func (p *parser) quoted() (string, error) {
+	open := p.pos
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c == '\\' && p.pos+1 < len(p.src):
+			b.WriteByte(p.src[p.pos+1])
+			p.pos += 2
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", errorAt(open, "unterminated quote")
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+// String formats the query in its canonical form, quoting values only
+// where needed
+func (q *Query) String() string {
+	parts := make([]string, 0, len(q.Terms))
+	for _, t := range q.Terms {
+		parts = append(parts, t.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+func (t Term) String() string {
+	var b strings.Builder
+	if t.Negate {
+		b.WriteByte('-')
+	}
+	if t.Field == "" {
+		if len(t.Values) > 0 {
+			b.WriteString(quote(t.Values[0], needsQuote(t.Values[0])))
+		}
+		return b.String()
+	}
+	b.WriteString(t.Field)
+	b.WriteString(t.Op)
+	for i, v := range t.Values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(quote(v, needsQuote(v)))
+	}
+	return b.String()
+}
+
+// needsQuote reports whether a value would not read back as itself bare
+func needsQuote(v string) bool {
+	if v == "" || v[0] == '-' {
+		return true
+	}
+	if strings.ContainsAny(v, " \t\r\n,\"\\") {
+		return true
+	}
+	// A bare word that looks like field:value would parse as a field
+	i := 0
+	for i < len(v) && isNameByte(v[i]) {
+		i++
+	}
+	if i > 0 {
+		for _, op := range operators {
+			if strings.HasPrefix(v[i:], op) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func quote(v string, force bool) string {
+	if !force {
+		return v
+	}
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return `"` + v + `"`
+}
@@ -0,0 +1,186 @@
+package smartquery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	q, err := Parse(`type:Poem status:Out year>=2020 quality:Best,Better words<300 has:submission -in:"Book X" night "train \"A\""`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, term := range q.Terms {
+		got = append(got, fmt.Sprintf("%v|%s|%s|%s", term.Negate, term.Field, term.Op, strings.Join(term.Values, ",")))
+	}
+	want := []string{
+		"false|type|:|Poem",
+		"false|status|:|Out",
+		"false|year|>=|2020",
+		"false|quality|:|Best,Better",
+		"false|words|<|300",
+		"false|has|:|submission",
+		"true|in|:|Book X",
+		"false|||night",
+		`false|||train "A"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Parse() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// The canonical form reads back as the same query
+	again, err := Parse(q.String())
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", q.String(), err)
+	}
+	if again.String() != q.String() {
+		t.Errorf("String() round trip: %q then %q", q.String(), again.String())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{`type:`, 5, "missing value"},
+		{`type:Poem,`, 10, "missing value"},
+		{`in:"Book X`, 3, "unterminated quote"},
+		{`status:Out - year>2000`, 11, "must be followed"},
+		{`title:"A"B`, 9, "comma or space"},
+		{`ni"ght`, 2, "quote inside a word"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.query)
+		var qe *Error
+		if !errors.As(err, &qe) {
+			t.Errorf("Parse(%q) error = %v, want a query error", tt.query, err)
+			continue
+		}
+		if qe.Pos != tt.pos || !strings.Contains(qe.Message, tt.msg) {
+			t.Errorf("Parse(%q) error = %d %q, want %d %q", tt.query, qe.Pos, qe.Message, tt.pos, tt.msg)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	where, args, err := Compile(`type:Poem quality:Best,Better year>=2020 -in:"Book X" 50%`, Works)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantWhere := `(w.type COLLATE NOCASE IN (?)) AND (w.quality COLLATE NOCASE IN (?, ?)) AND (CAST(w.year AS INTEGER) >= ?) AND NOT COALESCE((` +
+		Works.In + `), 0) AND (w.title LIKE ? ESCAPE '\')`
+	if where != wantWhere {
+		t.Errorf("where =\n%s\nwant\n%s", where, wantWhere)
+	}
+	if fmt.Sprint(args) != `[Poem Best Better 2020 Book X %50\%%]` {
+		t.Errorf("args = %v", args)
+	}
+
+	// Values never reach the SQL text
+	where, args, err = Compile(`status:"Out') OR 1=1 --"`, Works)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(where, "1=1") || len(args) != 1 {
+		t.Errorf("value leaked into SQL: %s %v", where, args)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		msg   string
+	}{
+		{``, "query is empty"},
+		{`colour:red`, `unknown field "colour"`},
+		{`status>Out`, "status is not a number"},
+		{`words:many`, "needs a whole number"},
+		{`year>2000,2010`, "takes one number"},
+		{`has:dog`, "unknown has:dog; use one of acceptance, collection, course, file, note, pending, submission, words, year"},
+		{`is>marked`, "use is:name"},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.query, Works)
+		if err == nil || !strings.Contains(err.Error(), tt.msg) {
+			t.Errorf("Validate(%q) = %v, want %q", tt.query, err, tt.msg)
+		}
+	}
+
+	if err := Validate(`in:"Book"`, &Schema{Name: "orgs", Fields: map[string]Field{}}); err == nil ||
+		!strings.Contains(err.Error(), "in: is not supported for orgs") {
+		t.Errorf("in: without a collection condition = %v", err)
+	}
+}
+
+func TestFromSQL(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+		ok   bool
+	}{
+		{"workID NOT IN (SELECT DISTINCT workID FROM CollectionDetails)", "-has:collection", true},
+		{"w.type = 'Poem' AND status IN ('Out', 'Focus')", "type:Poem status:Out,Focus", true},
+		{"(n_words < 300) and year >= '2020' AND quality <> 'Bad'", "words<300 year>=2020 -quality:Bad", true},
+		{"title LIKE '%night%' AND is_marked = 1", "title:night is:marked", true},
+		{"doc_type = 'docx' AND course_name = 'Writer''s Craft'", `doctype:docx course:"Writer's Craft"`, true},
+		{"year != '2020' AND n_words NOT IN ('0')", "has:year -year:2020 has:words -words:0", true},
+		{"course_name <> 'Poetry' AND type != 'Essay'", "has:course -course:Poetry -type:Essay", true},
+		{"type = 'Poem' OR type = 'Essay'", "", false},
+		{"attributes LIKE '%x%'", "", false},
+		{"title = 'Rain'", "", false},
+		{"workID IN (SELECT workID FROM Submissions)", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := FromSQL(tt.sql)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("FromSQL(%q) = %q, %v; want %q, %v", tt.sql, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCompileDates(t *testing.T) {
+	tests := []struct {
+		query string
+		where string
+		args  string
+	}{
+		{`submitted:2024`, `date(s.submission_date) >= date(?) AND date(s.submission_date) < date(?, ?)`, `[2024-01-01 2024-01-01 +1 year]`},
+		{`submitted<2024-03`, `date(s.submission_date) < date(?)`, `[2024-03-01]`},
+		{`submitted>2024-03-15`, `date(s.submission_date) >= date(?, ?)`, `[2024-03-15 +1 day]`},
+		{`responded<=thisyear`, `date(s.response_date) < date(?, ?, ?, ?)`, `[now localtime start of year +1 year]`},
+		{`submitted>=8w`, `date(s.submission_date) >= date(?, ?, ?)`, `[now localtime -56 days]`},
+	}
+	for _, tt := range tests {
+		where, args, err := Compile(tt.query, Submissions)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.query, err)
+			continue
+		}
+		where = strings.TrimSuffix(strings.TrimPrefix(where, "("), ")")
+		if where != tt.where || fmt.Sprint(args) != tt.args {
+			t.Errorf("Compile(%q) =\n%s %v\nwant\n%s %s", tt.query, where, args, tt.where, tt.args)
+		}
+	}
+
+	for _, tt := range []struct {
+		query  string
+		schema *Schema
+		msg    string
+	}{
+		{`submitted:soon`, Submissions, "needs a date"},
+		{`submitted:2024-13`, Submissions, "needs a date"},
+		{`submitted<2023,2024`, Submissions, "takes one date"},
+		{`added>thisyear -has:submission ranking<=3`, Organizations, ""},
+		{`in:"Book X"`, Organizations, "in: is not supported for organizations"},
+	} {
+		err := Validate(tt.query, tt.schema)
+		if tt.msg == "" && err != nil || tt.msg != "" && (err == nil || !strings.Contains(err.Error(), tt.msg)) {
+			t.Errorf("Validate(%q) = %v, want %q", tt.query, err, tt.msg)
+		}
+	}
+}
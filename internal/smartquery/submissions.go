@@ -0,0 +1,36 @@
+package smartquery
+
+const submissionTitle = `CASE WHEN s.is_collection = 1 THEN c.collection_name ELSE w.title END`
+
+// Submissions is the schema for queries over the Submissions table,
+// aliased s, joined to Works w, Collections c and Organizations o as the
+// submission views are
+var Submissions = &Schema{
+	Name: "submissions",
+	Text: submissionTitle,
+	Fields: map[string]Field{
+		"title":     {Column: submissionTitle, Kind: Contains},
+		"journal":   {Column: "o.name", Kind: Contains},
+		"type":      {Column: "s.submission_type", Kind: Text},
+		"response":  {Column: "s.response_type", Kind: Text},
+		"draft":     {Column: "s.draft", Kind: Text},
+		"contest":   {Column: "s.contest_name", Kind: Contains},
+		"worktype":  {Column: "w.type", Kind: Text},
+		"submitted": {Column: "date(s.submission_date)", Kind: Date},
+		"responded": {Column: "date(s.response_date)", Kind: Date},
+		"age":       {Column: "CAST(julianday('now', 'localtime') - julianday(s.submission_date) AS INTEGER)", Kind: Number},
+		"cost":      {Column: "s.cost", Kind: Number},
+		"id":        {Column: "s.submissionID", Kind: Number},
+	},
+	Has: map[string]string{
+		"note":  `EXISTS (SELECT 1 FROM Notes n WHERE n.entity_type = 'submission' AND n.entity_id = s.submissionID)`,
+		"query": `COALESCE(s.query_date, '') != ''`,
+	},
+	Is: map[string]string{
+		"pending":    pendingSubmission,
+		"accepted":   `s.response_type = 'Accepted'`,
+		"collection": `COALESCE(s.is_collection, 0) = 1`,
+	},
+	In: `COALESCE(s.is_collection, 0) = 0 AND EXISTS (SELECT 1 FROM CollectionDetails cd JOIN Collections cc ON cc.collID = cd.collID
+		WHERE cd.workID = s.workID AND cc.collection_name = ? COLLATE NOCASE)`,
+}
@@ -0,0 +1,40 @@
+package smartquery
+
+const pendingSubmission = `s.response_date IS NULL AND (s.response_type IS NULL OR s.response_type = '' OR s.response_type = 'Waiting')`
+
+// workSubmission matches the submissions of w itself. The workID of a
+// collection's submission holds the collection's ID.
+const workSubmission = `s.workID = w.workID AND COALESCE(s.is_collection, 0) = 0`
+
+// Works is the schema for queries over the Works table, aliased w
+var Works = &Schema{
+	Name: "works",
+	Text: "w.title",
+	Fields: map[string]Field{
+		"title":   {Column: "w.title", Kind: Contains},
+		"type":    {Column: "w.type", Kind: Text},
+		"status":  {Column: "w.status", Kind: Text},
+		"quality": {Column: "w.quality", Kind: Text},
+		"doctype": {Column: "w.doc_type", Kind: Text},
+		"course":  {Column: "w.course_name", Kind: Text},
+		"year":    {Column: "CAST(w.year AS INTEGER)", Kind: Number},
+		"words":   {Column: "w.n_words", Kind: Number},
+		"id":      {Column: "w.workID", Kind: Number},
+	},
+	Has: map[string]string{
+		"submission": `EXISTS (SELECT 1 FROM Submissions s WHERE ` + workSubmission + `)`,
+		"pending":    `EXISTS (SELECT 1 FROM Submissions s WHERE ` + workSubmission + ` AND ` + pendingSubmission + `)`,
+		"acceptance": `EXISTS (SELECT 1 FROM Submissions s WHERE ` + workSubmission + ` AND s.response_type = 'Accepted')`,
+		"note":       `EXISTS (SELECT 1 FROM Notes n WHERE n.entity_type = 'work' AND n.entity_id = w.workID)`,
+		"collection": `EXISTS (SELECT 1 FROM CollectionDetails cd WHERE cd.workID = w.workID)`,
+		"file":       `COALESCE(w.path, '') != ''`,
+		"year":       `w.year IS NOT NULL`,
+		"words":      `w.n_words IS NOT NULL`,
+		"course":     `w.course_name IS NOT NULL`,
+	},
+	Is: map[string]string{
+		"marked": `COALESCE(w.is_marked, 0) = 1`,
+	},
+	In: `EXISTS (SELECT 1 FROM CollectionDetails cd JOIN Collections c ON c.collID = cd.collID
+		WHERE cd.workID = w.workID AND c.collection_name = ? COLLATE NOCASE)`,
+}
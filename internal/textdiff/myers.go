@@ -0,0 +1,124 @@
+package textdiff
+
+// maxEditDistance bounds the work done on two very different inputs. Past
+// it, the differing middle is reported as removed and re-added whole.
+const maxEditDistance = 2000
+
+type edit struct {
+	op   string
+	a, b int // indexes into the inputs; only the side(s) the op touches are set
+}
+
+// editScript returns the shortest sequence of equal, delete and insert steps
+// turning a into b (Myers' O(ND) algorithm)
+func editScript(a, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	script := make([]edit, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		script = append(script, edit{op: OpEqual, a: i, b: i})
+	}
+	for _, e := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		e.a += prefix
+		e.b += prefix
+		script = append(script, e)
+	}
+	for i := suffix; i > 0; i-- {
+		script = append(script, edit{op: OpEqual, a: len(a) - i, b: len(b) - i})
+	}
+	return script
+}
+
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return replaceAll(n, m)
+	}
+
+	limit := min(n+m, maxEditDistance)
+	offset := limit + 1
+	v := make([]int, 2*offset+1)
+
+	// trace[d] holds v[-d-1..d+1] as it stood before step d
+	trace := [][]int{}
+	for d := 0; d <= limit; d++ {
+		snap := make([]int, 2*d+3)
+		copy(snap, v[offset-d-1:offset+d+2])
+		trace = append(trace, snap)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m)
+			}
+		}
+	}
+	return replaceAll(n, m)
+}
+
+func backtrack(trace [][]int, n, m int) []edit {
+	script := []edit{}
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		snap := trace[d]
+		get := func(k int) int { return snap[k+d+1] }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && get(k-1) < get(k+1)) {
+			prevK = k + 1
+		}
+		prevX := get(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			script = append(script, edit{op: OpEqual, a: x, b: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				script = append(script, edit{op: OpInsert, b: y})
+			} else {
+				x--
+				script = append(script, edit{op: OpDelete, a: x})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(script)-1; i < j; i, j = i+1, j-1 {
+		script[i], script[j] = script[j], script[i]
+	}
+	return script
+}
+
+func replaceAll(n, m int) []edit {
+	script := make([]edit, 0, n+m)
+	for i := 0; i < n; i++ {
+		script = append(script, edit{op: OpDelete, a: i})
+	}
+	for j := 0; j < m; j++ {
+		script = append(script, edit{op: OpInsert, b: j})
+	}
+	return script
+}
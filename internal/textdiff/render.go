@@ -0,0 +1,134 @@
+package textdiff
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// RenderHTML renders a result as an HTML fragment. Removed text is wrapped
+// in <del> and added text in <ins>; paragraphs carry a diff-<op> class so
+// the page can style them.
+func RenderHTML(r *Result) string {
+	var sb strings.Builder
+	sb.WriteString(`<div class="diff">` + "\n")
+	if r.From != "" || r.To != "" {
+		fmt.Fprintf(&sb, `<div class="diff-files"><del>%s</del> &rarr; <ins>%s</ins></div>`+"\n",
+			html.EscapeString(r.From), html.EscapeString(r.To))
+	}
+	if !r.HasChanges() {
+		sb.WriteString(`<p class="diff-none">No changes</p>` + "\n")
+	}
+
+	for _, h := range r.Hunks {
+		sb.WriteString(`<div class="diff-hunk">` + "\n")
+		fmt.Fprintf(&sb, `<div class="diff-header">%s</div>`+"\n", hunkHeader(h))
+		for _, p := range h.Paragraphs {
+			fmt.Fprintf(&sb, `<p class="diff-%s">`, p.Op)
+			switch p.Op {
+			case OpEqual:
+				sb.WriteString(html.EscapeString(p.To))
+			case OpDelete:
+				sb.WriteString("<del>" + html.EscapeString(p.From) + "</del>")
+			case OpInsert:
+				sb.WriteString("<ins>" + html.EscapeString(p.To) + "</ins>")
+			case OpChange:
+				for _, seg := range p.Words {
+					text := html.EscapeString(seg.Text)
+					switch seg.Op {
+					case OpDelete:
+						sb.WriteString("<del>" + text + "</del>")
+					case OpInsert:
+						sb.WriteString("<ins>" + text + "</ins>")
+					default:
+						sb.WriteString(text)
+					}
+				}
+			}
+			sb.WriteString("</p>\n")
+		}
+		sb.WriteString("</div>\n")
+	}
+
+	sb.WriteString("</div>\n")
+	return sb.String()
+}
+
+// RenderMarkdown renders a result as Markdown, with removed text struck
+// through (~~old~~) and added text in bold (**new**)
+func RenderMarkdown(r *Result) string {
+	var sb strings.Builder
+	if r.From != "" || r.To != "" {
+		fmt.Fprintf(&sb, "~~%s~~ → **%s**\n\n", escapeMarkdown(r.From), escapeMarkdown(r.To))
+	}
+	if !r.HasChanges() {
+		sb.WriteString("_No changes_\n")
+		return sb.String()
+	}
+
+	for i, h := range r.Hunks {
+		if i > 0 {
+			sb.WriteString("---\n\n")
+		}
+		fmt.Fprintf(&sb, "#### %s\n\n", hunkHeader(h))
+		for _, p := range h.Paragraphs {
+			switch p.Op {
+			case OpEqual:
+				sb.WriteString(escapeMarkdown(p.To))
+			case OpDelete:
+				sb.WriteString(markSpan("~~", p.From))
+			case OpInsert:
+				sb.WriteString(markSpan("**", p.To))
+			case OpChange:
+				for _, seg := range p.Words {
+					switch seg.Op {
+					case OpDelete:
+						sb.WriteString(markSpan("~~", seg.Text))
+					case OpInsert:
+						sb.WriteString(markSpan("**", seg.Text))
+					default:
+						sb.WriteString(escapeMarkdown(seg.Text))
+					}
+				}
+			}
+			sb.WriteString("\n\n")
+		}
+	}
+	return sb.String()
+}
+
+func hunkHeader(h Hunk) string {
+	return fmt.Sprintf("Paragraphs %s → %s", paragraphRange(h.FromStart, h.FromCount), paragraphRange(h.ToStart, h.ToCount))
+}
+
+func paragraphRange(start, count int) string {
+	switch count {
+	case 0:
+		return "none"
+	case 1:
+		return fmt.Sprintf("%d", start)
+	default:
+		return fmt.Sprintf("%d–%d", start, start+count-1)
+	}
+}
+
+// markSpan wraps text in a Markdown marker, keeping surrounding spaces
+// outside it since "** word**" does not render as bold
+func markSpan(marker, text string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	lead := text[:strings.Index(text, trimmed)]
+	trail := text[len(lead)+len(trimmed):]
+	return lead + marker + escapeMarkdown(trimmed) + marker + trail
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `~`, `\~`,
+	`[`, `\[`, `]`, `\]`, `#`, `\#`, `<`, `\<`, `>`, `\>`, `|`, `\|`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
@@ -0,0 +1,299 @@
+// Package textdiff compares the plain text extracted from two versions of a
+// document. Paragraphs are aligned first; a paragraph that was edited
+// rather than replaced is then diffed word by word.
+package textdiff
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+	OpChange = "change"
+)
+
+// similarityThreshold is how much of two paragraphs' wording must match for
+// the pair to be shown as an edit instead of a removal and an addition
+const similarityThreshold = 0.5
+
+// maxPairings bounds the paragraph comparisons made when pairing edits in
+// one run of removed and added paragraphs
+const maxPairings = 2500
+
+var (
+	tokenRegex = regexp.MustCompile(`[\p{L}\p{N}'’]+|\s+|[^\p{L}\p{N}\s]`)
+	wordRegex  = regexp.MustCompile(`[\p{L}\p{N}]+`)
+)
+
+// Segment is a run of text within a changed paragraph
+type Segment struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Paragraph is one aligned paragraph. From and To hold the old and new text
+// as applicable; line numbers are 1-based paragraph positions, 0 when the
+// paragraph does not exist on that side. Words is set for changes.
+type Paragraph struct {
+	Op       string    `json:"op"`
+	From     string    `json:"from,omitempty"`
+	To       string    `json:"to,omitempty"`
+	FromLine int       `json:"fromLine,omitempty"`
+	ToLine   int       `json:"toLine,omitempty"`
+	Words    []Segment `json:"words,omitempty"`
+}
+
+// Hunk is a group of nearby changes with surrounding context
+type Hunk struct {
+	FromStart  int         `json:"fromStart"`
+	FromCount  int         `json:"fromCount"`
+	ToStart    int         `json:"toStart"`
+	ToCount    int         `json:"toCount"`
+	Paragraphs []Paragraph `json:"paragraphs"`
+}
+
+type Stats struct {
+	ParagraphsAdded   int `json:"paragraphsAdded"`
+	ParagraphsRemoved int `json:"paragraphsRemoved"`
+	ParagraphsChanged int `json:"paragraphsChanged"`
+	WordsAdded        int `json:"wordsAdded"`
+	WordsRemoved      int `json:"wordsRemoved"`
+}
+
+// Result is the difference between two texts. From and To label the two
+// versions for display.
+type Result struct {
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+	Hunks []Hunk `json:"hunks"`
+	Stats Stats  `json:"stats"`
+}
+
+// Options controls how much unchanged text surrounds each hunk. A negative
+// Context returns the whole document as a single hunk.
+type Options struct {
+	Context int `json:"context"`
+}
+
+// HasChanges reports whether the two texts differ
+func (r *Result) HasChanges() bool {
+	s := r.Stats
+	return s.ParagraphsAdded+s.ParagraphsRemoved+s.ParagraphsChanged > 0
+}
+
+// Paragraphs splits extracted text into non-empty, trimmed paragraphs
+func Paragraphs(text string) []string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	paras := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			paras = append(paras, line)
+		}
+	}
+	return paras
+}
+
+// Compare diffs two texts
+func Compare(from, to string, opts Options) *Result {
+	paras := align(Paragraphs(from), Paragraphs(to))
+
+	result := &Result{Hunks: hunks(paras, opts.Context)}
+	for _, p := range paras {
+		switch p.Op {
+		case OpInsert:
+			result.Stats.ParagraphsAdded++
+			result.Stats.WordsAdded += countWords(p.To)
+		case OpDelete:
+			result.Stats.ParagraphsRemoved++
+			result.Stats.WordsRemoved += countWords(p.From)
+		case OpChange:
+			result.Stats.ParagraphsChanged++
+			for _, seg := range p.Words {
+				switch seg.Op {
+				case OpInsert:
+					result.Stats.WordsAdded += countWords(seg.Text)
+				case OpDelete:
+					result.Stats.WordsRemoved += countWords(seg.Text)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Words diffs two strings word by word, keeping spaces and punctuation so
+// the segments concatenate back to either text
+func Words(from, to string) []Segment {
+	a := tokenRegex.FindAllString(from, -1)
+	b := tokenRegex.FindAllString(to, -1)
+
+	segments := []Segment{}
+	add := func(op, text string) {
+		if n := len(segments); n > 0 && segments[n-1].Op == op {
+			segments[n-1].Text += text
+			return
+		}
+		segments = append(segments, Segment{Op: op, Text: text})
+	}
+	for _, e := range editScript(a, b) {
+		switch e.op {
+		case OpEqual:
+			add(OpEqual, a[e.a])
+		case OpDelete:
+			add(OpDelete, a[e.a])
+		case OpInsert:
+			add(OpInsert, b[e.b])
+		}
+	}
+	return segments
+}
+
+// align lines up two paragraph lists, pairing removed and added paragraphs
+// that are edits of one another
+func align(a, b []string) []Paragraph {
+	paras := []Paragraph{}
+	var dels, ins []int
+
+	flush := func() {
+		paras = append(paras, pairEdits(a, b, dels, ins)...)
+		dels, ins = dels[:0], ins[:0]
+	}
+
+	for _, e := range editScript(a, b) {
+		switch e.op {
+		case OpEqual:
+			flush()
+			paras = append(paras, Paragraph{Op: OpEqual, From: a[e.a], To: b[e.b], FromLine: e.a + 1, ToLine: e.b + 1})
+		case OpDelete:
+			dels = append(dels, e.a)
+		case OpInsert:
+			ins = append(ins, e.b)
+		}
+	}
+	flush()
+	return paras
+}
+
+// pairEdits turns one run of removed and added paragraphs into paragraphs,
+// keeping both sides in order
+func pairEdits(a, b []string, dels, ins []int) []Paragraph {
+	out := []Paragraph{}
+	insert := func(j int) {
+		out = append(out, Paragraph{Op: OpInsert, To: b[j], ToLine: j + 1})
+	}
+
+	pairing := len(dels)*len(ins) <= maxPairings
+	next := 0
+	for _, i := range dels {
+		match := -1
+		if pairing {
+			for k := next; k < len(ins); k++ {
+				if similarity(a[i], b[ins[k]]) >= similarityThreshold {
+					match = k
+					break
+				}
+			}
+		}
+		if match < 0 {
+			out = append(out, Paragraph{Op: OpDelete, From: a[i], FromLine: i + 1})
+			continue
+		}
+		for ; next < match; next++ {
+			insert(ins[next])
+		}
+		j := ins[match]
+		out = append(out, Paragraph{
+			Op: OpChange, From: a[i], To: b[j], FromLine: i + 1, ToLine: j + 1,
+			Words: Words(a[i], b[j]),
+		})
+		next = match + 1
+	}
+	for ; next < len(ins); next++ {
+		insert(ins[next])
+	}
+	return out
+}
+
+// similarity is the share of words two paragraphs have in common, in order
+func similarity(x, y string) float64 {
+	a := wordRegex.FindAllString(strings.ToLower(x), -1)
+	b := wordRegex.FindAllString(strings.ToLower(y), -1)
+	if len(a)+len(b) == 0 {
+		return 1
+	}
+	common := 0
+	for _, e := range editScript(a, b) {
+		if e.op == OpEqual {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(a)+len(b))
+}
+
+// hunks groups changed paragraphs with up to context unchanged paragraphs on
+// either side, merging groups whose context would overlap
+func hunks(paras []Paragraph, context int) []Hunk {
+	result := []Hunk{}
+	if len(paras) == 0 {
+		return result
+	}
+	if context < 0 {
+		return append(result, newHunk(paras, 0, len(paras)))
+	}
+
+	start, end := -1, -1
+	for i, p := range paras {
+		if p.Op == OpEqual {
+			continue
+		}
+		lo, hi := max(i-context, 0), min(i+context+1, len(paras))
+		if start >= 0 && lo <= end {
+			end = hi
+			continue
+		}
+		if start >= 0 {
+			result = append(result, newHunk(paras, start, end))
+		}
+		start, end = lo, hi
+	}
+	if start >= 0 {
+		result = append(result, newHunk(paras, start, end))
+	}
+	return result
+}
+
+// newHunk builds the hunk for paras[lo:hi]. A side with no paragraphs in
+// the hunk starts at the paragraph it follows, as in a unified diff.
+func newHunk(paras []Paragraph, lo, hi int) Hunk {
+	h := Hunk{Paragraphs: paras[lo:hi]}
+	for _, p := range paras[lo:hi] {
+		if p.FromLine > 0 {
+			if h.FromCount == 0 {
+				h.FromStart = p.FromLine
+			}
+			h.FromCount++
+		}
+		if p.ToLine > 0 {
+			if h.ToCount == 0 {
+				h.ToStart = p.ToLine
+			}
+			h.ToCount++
+		}
+	}
+	for i := lo - 1; i >= 0 && (h.FromCount == 0 && h.FromStart == 0 || h.ToCount == 0 && h.ToStart == 0); i-- {
+		if h.FromCount == 0 && h.FromStart == 0 && paras[i].FromLine > 0 {
+			h.FromStart = paras[i].FromLine
+		}
+		if h.ToCount == 0 && h.ToStart == 0 && paras[i].ToLine > 0 {
+			h.ToStart = paras[i].ToLine
+		}
+	}
+	return h
+}
+
+func countWords(text string) int {
+	return len(wordRegex.FindAllString(text, -1))
+}
@@ -0,0 +1,120 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func ops(r *Result) string {
+	var parts []string
+	for _, h := range r.Hunks {
+		for _, p := range h.Paragraphs {
+			parts = append(parts, p.Op)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestCompare(t *testing.T) {
+	from := "Title\n\nThe rain came down.\nIt kept falling.\nA bird sang somewhere far off.\n\nThe end."
+	to := "Title\n\nThe rain came down hard.\nIt kept falling.\nThen it stopped.\n\nThe end."
+
+	result := Compare(from, to, Options{Context: -1})
+
+	if got := ops(result); got != "equal change equal delete insert equal" {
+		t.Fatalf("unexpected paragraph ops: %s", got)
+	}
+	change := result.Hunks[0].Paragraphs[1]
+	if change.FromLine != 2 || change.ToLine != 2 {
+		t.Errorf("expected the change at paragraph 2, got %d → %d", change.FromLine, change.ToLine)
+	}
+	var rebuilt strings.Builder
+	for _, seg := range change.Words {
+		if seg.Op != OpDelete {
+			rebuilt.WriteString(seg.Text)
+		}
+	}
+	if rebuilt.String() != "The rain came down hard." {
+		t.Errorf("word segments do not rebuild the new text: %q", rebuilt.String())
+	}
+
+	want := Stats{ParagraphsAdded: 1, ParagraphsRemoved: 1, ParagraphsChanged: 1, WordsAdded: 4, WordsRemoved: 6}
+	if result.Stats != want {
+		t.Errorf("unexpected stats:\n got %+v\nwant %+v", result.Stats, want)
+	}
+}
+
+func TestHunkContext(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 20; i++ {
+		line := strings.Repeat("x", i+1)
+		a = append(a, line)
+		if i == 3 || i == 15 {
+			line = "changed completely"
+		}
+		b = append(b, line)
+	}
+
+	result := Compare(strings.Join(a, "\n"), strings.Join(b, "\n"), Options{Context: 1})
+	if len(result.Hunks) != 2 {
+		t.Fatalf("expected 2 hunks, got %d", len(result.Hunks))
+	}
+	h := result.Hunks[0]
+	if h.FromStart != 3 || h.FromCount != 3 || h.ToStart != 3 || h.ToCount != 3 {
+		t.Errorf("unexpected first hunk range: %+v", h)
+	}
+
+	inserted := Compare("a\nb\nc", "a\nb\nnew\nc", Options{})
+	if h := inserted.Hunks[0]; h.FromStart != 2 || h.FromCount != 0 || h.ToStart != 3 || h.ToCount != 1 {
+		t.Errorf("expected an insert after paragraph 2, got %+v", h)
+	}
+
+	if merged := Compare(strings.Join(a, "\n"), strings.Join(b, "\n"), Options{Context: 6}); len(merged.Hunks) != 1 {
+		t.Errorf("expected overlapping context to merge hunks, got %d", len(merged.Hunks))
+	}
+}
+
+func TestCompareIdenticalAndEmpty(t *testing.T) {
+	if r := Compare("a\nb", "a\nb", Options{}); r.HasChanges() || len(r.Hunks) != 0 {
+		t.Errorf("expected no hunks for identical text, got %+v", r)
+	}
+	if r := Compare("", "a\nb", Options{}); r.Stats.ParagraphsAdded != 2 {
+		t.Errorf("expected everything inserted, got %+v", r.Stats)
+	}
+	if r := Compare("", "", Options{Context: -1}); len(r.Hunks) != 0 {
+		t.Errorf("expected no hunks, got %+v", r)
+	}
+}
+
+func TestEditScriptIsMinimal(t *testing.T) {
+	a := strings.Split("a b c a b b a", " ")
+	b := strings.Split("c b a b a c", " ")
+	edits := 0
+	for _, e := range editScript(a, b) {
+		if e.op != OpEqual {
+			edits++
+		}
+	}
+	if edits != 5 {
+		t.Errorf("expected 5 edits, got %d", edits)
+	}
+}
+
+func TestRender(t *testing.T) {
+	result := Compare("The <rain> came.", "The <rain> came down.", Options{Context: -1})
+	result.From, result.To = "Revision 3", "Current file"
+
+	out := RenderHTML(result)
+	for _, want := range []string{`&lt;rain&gt;`, `<ins> down</ins>`, `class="diff-change"`, "Revision 3"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML is missing %q:\n%s", want, out)
+		}
+	}
+
+	md := RenderMarkdown(result)
+	for _, want := range []string{`\<rain\>`, " **down**", "~~Revision 3~~"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown is missing %q:\n%s", want, md)
+		}
+	}
+}
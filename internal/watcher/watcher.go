@@ -21,6 +21,7 @@ type Watcher struct {
 	debounceDelay time.Duration
 	onPDFNeeded   ChangeHandler
 	onFTSNeeded   ChangeHandler
+	onRevision    ChangeHandler
 	logFunc       LogFunc
 
 	watcher  *fsnotify.Watcher
@@ -60,6 +61,12 @@ func (w *Watcher) SetFTSHandler(handler ChangeHandler) {
 	w.onFTSNeeded = handler
 }
 
+// SetRevisionHandler registers a handler that records each change to a
+// work's document as a new revision
+func (w *Watcher) SetRevisionHandler(handler ChangeHandler) {
+	w.onRevision = handler
+}
+
 func (w *Watcher) getWorkDirectories() ([]string, error) {
 	rows, err := w.db.Query(`SELECT DISTINCT path FROM Works WHERE path IS NOT NULL AND path != ''`)
 	if err != nil {
@@ -214,6 +221,10 @@ func (w *Watcher) processFileChange(path string) {
 		return
 	}
 
+	if w.onRevision != nil {
+		go w.onRevision(workID, path)
+	}
+
 	if w.onPDFNeeded != nil {
 		go w.onPDFNeeded(workID, path)
 	}